@@ -21,6 +21,55 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// BlobPriority is the priority lane in which the disperser processes a blob
+type BlobPriority int32
+
+const (
+	// NORMAL is the default lane
+	BlobPriority_NORMAL BlobPriority = 0
+	// HIGH is the lane for expedited dispersal
+	BlobPriority_HIGH BlobPriority = 1
+)
+
+// Enum value maps for BlobPriority.
+var (
+	BlobPriority_name = map[int32]string{
+		0: "NORMAL",
+		1: "HIGH",
+	}
+	BlobPriority_value = map[string]int32{
+		"NORMAL": 0,
+		"HIGH":   1,
+	}
+)
+
+func (x BlobPriority) Enum() *BlobPriority {
+	p := new(BlobPriority)
+	*p = x
+	return p
+}
+
+func (x BlobPriority) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BlobPriority) Descriptor() protoreflect.EnumDescriptor {
+	return file_disperser_disperser_proto_enumTypes[0].Descriptor()
+}
+
+func (BlobPriority) Type() protoreflect.EnumType {
+	return &file_disperser_disperser_proto_enumTypes[0]
+}
+
+func (x BlobPriority) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BlobPriority.Descriptor instead.
+func (BlobPriority) EnumDescriptor() ([]byte, []int) {
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{0}
+}
+
 type BlobStatus int32
 
 const (
@@ -38,6 +87,10 @@ const (
 	// INSUFFICIENT_SIGNATURES means that the quorum threshold for the blob was not met
 	// for at least one quorum.
 	BlobStatus_INSUFFICIENT_SIGNATURES BlobStatus = 5
+	// PRUNED means that the blob was finalized, and its inclusion proof has since been pruned
+	// after the retention period of the disperser. The fields referencing the batch of the blob
+	// onchain are still returned, but the blob can no longer be verified with the reply.
+	BlobStatus_PRUNED BlobStatus = 6
 )
 
 // Enum value maps for BlobStatus.
@@ -49,6 +102,7 @@ var (
 		3: "FAILED",
 		4: "FINALIZED",
 		5: "INSUFFICIENT_SIGNATURES",
+		6: "PRUNED",
 	}
 	BlobStatus_value = map[string]int32{
 		"UNKNOWN":                 0,
@@ -57,6 +111,7 @@ var (
 		"FAILED":                  3,
 		"FINALIZED":               4,
 		"INSUFFICIENT_SIGNATURES": 5,
+		"PRUNED":                  6,
 	}
 )
 
@@ -71,11 +126,11 @@ func (x BlobStatus) String() string {
 }
 
 func (BlobStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_disperser_disperser_proto_enumTypes[0].Descriptor()
+	return file_disperser_disperser_proto_enumTypes[1].Descriptor()
 }
 
 func (BlobStatus) Type() protoreflect.EnumType {
-	return &file_disperser_disperser_proto_enumTypes[0]
+	return &file_disperser_disperser_proto_enumTypes[1]
 }
 
 func (x BlobStatus) Number() protoreflect.EnumNumber {
@@ -84,7 +139,7 @@ func (x BlobStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use BlobStatus.Descriptor instead.
 func (BlobStatus) EnumDescriptor() ([]byte, []int) {
-	return file_disperser_disperser_proto_rawDescGZIP(), []int{0}
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{1}
 }
 
 type AuthenticatedRequest struct {
@@ -369,6 +424,26 @@ type DisperseBlobRequest struct {
 	// The account ID of the client. This should be a hex-encoded string of the ECSDA public key
 	// corresponding to the key used by the client to sign the BlobAuthHeader.
 	AccountId string `protobuf:"bytes,3,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// An optional client-supplied key used to make dispersal idempotent.
+	// If a blob has already been dispersed with the same idempotency_key, the disperser
+	// does not create a new blob and instead returns the status and request ID of the
+	// existing one. This allows clients to safely retry DisperseBlob after a timeout.
+	// Clients should use a unique value (e.g. a UUID) per logical blob.
+	// The length of idempotency_key must be <= 128 bytes.
+	IdempotencyKey string `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// The priority lane of the blob. Blobs in the high priority lane are encoded and batched
+	// ahead of blobs in the normal lane. Only authenticated requests (DisperseBlobAuthenticated)
+	// and allowlisted accounts may request the high priority lane.
+	Priority BlobPriority `protobuf:"varint,5,opt,name=priority,proto3,enum=disperser.BlobPriority" json:"priority,omitempty"`
+	// Optional opaque metadata attached to the blob by the client (e.g. a rollup block number).
+	// It is returned as is in BlobStatusReply and doesn't affect how the blob is encoded or batched.
+	// The length of client_metadata must be <= 1KiB.
+	ClientMetadata []byte `protobuf:"bytes,6,opt,name=client_metadata,json=clientMetadata,proto3" json:"client_metadata,omitempty"`
+	// Optional number of symbols per chunk to encode the blob with in every quorum, overriding
+	// the chunk length the disperser derives from the operator state and security params.
+	// It must be a power of 2 and satisfy the constraints of the assignment of each quorum,
+	// otherwise the blob fails. If 0, the disperser chooses the chunk length.
+	ChunkLength uint32 `protobuf:"varint,7,opt,name=chunk_length,json=chunkLength,proto3" json:"chunk_length,omitempty"`
 }
 
 func (x *DisperseBlobRequest) Reset() {
@@ -424,6 +499,34 @@ func (x *DisperseBlobRequest) GetAccountId() string {
 	return ""
 }
 
+func (x *DisperseBlobRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *DisperseBlobRequest) GetPriority() BlobPriority {
+	if x != nil {
+		return x.Priority
+	}
+	return BlobPriority_NORMAL
+}
+
+func (x *DisperseBlobRequest) GetClientMetadata() []byte {
+	if x != nil {
+		return x.ClientMetadata
+	}
+	return nil
+}
+
+func (x *DisperseBlobRequest) GetChunkLength() uint32 {
+	if x != nil {
+		return x.ChunkLength
+	}
+	return 0
+}
+
 type DisperseBlobReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -440,6 +543,9 @@ type DisperseBlobReply struct {
 	// The client should use this ID to query the processing status of the request (via
 	// the GetBlobStatus API).
 	RequestId []byte `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// The receipt of the disperser acknowledging the acceptance of the blob, if the disperser
+	// signs receipts.
+	Receipt *BlobReceipt `protobuf:"bytes,3,opt,name=receipt,proto3" json:"receipt,omitempty"`
 }
 
 func (x *DisperseBlobReply) Reset() {
@@ -488,32 +594,555 @@ func (x *DisperseBlobReply) GetRequestId() []byte {
 	return nil
 }
 
-// BlobStatusRequest is used to query the status of a blob.
-type BlobStatusRequest struct {
+func (x *DisperseBlobReply) GetReceipt() *BlobReceipt {
+	if x != nil {
+		return x.Receipt
+	}
+	return nil
+}
+
+// BlobReceipt is the acknowledgement by the disperser that it accepted a blob. It is
+// signed with the receipt signing key of the disperser, so that the client can prove
+// that the blob was accepted before it's confirmed.
+type BlobReceipt struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The request ID of the blob, as returned in the DisperseBlobReply.
+	RequestId []byte `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// The time the blob was requested at, in nanoseconds since the Unix epoch.
+	RequestedAt uint64 `protobuf:"varint,2,opt,name=requested_at,json=requestedAt,proto3" json:"requested_at,omitempty"`
+	// The 65-byte ECDSA signature of the disperser over the keccak256 hash of the
+	// request_id followed by the 8-byte big-endian requested_at.
+	Signature []byte `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (x *BlobReceipt) Reset() {
+	*x = BlobReceipt{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_disperser_disperser_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlobReceipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobReceipt) ProtoMessage() {}
+
+func (x *BlobReceipt) ProtoReflect() protoreflect.Message {
+	mi := &file_disperser_disperser_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobReceipt.ProtoReflect.Descriptor instead.
+func (*BlobReceipt) Descriptor() ([]byte, []int) {
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BlobReceipt) GetRequestId() []byte {
+	if x != nil {
+		return x.RequestId
+	}
+	return nil
+}
+
+func (x *BlobReceipt) GetRequestedAt() uint64 {
+	if x != nil {
+		return x.RequestedAt
+	}
+	return 0
+}
+
+func (x *BlobReceipt) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+// BlobStatusRequest is used to query the status of a blob.
+type BlobStatusRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId []byte `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+}
+
+func (x *BlobStatusRequest) Reset() {
+	*x = BlobStatusRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_disperser_disperser_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlobStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobStatusRequest) ProtoMessage() {}
+
+func (x *BlobStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_disperser_disperser_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobStatusRequest.ProtoReflect.Descriptor instead.
+func (*BlobStatusRequest) Descriptor() ([]byte, []int) {
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BlobStatusRequest) GetRequestId() []byte {
+	if x != nil {
+		return x.RequestId
+	}
+	return nil
+}
+
+type BlobStatusReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The status of the blob.
+	Status BlobStatus `protobuf:"varint,1,opt,name=status,proto3,enum=disperser.BlobStatus" json:"status,omitempty"`
+	// The blob info needed for clients to confirm the blob against the EigenDA contracts.
+	Info *BlobInfo `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`
+	// The number of times the disperser has retried processing the blob.
+	NumRetries uint32 `protobuf:"varint,3,opt,name=num_retries,json=numRetries,proto3" json:"num_retries,omitempty"`
+	// The number of seconds elapsed since the disperser received the blob.
+	AgeSeconds uint64 `protobuf:"varint,4,opt,name=age_seconds,json=ageSeconds,proto3" json:"age_seconds,omitempty"`
+	// The client_metadata of the DisperseBlobRequest of the blob.
+	ClientMetadata []byte `protobuf:"bytes,5,opt,name=client_metadata,json=clientMetadata,proto3" json:"client_metadata,omitempty"`
+}
+
+func (x *BlobStatusReply) Reset() {
+	*x = BlobStatusReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_disperser_disperser_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlobStatusReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobStatusReply) ProtoMessage() {}
+
+func (x *BlobStatusReply) ProtoReflect() protoreflect.Message {
+	mi := &file_disperser_disperser_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobStatusReply.ProtoReflect.Descriptor instead.
+func (*BlobStatusReply) Descriptor() ([]byte, []int) {
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BlobStatusReply) GetStatus() BlobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return BlobStatus_UNKNOWN
+}
+
+func (x *BlobStatusReply) GetInfo() *BlobInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *BlobStatusReply) GetNumRetries() uint32 {
+	if x != nil {
+		return x.NumRetries
+	}
+	return 0
+}
+
+func (x *BlobStatusReply) GetAgeSeconds() uint64 {
+	if x != nil {
+		return x.AgeSeconds
+	}
+	return 0
+}
+
+func (x *BlobStatusReply) GetClientMetadata() []byte {
+	if x != nil {
+		return x.ClientMetadata
+	}
+	return nil
+}
+
+// RetrieveBlobRequest contains parameters to retrieve the blob.
+type RetrieveBlobRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BatchHeaderHash []byte `protobuf:"bytes,1,opt,name=batch_header_hash,json=batchHeaderHash,proto3" json:"batch_header_hash,omitempty"`
+	BlobIndex       uint32 `protobuf:"varint,2,opt,name=blob_index,json=blobIndex,proto3" json:"blob_index,omitempty"`
+}
+
+func (x *RetrieveBlobRequest) Reset() {
+	*x = RetrieveBlobRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_disperser_disperser_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RetrieveBlobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetrieveBlobRequest) ProtoMessage() {}
+
+func (x *RetrieveBlobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_disperser_disperser_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetrieveBlobRequest.ProtoReflect.Descriptor instead.
+func (*RetrieveBlobRequest) Descriptor() ([]byte, []int) {
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RetrieveBlobRequest) GetBatchHeaderHash() []byte {
+	if x != nil {
+		return x.BatchHeaderHash
+	}
+	return nil
+}
+
+func (x *RetrieveBlobRequest) GetBlobIndex() uint32 {
+	if x != nil {
+		return x.BlobIndex
+	}
+	return 0
+}
+
+// RetrieveBlobReply contains the retrieved blob data
+type RetrieveBlobReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *RetrieveBlobReply) Reset() {
+	*x = RetrieveBlobReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_disperser_disperser_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RetrieveBlobReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetrieveBlobReply) ProtoMessage() {}
+
+func (x *RetrieveBlobReply) ProtoReflect() protoreflect.Message {
+	mi := &file_disperser_disperser_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetrieveBlobReply.ProtoReflect.Descriptor instead.
+func (*RetrieveBlobReply) Descriptor() ([]byte, []int) {
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RetrieveBlobReply) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// BlobEncodingParamsRequest is used to query the encoding params of a confirmed blob.
+type BlobEncodingParamsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId []byte `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+}
+
+func (x *BlobEncodingParamsRequest) Reset() {
+	*x = BlobEncodingParamsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_disperser_disperser_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlobEncodingParamsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobEncodingParamsRequest) ProtoMessage() {}
+
+func (x *BlobEncodingParamsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_disperser_disperser_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobEncodingParamsRequest.ProtoReflect.Descriptor instead.
+func (*BlobEncodingParamsRequest) Descriptor() ([]byte, []int) {
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *BlobEncodingParamsRequest) GetRequestId() []byte {
+	if x != nil {
+		return x.RequestId
+	}
+	return nil
+}
+
+// BlobEncodingParamsReply contains the encoding params of each quorum of the blob.
+type BlobEncodingParamsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The encoding params of each quorum of the blob, sorted by quorum number.
+	QuorumEncodingParams []*QuorumEncodingParams `protobuf:"bytes,1,rep,name=quorum_encoding_params,json=quorumEncodingParams,proto3" json:"quorum_encoding_params,omitempty"`
+}
+
+func (x *BlobEncodingParamsReply) Reset() {
+	*x = BlobEncodingParamsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_disperser_disperser_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlobEncodingParamsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobEncodingParamsReply) ProtoMessage() {}
+
+func (x *BlobEncodingParamsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_disperser_disperser_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobEncodingParamsReply.ProtoReflect.Descriptor instead.
+func (*BlobEncodingParamsReply) Descriptor() ([]byte, []int) {
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *BlobEncodingParamsReply) GetQuorumEncodingParams() []*QuorumEncodingParams {
+	if x != nil {
+		return x.QuorumEncodingParams
+	}
+	return nil
+}
+
+// EstimateConfirmationRequest is used to query the estimated time to confirmation of a blob.
+type EstimateConfirmationRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId []byte `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+}
+
+func (x *EstimateConfirmationRequest) Reset() {
+	*x = EstimateConfirmationRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_disperser_disperser_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EstimateConfirmationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EstimateConfirmationRequest) ProtoMessage() {}
+
+func (x *EstimateConfirmationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_disperser_disperser_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EstimateConfirmationRequest.ProtoReflect.Descriptor instead.
+func (*EstimateConfirmationRequest) Descriptor() ([]byte, []int) {
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *EstimateConfirmationRequest) GetRequestId() []byte {
+	if x != nil {
+		return x.RequestId
+	}
+	return nil
+}
+
+// EstimateConfirmationReply contains the estimated time to confirmation of a blob.
+type EstimateConfirmationReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The estimated number of seconds until the blob is confirmed. This is 0 if the blob
+	// is already confirmed.
+	EstimatedSeconds uint64 `protobuf:"varint,1,opt,name=estimated_seconds,json=estimatedSeconds,proto3" json:"estimated_seconds,omitempty"`
+	// The number of blobs waiting to be processed that were requested no later than
+	// this blob, including the blob itself. This is 0 if the blob is no longer queued.
+	QueuePosition uint32 `protobuf:"varint,2,opt,name=queue_position,json=queuePosition,proto3" json:"queue_position,omitempty"`
+}
+
+func (x *EstimateConfirmationReply) Reset() {
+	*x = EstimateConfirmationReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_disperser_disperser_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EstimateConfirmationReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EstimateConfirmationReply) ProtoMessage() {}
+
+func (x *EstimateConfirmationReply) ProtoReflect() protoreflect.Message {
+	mi := &file_disperser_disperser_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EstimateConfirmationReply.ProtoReflect.Descriptor instead.
+func (*EstimateConfirmationReply) Descriptor() ([]byte, []int) {
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *EstimateConfirmationReply) GetEstimatedSeconds() uint64 {
+	if x != nil {
+		return x.EstimatedSeconds
+	}
+	return 0
+}
+
+func (x *EstimateConfirmationReply) GetQueuePosition() uint32 {
+	if x != nil {
+		return x.QueuePosition
+	}
+	return 0
+}
+
+// BlobByCommitmentRequest is used to query the confirmed blobs with a given commitment.
+type BlobByCommitmentRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	RequestId []byte `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// The KZG commitment of the blobs.
+	Commitment *common.G1Commitment `protobuf:"bytes,1,opt,name=commitment,proto3" json:"commitment,omitempty"`
+	// The maximum number of blobs to return. The disperser caps it, and uses its cap if it's 0.
+	Limit uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// The next_pagination_token of the previous reply, or empty for the first page.
+	PaginationToken []byte `protobuf:"bytes,3,opt,name=pagination_token,json=paginationToken,proto3" json:"pagination_token,omitempty"`
 }
 
-func (x *BlobStatusRequest) Reset() {
-	*x = BlobStatusRequest{}
+func (x *BlobByCommitmentRequest) Reset() {
+	*x = BlobByCommitmentRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_disperser_disperser_proto_msgTypes[6]
+		mi := &file_disperser_disperser_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *BlobStatusRequest) String() string {
+func (x *BlobByCommitmentRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BlobStatusRequest) ProtoMessage() {}
+func (*BlobByCommitmentRequest) ProtoMessage() {}
 
-func (x *BlobStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_disperser_disperser_proto_msgTypes[6]
+func (x *BlobByCommitmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_disperser_disperser_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -524,46 +1153,62 @@ func (x *BlobStatusRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BlobStatusRequest.ProtoReflect.Descriptor instead.
-func (*BlobStatusRequest) Descriptor() ([]byte, []int) {
-	return file_disperser_disperser_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use BlobByCommitmentRequest.ProtoReflect.Descriptor instead.
+func (*BlobByCommitmentRequest) Descriptor() ([]byte, []int) {
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *BlobStatusRequest) GetRequestId() []byte {
+func (x *BlobByCommitmentRequest) GetCommitment() *common.G1Commitment {
 	if x != nil {
-		return x.RequestId
+		return x.Commitment
 	}
 	return nil
 }
 
-type BlobStatusReply struct {
+func (x *BlobByCommitmentRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *BlobByCommitmentRequest) GetPaginationToken() []byte {
+	if x != nil {
+		return x.PaginationToken
+	}
+	return nil
+}
+
+// BlobByCommitmentReply contains a page of the confirmed blobs with a given commitment.
+type BlobByCommitmentReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The status of the blob.
-	Status BlobStatus `protobuf:"varint,1,opt,name=status,proto3,enum=disperser.BlobStatus" json:"status,omitempty"`
-	// The blob info needed for clients to confirm the blob against the EigenDA contracts.
-	Info *BlobInfo `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`
+	// The confirmed blobs in the page. A page may have fewer blobs than the limit even if
+	// it's not the last one.
+	Blobs []*CommittedBlob `protobuf:"bytes,1,rep,name=blobs,proto3" json:"blobs,omitempty"`
+	// The token to request the next page with, or empty if this is the last page.
+	NextPaginationToken []byte `protobuf:"bytes,2,opt,name=next_pagination_token,json=nextPaginationToken,proto3" json:"next_pagination_token,omitempty"`
 }
 
-func (x *BlobStatusReply) Reset() {
-	*x = BlobStatusReply{}
+func (x *BlobByCommitmentReply) Reset() {
+	*x = BlobByCommitmentReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_disperser_disperser_proto_msgTypes[7]
+		mi := &file_disperser_disperser_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *BlobStatusReply) String() string {
+func (x *BlobByCommitmentReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BlobStatusReply) ProtoMessage() {}
+func (*BlobByCommitmentReply) ProtoMessage() {}
 
-func (x *BlobStatusReply) ProtoReflect() protoreflect.Message {
-	mi := &file_disperser_disperser_proto_msgTypes[7]
+func (x *BlobByCommitmentReply) ProtoReflect() protoreflect.Message {
+	mi := &file_disperser_disperser_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -574,52 +1219,56 @@ func (x *BlobStatusReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BlobStatusReply.ProtoReflect.Descriptor instead.
-func (*BlobStatusReply) Descriptor() ([]byte, []int) {
-	return file_disperser_disperser_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use BlobByCommitmentReply.ProtoReflect.Descriptor instead.
+func (*BlobByCommitmentReply) Descriptor() ([]byte, []int) {
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *BlobStatusReply) GetStatus() BlobStatus {
+func (x *BlobByCommitmentReply) GetBlobs() []*CommittedBlob {
 	if x != nil {
-		return x.Status
+		return x.Blobs
 	}
-	return BlobStatus_UNKNOWN
+	return nil
 }
 
-func (x *BlobStatusReply) GetInfo() *BlobInfo {
+func (x *BlobByCommitmentReply) GetNextPaginationToken() []byte {
 	if x != nil {
-		return x.Info
+		return x.NextPaginationToken
 	}
 	return nil
 }
 
-// RetrieveBlobRequest contains parameters to retrieve the blob.
-type RetrieveBlobRequest struct {
+// CommittedBlob is a confirmed blob returned by GetBlobByCommitment.
+type CommittedBlob struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	BatchHeaderHash []byte `protobuf:"bytes,1,opt,name=batch_header_hash,json=batchHeaderHash,proto3" json:"batch_header_hash,omitempty"`
-	BlobIndex       uint32 `protobuf:"varint,2,opt,name=blob_index,json=blobIndex,proto3" json:"blob_index,omitempty"`
+	// The request_id of the DisperseBlobReply of the blob.
+	RequestId []byte `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// The status of the blob.
+	Status BlobStatus `protobuf:"varint,2,opt,name=status,proto3,enum=disperser.BlobStatus" json:"status,omitempty"`
+	// The blob info needed for clients to confirm the blob against the EigenDA contracts.
+	Info *BlobInfo `protobuf:"bytes,3,opt,name=info,proto3" json:"info,omitempty"`
 }
 
-func (x *RetrieveBlobRequest) Reset() {
-	*x = RetrieveBlobRequest{}
+func (x *CommittedBlob) Reset() {
+	*x = CommittedBlob{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_disperser_disperser_proto_msgTypes[8]
+		mi := &file_disperser_disperser_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *RetrieveBlobRequest) String() string {
+func (x *CommittedBlob) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RetrieveBlobRequest) ProtoMessage() {}
+func (*CommittedBlob) ProtoMessage() {}
 
-func (x *RetrieveBlobRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_disperser_disperser_proto_msgTypes[8]
+func (x *CommittedBlob) ProtoReflect() protoreflect.Message {
+	mi := &file_disperser_disperser_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -630,51 +1279,65 @@ func (x *RetrieveBlobRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RetrieveBlobRequest.ProtoReflect.Descriptor instead.
-func (*RetrieveBlobRequest) Descriptor() ([]byte, []int) {
-	return file_disperser_disperser_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use CommittedBlob.ProtoReflect.Descriptor instead.
+func (*CommittedBlob) Descriptor() ([]byte, []int) {
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *RetrieveBlobRequest) GetBatchHeaderHash() []byte {
+func (x *CommittedBlob) GetRequestId() []byte {
 	if x != nil {
-		return x.BatchHeaderHash
+		return x.RequestId
 	}
 	return nil
 }
 
-func (x *RetrieveBlobRequest) GetBlobIndex() uint32 {
+func (x *CommittedBlob) GetStatus() BlobStatus {
 	if x != nil {
-		return x.BlobIndex
+		return x.Status
 	}
-	return 0
+	return BlobStatus_UNKNOWN
 }
 
-// RetrieveBlobReply contains the retrieved blob data
-type RetrieveBlobReply struct {
+func (x *CommittedBlob) GetInfo() *BlobInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+// QuorumEncodingParams contains the params used to encode a blob for a given quorum.
+type QuorumEncodingParams struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// The ID of the quorum.
+	QuorumNumber uint32 `protobuf:"varint,1,opt,name=quorum_number,json=quorumNumber,proto3" json:"quorum_number,omitempty"`
+	// The length of each chunk in symbols.
+	ChunkLength uint32 `protobuf:"varint,2,opt,name=chunk_length,json=chunkLength,proto3" json:"chunk_length,omitempty"`
+	// The number of chunks the blob is encoded into.
+	NumChunks uint32 `protobuf:"varint,3,opt,name=num_chunks,json=numChunks,proto3" json:"num_chunks,omitempty"`
+	// The total number of chunks assigned to the operators of the quorum.
+	TotalChunks uint32 `protobuf:"varint,4,opt,name=total_chunks,json=totalChunks,proto3" json:"total_chunks,omitempty"`
 }
 
-func (x *RetrieveBlobReply) Reset() {
-	*x = RetrieveBlobReply{}
+func (x *QuorumEncodingParams) Reset() {
+	*x = QuorumEncodingParams{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_disperser_disperser_proto_msgTypes[9]
+		mi := &file_disperser_disperser_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *RetrieveBlobReply) String() string {
+func (x *QuorumEncodingParams) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RetrieveBlobReply) ProtoMessage() {}
+func (*QuorumEncodingParams) ProtoMessage() {}
 
-func (x *RetrieveBlobReply) ProtoReflect() protoreflect.Message {
-	mi := &file_disperser_disperser_proto_msgTypes[9]
+func (x *QuorumEncodingParams) ProtoReflect() protoreflect.Message {
+	mi := &file_disperser_disperser_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -685,16 +1348,37 @@ func (x *RetrieveBlobReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RetrieveBlobReply.ProtoReflect.Descriptor instead.
-func (*RetrieveBlobReply) Descriptor() ([]byte, []int) {
-	return file_disperser_disperser_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use QuorumEncodingParams.ProtoReflect.Descriptor instead.
+func (*QuorumEncodingParams) Descriptor() ([]byte, []int) {
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *RetrieveBlobReply) GetData() []byte {
+func (x *QuorumEncodingParams) GetQuorumNumber() uint32 {
 	if x != nil {
-		return x.Data
+		return x.QuorumNumber
 	}
-	return nil
+	return 0
+}
+
+func (x *QuorumEncodingParams) GetChunkLength() uint32 {
+	if x != nil {
+		return x.ChunkLength
+	}
+	return 0
+}
+
+func (x *QuorumEncodingParams) GetNumChunks() uint32 {
+	if x != nil {
+		return x.NumChunks
+	}
+	return 0
+}
+
+func (x *QuorumEncodingParams) GetTotalChunks() uint32 {
+	if x != nil {
+		return x.TotalChunks
+	}
+	return 0
 }
 
 // SecurityParams contains the security parameters for a given quorum.
@@ -737,7 +1421,7 @@ type SecurityParams struct {
 func (x *SecurityParams) Reset() {
 	*x = SecurityParams{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_disperser_disperser_proto_msgTypes[10]
+		mi := &file_disperser_disperser_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -750,7 +1434,7 @@ func (x *SecurityParams) String() string {
 func (*SecurityParams) ProtoMessage() {}
 
 func (x *SecurityParams) ProtoReflect() protoreflect.Message {
-	mi := &file_disperser_disperser_proto_msgTypes[10]
+	mi := &file_disperser_disperser_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -763,7 +1447,7 @@ func (x *SecurityParams) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SecurityParams.ProtoReflect.Descriptor instead.
 func (*SecurityParams) Descriptor() ([]byte, []int) {
-	return file_disperser_disperser_proto_rawDescGZIP(), []int{10}
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *SecurityParams) GetQuorumId() uint32 {
@@ -800,7 +1484,7 @@ type BlobInfo struct {
 func (x *BlobInfo) Reset() {
 	*x = BlobInfo{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_disperser_disperser_proto_msgTypes[11]
+		mi := &file_disperser_disperser_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -813,7 +1497,7 @@ func (x *BlobInfo) String() string {
 func (*BlobInfo) ProtoMessage() {}
 
 func (x *BlobInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_disperser_disperser_proto_msgTypes[11]
+	mi := &file_disperser_disperser_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -826,7 +1510,7 @@ func (x *BlobInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlobInfo.ProtoReflect.Descriptor instead.
 func (*BlobInfo) Descriptor() ([]byte, []int) {
-	return file_disperser_disperser_proto_rawDescGZIP(), []int{11}
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *BlobInfo) GetBlobHeader() *BlobHeader {
@@ -859,7 +1543,7 @@ type BlobHeader struct {
 func (x *BlobHeader) Reset() {
 	*x = BlobHeader{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_disperser_disperser_proto_msgTypes[12]
+		mi := &file_disperser_disperser_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -872,7 +1556,7 @@ func (x *BlobHeader) String() string {
 func (*BlobHeader) ProtoMessage() {}
 
 func (x *BlobHeader) ProtoReflect() protoreflect.Message {
-	mi := &file_disperser_disperser_proto_msgTypes[12]
+	mi := &file_disperser_disperser_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -885,7 +1569,7 @@ func (x *BlobHeader) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlobHeader.ProtoReflect.Descriptor instead.
 func (*BlobHeader) Descriptor() ([]byte, []int) {
-	return file_disperser_disperser_proto_rawDescGZIP(), []int{12}
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *BlobHeader) GetCommitment() *common.G1Commitment {
@@ -927,7 +1611,7 @@ type BlobQuorumParam struct {
 func (x *BlobQuorumParam) Reset() {
 	*x = BlobQuorumParam{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_disperser_disperser_proto_msgTypes[13]
+		mi := &file_disperser_disperser_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -940,7 +1624,7 @@ func (x *BlobQuorumParam) String() string {
 func (*BlobQuorumParam) ProtoMessage() {}
 
 func (x *BlobQuorumParam) ProtoReflect() protoreflect.Message {
-	mi := &file_disperser_disperser_proto_msgTypes[13]
+	mi := &file_disperser_disperser_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -953,7 +1637,7 @@ func (x *BlobQuorumParam) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlobQuorumParam.ProtoReflect.Descriptor instead.
 func (*BlobQuorumParam) Descriptor() ([]byte, []int) {
-	return file_disperser_disperser_proto_rawDescGZIP(), []int{13}
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *BlobQuorumParam) GetQuorumNumber() uint32 {
@@ -1021,7 +1705,7 @@ type BlobVerificationProof struct {
 func (x *BlobVerificationProof) Reset() {
 	*x = BlobVerificationProof{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_disperser_disperser_proto_msgTypes[14]
+		mi := &file_disperser_disperser_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1034,7 +1718,7 @@ func (x *BlobVerificationProof) String() string {
 func (*BlobVerificationProof) ProtoMessage() {}
 
 func (x *BlobVerificationProof) ProtoReflect() protoreflect.Message {
-	mi := &file_disperser_disperser_proto_msgTypes[14]
+	mi := &file_disperser_disperser_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1047,7 +1731,7 @@ func (x *BlobVerificationProof) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlobVerificationProof.ProtoReflect.Descriptor instead.
 func (*BlobVerificationProof) Descriptor() ([]byte, []int) {
-	return file_disperser_disperser_proto_rawDescGZIP(), []int{14}
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *BlobVerificationProof) GetBatchId() uint32 {
@@ -1107,7 +1791,7 @@ type BatchMetadata struct {
 func (x *BatchMetadata) Reset() {
 	*x = BatchMetadata{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_disperser_disperser_proto_msgTypes[15]
+		mi := &file_disperser_disperser_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1120,7 +1804,7 @@ func (x *BatchMetadata) String() string {
 func (*BatchMetadata) ProtoMessage() {}
 
 func (x *BatchMetadata) ProtoReflect() protoreflect.Message {
-	mi := &file_disperser_disperser_proto_msgTypes[15]
+	mi := &file_disperser_disperser_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1133,7 +1817,7 @@ func (x *BatchMetadata) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchMetadata.ProtoReflect.Descriptor instead.
 func (*BatchMetadata) Descriptor() ([]byte, []int) {
-	return file_disperser_disperser_proto_rawDescGZIP(), []int{15}
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *BatchMetadata) GetBatchHeader() *BatchHeader {
@@ -1193,7 +1877,7 @@ type BatchHeader struct {
 func (x *BatchHeader) Reset() {
 	*x = BatchHeader{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_disperser_disperser_proto_msgTypes[16]
+		mi := &file_disperser_disperser_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1206,7 +1890,7 @@ func (x *BatchHeader) String() string {
 func (*BatchHeader) ProtoMessage() {}
 
 func (x *BatchHeader) ProtoReflect() protoreflect.Message {
-	mi := &file_disperser_disperser_proto_msgTypes[16]
+	mi := &file_disperser_disperser_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1219,7 +1903,7 @@ func (x *BatchHeader) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchHeader.ProtoReflect.Descriptor instead.
 func (*BatchHeader) Descriptor() ([]byte, []int) {
-	return file_disperser_disperser_proto_rawDescGZIP(), []int{16}
+	return file_disperser_disperser_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *BatchHeader) GetBatchRoot() []byte {
@@ -1288,7 +1972,7 @@ var file_disperser_disperser_proto_rawDesc = []byte{
 	0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x12, 0x2f, 0x0a, 0x13, 0x61, 0x75, 0x74, 0x68, 0x65,
 	0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01,
 	0x20, 0x01, 0x28, 0x0c, 0x52, 0x12, 0x61, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x22, 0x8c, 0x01, 0x0a, 0x13, 0x44, 0x69, 0x73,
+	0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x22, 0xb6, 0x02, 0x0a, 0x13, 0x44, 0x69, 0x73,
 	0x70, 0x65, 0x72, 0x73, 0x65, 0x42, 0x6c, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
 	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
 	0x64, 0x61, 0x74, 0x61, 0x12, 0x42, 0x0a, 0x0f, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79,
@@ -1297,146 +1981,258 @@ var file_disperser_disperser_proto_rawDesc = []byte{
 	0x74, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x0e, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69,
 	0x74, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f,
 	0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x63,
-	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x22, 0x61, 0x0a, 0x11, 0x44, 0x69, 0x73, 0x70, 0x65,
-	0x72, 0x73, 0x65, 0x42, 0x6c, 0x6f, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2d, 0x0a, 0x06,
-	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x64,
-	0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x22, 0x32, 0x0a, 0x11, 0x42, 0x6c,
-	0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x22, 0x69,
-	0x0a, 0x0f, 0x42, 0x6c, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x70, 0x6c,
-	0x79, 0x12, 0x2d, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0e, 0x32, 0x15, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c,
-	0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x12, 0x27, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
-	0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x49,
-	0x6e, 0x66, 0x6f, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x22, 0x60, 0x0a, 0x13, 0x52, 0x65, 0x74,
-	0x72, 0x69, 0x65, 0x76, 0x65, 0x42, 0x6c, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x2a, 0x0a, 0x11, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72,
-	0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0f, 0x62, 0x61, 0x74,
-	0x63, 0x68, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1d, 0x0a, 0x0a,
-	0x62, 0x6c, 0x6f, 0x62, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x09, 0x62, 0x6c, 0x6f, 0x62, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x22, 0x27, 0x0a, 0x11, 0x52,
-	0x65, 0x74, 0x72, 0x69, 0x65, 0x76, 0x65, 0x42, 0x6c, 0x6f, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79,
-	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
-	0x64, 0x61, 0x74, 0x61, 0x22, 0x89, 0x01, 0x0a, 0x0e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74,
-	0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x71, 0x75, 0x6f, 0x72, 0x75,
-	0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x71, 0x75, 0x6f, 0x72,
-	0x75, 0x6d, 0x49, 0x64, 0x12, 0x2f, 0x0a, 0x13, 0x61, 0x64, 0x76, 0x65, 0x72, 0x73, 0x61, 0x72,
-	0x79, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x12, 0x61, 0x64, 0x76, 0x65, 0x72, 0x73, 0x61, 0x72, 0x79, 0x54, 0x68, 0x72, 0x65,
-	0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x5f,
-	0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x0f, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64,
-	0x22, 0x9c, 0x01, 0x0a, 0x08, 0x42, 0x6c, 0x6f, 0x62, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x36, 0x0a,
-	0x0b, 0x62, 0x6c, 0x6f, 0x62, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42,
-	0x6c, 0x6f, 0x62, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x0a, 0x62, 0x6c, 0x6f, 0x62, 0x48,
-	0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x58, 0x0a, 0x17, 0x62, 0x6c, 0x6f, 0x62, 0x5f, 0x76, 0x65,
-	0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x70, 0x72, 0x6f, 0x6f, 0x66,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73,
-	0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x52, 0x15, 0x62, 0x6c, 0x6f, 0x62, 0x56, 0x65,
-	0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x22,
-	0xad, 0x01, 0x0a, 0x0a, 0x42, 0x6c, 0x6f, 0x62, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x34,
-	0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x47, 0x31, 0x43, 0x6f,
-	0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74,
-	0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x6c, 0x65, 0x6e,
-	0x67, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x64, 0x61, 0x74, 0x61, 0x4c,
-	0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x48, 0x0a, 0x12, 0x62, 0x6c, 0x6f, 0x62, 0x5f, 0x71, 0x75,
-	0x6f, 0x72, 0x75, 0x6d, 0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x1a, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c,
-	0x6f, 0x62, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x52, 0x10, 0x62,
-	0x6c, 0x6f, 0x62, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x22,
-	0xdf, 0x01, 0x0a, 0x0f, 0x42, 0x6c, 0x6f, 0x62, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x50, 0x61,
-	0x72, 0x61, 0x6d, 0x12, 0x23, 0x0a, 0x0d, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x5f, 0x6e, 0x75,
-	0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x71, 0x75, 0x6f, 0x72,
-	0x75, 0x6d, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x44, 0x0a, 0x1e, 0x61, 0x64, 0x76, 0x65,
-	0x72, 0x73, 0x61, 0x72, 0x79, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x5f,
-	0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x1c, 0x61, 0x64, 0x76, 0x65, 0x72, 0x73, 0x61, 0x72, 0x79, 0x54, 0x68, 0x72, 0x65, 0x73,
-	0x68, 0x6f, 0x6c, 0x64, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x12, 0x3e,
-	0x0a, 0x1b, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f,
-	0x6c, 0x64, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x0d, 0x52, 0x19, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x54, 0x68, 0x72, 0x65, 0x73,
-	0x68, 0x6f, 0x6c, 0x64, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x12, 0x21,
-	0x0a, 0x0c, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x04,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x64, 0x65, 0x6d, 0x70,
+	0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0e, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4b, 0x65, 0x79,
+	0x12, 0x33, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x17, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42,
+	0x6c, 0x6f, 0x62, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x52, 0x08, 0x70, 0x72, 0x69,
+	0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f,
+	0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e,
+	0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x21,
+	0x0a, 0x0c, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x07,
 	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x4c, 0x65, 0x6e, 0x67, 0x74,
-	0x68, 0x22, 0xe2, 0x01, 0x0a, 0x15, 0x42, 0x6c, 0x6f, 0x62, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69,
-	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x19, 0x0a, 0x08, 0x62,
-	0x61, 0x74, 0x63, 0x68, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x62,
-	0x61, 0x74, 0x63, 0x68, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6c, 0x6f, 0x62, 0x5f, 0x69,
-	0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x62,
-	0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x3f, 0x0a, 0x0e, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x6d,
-	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e,
-	0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x4d,
-	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x0d, 0x62, 0x61, 0x74, 0x63, 0x68, 0x4d, 0x65,
-	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x73,
-	0x69, 0x6f, 0x6e, 0x5f, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x0e, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12,
-	0x25, 0x0a, 0x0e, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65,
-	0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x49,
-	0x6e, 0x64, 0x65, 0x78, 0x65, 0x73, 0x22, 0xf8, 0x01, 0x0a, 0x0d, 0x42, 0x61, 0x74, 0x63, 0x68,
-	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x39, 0x0a, 0x0c, 0x62, 0x61, 0x74, 0x63,
-	0x68, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x68, 0x22, 0x93, 0x01, 0x0a, 0x11, 0x44, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x42, 0x6c,
+	0x6f, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2d, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72,
+	0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x30, 0x0a, 0x07, 0x72, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73,
+	0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x52, 0x07,
+	0x72, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x22, 0x6d, 0x0a, 0x0b, 0x42, 0x6c, 0x6f, 0x62, 0x52,
+	0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x32, 0x0a, 0x11, 0x42, 0x6c, 0x6f, 0x62, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x22, 0xd4, 0x01, 0x0a, 0x0f, 0x42,
+	0x6c, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2d,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15,
+	0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x27, 0x0a,
+	0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x64, 0x69,
+	0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x49, 0x6e, 0x66, 0x6f,
+	0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x75, 0x6d, 0x5f, 0x72, 0x65,
+	0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6e, 0x75, 0x6d,
+	0x52, 0x65, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x67, 0x65, 0x5f, 0x73,
+	0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x61, 0x67,
+	0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0x22, 0x60, 0x0a, 0x13, 0x52, 0x65, 0x74, 0x72, 0x69, 0x65, 0x76, 0x65, 0x42, 0x6c, 0x6f,
+	0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x11, 0x62, 0x61, 0x74, 0x63,
+	0x68, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x0f, 0x62, 0x61, 0x74, 0x63, 0x68, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x48, 0x61, 0x73, 0x68, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6c, 0x6f, 0x62, 0x5f, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x62, 0x49, 0x6e,
+	0x64, 0x65, 0x78, 0x22, 0x27, 0x0a, 0x11, 0x52, 0x65, 0x74, 0x72, 0x69, 0x65, 0x76, 0x65, 0x42,
+	0x6c, 0x6f, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x3a, 0x0a, 0x19,
+	0x42, 0x6c, 0x6f, 0x62, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x72, 0x61,
+	0x6d, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x22, 0x70, 0x0a, 0x17, 0x42, 0x6c, 0x6f, 0x62,
+	0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x55, 0x0a, 0x16, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x5f, 0x65, 0x6e,
+	0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e,
+	0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x61,
+	0x72, 0x61, 0x6d, 0x73, 0x52, 0x14, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x45, 0x6e, 0x63, 0x6f,
+	0x64, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x22, 0x3c, 0x0a, 0x1b, 0x45, 0x73,
+	0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x22, 0x6f, 0x0a, 0x19, 0x45, 0x73, 0x74, 0x69,
+	0x6d, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2b, 0x0a, 0x11, 0x65, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74,
+	0x65, 0x64, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x10, 0x65, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x64, 0x53, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x70, 0x6f, 0x73, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x71, 0x75, 0x65, 0x75,
+	0x65, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x90, 0x01, 0x0a, 0x17, 0x42, 0x6c,
+	0x6f, 0x62, 0x42, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x34, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d,
+	0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x63, 0x6f, 0x6d, 0x6d,
+	0x6f, 0x6e, 0x2e, 0x47, 0x31, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x52,
+	0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69,
+	0x74, 0x12, 0x29, 0x0a, 0x10, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0f, 0x70, 0x61, 0x67,
+	0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x7b, 0x0a, 0x15,
+	0x42, 0x6c, 0x6f, 0x62, 0x42, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2e, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x62, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72,
+	0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x42, 0x6c, 0x6f, 0x62, 0x52, 0x05,
+	0x62, 0x6c, 0x6f, 0x62, 0x73, 0x12, 0x32, 0x0a, 0x15, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61,
+	0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x13, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x69, 0x6e, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x86, 0x01, 0x0a, 0x0d, 0x43, 0x6f,
+	0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x42, 0x6c, 0x6f, 0x62, 0x12, 0x1d, 0x0a, 0x0a, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x2d, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x64, 0x69, 0x73,
+	0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x27, 0x0a, 0x04, 0x69, 0x6e, 0x66,
+	0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72,
+	0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x69, 0x6e,
+	0x66, 0x6f, 0x22, 0xa0, 0x01, 0x0a, 0x14, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x45, 0x6e, 0x63,
+	0x6f, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x71,
+	0x75, 0x6f, 0x72, 0x75, 0x6d, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0c, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72,
+	0x12, 0x21, 0x0a, 0x0c, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x4c, 0x65, 0x6e,
+	0x67, 0x74, 0x68, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x75, 0x6d, 0x5f, 0x63, 0x68, 0x75, 0x6e, 0x6b,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x6e, 0x75, 0x6d, 0x43, 0x68, 0x75, 0x6e,
+	0x6b, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x68, 0x75, 0x6e,
+	0x6b, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43,
+	0x68, 0x75, 0x6e, 0x6b, 0x73, 0x22, 0x89, 0x01, 0x0a, 0x0e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69,
+	0x74, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x71, 0x75, 0x6f, 0x72,
+	0x75, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x71, 0x75, 0x6f,
+	0x72, 0x75, 0x6d, 0x49, 0x64, 0x12, 0x2f, 0x0a, 0x13, 0x61, 0x64, 0x76, 0x65, 0x72, 0x73, 0x61,
+	0x72, 0x79, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x12, 0x61, 0x64, 0x76, 0x65, 0x72, 0x73, 0x61, 0x72, 0x79, 0x54, 0x68, 0x72,
+	0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d,
+	0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0f, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c,
+	0x64, 0x22, 0x9c, 0x01, 0x0a, 0x08, 0x42, 0x6c, 0x6f, 0x62, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x36,
+	0x0a, 0x0b, 0x62, 0x6c, 0x6f, 0x62, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e,
+	0x42, 0x6c, 0x6f, 0x62, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x0a, 0x62, 0x6c, 0x6f, 0x62,
+	0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x58, 0x0a, 0x17, 0x62, 0x6c, 0x6f, 0x62, 0x5f, 0x76,
+	0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x70, 0x72, 0x6f, 0x6f,
+	0x66, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72,
+	0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x52, 0x15, 0x62, 0x6c, 0x6f, 0x62, 0x56,
+	0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x6f, 0x6f, 0x66,
+	0x22, 0xad, 0x01, 0x0a, 0x0a, 0x42, 0x6c, 0x6f, 0x62, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12,
+	0x34, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x47, 0x31, 0x43,
+	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x69,
+	0x74, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x6c, 0x65,
+	0x6e, 0x67, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x64, 0x61, 0x74, 0x61,
+	0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x48, 0x0a, 0x12, 0x62, 0x6c, 0x6f, 0x62, 0x5f, 0x71,
+	0x75, 0x6f, 0x72, 0x75, 0x6d, 0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42,
+	0x6c, 0x6f, 0x62, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x52, 0x10,
+	0x62, 0x6c, 0x6f, 0x62, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x22, 0xdf, 0x01, 0x0a, 0x0f, 0x42, 0x6c, 0x6f, 0x62, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x50,
+	0x61, 0x72, 0x61, 0x6d, 0x12, 0x23, 0x0a, 0x0d, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x5f, 0x6e,
+	0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x71, 0x75, 0x6f,
+	0x72, 0x75, 0x6d, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x44, 0x0a, 0x1e, 0x61, 0x64, 0x76,
+	0x65, 0x72, 0x73, 0x61, 0x72, 0x79, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64,
+	0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x1c, 0x61, 0x64, 0x76, 0x65, 0x72, 0x73, 0x61, 0x72, 0x79, 0x54, 0x68, 0x72, 0x65,
+	0x73, 0x68, 0x6f, 0x6c, 0x64, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x12,
+	0x3e, 0x0a, 0x1b, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68,
+	0x6f, 0x6c, 0x64, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x19, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x54, 0x68, 0x72, 0x65,
+	0x73, 0x68, 0x6f, 0x6c, 0x64, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x12,
+	0x21, 0x0a, 0x0c, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x4c, 0x65, 0x6e, 0x67,
+	0x74, 0x68, 0x22, 0xe2, 0x01, 0x0a, 0x15, 0x42, 0x6c, 0x6f, 0x62, 0x56, 0x65, 0x72, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x19, 0x0a, 0x08,
+	0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07,
+	0x62, 0x61, 0x74, 0x63, 0x68, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6c, 0x6f, 0x62, 0x5f,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x62, 0x6c, 0x6f,
+	0x62, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x3f, 0x0a, 0x0e, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f,
+	0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18,
 	0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68,
-	0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x0b, 0x62, 0x61, 0x74, 0x63, 0x68, 0x48, 0x65, 0x61,
-	0x64, 0x65, 0x72, 0x12, 0x32, 0x0a, 0x15, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x79,
-	0x5f, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0c, 0x52, 0x13, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x63,
-	0x6f, 0x72, 0x64, 0x48, 0x61, 0x73, 0x68, 0x12, 0x10, 0x0a, 0x03, 0x66, 0x65, 0x65, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x66, 0x65, 0x65, 0x12, 0x3a, 0x0a, 0x19, 0x63, 0x6f, 0x6e,
-	0x66, 0x69, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f,
-	0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x17, 0x63, 0x6f,
-	0x6e, 0x66, 0x69, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x4e,
-	0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x2a, 0x0a, 0x11, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x68,
-	0x65, 0x61, 0x64, 0x65, 0x72, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x0f, 0x62, 0x61, 0x74, 0x63, 0x68, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x48, 0x61, 0x73,
-	0x68, 0x22, 0xc5, 0x01, 0x0a, 0x0b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x48, 0x65, 0x61, 0x64, 0x65,
-	0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x62, 0x61, 0x74, 0x63, 0x68, 0x52, 0x6f, 0x6f, 0x74,
-	0x12, 0x25, 0x0a, 0x0e, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65,
-	0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d,
-	0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x12, 0x3a, 0x0a, 0x19, 0x71, 0x75, 0x6f, 0x72, 0x75,
-	0x6d, 0x5f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74,
-	0x61, 0x67, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x17, 0x71, 0x75, 0x6f, 0x72,
-	0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x61,
-	0x67, 0x65, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65,
-	0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x0d, 0x52, 0x14, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x42, 0x6c,
-	0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x2a, 0x70, 0x0a, 0x0a, 0x42, 0x6c, 0x6f,
-	0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f,
-	0x57, 0x4e, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x50, 0x52, 0x4f, 0x43, 0x45, 0x53, 0x53, 0x49,
-	0x4e, 0x47, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x43, 0x4f, 0x4e, 0x46, 0x49, 0x52, 0x4d, 0x45,
-	0x44, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x03, 0x12,
-	0x0d, 0x0a, 0x09, 0x46, 0x49, 0x4e, 0x41, 0x4c, 0x49, 0x5a, 0x45, 0x44, 0x10, 0x04, 0x12, 0x1b,
-	0x0a, 0x17, 0x49, 0x4e, 0x53, 0x55, 0x46, 0x46, 0x49, 0x43, 0x49, 0x45, 0x4e, 0x54, 0x5f, 0x53,
-	0x49, 0x47, 0x4e, 0x41, 0x54, 0x55, 0x52, 0x45, 0x53, 0x10, 0x05, 0x32, 0xd9, 0x02, 0x0a, 0x09,
-	0x44, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x12, 0x4e, 0x0a, 0x0c, 0x44, 0x69, 0x73,
-	0x70, 0x65, 0x72, 0x73, 0x65, 0x42, 0x6c, 0x6f, 0x62, 0x12, 0x1e, 0x2e, 0x64, 0x69, 0x73, 0x70,
-	0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x44, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x42, 0x6c,
-	0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x64, 0x69, 0x73, 0x70,
-	0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x44, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x42, 0x6c,
-	0x6f, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x5f, 0x0a, 0x19, 0x44, 0x69, 0x73,
-	0x70, 0x65, 0x72, 0x73, 0x65, 0x42, 0x6c, 0x6f, 0x62, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74,
-	0x69, 0x63, 0x61, 0x74, 0x65, 0x64, 0x12, 0x1f, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73,
-	0x65, 0x72, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x65, 0x64,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72,
-	0x73, 0x65, 0x72, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x65,
-	0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x28, 0x01, 0x30, 0x01, 0x12, 0x4b, 0x0a, 0x0d, 0x47, 0x65,
-	0x74, 0x42, 0x6c, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1c, 0x2e, 0x64, 0x69,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x0d, 0x62, 0x61, 0x74, 0x63, 0x68, 0x4d,
+	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x6e, 0x63, 0x6c, 0x75,
+	0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x0e, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x6f, 0x6f, 0x66,
+	0x12, 0x25, 0x0a, 0x0e, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d,
+	0x49, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x73, 0x22, 0xf8, 0x01, 0x0a, 0x0d, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x39, 0x0a, 0x0c, 0x62, 0x61, 0x74,
+	0x63, 0x68, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x16, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x0b, 0x62, 0x61, 0x74, 0x63, 0x68, 0x48, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x12, 0x32, 0x0a, 0x15, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x6f, 0x72,
+	0x79, 0x5f, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x13, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x48, 0x61, 0x73, 0x68, 0x12, 0x10, 0x0a, 0x03, 0x66, 0x65, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x66, 0x65, 0x65, 0x12, 0x3a, 0x0a, 0x19, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x17, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x2a, 0x0a, 0x11, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f,
+	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0f, 0x62, 0x61, 0x74, 0x63, 0x68, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x48, 0x61,
+	0x73, 0x68, 0x22, 0xc5, 0x01, 0x0a, 0x0b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x48, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x72, 0x6f, 0x6f, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x62, 0x61, 0x74, 0x63, 0x68, 0x52, 0x6f, 0x6f,
+	0x74, 0x12, 0x25, 0x0a, 0x0e, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x5f, 0x6e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x71, 0x75, 0x6f, 0x72, 0x75,
+	0x6d, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x12, 0x3a, 0x0a, 0x19, 0x71, 0x75, 0x6f, 0x72,
+	0x75, 0x6d, 0x5f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e,
+	0x74, 0x61, 0x67, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x17, 0x71, 0x75, 0x6f,
+	0x72, 0x75, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74,
+	0x61, 0x67, 0x65, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63,
+	0x65, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x14, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x2a, 0x24, 0x0a, 0x0c, 0x42, 0x6c,
+	0x6f, 0x62, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x0a, 0x0a, 0x06, 0x4e, 0x4f,
+	0x52, 0x4d, 0x41, 0x4c, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x49, 0x47, 0x48, 0x10, 0x01,
+	0x2a, 0x7c, 0x0a, 0x0a, 0x42, 0x6c, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0b,
+	0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x50,
+	0x52, 0x4f, 0x43, 0x45, 0x53, 0x53, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x43,
+	0x4f, 0x4e, 0x46, 0x49, 0x52, 0x4d, 0x45, 0x44, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x41,
+	0x49, 0x4c, 0x45, 0x44, 0x10, 0x03, 0x12, 0x0d, 0x0a, 0x09, 0x46, 0x49, 0x4e, 0x41, 0x4c, 0x49,
+	0x5a, 0x45, 0x44, 0x10, 0x04, 0x12, 0x1b, 0x0a, 0x17, 0x49, 0x4e, 0x53, 0x55, 0x46, 0x46, 0x49,
+	0x43, 0x49, 0x45, 0x4e, 0x54, 0x5f, 0x53, 0x49, 0x47, 0x4e, 0x41, 0x54, 0x55, 0x52, 0x45, 0x53,
+	0x10, 0x05, 0x12, 0x0a, 0x0a, 0x06, 0x50, 0x52, 0x55, 0x4e, 0x45, 0x44, 0x10, 0x06, 0x32, 0xd6,
+	0x05, 0x0a, 0x09, 0x44, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x12, 0x4e, 0x0a, 0x0c,
+	0x44, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x42, 0x6c, 0x6f, 0x62, 0x12, 0x1e, 0x2e, 0x64,
+	0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x44, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73,
+	0x65, 0x42, 0x6c, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x64,
+	0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x44, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73,
+	0x65, 0x42, 0x6c, 0x6f, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x5f, 0x0a, 0x19,
+	0x44, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x42, 0x6c, 0x6f, 0x62, 0x41, 0x75, 0x74, 0x68,
+	0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x65, 0x64, 0x12, 0x1f, 0x2e, 0x64, 0x69, 0x73, 0x70,
+	0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61,
+	0x74, 0x65, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x64, 0x69, 0x73,
+	0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63,
+	0x61, 0x74, 0x65, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x28, 0x01, 0x30, 0x01, 0x12, 0x4b, 0x0a,
+	0x0d, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1c,
+	0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64,
+	0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x4f, 0x0a, 0x0f, 0x57, 0x61,
+	0x74, 0x63, 0x68, 0x42, 0x6c, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1c, 0x2e,
+	0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64, 0x69,
 	0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64, 0x69, 0x73, 0x70,
-	0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x4e, 0x0a, 0x0c, 0x52, 0x65, 0x74, 0x72, 0x69,
-	0x65, 0x76, 0x65, 0x42, 0x6c, 0x6f, 0x62, 0x12, 0x1e, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72,
-	0x73, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x69, 0x65, 0x76, 0x65, 0x42, 0x6c, 0x6f, 0x62,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72,
-	0x73, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x69, 0x65, 0x76, 0x65, 0x42, 0x6c, 0x6f, 0x62,
+	0x75, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12, 0x4e, 0x0a, 0x0c, 0x52,
+	0x65, 0x74, 0x72, 0x69, 0x65, 0x76, 0x65, 0x42, 0x6c, 0x6f, 0x62, 0x12, 0x1e, 0x2e, 0x64, 0x69,
+	0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x69, 0x65, 0x76, 0x65,
+	0x42, 0x6c, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x64, 0x69,
+	0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x69, 0x65, 0x76, 0x65,
+	0x42, 0x6c, 0x6f, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x63, 0x0a, 0x15, 0x47,
+	0x65, 0x74, 0x42, 0x6c, 0x6f, 0x62, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x61,
+	0x72, 0x61, 0x6d, 0x73, 0x12, 0x24, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72,
+	0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x72,
+	0x61, 0x6d, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x64, 0x69, 0x73,
+	0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x45, 0x6e, 0x63, 0x6f, 0x64,
+	0x69, 0x6e, 0x67, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
+	0x12, 0x66, 0x0a, 0x14, 0x45, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x26, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65,
+	0x72, 0x73, 0x65, 0x72, 0x2e, 0x45, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e,
+	0x66, 0x69, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x24, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x45, 0x73, 0x74,
+	0x69, 0x6d, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x5d, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x42,
+	0x6c, 0x6f, 0x62, 0x42, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x12,
+	0x22, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62,
+	0x42, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x64, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x65, 0x72, 0x2e,
+	0x42, 0x6c, 0x6f, 0x62, 0x42, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74,
 	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x31, 0x5a, 0x2f, 0x67, 0x69, 0x74, 0x68, 0x75,
 	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x4c, 0x61, 0x79, 0x72, 0x2d, 0x4c, 0x61, 0x62, 0x73, 0x2f,
 	0x65, 0x69, 0x67, 0x65, 0x6e, 0x64, 0x61, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x72, 0x70, 0x63,
@@ -1456,57 +2252,82 @@ func file_disperser_disperser_proto_rawDescGZIP() []byte {
 	return file_disperser_disperser_proto_rawDescData
 }
 
-var file_disperser_disperser_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_disperser_disperser_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_disperser_disperser_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_disperser_disperser_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
 var file_disperser_disperser_proto_goTypes = []interface{}{
-	(BlobStatus)(0),               // 0: disperser.BlobStatus
-	(*AuthenticatedRequest)(nil),  // 1: disperser.AuthenticatedRequest
-	(*AuthenticatedReply)(nil),    // 2: disperser.AuthenticatedReply
-	(*BlobAuthHeader)(nil),        // 3: disperser.BlobAuthHeader
-	(*AuthenticationData)(nil),    // 4: disperser.AuthenticationData
-	(*DisperseBlobRequest)(nil),   // 5: disperser.DisperseBlobRequest
-	(*DisperseBlobReply)(nil),     // 6: disperser.DisperseBlobReply
-	(*BlobStatusRequest)(nil),     // 7: disperser.BlobStatusRequest
-	(*BlobStatusReply)(nil),       // 8: disperser.BlobStatusReply
-	(*RetrieveBlobRequest)(nil),   // 9: disperser.RetrieveBlobRequest
-	(*RetrieveBlobReply)(nil),     // 10: disperser.RetrieveBlobReply
-	(*SecurityParams)(nil),        // 11: disperser.SecurityParams
-	(*BlobInfo)(nil),              // 12: disperser.BlobInfo
-	(*BlobHeader)(nil),            // 13: disperser.BlobHeader
-	(*BlobQuorumParam)(nil),       // 14: disperser.BlobQuorumParam
-	(*BlobVerificationProof)(nil), // 15: disperser.BlobVerificationProof
-	(*BatchMetadata)(nil),         // 16: disperser.BatchMetadata
-	(*BatchHeader)(nil),           // 17: disperser.BatchHeader
-	(*common.G1Commitment)(nil),   // 18: common.G1Commitment
+	(BlobPriority)(0),                   // 0: disperser.BlobPriority
+	(BlobStatus)(0),                     // 1: disperser.BlobStatus
+	(*AuthenticatedRequest)(nil),        // 2: disperser.AuthenticatedRequest
+	(*AuthenticatedReply)(nil),          // 3: disperser.AuthenticatedReply
+	(*BlobAuthHeader)(nil),              // 4: disperser.BlobAuthHeader
+	(*AuthenticationData)(nil),          // 5: disperser.AuthenticationData
+	(*DisperseBlobRequest)(nil),         // 6: disperser.DisperseBlobRequest
+	(*DisperseBlobReply)(nil),           // 7: disperser.DisperseBlobReply
+	(*BlobReceipt)(nil),                 // 8: disperser.BlobReceipt
+	(*BlobStatusRequest)(nil),           // 9: disperser.BlobStatusRequest
+	(*BlobStatusReply)(nil),             // 10: disperser.BlobStatusReply
+	(*RetrieveBlobRequest)(nil),         // 11: disperser.RetrieveBlobRequest
+	(*RetrieveBlobReply)(nil),           // 12: disperser.RetrieveBlobReply
+	(*BlobEncodingParamsRequest)(nil),   // 13: disperser.BlobEncodingParamsRequest
+	(*BlobEncodingParamsReply)(nil),     // 14: disperser.BlobEncodingParamsReply
+	(*EstimateConfirmationRequest)(nil), // 15: disperser.EstimateConfirmationRequest
+	(*EstimateConfirmationReply)(nil),   // 16: disperser.EstimateConfirmationReply
+	(*BlobByCommitmentRequest)(nil),     // 17: disperser.BlobByCommitmentRequest
+	(*BlobByCommitmentReply)(nil),       // 18: disperser.BlobByCommitmentReply
+	(*CommittedBlob)(nil),               // 19: disperser.CommittedBlob
+	(*QuorumEncodingParams)(nil),        // 20: disperser.QuorumEncodingParams
+	(*SecurityParams)(nil),              // 21: disperser.SecurityParams
+	(*BlobInfo)(nil),                    // 22: disperser.BlobInfo
+	(*BlobHeader)(nil),                  // 23: disperser.BlobHeader
+	(*BlobQuorumParam)(nil),             // 24: disperser.BlobQuorumParam
+	(*BlobVerificationProof)(nil),       // 25: disperser.BlobVerificationProof
+	(*BatchMetadata)(nil),               // 26: disperser.BatchMetadata
+	(*BatchHeader)(nil),                 // 27: disperser.BatchHeader
+	(*common.G1Commitment)(nil),         // 28: common.G1Commitment
 }
 var file_disperser_disperser_proto_depIdxs = []int32{
-	5,  // 0: disperser.AuthenticatedRequest.disperse_request:type_name -> disperser.DisperseBlobRequest
-	4,  // 1: disperser.AuthenticatedRequest.authentication_data:type_name -> disperser.AuthenticationData
-	3,  // 2: disperser.AuthenticatedReply.blob_auth_header:type_name -> disperser.BlobAuthHeader
-	6,  // 3: disperser.AuthenticatedReply.disperse_reply:type_name -> disperser.DisperseBlobReply
-	11, // 4: disperser.DisperseBlobRequest.security_params:type_name -> disperser.SecurityParams
-	0,  // 5: disperser.DisperseBlobReply.result:type_name -> disperser.BlobStatus
-	0,  // 6: disperser.BlobStatusReply.status:type_name -> disperser.BlobStatus
-	12, // 7: disperser.BlobStatusReply.info:type_name -> disperser.BlobInfo
-	13, // 8: disperser.BlobInfo.blob_header:type_name -> disperser.BlobHeader
-	15, // 9: disperser.BlobInfo.blob_verification_proof:type_name -> disperser.BlobVerificationProof
-	18, // 10: disperser.BlobHeader.commitment:type_name -> common.G1Commitment
-	14, // 11: disperser.BlobHeader.blob_quorum_params:type_name -> disperser.BlobQuorumParam
-	16, // 12: disperser.BlobVerificationProof.batch_metadata:type_name -> disperser.BatchMetadata
-	17, // 13: disperser.BatchMetadata.batch_header:type_name -> disperser.BatchHeader
-	5,  // 14: disperser.Disperser.DisperseBlob:input_type -> disperser.DisperseBlobRequest
-	1,  // 15: disperser.Disperser.DisperseBlobAuthenticated:input_type -> disperser.AuthenticatedRequest
-	7,  // 16: disperser.Disperser.GetBlobStatus:input_type -> disperser.BlobStatusRequest
-	9,  // 17: disperser.Disperser.RetrieveBlob:input_type -> disperser.RetrieveBlobRequest
-	6,  // 18: disperser.Disperser.DisperseBlob:output_type -> disperser.DisperseBlobReply
-	2,  // 19: disperser.Disperser.DisperseBlobAuthenticated:output_type -> disperser.AuthenticatedReply
-	8,  // 20: disperser.Disperser.GetBlobStatus:output_type -> disperser.BlobStatusReply
-	10, // 21: disperser.Disperser.RetrieveBlob:output_type -> disperser.RetrieveBlobReply
-	18, // [18:22] is the sub-list for method output_type
-	14, // [14:18] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	6,  // 0: disperser.AuthenticatedRequest.disperse_request:type_name -> disperser.DisperseBlobRequest
+	5,  // 1: disperser.AuthenticatedRequest.authentication_data:type_name -> disperser.AuthenticationData
+	4,  // 2: disperser.AuthenticatedReply.blob_auth_header:type_name -> disperser.BlobAuthHeader
+	7,  // 3: disperser.AuthenticatedReply.disperse_reply:type_name -> disperser.DisperseBlobReply
+	21, // 4: disperser.DisperseBlobRequest.security_params:type_name -> disperser.SecurityParams
+	0,  // 5: disperser.DisperseBlobRequest.priority:type_name -> disperser.BlobPriority
+	1,  // 6: disperser.DisperseBlobReply.result:type_name -> disperser.BlobStatus
+	8,  // 7: disperser.DisperseBlobReply.receipt:type_name -> disperser.BlobReceipt
+	1,  // 8: disperser.BlobStatusReply.status:type_name -> disperser.BlobStatus
+	22, // 9: disperser.BlobStatusReply.info:type_name -> disperser.BlobInfo
+	20, // 10: disperser.BlobEncodingParamsReply.quorum_encoding_params:type_name -> disperser.QuorumEncodingParams
+	28, // 11: disperser.BlobByCommitmentRequest.commitment:type_name -> common.G1Commitment
+	19, // 12: disperser.BlobByCommitmentReply.blobs:type_name -> disperser.CommittedBlob
+	1,  // 13: disperser.CommittedBlob.status:type_name -> disperser.BlobStatus
+	22, // 14: disperser.CommittedBlob.info:type_name -> disperser.BlobInfo
+	23, // 15: disperser.BlobInfo.blob_header:type_name -> disperser.BlobHeader
+	25, // 16: disperser.BlobInfo.blob_verification_proof:type_name -> disperser.BlobVerificationProof
+	28, // 17: disperser.BlobHeader.commitment:type_name -> common.G1Commitment
+	24, // 18: disperser.BlobHeader.blob_quorum_params:type_name -> disperser.BlobQuorumParam
+	26, // 19: disperser.BlobVerificationProof.batch_metadata:type_name -> disperser.BatchMetadata
+	27, // 20: disperser.BatchMetadata.batch_header:type_name -> disperser.BatchHeader
+	6,  // 21: disperser.Disperser.DisperseBlob:input_type -> disperser.DisperseBlobRequest
+	2,  // 22: disperser.Disperser.DisperseBlobAuthenticated:input_type -> disperser.AuthenticatedRequest
+	9,  // 23: disperser.Disperser.GetBlobStatus:input_type -> disperser.BlobStatusRequest
+	9,  // 24: disperser.Disperser.WatchBlobStatus:input_type -> disperser.BlobStatusRequest
+	11, // 25: disperser.Disperser.RetrieveBlob:input_type -> disperser.RetrieveBlobRequest
+	13, // 26: disperser.Disperser.GetBlobEncodingParams:input_type -> disperser.BlobEncodingParamsRequest
+	15, // 27: disperser.Disperser.EstimateConfirmation:input_type -> disperser.EstimateConfirmationRequest
+	17, // 28: disperser.Disperser.GetBlobByCommitment:input_type -> disperser.BlobByCommitmentRequest
+	7,  // 29: disperser.Disperser.DisperseBlob:output_type -> disperser.DisperseBlobReply
+	3,  // 30: disperser.Disperser.DisperseBlobAuthenticated:output_type -> disperser.AuthenticatedReply
+	10, // 31: disperser.Disperser.GetBlobStatus:output_type -> disperser.BlobStatusReply
+	10, // 32: disperser.Disperser.WatchBlobStatus:output_type -> disperser.BlobStatusReply
+	12, // 33: disperser.Disperser.RetrieveBlob:output_type -> disperser.RetrieveBlobReply
+	14, // 34: disperser.Disperser.GetBlobEncodingParams:output_type -> disperser.BlobEncodingParamsReply
+	16, // 35: disperser.Disperser.EstimateConfirmation:output_type -> disperser.EstimateConfirmationReply
+	18, // 36: disperser.Disperser.GetBlobByCommitment:output_type -> disperser.BlobByCommitmentReply
+	29, // [29:37] is the sub-list for method output_type
+	21, // [21:29] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_disperser_disperser_proto_init() }
@@ -1588,7 +2409,7 @@ func file_disperser_disperser_proto_init() {
 			}
 		}
 		file_disperser_disperser_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BlobStatusRequest); i {
+			switch v := v.(*BlobReceipt); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1600,7 +2421,7 @@ func file_disperser_disperser_proto_init() {
 			}
 		}
 		file_disperser_disperser_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BlobStatusReply); i {
+			switch v := v.(*BlobStatusRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1612,7 +2433,7 @@ func file_disperser_disperser_proto_init() {
 			}
 		}
 		file_disperser_disperser_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RetrieveBlobRequest); i {
+			switch v := v.(*BlobStatusReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1624,7 +2445,7 @@ func file_disperser_disperser_proto_init() {
 			}
 		}
 		file_disperser_disperser_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RetrieveBlobReply); i {
+			switch v := v.(*RetrieveBlobRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1636,7 +2457,7 @@ func file_disperser_disperser_proto_init() {
 			}
 		}
 		file_disperser_disperser_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SecurityParams); i {
+			switch v := v.(*RetrieveBlobReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1648,7 +2469,7 @@ func file_disperser_disperser_proto_init() {
 			}
 		}
 		file_disperser_disperser_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BlobInfo); i {
+			switch v := v.(*BlobEncodingParamsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1660,7 +2481,7 @@ func file_disperser_disperser_proto_init() {
 			}
 		}
 		file_disperser_disperser_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BlobHeader); i {
+			switch v := v.(*BlobEncodingParamsReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1672,7 +2493,7 @@ func file_disperser_disperser_proto_init() {
 			}
 		}
 		file_disperser_disperser_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BlobQuorumParam); i {
+			switch v := v.(*EstimateConfirmationRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1684,7 +2505,7 @@ func file_disperser_disperser_proto_init() {
 			}
 		}
 		file_disperser_disperser_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BlobVerificationProof); i {
+			switch v := v.(*EstimateConfirmationReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1696,7 +2517,7 @@ func file_disperser_disperser_proto_init() {
 			}
 		}
 		file_disperser_disperser_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BatchMetadata); i {
+			switch v := v.(*BlobByCommitmentRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1708,6 +2529,114 @@ func file_disperser_disperser_proto_init() {
 			}
 		}
 		file_disperser_disperser_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BlobByCommitmentReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_disperser_disperser_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommittedBlob); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_disperser_disperser_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QuorumEncodingParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_disperser_disperser_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecurityParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_disperser_disperser_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BlobInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_disperser_disperser_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BlobHeader); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_disperser_disperser_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BlobQuorumParam); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_disperser_disperser_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BlobVerificationProof); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_disperser_disperser_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchMetadata); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_disperser_disperser_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*BatchHeader); i {
 			case 0:
 				return &v.state
@@ -1733,8 +2662,8 @@ func file_disperser_disperser_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_disperser_disperser_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   17,
+			NumEnums:      2,
+			NumMessages:   26,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
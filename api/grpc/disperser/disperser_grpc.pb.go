@@ -38,6 +38,11 @@ type DisperserClient interface {
 	DisperseBlobAuthenticated(ctx context.Context, opts ...grpc.CallOption) (Disperser_DisperseBlobAuthenticatedClient, error)
 	// This API is meant to be polled for the blob status.
 	GetBlobStatus(ctx context.Context, in *BlobStatusRequest, opts ...grpc.CallOption) (*BlobStatusReply, error)
+	// WatchBlobStatus streams the status of the blob as an alternative to polling GetBlobStatus.
+	// The current status is sent first, followed by a message for each status transition. The
+	// stream is closed after the message of a terminal status (FINALIZED, FAILED or
+	// INSUFFICIENT_SIGNATURES).
+	WatchBlobStatus(ctx context.Context, in *BlobStatusRequest, opts ...grpc.CallOption) (Disperser_WatchBlobStatusClient, error)
 	// This retrieves the requested blob from the Disperser's backend.
 	// This is a more efficient way to retrieve blobs than directly retrieving
 	// from the DA Nodes (see detail about this approach in
@@ -45,6 +50,16 @@ type DisperserClient interface {
 	// The blob should have been initially dispersed via this Disperser service
 	// for this API to work.
 	RetrieveBlob(ctx context.Context, in *RetrieveBlobRequest, opts ...grpc.CallOption) (*RetrieveBlobReply, error)
+	// This returns the encoding params and the chunk assignment info of each quorum
+	// of a confirmed blob, so that clients can independently re-encode and verify it.
+	GetBlobEncodingParams(ctx context.Context, in *BlobEncodingParamsRequest, opts ...grpc.CallOption) (*BlobEncodingParamsReply, error)
+	// This returns an approximate estimate of the time until a blob is confirmed, based on
+	// the number of blobs queued ahead of it and the recent confirmation latency.
+	EstimateConfirmation(ctx context.Context, in *EstimateConfirmationRequest, opts ...grpc.CallOption) (*EstimateConfirmationReply, error)
+	// This returns the confirmed blobs with the given KZG commitment. The same data dispersed
+	// in several requests has the same commitment, in which case all of the blobs are returned,
+	// by request time. The results are paginated with the pagination token of the reply.
+	GetBlobByCommitment(ctx context.Context, in *BlobByCommitmentRequest, opts ...grpc.CallOption) (*BlobByCommitmentReply, error)
 }
 
 type disperserClient struct {
@@ -104,6 +119,38 @@ func (c *disperserClient) GetBlobStatus(ctx context.Context, in *BlobStatusReque
 	return out, nil
 }
 
+func (c *disperserClient) WatchBlobStatus(ctx context.Context, in *BlobStatusRequest, opts ...grpc.CallOption) (Disperser_WatchBlobStatusClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Disperser_ServiceDesc.Streams[1], "/disperser.Disperser/WatchBlobStatus", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &disperserWatchBlobStatusClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Disperser_WatchBlobStatusClient interface {
+	Recv() (*BlobStatusReply, error)
+	grpc.ClientStream
+}
+
+type disperserWatchBlobStatusClient struct {
+	grpc.ClientStream
+}
+
+func (x *disperserWatchBlobStatusClient) Recv() (*BlobStatusReply, error) {
+	m := new(BlobStatusReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *disperserClient) RetrieveBlob(ctx context.Context, in *RetrieveBlobRequest, opts ...grpc.CallOption) (*RetrieveBlobReply, error) {
 	out := new(RetrieveBlobReply)
 	err := c.cc.Invoke(ctx, "/disperser.Disperser/RetrieveBlob", in, out, opts...)
@@ -113,6 +160,33 @@ func (c *disperserClient) RetrieveBlob(ctx context.Context, in *RetrieveBlobRequ
 	return out, nil
 }
 
+func (c *disperserClient) GetBlobEncodingParams(ctx context.Context, in *BlobEncodingParamsRequest, opts ...grpc.CallOption) (*BlobEncodingParamsReply, error) {
+	out := new(BlobEncodingParamsReply)
+	err := c.cc.Invoke(ctx, "/disperser.Disperser/GetBlobEncodingParams", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *disperserClient) EstimateConfirmation(ctx context.Context, in *EstimateConfirmationRequest, opts ...grpc.CallOption) (*EstimateConfirmationReply, error) {
+	out := new(EstimateConfirmationReply)
+	err := c.cc.Invoke(ctx, "/disperser.Disperser/EstimateConfirmation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *disperserClient) GetBlobByCommitment(ctx context.Context, in *BlobByCommitmentRequest, opts ...grpc.CallOption) (*BlobByCommitmentReply, error) {
+	out := new(BlobByCommitmentReply)
+	err := c.cc.Invoke(ctx, "/disperser.Disperser/GetBlobByCommitment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DisperserServer is the server API for Disperser service.
 // All implementations must embed UnimplementedDisperserServer
 // for forward compatibility
@@ -133,6 +207,11 @@ type DisperserServer interface {
 	DisperseBlobAuthenticated(Disperser_DisperseBlobAuthenticatedServer) error
 	// This API is meant to be polled for the blob status.
 	GetBlobStatus(context.Context, *BlobStatusRequest) (*BlobStatusReply, error)
+	// WatchBlobStatus streams the status of the blob as an alternative to polling GetBlobStatus.
+	// The current status is sent first, followed by a message for each status transition. The
+	// stream is closed after the message of a terminal status (FINALIZED, FAILED or
+	// INSUFFICIENT_SIGNATURES).
+	WatchBlobStatus(*BlobStatusRequest, Disperser_WatchBlobStatusServer) error
 	// This retrieves the requested blob from the Disperser's backend.
 	// This is a more efficient way to retrieve blobs than directly retrieving
 	// from the DA Nodes (see detail about this approach in
@@ -140,6 +219,16 @@ type DisperserServer interface {
 	// The blob should have been initially dispersed via this Disperser service
 	// for this API to work.
 	RetrieveBlob(context.Context, *RetrieveBlobRequest) (*RetrieveBlobReply, error)
+	// This returns the encoding params and the chunk assignment info of each quorum
+	// of a confirmed blob, so that clients can independently re-encode and verify it.
+	GetBlobEncodingParams(context.Context, *BlobEncodingParamsRequest) (*BlobEncodingParamsReply, error)
+	// This returns an approximate estimate of the time until a blob is confirmed, based on
+	// the number of blobs queued ahead of it and the recent confirmation latency.
+	EstimateConfirmation(context.Context, *EstimateConfirmationRequest) (*EstimateConfirmationReply, error)
+	// This returns the confirmed blobs with the given KZG commitment. The same data dispersed
+	// in several requests has the same commitment, in which case all of the blobs are returned,
+	// by request time. The results are paginated with the pagination token of the reply.
+	GetBlobByCommitment(context.Context, *BlobByCommitmentRequest) (*BlobByCommitmentReply, error)
 	mustEmbedUnimplementedDisperserServer()
 }
 
@@ -156,9 +245,21 @@ func (UnimplementedDisperserServer) DisperseBlobAuthenticated(Disperser_Disperse
 func (UnimplementedDisperserServer) GetBlobStatus(context.Context, *BlobStatusRequest) (*BlobStatusReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBlobStatus not implemented")
 }
+func (UnimplementedDisperserServer) WatchBlobStatus(*BlobStatusRequest, Disperser_WatchBlobStatusServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchBlobStatus not implemented")
+}
 func (UnimplementedDisperserServer) RetrieveBlob(context.Context, *RetrieveBlobRequest) (*RetrieveBlobReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RetrieveBlob not implemented")
 }
+func (UnimplementedDisperserServer) GetBlobEncodingParams(context.Context, *BlobEncodingParamsRequest) (*BlobEncodingParamsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlobEncodingParams not implemented")
+}
+func (UnimplementedDisperserServer) EstimateConfirmation(context.Context, *EstimateConfirmationRequest) (*EstimateConfirmationReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EstimateConfirmation not implemented")
+}
+func (UnimplementedDisperserServer) GetBlobByCommitment(context.Context, *BlobByCommitmentRequest) (*BlobByCommitmentReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlobByCommitment not implemented")
+}
 func (UnimplementedDisperserServer) mustEmbedUnimplementedDisperserServer() {}
 
 // UnsafeDisperserServer may be embedded to opt out of forward compatibility for this service.
@@ -234,6 +335,27 @@ func _Disperser_GetBlobStatus_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Disperser_WatchBlobStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BlobStatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DisperserServer).WatchBlobStatus(m, &disperserWatchBlobStatusServer{stream})
+}
+
+type Disperser_WatchBlobStatusServer interface {
+	Send(*BlobStatusReply) error
+	grpc.ServerStream
+}
+
+type disperserWatchBlobStatusServer struct {
+	grpc.ServerStream
+}
+
+func (x *disperserWatchBlobStatusServer) Send(m *BlobStatusReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _Disperser_RetrieveBlob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RetrieveBlobRequest)
 	if err := dec(in); err != nil {
@@ -252,6 +374,60 @@ func _Disperser_RetrieveBlob_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Disperser_GetBlobEncodingParams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlobEncodingParamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DisperserServer).GetBlobEncodingParams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/disperser.Disperser/GetBlobEncodingParams",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DisperserServer).GetBlobEncodingParams(ctx, req.(*BlobEncodingParamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Disperser_EstimateConfirmation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EstimateConfirmationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DisperserServer).EstimateConfirmation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/disperser.Disperser/EstimateConfirmation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DisperserServer).EstimateConfirmation(ctx, req.(*EstimateConfirmationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Disperser_GetBlobByCommitment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlobByCommitmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DisperserServer).GetBlobByCommitment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/disperser.Disperser/GetBlobByCommitment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DisperserServer).GetBlobByCommitment(ctx, req.(*BlobByCommitmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Disperser_ServiceDesc is the grpc.ServiceDesc for Disperser service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -271,6 +447,18 @@ var Disperser_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RetrieveBlob",
 			Handler:    _Disperser_RetrieveBlob_Handler,
 		},
+		{
+			MethodName: "GetBlobEncodingParams",
+			Handler:    _Disperser_GetBlobEncodingParams_Handler,
+		},
+		{
+			MethodName: "EstimateConfirmation",
+			Handler:    _Disperser_EstimateConfirmation_Handler,
+		},
+		{
+			MethodName: "GetBlobByCommitment",
+			Handler:    _Disperser_GetBlobByCommitment_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -279,6 +467,11 @@ var Disperser_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "WatchBlobStatus",
+			Handler:       _Disperser_WatchBlobStatus_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "disperser/disperser.proto",
 }
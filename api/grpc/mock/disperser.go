@@ -58,3 +58,37 @@ func (m *StreamMock) Close() {
 	close(m.recvToServer)
 	close(m.sentFromServer)
 }
+
+func MakeStatusStreamMock(ctx context.Context) *StatusStreamMock {
+	return &StatusStreamMock{
+		ctx:            ctx,
+		sentFromServer: make(chan *disperser.BlobStatusReply, 10),
+	}
+}
+
+type StatusStreamMock struct {
+	grpc.ServerStream
+	ctx            context.Context
+	sentFromServer chan *disperser.BlobStatusReply
+}
+
+func (m *StatusStreamMock) Context() context.Context {
+	return m.ctx
+}
+
+func (m *StatusStreamMock) Send(resp *disperser.BlobStatusReply) error {
+	m.sentFromServer <- resp
+	return nil
+}
+
+func (m *StatusStreamMock) RecvToClient() (*disperser.BlobStatusReply, error) {
+	response, more := <-m.sentFromServer
+	if !more {
+		return nil, errors.New("empty")
+	}
+	return response, nil
+}
+
+func (m *StatusStreamMock) Close() {
+	close(m.sentFromServer)
+}
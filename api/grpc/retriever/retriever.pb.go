@@ -99,6 +99,75 @@ func (x *BlobRequest) GetQuorumId() uint32 {
 	return 0
 }
 
+type BlobByBatchIdRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The ID of the batch that this blob belongs to, as emitted by the onchain
+	// BatchConfirmed event.
+	BatchId uint32 `protobuf:"varint,1,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	// Which blob in the batch this is requesting for (note: a batch is logically an
+	// ordered list of blobs).
+	BlobIndex uint32 `protobuf:"varint,2,opt,name=blob_index,json=blobIndex,proto3" json:"blob_index,omitempty"`
+	// Which quorum of the blob this is requesting for (note a blob can participate in
+	// multiple quorums).
+	QuorumId uint32 `protobuf:"varint,3,opt,name=quorum_id,json=quorumId,proto3" json:"quorum_id,omitempty"`
+}
+
+func (x *BlobByBatchIdRequest) Reset() {
+	*x = BlobByBatchIdRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_retriever_retriever_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlobByBatchIdRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobByBatchIdRequest) ProtoMessage() {}
+
+func (x *BlobByBatchIdRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_retriever_retriever_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobByBatchIdRequest.ProtoReflect.Descriptor instead.
+func (*BlobByBatchIdRequest) Descriptor() ([]byte, []int) {
+	return file_retriever_retriever_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BlobByBatchIdRequest) GetBatchId() uint32 {
+	if x != nil {
+		return x.BatchId
+	}
+	return 0
+}
+
+func (x *BlobByBatchIdRequest) GetBlobIndex() uint32 {
+	if x != nil {
+		return x.BlobIndex
+	}
+	return 0
+}
+
+func (x *BlobByBatchIdRequest) GetQuorumId() uint32 {
+	if x != nil {
+		return x.QuorumId
+	}
+	return 0
+}
+
 type BlobReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -111,7 +180,7 @@ type BlobReply struct {
 func (x *BlobReply) Reset() {
 	*x = BlobReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_retriever_retriever_proto_msgTypes[1]
+		mi := &file_retriever_retriever_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -124,7 +193,7 @@ func (x *BlobReply) String() string {
 func (*BlobReply) ProtoMessage() {}
 
 func (x *BlobReply) ProtoReflect() protoreflect.Message {
-	mi := &file_retriever_retriever_proto_msgTypes[1]
+	mi := &file_retriever_retriever_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -137,7 +206,7 @@ func (x *BlobReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlobReply.ProtoReflect.Descriptor instead.
 func (*BlobReply) Descriptor() ([]byte, []int) {
-	return file_retriever_retriever_proto_rawDescGZIP(), []int{1}
+	return file_retriever_retriever_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *BlobReply) GetData() []byte {
@@ -163,17 +232,29 @@ var file_retriever_retriever_proto_rawDesc = []byte{
 	0x0d, 0x52, 0x14, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x42, 0x6c, 0x6f, 0x63,
 	0x6b, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x71, 0x75, 0x6f, 0x72, 0x75,
 	0x6d, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x71, 0x75, 0x6f, 0x72,
-	0x75, 0x6d, 0x49, 0x64, 0x22, 0x1f, 0x0a, 0x09, 0x42, 0x6c, 0x6f, 0x62, 0x52, 0x65, 0x70, 0x6c,
-	0x79, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x04, 0x64, 0x61, 0x74, 0x61, 0x32, 0x4b, 0x0a, 0x09, 0x52, 0x65, 0x74, 0x72, 0x69, 0x65, 0x76,
+	0x75, 0x6d, 0x49, 0x64, 0x22, 0x6d, 0x0a, 0x14, 0x42, 0x6c, 0x6f, 0x62, 0x42, 0x79, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x49, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08,
+	0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07,
+	0x62, 0x61, 0x74, 0x63, 0x68, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6c, 0x6f, 0x62, 0x5f,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x62, 0x6c, 0x6f,
+	0x62, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1b, 0x0a, 0x09, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d,
+	0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x71, 0x75, 0x6f, 0x72, 0x75,
+	0x6d, 0x49, 0x64, 0x22, 0x1f, 0x0a, 0x09, 0x42, 0x6c, 0x6f, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x32, 0x9d, 0x01, 0x0a, 0x09, 0x52, 0x65, 0x74, 0x72, 0x69, 0x65, 0x76,
 	0x65, 0x72, 0x12, 0x3e, 0x0a, 0x0c, 0x52, 0x65, 0x74, 0x72, 0x69, 0x65, 0x76, 0x65, 0x42, 0x6c,
 	0x6f, 0x62, 0x12, 0x16, 0x2e, 0x72, 0x65, 0x74, 0x72, 0x69, 0x65, 0x76, 0x65, 0x72, 0x2e, 0x42,
 	0x6c, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x72, 0x65, 0x74,
 	0x72, 0x69, 0x65, 0x76, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79,
-	0x22, 0x00, 0x42, 0x31, 0x5a, 0x2f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
-	0x2f, 0x4c, 0x61, 0x79, 0x72, 0x2d, 0x4c, 0x61, 0x62, 0x73, 0x2f, 0x65, 0x69, 0x67, 0x65, 0x6e,
-	0x64, 0x61, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x72, 0x65, 0x74, 0x72,
-	0x69, 0x65, 0x76, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x22, 0x00, 0x12, 0x50, 0x0a, 0x15, 0x52, 0x65, 0x74, 0x72, 0x69, 0x65, 0x76, 0x65, 0x42, 0x6c,
+	0x6f, 0x62, 0x42, 0x79, 0x42, 0x61, 0x74, 0x63, 0x68, 0x49, 0x64, 0x12, 0x1f, 0x2e, 0x72, 0x65,
+	0x74, 0x72, 0x69, 0x65, 0x76, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x42, 0x79, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x49, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x72,
+	0x65, 0x74, 0x72, 0x69, 0x65, 0x76, 0x65, 0x72, 0x2e, 0x42, 0x6c, 0x6f, 0x62, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x00, 0x42, 0x31, 0x5a, 0x2f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x4c, 0x61, 0x79, 0x72, 0x2d, 0x4c, 0x61, 0x62, 0x73, 0x2f, 0x65, 0x69, 0x67,
+	0x65, 0x6e, 0x64, 0x61, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x72, 0x65,
+	0x74, 0x72, 0x69, 0x65, 0x76, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -188,16 +269,19 @@ func file_retriever_retriever_proto_rawDescGZIP() []byte {
 	return file_retriever_retriever_proto_rawDescData
 }
 
-var file_retriever_retriever_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_retriever_retriever_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_retriever_retriever_proto_goTypes = []interface{}{
-	(*BlobRequest)(nil), // 0: retriever.BlobRequest
-	(*BlobReply)(nil),   // 1: retriever.BlobReply
+	(*BlobRequest)(nil),          // 0: retriever.BlobRequest
+	(*BlobByBatchIdRequest)(nil), // 1: retriever.BlobByBatchIdRequest
+	(*BlobReply)(nil),            // 2: retriever.BlobReply
 }
 var file_retriever_retriever_proto_depIdxs = []int32{
 	0, // 0: retriever.Retriever.RetrieveBlob:input_type -> retriever.BlobRequest
-	1, // 1: retriever.Retriever.RetrieveBlob:output_type -> retriever.BlobReply
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
+	1, // 1: retriever.Retriever.RetrieveBlobByBatchId:input_type -> retriever.BlobByBatchIdRequest
+	2, // 2: retriever.Retriever.RetrieveBlob:output_type -> retriever.BlobReply
+	2, // 3: retriever.Retriever.RetrieveBlobByBatchId:output_type -> retriever.BlobReply
+	2, // [2:4] is the sub-list for method output_type
+	0, // [0:2] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
@@ -222,6 +306,18 @@ func file_retriever_retriever_proto_init() {
 			}
 		}
 		file_retriever_retriever_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BlobByBatchIdRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_retriever_retriever_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*BlobReply); i {
 			case 0:
 				return &v.state
@@ -240,7 +336,7 @@ func file_retriever_retriever_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_retriever_retriever_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -25,6 +25,10 @@ type RetrieverClient interface {
 	// This fans out request to EigenDA Nodes to retrieve the chunks and returns the
 	// reconstructed original blob in response.
 	RetrieveBlob(ctx context.Context, in *BlobRequest, opts ...grpc.CallOption) (*BlobReply, error)
+	// This is the same as RetrieveBlob, except that the batch is identified by the
+	// batch ID from the onchain BatchConfirmed event. The batch header is fetched from
+	// chain before retrieving the blob.
+	RetrieveBlobByBatchId(ctx context.Context, in *BlobByBatchIdRequest, opts ...grpc.CallOption) (*BlobReply, error)
 }
 
 type retrieverClient struct {
@@ -44,6 +48,15 @@ func (c *retrieverClient) RetrieveBlob(ctx context.Context, in *BlobRequest, opt
 	return out, nil
 }
 
+func (c *retrieverClient) RetrieveBlobByBatchId(ctx context.Context, in *BlobByBatchIdRequest, opts ...grpc.CallOption) (*BlobReply, error) {
+	out := new(BlobReply)
+	err := c.cc.Invoke(ctx, "/retriever.Retriever/RetrieveBlobByBatchId", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RetrieverServer is the server API for Retriever service.
 // All implementations must embed UnimplementedRetrieverServer
 // for forward compatibility
@@ -51,6 +64,10 @@ type RetrieverServer interface {
 	// This fans out request to EigenDA Nodes to retrieve the chunks and returns the
 	// reconstructed original blob in response.
 	RetrieveBlob(context.Context, *BlobRequest) (*BlobReply, error)
+	// This is the same as RetrieveBlob, except that the batch is identified by the
+	// batch ID from the onchain BatchConfirmed event. The batch header is fetched from
+	// chain before retrieving the blob.
+	RetrieveBlobByBatchId(context.Context, *BlobByBatchIdRequest) (*BlobReply, error)
 	mustEmbedUnimplementedRetrieverServer()
 }
 
@@ -61,6 +78,9 @@ type UnimplementedRetrieverServer struct {
 func (UnimplementedRetrieverServer) RetrieveBlob(context.Context, *BlobRequest) (*BlobReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RetrieveBlob not implemented")
 }
+func (UnimplementedRetrieverServer) RetrieveBlobByBatchId(context.Context, *BlobByBatchIdRequest) (*BlobReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetrieveBlobByBatchId not implemented")
+}
 func (UnimplementedRetrieverServer) mustEmbedUnimplementedRetrieverServer() {}
 
 // UnsafeRetrieverServer may be embedded to opt out of forward compatibility for this service.
@@ -92,6 +112,24 @@ func _Retriever_RetrieveBlob_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Retriever_RetrieveBlobByBatchId_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlobByBatchIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RetrieverServer).RetrieveBlobByBatchId(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/retriever.Retriever/RetrieveBlobByBatchId",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RetrieverServer).RetrieveBlobByBatchId(ctx, req.(*BlobByBatchIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Retriever_ServiceDesc is the grpc.ServiceDesc for Retriever service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -103,6 +141,10 @@ var Retriever_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RetrieveBlob",
 			Handler:    _Retriever_RetrieveBlob_Handler,
 		},
+		{
+			MethodName: "RetrieveBlobByBatchId",
+			Handler:    _Retriever_RetrieveBlobByBatchId_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "retriever/retriever.proto",
@@ -0,0 +1,172 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	disperser_rpc "github.com/Layr-Labs/eigenda/api/grpc/disperser"
+	binding "github.com/Layr-Labs/eigenda/contracts/bindings/EigenDAServiceManager"
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+)
+
+var (
+	// ErrBlobNotConfirmed is returned when the blob status reply isn't of a confirmed or finalized blob
+	ErrBlobNotConfirmed = errors.New("blob is not confirmed")
+	// ErrInvalidInclusionProof is returned when the blob header isn't included in the batch root by the inclusion proof
+	ErrInvalidInclusionProof = errors.New("invalid blob inclusion proof")
+	// ErrBatchHeaderHashMismatch is returned when the batch header hash doesn't match the batch header
+	ErrBatchHeaderHashMismatch = errors.New("batch header hash does not match the batch header")
+	// ErrBatchMetadataMismatch is returned when the batch metadata doesn't match the metadata of the batch confirmed onchain
+	ErrBatchMetadataMismatch = errors.New("batch metadata does not match the batch confirmed onchain")
+	// ErrCommitmentMismatch is returned when the commitment of the retrieved data doesn't match the blob header
+	ErrCommitmentMismatch = errors.New("commitment of the retrieved data does not match the blob header")
+)
+
+// VerifyBlobStatusOpts configures the optional steps of VerifyBlobStatus
+type VerifyBlobStatusOpts struct {
+	// RetrievalClient retrieves the blob from the operators if set. The data is then committed to with Encoder and the
+	// commitment is checked against the blob header.
+	RetrievalClient RetrievalClient
+	// Encoder commits to the retrieved data. Required if RetrievalClient is set.
+	Encoder core.Encoder
+}
+
+// VerifyBlobStatus verifies the blob of a BlobStatusReply end-to-end. It checks, in order, that
+//   - the inclusion proof links the blob header to the batch root,
+//   - the batch header hash is the hash of the batch header,
+//   - the batch metadata matches the metadata of the batch confirmed onchain,
+//   - if opts.RetrievalClient is set, the blob retrieved from the operators commits to the commitment of the blob header.
+//
+// The returned error wraps the error of the step that failed.
+func VerifyBlobStatus(ctx context.Context, reply *disperser_rpc.BlobStatusReply, chainClient core.Transactor, opts VerifyBlobStatusOpts) error {
+	status := reply.GetStatus()
+	if status != disperser_rpc.BlobStatus_CONFIRMED && status != disperser_rpc.BlobStatus_FINALIZED {
+		return fmt.Errorf("%w: status %s", ErrBlobNotConfirmed, status)
+	}
+	if opts.RetrievalClient != nil && opts.Encoder == nil {
+		return errors.New("an encoder is required to verify the retrieved blob")
+	}
+	proto := reply.GetInfo().GetBlobVerificationProof()
+	batchMetadata := proto.GetBatchMetadata()
+	if batchMetadata.GetBatchHeader() == nil || reply.GetInfo().GetBlobHeader().GetCommitment() == nil {
+		return fmt.Errorf("%w: missing blob verification proof", ErrBlobNotConfirmed)
+	}
+
+	blobHeader := getBlobHeaderFromStatusReply(reply.GetInfo().GetBlobHeader())
+	batchHeader := &core.BatchHeader{
+		ReferenceBlockNumber: uint(batchMetadata.GetBatchHeader().GetReferenceBlockNumber()),
+		BatchRoot:            [32]byte(padHash(batchMetadata.GetBatchHeader().GetBatchRoot())),
+	}
+
+	// the inclusion proof links the blob header to the batch root
+	blobHeaderHash, err := blobHeader.GetBlobHeaderHash()
+	if err != nil {
+		return fmt.Errorf("VerifyBlobStatus: failed to get blob header hash: %w", err)
+	}
+	proof, err := core.DeserializeBatchMerkleProof(uint64(proto.GetBlobIndex()), proto.GetInclusionProof())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInclusionProof, err)
+	}
+	ok, err := proof.Verify(blobHeaderHash[:], batchHeader.BatchRoot)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInclusionProof, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: blob header of blob %d is not included in batch root %x", ErrInvalidInclusionProof, proto.GetBlobIndex(), batchHeader.BatchRoot)
+	}
+
+	// the batch header hash is the hash of the batch header operators signed
+	batchHeaderHash, err := batchHeader.GetBatchHeaderHash()
+	if err != nil {
+		return fmt.Errorf("VerifyBlobStatus: failed to get batch header hash: %w", err)
+	}
+	if batchHeaderHash != [32]byte(padHash(batchMetadata.GetBatchHeaderHash())) {
+		return fmt.Errorf("%w: expected %x, got %x", ErrBatchHeaderHashMismatch, batchHeaderHash, batchMetadata.GetBatchHeaderHash())
+	}
+
+	// the batch metadata matches the metadata stored onchain when the batch was confirmed
+	onchainBatchHeaderHash, err := core.HashBatchHeader(binding.IEigenDAServiceManagerBatchHeader{
+		BlobHeadersRoot:            batchHeader.BatchRoot,
+		QuorumNumbers:              batchMetadata.GetBatchHeader().GetQuorumNumbers(),
+		QuorumThresholdPercentages: batchMetadata.GetBatchHeader().GetQuorumSignedPercentages(),
+		ReferenceBlockNumber:       uint32(batchHeader.ReferenceBlockNumber),
+	})
+	if err != nil {
+		return fmt.Errorf("VerifyBlobStatus: failed to hash batch header: %w", err)
+	}
+	batchMetadataHash := core.HashBatchMetadata(onchainBatchHeaderHash, [32]byte(padHash(batchMetadata.GetSignatoryRecordHash())), batchMetadata.GetConfirmationBlockNumber())
+	onchainBatchMetadataHash, err := chainClient.GetBatchMetadataHash(ctx, proto.GetBatchId())
+	if err != nil {
+		return fmt.Errorf("VerifyBlobStatus: failed to get metadata hash of batch %d: %w", proto.GetBatchId(), err)
+	}
+	if batchMetadataHash != onchainBatchMetadataHash {
+		return fmt.Errorf("%w: batch %d has metadata hash %x onchain, expected %x", ErrBatchMetadataMismatch, proto.GetBatchId(), onchainBatchMetadataHash, batchMetadataHash)
+	}
+
+	if opts.RetrievalClient == nil {
+		return nil
+	}
+
+	// the retrieved blob commits to the commitment of the blob header
+	if len(blobHeader.QuorumInfos) == 0 {
+		return fmt.Errorf("%w: blob header has no quorums to retrieve the blob from", ErrQuorumNotFound)
+	}
+	quorumInfo := blobHeader.QuorumInfos[0]
+	data, err := opts.RetrievalClient.RetrieveBlob(ctx, batchHeaderHash, proto.GetBlobIndex(), batchHeader.ReferenceBlockNumber, batchHeader.BatchRoot, quorumInfo.QuorumID)
+	if err != nil {
+		return fmt.Errorf("VerifyBlobStatus: failed to retrieve blob: %w", err)
+	}
+	if quorumInfo.ChunkLength == 0 {
+		return fmt.Errorf("VerifyBlobStatus: invalid chunk length of quorum %d", quorumInfo.QuorumID)
+	}
+	blobLength := core.GetBlobLength(uint(len(data)))
+	params, err := core.GetEncodingParams(quorumInfo.ChunkLength, (blobLength+quorumInfo.ChunkLength-1)/quorumInfo.ChunkLength)
+	if err != nil {
+		return fmt.Errorf("VerifyBlobStatus: failed to get encoding params: %w", err)
+	}
+	commitments, _, err := opts.Encoder.Encode(data, params)
+	if err != nil {
+		return fmt.Errorf("VerifyBlobStatus: failed to commit to the retrieved blob: %w", err)
+	}
+	if !commitments.Commitment.X.Equal(&blobHeader.Commitment.X) || !commitments.Commitment.Y.Equal(&blobHeader.Commitment.Y) {
+		return ErrCommitmentMismatch
+	}
+	return nil
+}
+
+// getBlobHeaderFromStatusReply converts the blob header of a BlobStatusReply. The reply carries the fields the blob header
+// hash commits to, but not the length commitment and proof.
+func getBlobHeaderFromStatusReply(h *disperser_rpc.BlobHeader) *core.BlobHeader {
+	commitment := &core.G1Commitment{
+		X: *new(fp.Element).SetBytes(h.GetCommitment().GetX()),
+		Y: *new(fp.Element).SetBytes(h.GetCommitment().GetY()),
+	}
+	quorumInfos := make([]*core.BlobQuorumInfo, len(h.GetBlobQuorumParams()))
+	for i, param := range h.GetBlobQuorumParams() {
+		quorumInfos[i] = &core.BlobQuorumInfo{
+			SecurityParam: core.SecurityParam{
+				QuorumID:           core.QuorumID(param.GetQuorumNumber()),
+				AdversaryThreshold: uint8(param.GetAdversaryThresholdPercentage()),
+				QuorumThreshold:    uint8(param.GetQuorumThresholdPercentage()),
+			},
+			ChunkLength: uint(param.GetChunkLength()),
+		}
+	}
+	return &core.BlobHeader{
+		BlobCommitments: core.BlobCommitments{
+			Commitment: commitment,
+			Length:     uint(h.GetDataLength()),
+		},
+		QuorumInfos: quorumInfos,
+	}
+}
+
+// padHash returns the hash in a 32 byte slice, truncating or zero padding it, so malformed hashes fail the comparisons
+// instead of panicking
+func padHash(hash []byte) []byte {
+	padded := make([]byte, 32)
+	copy(padded, hash)
+	return padded
+}
@@ -34,6 +34,8 @@ type DisperserClient interface {
 	DisperseBlob(ctx context.Context, data []byte, securityParams []*core.SecurityParam) (*disperser.BlobStatus, []byte, error)
 	DisperseBlobAuthenticated(ctx context.Context, data []byte, securityParams []*core.SecurityParam) (*disperser.BlobStatus, []byte, error)
 	GetBlobStatus(ctx context.Context, key []byte) (*disperser_rpc.BlobStatusReply, error)
+	// EstimateConfirmation returns the disperser's approximate estimate of the time until the blob is confirmed
+	EstimateConfirmation(ctx context.Context, key []byte) (time.Duration, error)
 }
 
 type disperserClient struct {
@@ -129,10 +131,13 @@ func (c *disperserClient) DisperseBlobAuthenticated(ctx context.Context, data []
 		}
 	}
 
+	// The account ID and the signature of the request must come from the same key, even if the key is rotated meanwhile
+	signer := c.signer.Current()
+
 	request := &disperser_rpc.DisperseBlobRequest{
 		Data:           data,
 		SecurityParams: sp,
-		AccountId:      c.signer.GetAccountID(),
+		AccountId:      signer.GetAccountID(),
 	}
 
 	// Send the initial request
@@ -160,7 +165,7 @@ func (c *disperserClient) DisperseBlobAuthenticated(ctx context.Context, data []
 		Nonce:           authHeaderReply.BlobAuthHeader.ChallengeParameter,
 	}
 
-	authData, err := c.signer.SignBlobRequest(authHeader)
+	authData, err := signer.SignBlobRequest(authHeader)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error signing blob request")
 	}
@@ -217,3 +222,25 @@ func (c *disperserClient) GetBlobStatus(ctx context.Context, requestID []byte) (
 
 	return reply, nil
 }
+
+func (c *disperserClient) EstimateConfirmation(ctx context.Context, requestID []byte) (time.Duration, error) {
+	addr := fmt.Sprintf("%v:%v", c.config.Hostname, c.config.Port)
+	conn, err := grpc.Dial(addr, c.getDialOptions()...)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	disperserClient := disperser_rpc.NewDisperserClient(conn)
+	ctxTimeout, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	reply, err := disperserClient.EstimateConfirmation(ctxTimeout, &disperser_rpc.EstimateConfirmationRequest{
+		RequestId: requestID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(reply.GetEstimatedSeconds()) * time.Second, nil
+}
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	disperser_rpc "github.com/Layr-Labs/eigenda/api/grpc/disperser"
 	"github.com/Layr-Labs/eigenda/clients"
@@ -66,3 +67,8 @@ func (c *MockDisperserClient) GetBlobStatus(ctx context.Context, key []byte) (*d
 	}
 	return reply, err
 }
+
+func (c *MockDisperserClient) EstimateConfirmation(ctx context.Context, key []byte) (time.Duration, error) {
+	args := c.Called(key)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
@@ -2,15 +2,30 @@ package mock
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/Layr-Labs/eigenda/clients"
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/stretchr/testify/mock"
-	"github.com/wealdtech/go-merkletree"
 )
 
 type MockNodeClient struct {
 	mock.Mock
+
+	// HeaderDelay delays the GetBlobHeader replies until the delay elapses or the request is cancelled
+	HeaderDelay time.Duration
+	// ChunkDelays delays the GetChunks replies of the given operators until the delay elapses or the request is cancelled
+	ChunkDelays map[core.OperatorID]time.Duration
+	// ChunkErrors makes the GetChunks requests of the given operators fail with the given errors
+	ChunkErrors map[core.OperatorID]error
+	// StorageKeys signs the storage attestations of the given operators with the given keys when attestations are requested.
+	// Operators without a key return no attestation.
+	StorageKeys map[core.OperatorID]*core.KeyPair
+
+	mu                 sync.Mutex
+	cancelledOperators []core.OperatorID
+	chunkBudgets       []time.Duration
 }
 
 var _ clients.NodeClient = (*MockNodeClient)(nil)
@@ -19,8 +34,15 @@ func NewNodeClient() *MockNodeClient {
 	return &MockNodeClient{}
 }
 
-func (c *MockNodeClient) GetBlobHeader(ctx context.Context, socket string, batchHeaderHash [32]byte, blobIndex uint32) (*core.BlobHeader, *merkletree.Proof, error) {
-	args := c.Called(socket, batchHeaderHash, blobIndex)
+func (c *MockNodeClient) GetBlobHeader(ctx context.Context, opID core.OperatorID, opInfo *core.IndexedOperatorInfo, batchHeaderHash [32]byte, blobIndex uint32) (*core.BlobHeader, *core.BatchMerkleProof, error) {
+	if c.HeaderDelay > 0 {
+		select {
+		case <-time.After(c.HeaderDelay):
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+	args := c.Called(opInfo.Socket, batchHeaderHash, blobIndex)
 	var hashes [][]byte
 	if args.Get(1) != nil {
 		hashes = (args.Get(1)).([][]byte)
@@ -36,7 +58,7 @@ func (c *MockNodeClient) GetBlobHeader(ctx context.Context, socket string, batch
 		err = args.Get(3).(error)
 	}
 
-	proof := &merkletree.Proof{
+	proof := &core.BatchMerkleProof{
 		Hashes: hashes,
 		Index:  index,
 	}
@@ -50,13 +72,73 @@ func (c *MockNodeClient) GetChunks(
 	batchHeaderHash [32]byte,
 	blobIndex uint32,
 	quorumID core.QuorumID,
+	attestStorage bool,
 	chunksChan chan clients.RetrievedChunks,
 ) {
+	if deadline, ok := ctx.Deadline(); ok {
+		c.mu.Lock()
+		c.chunkBudgets = append(c.chunkBudgets, time.Until(deadline))
+		c.mu.Unlock()
+	}
 	args := c.Called(opID, opInfo, batchHeaderHash, blobIndex)
 	encodedBlob := (args.Get(0)).(core.EncodedBlob)
-	chunksChan <- clients.RetrievedChunks{
+	if err, ok := c.ChunkErrors[opID]; ok {
+		chunksChan <- clients.RetrievedChunks{
+			OperatorID: opID,
+			Err:        err,
+		}
+		return
+	}
+	if delay, ok := c.ChunkDelays[opID]; ok {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			c.mu.Lock()
+			c.cancelledOperators = append(c.cancelledOperators, opID)
+			c.mu.Unlock()
+			chunksChan <- clients.RetrievedChunks{
+				OperatorID: opID,
+				Err:        ctx.Err(),
+			}
+			return
+		}
+	}
+	retrieved := clients.RetrievedChunks{
 		OperatorID: opID,
 		Err:        nil,
 		Chunks:     encodedBlob[opID].Bundles[quorumID],
 	}
+	if attestStorage {
+		serialized := make([][]byte, len(retrieved.Chunks))
+		for i, chunk := range retrieved.Chunks {
+			data, err := chunk.Serialize()
+			if err != nil {
+				chunksChan <- clients.RetrievedChunks{
+					OperatorID: opID,
+					Err:        err,
+				}
+				return
+			}
+			serialized[i] = data
+		}
+		retrieved.StorageMessage = core.ComputeStorageAttestationMessage(batchHeaderHash, blobIndex, quorumID, serialized)
+		if key, ok := c.StorageKeys[opID]; ok {
+			retrieved.StorageSignature = key.SignMessage(retrieved.StorageMessage)
+		}
+	}
+	chunksChan <- retrieved
+}
+
+// CancelledOperators returns the operators whose delayed GetChunks requests were cancelled
+func (c *MockNodeClient) CancelledOperators() []core.OperatorID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]core.OperatorID{}, c.cancelledOperators...)
+}
+
+// ChunkBudgets returns the time left until the deadline of each GetChunks request with a deadline, when it was made
+func (c *MockNodeClient) ChunkBudgets() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration{}, c.chunkBudgets...)
 }
@@ -29,9 +29,20 @@ func (c *MockRetrievalClient) RetrieveBlob(
 	blobIndex uint32,
 	referenceBlockNumber uint,
 	batchRoot [32]byte,
-	quorumID core.QuorumID) ([]byte, error) {
+	quorumID core.QuorumID,
+	opts ...clients.RetrievalOption) ([]byte, error) {
 	args := c.Called()
 
 	result := args.Get(0)
 	return result.([]byte), args.Error(1)
 }
+
+func (c *MockRetrievalClient) RetrieveBlobs(ctx context.Context, requests []clients.RetrieveRequest, concurrency int) ([]clients.RetrieveResult, error) {
+	args := c.Called(requests, concurrency)
+
+	result := args.Get(0)
+	if result == nil {
+		return nil, args.Error(1)
+	}
+	return result.([]clients.RetrieveResult), args.Error(1)
+}
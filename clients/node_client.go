@@ -5,48 +5,57 @@ import (
 	"time"
 
 	"github.com/Layr-Labs/eigenda/api/grpc/node"
+	"github.com/Layr-Labs/eigenda/common"
 	"github.com/Layr-Labs/eigenda/core"
 	node_utils "github.com/Layr-Labs/eigenda/node/grpc"
-	"github.com/wealdtech/go-merkletree"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type RetrievedChunks struct {
 	OperatorID core.OperatorID
 	Chunks     []*core.Chunk
 	Err        error
+	// StorageMessage is the storage attestation message of the chunks as served by the operator. Only set if a storage
+	// attestation is requested.
+	StorageMessage [32]byte
+	// StorageSignature is the operator's signature over StorageMessage, nil if the operator didn't return one
+	StorageSignature *core.Signature
 }
 
 type NodeClient interface {
-	GetBlobHeader(ctx context.Context, socket string, batchHeaderHash [32]byte, blobIndex uint32) (*core.BlobHeader, *merkletree.Proof, error)
-	GetChunks(ctx context.Context, opID core.OperatorID, opInfo *core.IndexedOperatorInfo, batchHeaderHash [32]byte, blobIndex uint32, quorumID core.QuorumID, chunksChan chan RetrievedChunks)
+	GetBlobHeader(ctx context.Context, opID core.OperatorID, opInfo *core.IndexedOperatorInfo, batchHeaderHash [32]byte, blobIndex uint32) (*core.BlobHeader, *core.BatchMerkleProof, error)
+	// GetChunks retrieves the chunks of the operator for the blob and sends them to chunksChan. If attestStorage is set,
+	// the operator is requested to sign the chunks it serves.
+	GetChunks(ctx context.Context, opID core.OperatorID, opInfo *core.IndexedOperatorInfo, batchHeaderHash [32]byte, blobIndex uint32, quorumID core.QuorumID, attestStorage bool, chunksChan chan RetrievedChunks)
 }
 
 type client struct {
 	timeout time.Duration
+	pool    *common.ConnectionPool
 }
 
 func NewNodeClient(timeout time.Duration) NodeClient {
+	return NewNodeClientWithConnectionPool(timeout, common.NewConnectionPool(common.ConnectionPoolConfig{}))
+}
+
+// NewNodeClientWithConnectionPool returns a node client which reuses the connections of the given pool
+func NewNodeClientWithConnectionPool(timeout time.Duration, pool *common.ConnectionPool) NodeClient {
 	return client{
 		timeout: timeout,
+		pool:    pool,
 	}
 }
 
 func (c client) GetBlobHeader(
 	ctx context.Context,
-	socket string,
+	opID core.OperatorID,
+	opInfo *core.IndexedOperatorInfo,
 	batchHeaderHash [32]byte,
 	blobIndex uint32,
-) (*core.BlobHeader, *merkletree.Proof, error) {
-	conn, err := grpc.Dial(
-		core.OperatorSocket(socket).GetRetrievalSocket(),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+) (*core.BlobHeader, *core.BatchMerkleProof, error) {
+	conn, err := c.pool.GetConnection(opID.Hex(), core.OperatorSocket(opInfo.Socket).GetRetrievalSocket())
 	if err != nil {
 		return nil, nil, err
 	}
-	defer conn.Close()
 
 	n := node.NewRetrievalClient(conn)
 	nodeCtx, cancel := context.WithTimeout(ctx, c.timeout)
@@ -67,9 +76,9 @@ func (c client) GetBlobHeader(
 		return nil, nil, err
 	}
 
-	proof := &merkletree.Proof{
-		Hashes: reply.GetProof().GetHashes(),
+	proof := &core.BatchMerkleProof{
 		Index:  uint64(reply.GetProof().GetIndex()),
+		Hashes: reply.GetProof().GetHashes(),
 	}
 
 	return blobHeader, proof, nil
@@ -82,12 +91,10 @@ func (c client) GetChunks(
 	batchHeaderHash [32]byte,
 	blobIndex uint32,
 	quorumID core.QuorumID,
+	attestStorage bool,
 	chunksChan chan RetrievedChunks,
 ) {
-	conn, err := grpc.Dial(
-		core.OperatorSocket(opInfo.Socket).GetRetrievalSocket(),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	conn, err := c.pool.GetConnection(opID.Hex(), core.OperatorSocket(opInfo.Socket).GetRetrievalSocket())
 	if err != nil {
 		chunksChan <- RetrievedChunks{
 			OperatorID: opID,
@@ -105,6 +112,7 @@ func (c client) GetChunks(
 		BatchHeaderHash: batchHeaderHash[:],
 		BlobIndex:       blobIndex,
 		QuorumId:        uint32(quorumID),
+		AttestStorage:   attestStorage,
 	}
 
 	reply, err := n.RetrieveChunks(nodeCtx, request)
@@ -131,9 +139,16 @@ func (c client) GetChunks(
 
 		chunks[i] = chunk
 	}
-	chunksChan <- RetrievedChunks{
+	retrieved := RetrievedChunks{
 		OperatorID: opID,
 		Err:        nil,
 		Chunks:     chunks,
 	}
+	if attestStorage {
+		retrieved.StorageMessage = core.ComputeStorageAttestationMessage(batchHeaderHash, blobIndex, quorumID, reply.GetChunks())
+		if len(reply.GetStorageSignature()) > 0 {
+			retrieved.StorageSignature = &core.Signature{G1Point: new(core.Signature).Deserialize(reply.GetStorageSignature())}
+		}
+	}
+	chunksChan <- retrieved
 }
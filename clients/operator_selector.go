@@ -0,0 +1,63 @@
+package clients
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+
+	"github.com/Layr-Labs/eigenda/core"
+)
+
+// OperatorSelector orders the operators of a quorum that RetrieveBlob requests chunks from. The operators are requested
+// in the returned order, so with an over request factor only the first operators needed to reconstruct the blob are
+// requested. Preferred operators (see WithPreferredOperators) are still requested before the others.
+type OperatorSelector interface {
+	// OrderOperators returns each operator of the quorum in the state exactly once, in the order to request them.
+	// assignments are the chunk assignments of the operators for the blob being retrieved.
+	OrderOperators(state *core.OperatorState, quorumID core.QuorumID, assignments map[core.OperatorID]core.Assignment) []core.OperatorID
+}
+
+type randomOperatorSelector struct{}
+
+// NewRandomOperatorSelector returns a selector that orders the operators randomly, so that retrievals are spread across
+// the operators of the quorum. This is the default selector of the retrieval client.
+func NewRandomOperatorSelector() OperatorSelector {
+	return randomOperatorSelector{}
+}
+
+func (randomOperatorSelector) OrderOperators(state *core.OperatorState, quorumID core.QuorumID, assignments map[core.OperatorID]core.Assignment) []core.OperatorID {
+	opIDs := getQuorumOperators(state, quorumID)
+	rand.Shuffle(len(opIDs), func(i, j int) {
+		opIDs[i], opIDs[j] = opIDs[j], opIDs[i]
+	})
+	return opIDs
+}
+
+type assignedChunksOperatorSelector struct{}
+
+// NewAssignedChunksOperatorSelector returns a selector that orders the operators by the number of chunks assigned to
+// them, most first, so that the fewest operators are requested
+func NewAssignedChunksOperatorSelector() OperatorSelector {
+	return assignedChunksOperatorSelector{}
+}
+
+func (assignedChunksOperatorSelector) OrderOperators(state *core.OperatorState, quorumID core.QuorumID, assignments map[core.OperatorID]core.Assignment) []core.OperatorID {
+	opIDs := getQuorumOperators(state, quorumID)
+	sort.Slice(opIDs, func(i, j int) bool {
+		numChunksI, numChunksJ := assignments[opIDs[i]].NumChunks, assignments[opIDs[j]].NumChunks
+		if numChunksI != numChunksJ {
+			return numChunksI > numChunksJ
+		}
+		return bytes.Compare(opIDs[i][:], opIDs[j][:]) < 0
+	})
+	return opIDs
+}
+
+func getQuorumOperators(state *core.OperatorState, quorumID core.QuorumID) []core.OperatorID {
+	operators := state.Operators[quorumID]
+	opIDs := make([]core.OperatorID, 0, len(operators))
+	for opID := range operators {
+		opIDs = append(opIDs, opID)
+	}
+	return opIDs
+}
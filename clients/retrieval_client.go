@@ -2,24 +2,223 @@ package clients
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/Layr-Labs/eigenda/common"
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/pkg/kzg/bn254"
 	"github.com/gammazero/workerpool"
-	"github.com/wealdtech/go-merkletree"
-	"github.com/wealdtech/go-merkletree/keccak256"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"golang.org/x/sync/singleflight"
 )
 
+var (
+	// ErrQuorumNotFound is returned when the requested quorum is not in the operator state or in the blob header
+	ErrQuorumNotFound = errors.New("quorum not found")
+	// ErrBlobHeaderUnavailable is returned when no operator returns a valid blob header
+	ErrBlobHeaderUnavailable = errors.New("blob header unavailable")
+	// ErrDecodeFailed is returned when the blob cannot be decoded from the chunks retrieved from the operators
+	ErrDecodeFailed = errors.New("failed to decode blob")
+	// ErrChunkIndexMismatch is returned when an operator's chunks cannot be bound to the indices it is assigned
+	ErrChunkIndexMismatch = errors.New("chunks do not match the operator's assignment")
+	// ErrInvalidStorageAttestation flags a storage attestation that is missing or not signed by the operator
+	ErrInvalidStorageAttestation = errors.New("invalid storage attestation")
+	// ErrBlobSizeMismatch is returned when the expected blob size doesn't match the length of the blob in its header
+	ErrBlobSizeMismatch = errors.New("blob size does not match the blob length")
+	// ErrInvalidStageDeadlines is returned when the fractions of the stage deadlines are out of range
+	ErrInvalidStageDeadlines = errors.New("invalid stage deadlines")
+	// ErrDecodeRedundancyMismatch is returned when re-encoding the decoded blob doesn't regenerate the retrieved chunks
+	ErrDecodeRedundancyMismatch = errors.New("decoded blob does not regenerate the retrieved chunks")
+	// ErrBlobLengthMismatch is returned when the length commitment of the blob header doesn't prove the length of the blob
+	// in the header, so that decoding up to the header length could truncate the blob
+	ErrBlobLengthMismatch = errors.New("blob length does not match the length commitment")
+	// ErrBatchRootMismatch is returned when the batch root given to RetrieveBlob differs from the batch root confirmed
+	// onchain
+	ErrBatchRootMismatch = errors.New("batch root does not match the onchain batch root")
+)
+
+// BatchRootFetcher fetches the root of the blob headers of a batch as it was confirmed onchain
+type BatchRootFetcher interface {
+	FetchBatchRoot(ctx context.Context, batchHeaderHash [32]byte) ([32]byte, error)
+}
+
 type RetrievalClient interface {
+	// RetrieveBlob retrieves the data of a blob. The blob header only records the length of the blob in symbols, so
+	// unless the size of the blob is given with WithBlobSize, the data is zero padded to a multiple of the symbol size.
 	RetrieveBlob(
 		ctx context.Context,
 		batchHeaderHash [32]byte,
 		blobIndex uint32,
 		referenceBlockNumber uint,
 		batchRoot [32]byte,
-		quorumID core.QuorumID) ([]byte, error)
+		quorumID core.QuorumID,
+		opts ...RetrievalOption) ([]byte, error)
+	// RetrieveBlobs retrieves the blobs of the requests with at most concurrency retrievals in flight at a time. The
+	// results are returned in the order of the requests, each with either the blob data or the retrieval error.
+	RetrieveBlobs(ctx context.Context, requests []RetrieveRequest, concurrency int) ([]RetrieveResult, error)
+}
+
+// RetrieveRequest identifies a blob to retrieve with RetrieveBlobs, see RetrieveBlob for the fields
+type RetrieveRequest struct {
+	BatchHeaderHash      [32]byte
+	BlobIndex            uint32
+	ReferenceBlockNumber uint
+	BatchRoot            [32]byte
+	QuorumID             core.QuorumID
+	Opts                 []RetrievalOption
+}
+
+// RetrieveResult is the result of a single request of RetrieveBlobs. Err is nil if Data is the retrieved blob.
+type RetrieveResult struct {
+	Data []byte
+	Err  error
+}
+
+// RetrievalOption configures a single RetrieveBlob call
+type RetrievalOption func(*retrievalOptions)
+
+type retrievalOptions struct {
+	preferredOperators  []core.OperatorID
+	storageAttestations *[]*StorageAttestation
+	blobSize            uint
+	stageDeadlines      StageDeadlines
+	verifyRedundancy    bool
+	progressiveDecode   bool
+	batchRootFetcher    BatchRootFetcher
+	cacheOperatorState  bool
+}
+
+// StageDeadlines bounds each stage of RetrieveBlob to a fraction of the time left until the deadline of its context,
+// so that a slow stage can't use up the budget of the later stages. The fractions must be in [0, 1] and sum to at
+// most 1. A zero fraction leaves the stage bounded by the context of RetrieveBlob only. The stage deadlines are not
+// applied if the context has no deadline.
+type StageDeadlines struct {
+	// HeaderFetch is the fraction of the budget for fetching and verifying the blob header
+	HeaderFetch float64
+	// ChunkFetch is the fraction of the budget for fetching and verifying the chunks
+	ChunkFetch float64
+	// Decode is the fraction of the budget for decoding the blob from the chunks
+	Decode float64
+}
+
+func (d StageDeadlines) validate() error {
+	for _, fraction := range []float64{d.HeaderFetch, d.ChunkFetch, d.Decode} {
+		if fraction < 0 || fraction > 1 {
+			return fmt.Errorf("%w: fraction %f is not in [0, 1]", ErrInvalidStageDeadlines, fraction)
+		}
+	}
+	if sum := d.HeaderFetch + d.ChunkFetch + d.Decode; sum > 1 {
+		return fmt.Errorf("%w: fractions sum to %f", ErrInvalidStageDeadlines, sum)
+	}
+	return nil
+}
+
+// stageContext returns the context of a stage that may use the given fraction of the budget
+func stageContext(ctx context.Context, budget time.Duration, fraction float64) (context.Context, context.CancelFunc) {
+	if budget <= 0 || fraction == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(fraction*float64(budget)))
+}
+
+// StorageAttestation is an operator's signature over the chunks it served for a blob. An operator can't deny serving the
+// chunks it signed, so attestations can be used as evidence in disputes, e.g. about operators serving stale data.
+type StorageAttestation struct {
+	OperatorID core.OperatorID
+	// Message is the storage attestation message of the served chunks, see core.ComputeStorageAttestationMessage
+	Message [32]byte
+	// Signature is the operator's signature over Message, nil if the operator didn't return one
+	Signature *core.Signature
+	// Err is nil if Signature is a valid signature of the operator over Message, and wraps ErrInvalidStorageAttestation otherwise
+	Err error
+}
+
+// WithPreferredOperators makes RetrieveBlob query the given operators first, in the given order, and only fall back to
+// the other operators of the quorum if the preferred ones don't serve enough valid chunks. The blob header and the chunks
+// are verified the same way regardless of the operators they are retrieved from.
+func WithPreferredOperators(opIDs ...core.OperatorID) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.preferredOperators = opIDs
+	}
+}
+
+// WithStorageAttestations makes RetrieveBlob request a storage attestation from each operator along with its chunks.
+// The attestation of each operator that serves chunks is verified and appended to attestations, whether or not its
+// chunks are valid. Missing and invalid attestations are flagged by their Err.
+func WithStorageAttestations(attestations *[]*StorageAttestation) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.storageAttestations = attestations
+	}
+}
+
+// WithBlobSize makes RetrieveBlob return exactly size bytes, i.e. the data as it was dispersed, instead of the data zero
+// padded to a multiple of the symbol size. This preserves trailing zero bytes, which can't be told apart from the
+// padding otherwise. The size must be consistent with the blob length in the blob header.
+func WithBlobSize(size uint) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.blobSize = size
+	}
+}
+
+// WithStageDeadlines bounds each stage of RetrieveBlob to the given fraction of the time left until the deadline of its
+// context, see StageDeadlines
+func WithStageDeadlines(deadlines StageDeadlines) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.stageDeadlines = deadlines
+	}
+}
+
+// WithDecodeRedundancyCheck makes RetrieveBlob re-encode the decoded blob and check that the regenerated chunks at the
+// retrieved indices match the retrieved chunks, returning ErrDecodeRedundancyMismatch otherwise. Unlike the verification
+// of the chunks against the commitment, this catches bugs in the erasure decoding itself. The encoder of the client must
+// be able to encode blobs.
+func WithDecodeRedundancyCheck() RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.verifyRedundancy = true
+	}
+}
+
+// WithProgressiveDecode makes RetrieveBlob try to decode the blob each time it verifies the chunks of another operator,
+// once it has the minimum number of chunks to decode the blob from, and return on the first successful decode. A failed
+// decode doesn't fail the retrieval as long as operators are left to retrieve chunks from. The decode attempts are
+// bounded by the chunk fetch stage deadline rather than the decode stage deadline.
+func WithProgressiveDecode() RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.progressiveDecode = true
+	}
+}
+
+// WithOnchainBatchRoot makes RetrieveBlob verify the blob header against the batch root fetched onchain with fetcher
+// rather than the batch root given by the caller, so that a forged batch root can't make a forged blob header verify.
+// The given batch root may be left zero to only use the onchain one; otherwise it must match the onchain batch root,
+// and RetrieveBlob returns ErrBatchRootMismatch if it doesn't.
+func WithOnchainBatchRoot(fetcher BatchRootFetcher) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.batchRootFetcher = fetcher
+	}
+}
+
+// WithCachedOperatorState makes RetrieveBlob use the operator pubkeys and sockets cached by the client for the reference
+// block and quorum of the retrieval, fetching them only if they aren't cached yet. The client caches the operator states
+// of the latest reference blocks and quorums retrieved with this option, and concurrent retrievals share a single fetch.
+func WithCachedOperatorState() RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.cacheOperatorState = true
+	}
+}
+
+// operatorStateCacheSize is the number of reference blocks and quorums whose operator state is cached, see
+// WithCachedOperatorState
+const operatorStateCacheSize = 16
+
+// operatorStateKey identifies the operator state of a quorum at a reference block
+type operatorStateKey struct {
+	referenceBlockNumber uint
+	quorumID             core.QuorumID
 }
 
 type retrievalClient struct {
@@ -29,10 +228,21 @@ type retrievalClient struct {
 	nodeClient            NodeClient
 	encoder               core.Encoder
 	numConnections        int
+	// overRequestFactor is the multiple of the number of operators needed to reconstruct a blob that chunks are requested from.
+	// If zero, chunks are requested from all operators.
+	overRequestFactor float64
+	operatorSelector  OperatorSelector
+
+	// operatorStates caches the operator states of the retrievals made with WithCachedOperatorState
+	operatorStates *lru.Cache[operatorStateKey, *core.IndexedOperatorState]
+	// operatorStateFetches shares the concurrent fetches of the same operator state between the retrievals
+	operatorStateFetches singleflight.Group
 }
 
 var _ RetrievalClient = (*retrievalClient)(nil)
 
+// NewRetrievalClient returns a retrieval client that requests chunks from the operators in the order of the operator
+// selector. If operatorSelector is nil, the operators are requested in random order.
 func NewRetrievalClient(
 	logger common.Logger,
 	chainState core.IndexedChainState,
@@ -40,7 +250,19 @@ func NewRetrievalClient(
 	nodeClient NodeClient,
 	encoder core.Encoder,
 	numConnections int,
+	overRequestFactor float64,
+	operatorSelector OperatorSelector,
 ) (*retrievalClient, error) {
+	if overRequestFactor != 0 && overRequestFactor < 1 {
+		return nil, fmt.Errorf("over request factor must be at least 1 or zero, got %f", overRequestFactor)
+	}
+	if operatorSelector == nil {
+		operatorSelector = NewRandomOperatorSelector()
+	}
+	operatorStates, err := lru.New[operatorStateKey, *core.IndexedOperatorState](operatorStateCacheSize)
+	if err != nil {
+		return nil, err
+	}
 
 	return &retrievalClient{
 		logger:                logger,
@@ -49,32 +271,152 @@ func NewRetrievalClient(
 		nodeClient:            nodeClient,
 		encoder:               encoder,
 		numConnections:        numConnections,
+		overRequestFactor:     overRequestFactor,
+		operatorSelector:      operatorSelector,
+		operatorStates:        operatorStates,
 	}, nil
 }
 
+// getIndexedOperatorState returns the operator state of the quorum at the reference block, from the cache if cached is
+// set, see WithCachedOperatorState
+func (r *retrievalClient) getIndexedOperatorState(ctx context.Context, referenceBlockNumber uint, quorumID core.QuorumID, cached bool) (*core.IndexedOperatorState, error) {
+	if !cached {
+		return r.indexedChainState.GetIndexedOperatorState(ctx, referenceBlockNumber, []core.QuorumID{quorumID})
+	}
+
+	key := operatorStateKey{referenceBlockNumber: referenceBlockNumber, quorumID: quorumID}
+	if state, ok := r.operatorStates.Get(key); ok {
+		return state, nil
+	}
+	state, err, _ := r.operatorStateFetches.Do(fmt.Sprintf("%d/%d", referenceBlockNumber, quorumID), func() (interface{}, error) {
+		state, err := r.indexedChainState.GetIndexedOperatorState(ctx, referenceBlockNumber, []core.QuorumID{quorumID})
+		if err != nil {
+			return nil, err
+		}
+		r.operatorStates.Add(key, state)
+		return state, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state.(*core.IndexedOperatorState), nil
+}
+
+// getNumOperatorsToRequest returns the number of operators, in the given order, to request chunks from
+func getNumOperatorsToRequest(opIDs []core.OperatorID, assignments map[core.OperatorID]core.Assignment, numChunksNeeded uint, overRequestFactor float64) int {
+	if overRequestFactor == 0 {
+		return len(opIDs)
+	}
+	numOperatorsNeeded := 0
+	numChunks := uint(0)
+	for _, opID := range opIDs {
+		if numChunks >= numChunksNeeded {
+			break
+		}
+		numChunks += uint(assignments[opID].NumChunks)
+		numOperatorsNeeded++
+	}
+	numOperators := int(math.Ceil(float64(numOperatorsNeeded) * overRequestFactor))
+	if numOperators > len(opIDs) {
+		return len(opIDs)
+	}
+	return numOperators
+}
+
+// requestNextOperator requests chunks from the next operator that has not been requested yet, if any.
+// It returns the number of operators requested.
+func requestNextOperator(opIDs []core.OperatorID, numRequested int, requestChunks func([]core.OperatorID), overRequestFactor float64) int {
+	if overRequestFactor == 0 || numRequested >= len(opIDs) {
+		return 0
+	}
+	requestChunks(opIDs[numRequested : numRequested+1])
+	return 1
+}
+
+// RetrieveBlobs retrieves the blobs of the requests concurrently. All the retrievals share the operator connections of
+// the node client, so an operator serving chunks of several blobs is only dialed once.
+func (r *retrievalClient) RetrieveBlobs(ctx context.Context, requests []RetrieveRequest, concurrency int) ([]RetrieveResult, error) {
+	if concurrency < 1 {
+		return nil, fmt.Errorf("concurrency must be at least 1, got %d", concurrency)
+	}
+
+	results := make([]RetrieveResult, len(requests))
+	pool := workerpool.New(concurrency)
+	for i := range requests {
+		i := i
+		pool.Submit(func() {
+			req := requests[i]
+			data, err := r.RetrieveBlob(ctx, req.BatchHeaderHash, req.BlobIndex, req.ReferenceBlockNumber, req.BatchRoot, req.QuorumID, req.Opts...)
+			if err != nil {
+				r.logger.Warn("failed to retrieve blob", "batchHeaderHash", hex.EncodeToString(req.BatchHeaderHash[:]), "blobIndex", req.BlobIndex, "err", err)
+			}
+			results[i] = RetrieveResult{
+				Data: data,
+				Err:  err,
+			}
+		})
+	}
+	pool.StopWait()
+
+	return results, nil
+}
+
 func (r *retrievalClient) RetrieveBlob(
 	ctx context.Context,
 	batchHeaderHash [32]byte,
 	blobIndex uint32,
 	referenceBlockNumber uint,
 	batchRoot [32]byte,
-	quorumID core.QuorumID) ([]byte, error) {
-	indexedOperatorState, err := r.indexedChainState.GetIndexedOperatorState(ctx, referenceBlockNumber, []core.QuorumID{quorumID})
+	quorumID core.QuorumID,
+	opts ...RetrievalOption) ([]byte, error) {
+	options := &retrievalOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if err := options.stageDeadlines.validate(); err != nil {
+		return nil, err
+	}
+	var budget time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		budget = time.Until(deadline)
+	}
+	if options.batchRootFetcher != nil {
+		onchainBatchRoot, err := options.batchRootFetcher.FetchBatchRoot(ctx, batchHeaderHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the onchain batch root: %w", err)
+		}
+		if batchRoot != ([32]byte{}) && batchRoot != onchainBatchRoot {
+			return nil, fmt.Errorf("%w: given %x, onchain %x", ErrBatchRootMismatch, batchRoot, onchainBatchRoot)
+		}
+		batchRoot = onchainBatchRoot
+	}
+
+	indexedOperatorState, err := r.getIndexedOperatorState(ctx, referenceBlockNumber, quorumID, options.cacheOperatorState)
 	if err != nil {
 		return nil, err
 	}
+	if err := indexedOperatorState.ValidateOperatorRegistrations(); err != nil {
+		return nil, fmt.Errorf("invalid reference block %d: %w", referenceBlockNumber, err)
+	}
 	operators, ok := indexedOperatorState.Operators[quorumID]
 	if !ok {
-		return nil, fmt.Errorf("no quorum with ID: %d", quorumID)
+		return nil, fmt.Errorf("%w: no quorum with ID: %d", ErrQuorumNotFound, quorumID)
 	}
 
-	// Get blob header from any operator
+	// Get blob header from any operator, trying the preferred operators first
+	headerOpIDs := make([]core.OperatorID, 0, len(operators))
+	for opID := range operators {
+		headerOpIDs = append(headerOpIDs, opID)
+	}
+	headerOpIDs = orderPreferredFirst(headerOpIDs, options.preferredOperators)
 	var blobHeader *core.BlobHeader
-	var proof *merkletree.Proof
+	var proof *core.BatchMerkleProof
 	var proofVerified bool
-	for opID := range operators {
+	headerCtx, cancelHeader := stageContext(ctx, budget, options.stageDeadlines.HeaderFetch)
+	defer cancelHeader()
+	for _, opID := range headerOpIDs {
 		opInfo := indexedOperatorState.IndexedOperators[opID]
-		blobHeader, proof, err = r.nodeClient.GetBlobHeader(ctx, opInfo.Socket, batchHeaderHash, blobIndex)
+		blobHeader, proof, err = r.nodeClient.GetBlobHeader(headerCtx, opID, opInfo, batchHeaderHash, blobIndex)
 		if err != nil {
 			// try another operator
 			r.logger.Warn("failed to dial operator while fetching BlobHeader, trying different operator", "operator", opInfo.Socket, "err", err)
@@ -86,7 +428,7 @@ func (r *retrievalClient) RetrieveBlob(
 			r.logger.Warn("got invalid blob header, trying different operator", "operator", opInfo.Socket, "err", err)
 			continue
 		}
-		proofVerified, err = merkletree.VerifyProofUsing(blobHeaderHash[:], false, proof, [][]byte{batchRoot[:]}, keccak256.New())
+		proofVerified, err = proof.Verify(blobHeaderHash[:], batchRoot)
 		if err != nil {
 			r.logger.Warn("got invalid blob header proof, trying different operator", "operator", opInfo.Socket, "err", err)
 			continue
@@ -98,8 +440,9 @@ func (r *retrievalClient) RetrieveBlob(
 
 		break
 	}
+	cancelHeader()
 	if blobHeader == nil || proof == nil || !proofVerified {
-		return nil, fmt.Errorf("failed to get blob header from all operators (header hash: %s, index: %d)", batchHeaderHash, blobIndex)
+		return nil, fmt.Errorf("%w: failed to get blob header from all operators (header hash: %s, index: %d)", ErrBlobHeaderUnavailable, batchHeaderHash, blobIndex)
 	}
 
 	var quorumHeader *core.BlobQuorumInfo
@@ -111,13 +454,22 @@ func (r *retrievalClient) RetrieveBlob(
 	}
 
 	if quorumHeader == nil {
-		return nil, fmt.Errorf("no quorum header for quorum %d", quorumID)
+		return nil, fmt.Errorf("%w: no quorum header for quorum %d", ErrQuorumNotFound, quorumID)
 	}
 
-	// Validate the blob length
+	maxInputSize := uint64(blobHeader.Length) * bn254.BYTES_PER_COEFFICIENT
+	if options.blobSize > 0 {
+		if core.GetBlobLength(options.blobSize) != blobHeader.Length {
+			return nil, fmt.Errorf("%w: blob size %d, blob length %d", ErrBlobSizeMismatch, options.blobSize, blobHeader.Length)
+		}
+		maxInputSize = uint64(options.blobSize)
+	}
+
+	// Validate the blob length. The length commitment is checked to be equivalent to the commitment the chunks are
+	// verified against below, which binds the length of the header to the polynomial the blob is decoded from.
 	err = r.encoder.VerifyBlobLength(blobHeader.BlobCommitments)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrBlobLengthMismatch, err)
 	}
 
 	// Validate the commitments are equivalent
@@ -132,47 +484,213 @@ func (r *retrievalClient) RetrieveBlob(
 		return nil, fmt.Errorf("failed to get assignments")
 	}
 
-	// Fetch chunks from all operators
-	chunksChan := make(chan RetrievedChunks, len(operators))
-	pool := workerpool.New(r.numConnections)
-	for opID := range operators {
-		opID := opID
-		opInfo := indexedOperatorState.IndexedOperators[opID]
-		pool.Submit(func() {
-			r.nodeClient.GetChunks(ctx, opID, opInfo, batchHeaderHash, blobIndex, quorumID, chunksChan)
-		})
-	}
-
 	encodingParams, err := core.GetEncodingParams(quorumHeader.ChunkLength, info.TotalChunks)
 	if err != nil {
 		return nil, err
 	}
 
+	opIDs := r.operatorSelector.OrderOperators(indexedOperatorState.OperatorState, quorumID, assignments)
+	// The preferred operators are requested first. Other operators are only requested when the preferred ones don't have
+	// enough chunks or fail to serve valid chunks.
+	overRequestFactor := r.overRequestFactor
+	if len(options.preferredOperators) > 0 {
+		opIDs = orderPreferredFirst(opIDs, options.preferredOperators)
+		if overRequestFactor == 0 {
+			overRequestFactor = 1
+		}
+	}
+	numChunksNeeded := (blobHeader.Length + encodingParams.ChunkLength - 1) / encodingParams.ChunkLength
+
+	// Fetch chunks from the operators. The remaining requests are cancelled once enough chunks are verified.
+	chunksCtx, cancel := stageContext(ctx, budget, options.stageDeadlines.ChunkFetch)
+	defer cancel()
+	chunksChan := make(chan RetrievedChunks, len(operators))
+	pool := workerpool.New(r.numConnections)
+	requestChunks := func(opIDs []core.OperatorID) {
+		for _, opID := range opIDs {
+			opID := opID
+			opInfo := indexedOperatorState.IndexedOperators[opID]
+			pool.Submit(func() {
+				r.nodeClient.GetChunks(chunksCtx, opID, opInfo, batchHeaderHash, blobIndex, quorumID, options.storageAttestations != nil, chunksChan)
+			})
+		}
+	}
+	numRequested := getNumOperatorsToRequest(opIDs, assignments, numChunksNeeded, overRequestFactor)
+	requestChunks(opIDs[:numRequested])
+
 	var chunks []*core.Chunk
 	var indices []core.ChunkNumber
-	// TODO(ian-shim): if we gathered enough chunks, cancel remaining RPC calls
-	for i := 0; i < len(operators); i++ {
+	decode := func() ([]byte, error) {
+		data, err := r.encoder.Decode(chunks, indices, encodingParams, maxInputSize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDecodeFailed, err)
+		}
+		if options.verifyRedundancy {
+			if err := r.verifyDecodeRedundancy(data, chunks, indices, encodingParams); err != nil {
+				return nil, err
+			}
+		}
+		return data, nil
+	}
+
+	// Without progressive decoding, the chunks are only gathered up to the minimum number needed to decode the blob
+	var decodeErr error
+	for numReplies := 0; numReplies < numRequested && (options.progressiveDecode || uint(len(chunks)) < numChunksNeeded); numReplies++ {
 		reply := <-chunksChan
 		if reply.Err != nil {
 			r.logger.Error("failed to get chunks from operator", "operator", reply.OperatorID, "err", reply.Err)
+			numRequested += requestNextOperator(opIDs, numRequested, requestChunks, overRequestFactor)
 			continue
 		}
+		if options.storageAttestations != nil {
+			attestation := verifyStorageAttestation(reply, indexedOperatorState.IndexedOperators[reply.OperatorID])
+			if attestation.Err != nil {
+				r.logger.Warn("got invalid storage attestation from operator", "operator", reply.OperatorID, "err", attestation.Err)
+			}
+			*options.storageAttestations = append(*options.storageAttestations, attestation)
+		}
 		assignment, ok := assignments[reply.OperatorID]
 		if !ok {
 			return nil, fmt.Errorf("no assignment to operator %v", reply.OperatorID)
 		}
 
-		err = r.encoder.VerifyChunks(reply.Chunks, assignment.GetIndices(), blobHeader.BlobCommitments, encodingParams)
+		assignedIndices, err := bindChunksToAssignment(reply.Chunks, assignment)
+		if err != nil {
+			r.logger.Error("rejected chunks from operator", "operator", reply.OperatorID, "err", err)
+			numRequested += requestNextOperator(opIDs, numRequested, requestChunks, overRequestFactor)
+			continue
+		}
+
+		// Each chunk is verified at the index the operator is responsible for, so that a chunk whose proof is
+		// valid for another index (e.g. a chunk assigned to another operator) is rejected
+		err = r.encoder.VerifyChunks(reply.Chunks, assignedIndices, blobHeader.BlobCommitments, encodingParams)
 		if err != nil {
 			r.logger.Error("failed to verify chunks from operator", "operator", reply.OperatorID, "err", err)
+			numRequested += requestNextOperator(opIDs, numRequested, requestChunks, overRequestFactor)
 			continue
 		} else {
 			r.logger.Info("verified chunks from operator", "operator", reply.OperatorID)
 		}
 
 		chunks = append(chunks, reply.Chunks...)
-		indices = append(indices, assignment.GetIndices()...)
+		indices = append(indices, assignedIndices...)
+
+		if options.progressiveDecode && uint(len(chunks)) >= numChunksNeeded {
+			data, err := decode()
+			if err == nil {
+				return data, nil
+			}
+			r.logger.Warn("failed to decode blob from the chunks retrieved so far, waiting for more chunks", "numChunks", len(chunks), "err", err)
+			decodeErr = err
+			numRequested += requestNextOperator(opIDs, numRequested, requestChunks, overRequestFactor)
+		}
+	}
+	// cancel the requests to the operators that have not responded yet
+	cancel()
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	if budget <= 0 || options.stageDeadlines.Decode == 0 {
+		return decode()
+	}
+
+	// The decoder can't be interrupted, so the result is abandoned if the decode stage runs out of time
+	decodeCtx, cancelDecode := stageContext(ctx, budget, options.stageDeadlines.Decode)
+	defer cancelDecode()
+	type decodeResult struct {
+		data []byte
+		err  error
+	}
+	resultChan := make(chan decodeResult, 1)
+	go func() {
+		data, err := decode()
+		resultChan <- decodeResult{data: data, err: err}
+	}()
+	select {
+	case result := <-resultChan:
+		return result.data, result.err
+	case <-decodeCtx.Done():
+		return nil, fmt.Errorf("%w: %v", ErrDecodeFailed, decodeCtx.Err())
+	}
+}
+
+// verifyDecodeRedundancy re-encodes the decoded blob and checks that the regenerated chunks at the given indices match
+// the chunks they were decoded from
+func (r *retrievalClient) verifyDecodeRedundancy(data []byte, chunks []*core.Chunk, indices []core.ChunkNumber, params core.EncodingParams) error {
+	_, regenerated, err := r.encoder.Encode(data, params)
+	if err != nil {
+		return fmt.Errorf("failed to re-encode the decoded blob: %w", err)
+	}
+	for i, index := range indices {
+		if uint64(index) >= uint64(len(regenerated)) {
+			return fmt.Errorf("%w: no regenerated chunk at index %d", ErrDecodeRedundancyMismatch, index)
+		}
+		if !chunksEqual(regenerated[index], chunks[i]) {
+			return fmt.Errorf("%w: chunk at index %d differs", ErrDecodeRedundancyMismatch, index)
+		}
+	}
+	return nil
+}
+
+func chunksEqual(a, b *core.Chunk) bool {
+	if a.Proof != b.Proof || len(a.Coeffs) != len(b.Coeffs) {
+		return false
+	}
+	for i := range a.Coeffs {
+		if a.Coeffs[i] != b.Coeffs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// bindChunksToAssignment returns the assignment index of each of the chunks returned by an operator. The i-th chunk
+// is bound to the i-th index of the operator's assignment, so the operator must return exactly the number of chunks
+// it is assigned.
+func bindChunksToAssignment(chunks []*core.Chunk, assignment core.Assignment) ([]core.ChunkNumber, error) {
+	if uint(len(chunks)) != assignment.NumChunks {
+		return nil, fmt.Errorf("%w: got %d chunks, assigned %d", ErrChunkIndexMismatch, len(chunks), assignment.NumChunks)
+	}
+	return assignment.GetIndices(), nil
+}
+
+// verifyStorageAttestation verifies the storage attestation of the chunks retrieved from an operator against the operator's public key
+func verifyStorageAttestation(reply RetrievedChunks, opInfo *core.IndexedOperatorInfo) *StorageAttestation {
+	attestation := &StorageAttestation{
+		OperatorID: reply.OperatorID,
+		Message:    reply.StorageMessage,
+		Signature:  reply.StorageSignature,
+	}
+	if reply.StorageSignature == nil {
+		attestation.Err = fmt.Errorf("%w: operator returned no signature", ErrInvalidStorageAttestation)
+	} else if opInfo == nil || !reply.StorageSignature.Verify(opInfo.PubkeyG2, reply.StorageMessage) {
+		attestation.Err = fmt.Errorf("%w: signature does not verify against the operator's public key", ErrInvalidStorageAttestation)
 	}
+	return attestation
+}
 
-	return r.encoder.Decode(chunks, indices, encodingParams, uint64(blobHeader.Length)*bn254.BYTES_PER_COEFFICIENT)
+// orderPreferredFirst returns the operators with the preferred operators that are in opIDs first, in the preferred order,
+// followed by the other operators in their original order
+func orderPreferredFirst(opIDs []core.OperatorID, preferred []core.OperatorID) []core.OperatorID {
+	if len(preferred) == 0 {
+		return opIDs
+	}
+	remaining := make(map[core.OperatorID]bool, len(opIDs))
+	for _, opID := range opIDs {
+		remaining[opID] = true
+	}
+	ordered := make([]core.OperatorID, 0, len(opIDs))
+	for _, opID := range preferred {
+		if remaining[opID] {
+			ordered = append(ordered, opID)
+			delete(remaining, opID)
+		}
+	}
+	for _, opID := range opIDs {
+		if remaining[opID] {
+			ordered = append(ordered, opID)
+		}
+	}
+	return ordered
 }
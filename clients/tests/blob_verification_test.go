@@ -0,0 +1,122 @@
+package retriever_test
+
+import (
+	"context"
+	"testing"
+
+	commonpb "github.com/Layr-Labs/eigenda/api/grpc/common"
+	disperserpb "github.com/Layr-Labs/eigenda/api/grpc/disperser"
+	"github.com/Layr-Labs/eigenda/clients"
+	binding "github.com/Layr-Labs/eigenda/contracts/bindings/EigenDAServiceManager"
+	"github.com/Layr-Labs/eigenda/core"
+	coremock "github.com/Layr-Labs/eigenda/core/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// makeBlobStatusReply returns the status reply of the confirmed blob served by the operators in setup, along with the
+// batch metadata hash stored onchain for its batch
+func makeBlobStatusReply(t *testing.T) (*disperserpb.BlobStatusReply, [32]byte) {
+	quorumInfo := blobHeader.QuorumInfos[0]
+	signatoryRecordHash := [32]byte{1, 2, 3}
+	quorumNumbers := []byte{quorumInfo.QuorumID}
+	quorumSignedPercentages := []byte{100}
+	var confirmationBlockNumber uint32 = 150
+
+	onchainBatchHeaderHash, err := core.HashBatchHeader(binding.IEigenDAServiceManagerBatchHeader{
+		BlobHeadersRoot:            batchRoot,
+		QuorumNumbers:              quorumNumbers,
+		QuorumThresholdPercentages: quorumSignedPercentages,
+		ReferenceBlockNumber:       0,
+	})
+	assert.NoError(t, err)
+
+	return &disperserpb.BlobStatusReply{
+		Status: disperserpb.BlobStatus_CONFIRMED,
+		Info: &disperserpb.BlobInfo{
+			BlobHeader: &disperserpb.BlobHeader{
+				Commitment: &commonpb.G1Commitment{
+					X: blobHeader.Commitment.X.Marshal(),
+					Y: blobHeader.Commitment.Y.Marshal(),
+				},
+				DataLength: uint32(blobHeader.Length),
+				BlobQuorumParams: []*disperserpb.BlobQuorumParam{{
+					QuorumNumber:                 uint32(quorumInfo.QuorumID),
+					AdversaryThresholdPercentage: uint32(quorumInfo.AdversaryThreshold),
+					QuorumThresholdPercentage:    uint32(quorumInfo.QuorumThreshold),
+					ChunkLength:                  uint32(quorumInfo.ChunkLength),
+				}},
+			},
+			BlobVerificationProof: &disperserpb.BlobVerificationProof{
+				BatchId:   7,
+				BlobIndex: 0,
+				BatchMetadata: &disperserpb.BatchMetadata{
+					BatchHeader: &disperserpb.BatchHeader{
+						BatchRoot:               batchRoot[:],
+						QuorumNumbers:           quorumNumbers,
+						QuorumSignedPercentages: quorumSignedPercentages,
+						ReferenceBlockNumber:    0,
+					},
+					SignatoryRecordHash:     signatoryRecordHash[:],
+					Fee:                     []byte{0},
+					ConfirmationBlockNumber: confirmationBlockNumber,
+					BatchHeaderHash:         batchHeaderHash[:],
+				},
+				// the blob is the only leaf of the batch merkle tree, so its inclusion proof is empty
+				InclusionProof: []byte{},
+				QuorumIndexes:  []byte{0},
+			},
+		},
+	}, core.HashBatchMetadata(onchainBatchHeaderHash, signatoryRecordHash, confirmationBlockNumber)
+}
+
+func TestVerifyBlobStatus(t *testing.T) {
+	setup(t)
+	ctx := context.Background()
+	reply, batchMetadataHash := makeBlobStatusReply(t)
+
+	transactor := &coremock.MockTransactor{}
+	transactor.On("GetBatchMetadataHash").Return(batchMetadataHash, nil)
+
+	// a valid reply passes the verification against the chain
+	err := clients.VerifyBlobStatus(ctx, reply, transactor, clients.VerifyBlobStatusOpts{})
+	assert.NoError(t, err)
+
+	// and against the data retrieved from the operators
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blobHeader, [][]byte{}, uint64(0), nil).Once()
+	nodeClient.
+		On("GetChunks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(encodedBlob)
+	indexer.On("GetObject", mock.Anything, 0).Return(mustMakeOpertatorPubKeysPair(t), nil).Once()
+	indexer.On("GetObject", mock.Anything, 1).Return(musMakeOperatorSocket(t), nil).Once()
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	err = clients.VerifyBlobStatus(ctx, reply, transactor, clients.VerifyBlobStatusOpts{
+		RetrievalClient: retrievalClient,
+		Encoder:         encoder,
+	})
+	assert.NoError(t, err)
+
+	// a tampered inclusion proof doesn't link the blob header to the batch root
+	reply.Info.BlobVerificationProof.InclusionProof = make([]byte, 32)
+	err = clients.VerifyBlobStatus(ctx, reply, transactor, clients.VerifyBlobStatusOpts{})
+	assert.ErrorIs(t, err, clients.ErrInvalidInclusionProof)
+	reply.Info.BlobVerificationProof.InclusionProof = []byte{}
+
+	// a batch header that doesn't match its hash
+	reply.Info.BlobVerificationProof.BatchMetadata.BatchHeader.ReferenceBlockNumber = 1
+	err = clients.VerifyBlobStatus(ctx, reply, transactor, clients.VerifyBlobStatusOpts{})
+	assert.ErrorIs(t, err, clients.ErrBatchHeaderHashMismatch)
+	reply.Info.BlobVerificationProof.BatchMetadata.BatchHeader.ReferenceBlockNumber = 0
+
+	// batch metadata that doesn't match the batch confirmed onchain
+	reply.Info.BlobVerificationProof.BatchMetadata.ConfirmationBlockNumber++
+	err = clients.VerifyBlobStatus(ctx, reply, transactor, clients.VerifyBlobStatusOpts{})
+	assert.ErrorIs(t, err, clients.ErrBatchMetadataMismatch)
+	reply.Info.BlobVerificationProof.BatchMetadata.ConfirmationBlockNumber--
+
+	// unconfirmed blobs have no verification proof
+	reply.Status = disperserpb.BlobStatus_PROCESSING
+	err = clients.VerifyBlobStatus(ctx, reply, transactor, clients.VerifyBlobStatusOpts{})
+	assert.ErrorIs(t, err, clients.ErrBlobNotConfirmed)
+}
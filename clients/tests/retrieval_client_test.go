@@ -3,8 +3,12 @@ package retriever_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"runtime"
+	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/Layr-Labs/eigenda/clients"
 	clientsmock "github.com/Layr-Labs/eigenda/clients/mock"
@@ -68,6 +72,11 @@ var (
 )
 
 func setup(t *testing.T) {
+	setupWithData(t, gettysburgAddressBytes)
+}
+
+// setupWithData sets up the operators to serve the chunks of a blob with the given data
+func setupWithData(t *testing.T, data []byte) {
 
 	var err error
 	chainState, err = coremock.MakeChainDataMock(core.OperatorIndex(numOperators))
@@ -99,7 +108,7 @@ func setup(t *testing.T) {
 		panic("failed to create a new indexed chain state")
 	}
 
-	retrievalClient, err = clients.NewRetrievalClient(logger, ics, coordinator, nodeClient, encoder, 2)
+	retrievalClient, err = clients.NewRetrievalClient(logger, ics, coordinator, nodeClient, encoder, 2, 0, nil)
 	if err != nil {
 		panic("failed to create a new retrieval client")
 	}
@@ -124,7 +133,7 @@ func setup(t *testing.T) {
 		RequestHeader: core.BlobRequestHeader{
 			SecurityParams: securityParams,
 		},
-		Data: gettysburgAddressBytes,
+		Data: data,
 	}
 	operatorState, err = indexedChainState.GetOperatorState(context.Background(), (0), []core.QuorumID{quorumID})
 	if err != nil {
@@ -253,6 +262,7 @@ func TestInvalidBlobHeader(t *testing.T) {
 
 	_, err := retrievalClient.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0)
 	assert.ErrorContains(t, err, "failed to get blob header from all operators")
+	assert.ErrorIs(t, err, clients.ErrBlobHeaderUnavailable)
 
 }
 
@@ -278,4 +288,734 @@ func TestValidBlobHeader(t *testing.T) {
 	assert.Len(t, data, 1488)
 	assert.Equal(t, gettysburgAddressBytes, recovered)
 
+	// the exact blob is recovered with its size
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blobHeader, [][]byte{}, uint64(0), nil).Once()
+	indexer.On("GetObject", mock.Anything, 0).Return(operatorPubKeys, nil).Once()
+	indexer.On("GetObject", mock.Anything, 1).Return(operatorSocket, nil).Once()
+	data, err = retrievalClient.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0, clients.WithBlobSize(uint(len(gettysburgAddressBytes))))
+	assert.NoError(t, err)
+	assert.Equal(t, gettysburgAddressBytes, data)
+
+}
+
+func TestRetrieveBlobCancelsSlowOperators(t *testing.T) {
+
+	setup(t)
+
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blobHeader, [][]byte{}, uint64(0), nil).Once()
+	nodeClient.
+		On("GetChunks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(encodedBlob)
+
+	// the 3 operators with the fewest chunks are slow
+	assignments, _, err := coordinator.GetAssignments(operatorState, blobHeader.Length, blobHeader.QuorumInfos[0])
+	assert.NoError(t, err)
+	opIDs := make([]core.OperatorID, 0, len(assignments))
+	for opID := range assignments {
+		opIDs = append(opIDs, opID)
+	}
+	sort.Slice(opIDs, func(i, j int) bool {
+		return assignments[opIDs[i]].NumChunks < assignments[opIDs[j]].NumChunks
+	})
+	assert.Len(t, opIDs, numOperators)
+	slowOperators := opIDs[:3]
+	delay := 10 * time.Second
+	nodeClient.ChunkDelays = make(map[core.OperatorID]time.Duration)
+	for _, opID := range slowOperators {
+		nodeClient.ChunkDelays[opID] = delay
+	}
+
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	// request chunks from all operators
+	client, err := clients.NewRetrievalClient(logger, indexedChainState, coordinator, nodeClient, encoder, numOperators, numOperators, nil)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	data, err := client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), delay)
+	assert.Equal(t, gettysburgAddressBytes, bytes.TrimRight(data, "\x00"))
+	assert.Eventually(t, func() bool {
+		return len(nodeClient.CancelledOperators()) == len(slowOperators)
+	}, time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, slowOperators, nodeClient.CancelledOperators())
+}
+
+func TestRetrieveBlobStageDeadlines(t *testing.T) {
+	setup(t)
+
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blobHeader, [][]byte{}, uint64(0), nil)
+	nodeClient.
+		On("GetChunks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(encodedBlob)
+	indexer.On("GetObject", mock.Anything, 0).Return(mustMakeOpertatorPubKeysPair(t), nil).Twice()
+	indexer.On("GetObject", mock.Anything, 1).Return(musMakeOperatorSocket(t), nil).Twice()
+	timeout := 2 * time.Second
+	deadlines := clients.WithStageDeadlines(clients.StageDeadlines{
+		HeaderFetch: 0.3,
+		ChunkFetch:  0.5,
+		Decode:      0.2,
+	})
+
+	// the header phase is slow, but the chunk fetch still gets half of the budget
+	nodeClient.HeaderDelay = 400 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	data, err := retrievalClient.RetrieveBlob(ctx, batchHeaderHash, 0, 0, batchRoot, 0, deadlines)
+	assert.NoError(t, err)
+	assert.Equal(t, gettysburgAddressBytes, bytes.TrimRight(data, "\x00"))
+	budgets := nodeClient.ChunkBudgets()
+	assert.NotEmpty(t, budgets)
+	for _, budget := range budgets {
+		assert.Greater(t, budget, timeout*4/10)
+		assert.LessOrEqual(t, budget, timeout/2)
+	}
+
+	// a header phase that doesn't complete is cut off at its deadline instead of using up the whole budget
+	nodeClient.HeaderDelay = 10 * time.Second
+	ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	_, err = retrievalClient.RetrieveBlob(ctx, batchHeaderHash, 0, 0, batchRoot, 0, deadlines)
+	assert.ErrorIs(t, err, clients.ErrBlobHeaderUnavailable)
+	assert.Less(t, time.Since(start), timeout/2)
+
+	// the fractions must not exceed the budget
+	_, err = retrievalClient.RetrieveBlob(ctx, batchHeaderHash, 0, 0, batchRoot, 0, clients.WithStageDeadlines(clients.StageDeadlines{
+		HeaderFetch: 0.5,
+		ChunkFetch:  0.6,
+	}))
+	assert.ErrorIs(t, err, clients.ErrInvalidStageDeadlines)
+}
+
+// getChunksRequestOrder returns the operators whose chunks were requested, in the order of the requests
+func getChunksRequestOrder() []core.OperatorID {
+	opIDs := make([]core.OperatorID, 0)
+	for _, call := range nodeClient.Calls {
+		if call.Method == "GetChunks" {
+			opIDs = append(opIDs, call.Arguments.Get(0).(core.OperatorID))
+		}
+	}
+	return opIDs
+}
+
+func TestRetrieveBlobPreferredOperators(t *testing.T) {
+
+	setup(t)
+
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blobHeader, [][]byte{}, uint64(0), nil)
+	nodeClient.
+		On("GetChunks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(encodedBlob)
+
+	// prefer the 2 operators with the fewest chunks, which are otherwise requested last
+	assignments, _, err := coordinator.GetAssignments(operatorState, blobHeader.Length, blobHeader.QuorumInfos[0])
+	assert.NoError(t, err)
+	opIDs := make([]core.OperatorID, 0, len(assignments))
+	for opID := range assignments {
+		opIDs = append(opIDs, opID)
+	}
+	sort.Slice(opIDs, func(i, j int) bool {
+		return assignments[opIDs[i]].NumChunks < assignments[opIDs[j]].NumChunks
+	})
+	preferred := opIDs[:2]
+	state, err := indexedChainState.GetIndexedOperatorState(context.Background(), 0, []core.QuorumID{0})
+	assert.NoError(t, err)
+
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	// a single connection so that the operators are requested in order
+	client, err := clients.NewRetrievalClient(logger, indexedChainState, coordinator, nodeClient, encoder, 1, 0, clients.NewAssignedChunksOperatorSelector())
+	assert.NoError(t, err)
+
+	data, err := client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0, clients.WithPreferredOperators(preferred...))
+	assert.NoError(t, err)
+	assert.Equal(t, gettysburgAddressBytes, bytes.TrimRight(data, "\x00"))
+	assert.Equal(t, "GetBlobHeader", nodeClient.Calls[0].Method)
+	assert.Equal(t, state.IndexedOperators[preferred[0]].Socket, nodeClient.Calls[0].Arguments.Get(0))
+	requested := getChunksRequestOrder()
+	assert.GreaterOrEqual(t, len(requested), len(preferred))
+	assert.Equal(t, preferred, requested[:len(preferred)])
+	// only the operators needed to reconstruct the blob are requested
+	assert.Less(t, len(requested), numOperators)
+
+	// the other operators are requested when the preferred operators fail
+	nodeClient.Calls = nil
+	nodeClient.ChunkErrors = map[core.OperatorID]error{
+		preferred[0]: errors.New("unavailable"),
+		preferred[1]: errors.New("unavailable"),
+	}
+	data, err = client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0, clients.WithPreferredOperators(preferred...))
+	assert.NoError(t, err)
+	assert.Equal(t, gettysburgAddressBytes, bytes.TrimRight(data, "\x00"))
+	requested = getChunksRequestOrder()
+	assert.Greater(t, len(requested), len(preferred))
+	assert.Equal(t, preferred, requested[:len(preferred)])
+}
+
+func TestRetrieveBlobRejectsIndexSwappedChunks(t *testing.T) {
+
+	setup(t)
+
+	assignments, info, err := coordinator.GetAssignments(operatorState, blobHeader.Length, blobHeader.QuorumInfos[0])
+	assert.NoError(t, err)
+	params, err := core.GetEncodingParams(blobHeader.QuorumInfos[0].ChunkLength, info.TotalChunks)
+	assert.NoError(t, err)
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+
+	opIDs := make([]core.OperatorID, 0, len(assignments))
+	for opID := range assignments {
+		opIDs = append(opIDs, opID)
+	}
+	sort.Slice(opIDs, func(i, j int) bool {
+		return assignments[opIDs[i]].StartIndex < assignments[opIDs[j]].StartIndex
+	})
+
+	// each operator swaps in the first chunk of the next operator, whose proof is valid for the next operator's index
+	swapped := make(core.EncodedBlob, len(opIDs))
+	for i, opID := range opIDs {
+		next := opIDs[(i+1)%len(opIDs)]
+		swappedChunk := encodedBlob[next].Bundles[0][0]
+		err = encoder.VerifyChunks([]*core.Chunk{swappedChunk}, []core.ChunkNumber{assignments[next].StartIndex}, blobHeader.BlobCommitments, params)
+		assert.NoError(t, err)
+
+		own := encodedBlob[opID].Bundles[0]
+		var bundle core.Bundle
+		if i%2 == 0 {
+			// in place of the operator's first chunk
+			bundle = append(core.Bundle{swappedChunk}, own[1:]...)
+		} else {
+			// in addition to the operator's chunks
+			bundle = append(core.Bundle{swappedChunk}, own...)
+		}
+		swapped[opID] = &core.BlobMessage{
+			BlobHeader: blobHeader,
+			Bundles:    map[core.QuorumID]core.Bundle{0: bundle},
+		}
+	}
+
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blobHeader, [][]byte{}, uint64(0), nil)
+	nodeClient.
+		On("GetChunks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(swapped)
+
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	client, err := clients.NewRetrievalClient(logger, indexedChainState, coordinator, nodeClient, encoder, 2, 0, nil)
+	assert.NoError(t, err)
+
+	_, err = client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0)
+	assert.ErrorIs(t, err, clients.ErrDecodeFailed)
+	// every reply is rejected, so all the operators are requested
+	assert.ElementsMatch(t, opIDs, getChunksRequestOrder())
+}
+
+func TestRetrieveBlobStorageAttestations(t *testing.T) {
+
+	setup(t)
+
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blobHeader, [][]byte{}, uint64(0), nil)
+	nodeClient.
+		On("GetChunks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(encodedBlob)
+
+	// the operators with the fewest chunks are preferred, so that more than one of them is needed to reconstruct the blob
+	assignments, _, err := coordinator.GetAssignments(operatorState, blobHeader.Length, blobHeader.QuorumInfos[0])
+	assert.NoError(t, err)
+	opIDs := make([]core.OperatorID, 0, len(assignments))
+	for opID := range assignments {
+		opIDs = append(opIDs, opID)
+	}
+	sort.Slice(opIDs, func(i, j int) bool {
+		return assignments[opIDs[i]].NumChunks < assignments[opIDs[j]].NumChunks
+	})
+
+	// every operator signs its chunks with its own key, except one that signs with another operator's key and one
+	// that returns no attestation
+	privateState := indexedChainState.(*coremock.ChainDataMock).GetTotalOperatorState(context.Background(), 0)
+	nodeClient.StorageKeys = make(map[core.OperatorID]*core.KeyPair, len(privateState.PrivateOperators))
+	for opID, op := range privateState.PrivateOperators {
+		nodeClient.StorageKeys[opID] = op.KeyPair
+	}
+	wrongKey, valid, missing := opIDs[0], opIDs[1], opIDs[2]
+	nodeClient.StorageKeys[wrongKey] = privateState.PrivateOperators[valid].KeyPair
+	delete(nodeClient.StorageKeys, missing)
+
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	// a single connection so that the preferred operators are requested, and their replies processed, first
+	client, err := clients.NewRetrievalClient(logger, indexedChainState, coordinator, nodeClient, encoder, 1, 0, nil)
+	assert.NoError(t, err)
+
+	retrieve := func(preferred ...core.OperatorID) map[core.OperatorID]*clients.StorageAttestation {
+		nodeClient.Calls = nil
+		var attestations []*clients.StorageAttestation
+		data, err := client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0,
+			clients.WithPreferredOperators(preferred...), clients.WithStorageAttestations(&attestations))
+		assert.NoError(t, err)
+		assert.Equal(t, gettysburgAddressBytes, bytes.TrimRight(data, "\x00"))
+		// an attestation is collected from every operator that served chunks
+		assert.Equal(t, len(getChunksRequestOrder()), len(attestations))
+		byOperator := make(map[core.OperatorID]*clients.StorageAttestation, len(attestations))
+		for _, attestation := range attestations {
+			byOperator[attestation.OperatorID] = attestation
+		}
+		for _, opID := range preferred {
+			assert.Contains(t, byOperator, opID)
+		}
+		return byOperator
+	}
+
+	// the chunks of the operators are still used, but the invalid attestation is flagged
+	attestations := retrieve(wrongKey, valid)
+	assert.ErrorIs(t, attestations[wrongKey].Err, clients.ErrInvalidStorageAttestation)
+	assert.NotNil(t, attestations[wrongKey].Signature)
+	assert.NoError(t, attestations[valid].Err)
+	assert.True(t, attestations[valid].Signature.Verify(privateState.PrivateOperators[valid].KeyPair.GetPubKeyG2(), attestations[valid].Message))
+
+	attestations = retrieve(missing)
+	assert.ErrorIs(t, attestations[missing].Err, clients.ErrInvalidStorageAttestation)
+	assert.Nil(t, attestations[missing].Signature)
+}
+
+func TestRetrieveBlobs(t *testing.T) {
+
+	setup(t)
+
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blobHeader, [][]byte{}, uint64(0), nil)
+	nodeClient.
+		On("GetChunks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(encodedBlob)
+
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	client, err := clients.NewRetrievalClient(logger, indexedChainState, coordinator, nodeClient, encoder, 2, 0, nil)
+	assert.NoError(t, err)
+
+	requests := make([]clients.RetrieveRequest, 6)
+	for i := range requests {
+		requests[i] = clients.RetrieveRequest{
+			BatchHeaderHash:      batchHeaderHash,
+			BlobIndex:            0,
+			ReferenceBlockNumber: 0,
+			BatchRoot:            batchRoot,
+			QuorumID:             0,
+		}
+	}
+	results, err := client.RetrieveBlobs(context.Background(), requests, 3)
+	assert.NoError(t, err)
+	assert.Len(t, results, len(requests))
+	for _, result := range results {
+		assert.NoError(t, result.Err)
+		assert.Equal(t, gettysburgAddressBytes, bytes.TrimRight(result.Data, "\x00"))
+	}
+
+	// a failed retrieval doesn't fail the others, and the results are in the order of the requests
+	requests[2].BatchRoot = [32]byte{1}
+	results, err = client.RetrieveBlobs(context.Background(), requests, 3)
+	assert.NoError(t, err)
+	for i, result := range results {
+		if i == 2 {
+			assert.ErrorIs(t, result.Err, clients.ErrBlobHeaderUnavailable)
+			assert.Nil(t, result.Data)
+			continue
+		}
+		assert.NoError(t, result.Err)
+		assert.Equal(t, gettysburgAddressBytes, bytes.TrimRight(result.Data, "\x00"))
+	}
+
+	_, err = client.RetrieveBlobs(context.Background(), requests, 0)
+	assert.Error(t, err)
+}
+
+// fixedOrderSelector orders the operators in a fixed order and records the quorums it's consulted for
+type fixedOrderSelector struct {
+	order   []core.OperatorID
+	quorums []core.QuorumID
+}
+
+func (s *fixedOrderSelector) OrderOperators(state *core.OperatorState, quorumID core.QuorumID, assignments map[core.OperatorID]core.Assignment) []core.OperatorID {
+	s.quorums = append(s.quorums, quorumID)
+	return append([]core.OperatorID{}, s.order...)
+}
+
+func TestRetrieveBlobOperatorSelector(t *testing.T) {
+
+	setup(t)
+
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blobHeader, [][]byte{}, uint64(0), nil)
+	nodeClient.
+		On("GetChunks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(encodedBlob)
+
+	// order the operators by ID, descending
+	order := make([]core.OperatorID, 0, len(operatorState.Operators[0]))
+	for opID := range operatorState.Operators[0] {
+		order = append(order, opID)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return bytes.Compare(order[i][:], order[j][:]) > 0
+	})
+	selector := &fixedOrderSelector{order: order}
+
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	// a single connection so that the operators are requested in order
+	client, err := clients.NewRetrievalClient(logger, indexedChainState, coordinator, nodeClient, encoder, 1, 0, selector)
+	assert.NoError(t, err)
+
+	data, err := client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, gettysburgAddressBytes, bytes.TrimRight(data, "\x00"))
+	assert.Equal(t, []core.QuorumID{0}, selector.quorums)
+	requested := getChunksRequestOrder()
+	assert.NotEmpty(t, requested)
+	assert.Equal(t, order[:len(requested)], requested)
+}
+
+// unregisteredOperatorChainState makes an operator register after the reference block
+type unregisteredOperatorChainState struct {
+	core.IndexedChainState
+	operatorID core.OperatorID
+}
+
+func (s *unregisteredOperatorChainState) GetIndexedOperatorState(ctx context.Context, blockNumber uint, quorums []core.QuorumID) (*core.IndexedOperatorState, error) {
+	state, err := s.IndexedChainState.GetIndexedOperatorState(ctx, blockNumber, quorums)
+	if err != nil {
+		return nil, err
+	}
+	indexedOperators := make(map[core.OperatorID]*core.IndexedOperatorInfo, len(state.IndexedOperators))
+	for id, info := range state.IndexedOperators {
+		indexedOperators[id] = info
+	}
+	info := *indexedOperators[s.operatorID]
+	info.RegistrationBlockNumber = blockNumber + 1
+	indexedOperators[s.operatorID] = &info
+	state.IndexedOperators = indexedOperators
+	return state, nil
+}
+
+func TestRetrieveBlobReferenceBlockBeforeOperatorRegistration(t *testing.T) {
+	setup(t)
+
+	var operatorID core.OperatorID
+	for id := range operatorState.Operators[0] {
+		operatorID = id
+		break
+	}
+	chainState := &unregisteredOperatorChainState{IndexedChainState: indexedChainState, operatorID: operatorID}
+
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	client, err := clients.NewRetrievalClient(logger, chainState, coordinator, nodeClient, encoder, 2, 0, nil)
+	assert.NoError(t, err)
+
+	_, err = client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0)
+	assert.ErrorIs(t, err, core.ErrOperatorNotRegistered)
+	assert.ErrorContains(t, err, "invalid reference block 0")
+	nodeClient.AssertNotCalled(t, "GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRetrieveAllZeroBlob(t *testing.T) {
+
+	// the size is not a multiple of the symbol size, so the blob is padded when encoded
+	data := make([]byte, 100)
+	setupWithData(t, data)
+
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blobHeader, [][]byte{}, uint64(0), nil)
+	nodeClient.
+		On("GetChunks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(encodedBlob)
+
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	client, err := clients.NewRetrievalClient(logger, indexedChainState, coordinator, nodeClient, encoder, 2, 0, nil)
+	assert.NoError(t, err)
+
+	// the exact blob is recovered with its size
+	recovered, err := client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0, clients.WithBlobSize(uint(len(data))))
+	assert.NoError(t, err)
+	assert.Equal(t, data, recovered)
+
+	// without the size, the blob is padded to its length in symbols
+	recovered, err = client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, make([]byte, 124), recovered)
+
+	// a size that doesn't match the blob length is rejected
+	_, err = client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0, clients.WithBlobSize(200))
+	assert.ErrorIs(t, err, clients.ErrBlobSizeMismatch)
+}
+
+// corruptingDecoder flips a bit of the data decoded by the underlying encoder
+type corruptingDecoder struct {
+	core.Encoder
+}
+
+func (d *corruptingDecoder) Decode(chunks []*core.Chunk, indices []core.ChunkNumber, params core.EncodingParams, maxInputSize uint64) ([]byte, error) {
+	data, err := d.Encoder.Decode(chunks, indices, params, maxInputSize)
+	if err != nil {
+		return nil, err
+	}
+	data[0] ^= 1
+	return data, nil
+}
+
+func TestRetrieveBlobDecodeRedundancyCheck(t *testing.T) {
+	setup(t)
+
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blobHeader, [][]byte{}, uint64(0), nil)
+	nodeClient.
+		On("GetChunks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(encodedBlob)
+
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	size := clients.WithBlobSize(uint(len(gettysburgAddressBytes)))
+
+	// a correct decode passes the check
+	client, err := clients.NewRetrievalClient(logger, indexedChainState, coordinator, nodeClient, encoder, 2, 0, nil)
+	assert.NoError(t, err)
+	data, err := client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0, size, clients.WithDecodeRedundancyCheck())
+	assert.NoError(t, err)
+	assert.Equal(t, gettysburgAddressBytes, data)
+
+	// a corrupted decode goes unnoticed without the check, even though all the chunks are valid
+	client, err = clients.NewRetrievalClient(logger, indexedChainState, coordinator, nodeClient, &corruptingDecoder{Encoder: encoder}, 2, 0, nil)
+	assert.NoError(t, err)
+	data, err = client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0, size)
+	assert.NoError(t, err)
+	assert.NotEqual(t, gettysburgAddressBytes, data)
+
+	// the check catches it
+	data, err = client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0, size, clients.WithDecodeRedundancyCheck())
+	assert.ErrorIs(t, err, clients.ErrDecodeRedundancyMismatch)
+	assert.Nil(t, data)
+}
+
+func TestRetrieveBlobHeaderLengthMismatch(t *testing.T) {
+	setup(t)
+
+	// the header claims one symbol fewer than the blob holds, so that decoding up to the header length would silently
+	// truncate the blob
+	mismatched := *blobHeader
+	mismatched.Length--
+	blobHeaderHash, err := mismatched.GetBlobHeaderHash()
+	assert.NoError(t, err)
+	tree, err := merkletree.NewTree(merkletree.WithData([][]byte{blobHeaderHash[:]}), merkletree.WithHashType(keccak256.New()))
+	assert.NoError(t, err)
+	var root [32]byte
+	copy(root[:], tree.Root())
+	headerHash, err := core.BatchHeader{
+		BatchRoot:            root,
+		ReferenceBlockNumber: 0,
+	}.GetBatchHeaderHash()
+	assert.NoError(t, err)
+
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&mismatched, [][]byte{}, uint64(0), nil)
+	nodeClient.
+		On("GetChunks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(encodedBlob)
+
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	client, err := clients.NewRetrievalClient(logger, indexedChainState, coordinator, nodeClient, encoder, 2, 0, nil)
+	assert.NoError(t, err)
+	data, err := client.RetrieveBlob(context.Background(), headerHash, 0, 0, root, 0)
+	assert.ErrorIs(t, err, clients.ErrBlobLengthMismatch)
+	assert.Nil(t, data)
+}
+
+func TestRetrieveBlobCachesOperatorState(t *testing.T) {
+	setup(t)
+
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blobHeader, [][]byte{}, uint64(0), nil)
+	nodeClient.
+		On("GetChunks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(encodedBlob)
+	indexer.On("GetObject", mock.Anything, 0).Return(mustMakeOpertatorPubKeysPair(t), nil)
+	indexer.On("GetObject", mock.Anything, 1).Return(musMakeOperatorSocket(t), nil)
+
+	ics, err := coreindexer.NewIndexedChainState(chainState, indexer)
+	assert.NoError(t, err)
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	client, err := clients.NewRetrievalClient(logger, ics, coordinator, nodeClient, encoder, 2, 0, nil)
+	assert.NoError(t, err)
+
+	// two retrievals at the same reference block fetch the pubkeys and the sockets once
+	for i := 0; i < 2; i++ {
+		data, err := client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0, clients.WithCachedOperatorState())
+		assert.NoError(t, err)
+		assert.Equal(t, gettysburgAddressBytes, bytes.TrimRight(data, "\x00"))
+	}
+	indexer.AssertNumberOfCalls(t, "GetObject", 2)
+
+	// the operator state of another reference block is fetched, and the earlier one stays cached
+	_, err = client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 1, batchRoot, 0, clients.WithCachedOperatorState())
+	assert.NoError(t, err)
+	indexer.AssertNumberOfCalls(t, "GetObject", 4)
+	_, err = client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0, clients.WithCachedOperatorState())
+	assert.NoError(t, err)
+	indexer.AssertNumberOfCalls(t, "GetObject", 4)
+
+	// concurrent retrievals at a new reference block share a single fetch
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 2, batchRoot, 0, clients.WithCachedOperatorState())
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	indexer.AssertNumberOfCalls(t, "GetObject", 6)
+
+	// retrievals without the option always fetch the operator state
+	_, err = client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0)
+	assert.NoError(t, err)
+	indexer.AssertNumberOfCalls(t, "GetObject", 8)
+}
+
+// flakyDecoder fails the first numFailures decodes, and records the number of chunks of each decode
+type flakyDecoder struct {
+	core.Encoder
+	numFailures int
+	numChunks   []int
+}
+
+func (d *flakyDecoder) Decode(chunks []*core.Chunk, indices []core.ChunkNumber, params core.EncodingParams, maxInputSize uint64) ([]byte, error) {
+	d.numChunks = append(d.numChunks, len(chunks))
+	if len(d.numChunks) <= d.numFailures {
+		return nil, errors.New("transient decode failure")
+	}
+	return d.Encoder.Decode(chunks, indices, params, maxInputSize)
+}
+
+func TestRetrieveBlobProgressiveDecode(t *testing.T) {
+	setup(t)
+
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blobHeader, [][]byte{}, uint64(0), nil)
+	nodeClient.
+		On("GetChunks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(encodedBlob)
+
+	// the operators respond in a trickle, one at a time
+	order := make([]core.OperatorID, 0, len(operatorState.Operators[0]))
+	for opID := range operatorState.Operators[0] {
+		order = append(order, opID)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return bytes.Compare(order[i][:], order[j][:]) < 0
+	})
+	delay := 200 * time.Millisecond
+	nodeClient.ChunkDelays = make(map[core.OperatorID]time.Duration)
+	for i, opID := range order {
+		nodeClient.ChunkDelays[opID] = time.Duration(i+1) * delay
+	}
+	selector := &fixedOrderSelector{order: order}
+
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	size := clients.WithBlobSize(uint(len(gettysburgAddressBytes)))
+
+	// without progressive decoding, a single decode is attempted once the minimum number of chunks is in
+	decoder := &flakyDecoder{Encoder: encoder, numFailures: 1}
+	client, err := clients.NewRetrievalClient(logger, indexedChainState, coordinator, nodeClient, decoder, numOperators, 0, selector)
+	assert.NoError(t, err)
+	_, err = client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0, size)
+	assert.ErrorIs(t, err, clients.ErrDecodeFailed)
+	assert.Len(t, decoder.numChunks, 1)
+	minChunks := decoder.numChunks[0]
+
+	// with progressive decoding, the decode is attempted again as soon as the next operator's chunks are in
+	decoder = &flakyDecoder{Encoder: encoder, numFailures: 1}
+	client, err = clients.NewRetrievalClient(logger, indexedChainState, coordinator, nodeClient, decoder, numOperators, 0, selector)
+	assert.NoError(t, err)
+	start := time.Now()
+	data, err := client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0, size, clients.WithProgressiveDecode())
+	assert.NoError(t, err)
+	assert.Equal(t, gettysburgAddressBytes, data)
+	assert.Len(t, decoder.numChunks, 2)
+	assert.Equal(t, minChunks, decoder.numChunks[0])
+	assert.Greater(t, decoder.numChunks[1], minChunks)
+	assert.Less(t, time.Since(start), time.Duration(len(order))*delay)
+}
+
+// onchainBatchRoots maps the batch header hashes to the batch roots confirmed onchain
+type onchainBatchRoots map[[32]byte][32]byte
+
+func (r onchainBatchRoots) FetchBatchRoot(ctx context.Context, batchHeaderHash [32]byte) ([32]byte, error) {
+	root, ok := r[batchHeaderHash]
+	if !ok {
+		return [32]byte{}, errors.New("batch not confirmed")
+	}
+	return root, nil
+}
+
+func TestRetrieveBlobOnchainBatchRoot(t *testing.T) {
+	setup(t)
+
+	nodeClient.On("GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(blobHeader, [][]byte{}, uint64(0), nil)
+	nodeClient.
+		On("GetChunks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(encodedBlob)
+
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	client, err := clients.NewRetrievalClient(logger, indexedChainState, coordinator, nodeClient, encoder, 2, 0, nil)
+	assert.NoError(t, err)
+	onchain := clients.WithOnchainBatchRoot(onchainBatchRoots{batchHeaderHash: batchRoot})
+	forgedRoot := [32]byte{1, 2, 3}
+
+	// a root that differs from the onchain root is rejected before any operator is queried
+	_, err = client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, forgedRoot, 0, onchain)
+	assert.ErrorIs(t, err, clients.ErrBatchRootMismatch)
+	nodeClient.AssertNotCalled(t, "GetBlobHeader", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// the onchain root is used if no root is given
+	data, err := client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, [32]byte{}, 0, onchain)
+	assert.NoError(t, err)
+	assert.Equal(t, gettysburgAddressBytes, bytes.TrimRight(data, "\x00"))
+
+	// a root matching the onchain root is accepted
+	data, err = client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, batchRoot, 0, onchain)
+	assert.NoError(t, err)
+	assert.Equal(t, gettysburgAddressBytes, bytes.TrimRight(data, "\x00"))
+
+	// the onchain root is authoritative: the blob header is verified against it rather than the given root
+	forgedOnchain := clients.WithOnchainBatchRoot(onchainBatchRoots{batchHeaderHash: forgedRoot})
+	_, err = client.RetrieveBlob(context.Background(), batchHeaderHash, 0, 0, [32]byte{}, 0, forgedOnchain)
+	assert.ErrorIs(t, err, clients.ErrBlobHeaderUnavailable)
+
+	// the retrieval fails if the batch isn't confirmed onchain
+	_, err = client.RetrieveBlob(context.Background(), [32]byte{4, 5, 6}, 0, 0, batchRoot, 0, onchain)
+	assert.ErrorContains(t, err, "failed to fetch the onchain batch root")
 }
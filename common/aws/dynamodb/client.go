@@ -2,6 +2,7 @@ package dynamodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"sync"
@@ -19,6 +20,8 @@ import (
 const (
 	// dynamoBatchLimit is the maximum number of items that can be written in a single batch
 	dynamoBatchLimit = 25
+	// dynamoBatchGetLimit is the maximum number of items that can be read in a single batch
+	dynamoBatchGetLimit = 100
 )
 
 type batchOperation uint
@@ -33,6 +36,9 @@ var (
 	clientRef *Client
 )
 
+// ErrConditionFailed is returned by conditional writes whose condition doesn't hold
+var ErrConditionFailed = errors.New("condition failed")
+
 type Item = map[string]types.AttributeValue
 type Key = map[string]types.AttributeValue
 type ExpresseionValues = map[string]types.AttributeValue
@@ -105,6 +111,25 @@ func (c *Client) PutItem(ctx context.Context, tableName string, item Item) (err
 	return nil
 }
 
+// PutItemWithCondition puts the item if the condition expression holds for the item it replaces, if any, and returns
+// ErrConditionFailed otherwise
+func (c *Client) PutItemWithCondition(ctx context.Context, tableName string, item Item, condition string, expAttributeValues ExpresseionValues) error {
+	if len(expAttributeValues) == 0 {
+		expAttributeValues = nil
+	}
+	_, err := c.dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(tableName),
+		Item:                      item,
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeValues: expAttributeValues,
+	})
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return ErrConditionFailed
+	}
+	return err
+}
+
 // PutItems puts items in batches of 25 items (which is a limit DynamoDB imposes)
 // It returns the items that failed to be put.
 func (c *Client) PutItems(ctx context.Context, tableName string, items []Item) ([]Item, error) {
@@ -142,6 +167,101 @@ func (c *Client) UpdateItem(ctx context.Context, tableName string, key Key, item
 	return resp.Attributes, err
 }
 
+// ConditionalUpdate sets the attributes of Item on the item with Key, if Condition holds for the item
+type ConditionalUpdate struct {
+	Key       Key
+	Item      Item
+	Condition expression.ConditionBuilder
+}
+
+// UpdateItemWithCondition sets the attributes of the item with the given key if the condition holds for it, and
+// returns ErrConditionFailed otherwise
+func (c *Client) UpdateItemWithCondition(ctx context.Context, tableName string, key Key, item Item, condition expression.ConditionBuilder) error {
+	update, err := buildConditionalUpdate(tableName, ConditionalUpdate{Key: key, Item: item, Condition: condition})
+	if err != nil {
+		return err
+	}
+	_, err = c.dynamoClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 update.TableName,
+		Key:                       update.Key,
+		UpdateExpression:          update.UpdateExpression,
+		ConditionExpression:       update.ConditionExpression,
+		ExpressionAttributeNames:  update.ExpressionAttributeNames,
+		ExpressionAttributeValues: update.ExpressionAttributeValues,
+	})
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return ErrConditionFailed
+	}
+	return err
+}
+
+// UpdateItemsWithCondition applies the conditional updates in transactions of up to 25 items. A transaction is
+// cancelled as a whole if the condition of one of its updates fails, in which case the other updates of the
+// transaction are applied one at a time. It returns the keys of the updates whose condition failed.
+func (c *Client) UpdateItemsWithCondition(ctx context.Context, tableName string, updates []ConditionalUpdate) ([]Key, error) {
+	failedKeys := make([]Key, 0)
+	for start := 0; start < len(updates); start += dynamoBatchLimit {
+		end := start + dynamoBatchLimit
+		if end > len(updates) {
+			end = len(updates)
+		}
+		batch := updates[start:end]
+		transactItems := make([]types.TransactWriteItem, len(batch))
+		for i, update := range batch {
+			u, err := buildConditionalUpdate(tableName, update)
+			if err != nil {
+				return nil, err
+			}
+			transactItems[i] = types.TransactWriteItem{Update: u}
+		}
+		_, err := c.dynamoClient.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: transactItems})
+		if err == nil {
+			continue
+		}
+		var cancelledErr *types.TransactionCanceledException
+		if !errors.As(err, &cancelledErr) {
+			return nil, err
+		}
+		// apply the updates of the cancelled transaction one at a time, so that only the updates whose condition
+		// fails are left out
+		for _, update := range batch {
+			err := c.UpdateItemWithCondition(ctx, tableName, update.Key, update.Item, update.Condition)
+			if errors.Is(err, ErrConditionFailed) {
+				failedKeys = append(failedKeys, update.Key)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return failedKeys, nil
+}
+
+func buildConditionalUpdate(tableName string, update ConditionalUpdate) (*types.Update, error) {
+	updateBuilder := expression.UpdateBuilder{}
+	for itemKey, itemValue := range update.Item {
+		if _, ok := update.Key[itemKey]; ok {
+			// Cannot update the key
+			continue
+		}
+		updateBuilder = updateBuilder.Set(expression.Name(itemKey), expression.Value(itemValue))
+	}
+	expr, err := expression.NewBuilder().WithUpdate(updateBuilder).WithCondition(update.Condition).Build()
+	if err != nil {
+		return nil, err
+	}
+	return &types.Update{
+		TableName:                 aws.String(tableName),
+		Key:                       update.Key,
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}, nil
+}
+
 func (c *Client) GetItem(ctx context.Context, tableName string, key Key) (Item, error) {
 	resp, err := c.dynamoClient.GetItem(ctx, &dynamodb.GetItemInput{Key: key, TableName: aws.String(tableName)})
 	if err != nil {
@@ -151,6 +271,56 @@ func (c *Client) GetItem(ctx context.Context, tableName string, key Key) (Item,
 	return resp.Item, nil
 }
 
+// GetItemWithConsistentRead returns the item with the given key, reflecting all the writes to it that succeeded before
+// the read
+func (c *Client) GetItemWithConsistentRead(ctx context.Context, tableName string, key Key) (Item, error) {
+	resp, err := c.dynamoClient.GetItem(ctx, &dynamodb.GetItemInput{Key: key, TableName: aws.String(tableName), ConsistentRead: aws.Bool(true)})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Item, nil
+}
+
+// GetItems returns the items with the given keys, reading them in batches of 100 keys (which is a limit DynamoDB
+// imposes). Items that don't exist are left out, and the items are returned in no particular order.
+func (c *Client) GetItems(ctx context.Context, tableName string, keys []Key) ([]Item, error) {
+	items := make([]Item, 0, len(keys))
+	for start := 0; start < len(keys); start += dynamoBatchGetLimit {
+		end := start + dynamoBatchGetLimit
+		if end > len(keys) {
+			end = len(keys)
+		}
+		requestItems := map[string]types.KeysAndAttributes{
+			tableName: {Keys: keys[start:end]},
+		}
+		// retry the keys DynamoDB leaves unprocessed, e.g. when the request exceeds the provisioned throughput
+		for len(requestItems) > 0 {
+			output, err := c.dynamoClient.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: requestItems})
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, output.Responses[tableName]...)
+			requestItems = output.UnprocessedKeys
+		}
+	}
+	return items, nil
+}
+
+// Query returns all items in the table that match the given key
+func (c *Client) Query(ctx context.Context, tableName string, keyCondition string, expAttributeValues ExpresseionValues) ([]Item, error) {
+	response, err := c.dynamoClient.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(tableName),
+		KeyConditionExpression:    aws.String(keyCondition),
+		ExpressionAttributeValues: expAttributeValues,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Items, nil
+}
+
 // QueryIndex returns all items in the index that match the given key
 func (c *Client) QueryIndex(ctx context.Context, tableName string, indexName string, keyCondition string, expAttributeValues ExpresseionValues) ([]Item, error) {
 	response, err := c.dynamoClient.Query(ctx, &dynamodb.QueryInput{
@@ -277,7 +447,11 @@ func (c *Client) writeItems(ctx context.Context, tableName string, requestItems
 		// check for unprocessed items
 		if len(output.UnprocessedItems) > 0 {
 			for _, req := range output.UnprocessedItems[tableName] {
-				failedItems = append(failedItems, req.DeleteRequest.Key)
+				if req.PutRequest != nil {
+					failedItems = append(failedItems, req.PutRequest.Item)
+				} else if req.DeleteRequest != nil {
+					failedItems = append(failedItems, req.DeleteRequest.Key)
+				}
 			}
 		}
 
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+type ConnectionPoolConfig struct {
+	// IdleTimeout is the duration without any request after which a connection releases its transport. The connection
+	// reconnects on the next request. 0 disables the idle timeout.
+	IdleTimeout time.Duration
+	// KeepaliveTime is the interval of the keepalive pings sent on the connections. 0 disables keepalive pings.
+	KeepaliveTime time.Duration
+	// KeepaliveTimeout is the duration to wait for a keepalive ping to be acknowledged before closing the transport
+	KeepaliveTimeout time.Duration
+}
+
+// ConnectionPool reuses gRPC connections across requests. Connections are keyed by their owner (e.g. an operator), and
+// the connection of an owner is closed and dialed again when the socket of the owner changes, so that a stale socket
+// is never reused nor shared with another owner.
+type ConnectionPool struct {
+	dialOptions []grpc.DialOption
+
+	mu    sync.Mutex
+	conns map[string]*pooledConnection
+}
+
+type pooledConnection struct {
+	socket string
+	conn   *grpc.ClientConn
+}
+
+func NewConnectionPool(config ConnectionPoolConfig, dialOptions ...grpc.DialOption) *ConnectionPool {
+	if len(dialOptions) == 0 {
+		dialOptions = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	if config.IdleTimeout > 0 {
+		dialOptions = append(dialOptions, grpc.WithIdleTimeout(config.IdleTimeout))
+	}
+	if config.KeepaliveTime > 0 {
+		dialOptions = append(dialOptions, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    config.KeepaliveTime,
+			Timeout: config.KeepaliveTimeout,
+		}))
+	}
+
+	return &ConnectionPool{
+		dialOptions: dialOptions,
+		conns:       make(map[string]*pooledConnection),
+	}
+}
+
+// GetConnection returns the connection of the given owner to the given socket, dialing a new one if the owner has no
+// connection in the pool. If the socket of the owner changed since its connection was dialed, the connection to the
+// previous socket is closed and replaced.
+func (p *ConnectionPool) GetConnection(owner string, socket string) (*grpc.ClientConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pooled, ok := p.conns[owner]; ok {
+		if pooled.socket == socket {
+			return pooled.conn, nil
+		}
+		p.closeConnection(owner)
+	}
+
+	conn, err := grpc.Dial(socket, p.dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+	p.conns[owner] = &pooledConnection{socket: socket, conn: conn}
+	return conn, nil
+}
+
+// Invalidate closes the connection of the given owner so that its next request dials a new connection
+func (p *ConnectionPool) Invalidate(owner string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closeConnection(owner)
+}
+
+// Size returns the number of connections in the pool
+func (p *ConnectionPool) Size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.conns)
+}
+
+// Close closes all connections in the pool
+func (p *ConnectionPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for owner := range p.conns {
+		p.closeConnection(owner)
+	}
+}
+
+func (p *ConnectionPool) closeConnection(owner string) {
+	pooled, ok := p.conns[owner]
+	if !ok {
+		return
+	}
+	delete(p.conns, owner)
+	_ = pooled.conn.Close()
+}
@@ -0,0 +1,39 @@
+package common_test
+
+import (
+	"testing"
+
+	"github.com/Layr-Labs/eigenda/common"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/connectivity"
+)
+
+func TestConnectionPoolSocketChange(t *testing.T) {
+	pool := common.NewConnectionPool(common.ConnectionPoolConfig{})
+	defer pool.Close()
+
+	conn, err := pool.GetConnection("operator1", "127.0.0.1:32001")
+	assert.NoError(t, err)
+	reused, err := pool.GetConnection("operator1", "127.0.0.1:32001")
+	assert.NoError(t, err)
+	assert.Same(t, conn, reused)
+
+	// owners sharing a socket don't share a connection, so that one of them updating its socket doesn't close the
+	// connection of the other
+	other, err := pool.GetConnection("operator2", "127.0.0.1:32001")
+	assert.NoError(t, err)
+	assert.NotSame(t, conn, other)
+	assert.Equal(t, 2, pool.Size())
+
+	// the connection to the previous socket of the owner is closed when its socket changes
+	updated, err := pool.GetConnection("operator1", "127.0.0.1:32002")
+	assert.NoError(t, err)
+	assert.NotSame(t, conn, updated)
+	assert.Equal(t, connectivity.Shutdown, conn.GetState())
+	assert.NotEqual(t, connectivity.Shutdown, other.GetState())
+	assert.Equal(t, 2, pool.Size())
+
+	pool.Invalidate("operator1")
+	assert.Equal(t, connectivity.Shutdown, updated.GetState())
+	assert.Equal(t, 1, pool.Size())
+}
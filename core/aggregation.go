@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"sort"
+	"time"
 
 	"github.com/Layr-Labs/eigenda/common"
 	gethcommon "github.com/ethereum/go-ethereum/common"
@@ -19,12 +21,20 @@ var (
 	ErrPubKeysNotEqual     = errors.New("public keys are not equal")
 	ErrInsufficientEthSigs = errors.New("insufficient eth signatures")
 	ErrAggSigNotValid      = errors.New("aggregated signature is not valid")
+	ErrMissingQuorumResult = errors.New("missing quorum result")
 )
 
 type SignerMessage struct {
 	Signature *Signature
 	Operator  OperatorID
 	Err       error
+	// Latency is the time between dispatching the batch to the operator and receiving its reply. Zero if unknown.
+	Latency time.Duration
+}
+
+// SigningLatencyRecorder records how long each operator took to return a valid signature
+type SigningLatencyRecorder interface {
+	ObserveSigningLatency(operatorID OperatorID, latency time.Duration)
 }
 
 // SignatureAggregation contains the results of aggregating signatures from a set of operators
@@ -43,14 +53,21 @@ type SignatureAggregation struct {
 	AggSignature *Signature
 	// QuorumResults contains the quorum ID and the amount signed for each quorum
 	QuorumResults map[QuorumID]*QuorumResult
+	// Scheme is the signing scheme of the signatures
+	Scheme SigningSchemeID
 }
 
 // SignatureAggregator is an interface for aggregating the signatures returned by DA nodes so that they can be verified by the DA contract
 type SignatureAggregator interface {
 
 	// AggregateSignatures blocks until it receives a response for each operator in the operator state via messageChan, and then returns the aggregated signature.
-	// If the aggregated signature is invalid, an error is returned.
-	AggregateSignatures(ctx context.Context, state *IndexedOperatorState, quorumIDs []QuorumID, message [32]byte, messageChan chan SignerMessage) (*SignatureAggregation, error)
+	// The signatures are verified under the given signing scheme. If the aggregated signature is invalid, an error is returned.
+	AggregateSignatures(ctx context.Context, state *IndexedOperatorState, quorumIDs []QuorumID, scheme SigningSchemeID, message [32]byte, messageChan chan SignerMessage) (*SignatureAggregation, error)
+
+	// VerifyAggregation verifies an aggregation returned by AggregateSignatures for the given quorums against the operator state:
+	// the aggregated public key must match the quorum aggregate public keys without the non-signers, and the aggregated
+	// signature must be valid for the message under the aggregated public key.
+	VerifyAggregation(state *IndexedOperatorState, quorumIDs []QuorumID, message [32]byte, aggregation *SignatureAggregation) error
 }
 
 type StdSignatureAggregator struct {
@@ -58,27 +75,43 @@ type StdSignatureAggregator struct {
 	Transactor Transactor
 	// OperatorAddresses contains the ethereum addresses of the operators corresponding to their operator IDs
 	OperatorAddresses *lru.Cache[OperatorID, gethcommon.Address]
+	// LatencyRecorder, if set, is notified of the signing latency of each operator that returns a valid signature
+	LatencyRecorder SigningLatencyRecorder
+	// Schemes contains the signing schemes the aggregator supports
+	Schemes SigningSchemes
 }
 
-func NewStdSignatureAggregator(logger common.Logger, transactor Transactor) (*StdSignatureAggregator, error) {
+// NewStdSignatureAggregator creates an aggregator supporting the BN254 BLS signing scheme and the given additional schemes
+func NewStdSignatureAggregator(logger common.Logger, transactor Transactor, schemes ...SigningScheme) (*StdSignatureAggregator, error) {
 	operatorAddrs, err := lru.New[OperatorID, gethcommon.Address](maxNumOperatorAddresses)
 	if err != nil {
 		return nil, err
 	}
 
+	supported := SigningSchemes{SigningSchemeBN254BLS: NewBN254BLSScheme()}
+	for _, scheme := range schemes {
+		supported[scheme.ID()] = scheme
+	}
+
 	return &StdSignatureAggregator{
 		Logger:            logger,
 		Transactor:        transactor,
 		OperatorAddresses: operatorAddrs,
+		Schemes:           supported,
 	}, nil
 }
 
 var _ SignatureAggregator = (*StdSignatureAggregator)(nil)
 
-func (a *StdSignatureAggregator) AggregateSignatures(ctx context.Context, state *IndexedOperatorState, quorumIDs []QuorumID, message [32]byte, messageChan chan SignerMessage) (*SignatureAggregation, error) {
+func (a *StdSignatureAggregator) AggregateSignatures(ctx context.Context, state *IndexedOperatorState, quorumIDs []QuorumID, scheme SigningSchemeID, message [32]byte, messageChan chan SignerMessage) (*SignatureAggregation, error) {
 
 	// TODO: Add logging
 
+	signingScheme, err := a.Schemes.Get(scheme)
+	if err != nil {
+		return nil, err
+	}
+
 	// Ensure all quorums are found in state
 	for _, id := range quorumIDs {
 		_, found := state.Operators[id]
@@ -133,13 +166,16 @@ func (a *StdSignatureAggregator) AggregateSignatures(ctx context.Context, state
 
 		// Verify Signature
 		sig := r.Signature
-		ok = sig.Verify(op.PubkeyG2, message)
+		ok = signingScheme.Verify(op.PubkeyG2, message, sig)
 		if !ok {
 			a.Logger.Error("Signature is not valid", "operatorID", operatorIDHex, "operatorAddress", operatorAddr, "socket", socket, "pubkey", hexutil.Encode(op.PubkeyG2.Serialize()))
 			continue
 		}
 
-		a.Logger.Info("[AggregateSignatures] received signature from operator", "operatorID", operatorIDHex, "operatorAddress", operatorAddr, "socket", socket)
+		a.Logger.Info("[AggregateSignatures] received signature from operator", "operatorID", operatorIDHex, "operatorAddress", operatorAddr, "socket", socket, "latency", r.Latency)
+		if a.LatencyRecorder != nil && r.Latency > 0 {
+			a.LatencyRecorder.ObserveSigningLatency(r.Operator, r.Latency)
+		}
 
 		for ind, id := range quorumIDs {
 
@@ -219,12 +255,16 @@ func (a *StdSignatureAggregator) AggregateSignatures(ctx context.Context, state
 		}
 
 		// Verify the aggregated signature for the quorum
-		ok = aggSigs[ind].Verify(aggPubKeys[ind], message)
+		ok = signingScheme.Verify(aggPubKeys[ind], message, aggSigs[ind])
 		if !ok {
 			return nil, ErrAggSigNotValid
 		}
 	}
 
+	if err := ValidateQuorumResults(quorumIDs, quorumResults); err != nil {
+		return nil, err
+	}
+
 	// Aggregate the aggregated signatures. We reuse the first aggregated signature as the accumulator
 	for i := 1; i < len(aggSigs); i++ {
 		aggSigs[0].Add(aggSigs[i].G1Point)
@@ -250,10 +290,92 @@ func (a *StdSignatureAggregator) AggregateSignatures(ctx context.Context, state
 		AggPubKey:        aggPubKeys[0],
 		AggSignature:     aggSigs[0],
 		QuorumResults:    quorumResults,
+		Scheme:           scheme,
 	}, nil
 
 }
 
+func (a *StdSignatureAggregator) VerifyAggregation(state *IndexedOperatorState, quorumIDs []QuorumID, message [32]byte, aggregation *SignatureAggregation) error {
+	if aggregation == nil || aggregation.AggPubKey == nil || aggregation.AggSignature == nil {
+		return ErrAggSigNotValid
+	}
+	signingScheme, err := a.Schemes.Get(aggregation.Scheme)
+	if err != nil {
+		return err
+	}
+	if len(aggregation.QuorumAggPubKeys) != len(quorumIDs) {
+		return fmt.Errorf("expected %d quorum aggregate public keys, got %d", len(quorumIDs), len(aggregation.QuorumAggPubKeys))
+	}
+
+	operatorsByPubkey := make(map[string]OperatorID, len(state.IndexedOperators))
+	for id, op := range state.IndexedOperators {
+		operatorsByPubkey[string(op.PubkeyG1.Serialize())] = id
+	}
+	nonSignerIDs := make([]OperatorID, len(aggregation.NonSigners))
+	for i, nonSigner := range aggregation.NonSigners {
+		id, ok := operatorsByPubkey[string(nonSigner.Serialize())]
+		if !ok {
+			return fmt.Errorf("non-signer %s is not in the operator state", hexutil.Encode(nonSigner.Serialize()))
+		}
+		nonSignerIDs[i] = id
+	}
+
+	// The aggregated public key is the sum over the quorums of the quorum aggregate public keys without the non-signers
+	var signersAggKey *G1Point
+	for ind, id := range quorumIDs {
+		quorumAggKey, ok := state.AggKeys[id]
+		if !ok {
+			return errors.New("quorum not found")
+		}
+		if !bytes.Equal(quorumAggKey.Serialize(), aggregation.QuorumAggPubKeys[ind].Serialize()) {
+			return fmt.Errorf("aggregate public key of quorum %d does not match the operator state", id)
+		}
+
+		quorumSignersKey := quorumAggKey.Deserialize(quorumAggKey.Serialize())
+		for i, nonSigner := range aggregation.NonSigners {
+			if _, ok := state.Operators[id][nonSignerIDs[i]]; ok {
+				quorumSignersKey.Sub(nonSigner)
+			}
+		}
+		if signersAggKey == nil {
+			signersAggKey = quorumSignersKey
+		} else {
+			signersAggKey.Add(quorumSignersKey)
+		}
+	}
+	if signersAggKey == nil {
+		return ErrAggSigNotValid
+	}
+
+	ok, err := signersAggKey.VerifyEquivalence(aggregation.AggPubKey)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPubKeysNotEqual
+	}
+
+	if !signingScheme.Verify(aggregation.AggPubKey, message, aggregation.AggSignature) {
+		return ErrAggSigNotValid
+	}
+	return nil
+}
+
+// ValidateQuorumResults checks that there is an explicit result for each of the given quorums, including the quorums
+// that no operator signed for, so that a missing result is never mistaken for an unsigned quorum
+func ValidateQuorumResults(quorumIDs []QuorumID, results map[QuorumID]*QuorumResult) error {
+	for _, id := range quorumIDs {
+		result, ok := results[id]
+		if !ok || result == nil {
+			return fmt.Errorf("%w: quorum %d", ErrMissingQuorumResult, id)
+		}
+		if result.QuorumID != id {
+			return fmt.Errorf("%w: result for quorum %d has quorum ID %d", ErrMissingQuorumResult, id, result.QuorumID)
+		}
+	}
+	return nil
+}
+
 func GetStakeThreshold(state *OperatorState, quorum QuorumID, quorumThreshold uint8) *big.Int {
 
 	// Get stake threshold
@@ -274,3 +396,30 @@ func GetSignedPercentage(state *OperatorState, quorum QuorumID, stakeAmount *big
 
 	return quorumThreshold
 }
+
+// ComputePercentSigned returns the PercentSigned the quorum results of an aggregation would report for each quorum of the
+// state if the given operators signed, weighting the operators by their stake in each quorum like the aggregator does.
+// Signers that aren't in a quorum don't count toward it, and a quorum without stake is reported as 0 percent signed.
+func ComputePercentSigned(state *OperatorState, signers []OperatorID) map[QuorumID]uint8 {
+	signerSet := make(map[OperatorID]struct{}, len(signers))
+	for _, id := range signers {
+		signerSet[id] = struct{}{}
+	}
+
+	percentSigned := make(map[QuorumID]uint8, len(state.Operators))
+	for quorumID, operators := range state.Operators {
+		total, ok := state.Totals[quorumID]
+		if !ok || total.Stake == nil || total.Stake.Sign() == 0 {
+			percentSigned[quorumID] = 0
+			continue
+		}
+		stakeSigned := big.NewInt(0)
+		for id := range signerSet {
+			if op, ok := operators[id]; ok {
+				stakeSigned.Add(stakeSigned, op.Stake)
+			}
+		}
+		percentSigned[quorumID] = GetSignedPercentage(state, quorumID, stakeSigned)
+	}
+	return percentSigned
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"math/rand"
 	"os"
 	"testing"
+	"time"
 
 	commonmock "github.com/Layr-Labs/eigenda/common/mock"
 	"github.com/Layr-Labs/eigenda/core"
@@ -130,7 +132,7 @@ func TestAggregateSignaturesStatus(t *testing.T) {
 				quorumIDs[ind] = quorum.QuorumID
 			}
 
-			sigAgg, err := agg.AggregateSignatures(context.Background(), state.IndexedOperatorState, quorumIDs, message, update)
+			sigAgg, err := agg.AggregateSignatures(context.Background(), state.IndexedOperatorState, quorumIDs, core.SigningSchemeBN254BLS, message, update)
 			assert.NoError(t, err)
 
 			for _, quorum := range tt.quorums {
@@ -156,7 +158,7 @@ func TestSortNonsigners(t *testing.T) {
 
 	quorums := []core.QuorumID{0}
 
-	sigAgg, err := agg.AggregateSignatures(context.Background(), state.IndexedOperatorState, quorums, message, update)
+	sigAgg, err := agg.AggregateSignatures(context.Background(), state.IndexedOperatorState, quorums, core.SigningSchemeBN254BLS, message, update)
 	assert.NoError(t, err)
 
 	for i := range sigAgg.NonSigners {
@@ -170,3 +172,239 @@ func TestSortNonsigners(t *testing.T) {
 		assert.Equal(t, currHashInt.Cmp(prevHashInt), 1)
 	}
 }
+
+// latencyRecorder records the signing latencies observed by the aggregator
+type latencyRecorder struct {
+	latencies map[core.OperatorID]time.Duration
+}
+
+func (r *latencyRecorder) ObserveSigningLatency(operatorID core.OperatorID, latency time.Duration) {
+	r.latencies[operatorID] = latency
+}
+
+func TestAggregateSignaturesSigningLatency(t *testing.T) {
+	logger := &commonmock.Logger{}
+	transactor := &mock.MockTransactor{}
+	transactor.On("OperatorIDToAddress").Return(gethcommon.Address{}, nil)
+	aggregator, err := core.NewStdSignatureAggregator(logger, transactor)
+	assert.NoError(t, err)
+	recorder := &latencyRecorder{latencies: make(map[core.OperatorID]time.Duration)}
+	aggregator.LatencyRecorder = recorder
+
+	state := dat.GetTotalOperatorState(context.Background(), 0)
+	update := make(chan core.SignerMessage)
+	message := [32]byte{1, 2, 3, 4, 5, 6}
+
+	// every operator signs at a different time except the last one, which never signs
+	numOperators := len(state.PrivateOperators)
+	nonSigner := makeOperatorId(numOperators - 1)
+	go func() {
+		for i := 0; i < numOperators; i++ {
+			id := makeOperatorId(i)
+			latency := time.Duration(i+1) * 100 * time.Millisecond
+			if id == nonSigner {
+				update <- core.SignerMessage{
+					Operator: id,
+					Err:      errors.New("timed out"),
+					Latency:  latency,
+				}
+				continue
+			}
+			update <- core.SignerMessage{
+				Signature: state.PrivateOperators[id].KeyPair.SignMessage(message),
+				Operator:  id,
+				Latency:   latency,
+			}
+		}
+	}()
+
+	_, err = aggregator.AggregateSignatures(context.Background(), state.IndexedOperatorState, []core.QuorumID{0}, core.SigningSchemeBN254BLS, message, update)
+	assert.NoError(t, err)
+
+	assert.Len(t, recorder.latencies, numOperators-1)
+	for i := 0; i < numOperators-1; i++ {
+		assert.Equal(t, time.Duration(i+1)*100*time.Millisecond, recorder.latencies[makeOperatorId(i)])
+	}
+	_, ok := recorder.latencies[nonSigner]
+	assert.False(t, ok)
+}
+
+func TestVerifyAggregation(t *testing.T) {
+	state := dat.GetTotalOperatorState(context.Background(), 0)
+	quorumIDs := []core.QuorumID{0, 1}
+	message := [32]byte{1, 2, 3, 4, 5, 6}
+
+	aggregate := func() *core.SignatureAggregation {
+		update := make(chan core.SignerMessage)
+		go simulateOperators(*state, message, update, 2)
+		sigAgg, err := agg.AggregateSignatures(context.Background(), state.IndexedOperatorState, quorumIDs, core.SigningSchemeBN254BLS, message, update)
+		assert.NoError(t, err)
+		return sigAgg
+	}
+
+	sigAgg := aggregate()
+	assert.NoError(t, agg.VerifyAggregation(state.IndexedOperatorState, quorumIDs, message, sigAgg))
+
+	// signature over another message
+	assert.ErrorIs(t, agg.VerifyAggregation(state.IndexedOperatorState, quorumIDs, [32]byte{7}, sigAgg), core.ErrAggSigNotValid)
+
+	// tampered signature
+	sigAgg = aggregate()
+	sigAgg.AggSignature.Add(sigAgg.AggSignature.G1Point)
+	assert.ErrorIs(t, agg.VerifyAggregation(state.IndexedOperatorState, quorumIDs, message, sigAgg), core.ErrAggSigNotValid)
+
+	// a non-signer is omitted, so the aggregated public key doesn't match the signers
+	sigAgg = aggregate()
+	sigAgg.NonSigners = sigAgg.NonSigners[1:]
+	assert.ErrorIs(t, agg.VerifyAggregation(state.IndexedOperatorState, quorumIDs, message, sigAgg), core.ErrPubKeysNotEqual)
+}
+
+func TestAggregateSignaturesQuorumResults(t *testing.T) {
+	state := dat.GetTotalOperatorState(context.Background(), 0)
+	quorumIDs := []core.QuorumID{0, 1, 2}
+	message := [32]byte{1, 2, 3, 4, 5, 6}
+
+	update := make(chan core.SignerMessage)
+	go simulateOperators(*state, message, update, 4)
+	sigAgg, err := agg.AggregateSignatures(context.Background(), state.IndexedOperatorState, quorumIDs, core.SigningSchemeBN254BLS, message, update)
+	assert.NoError(t, err)
+
+	// every requested quorum has an explicit result, and no other quorum has one
+	assert.Len(t, sigAgg.QuorumResults, len(quorumIDs))
+	for _, id := range quorumIDs {
+		result, ok := sigAgg.QuorumResults[id]
+		if assert.True(t, ok) && assert.NotNil(t, result) {
+			assert.Equal(t, id, result.QuorumID)
+			assert.NotZero(t, result.PercentSigned)
+		}
+	}
+	assert.NoError(t, core.ValidateQuorumResults(quorumIDs, sigAgg.QuorumResults))
+}
+
+func TestValidateQuorumResults(t *testing.T) {
+	quorumIDs := []core.QuorumID{0, 1}
+
+	// a zero-signed quorum is a valid explicit result
+	err := core.ValidateQuorumResults(quorumIDs, map[core.QuorumID]*core.QuorumResult{
+		0: {QuorumID: 0, PercentSigned: 100},
+		1: {QuorumID: 1, PercentSigned: 0},
+	})
+	assert.NoError(t, err)
+
+	err = core.ValidateQuorumResults(quorumIDs, map[core.QuorumID]*core.QuorumResult{
+		0: {QuorumID: 0, PercentSigned: 100},
+	})
+	assert.ErrorIs(t, err, core.ErrMissingQuorumResult)
+
+	err = core.ValidateQuorumResults(quorumIDs, map[core.QuorumID]*core.QuorumResult{
+		0: {QuorumID: 0, PercentSigned: 100},
+		1: nil,
+	})
+	assert.ErrorIs(t, err, core.ErrMissingQuorumResult)
+
+	// a result stored under the wrong quorum
+	err = core.ValidateQuorumResults(quorumIDs, map[core.QuorumID]*core.QuorumResult{
+		0: {QuorumID: 0, PercentSigned: 100},
+		1: {QuorumID: 0, PercentSigned: 100},
+	})
+	assert.ErrorIs(t, err, core.ErrMissingQuorumResult)
+}
+
+// renumberedScheme is BN254 BLS registered under another scheme ID
+type renumberedScheme struct {
+	core.SigningScheme
+	id core.SigningSchemeID
+}
+
+func (s renumberedScheme) ID() core.SigningSchemeID {
+	return s.id
+}
+
+func TestAggregateSignaturesSigningScheme(t *testing.T) {
+	state := dat.GetTotalOperatorState(context.Background(), 0)
+	quorumIDs := []core.QuorumID{0}
+	message := [32]byte{1, 2, 3, 4, 5, 6}
+
+	// the default scheme is recorded in the aggregation and used to verify it
+	update := make(chan core.SignerMessage)
+	go simulateOperators(*state, message, update, 0)
+	sigAgg, err := agg.AggregateSignatures(context.Background(), state.IndexedOperatorState, quorumIDs, core.SigningSchemeBN254BLS, message, update)
+	assert.NoError(t, err)
+	assert.Equal(t, core.SigningSchemeBN254BLS, sigAgg.Scheme)
+	assert.NoError(t, agg.VerifyAggregation(state.IndexedOperatorState, quorumIDs, message, sigAgg))
+
+	// an unregistered scheme is rejected
+	_, err = agg.AggregateSignatures(context.Background(), state.IndexedOperatorState, quorumIDs, core.SigningSchemeID(1), message, make(chan core.SignerMessage))
+	assert.ErrorIs(t, err, core.ErrUnsupportedSigningScheme)
+	sigAgg.Scheme = core.SigningSchemeID(1)
+	assert.ErrorIs(t, agg.VerifyAggregation(state.IndexedOperatorState, quorumIDs, message, sigAgg), core.ErrUnsupportedSigningScheme)
+
+	// additional schemes can be registered alongside the default one
+	logger := &commonmock.Logger{}
+	transactor := &mock.MockTransactor{}
+	transactor.On("OperatorIDToAddress").Return(gethcommon.Address{}, nil)
+	aggregator, err := core.NewStdSignatureAggregator(logger, transactor, renumberedScheme{SigningScheme: core.NewBN254BLSScheme(), id: 1})
+	assert.NoError(t, err)
+
+	update = make(chan core.SignerMessage)
+	go simulateOperators(*state, message, update, 0)
+	sigAgg, err = aggregator.AggregateSignatures(context.Background(), state.IndexedOperatorState, quorumIDs, core.SigningSchemeID(1), message, update)
+	assert.NoError(t, err)
+	assert.Equal(t, core.SigningSchemeID(1), sigAgg.Scheme)
+	assert.NoError(t, aggregator.VerifyAggregation(state.IndexedOperatorState, quorumIDs, message, sigAgg))
+}
+
+func TestBN254BLSSchemeVerify(t *testing.T) {
+	keyPair, err := core.GenRandomBlsKeys()
+	assert.NoError(t, err)
+	message := [32]byte{1, 2, 3}
+	sig := keyPair.SignMessage(message)
+
+	scheme := core.NewBN254BLSScheme()
+	assert.True(t, scheme.Verify(keyPair.GetPubKeyG2(), message, sig))
+	assert.False(t, scheme.Verify(keyPair.GetPubKeyG2(), [32]byte{4, 5, 6}, sig))
+
+	// keys and signatures of other types, e.g. those of another scheme, are rejected
+	assert.False(t, scheme.Verify(keyPair.GetPubKeyG1(), message, sig))
+	assert.False(t, scheme.Verify(keyPair.GetPubKeyG2(), message, sig.G1Point))
+	assert.False(t, scheme.Verify((*core.G2Point)(nil), message, sig))
+}
+
+func TestComputePercentSigned(t *testing.T) {
+	state := dat.GetTotalOperatorState(context.Background(), 0)
+	message := [32]byte{1, 2, 3, 4, 5, 6}
+	quorumIDs := make([]core.QuorumID, 0, len(state.Operators))
+	for quorumID := range state.Operators {
+		quorumIDs = append(quorumIDs, quorumID)
+	}
+
+	r := rand.New(rand.NewSource(42))
+	for i := 0; i < 10; i++ {
+		// a random subset of the operators signs
+		signers := make([]core.OperatorID, 0)
+		update := make(chan core.SignerMessage, len(state.PrivateOperators))
+		for j := 0; j < len(state.PrivateOperators); j++ {
+			id := makeOperatorId(j)
+			op := state.PrivateOperators[id]
+			if r.Intn(2) == 0 {
+				update <- core.SignerMessage{Operator: id, Err: errors.New("not signing")}
+				continue
+			}
+			signers = append(signers, id)
+			update <- core.SignerMessage{Operator: id, Signature: op.KeyPair.SignMessage(message)}
+		}
+
+		sigAgg, err := agg.AggregateSignatures(context.Background(), state.IndexedOperatorState, quorumIDs, core.SigningSchemeBN254BLS, message, update)
+		assert.NoError(t, err)
+
+		percentSigned := core.ComputePercentSigned(state.OperatorState, signers)
+		assert.Len(t, percentSigned, len(quorumIDs))
+		for _, quorumID := range quorumIDs {
+			assert.Equal(t, sigAgg.QuorumResults[quorumID].PercentSigned, percentSigned[quorumID], "quorum %d with %d signers", quorumID, len(signers))
+		}
+	}
+
+	// duplicated and unknown signers don't count
+	signers := []core.OperatorID{makeOperatorId(0), makeOperatorId(0), {0xff}}
+	assert.Equal(t, core.ComputePercentSigned(state.OperatorState, signers[:1]), core.ComputePercentSigned(state.OperatorState, signers))
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -25,6 +26,7 @@ var (
 	ErrChunkLengthTooSmall = errors.New("chunk length too small")
 	ErrChunkLengthTooLarge = errors.New("chunk length too large")
 	ErrNotFound            = errors.New("not found")
+	ErrNoChainState        = errors.New("assignment coordinator has no chain state")
 )
 
 // Assignment
@@ -81,9 +83,18 @@ type AssignmentCoordinator interface {
 	// results in a number of chunks less than or equal to targetNumChunks and 2) the largest chunk length which satisfies
 	// the protocol constraints.
 	CalculateChunkLength(state *OperatorState, blobLength, targetNumChunks uint, param *SecurityParam) (uint, error)
+
+	// PreviewAssignments returns, for each quorum of the security params, the assignments of each operator for a blob of
+	// the given size (in bytes) at the current block, using the chunk length dispersal would choose.
+	PreviewAssignments(ctx context.Context, blobSize uint, securityParams []*SecurityParam) (map[QuorumID]map[OperatorID]Assignment, error)
 }
 
 type StdAssignmentCoordinator struct {
+	// ChainState provides the operator state at the current block. It is only required by PreviewAssignments.
+	ChainState ChainState
+	// TargetNumChunks is the target number of chunks per quorum that PreviewAssignments calculates chunk lengths with.
+	// It should match the target of the disperser.
+	TargetNumChunks uint
 }
 
 var _ AssignmentCoordinator = (*StdAssignmentCoordinator)(nil)
@@ -237,6 +248,49 @@ func (c *StdAssignmentCoordinator) CalculateChunkLength(state *OperatorState, bl
 
 }
 
+func (c *StdAssignmentCoordinator) PreviewAssignments(ctx context.Context, blobSize uint, securityParams []*SecurityParam) (map[QuorumID]map[OperatorID]Assignment, error) {
+	if c.ChainState == nil {
+		return nil, ErrNoChainState
+	}
+
+	blockNumber, err := c.ChainState.GetCurrentBlockNumber()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the current block number: %w", err)
+	}
+	quorumIDs := make([]QuorumID, len(securityParams))
+	for i, param := range securityParams {
+		quorumIDs[i] = param.QuorumID
+	}
+	state, err := c.ChainState.GetOperatorState(ctx, blockNumber, quorumIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the operator state at block %d: %w", blockNumber, err)
+	}
+
+	blobLength := GetBlobLength(blobSize)
+	previews := make(map[QuorumID]map[OperatorID]Assignment, len(securityParams))
+	for _, param := range securityParams {
+		if _, ok := state.Operators[param.QuorumID]; !ok {
+			return nil, fmt.Errorf("%w: quorum %d at block %d", ErrNotFound, param.QuorumID, blockNumber)
+		}
+
+		chunkLength, err := c.CalculateChunkLength(state, blobLength, c.TargetNumChunks, param)
+		if err != nil {
+			return nil, err
+		}
+		quorumInfo := &BlobQuorumInfo{
+			SecurityParam: *param,
+			ChunkLength:   chunkLength,
+		}
+		assignments, _, err := c.GetAssignments(state, blobLength, quorumInfo)
+		if err != nil {
+			return nil, err
+		}
+		previews[param.QuorumID] = assignments
+	}
+
+	return previews, nil
+}
+
 func roundUpDivideBig(a, b *big.Int) *big.Int {
 
 	one := new(big.Int).SetUint64(1)
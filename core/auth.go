@@ -7,4 +7,7 @@ type BlobRequestAuthenticator interface {
 type BlobRequestSigner interface {
 	SignBlobRequest(header BlobAuthHeader) ([]byte, error)
 	GetAccountID() string
+	// Current returns a signer whose key doesn't change, so that the account ID of a request and its signature come from
+	// the same key even if the key of this signer is rotated meanwhile
+	Current() BlobRequestSigner
 }
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigenda/common"
+)
+
+// AccountAllowlist is the set of accounts allowed to disperse authenticated blobs, identified by their account IDs, i.e.
+// their hex-encoded public keys. It's safe for concurrent use and can be updated at runtime, so that a client can rotate
+// its key by allowing the new key, switching to it, and revoking the old key once the overlap window is over.
+//
+// The allowlist can also be backed by a file listing the allowed account IDs, which is reloaded when the file changes, so
+// that the operators of the disperser can rotate the keys of the clients without restarting it.
+type AccountAllowlist struct {
+	mu         sync.RWMutex
+	accountIDs map[string]struct{}
+	// fileAccountIDs are the accounts listed in the file the allowlist was last loaded from
+	fileAccountIDs map[string]struct{}
+}
+
+// NewAccountAllowlist returns an allowlist of the given accounts
+func NewAccountAllowlist(accountIDs ...string) *AccountAllowlist {
+	allowlist := &AccountAllowlist{
+		accountIDs:     make(map[string]struct{}, len(accountIDs)),
+		fileAccountIDs: make(map[string]struct{}),
+	}
+	for _, accountID := range accountIDs {
+		allowlist.Allow(accountID)
+	}
+	return allowlist
+}
+
+// Allow adds the account to the allowlist
+func (a *AccountAllowlist) Allow(accountID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.accountIDs[strings.ToLower(accountID)] = struct{}{}
+}
+
+// Revoke removes the account from the allowlist. An account listed in the file of the allowlist is allowed again if the
+// file is reloaded and still lists it.
+func (a *AccountAllowlist) Revoke(accountID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.accountIDs, strings.ToLower(accountID))
+	delete(a.fileAccountIDs, strings.ToLower(accountID))
+}
+
+// IsAllowed returns whether the account is in the allowlist
+func (a *AccountAllowlist) IsAllowed(accountID string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	accountID = strings.ToLower(accountID)
+	if _, ok := a.accountIDs[accountID]; ok {
+		return true
+	}
+	_, ok := a.fileAccountIDs[accountID]
+	return ok
+}
+
+// LoadFile replaces the accounts loaded from a file by the accounts listed in the given file, one account ID per line.
+// Empty lines and lines starting with # are ignored. The accounts allowed with Allow are kept.
+func (a *AccountAllowlist) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open account allowlist file: %w", err)
+	}
+	defer file.Close()
+
+	accountIDs := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		accountIDs[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read account allowlist file: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.fileAccountIDs = accountIDs
+	return nil
+}
+
+// WatchFile reloads the allowlist from the given file whenever the file is modified, checking the file at the given
+// interval until the context is done. The file is reloaded on the first check too, in case it changed since it was
+// loaded. The allowlist is left unchanged if the file can't be read.
+func (a *AccountAllowlist) WatchFile(ctx context.Context, path string, interval time.Duration, logger common.Logger) {
+	var modTime time.Time
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(path)
+			if err != nil {
+				logger.Error("failed to stat account allowlist file", "path", path, "err", err)
+				continue
+			}
+			if info.ModTime().Equal(modTime) {
+				continue
+			}
+			if err := a.LoadFile(path); err != nil {
+				logger.Error("failed to reload account allowlist", "path", path, "err", err)
+				continue
+			}
+			modTime = info.ModTime()
+			logger.Info("reloaded account allowlist", "path", path)
+		}
+	}
+}
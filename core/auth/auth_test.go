@@ -1,9 +1,14 @@
 package auth_test
 
 import (
+	"context"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	cmock "github.com/Layr-Labs/eigenda/common/mock"
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/core/auth"
 	"github.com/stretchr/testify/assert"
@@ -65,3 +70,80 @@ func TestAuthenticationFail(t *testing.T) {
 	assert.Error(t, err)
 
 }
+
+func TestAuthenticationKeyRotation(t *testing.T) {
+
+	oldSigner := auth.NewSigner("0x0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+	newSigner := auth.NewSigner("0x0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcded")
+
+	// Only the old key is allowed before the rotation
+	allowlist := auth.NewAccountAllowlist(oldSigner.GetAccountID())
+	authenticator := auth.NewAuthenticator(auth.AuthConfig{AllowedAccounts: allowlist})
+
+	authenticate := func(signer core.BlobRequestSigner) error {
+		signer = signer.Current()
+		header := core.BlobAuthHeader{
+			BlobCommitments: core.BlobCommitments{},
+			AccountID:       signer.GetAccountID(),
+			Nonce:           rand.Uint32(),
+		}
+		signature, err := signer.SignBlobRequest(header)
+		assert.NoError(t, err)
+		header.AuthenticationData = signature
+		return authenticator.AuthenticateBlobRequest(header)
+	}
+
+	rotatingSigner := auth.NewRotatingSigner(oldSigner)
+	assert.NoError(t, authenticate(rotatingSigner))
+	assert.Error(t, authenticate(newSigner))
+
+	// Both keys are allowed during the overlap window
+	allowlist.Allow(newSigner.GetAccountID())
+	rotatingSigner.Rotate(newSigner)
+	assert.Equal(t, newSigner.GetAccountID(), rotatingSigner.GetAccountID())
+	assert.NoError(t, authenticate(rotatingSigner))
+	assert.NoError(t, authenticate(oldSigner))
+
+	// Only the new key is allowed once the old key is revoked
+	allowlist.Revoke(oldSigner.GetAccountID())
+	assert.NoError(t, authenticate(rotatingSigner))
+	assert.Error(t, authenticate(oldSigner))
+}
+
+func TestAccountAllowlistFile(t *testing.T) {
+	oldSigner := auth.NewSigner("0x0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+	newSigner := auth.NewSigner("0x0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcded")
+	staticSigner := auth.NewSigner("0x0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdec")
+
+	path := filepath.Join(t.TempDir(), "allowlist")
+	err := os.WriteFile(path, []byte("# allowed accounts\n"+oldSigner.GetAccountID()+"\n\n"), 0644)
+	assert.NoError(t, err)
+
+	allowlist := auth.NewAccountAllowlist(staticSigner.GetAccountID())
+	err = allowlist.LoadFile(path)
+	assert.NoError(t, err)
+	assert.True(t, allowlist.IsAllowed(oldSigner.GetAccountID()))
+	assert.False(t, allowlist.IsAllowed(newSigner.GetAccountID()))
+	assert.True(t, allowlist.IsAllowed(staticSigner.GetAccountID()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go allowlist.WatchFile(ctx, path, 10*time.Millisecond, &cmock.Logger{})
+
+	// the old key is replaced by the new key in the file, and the allowlist is reloaded
+	err = os.WriteFile(path, []byte(newSigner.GetAccountID()+"\n"), 0644)
+	assert.NoError(t, err)
+	future := time.Now().Add(time.Second)
+	err = os.Chtimes(path, future, future)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return allowlist.IsAllowed(newSigner.GetAccountID()) && !allowlist.IsAllowed(oldSigner.GetAccountID())
+	}, time.Second, 10*time.Millisecond)
+	assert.True(t, allowlist.IsAllowed(staticSigner.GetAccountID()))
+
+	// the allowlist is kept if the file can't be read
+	err = os.Remove(path)
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, allowlist.IsAllowed(newSigner.GetAccountID()))
+}
@@ -12,6 +12,9 @@ import (
 )
 
 type AuthConfig struct {
+	// AllowedAccounts restricts the accounts whose requests are authenticated to the accounts of the allowlist. Nil
+	// authenticates the requests of any account.
+	AllowedAccounts *AccountAllowlist
 }
 
 type authenticator struct {
@@ -25,7 +28,10 @@ func NewAuthenticator(config AuthConfig) core.BlobRequestAuthenticator {
 	}
 }
 
-func (*authenticator) AuthenticateBlobRequest(header core.BlobAuthHeader) error {
+func (a *authenticator) AuthenticateBlobRequest(header core.BlobAuthHeader) error {
+	if a.config.AllowedAccounts != nil && !a.config.AllowedAccounts.IsAllowed(header.AccountID) {
+		return fmt.Errorf("account %v is not allowed", header.AccountID)
+	}
 
 	buf := make([]byte, 4)
 	binary.BigEndian.PutUint32(buf, header.Nonce)
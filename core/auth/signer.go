@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"log"
+	"sync"
 
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/ethereum/go-ethereum/common"
@@ -51,3 +52,46 @@ func (s *signer) GetAccountID() string {
 	return hexutil.Encode(publicKeyBytes)
 
 }
+
+func (s *signer) Current() core.BlobRequestSigner {
+	return s
+}
+
+// RotatingSigner is a BlobRequestSigner whose key can be swapped at runtime, so that a client can rotate its key without
+// being restarted
+type RotatingSigner struct {
+	mu     sync.RWMutex
+	signer core.BlobRequestSigner
+}
+
+var _ core.BlobRequestSigner = (*RotatingSigner)(nil)
+
+func NewRotatingSigner(signer core.BlobRequestSigner) *RotatingSigner {
+	return &RotatingSigner{
+		signer: signer,
+	}
+}
+
+// Rotate makes the given signer sign the requests from now on
+func (s *RotatingSigner) Rotate(signer core.BlobRequestSigner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signer = signer
+}
+
+// Current returns the signer currently signing the requests. A request should be signed by the signer whose account ID
+// it carries, so a request in progress should keep using the signer returned when it started, even if the key is
+// rotated meanwhile.
+func (s *RotatingSigner) Current() core.BlobRequestSigner {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.signer.Current()
+}
+
+func (s *RotatingSigner) SignBlobRequest(header core.BlobAuthHeader) ([]byte, error) {
+	return s.Current().SignBlobRequest(header)
+}
+
+func (s *RotatingSigner) GetAccountID() string {
+	return s.Current().GetAccountID()
+}
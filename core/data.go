@@ -73,8 +73,33 @@ type BlobRequestHeader struct {
 	BlobAuthHeader `json:"blob_auth_header"`
 	// For a blob to be accepted by EigenDA, it satisfy the AdversaryThreshold of each quorum contained in SecurityParams
 	SecurityParams []*SecurityParam `json:"security_params"`
+	// IdempotencyKey is an optional client-supplied key used by the disperser to deduplicate retried requests.
+	// The disperser scopes it to the dispersing account, see disperser.ScopeIdempotencyKey.
+	IdempotencyKey string `json:"idempotency_key" dynamodbav:",omitempty"`
+	// Requester is the authenticated account that dispersed the blob, or the origin of an unauthenticated request. It is
+	// set by the disperser, which only deduplicates blobs dispersed by the same requester.
+	Requester string `json:"requester" dynamodbav:",omitempty"`
+	// Priority is the priority lane in which the disperser processes the blob
+	Priority BlobPriority `json:"priority"`
+	// ClientMetadata is opaque metadata attached by the client and returned with the blob status.
+	// It is not part of the blob header and doesn't affect encoding or batching.
+	ClientMetadata []byte `json:"client_metadata"`
+	// ChunkLength overrides the chunk length the blob is encoded with in every quorum. If 0, the chunk length is
+	// calculated from the operator state.
+	ChunkLength uint `json:"chunk_length"`
 }
 
+// BlobPriority is the priority lane in which the disperser processes a blob.
+// Blobs with a higher priority are encoded and batched ahead of blobs with a lower priority.
+type BlobPriority uint8
+
+const (
+	PriorityNormal BlobPriority = iota
+	PriorityHigh
+
+	MaxBlobPriority = PriorityHigh
+)
+
 func (h *BlobRequestHeader) Validate() error {
 	for _, quorum := range h.SecurityParams {
 		if quorum.QuorumThreshold < quorum.AdversaryThreshold+10 {
@@ -87,6 +112,9 @@ func (h *BlobRequestHeader) Validate() error {
 			return errors.New("invalid request: adversary threshold equals 0")
 		}
 	}
+	if h.ChunkLength != 0 && h.ChunkLength&(h.ChunkLength-1) != 0 {
+		return errors.New("invalid request: chunk length must be a power of 2")
+	}
 	return nil
 }
 
@@ -105,6 +133,9 @@ type BlobHeader struct {
 
 	// AccountID is the account that is paying for the blob to be stored
 	AccountID AccountID `json:"account_id"`
+
+	// Version selects the layout used to compute the blob header hash. Defaults to BlobHeaderVersionV0.
+	Version uint8 `json:"version"`
 }
 
 func (b *BlobHeader) GetQuorumInfo(quorum QuorumID) *BlobQuorumInfo {
@@ -143,6 +174,9 @@ type BatchHeader struct {
 	ReferenceBlockNumber uint
 	// BatchRoot is the root of a Merkle tree whose leaves are the hashes of the blobs in the batch
 	BatchRoot [32]byte
+	// SigningScheme is the scheme operators sign the batch header with. It is not part of the header hash verified
+	// onchain, but the message signed under any scheme other than the default commits to it, see GetSigningMessage.
+	SigningScheme SigningSchemeID
 }
 
 // EncodedBlob contains the messages to be sent to a group of DA nodes corresponding to a single blob
@@ -214,6 +248,13 @@ type Sample struct {
 	BlobIndex       int
 }
 
+// BlobChunks are chunks of a single blob with their assignment indices, verified against the commitments of the blob
+type BlobChunks struct {
+	Commitments BlobCommitments
+	Chunks      []*Chunk
+	Indices     []ChunkNumber
+}
+
 // SubBatch is a part of the whole Batch with identical Encoding Parameters, i.e. (ChunkLen, NumChunk)
 // Blobs with the same encoding parameters are collected in a single subBatch
 type SubBatch struct {
@@ -45,6 +45,10 @@ type Encoder interface {
 	// VerifyBatch takes in the encoding parameters, samples and the number of blobs and returns an error if a chunk in any sample is invalid.
 	UniversalVerifySubBatch(params EncodingParams, samples []Sample, numBlobs int) error
 
+	// VerifyChunksBatch verifies the chunks of several blobs with the same encoding parameters in a single pairing check.
+	// If any chunk is invalid, it returns a *ChunkVerificationError identifying the first blob with an invalid chunk.
+	VerifyChunksBatch(blobs []BlobChunks, params EncodingParams) error
+
 	// VerifyBlobLength takes in the commitments and returns an error if the blob length is invalid.
 	VerifyBlobLength(commitments BlobCommitments) error
 
@@ -55,6 +59,21 @@ type Encoder interface {
 	Decode(chunks []*Chunk, indices []ChunkNumber, params EncodingParams, inputSize uint64) ([]byte, error)
 }
 
+// ChunkVerificationError is returned by VerifyChunksBatch when the chunks of a blob are invalid
+type ChunkVerificationError struct {
+	// BlobIndex is the index of the blob with invalid chunks in the verified batch
+	BlobIndex int
+	Err       error
+}
+
+func (e *ChunkVerificationError) Error() string {
+	return fmt.Sprintf("invalid chunks for blob %d: %v", e.BlobIndex, e.Err)
+}
+
+func (e *ChunkVerificationError) Unwrap() error {
+	return e.Err
+}
+
 // GetBlobLength converts from blob size in bytes to blob size in symbols
 func GetBlobLength(blobSize uint) uint {
 	symSize := uint(bn254.BYTES_PER_COEFFICIENT)
@@ -0,0 +1,97 @@
+package encoding
+
+import (
+	"math/bits"
+	"sync"
+
+	"github.com/Layr-Labs/eigenda/core"
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// defaultCacheSize is the number of encoded blobs cached across all the size classes if the size isn't configured
+const defaultCacheSize = 128
+
+// encodedBlobCache caches up to size encoded blobs in separate LRU partitions for each size class of the encoded blobs.
+// When the cache is full, the least recently used blob of the largest partition is evicted, so that the encodings of
+// frequent small blobs aren't evicted by occasional large ones.
+type encodedBlobCache struct {
+	mu sync.Mutex
+	// partitions holds the cache of each size class, see sizeClass
+	partitions map[int]*lru.Cache[string, encodedValue]
+	// size is the maximum number of blobs cached across all the partitions
+	size int
+	// len is the number of blobs cached across all the partitions
+	len int
+}
+
+func newEncodedBlobCache(size uint) *encodedBlobCache {
+	if size == 0 {
+		size = defaultCacheSize
+	}
+	return &encodedBlobCache{
+		partitions: make(map[int]*lru.Cache[string, encodedValue]),
+		size:       int(size),
+	}
+}
+
+// sizeClass returns the size class of the blobs encoded with the given params, i.e. the number of bits of the length of
+// the encoded blob in symbols
+func sizeClass(params core.EncodingParams) int {
+	return bits.Len(params.ChunkLength * params.NumChunks)
+}
+
+// Get returns the encoded blob cached under the key in the partition of the params
+func (c *encodedBlobCache) Get(key string, params core.EncodingParams) (encodedValue, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	partition, ok := c.partitions[sizeClass(params)]
+	if !ok {
+		return encodedValue{}, false
+	}
+	return partition.Get(key)
+}
+
+// Add caches the encoded blob under the key in the partition of the params. If the cache is full, the least recently
+// used blob of the partition holding the most blobs is evicted, preferring the partition of the params on ties.
+func (c *encodedBlobCache) Add(key string, params core.EncodingParams, value encodedValue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	class := sizeClass(params)
+	partition, ok := c.partitions[class]
+	if !ok {
+		// the partitions are evicted by evict rather than by their own capacity, which leaves room for the blob added
+		// before evicting
+		var err error
+		partition, err = lru.New[string, encodedValue](c.size + 1)
+		if err != nil {
+			return
+		}
+		c.partitions[class] = partition
+	}
+	if partition.Contains(key) {
+		partition.Add(key, value)
+		return
+	}
+
+	partition.Add(key, value)
+	c.len++
+	for c.len > c.size {
+		c.evict(class)
+	}
+}
+
+// evict removes the least recently used blob of the partition holding the most blobs. Ties are resolved in favor of
+// evicting from the partition of the given class.
+func (c *encodedBlobCache) evict(class int) {
+	largest := class
+	for k, partition := range c.partitions {
+		if partition.Len() > c.partitions[largest].Len() {
+			largest = k
+		}
+	}
+	if _, _, ok := c.partitions[largest].RemoveOldest(); ok {
+		c.len--
+	}
+}
@@ -17,8 +17,12 @@ const (
 	VerboseFlagName           = "kzg.verbose"
 	PreloadEncoderFlagName    = "kzg.preload-encoder"
 	CacheEncodedBlobsFlagName = "cache-encoded-blobs"
+	CacheSizeFlagName         = "cache-encoded-blobs-size"
 	SRSLoadingNumberFlagName  = "kzg.srs-load"
 	G2PowerOf2PathFlagName    = "kzg.g2-power-of-2-path"
+	MaxBlobLengthFlagName     = "max-blob-length"
+	ParallelDecodeFlagName    = "kzg.parallel-decode"
+	ParallelVerifyFlagName    = "kzg.parallel-verify-chunks"
 )
 
 func CLIFlags(envPrefix string) []cli.Flag {
@@ -72,6 +76,12 @@ func CLIFlags(envPrefix string) []cli.Flag {
 			Required: false,
 			EnvVar:   common.PrefixEnvVar(envPrefix, "CACHE_ENCODED_BLOBS"),
 		},
+		cli.UintFlag{
+			Name:     CacheSizeFlagName,
+			Usage:    "Number of encoded results cached across all the size classes of the encoded blobs. 0 caches 128 results",
+			Required: false,
+			EnvVar:   common.PrefixEnvVar(envPrefix, "CACHE_ENCODED_BLOBS_SIZE"),
+		},
 		cli.BoolFlag{
 			Name:     PreloadEncoderFlagName,
 			Usage:    "Set to enable Encoder PreLoading",
@@ -84,6 +94,24 @@ func CLIFlags(envPrefix string) []cli.Flag {
 			Required: false,
 			EnvVar:   common.PrefixEnvVar(envPrefix, "G2_POWER_OF_2_PATH"),
 		},
+		cli.UintFlag{
+			Name:     MaxBlobLengthFlagName,
+			Usage:    "Maximum length in symbols of the data to encode. 0 means no limit",
+			Required: false,
+			EnvVar:   common.PrefixEnvVar(envPrefix, "MAX_BLOB_LENGTH"),
+		},
+		cli.BoolFlag{
+			Name:     ParallelDecodeFlagName,
+			Usage:    "Enable to interpolate chunks in parallel using the configured number of workers when decoding",
+			Required: false,
+			EnvVar:   common.PrefixEnvVar(envPrefix, "PARALLEL_DECODE"),
+		},
+		cli.BoolFlag{
+			Name:     ParallelVerifyFlagName,
+			Usage:    "Enable to verify chunks in parallel using the configured number of workers",
+			Required: false,
+			EnvVar:   common.PrefixEnvVar(envPrefix, "PARALLEL_VERIFY_CHUNKS"),
+		},
 	}
 }
 
@@ -100,7 +128,11 @@ func ReadCLIConfig(ctx *cli.Context) EncoderConfig {
 	cfg.G2PowerOf2Path = ctx.GlobalString(G2PowerOf2PathFlagName)
 
 	return EncoderConfig{
-		KzgConfig:         cfg,
-		CacheEncodedBlobs: ctx.GlobalBoolT(CacheEncodedBlobsFlagName),
+		KzgConfig:            cfg,
+		CacheEncodedBlobs:    ctx.GlobalBoolT(CacheEncodedBlobsFlagName),
+		CacheSize:            ctx.GlobalUint(CacheSizeFlagName),
+		MaxBlobLength:        ctx.GlobalUint(MaxBlobLengthFlagName),
+		ParallelDecode:       ctx.GlobalBool(ParallelDecodeFlagName),
+		ParallelVerifyChunks: ctx.GlobalBool(ParallelVerifyFlagName),
 	}
 }
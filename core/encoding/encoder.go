@@ -2,6 +2,7 @@ package encoding
 
 import (
 	"crypto/sha256"
+	"fmt"
 
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/encoding"
@@ -10,7 +11,6 @@ import (
 	"github.com/Layr-Labs/eigenda/encoding/kzgrs/verifier"
 	encoder "github.com/Layr-Labs/eigenda/encoding/rs"
 	"github.com/Layr-Labs/eigenda/pkg/kzg/bn254"
-	lru "github.com/hashicorp/golang-lru/v2"
 )
 
 func toEncParams(params core.EncodingParams) encoder.EncodingParams {
@@ -20,13 +20,37 @@ func toEncParams(params core.EncodingParams) encoder.EncodingParams {
 type EncoderConfig struct {
 	KzgConfig         kzgrs.KzgConfig
 	CacheEncodedBlobs bool
+	// CacheSize is the number of encoded blobs cached across all the size classes of the encoded blobs. When the cache
+	// is full, the blobs of the size class holding the most blobs are evicted first, so that occasional large blobs
+	// don't evict frequent small ones. 0 caches 128 blobs.
+	CacheSize uint
+	// MaxBlobLength is the maximum length in symbols of the data accepted by Encode. 0 means no limit.
+	MaxBlobLength uint
+	// ParallelDecode enables interpolating the chunks in Decode across KzgConfig.NumWorker goroutines.
+	// The decoded data is identical to the serial path.
+	ParallelDecode bool
+	// ParallelVerifyChunks enables verifying the chunks in VerifyChunks across KzgConfig.NumWorker goroutines. The
+	// chunks are accepted or rejected with the same error as in the serial path.
+	ParallelVerifyChunks bool
+}
+
+// ErrBlobTooLarge is returned by Encode when the data is longer than the configured MaxBlobLength
+type ErrBlobTooLarge struct {
+	// Length is the length of the data in symbols
+	Length uint
+	// MaxLength is the maximum length in symbols allowed by the encoder
+	MaxLength uint
+}
+
+func (e *ErrBlobTooLarge) Error() string {
+	return fmt.Sprintf("blob length %d exceeds the maximum blob length %d", e.Length, e.MaxLength)
 }
 
 type Encoder struct {
 	Config        EncoderConfig
 	EncoderGroup  *prover.Prover
 	VerifierGroup *verifier.Verifier
-	Cache         *lru.Cache[string, encodedValue]
+	Cache         *encodedBlobCache
 }
 
 var _ core.Encoder = &Encoder{}
@@ -42,15 +66,10 @@ func NewEncoder(config EncoderConfig, loadG2Points bool) (*Encoder, error) {
 		return nil, err
 	}
 
-	cache, err := lru.New[string, encodedValue](128)
-	if err != nil {
-		return nil, err
-	}
-
 	return &Encoder{
 		EncoderGroup:  kzgEncoderGroup,
 		VerifierGroup: kzgVerifierGroup,
-		Cache:         cache,
+		Cache:         newEncodedBlobCache(config.CacheSize),
 		Config:        config,
 	}, nil
 }
@@ -62,11 +81,15 @@ type encodedValue struct {
 }
 
 func (e *Encoder) Encode(data []byte, params core.EncodingParams) (core.BlobCommitments, []*core.Chunk, error) {
+	length := core.GetBlobLength(uint(len(data)))
+	if e.Config.MaxBlobLength > 0 && length > e.Config.MaxBlobLength {
+		return core.BlobCommitments{}, nil, &ErrBlobTooLarge{Length: length, MaxLength: e.Config.MaxBlobLength}
+	}
 
 	var cacheKey string = ""
 	if e.Config.CacheEncodedBlobs {
 		cacheKey = hashBlob(data, params)
-		if v, ok := e.Cache.Get(cacheKey); ok {
+		if v, ok := e.Cache.Get(cacheKey, params); ok {
 			return v.commitments, v.chunks, v.err
 		}
 	}
@@ -91,7 +114,6 @@ func (e *Encoder) Encode(data []byte, params core.EncodingParams) (core.BlobComm
 		}
 	}
 
-	length := uint(len(encoder.ToFrArray(data)))
 	commitments := core.BlobCommitments{
 		Commitment:       (*core.G1Commitment)(commit),
 		LengthCommitment: (*core.G2Commitment)(lowDegreeCommit),
@@ -100,7 +122,7 @@ func (e *Encoder) Encode(data []byte, params core.EncodingParams) (core.BlobComm
 	}
 
 	if e.Config.CacheEncodedBlobs {
-		e.Cache.Add(cacheKey, encodedValue{
+		e.Cache.Add(cacheKey, params, encodedValue{
 			commitments: commitments,
 			chunks:      chunks,
 			err:         nil,
@@ -123,23 +145,18 @@ func (e *Encoder) VerifyChunks(chunks []*core.Chunk, indices []core.ChunkNumber,
 		return err
 	}
 
+	frames := make([]encoding.Frame, len(chunks))
 	for ind := range chunks {
-		err = verifier.VerifyFrame(
-			(*bn254.G1Point)(commitments.Commitment),
-			&encoding.Frame{
-				Proof:  chunks[ind].Proof,
-				Coeffs: chunks[ind].Coeffs,
-			},
-			uint64(indices[ind]),
-		)
-
-		if err != nil {
-			return err
+		frames[ind] = encoding.Frame{
+			Proof:  chunks[ind].Proof,
+			Coeffs: chunks[ind].Coeffs,
 		}
 	}
 
-	return nil
-
+	if e.Config.ParallelVerifyChunks {
+		return verifier.VerifyFramesParallel((*bn254.G1Point)(commitments.Commitment), frames, toUint64Array(indices), e.VerifierGroup.NumWorker)
+	}
+	return verifier.VerifyFrames((*bn254.G1Point)(commitments.Commitment), frames, toUint64Array(indices))
 }
 
 func (e *Encoder) VerifyCommitEquivalenceBatch(commitments []core.BlobCommitments) error {
@@ -181,6 +198,44 @@ func (e *Encoder) UniversalVerifySubBatch(params core.EncodingParams, samplesCor
 	return e.VerifierGroup.UniversalVerify(encParams, samples, numBlobs)
 }
 
+// VerifyChunksBatch verifies the chunks of all blobs with a single call to UniversalVerifySubBatch.
+// If the batch fails to verify, each blob is verified separately to find the first one with invalid chunks.
+func (e *Encoder) VerifyChunksBatch(blobs []core.BlobChunks, params core.EncodingParams) error {
+	samples := make([]core.Sample, 0)
+	for blobIndex, blob := range blobs {
+		if len(blob.Chunks) != len(blob.Indices) {
+			return &core.ChunkVerificationError{
+				BlobIndex: blobIndex,
+				Err:       fmt.Errorf("number of chunks (%d) and indices (%d) do not match", len(blob.Chunks), len(blob.Indices)),
+			}
+		}
+		for i, chunk := range blob.Chunks {
+			samples = append(samples, core.Sample{
+				Commitment:      blob.Commitments.Commitment,
+				Chunk:           chunk,
+				AssignmentIndex: blob.Indices[i],
+				BlobIndex:       blobIndex,
+			})
+		}
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	err := e.UniversalVerifySubBatch(params, samples, len(blobs))
+	if err == nil {
+		return nil
+	}
+
+	for blobIndex, blob := range blobs {
+		if blobErr := e.VerifyChunks(blob.Chunks, blob.Indices, blob.Commitments, params); blobErr != nil {
+			return &core.ChunkVerificationError{BlobIndex: blobIndex, Err: blobErr}
+		}
+	}
+	// the batch failed even though every blob verifies on its own
+	return err
+}
+
 // Decode takes in the chunks, indices, and encoding parameters and returns the decoded blob
 // The result is trimmed to the given maxInputSize.
 func (e *Encoder) Decode(chunks []*core.Chunk, indices []core.ChunkNumber, params core.EncodingParams, maxInputSize uint64) ([]byte, error) {
@@ -196,6 +251,9 @@ func (e *Encoder) Decode(chunks []*core.Chunk, indices []core.ChunkNumber, param
 		return nil, err
 	}
 
+	if e.Config.ParallelDecode {
+		return encoder.DecodeParallel(frames, toUint64Array(indices), maxInputSize)
+	}
 	return encoder.Decode(frames, toUint64Array(indices), maxInputSize)
 }
 
@@ -9,6 +9,7 @@ import (
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/core/encoding"
 	"github.com/Layr-Labs/eigenda/encoding/kzgrs"
+	"github.com/Layr-Labs/eigenda/encoding/rs"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -107,3 +108,120 @@ func BenchmarkEncode(b *testing.B) {
 		_, _, _ = enc.Encode(blobs[i%numSamples], params)
 	}
 }
+
+func TestVerifyChunksBatch(t *testing.T) {
+	params := core.EncodingParams{
+		ChunkLength: 8,
+		NumChunks:   8,
+	}
+	numBlobs := 3
+	blobs := make([]core.BlobChunks, numBlobs)
+	for i := range blobs {
+		data := make([]byte, 1000)
+		_, err := rand.Read(data)
+		assert.NoError(t, err)
+		commitments, chunks, err := enc.Encode(data, params)
+		assert.NoError(t, err)
+		indices := make([]core.ChunkNumber, len(chunks))
+		for j := range indices {
+			indices[j] = core.ChunkNumber(j)
+		}
+		blobs[i] = core.BlobChunks{
+			Commitments: commitments,
+			Chunks:      chunks,
+			Indices:     indices,
+		}
+	}
+
+	err := enc.VerifyChunksBatch(blobs, params)
+	assert.NoError(t, err)
+
+	// corrupt a chunk of the second blob by swapping in a chunk of the third blob
+	badChunks := make([]*core.Chunk, len(blobs[1].Chunks))
+	copy(badChunks, blobs[1].Chunks)
+	badChunks[3] = blobs[2].Chunks[3]
+	blobs[1].Chunks = badChunks
+
+	err = enc.VerifyChunksBatch(blobs, params)
+	var verificationErr *core.ChunkVerificationError
+	assert.ErrorAs(t, err, &verificationErr)
+	assert.Equal(t, 1, verificationErr.BlobIndex)
+}
+
+func TestEncodeBlobTooLarge(t *testing.T) {
+	encoder := enc.(*encoding.Encoder)
+	encoder.Config.MaxBlobLength = 10
+	defer func() {
+		encoder.Config.MaxBlobLength = 0
+	}()
+
+	params := core.EncodingParams{
+		ChunkLength: 3,
+		NumChunks:   7,
+	}
+	_, _, err := encoder.Encode(gettysburgAddressBytes, params)
+	var tooLargeErr *encoding.ErrBlobTooLarge
+	assert.ErrorAs(t, err, &tooLargeErr)
+	assert.Equal(t, core.GetBlobLength(uint(len(gettysburgAddressBytes))), tooLargeErr.Length)
+	assert.Equal(t, uint(10), tooLargeErr.MaxLength)
+
+	// the encoder for the params is never created
+	_, ok := encoder.EncoderGroup.ParametrizedProvers[rs.ParamsFromMins(uint64(params.NumChunks), uint64(params.ChunkLength))]
+	assert.False(t, ok)
+
+	// data within the limit is encoded
+	_, _, err = encoder.Encode(gettysburgAddressBytes[:10*31], params)
+	assert.NoError(t, err)
+	_, ok = encoder.EncoderGroup.ParametrizedProvers[rs.ParamsFromMins(uint64(params.NumChunks), uint64(params.ChunkLength))]
+	assert.True(t, ok)
+}
+
+func TestEncodedBlobCachePartitions(t *testing.T) {
+	config := kzgrs.KzgConfig{
+		G1Path:          "../../inabox/resources/kzg/g1.point",
+		G2Path:          "../../inabox/resources/kzg/g2.point",
+		CacheDir:        "../../inabox/resources/kzg/SRSTables",
+		SRSOrder:        3000,
+		SRSNumberToLoad: 3000,
+		NumWorker:       uint64(runtime.GOMAXPROCS(0)),
+	}
+	encoder, err := encoding.NewEncoder(encoding.EncoderConfig{
+		KzgConfig:         config,
+		CacheEncodedBlobs: true,
+		CacheSize:         2,
+	}, true)
+	assert.NoError(t, err)
+
+	smallParams := core.EncodingParams{ChunkLength: 2, NumChunks: 4}
+	largeParams := core.EncodingParams{ChunkLength: 16, NumChunks: 8}
+	small := gettysburgAddressBytes[:100]
+	large := gettysburgAddressBytes
+	otherLarge := make([]byte, len(gettysburgAddressBytes))
+	_, err = rand.Read(otherLarge)
+	assert.NoError(t, err)
+
+	// a cached encoding is returned as is
+	_, smallChunks, err := encoder.Encode(small, smallParams)
+	assert.NoError(t, err)
+	_, chunks, err := encoder.Encode(small, smallParams)
+	assert.NoError(t, err)
+	assert.Same(t, smallChunks[0], chunks[0])
+
+	// the small blob stays cached after a large blob fills the cache
+	_, largeChunks, err := encoder.Encode(large, largeParams)
+	assert.NoError(t, err)
+	_, chunks, err = encoder.Encode(small, smallParams)
+	assert.NoError(t, err)
+	assert.Same(t, smallChunks[0], chunks[0])
+
+	// large blobs evict each other rather than the small blob, since their partition holds the most blobs
+	_, _, err = encoder.Encode(otherLarge, largeParams)
+	assert.NoError(t, err)
+	_, chunks, err = encoder.Encode(large, largeParams)
+	assert.NoError(t, err)
+	assert.NotSame(t, largeChunks[0], chunks[0])
+	assert.Equal(t, largeChunks, chunks)
+	_, chunks, err = encoder.Encode(small, smallParams)
+	assert.NoError(t, err)
+	assert.Same(t, smallChunks[0], chunks[0])
+}
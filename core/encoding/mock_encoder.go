@@ -32,6 +32,13 @@ func (e *MockEncoder) UniversalVerifySubBatch(params core.EncodingParams, sample
 	time.Sleep(e.Delay)
 	return args.Error(0)
 }
+
+func (e *MockEncoder) VerifyChunksBatch(blobs []core.BlobChunks, params core.EncodingParams) error {
+	args := e.Called(blobs, params)
+	time.Sleep(e.Delay)
+	return args.Error(0)
+}
+
 func (e *MockEncoder) VerifyCommitEquivalenceBatch(commitments []core.BlobCommitments) error {
 	args := e.Called(commitments)
 	time.Sleep(e.Delay)
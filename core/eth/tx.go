@@ -603,6 +603,12 @@ func (t *Transactor) GetQuorumCount(ctx context.Context, blockNumber uint32) (ui
 	})
 }
 
+func (t *Transactor) GetBatchMetadataHash(ctx context.Context, batchID uint32) ([32]byte, error) {
+	return t.Bindings.EigenDAServiceManager.BatchIdToBatchMetadataHash(&bind.CallOpts{
+		Context: ctx,
+	}, batchID)
+}
+
 func (t *Transactor) updateContractBindings(blsOperatorStateRetrieverAddr, eigenDAServiceManagerAddr gethcommon.Address) error {
 
 	contractEigenDAServiceManager, err := eigendasrvmg.NewContractEigenDAServiceManager(eigenDAServiceManagerAddr, t.EthClient)
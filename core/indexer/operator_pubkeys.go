@@ -22,6 +22,8 @@ const (
 type OperatorPubKeysPair struct {
 	PubKeyG1 *bn254.G1Affine
 	PubKeyG2 *bn254.G2Affine
+	// RegistrationBlockNumber is the block number at which the operator was last added to quorums
+	RegistrationBlockNumber uint64
 }
 
 type OperatorPubKeys struct {
@@ -82,6 +84,7 @@ func (a *OperatorPubKeysAccumulator) UpdateObject(object indexer.AccumulatorObje
 					A1: newFpElement(payload.RegEvent.PubkeyG2.Y[0]),
 				},
 			},
+			RegistrationBlockNumber: header.Number,
 		}
 
 		p := core.G1Point{G1Affine: pubKeysPair.PubKeyG1}
@@ -52,9 +52,10 @@ func (ics *IndexedChainState) GetIndexedOperatorState(ctx context.Context, block
 		}
 
 		ops[id] = &core.IndexedOperatorInfo{
-			PubkeyG1: &core.G1Point{G1Affine: op.PubKeyG1},
-			PubkeyG2: &core.G2Point{G2Affine: op.PubKeyG2},
-			Socket:   socket,
+			PubkeyG1:                &core.G1Point{G1Affine: op.PubKeyG1},
+			PubkeyG2:                &core.G2Point{G2Affine: op.PubKeyG2},
+			Socket:                  socket,
+			RegistrationBlockNumber: uint(op.RegistrationBlockNumber),
 		}
 	}
 
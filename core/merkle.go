@@ -0,0 +1,109 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/wealdtech/go-merkletree"
+	"github.com/wealdtech/go-merkletree/keccak256"
+)
+
+var ErrInvalidMerkleProof = errors.New("invalid merkle proof")
+
+// BatchMerkleTree is the keccak256 merkle tree over the blob header hashes of a batch
+type BatchMerkleTree struct {
+	tree *merkletree.MerkleTree
+}
+
+// BatchMerkleProof is the proof of inclusion of a blob header in a batch
+type BatchMerkleProof struct {
+	// Index is the index of the blob in the batch
+	Index uint64
+	// Hashes are the sibling hashes from the blob header up to the batch root
+	Hashes [][]byte
+}
+
+// NewBatchMerkleTree builds the merkle tree over the given blob header hashes.
+func NewBatchMerkleTree(leaves [][]byte) (*BatchMerkleTree, error) {
+	if len(leaves) == 0 {
+		return nil, errors.New("no leaves to build merkle tree")
+	}
+	tree, err := merkletree.NewTree(merkletree.WithData(leaves), merkletree.WithHashType(keccak256.New()))
+	if err != nil {
+		return nil, err
+	}
+	return &BatchMerkleTree{tree: tree}, nil
+}
+
+// Root returns the batch root
+func (t *BatchMerkleTree) Root() []byte {
+	return t.tree.Root()
+}
+
+// GenerateProof returns the proof of inclusion of the given blob header hash in the batch
+func (t *BatchMerkleTree) GenerateProof(leaf []byte) (*BatchMerkleProof, error) {
+	proof, err := t.tree.GenerateProof(leaf, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &BatchMerkleProof{
+		Index:  proof.Index,
+		Hashes: proof.Hashes,
+	}, nil
+}
+
+// Verify checks that the proof links the blob header hash to the batch root.
+// The only blob of a single-blob batch has an empty proof, and the batch root is the hash of its blob header.
+// It returns ErrInvalidMerkleProof if a sibling hash is not 32 bytes or the index does not fit in a tree of the proof's depth.
+func (p *BatchMerkleProof) Verify(leaf []byte, root [32]byte) (bool, error) {
+	for i, hash := range p.Hashes {
+		if len(hash) != 32 {
+			return false, fmt.Errorf("%w: sibling hash %d has length %d, expected 32", ErrInvalidMerkleProof, i, len(hash))
+		}
+	}
+	if len(p.Hashes) < 64 && p.Index >= uint64(1)<<len(p.Hashes) {
+		return false, fmt.Errorf("%w: index %d out of range for a proof of %d hashes", ErrInvalidMerkleProof, p.Index, len(p.Hashes))
+	}
+	return bytes.Equal(computeMerkleRoot(leaf, p.Hashes, p.Index), root[:]), nil
+}
+
+// Serialize returns the proof in the format stored in the blob inclusion proof of a confirmed blob,
+// i.e. the concatenation of the sibling hashes.
+func (p *BatchMerkleProof) Serialize() []byte {
+	proofBytes := make([]byte, 0, 32*len(p.Hashes))
+	for _, hash := range p.Hashes {
+		proofBytes = append(proofBytes, hash...)
+	}
+	return proofBytes
+}
+
+// DeserializeBatchMerkleProof parses a proof serialized with BatchMerkleProof.Serialize for the blob at the given index.
+func DeserializeBatchMerkleProof(index uint64, data []byte) (*BatchMerkleProof, error) {
+	if len(data)%32 != 0 {
+		return nil, fmt.Errorf("%w: proof length must be a multiple of 32", ErrInvalidMerkleProof)
+	}
+	hashes := make([][]byte, len(data)/32)
+	for i := range hashes {
+		hashes[i] = data[i*32 : (i+1)*32]
+	}
+	return &BatchMerkleProof{
+		Index:  index,
+		Hashes: hashes,
+	}, nil
+}
+
+// computeMerkleRoot computes the root of a keccak256 merkle tree from a leaf and its proof
+func computeMerkleRoot(leaf []byte, hashes [][]byte, index uint64) []byte {
+	hasher := keccak256.New()
+	hash := hasher.Hash(leaf)
+	for _, sibling := range hashes {
+		if index%2 == 0 {
+			hash = hasher.Hash(hash, sibling)
+		} else {
+			hash = hasher.Hash(sibling, hash)
+		}
+		index >>= 1
+	}
+	return hash
+}
@@ -0,0 +1,128 @@
+package core_test
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/wealdtech/go-merkletree"
+	"github.com/wealdtech/go-merkletree/keccak256"
+)
+
+func makeLeaves(n int) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], uint64(i))
+		hash := sha256.Sum256(buf[:])
+		leaves[i] = hash[:]
+	}
+	return leaves
+}
+
+func TestBatchMerkleTree(t *testing.T) {
+	leaves := makeLeaves(5)
+	tree, err := core.NewBatchMerkleTree(leaves)
+	assert.NoError(t, err)
+
+	// the root matches a plain merkle tree over the leaves
+	plainTree, err := merkletree.NewTree(merkletree.WithData(leaves), merkletree.WithHashType(keccak256.New()))
+	assert.NoError(t, err)
+	assert.Equal(t, plainTree.Root(), tree.Root())
+
+	var root [32]byte
+	copy(root[:], tree.Root())
+	for i, leaf := range leaves {
+		proof, err := tree.GenerateProof(leaf)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(i), proof.Index)
+
+		ok, err := proof.Verify(leaf, root)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		// the serialized proof is the concatenation of the sibling hashes
+		serialized := proof.Serialize()
+		assert.Len(t, serialized, 32*len(proof.Hashes))
+		ok, err = merkletree.VerifyProofUsing(leaf, false, &merkletree.Proof{Hashes: proof.Hashes, Index: uint64(i)}, [][]byte{root[:]}, keccak256.New())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		deserialized, err := core.DeserializeBatchMerkleProof(uint64(i), serialized)
+		assert.NoError(t, err)
+		ok, err = deserialized.Verify(leaf, root)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		// the proof does not verify another leaf
+		ok, err = proof.Verify(leaves[(i+1)%len(leaves)], root)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	}
+}
+
+func TestSetBatchRootSingleBlob(t *testing.T) {
+	blobHeader := &core.BlobHeader{
+		BlobCommitments: core.BlobCommitments{
+			Commitment:       &core.G1Commitment{},
+			LengthCommitment: &core.G2Commitment{},
+			LengthProof:      &core.G2Commitment{},
+			Length:           1,
+		},
+		QuorumInfos: []*core.BlobQuorumInfo{},
+	}
+	header := &core.BatchHeader{
+		ReferenceBlockNumber: 100,
+	}
+	tree, err := header.SetBatchRoot([]*core.BlobHeader{blobHeader})
+	assert.NoError(t, err)
+	assert.Equal(t, tree.Root(), header.BatchRoot[:])
+
+	blobHeaderHash, err := blobHeader.GetBlobHeaderHash()
+	assert.NoError(t, err)
+	proof, err := tree.GenerateProof(blobHeaderHash[:])
+	assert.NoError(t, err)
+
+	// the proof of the only blob is empty, and the batch root is the hash of its blob header
+	serialized := proof.Serialize()
+	assert.Empty(t, serialized)
+	assert.Equal(t, crypto.Keccak256(blobHeaderHash[:]), header.BatchRoot[:])
+	deserialized, err := core.DeserializeBatchMerkleProof(0, serialized)
+	assert.NoError(t, err)
+	ok, err := deserialized.Verify(blobHeaderHash[:], header.BatchRoot)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = merkletree.VerifyProofUsing(blobHeaderHash[:], false, &merkletree.Proof{Hashes: deserialized.Hashes, Index: 0}, [][]byte{header.BatchRoot[:]}, keccak256.New())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestBatchMerkleProofVerifyMalformed(t *testing.T) {
+	leaves := makeLeaves(4)
+	tree, err := core.NewBatchMerkleTree(leaves)
+	assert.NoError(t, err)
+	var root [32]byte
+	copy(root[:], tree.Root())
+	proof, err := tree.GenerateProof(leaves[1])
+	assert.NoError(t, err)
+
+	// a sibling hash of the wrong length
+	badLength := &core.BatchMerkleProof{Index: proof.Index, Hashes: [][]byte{proof.Hashes[0], proof.Hashes[1][:31]}}
+	ok, err := badLength.Verify(leaves[1], root)
+	assert.ErrorIs(t, err, core.ErrInvalidMerkleProof)
+	assert.False(t, ok)
+
+	// an index past the last leaf of a tree of the proof's depth
+	outOfRange := &core.BatchMerkleProof{Index: 4, Hashes: proof.Hashes}
+	ok, err = outOfRange.Verify(leaves[1], root)
+	assert.ErrorIs(t, err, core.ErrInvalidMerkleProof)
+	assert.False(t, ok)
+
+	// an index into a single-blob batch other than 0
+	ok, err = (&core.BatchMerkleProof{Index: 1}).Verify(leaves[0], root)
+	assert.ErrorIs(t, err, core.ErrInvalidMerkleProof)
+	assert.False(t, ok)
+}
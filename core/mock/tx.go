@@ -159,6 +159,12 @@ func (t *MockTransactor) GetQuorumCount(ctx context.Context, blockNumber uint32)
 	return result.(uint8), args.Error(1)
 }
 
+func (t *MockTransactor) GetBatchMetadataHash(ctx context.Context, batchID uint32) ([32]byte, error) {
+	args := t.Called()
+	result := args.Get(0)
+	return result.([32]byte), args.Error(1)
+}
+
 func (t *MockTransactor) PubkeyHashToOperator(ctx context.Context, operatorId core.OperatorID) (gethcommon.Address, error) {
 	args := t.Called()
 	result := args.Get(0)
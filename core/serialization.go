@@ -13,14 +13,22 @@ import (
 
 	binding "github.com/Layr-Labs/eigenda/contracts/bindings/EigenDAServiceManager"
 	bn "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/wealdtech/go-merkletree"
-	"github.com/wealdtech/go-merkletree/keccak256"
 	"golang.org/x/crypto/sha3"
 )
 
-var ErrInvalidCommitment = errors.New("invalid commitment")
+// BlobHeaderVersionV0 hashes the ABI encoding of the blob header as defined in IEigenDAServiceManager.sol.
+const BlobHeaderVersionV0 uint8 = 0
+
+// signatureAggregationVersion is the version of the encoding of SignatureAggregation
+const signatureAggregationVersion byte = 0
+
+var (
+	ErrInvalidCommitment           = errors.New("invalid commitment")
+	ErrInvalidSignatureAggregation = errors.New("invalid signature aggregation")
+)
 
 func ComputeSignatoryRecordHash(referenceBlockNumber uint32, nonSignerKeys []*G1Point) [32]byte {
 	buf := make([]byte, 4)
@@ -38,8 +46,29 @@ func ComputeSignatoryRecordHash(referenceBlockNumber uint32, nonSignerKeys []*G1
 	return res
 }
 
+// ComputeStorageAttestationMessage returns the message an operator signs to attest that it stores the given serialized
+// chunks of a blob. Each chunk is hashed separately, so that the message commits to the order of the chunks.
+func ComputeStorageAttestationMessage(batchHeaderHash [32]byte, blobIndex uint32, quorumID QuorumID, chunks [][]byte) [32]byte {
+	buf := make([]byte, 0, 32+4+1+32*len(chunks))
+	buf = append(buf, batchHeaderHash[:]...)
+	buf = binary.BigEndian.AppendUint32(buf, blobIndex)
+	buf = append(buf, quorumID)
+	for _, chunk := range chunks {
+		hasher := sha3.NewLegacyKeccak256()
+		hasher.Write(chunk)
+		buf = hasher.Sum(buf)
+	}
+
+	var res [32]byte
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(buf)
+	copy(res[:], hasher.Sum(nil)[:32])
+
+	return res
+}
+
 // SetBatchRoot sets the BatchRoot field of the BatchHeader to the Merkle root of the blob headers in the batch (i.e. the root of the Merkle tree whose leaves are the blob headers)
-func (h *BatchHeader) SetBatchRoot(blobHeaders []*BlobHeader) (*merkletree.MerkleTree, error) {
+func (h *BatchHeader) SetBatchRoot(blobHeaders []*BlobHeader) (*BatchMerkleTree, error) {
 	leafs := make([][]byte, len(blobHeaders))
 	for i, header := range blobHeaders {
 		leaf, err := header.GetBlobHeaderHash()
@@ -49,7 +78,7 @@ func (h *BatchHeader) SetBatchRoot(blobHeaders []*BlobHeader) (*merkletree.Merkl
 		leafs[i] = leaf[:]
 	}
 
-	tree, err := merkletree.NewTree(merkletree.WithData(leafs), merkletree.WithHashType(keccak256.New()))
+	tree, err := NewBatchMerkleTree(leafs)
 	if err != nil {
 		return nil, err
 	}
@@ -58,6 +87,17 @@ func (h *BatchHeader) SetBatchRoot(blobHeaders []*BlobHeader) (*merkletree.Merkl
 	return tree, nil
 }
 
+// ComputeBatchRoot computes the batch root of the ordered blob headers of a batch the same way the batcher does,
+// i.e. the root of the keccak256 Merkle tree whose leaves are the blob header hashes.
+// It allows verifiers to check the batch root stored onchain independently of the disperser.
+func ComputeBatchRoot(blobHeaders []*BlobHeader) ([32]byte, error) {
+	header := &BatchHeader{}
+	if _, err := header.SetBatchRoot(blobHeaders); err != nil {
+		return [32]byte{}, err
+	}
+	return header.BatchRoot, nil
+}
+
 func (h *BatchHeader) Encode() ([]byte, error) {
 	// The order here has to match the field ordering of ReducedBatchHeader defined in IEigenDAServiceManager.sol
 	// ref: https://github.com/Layr-Labs/eigenda/blob/master/contracts/src/interfaces/IEigenDAServiceManager.sol#L43
@@ -113,6 +153,27 @@ func (h BatchHeader) GetBatchHeaderHash() ([32]byte, error) {
 	return headerHash, nil
 }
 
+// GetSigningMessage returns the message operators sign for the batch under the signing scheme of the header. Under the
+// default BN254 BLS scheme it's the batch header hash, which is what the EigenDA contracts verify the signatures against.
+// Under any other scheme it's the keccak256 hash of the batch header hash followed by the scheme ID, so that a signature
+// under one scheme can't be passed off as a signature of the batch under another.
+func (h BatchHeader) GetSigningMessage() ([32]byte, error) {
+	headerHash, err := h.GetBatchHeaderHash()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if h.SigningScheme == SigningSchemeBN254BLS {
+		return headerHash, nil
+	}
+
+	var message [32]byte
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(headerHash[:])
+	hasher.Write([]byte{byte(h.SigningScheme)})
+	copy(message[:], hasher.Sum(nil)[:32])
+	return message, nil
+}
+
 // HashBatchHeader returns the hash of the BatchHeader that is used to emit the BatchConfirmed event
 // ref: https://github.com/Layr-Labs/eigenda/blob/master/contracts/src/libraries/EigenDAHasher.sol#L57
 func HashBatchHeader(batchHeader binding.IEigenDAServiceManagerBatchHeader) ([32]byte, error) {
@@ -170,9 +231,33 @@ func HashBatchHeader(batchHeader binding.IEigenDAServiceManagerBatchHeader) ([32
 	return headerHash, nil
 }
 
-// GetBlobHeaderHash returns the hash of the BlobHeader that is used to sign the Blob
+// HashBatchMetadata returns the hash of the batch metadata that is stored onchain for each confirmed batch, given the
+// hash of the BatchHeader returned by HashBatchHeader
+// ref: https://github.com/Layr-Labs/eigenda/blob/master/contracts/src/libraries/EigenDAHasher.sol#L19
+func HashBatchMetadata(batchHeaderHash [32]byte, signatoryRecordHash [32]byte, confirmationBlockNumber uint32) [32]byte {
+	buf := make([]byte, 0, 68)
+	buf = append(buf, batchHeaderHash[:]...)
+	buf = append(buf, signatoryRecordHash[:]...)
+	buf = binary.BigEndian.AppendUint32(buf, confirmationBlockNumber)
+
+	var res [32]byte
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(buf)
+	copy(res[:], hasher.Sum(nil)[:32])
+	return res
+}
+
+// GetBlobHeaderHash returns the hash of the BlobHeader that is used to sign the Blob.
+// The layout that is hashed is selected by the Version of the header.
 func (h BlobHeader) GetBlobHeaderHash() ([32]byte, error) {
-	headerByte, err := h.Encode()
+	var headerByte []byte
+	var err error
+	switch h.Version {
+	case BlobHeaderVersionV0:
+		headerByte, err = h.Encode()
+	default:
+		return [32]byte{}, fmt.Errorf("unsupported blob header version: %d", h.Version)
+	}
 	if err != nil {
 		return [32]byte{}, err
 	}
@@ -367,6 +452,96 @@ func (h *BlobHeader) Deserialize(data []byte) (*BlobHeader, error) {
 	return h, err
 }
 
+// Serialize encodes the signature aggregation in a language-neutral binary format, so that clients in any language can
+// verify the aggregate of a batch. Integers are big-endian and curve points are uncompressed affine coordinates encoded
+// as 32 byte big-endian integers, in the order used by the EVM: G1 points as X, Y and G2 points as X.A1, X.A0, Y.A1,
+// Y.A0. The fields are encoded in this order:
+//   - version (1 byte, signatureAggregationVersion)
+//   - signing scheme (1 byte)
+//   - number of non-signers (uint32), followed by the G1 public key of each non-signer
+//   - number of quorum aggregate public keys (uint32), followed by each G1 public key
+//   - aggregate G2 public key of the signers
+//   - aggregate G1 signature
+//   - number of quorum results (uint32), followed by the quorum ID and percent signed (1 byte each) of each quorum
+//     result, in increasing order of quorum ID
+func (a *SignatureAggregation) Serialize() ([]byte, error) {
+	if a.AggPubKey == nil || a.AggSignature == nil || a.AggSignature.G1Point == nil {
+		return nil, errors.New("the aggregate public key and signature must be set")
+	}
+	buf := []byte{signatureAggregationVersion, byte(a.Scheme)}
+	buf = appendG1Points(buf, a.NonSigners)
+	buf = appendG1Points(buf, a.QuorumAggPubKeys)
+	buf = appendG2Point(buf, a.AggPubKey.G2Affine)
+	buf = appendG1Point(buf, a.AggSignature.G1Affine)
+
+	quorumIDs := make([]QuorumID, 0, len(a.QuorumResults))
+	for quorumID := range a.QuorumResults {
+		quorumIDs = append(quorumIDs, quorumID)
+	}
+	slices.Sort(quorumIDs)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(quorumIDs)))
+	for _, quorumID := range quorumIDs {
+		var percentSigned uint8
+		if result := a.QuorumResults[quorumID]; result != nil {
+			percentSigned = result.PercentSigned
+		}
+		buf = append(buf, quorumID, percentSigned)
+	}
+	return buf, nil
+}
+
+// Deserialize decodes a signature aggregation encoded with Serialize, checking that every curve point is in the
+// correct subgroup
+func (a *SignatureAggregation) Deserialize(data []byte) (*SignatureAggregation, error) {
+	r := &byteReader{data: data}
+	version := r.next(1)
+	if r.err == nil && version[0] != signatureAggregationVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSignatureAggregation, version[0])
+	}
+	scheme := r.next(1)
+	nonSigners := r.g1Points()
+	quorumAggPubKeys := r.g1Points()
+	aggPubKey := r.g2Point()
+	aggSignature := r.g1Point()
+	numResults := r.uint32()
+	if r.err == nil && uint64(numResults)*2 > uint64(len(r.data)) {
+		r.err = ErrInvalidSignatureAggregation
+	}
+	var quorumResults map[QuorumID]*QuorumResult
+	if r.err == nil {
+		quorumResults = make(map[QuorumID]*QuorumResult, numResults)
+		for i := uint32(0); i < numResults && r.err == nil; i++ {
+			result := r.next(2)
+			if r.err == nil {
+				quorumResults[result[0]] = &QuorumResult{QuorumID: result[0], PercentSigned: result[1]}
+			}
+		}
+	}
+	if r.err == nil && len(r.data) != 0 {
+		r.err = fmt.Errorf("%w: %d trailing bytes", ErrInvalidSignatureAggregation, len(r.data))
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	a.NonSigners = nonSigners
+	a.QuorumAggPubKeys = quorumAggPubKeys
+	a.AggPubKey = &G2Point{aggPubKey}
+	a.AggSignature = &Signature{&G1Point{aggSignature}}
+	a.QuorumResults = quorumResults
+	a.Scheme = SigningSchemeID(scheme[0])
+	return a, nil
+}
+
+func (s *IndexedOperatorState) Serialize() ([]byte, error) {
+	return encode(s)
+}
+
+func (s *IndexedOperatorState) Deserialize(data []byte) (*IndexedOperatorState, error) {
+	err := decode(data, s)
+	return s, err
+}
+
 func (c *Chunk) Serialize() ([]byte, error) {
 	return encode(c)
 }
@@ -466,3 +641,98 @@ func extractIPAndPorts(s string) (string, string, string, error) {
 
 	return ip, port1, port2, nil
 }
+
+func appendG1Point(buf []byte, p *bn.G1Affine) []byte {
+	x, y := p.X.Bytes(), p.Y.Bytes()
+	buf = append(buf, x[:]...)
+	return append(buf, y[:]...)
+}
+
+func appendG1Points(buf []byte, points []*G1Point) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(points)))
+	for _, p := range points {
+		buf = appendG1Point(buf, p.G1Affine)
+	}
+	return buf
+}
+
+func appendG2Point(buf []byte, p *bn.G2Affine) []byte {
+	for _, e := range []*fp.Element{&p.X.A1, &p.X.A0, &p.Y.A1, &p.Y.A0} {
+		b := e.Bytes()
+		buf = append(buf, b[:]...)
+	}
+	return buf
+}
+
+// byteReader reads the fields of a binary encoding, recording the first error so that it only needs to be checked once
+type byteReader struct {
+	data []byte
+	err  error
+}
+
+func (r *byteReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = fmt.Errorf("%w: unexpected end of data", ErrInvalidSignatureAggregation)
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *byteReader) uint32() uint32 {
+	b := r.next(4)
+	if r.err != nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (r *byteReader) fpElement(e *fp.Element) {
+	b := r.next(fp.Bytes)
+	if r.err != nil {
+		return
+	}
+	if err := e.SetBytesCanonical(b); err != nil {
+		r.err = fmt.Errorf("%w: %v", ErrInvalidSignatureAggregation, err)
+	}
+}
+
+func (r *byteReader) g1Point() *bn.G1Affine {
+	p := new(bn.G1Affine)
+	r.fpElement(&p.X)
+	r.fpElement(&p.Y)
+	if r.err == nil && !p.IsInSubGroup() {
+		r.err = fmt.Errorf("%w: G1 point not in subgroup", ErrInvalidSignatureAggregation)
+	}
+	return p
+}
+
+func (r *byteReader) g1Points() []*G1Point {
+	n := r.uint32()
+	if r.err == nil && uint64(n)*2*fp.Bytes > uint64(len(r.data)) {
+		r.err = fmt.Errorf("%w: unexpected end of data", ErrInvalidSignatureAggregation)
+	}
+	if r.err != nil {
+		return nil
+	}
+	points := make([]*G1Point, n)
+	for i := range points {
+		points[i] = &G1Point{r.g1Point()}
+	}
+	return points
+}
+
+func (r *byteReader) g2Point() *bn.G2Affine {
+	p := new(bn.G2Affine)
+	for _, e := range []*fp.Element{&p.X.A1, &p.X.A0, &p.Y.A1, &p.Y.A0} {
+		r.fpElement(e)
+	}
+	if r.err == nil && !p.IsInSubGroup() {
+		r.err = fmt.Errorf("%w: G2 point not in subgroup", ErrInvalidSignatureAggregation)
+	}
+	return p
+}
@@ -1,6 +1,7 @@
 package core_test
 
 import (
+	"context"
 	"encoding/json"
 	"math/big"
 	"testing"
@@ -8,6 +9,7 @@ import (
 	binding "github.com/Layr-Labs/eigenda/contracts/bindings/EigenDAServiceManager"
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/core/eth"
+	"github.com/Layr-Labs/eigenda/core/mock"
 	kzgbn254 "github.com/Layr-Labs/eigenda/pkg/kzg/bn254"
 	"github.com/consensys/gnark-crypto/ecc/bn254"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
@@ -51,6 +53,34 @@ func TestBatchHeaderEncoding(t *testing.T) {
 	assert.Equal(t, hexutil.Encode(hash[:]), batchHeaderHash)
 }
 
+func TestBatchHeaderSigningMessage(t *testing.T) {
+	batchRoot := [32]byte{}
+	copy(batchRoot[:], []byte("1"))
+	batchHeader := core.BatchHeader{
+		ReferenceBlockNumber: 1,
+		BatchRoot:            batchRoot,
+	}
+
+	// operators sign the batch header hash verified onchain under the default scheme
+	message, err := batchHeader.GetSigningMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, reducedBatchHeaderHash, hexutil.Encode(message[:]))
+
+	// the message signed under any other scheme commits to the scheme, which isn't part of the batch header hash
+	batchHeader.SigningScheme = 1
+	otherMessage, err := batchHeader.GetSigningMessage()
+	assert.NoError(t, err)
+	assert.NotEqual(t, message, otherMessage)
+	hash, err := batchHeader.GetBatchHeaderHash()
+	assert.NoError(t, err)
+	assert.Equal(t, reducedBatchHeaderHash, hexutil.Encode(hash[:]))
+
+	batchHeader.SigningScheme = 2
+	thirdMessage, err := batchHeader.GetSigningMessage()
+	assert.NoError(t, err)
+	assert.NotEqual(t, otherMessage, thirdMessage)
+}
+
 func TestBlobHeaderEncoding(t *testing.T) {
 
 	var commitX, commitY fp.Element
@@ -105,6 +135,20 @@ func TestBlobHeaderEncoding(t *testing.T) {
 	h, err := blobHeader.GetBlobHeaderHash()
 	assert.NoError(t, err)
 	assert.Equal(t, blobHeaderHash, hexutil.Encode(h[:]))
+
+	// Version 0 is the default and must keep hashing to the same value
+	blobHeader.Version = core.BlobHeaderVersionV0
+	h, err = blobHeader.GetBlobHeaderHash()
+	assert.NoError(t, err)
+	assert.Equal(t, blobHeaderHash, hexutil.Encode(h[:]))
+
+	blobHeader.Version = core.BlobHeaderVersionV0 + 1
+	_, err = blobHeader.GetBlobHeaderHash()
+	assert.ErrorContains(t, err, "unsupported blob header version")
+
+	encoded, err := json.Marshal(blobHeader)
+	assert.NoError(t, err)
+	assert.Contains(t, string(encoded), `"version":1`)
 }
 
 func TestSignatoryRecord(t *testing.T) {
@@ -193,3 +237,77 @@ func TestHashPubKeyG1(t *testing.T) {
 	hash := eth.HashPubKeyG1(pk)
 	assert.Equal(t, common.Bytes2Hex(hash[:]), "426d1a0363fbdcd0c8d33b643252164057193ca022958fa0da99d9e70c980dd7")
 }
+
+func TestOperatorStateSnapshot(t *testing.T) {
+	chainState, err := mock.MakeChainDataMock(10)
+	assert.NoError(t, err)
+	ctx := context.Background()
+	quorums := []core.QuorumID{0, 1}
+
+	state, err := chainState.GetIndexedOperatorState(ctx, 123, quorums)
+	assert.NoError(t, err)
+	snapshot, err := core.ExportOperatorStateSnapshot(ctx, chainState, 123, quorums)
+	assert.NoError(t, err)
+
+	loaded, err := core.LoadOperatorStateSnapshot(snapshot)
+	assert.NoError(t, err)
+	assert.Equal(t, state, loaded)
+	assert.Equal(t, uint(123), loaded.BlockNumber)
+	assert.Len(t, loaded.Operators, 2)
+
+	_, err = core.LoadOperatorStateSnapshot(snapshot[:len(snapshot)/2])
+	assert.ErrorContains(t, err, "failed to load operator state snapshot")
+}
+
+func TestSignatureAggregationSerialization(t *testing.T) {
+	signer, err := core.GenRandomBlsKeys()
+	assert.NoError(t, err)
+	nonSigner, err := core.GenRandomBlsKeys()
+	assert.NoError(t, err)
+	message := [32]byte{1, 2, 3}
+	aggSig := &core.SignatureAggregation{
+		NonSigners:       []*core.G1Point{nonSigner.GetPubKeyG1()},
+		QuorumAggPubKeys: []*core.G1Point{signer.GetPubKeyG1(), nonSigner.GetPubKeyG1()},
+		AggPubKey:        signer.GetPubKeyG2(),
+		AggSignature:     signer.SignMessage(message),
+		QuorumResults: map[core.QuorumID]*core.QuorumResult{
+			1: {QuorumID: 1, PercentSigned: 50},
+			0: {QuorumID: 0, PercentSigned: 100},
+		},
+		Scheme: core.SigningSchemeBN254BLS,
+	}
+
+	data, err := aggSig.Serialize()
+	assert.NoError(t, err)
+	// version, scheme, 1 non-signer, 2 quorum APKs, the aggregate public key and signature, and 2 quorum results
+	assert.Len(t, data, 2+4+64+4+2*64+128+64+4+2*2)
+	// the quorum results are ordered by quorum ID
+	assert.Equal(t, []byte{0, 100, 1, 50}, data[len(data)-4:])
+	// the points are encoded as big-endian coordinates, as in the EVM
+	x := aggSig.AggSignature.X.Bytes()
+	assert.Equal(t, x[:], data[len(data)-8-64:len(data)-8-32])
+
+	decoded, err := new(core.SignatureAggregation).Deserialize(data)
+	assert.NoError(t, err)
+	assert.Len(t, decoded.NonSigners, 1)
+	assert.True(t, decoded.NonSigners[0].Equal(aggSig.NonSigners[0].G1Affine))
+	assert.Len(t, decoded.QuorumAggPubKeys, 2)
+	for i, apk := range aggSig.QuorumAggPubKeys {
+		assert.True(t, decoded.QuorumAggPubKeys[i].Equal(apk.G1Affine))
+	}
+	assert.True(t, decoded.AggPubKey.Equal(aggSig.AggPubKey.G2Affine))
+	assert.True(t, decoded.AggSignature.Equal(aggSig.AggSignature.G1Affine))
+	assert.Equal(t, aggSig.QuorumResults, decoded.QuorumResults)
+	assert.Equal(t, aggSig.Scheme, decoded.Scheme)
+	assert.True(t, decoded.AggSignature.Verify(decoded.AggPubKey, message))
+
+	// truncated, extended and corrupted encodings are rejected
+	_, err = new(core.SignatureAggregation).Deserialize(data[:len(data)-1])
+	assert.ErrorIs(t, err, core.ErrInvalidSignatureAggregation)
+	_, err = new(core.SignatureAggregation).Deserialize(append(data, 0))
+	assert.ErrorIs(t, err, core.ErrInvalidSignatureAggregation)
+	corrupted := append([]byte{}, data...)
+	corrupted[len(corrupted)-8-1] ^= 1
+	_, err = new(core.SignatureAggregation).Deserialize(corrupted)
+	assert.ErrorIs(t, err, core.ErrInvalidSignatureAggregation)
+}
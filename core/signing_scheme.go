@@ -0,0 +1,70 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SigningSchemeID identifies the signature scheme that operators use to sign a batch header
+type SigningSchemeID uint8
+
+const (
+	// SigningSchemeBN254BLS is BLS over the BN254 curve, the scheme verified by the EigenDA contracts
+	SigningSchemeBN254BLS SigningSchemeID = 0
+)
+
+var ErrUnsupportedSigningScheme = errors.New("unsupported signing scheme")
+
+// PublicKey is the public key of an operator under a signing scheme
+type PublicKey interface {
+	Serialize() []byte
+}
+
+// SchemeSignature is a signature under a signing scheme
+type SchemeSignature interface {
+	Serialize() []byte
+}
+
+// SigningScheme verifies the signatures of operators under a signature scheme. Each scheme defines the concrete types of
+// its public keys and signatures, and rejects the keys and signatures of other schemes.
+type SigningScheme interface {
+	// ID returns the identifier of the scheme recorded in the batch header
+	ID() SigningSchemeID
+	// Verify returns whether the signature is a valid signature of the message under the public key
+	Verify(pubKey PublicKey, message [32]byte, sig SchemeSignature) bool
+}
+
+type bn254BLSScheme struct{}
+
+// NewBN254BLSScheme returns the BLS over BN254 signing scheme, whose public keys are *G2Point and signatures *Signature
+func NewBN254BLSScheme() SigningScheme {
+	return bn254BLSScheme{}
+}
+
+func (bn254BLSScheme) ID() SigningSchemeID {
+	return SigningSchemeBN254BLS
+}
+
+func (bn254BLSScheme) Verify(pubKey PublicKey, message [32]byte, sig SchemeSignature) bool {
+	g2PubKey, ok := pubKey.(*G2Point)
+	if !ok || g2PubKey == nil {
+		return false
+	}
+	signature, ok := sig.(*Signature)
+	if !ok || signature == nil || signature.G1Point == nil {
+		return false
+	}
+	return signature.Verify(g2PubKey, message)
+}
+
+// SigningSchemes is a set of signing schemes keyed by their IDs
+type SigningSchemes map[SigningSchemeID]SigningScheme
+
+// Get returns the signing scheme with the given ID, or ErrUnsupportedSigningScheme if there is none
+func (s SigningSchemes) Get(id SigningSchemeID) (SigningScheme, error) {
+	scheme, ok := s[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedSigningScheme, id)
+	}
+	return scheme, nil
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
@@ -49,6 +50,13 @@ type OperatorInfo struct {
 	Index OperatorIndex
 }
 
+// ErrReferenceBlockMismatch is returned when an operator state is used with a batch pinned to a different reference block
+var ErrReferenceBlockMismatch = errors.New("operator state does not match the reference block")
+
+// ErrOperatorNotRegistered is returned when an operator state includes an operator that was not registered at the
+// reference block of the state
+var ErrOperatorNotRegistered = errors.New("operator not registered at the reference block")
+
 // OperatorState contains information about the current state of operators which is stored in the blockchain state
 type OperatorState struct {
 	// Operators is a map from quorum ID to a map from the operators in that quourm to their StoredOperatorInfo. Membership
@@ -60,6 +68,14 @@ type OperatorState struct {
 	BlockNumber uint
 }
 
+// ValidateReferenceBlock returns an error if the operator state was not retrieved at the given reference block number
+func (s *OperatorState) ValidateReferenceBlock(referenceBlockNumber uint) error {
+	if s.BlockNumber != referenceBlockNumber {
+		return fmt.Errorf("%w: state is at block %d, reference block is %d", ErrReferenceBlockMismatch, s.BlockNumber, referenceBlockNumber)
+	}
+	return nil
+}
+
 // IndexedOperatorInfo contains information about an operator which is contained in events from the EigenDA smart contracts. Note that
 // this information does not depend on the quorum.
 type IndexedOperatorInfo struct {
@@ -68,6 +84,8 @@ type IndexedOperatorInfo struct {
 	PubkeyG2 *G2Point
 	// Socket is the socket address of the operator, in the form "host:port"
 	Socket string
+	// RegistrationBlockNumber is the block number at which the operator last registered
+	RegistrationBlockNumber uint
 }
 
 // IndexedOperatorState contains information about the current state of operators which is contained in events from the EigenDA smart contracts,
@@ -80,6 +98,44 @@ type IndexedOperatorState struct {
 	AggKeys map[QuorumID]*G1Point
 }
 
+// ValidateOperatorRegistrations returns an error if an operator of any quorum of the state has no indexed operator info,
+// or registered after the block number of the state. This happens when the reference block precedes the registration of
+// the operator, in which case the assignments derived from the state can't be relied on.
+func (s *IndexedOperatorState) ValidateOperatorRegistrations() error {
+	for quorumID, operators := range s.Operators {
+		for operatorID := range operators {
+			info, ok := s.IndexedOperators[operatorID]
+			if !ok {
+				return fmt.Errorf("%w: operator %s of quorum %d at block %d", ErrOperatorNotRegistered, operatorID.Hex(), quorumID, s.BlockNumber)
+			}
+			if info.RegistrationBlockNumber > s.BlockNumber {
+				return fmt.Errorf("%w: operator %s of quorum %d registered at block %d, after block %d", ErrOperatorNotRegistered, operatorID.Hex(), quorumID, info.RegistrationBlockNumber, s.BlockNumber)
+			}
+		}
+	}
+	return nil
+}
+
+// ExportOperatorStateSnapshot returns a serialized snapshot of the stakes, public keys and sockets of the operators of the
+// given quorums at the reference block of a batch, so that the operator set the batch was dispersed to can be audited.
+// The snapshot is loaded with LoadOperatorStateSnapshot.
+func ExportOperatorStateSnapshot(ctx context.Context, chainState IndexedChainState, referenceBlockNumber uint, quorums []QuorumID) ([]byte, error) {
+	state, err := chainState.GetIndexedOperatorState(ctx, referenceBlockNumber, quorums)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operator state at block %d: %w", referenceBlockNumber, err)
+	}
+	return state.Serialize()
+}
+
+// LoadOperatorStateSnapshot returns the operator state of a snapshot made with ExportOperatorStateSnapshot
+func LoadOperatorStateSnapshot(snapshot []byte) (*IndexedOperatorState, error) {
+	state, err := new(IndexedOperatorState).Deserialize(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load operator state snapshot: %w", err)
+	}
+	return state, nil
+}
+
 // ChainState is an interface for getting information about the current chain state.
 type ChainState interface {
 	GetCurrentBlockNumber() (uint, error)
@@ -0,0 +1,34 @@
+package core_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOperatorRegistrations(t *testing.T) {
+	operatorID := core.OperatorID{1}
+	state := &core.IndexedOperatorState{
+		OperatorState: &core.OperatorState{
+			Operators: map[core.QuorumID]map[core.OperatorID]*core.OperatorInfo{
+				0: {operatorID: {Stake: big.NewInt(1), Index: 0}},
+			},
+			BlockNumber: 10,
+		},
+		IndexedOperators: map[core.OperatorID]*core.IndexedOperatorInfo{
+			operatorID: {Socket: "localhost:32000;32001", RegistrationBlockNumber: 10},
+		},
+	}
+	assert.NoError(t, state.ValidateOperatorRegistrations())
+
+	// the operator registers after the reference block
+	state.IndexedOperators[operatorID].RegistrationBlockNumber = 11
+	err := state.ValidateOperatorRegistrations()
+	assert.ErrorIs(t, err, core.ErrOperatorNotRegistered)
+	assert.ErrorContains(t, err, "registered at block 11, after block 10")
+
+	delete(state.IndexedOperators, operatorID)
+	assert.ErrorIs(t, state.ValidateOperatorRegistrations(), core.ErrOperatorNotRegistered)
+}
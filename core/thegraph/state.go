@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 	"time"
 
@@ -46,6 +47,8 @@ type (
 		PubkeyG2_Y []graphql.String `graphql:"pubkeyG2_Y"`
 		// Socket is the socket address of the operator, in the form "host:port"
 		SocketUpdates []SocketUpdates `graphql:"socketUpdates(first: 1, orderBy: blockNumber, orderDirection: desc)"`
+		// RegistrationBlockNumber is the block number at which the operator last registered
+		RegistrationBlockNumber graphql.String
 	}
 
 	QueryOperatorsGql struct {
@@ -317,9 +320,15 @@ func convertIndexedOperatorInfoGqlToIndexedOperatorInfo(operator *IndexedOperato
 		return nil, err
 	}
 
+	registrationBlockNumber, err := strconv.ParseUint(string(operator.RegistrationBlockNumber), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registration block number: %w", err)
+	}
+
 	return &core.IndexedOperatorInfo{
-		PubkeyG1: &core.G1Point{G1Affine: pubkeyG1},
-		PubkeyG2: &core.G2Point{G2Affine: pubkeyG2},
-		Socket:   string(operator.SocketUpdates[0].Socket),
+		PubkeyG1:                &core.G1Point{G1Affine: pubkeyG1},
+		PubkeyG2:                &core.G2Point{G2Affine: pubkeyG2},
+		Socket:                  string(operator.SocketUpdates[0].Socket),
+		RegistrationBlockNumber: uint(registrationBlockNumber),
 	}, nil
 }
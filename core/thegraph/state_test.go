@@ -77,7 +77,8 @@ func TestIndexedChainState_GetIndexedOperatorState(t *testing.T) {
 						"9416989242565286095121881312760798075882411191579108217086927390793923664442",
 						"13612061731370453436662267863740141021994163834412349567410746669651828926551",
 					},
-					SocketUpdates: []thegraph.SocketUpdates{{Socket: "localhost:32006;32007"}},
+					SocketUpdates:           []thegraph.SocketUpdates{{Socket: "localhost:32006;32007"}},
+					RegistrationBlockNumber: "1",
 				},
 			}
 			operatorsQueryCalled = true
@@ -134,7 +135,8 @@ func TestIndexedChainState_GetIndexedOperatorInfoByOperatorId(t *testing.T) {
 					"9416989242565286095121881312760798075882411191579108217086927390793923664442",
 					"13612061731370453436662267863740141021994163834412349567410746669651828926551",
 				},
-				SocketUpdates: []thegraph.SocketUpdates{{Socket: "localhost:32006;32007"}},
+				SocketUpdates:           []thegraph.SocketUpdates{{Socket: "localhost:32006;32007"}},
+				RegistrationBlockNumber: "1",
 			}
 			return nil
 		default:
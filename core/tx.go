@@ -122,4 +122,8 @@ type Transactor interface {
 
 	// GetQuorumCount returns the number of quorums registered at given block number.
 	GetQuorumCount(ctx context.Context, blockNumber uint32) (uint8, error)
+
+	// GetBatchMetadataHash returns the hash of the metadata of the batch with the given ID stored onchain when the batch
+	// was confirmed, or a zero hash if no batch with the ID was confirmed.
+	GetBatchMetadataHash(ctx context.Context, batchID uint32) ([32]byte, error)
 }
@@ -0,0 +1,204 @@
+package apiserver
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigenda/common"
+	"github.com/Layr-Labs/eigenda/disperser"
+)
+
+const (
+	// maxConfirmationLatencySamples is the number of most recent confirmation latencies used for the estimate. It also
+	// bounds the number of blobs that left the queue whose metadata is read on each refresh of the queue.
+	maxConfirmationLatencySamples = 100
+	// defaultQueueRefreshInterval is how long the queue of blobs waiting for confirmation is cached if the interval
+	// isn't configured
+	defaultQueueRefreshInterval = 10 * time.Second
+)
+
+// queuedBlob is a blob waiting for confirmation
+type queuedBlob struct {
+	key         disperser.BlobKey
+	requestedAt uint64
+}
+
+// confirmationEstimator estimates the time until a queued blob is confirmed. The estimate is the median of the
+// recently observed confirmation latencies, less the time the blob has already waited, plus one batch interval
+// for each full batch of blobs queued ahead of it.
+//
+// The queue of blobs waiting for confirmation is read from the blob store at most once per refresh interval. The
+// confirmation latencies are sampled from the blobs that were confirmed since the previous refresh, so that they
+// don't depend on which blobs the clients poll nor on when they poll them.
+type confirmationEstimator struct {
+	blobStore disperser.BlobStore
+	logger    common.Logger
+
+	batchInterval              time.Duration
+	blobsPerBatch              uint
+	defaultConfirmationLatency time.Duration
+	queueRefreshInterval       time.Duration
+
+	mu sync.Mutex
+	// latencies is a ring buffer of the most recent confirmation latencies
+	latencies []time.Duration
+	next      int
+
+	// queueMu is held while the queue is refreshed, so that concurrent estimates share a single read of the queue
+	queueMu sync.Mutex
+	// queue holds the blobs waiting for confirmation as of queueRefreshedAt, by request time
+	queue            []queuedBlob
+	queueRefreshedAt time.Time
+}
+
+func newConfirmationEstimator(
+	blobStore disperser.BlobStore,
+	logger common.Logger,
+	batchInterval time.Duration,
+	blobsPerBatch uint,
+	defaultConfirmationLatency time.Duration,
+	queueRefreshInterval time.Duration,
+) *confirmationEstimator {
+	if blobsPerBatch == 0 {
+		blobsPerBatch = 1
+	}
+	if queueRefreshInterval == 0 {
+		queueRefreshInterval = defaultQueueRefreshInterval
+	}
+	return &confirmationEstimator{
+		blobStore:                  blobStore,
+		logger:                     logger,
+		batchInterval:              batchInterval,
+		blobsPerBatch:              blobsPerBatch,
+		defaultConfirmationLatency: defaultConfirmationLatency,
+		queueRefreshInterval:       queueRefreshInterval,
+		latencies:                  make([]time.Duration, 0, maxConfirmationLatencySamples),
+	}
+}
+
+// QueuePosition returns the position in the queue of a blob requested at the given time, i.e. the number of blobs
+// waiting for confirmation that were requested at or before it, as of the last refresh of the queue
+func (e *confirmationEstimator) QueuePosition(ctx context.Context, requestedAt uint64) (uint, error) {
+	e.queueMu.Lock()
+	defer e.queueMu.Unlock()
+
+	if e.queueRefreshedAt.IsZero() || time.Since(e.queueRefreshedAt) >= e.queueRefreshInterval {
+		if err := e.refreshQueue(ctx); err != nil {
+			return 0, err
+		}
+	}
+	position := sort.Search(len(e.queue), func(i int) bool {
+		return e.queue[i].requestedAt > requestedAt
+	})
+	return uint(position), nil
+}
+
+// refreshQueue reads the blobs waiting for confirmation, and samples the confirmation latencies of the blobs that left
+// the queue since the previous refresh
+func (e *confirmationEstimator) refreshQueue(ctx context.Context) error {
+	processing, err := e.blobStore.GetBlobMetadataByStatus(ctx, disperser.Processing)
+	if err != nil {
+		return err
+	}
+	refreshedAt := time.Now()
+
+	queue := make([]queuedBlob, 0, len(processing))
+	queued := make(map[disperser.BlobKey]struct{}, len(processing))
+	for _, metadata := range processing {
+		if metadata.RequestMetadata == nil {
+			continue
+		}
+		key := metadata.GetBlobKey()
+		queue = append(queue, queuedBlob{key: key, requestedAt: metadata.RequestMetadata.RequestedAt})
+		queued[key] = struct{}{}
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		return queue[i].requestedAt < queue[j].requestedAt
+	})
+
+	left := make([]disperser.BlobKey, 0)
+	for _, blob := range e.queue {
+		if _, ok := queued[blob.key]; !ok {
+			left = append(left, blob.key)
+		}
+	}
+	// the blobs requested last are the ones whose latency reflects the current load
+	if len(left) > maxConfirmationLatencySamples {
+		left = left[len(left)-maxConfirmationLatencySamples:]
+	}
+	for _, key := range left {
+		metadata, err := e.blobStore.GetBlobMetadata(ctx, key)
+		if err != nil {
+			e.logger.Warn("failed to get the metadata of a blob that left the queue", "key", key.String(), "err", err)
+			continue
+		}
+		e.observeConfirmation(metadata, refreshedAt)
+	}
+
+	e.queue = queue
+	e.queueRefreshedAt = refreshedAt
+	return nil
+}
+
+// observeConfirmation records the confirmation latency of a blob if it's confirmed. The latency is measured up to the
+// time the batcher processed the confirmation, or up to the given time for blobs confirmed by earlier releases.
+func (e *confirmationEstimator) observeConfirmation(metadata *disperser.BlobMetadata, now time.Time) {
+	if metadata.RequestMetadata == nil || metadata.ConfirmationInfo == nil {
+		return
+	}
+	switch metadata.BlobStatus {
+	case disperser.Confirmed, disperser.Finalized:
+	default:
+		return
+	}
+	confirmedAt := now
+	if metadata.ConfirmationInfo.ConfirmedAt != 0 {
+		confirmedAt = time.Unix(0, int64(metadata.ConfirmationInfo.ConfirmedAt))
+	}
+	latency := confirmedAt.Sub(time.Unix(0, int64(metadata.RequestMetadata.RequestedAt)))
+	if latency < 0 {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.latencies) < maxConfirmationLatencySamples {
+		e.latencies = append(e.latencies, latency)
+		return
+	}
+	e.latencies[e.next] = latency
+	e.next = (e.next + 1) % maxConfirmationLatencySamples
+}
+
+// confirmationLatency returns the median of the recent confirmation latencies, or the default latency if
+// no confirmation has been observed yet
+func (e *confirmationEstimator) confirmationLatency() time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.latencies) == 0 {
+		return e.defaultConfirmationLatency
+	}
+	sorted := slices.Clone(e.latencies)
+	slices.Sort(sorted)
+	return sorted[len(sorted)/2]
+}
+
+// Estimate returns the estimated time until a blob is confirmed given its position in the queue (1 for the
+// blob at the head of the queue) and the time it has already waited
+func (e *confirmationEstimator) Estimate(queuePosition uint, waited time.Duration) time.Duration {
+	if waited < 0 {
+		waited = 0
+	}
+	remaining := e.confirmationLatency() - waited
+	if remaining < 0 {
+		remaining = 0
+	}
+	if queuePosition == 0 {
+		return remaining
+	}
+	batchesAhead := (queuePosition - 1) / e.blobsPerBatch
+	return remaining + time.Duration(batchesAhead)*e.batchInterval
+}
@@ -19,6 +19,7 @@ const (
 	PerUserUnauthBlobRateFlagName   = "auth.per-user-unauth-blob-rate"
 	ClientIPHeaderFlagName          = "auth.client-ip-header"
 	AllowlistFlagName               = "auth.allowlist"
+	PerUserEstimateRateFlagName     = "auth.per-user-estimate-rate"
 
 	// We allow the user to specify the blob rate in blobs/sec, but internally we use blobs/sec * 1e6 (i.e. blobs/microsec).
 	// This is because the rate limiter takes an integer rate.
@@ -43,6 +44,9 @@ type RateConfig struct {
 	QuorumRateInfos map[core.QuorumID]QuorumRateInfo
 	ClientIPHeader  string
 	Allowlist       Allowlist
+	// PerUserEstimateRate is the rate of EstimateConfirmation requests allowed for each client origin, in requests/sec
+	// multiplied by blobRateMultiplier. 0 means no limit.
+	PerUserEstimateRate common.RateParam
 }
 
 func CLIFlags(envPrefix string) []cli.Flag {
@@ -91,6 +95,13 @@ func CLIFlags(envPrefix string) []cli.Flag {
 			Required: false,
 			Value:    &cli.StringSlice{},
 		},
+		cli.Float64Flag{
+			Name:     PerUserEstimateRateFlagName,
+			Usage:    "Per-user rate of EstimateConfirmation requests (Requests/sec). 0 means no limit",
+			Required: false,
+			Value:    1,
+			EnvVar:   common.PrefixEnvVar(envPrefix, "PER_USER_ESTIMATE_RATE"),
+		},
 	}
 }
 
@@ -166,5 +177,7 @@ func ReadCLIConfig(c *cli.Context) (RateConfig, error) {
 		QuorumRateInfos: quorumRateInfos,
 		ClientIPHeader:  c.String(ClientIPHeaderFlagName),
 		Allowlist:       allowlist,
+
+		PerUserEstimateRate: common.RateParam(c.Float64(PerUserEstimateRateFlagName) * blobRateMultiplier),
 	}, nil
 }
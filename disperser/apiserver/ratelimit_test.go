@@ -13,7 +13,9 @@ import (
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/core/auth"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 func TestRatelimit(t *testing.T) {
@@ -187,6 +189,36 @@ func TestAuthRatelimit(t *testing.T) {
 
 }
 
+func TestEstimateConfirmationRatelimit(t *testing.T) {
+	p := &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("1.1.1.2"),
+			Port: 51001,
+		},
+	}
+	ctx := peer.NewContext(context.Background(), p)
+
+	// Should be rate limited because the estimate rate (1 request/s) X bucket size (3s) is smaller than 20 requests
+	numLimited := 0
+	for i := 0; i < 20; i++ {
+		_, err := dispersalServer.EstimateConfirmation(ctx, &pb.EstimateConfirmationRequest{RequestId: []byte("unknown")})
+		if status.Code(err) == codes.ResourceExhausted {
+			numLimited++
+		}
+	}
+	assert.Greater(t, numLimited, 0)
+
+	// The requests of other clients aren't limited
+	p = &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("1.1.1.3"),
+			Port: 51001,
+		},
+	}
+	_, err := dispersalServer.EstimateConfirmation(peer.NewContext(context.Background(), p), &pb.EstimateConfirmationRequest{RequestId: []byte("unknown")})
+	assert.NotEqual(t, codes.ResourceExhausted, status.Code(err))
+}
+
 func simulateClient(t *testing.T, signer core.BlobRequestSigner, origin string, data []byte, params []*pb.SecurityParams, errorChan chan error, shouldSucceed bool) {
 
 	p := &peer.Peer{
@@ -2,6 +2,7 @@ package apiserver
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -18,22 +19,36 @@ import (
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/core/auth"
 	"github.com/Layr-Labs/eigenda/disperser"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 var errSystemBlobRateLimit = fmt.Errorf("request ratelimited: system blob limit")
 var errSystemThroughputRateLimit = fmt.Errorf("request ratelimited: system throughput limit")
 var errAccountBlobRateLimit = fmt.Errorf("request ratelimited: account blob limit")
 var errAccountThroughputRateLimit = fmt.Errorf("request ratelimited: account throughput limit")
+var errEstimateRateLimit = fmt.Errorf("request ratelimited: estimate confirmation limit")
 
 const systemAccountKey = "system"
 
+// estimateAccountKeyPrefix prefixes the rate limiter keys of the EstimateConfirmation requests of each client origin
+const estimateAccountKeyPrefix = "estimate"
+
 const maxBlobSize = 2 * 1024 * 1024 // 2 MiB
 
+const maxIdempotencyKeyLength = 128
+
+const maxClientMetadataLength = 1024
+
+// maxBlobsByCommitmentPerPage is the maximum number of blobs returned in a page of GetBlobByCommitment
+const maxBlobsByCommitmentPerPage = 100
+
 type DispersalServer struct {
 	pb.UnimplementedDisperserServer
 	mu *sync.Mutex
@@ -43,6 +58,10 @@ type DispersalServer struct {
 	blobStore   disperser.BlobStore
 	tx          core.Transactor
 	quorumCount uint8
+	// allowedQuorums is the set of quorums clients may disperse to. Nil means all onchain quorums are allowed.
+	allowedQuorums map[core.QuorumID]struct{}
+
+	confirmationEstimator *confirmationEstimator
 
 	rateConfig    RateConfig
 	ratelimiter   common.RateLimiter
@@ -50,6 +69,10 @@ type DispersalServer struct {
 
 	metrics *disperser.Metrics
 
+	// watchStreams is the number of open WatchBlobStatus streams of each client origin
+	watchStreamsMu sync.Mutex
+	watchStreams   map[string]uint
+
 	logger common.Logger
 }
 
@@ -71,19 +94,40 @@ func NewDispersalServer(
 		}
 	}
 
-	authenticator := auth.NewAuthenticator(auth.AuthConfig{})
+	authenticator := auth.NewAuthenticator(auth.AuthConfig{
+		AllowedAccounts: config.AllowedAccounts,
+	})
+
+	var allowedQuorums map[core.QuorumID]struct{}
+	if len(config.AllowedQuorumIDs) > 0 {
+		allowedQuorums = make(map[core.QuorumID]struct{}, len(config.AllowedQuorumIDs))
+		for _, quorumID := range config.AllowedQuorumIDs {
+			allowedQuorums[quorumID] = struct{}{}
+		}
+		logger.Info("[AllowedQuorums]", "quorumIDs", config.AllowedQuorumIDs)
+	}
 
 	return &DispersalServer{
-		config:        config,
-		blobStore:     store,
-		tx:            tx,
-		quorumCount:   0,
+		config:         config,
+		blobStore:      store,
+		tx:             tx,
+		quorumCount:    0,
+		allowedQuorums: allowedQuorums,
+		confirmationEstimator: newConfirmationEstimator(
+			store,
+			logger,
+			config.BatchInterval,
+			config.BlobsPerBatch,
+			config.DefaultConfirmationLatency,
+			config.QueueRefreshInterval,
+		),
 		metrics:       metrics,
 		logger:        logger,
 		ratelimiter:   ratelimiter,
 		authenticator: authenticator,
 		rateConfig:    rateConfig,
 		mu:            &sync.Mutex{},
+		watchStreams:  make(map[string]uint),
 	}
 }
 
@@ -167,6 +211,10 @@ func (s *DispersalServer) DisperseBlobAuthenticated(stream pb.Disperser_Disperse
 }
 
 func (s *DispersalServer) DisperseBlob(ctx context.Context, req *pb.DisperseBlobRequest) (*pb.DisperseBlobReply, error) {
+	if s.config.RequireAuthentication {
+		s.metrics.HandleRejectedRequest(disperser.RejectUnauthenticated)
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated dispersal is disabled, use DisperseBlobAuthenticated")
+	}
 
 	blob := getBlobFromRequest(req)
 
@@ -185,21 +233,35 @@ func (s *DispersalServer) disperseBlob(ctx context.Context, blob *core.Blob, aut
 
 	securityParams := blob.RequestHeader.SecurityParams
 	if len(securityParams) == 0 {
+		s.metrics.HandleRejectedRequest(disperser.RejectEmptySecurityParams)
 		return nil, fmt.Errorf("invalid request: security_params must not be empty")
 	}
 	if len(securityParams) > 256 {
+		s.metrics.HandleRejectedRequest(disperser.RejectTooManyQuorums)
 		return nil, fmt.Errorf("invalid request: security_params must not exceed 256")
 	}
+	if s.config.MaxQuorumsPerBlob > 0 && uint(len(securityParams)) > s.config.MaxQuorumsPerBlob {
+		s.metrics.HandleRejectedRequest(disperser.RejectTooManyQuorums)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid request: security_params must not exceed %d quorums, but found %d", s.config.MaxQuorumsPerBlob, len(securityParams))
+	}
 
 	seenQuorums := make(map[uint8]struct{})
 	// The quorum ID must be in range [0, 254]. It'll actually be converted
 	// to uint8, so it cannot be greater than 254.
 	for _, param := range securityParams {
 		if _, ok := seenQuorums[param.QuorumID]; ok {
+			s.metrics.HandleRejectedRequest(disperser.RejectDuplicateQuorum)
 			return nil, fmt.Errorf("invalid request: security_params must not contain duplicate quorum_id")
 		}
 		seenQuorums[param.QuorumID] = struct{}{}
 
+		if s.allowedQuorums != nil {
+			if _, ok := s.allowedQuorums[param.QuorumID]; !ok {
+				s.metrics.HandleRejectedRequest(disperser.RejectUnsupportedQuorum)
+				return nil, status.Errorf(codes.InvalidArgument, "invalid request: quorum_id %d is not supported by this disperser", param.QuorumID)
+			}
+		}
+
 		if param.QuorumID >= s.quorumCount {
 			err := s.updateQuorumCount(ctx)
 			if err != nil {
@@ -207,6 +269,7 @@ func (s *DispersalServer) disperseBlob(ctx context.Context, blob *core.Blob, aut
 			}
 
 			if param.QuorumID >= s.quorumCount {
+				s.metrics.HandleRejectedRequest(disperser.RejectInvalidQuorum)
 				return nil, fmt.Errorf("invalid request: the quorum_id must be in range [0, %d], but found %d", s.quorumCount-1, param.QuorumID)
 			}
 		}
@@ -215,12 +278,29 @@ func (s *DispersalServer) disperseBlob(ctx context.Context, blob *core.Blob, aut
 	blobSize := len(blob.Data)
 	// The blob size in bytes must be in range [1, maxBlobSize].
 	if blobSize > maxBlobSize {
+		s.metrics.HandleRejectedRequest(disperser.RejectOversizedBlob)
 		return nil, fmt.Errorf("blob size cannot exceed 2 MiB")
 	}
 	if blobSize == 0 {
+		s.metrics.HandleRejectedRequest(disperser.RejectEmptyBlob)
 		return nil, fmt.Errorf("blob size must be greater than 0")
 	}
 
+	if len(blob.RequestHeader.IdempotencyKey) > maxIdempotencyKeyLength {
+		s.metrics.HandleRejectedRequest(disperser.RejectIdempotencyKeyTooLong)
+		return nil, fmt.Errorf("invalid request: idempotency_key must not exceed %d bytes", maxIdempotencyKeyLength)
+	}
+
+	if len(blob.RequestHeader.ClientMetadata) > maxClientMetadataLength {
+		s.metrics.HandleRejectedRequest(disperser.RejectClientMetadataTooLong)
+		return nil, fmt.Errorf("invalid request: client_metadata must not exceed %d bytes", maxClientMetadataLength)
+	}
+
+	if blob.RequestHeader.Priority > core.MaxBlobPriority {
+		s.metrics.HandleRejectedRequest(disperser.RejectInvalidPriority)
+		return nil, fmt.Errorf("invalid request: unknown priority %d", blob.RequestHeader.Priority)
+	}
+
 	origin, err := common.GetClientAddress(ctx, s.rateConfig.ClientIPHeader, 2, true)
 	if err != nil {
 		for _, param := range securityParams {
@@ -236,8 +316,16 @@ func (s *DispersalServer) disperseBlob(ctx context.Context, blob *core.Blob, aut
 	}
 	s.logger.Debug("received a new blob request", "origin", origin, "securityParams", strings.Join(securityParamsStrings, ", "))
 
+	// Clients can't expedite their blobs on their own: the high priority lane is reserved to the authenticated requests
+	// and the allowlisted accounts
+	if blob.RequestHeader.Priority > core.PriorityNormal && len(authenticatedAddress) == 0 && !s.isAllowlisted(origin) {
+		s.metrics.HandleRejectedRequest(disperser.RejectUnauthorizedPriority)
+		return nil, status.Errorf(codes.PermissionDenied, "invalid request: priority %d requires an authenticated or allowlisted account", blob.RequestHeader.Priority)
+	}
+
 	if err := blob.RequestHeader.Validate(); err != nil {
 		s.logger.Warn("invalid header", "err", err)
+		s.metrics.HandleRejectedRequest(disperser.RejectInvalidHeader)
 		for _, param := range securityParams {
 			quorumId := string(param.QuorumID)
 			s.metrics.HandleFailedRequest(quorumId, blobSize, "DisperseBlob")
@@ -245,6 +333,37 @@ func (s *DispersalServer) disperseBlob(ctx context.Context, blob *core.Blob, aut
 		return nil, err
 	}
 
+	// Blobs are attributed to the authenticated account, or to the origin of unauthenticated requests
+	blob.RequestHeader.Requester = authenticatedAddress
+	if blob.RequestHeader.Requester == "" {
+		blob.RequestHeader.Requester = origin
+	}
+
+	// A retried request with the same idempotency key returns the blob that was already stored.
+	// This check happens before rate limiting so that retries are not charged twice. Keys are scoped to the requester.
+	var payloadHash string
+	if idempotencyKey := blob.RequestHeader.IdempotencyKey; idempotencyKey != "" {
+		blob.RequestHeader.IdempotencyKey = disperser.ScopeIdempotencyKey(blob.RequestHeader.Requester, idempotencyKey)
+		payloadHash = disperser.GetIdempotencyPayloadHash(blob)
+		existing, err := s.blobStore.GetBlobMetadataByIdempotencyKey(ctx, blob.RequestHeader.IdempotencyKey, payloadHash)
+		if err == nil {
+			s.logger.Info("found existing blob for idempotency key", "idempotencyKey", idempotencyKey, "key", existing.GetBlobKey().String())
+			return s.makeDisperseBlobReply(getResponseStatus(existing.BlobStatus), existing.GetBlobKey(), existing.RequestMetadata.RequestedAt)
+		}
+		if errors.Is(err, disperser.ErrIdempotencyKeyMismatch) {
+			s.metrics.HandleRejectedRequest(disperser.RejectIdempotencyKeyReused)
+			return nil, status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+		}
+		if !errors.Is(err, disperser.ErrBlobNotFound) {
+			for _, param := range securityParams {
+				quorumId := string(param.QuorumID)
+				s.metrics.HandleBlobStoreFailedRequest(quorumId, blobSize, "DisperseBlob")
+			}
+			s.logger.Error("failed to look up blob by idempotency key", "err", err)
+			return nil, fmt.Errorf("failed to look up blob by idempotency key, please try again later")
+		}
+	}
+
 	if s.ratelimiter != nil {
 		err := s.checkRateLimitsAndAddRates(ctx, blob, origin, authenticatedAddress)
 		if err != nil {
@@ -262,8 +381,40 @@ func (s *DispersalServer) disperseBlob(ctx context.Context, blob *core.Blob, aut
 		}
 	}
 
+	// A request duplicating the content and quorums of an earlier blob of the same requester is handled according to the
+	// duplicate blob policy. Unlike idempotent retries, duplicates are rate limited like any other request, so that
+	// they can't be used to probe the blobs of other requesters or to bypass the limits.
+	if policy := s.config.DuplicateBlobPolicy; policy == disperser.DuplicateBlobDedup || policy == disperser.DuplicateBlobReject {
+		existing, err := s.findDuplicateBlob(ctx, blob)
+		if err != nil {
+			for _, param := range securityParams {
+				quorumId := string(param.QuorumID)
+				s.metrics.HandleBlobStoreFailedRequest(quorumId, blobSize, "DisperseBlob")
+			}
+			s.logger.Error("failed to look up duplicate blobs", "err", err)
+			return nil, fmt.Errorf("failed to look up duplicate blobs, please try again later")
+		}
+		if existing != nil {
+			existingKey := existing.GetBlobKey().String()
+			s.metrics.HandleDuplicateRequest(string(policy))
+			s.logger.Info("found existing blob with identical content and quorums", "policy", policy, "key", existingKey)
+			if policy == disperser.DuplicateBlobReject {
+				return nil, status.Errorf(codes.AlreadyExists, "blob with identical content and quorums was already dispersed with request_id %s", existingKey)
+			}
+			return s.makeDisperseBlobReply(getResponseStatus(existing.BlobStatus), existing.GetBlobKey(), existing.RequestMetadata.RequestedAt)
+		}
+	}
+
 	requestedAt := uint64(time.Now().UnixNano())
 	metadataKey, err := s.blobStore.StoreBlob(ctx, blob, requestedAt)
+	if errors.Is(err, disperser.ErrIdempotencyKeyMismatch) {
+		s.metrics.HandleRejectedRequest(disperser.RejectIdempotencyKeyReused)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+	}
+	if errors.Is(err, disperser.ErrIdempotencyKeyExists) {
+		// a concurrent request with the same idempotency key stored the blob first
+		return s.makeIdempotentDisperseBlobReply(ctx, metadataKey, payloadHash, blob.RequestHeader.IdempotencyKey)
+	}
 	if err != nil {
 		for _, param := range securityParams {
 			quorumId := string(param.QuorumID)
@@ -279,10 +430,42 @@ func (s *DispersalServer) disperseBlob(ctx context.Context, blob *core.Blob, aut
 	}
 
 	s.logger.Info("successfully received a new blob: ", "key", metadataKey.String())
-	return &pb.DisperseBlobReply{
-		Result:    pb.BlobStatus_PROCESSING,
-		RequestId: []byte(metadataKey.String()),
-	}, nil
+	return s.makeDisperseBlobReply(pb.BlobStatus_PROCESSING, metadataKey, requestedAt)
+}
+
+// makeIdempotentDisperseBlobReply returns the reply to a request whose idempotency key was claimed by a concurrent
+// request for the blob with the given key
+func (s *DispersalServer) makeIdempotentDisperseBlobReply(ctx context.Context, blobKey disperser.BlobKey, payloadHash string, idempotencyKey string) (*pb.DisperseBlobReply, error) {
+	existing, err := s.blobStore.GetBlobMetadataByIdempotencyKey(ctx, idempotencyKey, payloadHash)
+	if errors.Is(err, disperser.ErrBlobNotFound) {
+		// the concurrent request hasn't stored the blob metadata yet
+		return nil, status.Errorf(codes.Unavailable, "a request with the same idempotency key is in progress, please try again later")
+	}
+	if err != nil {
+		s.logger.Error("failed to look up blob by idempotency key", "err", err)
+		return nil, fmt.Errorf("failed to look up blob by idempotency key, please try again later")
+	}
+	s.logger.Info("found existing blob for idempotency key", "key", blobKey.String())
+	return s.makeDisperseBlobReply(getResponseStatus(existing.BlobStatus), existing.GetBlobKey(), existing.RequestMetadata.RequestedAt)
+}
+
+// makeDisperseBlobReply returns the reply to the dispersal of the blob with the given key, with the receipt of the blob
+// if receipts are enabled
+func (s *DispersalServer) makeDisperseBlobReply(status pb.BlobStatus, blobKey disperser.BlobKey, requestedAt uint64) (*pb.DisperseBlobReply, error) {
+	reply := &pb.DisperseBlobReply{
+		Result:    status,
+		RequestId: []byte(blobKey.String()),
+	}
+	if s.config.ReceiptSigner == nil {
+		return reply, nil
+	}
+	receipt, err := s.config.ReceiptSigner.SignReceipt(blobKey, requestedAt)
+	if err != nil {
+		s.logger.Error("failed to sign blob receipt", "key", blobKey.String(), "err", err)
+		return nil, fmt.Errorf("failed to sign blob receipt, please try again later")
+	}
+	reply.Receipt = receipt.ToProtobuf()
+	return reply, nil
 }
 
 func (s *DispersalServer) getAccountRate(origin, authenticatedAddress string, quorumID core.QuorumID) (*PerUserRateInfo, string, error) {
@@ -343,6 +526,17 @@ func (s *DispersalServer) getAccountRate(origin, authenticatedAddress string, qu
 
 }
 
+// isAllowlisted returns whether the origin of a request matches an account of the allowlist, the same way as the rates of
+// the allowlist are applied
+func (s *DispersalServer) isAllowlisted(origin string) bool {
+	for account := range s.rateConfig.Allowlist {
+		if strings.Contains(origin, account) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *DispersalServer) checkRateLimitsAndAddRates(ctx context.Context, blob *core.Blob, origin, authenticatedAddress string) error {
 
 	// TODO(robert): Remove these locks once we have resolved ratelimiting approach
@@ -442,15 +636,138 @@ func (s *DispersalServer) GetBlobStatus(ctx context.Context, req *pb.BlobStatusR
 		return nil, err
 	}
 
+	return s.getBlobStatusReply(metadata)
+}
+
+// WatchBlobStatus streams the status of the blob, starting with its current status and followed by each status
+// transition, until the blob reaches a terminal status
+func (s *DispersalServer) WatchBlobStatus(req *pb.BlobStatusRequest, stream pb.Disperser_WatchBlobStatusServer) error {
+	requestID := req.GetRequestId()
+	if len(requestID) == 0 {
+		return fmt.Errorf("invalid request: request_id must not be empty")
+	}
+
+	s.logger.Info("received a new blob status watch request", "requestID", string(requestID))
+	metadataKey, err := disperser.ParseBlobKey(string(requestID))
+	if err != nil {
+		return err
+	}
+
+	origin, err := common.GetClientAddress(stream.Context(), s.rateConfig.ClientIPHeader, 2, true)
+	if err != nil {
+		return err
+	}
+	release, err := s.acquireWatchStream(origin)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if s.config.MaxWatchDuration > 0 {
+		ctx, cancel = context.WithTimeout(stream.Context(), s.config.MaxWatchDuration)
+	} else {
+		ctx, cancel = context.WithCancel(stream.Context())
+	}
+	defer cancel()
+
+	// Subscribe before reading the current status so that no transition is missed in between
+	updates, err := s.blobStore.WatchBlobStatus(ctx, metadataKey)
+	if err != nil {
+		return err
+	}
+	metadata, err := s.blobStore.GetBlobMetadata(ctx, metadataKey)
+	if err != nil {
+		return err
+	}
+
+	for {
+		reply, err := s.getBlobStatusReply(metadata)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(reply); err != nil {
+			return err
+		}
+		if isTerminalStatus(metadata.BlobStatus) {
+			return nil
+		}
+
+		lastStatus := metadata.BlobStatus
+		for metadata.BlobStatus == lastStatus {
+			var ok bool
+			select {
+			case metadata, ok = <-updates:
+			case <-ctx.Done():
+				return s.watchStreamDone(stream.Context(), ctx)
+			}
+			if !ok {
+				return s.watchStreamDone(stream.Context(), ctx)
+			}
+		}
+	}
+}
+
+// acquireWatchStream counts a new WatchBlobStatus stream of the client origin, and returns the function releasing it.
+// It returns ResourceExhausted if the client already has MaxWatchStreamsPerClient streams open.
+func (s *DispersalServer) acquireWatchStream(origin string) (func(), error) {
+	s.watchStreamsMu.Lock()
+	defer s.watchStreamsMu.Unlock()
+	if limit := s.config.MaxWatchStreamsPerClient; limit > 0 && s.watchStreams[origin] >= limit {
+		return nil, status.Errorf(codes.ResourceExhausted, "too many blob status streams open, at most %d are allowed per client", limit)
+	}
+	s.watchStreams[origin]++
+	return func() {
+		s.watchStreamsMu.Lock()
+		defer s.watchStreamsMu.Unlock()
+		s.watchStreams[origin]--
+		if s.watchStreams[origin] == 0 {
+			delete(s.watchStreams, origin)
+		}
+	}, nil
+}
+
+// watchStreamDone returns the error ending a WatchBlobStatus stream whose watch context is done, telling the client to
+// watch the blob again if the stream reached MaxWatchDuration
+func (s *DispersalServer) watchStreamDone(streamCtx, watchCtx context.Context) error {
+	if streamCtx.Err() == nil && errors.Is(watchCtx.Err(), context.DeadlineExceeded) {
+		return status.Errorf(codes.DeadlineExceeded, "blob status stream exceeded the maximum duration of %s, please watch the blob again", s.config.MaxWatchDuration)
+	}
+	return watchCtx.Err()
+}
+
+// isTerminalStatus returns whether the status of a blob can no longer change, except through pruning
+func isTerminalStatus(status disperser.BlobStatus) bool {
+	return status == disperser.Finalized || status == disperser.Pruned || status == disperser.Failed || status == disperser.InsufficientSignatures
+}
+
+// getBlobStatusReply builds the status reply of the blob from its metadata
+func (s *DispersalServer) getBlobStatusReply(metadata *disperser.BlobMetadata) (*pb.BlobStatusReply, error) {
 	isConfirmed, err := metadata.IsConfirmed()
 	if err != nil {
 		return nil, err
 	}
+	numRetries := uint32(metadata.NumRetries)
+	ageSeconds := getBlobAgeSeconds(metadata)
+	var clientMetadata []byte
+	if metadata.RequestMetadata != nil {
+		clientMetadata = metadata.RequestMetadata.ClientMetadata
+	}
 
 	s.logger.Debug("isConfirmed", "metadata", metadata, "isConfirmed", isConfirmed)
 	if isConfirmed {
 		confirmationInfo := metadata.ConfirmationInfo
-		dataLength := uint32(confirmationInfo.BlobCommitment.Length)
+		// The commitment of blobs pruned by earlier releases was dropped along with the inclusion proof
+		var commitment *commonpb.G1Commitment
+		var dataLength uint32
+		if confirmationInfo.BlobCommitment != nil && confirmationInfo.BlobCommitment.Commitment != nil {
+			commitment = &commonpb.G1Commitment{
+				X: confirmationInfo.BlobCommitment.Commitment.X.Marshal(),
+				Y: confirmationInfo.BlobCommitment.Commitment.Y.Marshal(),
+			}
+			dataLength = uint32(confirmationInfo.BlobCommitment.Length)
+		}
 		quorumInfos := confirmationInfo.BlobQuorumInfos
 		slices.SortStableFunc[[]*core.BlobQuorumInfo](quorumInfos, func(a, b *core.BlobQuorumInfo) int {
 			return int(a.QuorumID) - int(b.QuorumID)
@@ -475,10 +792,7 @@ func (s *DispersalServer) GetBlobStatus(ctx context.Context, req *pb.BlobStatusR
 			Status: getResponseStatus(metadata.BlobStatus),
 			Info: &pb.BlobInfo{
 				BlobHeader: &pb.BlobHeader{
-					Commitment: &commonpb.G1Commitment{
-						X: confirmationInfo.BlobCommitment.Commitment.X.Marshal(),
-						Y: confirmationInfo.BlobCommitment.Commitment.Y.Marshal(),
-					},
+					Commitment:       commitment,
 					DataLength:       dataLength,
 					BlobQuorumParams: blobQuorumParams,
 				},
@@ -502,15 +816,33 @@ func (s *DispersalServer) GetBlobStatus(ctx context.Context, req *pb.BlobStatusR
 					QuorumIndexes: quorumIndexes,
 				},
 			},
+			NumRetries:     numRetries,
+			AgeSeconds:     ageSeconds,
+			ClientMetadata: clientMetadata,
 		}, nil
 	}
 
 	return &pb.BlobStatusReply{
-		Status: getResponseStatus(metadata.BlobStatus),
-		Info:   &pb.BlobInfo{},
+		Status:         getResponseStatus(metadata.BlobStatus),
+		Info:           &pb.BlobInfo{},
+		NumRetries:     numRetries,
+		AgeSeconds:     ageSeconds,
+		ClientMetadata: clientMetadata,
 	}, nil
 }
 
+// getBlobAgeSeconds returns the number of seconds elapsed since the blob was requested
+func getBlobAgeSeconds(metadata *disperser.BlobMetadata) uint64 {
+	if metadata.RequestMetadata == nil {
+		return 0
+	}
+	age := time.Since(time.Unix(0, int64(metadata.RequestMetadata.RequestedAt)))
+	if age < 0 {
+		return 0
+	}
+	return uint64(age.Seconds())
+}
+
 func (s *DispersalServer) RetrieveBlob(ctx context.Context, req *pb.RetrieveBlobRequest) (*pb.RetrieveBlobReply, error) {
 	timer := prometheus.NewTimer(prometheus.ObserverFunc(func(f float64) {
 		s.metrics.ObserveLatency("RetrieveBlob", f*1000) // make milliseconds
@@ -549,10 +881,215 @@ func (s *DispersalServer) RetrieveBlob(ctx context.Context, req *pb.RetrieveBlob
 	}, nil
 }
 
+// GetBlobByCommitment returns a page of the confirmed blobs with the given commitment. The same data dispersed in
+// several requests produces the same commitment, in which case all of them are returned, by request time.
+func (s *DispersalServer) GetBlobByCommitment(ctx context.Context, req *pb.BlobByCommitmentRequest) (*pb.BlobByCommitmentReply, error) {
+	timer := prometheus.NewTimer(prometheus.ObserverFunc(func(f float64) {
+		s.metrics.ObserveLatency("GetBlobByCommitment", f*1000) // make milliseconds
+	}))
+	defer timer.ObserveDuration()
+
+	if req.GetCommitment() == nil || len(req.GetCommitment().GetX()) == 0 || len(req.GetCommitment().GetY()) == 0 {
+		return nil, fmt.Errorf("invalid request: commitment must not be empty")
+	}
+	commitment := &core.G1Commitment{
+		X: *new(fp.Element).SetBytes(req.GetCommitment().GetX()),
+		Y: *new(fp.Element).SetBytes(req.GetCommitment().GetY()),
+	}
+
+	limit := req.GetLimit()
+	if limit == 0 || limit > maxBlobsByCommitmentPerPage {
+		limit = maxBlobsByCommitmentPerPage
+	}
+	var exclusiveStartKey *disperser.BlobStoreExclusiveStartKey
+	if len(req.GetPaginationToken()) > 0 {
+		exclusiveStartKey = &disperser.BlobStoreExclusiveStartKey{}
+		if err := json.Unmarshal(req.GetPaginationToken(), exclusiveStartKey); err != nil {
+			return nil, fmt.Errorf("invalid request: malformed pagination_token")
+		}
+	}
+
+	metadatas, exclusiveStartKey, err := s.blobStore.GetBlobMetadataByCommitment(ctx, commitment, int32(limit), exclusiveStartKey)
+	if err != nil {
+		s.logger.Error("Failed to retrieve blob metadata by commitment", "err", err)
+		return nil, err
+	}
+
+	blobs := make([]*pb.CommittedBlob, 0, len(metadatas))
+	for _, metadata := range metadatas {
+		isConfirmed, err := metadata.IsConfirmed()
+		if err != nil {
+			return nil, err
+		}
+		if !isConfirmed {
+			continue
+		}
+		reply, err := s.getBlobStatusReply(metadata)
+		if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, &pb.CommittedBlob{
+			RequestId: []byte(metadata.GetBlobKey().String()),
+			Status:    reply.GetStatus(),
+			Info:      reply.GetInfo(),
+		})
+	}
+
+	var nextPaginationToken []byte
+	if exclusiveStartKey != nil {
+		nextPaginationToken, err = json.Marshal(exclusiveStartKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &pb.BlobByCommitmentReply{
+		Blobs:               blobs,
+		NextPaginationToken: nextPaginationToken,
+	}, nil
+}
+
+func (s *DispersalServer) GetBlobEncodingParams(ctx context.Context, req *pb.BlobEncodingParamsRequest) (*pb.BlobEncodingParamsReply, error) {
+	timer := prometheus.NewTimer(prometheus.ObserverFunc(func(f float64) {
+		s.metrics.ObserveLatency("GetBlobEncodingParams", f*1000) // make milliseconds
+	}))
+	defer timer.ObserveDuration()
+
+	requestID := req.GetRequestId()
+	if len(requestID) == 0 {
+		return nil, fmt.Errorf("invalid request: request_id must not be empty")
+	}
+
+	s.logger.Info("received a new blob encoding params request", "requestID", string(requestID))
+	metadataKey, err := disperser.ParseBlobKey(string(requestID))
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := s.blobStore.GetBlobMetadata(ctx, metadataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	isConfirmed, err := metadata.IsConfirmed()
+	if err != nil {
+		return nil, err
+	}
+	if !isConfirmed {
+		return nil, fmt.Errorf("blob %s is not confirmed", metadataKey.String())
+	}
+
+	confirmationInfo := metadata.ConfirmationInfo
+	if len(confirmationInfo.AssignmentInfos) == 0 {
+		return nil, fmt.Errorf("encoding params are not available for blob %s", metadataKey.String())
+	}
+	quorumInfos := confirmationInfo.BlobQuorumInfos
+	slices.SortStableFunc[[]*core.BlobQuorumInfo](quorumInfos, func(a, b *core.BlobQuorumInfo) int {
+		return int(a.QuorumID) - int(b.QuorumID)
+	})
+	quorumEncodingParams := make([]*pb.QuorumEncodingParams, len(quorumInfos))
+	for i, quorumInfo := range quorumInfos {
+		assignmentInfo, ok := confirmationInfo.AssignmentInfos[quorumInfo.QuorumID]
+		if !ok {
+			return nil, fmt.Errorf("assignment info for quorum %d is not available for blob %s", quorumInfo.QuorumID, metadataKey.String())
+		}
+		params, err := core.GetEncodingParams(quorumInfo.ChunkLength, uint(assignmentInfo.TotalChunks))
+		if err != nil {
+			return nil, err
+		}
+		quorumEncodingParams[i] = &pb.QuorumEncodingParams{
+			QuorumNumber: uint32(quorumInfo.QuorumID),
+			ChunkLength:  uint32(params.ChunkLength),
+			NumChunks:    uint32(params.NumChunks),
+			TotalChunks:  uint32(assignmentInfo.TotalChunks),
+		}
+	}
+
+	return &pb.BlobEncodingParamsReply{
+		QuorumEncodingParams: quorumEncodingParams,
+	}, nil
+}
+
+// EstimateConfirmation returns an approximate estimate of the time until a blob is confirmed. The estimate
+// reflects the backlog of blobs queued ahead of the blob and the recent confirmation latency. The requests of each
+// client origin are rate limited with the PerUserEstimateRate of the rate config.
+func (s *DispersalServer) EstimateConfirmation(ctx context.Context, req *pb.EstimateConfirmationRequest) (*pb.EstimateConfirmationReply, error) {
+	timer := prometheus.NewTimer(prometheus.ObserverFunc(func(f float64) {
+		s.metrics.ObserveLatency("EstimateConfirmation", f*1000) // make milliseconds
+	}))
+	defer timer.ObserveDuration()
+
+	requestID := req.GetRequestId()
+	if len(requestID) == 0 {
+		return nil, fmt.Errorf("invalid request: request_id must not be empty")
+	}
+
+	if err := s.checkEstimateRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	metadataKey, err := disperser.ParseBlobKey(string(requestID))
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := s.blobStore.GetBlobMetadata(ctx, metadataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	switch metadata.BlobStatus {
+	case disperser.Confirmed, disperser.Finalized, disperser.Pruned, disperser.BatchIDPending:
+		return &pb.EstimateConfirmationReply{}, nil
+	case disperser.Failed, disperser.InsufficientSignatures:
+		return nil, fmt.Errorf("blob %s will not be confirmed: status %s", metadataKey.String(), metadata.BlobStatus.String())
+	}
+
+	var requestedAt uint64
+	if metadata.RequestMetadata != nil {
+		requestedAt = metadata.RequestMetadata.RequestedAt
+	}
+	queuePosition, err := s.confirmationEstimator.QueuePosition(ctx, requestedAt)
+	if err != nil {
+		s.logger.Error("failed to get queued blobs", "err", err)
+		return nil, fmt.Errorf("failed to get queued blobs, please try again later")
+	}
+
+	waited := time.Since(time.Unix(0, int64(requestedAt)))
+	estimate := s.confirmationEstimator.Estimate(queuePosition, waited)
+	return &pb.EstimateConfirmationReply{
+		EstimatedSeconds: uint64(estimate.Seconds()),
+		QueuePosition:    uint32(queuePosition),
+	}, nil
+}
+
+// checkEstimateRateLimit returns ResourceExhausted if the client origin exceeded its rate of EstimateConfirmation
+// requests. The requests aren't limited if the rate limiter is disabled.
+func (s *DispersalServer) checkEstimateRateLimit(ctx context.Context) error {
+	if s.ratelimiter == nil || s.rateConfig.PerUserEstimateRate == 0 {
+		return nil
+	}
+	origin, err := common.GetClientAddress(ctx, s.rateConfig.ClientIPHeader, 2, true)
+	if err != nil {
+		return err
+	}
+	allowed, err := s.ratelimiter.AllowRequest(ctx, fmt.Sprintf("%s:%s", estimateAccountKeyPrefix, origin), blobRateMultiplier, s.rateConfig.PerUserEstimateRate)
+	if err != nil {
+		return fmt.Errorf("ratelimiter error: %v", err)
+	}
+	if !allowed {
+		return status.Error(codes.ResourceExhausted, errEstimateRateLimit.Error())
+	}
+	return nil
+}
+
 func (s *DispersalServer) Start(ctx context.Context) error {
 	s.logger.Trace("Entering Start function...")
 	defer s.logger.Trace("Exiting Start function...")
 
+	if s.config.AllowedAccounts != nil && s.config.AllowedAccountsFile != "" && s.config.AllowedAccountsReloadInterval > 0 {
+		go s.config.AllowedAccounts.WatchFile(ctx, s.config.AllowedAccountsFile, s.config.AllowedAccountsReloadInterval, s.logger)
+	}
+
 	// Serve grpc requests
 	addr := fmt.Sprintf("%s:%s", disperser.Localhost, s.config.GrpcPort)
 	listener, err := net.Listen("tcp", addr)
@@ -594,9 +1131,46 @@ func (s *DispersalServer) updateQuorumCount(ctx context.Context) error {
 	return nil
 }
 
+// findDuplicateBlob returns the metadata of a blob that was dispersed by the requester of the given blob with the same
+// content and security params and hasn't failed, or nil if there is none
+func (s *DispersalServer) findDuplicateBlob(ctx context.Context, blob *core.Blob) (*disperser.BlobMetadata, error) {
+	metadatas, err := s.blobStore.GetBlobMetadataByContentHash(ctx, disperser.GetBlobContentHash(blob.Data), blob.RequestHeader.Requester)
+	if err != nil {
+		return nil, err
+	}
+	for _, metadata := range metadatas {
+		if metadata.BlobStatus == disperser.Failed || metadata.BlobStatus == disperser.InsufficientSignatures {
+			continue
+		}
+		if metadata.RequestMetadata != nil && sameSecurityParams(metadata.RequestMetadata.SecurityParams, blob.RequestHeader.SecurityParams) {
+			return metadata, nil
+		}
+	}
+	return nil, nil
+}
+
+// sameSecurityParams returns whether the security params cover the same quorums with the same thresholds, in any order
+func sameSecurityParams(a, b []*core.SecurityParam) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	params := make(map[core.QuorumID]*core.SecurityParam, len(a))
+	for _, param := range a {
+		params[param.QuorumID] = param
+	}
+	for _, param := range b {
+		other, ok := params[param.QuorumID]
+		if !ok || other.AdversaryThreshold != param.AdversaryThreshold || other.QuorumThreshold != param.QuorumThreshold {
+			return false
+		}
+	}
+	return true
+}
+
 func getResponseStatus(status disperser.BlobStatus) pb.BlobStatus {
 	switch status {
-	case disperser.Processing:
+	case disperser.Processing, disperser.BatchIDPending:
+		// blobs pending their batch ID are reported as processing until their confirmation info is complete
 		return pb.BlobStatus_PROCESSING
 	case disperser.Confirmed:
 		return pb.BlobStatus_CONFIRMED
@@ -606,11 +1180,26 @@ func getResponseStatus(status disperser.BlobStatus) pb.BlobStatus {
 		return pb.BlobStatus_FINALIZED
 	case disperser.InsufficientSignatures:
 		return pb.BlobStatus_INSUFFICIENT_SIGNATURES
+	case disperser.Pruned:
+		return pb.BlobStatus_PRUNED
 	default:
 		return pb.BlobStatus_UNKNOWN
 	}
 }
 
+// getBlobPriority converts the requested priority to the blob priority. Unknown priorities are mapped to
+// an invalid priority so that the request is rejected.
+func getBlobPriority(priority pb.BlobPriority) core.BlobPriority {
+	switch priority {
+	case pb.BlobPriority_NORMAL:
+		return core.PriorityNormal
+	case pb.BlobPriority_HIGH:
+		return core.PriorityHigh
+	default:
+		return core.MaxBlobPriority + 1
+	}
+}
+
 func getBlobFromRequest(req *pb.DisperseBlobRequest) *core.Blob {
 	params := make([]*core.SecurityParam, len(req.SecurityParams))
 
@@ -630,6 +1219,10 @@ func getBlobFromRequest(req *pb.DisperseBlobRequest) *core.Blob {
 				AccountID: req.AccountId,
 			},
 			SecurityParams: params,
+			IdempotencyKey: req.GetIdempotencyKey(),
+			Priority:       getBlobPriority(req.GetPriority()),
+			ClientMetadata: req.GetClientMetadata(),
+			ChunkLength:    uint(req.GetChunkLength()),
 		},
 		Data: data,
 	}
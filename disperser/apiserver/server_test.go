@@ -9,9 +9,13 @@ import (
 	"testing"
 	"time"
 
+	commonpb "github.com/Layr-Labs/eigenda/api/grpc/common"
+	pbmock "github.com/Layr-Labs/eigenda/api/grpc/mock"
 	"github.com/Layr-Labs/eigenda/disperser/apiserver"
 	"github.com/Layr-Labs/eigenda/disperser/common/blobstore"
 	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/google/uuid"
 
 	pb "github.com/Layr-Labs/eigenda/api/grpc/disperser"
@@ -28,8 +32,11 @@ import (
 	"github.com/Layr-Labs/eigenda/inabox/deploy"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
 	"github.com/ory/dockertest/v3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -62,6 +69,94 @@ func TestDisperseBlob(t *testing.T) {
 	assert.NotNil(t, key)
 }
 
+func TestDisperseBlobWithIdempotencyKey(t *testing.T) {
+	data := make([]byte, 1024)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	p := &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("0.0.0.0"),
+			Port: 51001,
+		},
+	}
+	ctx := peer.NewContext(context.Background(), p)
+
+	idempotencyKey := uuid.New().String()
+	request := &pb.DisperseBlobRequest{
+		Data: data,
+		SecurityParams: []*pb.SecurityParams{
+			{
+				QuorumId:           0,
+				AdversaryThreshold: 80,
+				QuorumThreshold:    100,
+			},
+		},
+		IdempotencyKey: idempotencyKey,
+	}
+	reply1, err := dispersalServer.DisperseBlob(ctx, request)
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BlobStatus_PROCESSING, reply1.GetResult())
+
+	// retrying with the same key returns the existing blob
+	reply2, err := dispersalServer.DisperseBlob(ctx, request)
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BlobStatus_PROCESSING, reply2.GetResult())
+	assert.Equal(t, reply1.GetRequestId(), reply2.GetRequestId())
+
+	// the key is scoped to the origin of the unauthenticated request
+	scopedKey := disperser.ScopeIdempotencyKey("0.0.0.0", idempotencyKey)
+	payloadHash := disperser.GetIdempotencyPayloadHash(&core.Blob{
+		RequestHeader: core.BlobRequestHeader{
+			SecurityParams: []*core.SecurityParam{{QuorumID: 0, AdversaryThreshold: 80, QuorumThreshold: 100}},
+		},
+		Data: data,
+	})
+	metadata, err := queue.GetBlobMetadataByIdempotencyKey(ctx, scopedKey, payloadHash)
+	assert.NoError(t, err)
+	assert.Equal(t, string(reply1.GetRequestId()), metadata.GetBlobKey().String())
+
+	processing, err := queue.GetBlobMetadataByStatus(ctx, disperser.Processing)
+	assert.NoError(t, err)
+	numWithKey := 0
+	for _, m := range processing {
+		if m.RequestMetadata.IdempotencyKey == scopedKey {
+			numWithKey++
+		}
+	}
+	assert.Equal(t, 1, numWithKey)
+
+	// reusing the key with a different payload is rejected
+	otherData := make([]byte, 1024)
+	_, err = rand.Read(otherData)
+	assert.NoError(t, err)
+	otherRequest := &pb.DisperseBlobRequest{
+		Data:           otherData,
+		SecurityParams: request.SecurityParams,
+		IdempotencyKey: idempotencyKey,
+	}
+	_, err = dispersalServer.DisperseBlob(ctx, otherRequest)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.ErrorContains(t, err, disperser.ErrIdempotencyKeyMismatch.Error())
+
+	// the same key from another origin creates a new blob
+	otherOrigin := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("0.0.0.1"),
+			Port: 51001,
+		},
+	})
+	reply3, err := dispersalServer.DisperseBlob(otherOrigin, request)
+	assert.NoError(t, err)
+	assert.NotEqual(t, reply1.GetRequestId(), reply3.GetRequestId())
+
+	// a different key creates a new blob
+	request.IdempotencyKey = uuid.New().String()
+	reply4, err := dispersalServer.DisperseBlob(ctx, request)
+	assert.NoError(t, err)
+	assert.NotEqual(t, reply1.GetRequestId(), reply4.GetRequestId())
+}
+
 func TestDisperseBlobWithInvalidQuorum(t *testing.T) {
 	data := make([]byte, 1024)
 	_, err := rand.Read(data)
@@ -105,6 +200,491 @@ func TestDisperseBlobWithInvalidQuorum(t *testing.T) {
 	assert.ErrorContains(t, err, "invalid request: security_params must not contain duplicate quorum_id")
 }
 
+func TestDisperseBlobWithUnsupportedQuorum(t *testing.T) {
+	data := make([]byte, 1024)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	tx := &mock.MockTransactor{}
+	tx.On("GetCurrentBlockNumber").Return(uint32(100), nil)
+	tx.On("GetQuorumCount").Return(uint8(2), nil)
+	server := apiserver.NewDispersalServer(disperser.ServerConfig{
+		GrpcPort:         "51001",
+		AllowedQuorumIDs: []core.QuorumID{0},
+	}, queue, tx, logger, disperser.NewMetrics("9001", logger), nil, apiserver.RateConfig{})
+
+	p := &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("0.0.0.0"),
+			Port: 51001,
+		},
+	}
+	ctx := peer.NewContext(context.Background(), p)
+
+	processing, err := queue.GetBlobMetadataByStatus(ctx, disperser.Processing)
+	assert.NoError(t, err)
+
+	// quorum 1 exists onchain, but is not supported by this disperser
+	_, err = server.DisperseBlob(ctx, &pb.DisperseBlobRequest{
+		Data: data,
+		SecurityParams: []*pb.SecurityParams{
+			{
+				QuorumId:           0,
+				AdversaryThreshold: 80,
+				QuorumThreshold:    100,
+			},
+			{
+				QuorumId:           1,
+				AdversaryThreshold: 80,
+				QuorumThreshold:    100,
+			},
+		},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.ErrorContains(t, err, "quorum_id 1 is not supported")
+
+	// the request is rejected before the blob is stored for encoding
+	processingAfter, err := queue.GetBlobMetadataByStatus(ctx, disperser.Processing)
+	assert.NoError(t, err)
+	assert.Len(t, processingAfter, len(processing))
+}
+
+func TestDisperseBlobWithTooManyQuorums(t *testing.T) {
+	data := make([]byte, 1024)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	tx := &mock.MockTransactor{}
+	tx.On("GetCurrentBlockNumber").Return(uint32(100), nil)
+	tx.On("GetQuorumCount").Return(uint8(3), nil)
+	server := apiserver.NewDispersalServer(disperser.ServerConfig{
+		GrpcPort:          "51001",
+		MaxQuorumsPerBlob: 2,
+	}, queue, tx, logger, disperser.NewMetrics("9001", logger), nil, apiserver.RateConfig{})
+
+	p := &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("0.0.0.0"),
+			Port: 51001,
+		},
+	}
+	ctx := peer.NewContext(context.Background(), p)
+
+	processing, err := queue.GetBlobMetadataByStatus(ctx, disperser.Processing)
+	assert.NoError(t, err)
+
+	securityParams := make([]*pb.SecurityParams, 3)
+	for i := range securityParams {
+		securityParams[i] = &pb.SecurityParams{
+			QuorumId:           uint32(i),
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		}
+	}
+	_, err = server.DisperseBlob(ctx, &pb.DisperseBlobRequest{
+		Data:           data,
+		SecurityParams: securityParams,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.ErrorContains(t, err, "must not exceed 2 quorums")
+
+	// the request is rejected before the blob is stored for encoding
+	processingAfter, err := queue.GetBlobMetadataByStatus(ctx, disperser.Processing)
+	assert.NoError(t, err)
+	assert.Len(t, processingAfter, len(processing))
+}
+
+func TestDisperseBlobRejectedRequestsMetric(t *testing.T) {
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	tx := &mock.MockTransactor{}
+	tx.On("GetCurrentBlockNumber").Return(uint32(100), nil)
+	tx.On("GetQuorumCount").Return(uint8(2), nil)
+	metrics := disperser.NewMetrics("9001", logger)
+	server := apiserver.NewDispersalServer(disperser.ServerConfig{
+		GrpcPort:          "51001",
+		MaxQuorumsPerBlob: 2,
+	}, queue, tx, logger, metrics, nil, apiserver.RateConfig{})
+
+	p := &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("0.0.0.0"),
+			Port: 51001,
+		},
+	}
+	ctx := peer.NewContext(context.Background(), p)
+
+	quorum := func(quorumID uint32) *pb.SecurityParams {
+		return &pb.SecurityParams{
+			QuorumId:           quorumID,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		}
+	}
+	requests := []*pb.DisperseBlobRequest{
+		// empty blobs
+		{Data: []byte{}, SecurityParams: []*pb.SecurityParams{quorum(0)}},
+		{Data: nil, SecurityParams: []*pb.SecurityParams{quorum(1)}},
+		// duplicate quorum
+		{Data: []byte{1}, SecurityParams: []*pb.SecurityParams{quorum(0), quorum(0)}},
+		// too many quorums
+		{Data: []byte{1}, SecurityParams: []*pb.SecurityParams{quorum(0), quorum(1), quorum(2)}},
+		// quorum that doesn't exist onchain
+		{Data: []byte{1}, SecurityParams: []*pb.SecurityParams{quorum(5)}},
+		// oversized blob
+		{Data: make([]byte, 3*1024*1024), SecurityParams: []*pb.SecurityParams{quorum(0)}},
+	}
+	for _, req := range requests {
+		_, err := server.DisperseBlob(ctx, req)
+		assert.Error(t, err)
+	}
+
+	for reason, count := range map[string]float64{
+		disperser.RejectEmptyBlob:         2,
+		disperser.RejectDuplicateQuorum:   1,
+		disperser.RejectTooManyQuorums:    1,
+		disperser.RejectInvalidQuorum:     1,
+		disperser.RejectOversizedBlob:     1,
+		disperser.RejectUnsupportedQuorum: 0,
+	} {
+		assert.Equal(t, count, testutil.ToFloat64(metrics.RejectedRequests.WithLabelValues(reason)), reason)
+	}
+}
+
+func TestDisperseBlobRequireAuthentication(t *testing.T) {
+	data := make([]byte, 1024)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	tx := &mock.MockTransactor{}
+	tx.On("GetCurrentBlockNumber").Return(uint32(100), nil)
+	tx.On("GetQuorumCount").Return(uint8(2), nil)
+
+	p := &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("0.0.0.0"),
+			Port: 51001,
+		},
+	}
+	ctx := peer.NewContext(context.Background(), p)
+	req := &pb.DisperseBlobRequest{
+		Data: data,
+		SecurityParams: []*pb.SecurityParams{
+			{
+				QuorumId:           0,
+				AdversaryThreshold: 80,
+				QuorumThreshold:    100,
+			},
+		},
+	}
+
+	// unauthenticated dispersal is rejected when authentication is required
+	metrics := disperser.NewMetrics("9001", logger)
+	server := apiserver.NewDispersalServer(disperser.ServerConfig{
+		GrpcPort:              "51001",
+		RequireAuthentication: true,
+	}, queue, tx, logger, metrics, nil, apiserver.RateConfig{})
+	reply, err := server.DisperseBlob(ctx, req)
+	assert.Nil(t, reply)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.RejectedRequests.WithLabelValues(disperser.RejectUnauthenticated)))
+
+	// and allowed otherwise
+	metrics = disperser.NewMetrics("9001", logger)
+	server = apiserver.NewDispersalServer(disperser.ServerConfig{
+		GrpcPort: "51001",
+	}, queue, tx, logger, metrics, nil, apiserver.RateConfig{})
+	reply, err = server.DisperseBlob(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BlobStatus_PROCESSING, reply.GetResult())
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.RejectedRequests.WithLabelValues(disperser.RejectUnauthenticated)))
+}
+
+func disperseDuplicateBlobs(t *testing.T, policy disperser.DuplicateBlobPolicy, origins ...string) ([]*pb.DisperseBlobReply, []error, *disperser.Metrics) {
+	data := make([]byte, 1024)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	tx := &mock.MockTransactor{}
+	tx.On("GetCurrentBlockNumber").Return(uint32(100), nil)
+	tx.On("GetQuorumCount").Return(uint8(2), nil)
+	metrics := disperser.NewMetrics("9001", logger)
+	server := apiserver.NewDispersalServer(disperser.ServerConfig{
+		GrpcPort:            "51001",
+		DuplicateBlobPolicy: policy,
+	}, queue, tx, logger, metrics, nil, apiserver.RateConfig{})
+
+	if len(origins) == 0 {
+		origins = []string{"0.0.0.0", "0.0.0.0"}
+	}
+	replies := make([]*pb.DisperseBlobReply, len(origins))
+	errs := make([]error, len(origins))
+	for i, origin := range origins {
+		p := &peer.Peer{
+			Addr: &net.TCPAddr{
+				IP:   net.ParseIP(origin),
+				Port: 51001,
+			},
+		}
+		ctx := peer.NewContext(context.Background(), p)
+		replies[i], errs[i] = server.DisperseBlob(ctx, &pb.DisperseBlobRequest{
+			Data: data,
+			SecurityParams: []*pb.SecurityParams{
+				{
+					QuorumId:           0,
+					AdversaryThreshold: 50,
+					QuorumThreshold:    100,
+				},
+			},
+		})
+	}
+	return replies, errs, metrics
+}
+
+func TestDisperseBlobDuplicateAllow(t *testing.T) {
+	replies, errs, metrics := disperseDuplicateBlobs(t, disperser.DuplicateBlobAllow)
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.Equal(t, pb.BlobStatus_PROCESSING, replies[1].GetResult())
+	assert.NotEqual(t, replies[0].GetRequestId(), replies[1].GetRequestId())
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.DuplicateRequests.WithLabelValues(string(disperser.DuplicateBlobAllow))))
+}
+
+func TestDisperseBlobDuplicateDedup(t *testing.T) {
+	replies, errs, metrics := disperseDuplicateBlobs(t, disperser.DuplicateBlobDedup)
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.Equal(t, pb.BlobStatus_PROCESSING, replies[1].GetResult())
+	assert.Equal(t, replies[0].GetRequestId(), replies[1].GetRequestId())
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.DuplicateRequests.WithLabelValues(string(disperser.DuplicateBlobDedup))))
+}
+
+func TestDisperseBlobDuplicateReject(t *testing.T) {
+	replies, errs, metrics := disperseDuplicateBlobs(t, disperser.DuplicateBlobReject)
+	assert.NoError(t, errs[0])
+	assert.Equal(t, codes.AlreadyExists, status.Code(errs[1]))
+	assert.ErrorContains(t, errs[1], string(replies[0].GetRequestId()))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.DuplicateRequests.WithLabelValues(string(disperser.DuplicateBlobReject))))
+}
+
+func TestDisperseBlobDuplicateOtherRequester(t *testing.T) {
+	// blobs of other requesters are neither returned nor revealed
+	replies, errs, metrics := disperseDuplicateBlobs(t, disperser.DuplicateBlobReject, "0.0.0.1", "0.0.0.2")
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.NotEqual(t, replies[0].GetRequestId(), replies[1].GetRequestId())
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.DuplicateRequests.WithLabelValues(string(disperser.DuplicateBlobReject))))
+}
+
+func TestDisperseBlobReceipt(t *testing.T) {
+	data := make([]byte, 1024)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	tx := &mock.MockTransactor{}
+	tx.On("GetCurrentBlockNumber").Return(uint32(100), nil)
+	tx.On("GetQuorumCount").Return(uint8(2), nil)
+	privateKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	signer, err := disperser.NewReceiptSigner(hexutil.Encode(crypto.FromECDSA(privateKey)))
+	assert.NoError(t, err)
+	server := apiserver.NewDispersalServer(disperser.ServerConfig{
+		GrpcPort:            "51001",
+		DuplicateBlobPolicy: disperser.DuplicateBlobDedup,
+		ReceiptSigner:       signer,
+	}, queue, tx, logger, disperser.NewMetrics("9001", logger), nil, apiserver.RateConfig{})
+
+	p := &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("0.0.0.0"),
+			Port: 51001,
+		},
+	}
+	ctx := peer.NewContext(context.Background(), p)
+	req := &pb.DisperseBlobRequest{
+		Data: data,
+		SecurityParams: []*pb.SecurityParams{
+			{
+				QuorumId:           0,
+				AdversaryThreshold: 50,
+				QuorumThreshold:    100,
+			},
+		},
+	}
+
+	// the receipt of the dispersed blob is signed by the disperser
+	reply, err := server.DisperseBlob(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, reply.GetRequestId(), reply.GetReceipt().GetRequestId())
+	receipt, err := disperser.BlobReceiptFromProtobuf(reply.GetReceipt())
+	assert.NoError(t, err)
+	assert.NoError(t, disperser.VerifyBlobReceipt(receipt, signer.PublicKey()))
+	metadata, err := queue.GetBlobMetadata(ctx, receipt.BlobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, metadata.RequestMetadata.RequestedAt, receipt.RequestedAt)
+
+	// a receipt altered by the client doesn't verify
+	altered := *receipt
+	altered.RequestedAt++
+	assert.Error(t, disperser.VerifyBlobReceipt(&altered, signer.PublicKey()))
+	otherKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	assert.Error(t, disperser.VerifyBlobReceipt(receipt, hexutil.Encode(crypto.FromECDSAPub(&otherKey.PublicKey))))
+
+	// a duplicate answered with the existing blob gets the receipt of the existing blob
+	reply, err = server.DisperseBlob(ctx, req)
+	assert.NoError(t, err)
+	duplicate, err := disperser.BlobReceiptFromProtobuf(reply.GetReceipt())
+	assert.NoError(t, err)
+	assert.Equal(t, receipt.BlobKey, duplicate.BlobKey)
+	assert.Equal(t, receipt.RequestedAt, duplicate.RequestedAt)
+	assert.NoError(t, disperser.VerifyBlobReceipt(duplicate, signer.PublicKey()))
+}
+
+func TestDisperseBlobWithPriority(t *testing.T) {
+	data := make([]byte, 1024)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	securityParams := []*pb.SecurityParams{
+		{
+			QuorumId:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+	}
+
+	// unauthenticated clients that aren't allowlisted can't request the high priority lane
+	p := &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("0.0.0.0"),
+			Port: 51001,
+		},
+	}
+	_, err = dispersalServer.DisperseBlob(peer.NewContext(context.Background(), p), &pb.DisperseBlobRequest{
+		Data:           data,
+		SecurityParams: securityParams,
+		Priority:       pb.BlobPriority_HIGH,
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// allowlisted clients can
+	p = &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("1.2.3.4"),
+			Port: 51001,
+		},
+	}
+	ctx := peer.NewContext(context.Background(), p)
+	reply, err := dispersalServer.DisperseBlob(ctx, &pb.DisperseBlobRequest{
+		Data:           data,
+		SecurityParams: securityParams,
+		Priority:       pb.BlobPriority_HIGH,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BlobStatus_PROCESSING, reply.GetResult())
+
+	blobKey, err := disperser.ParseBlobKey(string(reply.GetRequestId()))
+	assert.NoError(t, err)
+	metadata, err := queue.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, core.PriorityHigh, metadata.RequestMetadata.Priority)
+
+	_, err = dispersalServer.DisperseBlob(ctx, &pb.DisperseBlobRequest{
+		Data:           data,
+		SecurityParams: securityParams,
+		Priority:       pb.BlobPriority(7),
+	})
+	assert.ErrorContains(t, err, "invalid request: unknown priority")
+}
+
+func TestDisperseBlobWithClientMetadata(t *testing.T) {
+	data := make([]byte, 1024)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	// use a separate client address so that the dispersals don't count towards the rate limits of the other tests
+	p := &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("0.0.0.2"),
+			Port: 51001,
+		},
+	}
+	ctx := peer.NewContext(context.Background(), p)
+
+	securityParams := []*pb.SecurityParams{
+		{
+			QuorumId:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+	}
+	clientMetadata := []byte("rollup block 12345")
+	reply, err := dispersalServer.DisperseBlob(ctx, &pb.DisperseBlobRequest{
+		Data:           data,
+		SecurityParams: securityParams,
+		ClientMetadata: clientMetadata,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BlobStatus_PROCESSING, reply.GetResult())
+	requestID := reply.GetRequestId()
+
+	statusReply, err := dispersalServer.GetBlobStatus(ctx, &pb.BlobStatusRequest{RequestId: requestID})
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BlobStatus_PROCESSING, statusReply.GetStatus())
+	assert.Equal(t, clientMetadata, statusReply.GetClientMetadata())
+
+	// the client metadata is still returned once the blob is confirmed
+	blobKey, err := disperser.ParseBlobKey(string(requestID))
+	assert.NoError(t, err)
+	metadata, err := queue.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	commitment := &core.G1Commitment{}
+	_, err = commitment.X.SetString("21661178944771197726808973281966770251114553549453983978976194544185382599016")
+	assert.NoError(t, err)
+	_, err = commitment.Y.SetString("9207254729396071334325696286939045899948985698134704137261649190717970615186")
+	assert.NoError(t, err)
+	_, err = queue.MarkBlobConfirmed(ctx, metadata, &disperser.ConfirmationInfo{
+		BatchHeaderHash: [32]byte{1, 2, 3},
+		BlobCommitment: &core.BlobCommitments{
+			Commitment: commitment,
+			Length:     32,
+		},
+		BatchID:       1,
+		QuorumResults: map[core.QuorumID]*core.QuorumResult{0: {QuorumID: 0, PercentSigned: 100}},
+		BlobQuorumInfos: []*core.BlobQuorumInfo{{
+			SecurityParam: core.SecurityParam{QuorumID: 0, AdversaryThreshold: 80, QuorumThreshold: 100},
+			ChunkLength:   10,
+		}},
+	})
+	assert.NoError(t, err)
+
+	statusReply, err = dispersalServer.GetBlobStatus(ctx, &pb.BlobStatusRequest{RequestId: requestID})
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BlobStatus_CONFIRMED, statusReply.GetStatus())
+	assert.Equal(t, clientMetadata, statusReply.GetClientMetadata())
+
+	// the client metadata doesn't affect the blob header
+	assert.Equal(t, uint32(32), statusReply.GetInfo().GetBlobHeader().GetDataLength())
+
+	_, err = dispersalServer.DisperseBlob(ctx, &pb.DisperseBlobRequest{
+		Data:           data,
+		SecurityParams: securityParams,
+		ClientMetadata: make([]byte, 1025),
+	})
+	assert.ErrorContains(t, err, "invalid request: client_metadata must not exceed 1024 bytes")
+}
+
 func TestGetBlobStatus(t *testing.T) {
 	data := make([]byte, 1024)
 	_, err := rand.Read(data)
@@ -181,6 +761,320 @@ func TestGetBlobStatus(t *testing.T) {
 	assert.Equal(t, reply.GetInfo().GetBlobVerificationProof().GetQuorumIndexes(), quorumIndexes)
 }
 
+func TestGetBlobStatusWithRetries(t *testing.T) {
+	data := make([]byte, 1024)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	status, _, requestID := disperseBlob(t, dispersalServer, data)
+	assert.Equal(t, status, pb.BlobStatus_PROCESSING)
+
+	reply, err := dispersalServer.GetBlobStatus(context.Background(), &pb.BlobStatusRequest{
+		RequestId: requestID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0), reply.GetNumRetries())
+
+	// simulate a failed processing attempt
+	ctx := context.Background()
+	metadataKey, err := disperser.ParseBlobKey(string(requestID))
+	assert.NoError(t, err)
+	metadata, err := queue.GetBlobMetadata(ctx, metadataKey)
+	assert.NoError(t, err)
+	retried, err := queue.HandleBlobFailure(ctx, metadata, 2)
+	assert.NoError(t, err)
+	assert.True(t, retried)
+
+	reply, err = dispersalServer.GetBlobStatus(ctx, &pb.BlobStatusRequest{
+		RequestId: requestID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BlobStatus_PROCESSING, reply.GetStatus())
+	assert.Greater(t, reply.GetNumRetries(), uint32(0))
+	requestedAt := time.Unix(0, int64(metadata.RequestMetadata.RequestedAt))
+	assert.LessOrEqual(t, reply.GetAgeSeconds(), uint64(time.Since(requestedAt).Seconds()))
+}
+
+func TestWatchBlobStatus(t *testing.T) {
+	data := make([]byte, 1024)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	_, blobSize, requestID := disperseBlob(t, dispersalServer, data)
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("0.0.0.0"),
+			Port: 51001,
+		},
+	})
+	stream := pbmock.MakeStatusStreamMock(ctx)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- dispersalServer.WatchBlobStatus(&pb.BlobStatusRequest{RequestId: requestID}, stream)
+		stream.Close()
+	}()
+
+	// the current status is sent first
+	reply, err := stream.RecvToClient()
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BlobStatus_PROCESSING, reply.GetStatus())
+
+	securityParams := []*core.SecurityParam{
+		{
+			QuorumID:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+	}
+	confirmedMetadata := simulateBlobConfirmation(t, requestID, blobSize, securityParams, 0)
+	reply, err = stream.RecvToClient()
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BlobStatus_CONFIRMED, reply.GetStatus())
+	assert.Equal(t, confirmedMetadata.ConfirmationInfo.BlobCommitment.Commitment.X.Marshal(), reply.GetInfo().GetBlobHeader().GetCommitment().X)
+
+	err = queue.MarkBlobFinalized(ctx, confirmedMetadata.GetBlobKey())
+	assert.NoError(t, err)
+	reply, err = stream.RecvToClient()
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BlobStatus_FINALIZED, reply.GetStatus())
+
+	// the stream is closed after the terminal status
+	assert.NoError(t, <-errChan)
+	_, err = stream.RecvToClient()
+	assert.Error(t, err)
+}
+
+func TestWatchBlobStatusLimits(t *testing.T) {
+	data := make([]byte, 1024)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	tx := &mock.MockTransactor{}
+	tx.On("GetCurrentBlockNumber").Return(uint32(100), nil)
+	tx.On("GetQuorumCount").Return(uint8(2), nil)
+	server := apiserver.NewDispersalServer(disperser.ServerConfig{
+		GrpcPort:                 "51001",
+		MaxWatchStreamsPerClient: 1,
+		MaxWatchDuration:         500 * time.Millisecond,
+	}, queue, tx, logger, disperser.NewMetrics("9001", logger), nil, apiserver.RateConfig{})
+	_, _, requestID := disperseBlob(t, server, data)
+	peerContext := func(ip string) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{
+			Addr: &net.TCPAddr{
+				IP:   net.ParseIP(ip),
+				Port: 51001,
+			},
+		})
+	}
+
+	stream := pbmock.MakeStatusStreamMock(peerContext("0.0.0.1"))
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.WatchBlobStatus(&pb.BlobStatusRequest{RequestId: requestID}, stream)
+		stream.Close()
+	}()
+	reply, err := stream.RecvToClient()
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BlobStatus_PROCESSING, reply.GetStatus())
+
+	// the client can't open more streams than allowed while the first one is open, unlike other clients
+	err = server.WatchBlobStatus(&pb.BlobStatusRequest{RequestId: requestID}, pbmock.MakeStatusStreamMock(peerContext("0.0.0.1")))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	otherStream := pbmock.MakeStatusStreamMock(peerContext("0.0.0.2"))
+	otherErrChan := make(chan error, 1)
+	go func() {
+		otherErrChan <- server.WatchBlobStatus(&pb.BlobStatusRequest{RequestId: requestID}, otherStream)
+		otherStream.Close()
+	}()
+	reply, err = otherStream.RecvToClient()
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BlobStatus_PROCESSING, reply.GetStatus())
+
+	// the streams are closed once they reach the maximum duration, which releases them
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(<-errChan))
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(<-otherErrChan))
+	stream = pbmock.MakeStatusStreamMock(peerContext("0.0.0.1"))
+	go func() {
+		errChan <- server.WatchBlobStatus(&pb.BlobStatusRequest{RequestId: requestID}, stream)
+		stream.Close()
+	}()
+	_, err = stream.RecvToClient()
+	assert.NoError(t, err)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(<-errChan))
+}
+
+func TestGetBlobStatusPruned(t *testing.T) {
+	data := make([]byte, 1024)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	_, blobSize, requestID := disperseBlob(t, dispersalServer, data)
+	securityParams := []*core.SecurityParam{
+		{
+			QuorumID:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+	}
+	confirmedMetadata := simulateBlobConfirmation(t, requestID, blobSize, securityParams, 0)
+	ctx := context.Background()
+	err = queue.MarkBlobFinalized(ctx, confirmedMetadata.GetBlobKey())
+	assert.NoError(t, err)
+	finalizedMetadata, err := queue.GetBlobMetadata(ctx, confirmedMetadata.GetBlobKey())
+	assert.NoError(t, err)
+	err = queue.PruneConfirmationInfo(ctx, finalizedMetadata)
+	assert.NoError(t, err)
+
+	// the pruned blob still references its batch onchain, but can no longer be verified
+	reply, err := dispersalServer.GetBlobStatus(ctx, &pb.BlobStatusRequest{
+		RequestId: requestID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BlobStatus_PRUNED, reply.GetStatus())
+	assert.Equal(t, confirmedMetadata.ConfirmationInfo.BatchID, reply.GetInfo().GetBlobVerificationProof().GetBatchId())
+	assert.Equal(t, confirmedMetadata.ConfirmationInfo.BatchHeaderHash[:], reply.GetInfo().GetBlobVerificationProof().GetBatchMetadata().GetBatchHeaderHash())
+	assert.Empty(t, reply.GetInfo().GetBlobVerificationProof().GetInclusionProof())
+	assert.Equal(t, confirmedMetadata.ConfirmationInfo.BlobCommitment.Commitment.X.Marshal(), reply.GetInfo().GetBlobHeader().GetCommitment().X)
+
+	// the pruned blob can still be looked up by its commitment
+	requestIDs, _ := getBlobsByCommitment(t, reply.GetInfo().GetBlobHeader().GetCommitment(), 0)
+	assert.Contains(t, requestIDs, string(requestID))
+}
+
+func TestGetBlobByCommitment(t *testing.T) {
+	data := make([]byte, 1024)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	securityParams := []*core.SecurityParam{
+		{
+			QuorumID:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+		{
+			QuorumID:           1,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+	}
+
+	// disperse the same data twice
+	_, blobSize, requestID1 := disperseBlob(t, dispersalServer, data)
+	_, _, requestID2 := disperseBlob(t, dispersalServer, data)
+	assert.NotEqual(t, requestID1, requestID2)
+
+	confirmed1 := simulateBlobConfirmation(t, requestID1, blobSize, securityParams, 0)
+	confirmed2 := simulateBlobConfirmation(t, requestID2, blobSize, securityParams, 1)
+	commitment := &commonpb.G1Commitment{
+		X: confirmed1.ConfirmationInfo.BlobCommitment.Commitment.X.Marshal(),
+		Y: confirmed1.ConfirmationInfo.BlobCommitment.Commitment.Y.Marshal(),
+	}
+
+	// both blobs are found, across several pages
+	requestIDs, numPages := getBlobsByCommitment(t, commitment, 1)
+	assert.Contains(t, requestIDs, confirmed1.GetBlobKey().String())
+	assert.Contains(t, requestIDs, confirmed2.GetBlobKey().String())
+	assert.GreaterOrEqual(t, numPages, 2)
+
+	reply, err := dispersalServer.GetBlobByCommitment(context.Background(), &pb.BlobByCommitmentRequest{
+		Commitment: commitment,
+	})
+	assert.NoError(t, err)
+	for _, blob := range reply.GetBlobs() {
+		if string(blob.GetRequestId()) == confirmed1.GetBlobKey().String() {
+			assert.Equal(t, pb.BlobStatus_CONFIRMED, blob.GetStatus())
+			assert.Equal(t, confirmed1.ConfirmationInfo.BatchHeaderHash[:], blob.GetInfo().GetBlobVerificationProof().GetBatchMetadata().GetBatchHeaderHash())
+		}
+		assert.Equal(t, commitment.GetX(), blob.GetInfo().GetBlobHeader().GetCommitment().GetX())
+		assert.Equal(t, commitment.GetY(), blob.GetInfo().GetBlobHeader().GetCommitment().GetY())
+	}
+
+	_, err = dispersalServer.GetBlobByCommitment(context.Background(), &pb.BlobByCommitmentRequest{})
+	assert.ErrorContains(t, err, "commitment must not be empty")
+
+	_, err = dispersalServer.GetBlobByCommitment(context.Background(), &pb.BlobByCommitmentRequest{
+		Commitment:      commitment,
+		PaginationToken: []byte("invalid"),
+	})
+	assert.ErrorContains(t, err, "malformed pagination_token")
+}
+
+// getBlobsByCommitment returns the request IDs of all the blobs with the given commitment, fetched in pages of the given
+// limit, along with the number of pages
+func getBlobsByCommitment(t *testing.T, commitment *commonpb.G1Commitment, limit uint32) ([]string, int) {
+	requestIDs := make([]string, 0)
+	var paginationToken []byte
+	numPages := 0
+	for {
+		reply, err := dispersalServer.GetBlobByCommitment(context.Background(), &pb.BlobByCommitmentRequest{
+			Commitment:      commitment,
+			Limit:           limit,
+			PaginationToken: paginationToken,
+		})
+		assert.NoError(t, err)
+		numPages++
+		if limit > 0 {
+			assert.LessOrEqual(t, len(reply.GetBlobs()), int(limit))
+		}
+		for _, blob := range reply.GetBlobs() {
+			requestIDs = append(requestIDs, string(blob.GetRequestId()))
+		}
+		paginationToken = reply.GetNextPaginationToken()
+		if len(paginationToken) == 0 {
+			return requestIDs, numPages
+		}
+	}
+}
+
+func TestGetBlobEncodingParams(t *testing.T) {
+	data := make([]byte, 1024)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	status, blobSize, requestID := disperseBlob(t, dispersalServer, data)
+	assert.Equal(t, status, pb.BlobStatus_PROCESSING)
+
+	// encoding params are only available once the blob is confirmed
+	_, err = dispersalServer.GetBlobEncodingParams(context.Background(), &pb.BlobEncodingParamsRequest{
+		RequestId: requestID,
+	})
+	assert.Error(t, err)
+
+	securityParams := []*core.SecurityParam{
+		{
+			QuorumID:           1,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+		{
+			QuorumID:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+	}
+	confirmedMetadata := simulateBlobConfirmation(t, requestID, blobSize, securityParams, 0)
+
+	reply, err := dispersalServer.GetBlobEncodingParams(context.Background(), &pb.BlobEncodingParamsRequest{
+		RequestId: requestID,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, reply.GetQuorumEncodingParams(), len(securityParams))
+	for i, quorumParams := range reply.GetQuorumEncodingParams() {
+		assert.Equal(t, uint32(i), quorumParams.GetQuorumNumber())
+		assignmentInfo := confirmedMetadata.ConfirmationInfo.AssignmentInfos[core.QuorumID(i)]
+		// the chunk length and number of chunks are rounded up to powers of 2 at dispersal
+		expected, err := core.GetEncodingParams(10, uint(assignmentInfo.TotalChunks))
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(expected.ChunkLength), quorumParams.GetChunkLength())
+		assert.Equal(t, uint32(expected.NumChunks), quorumParams.GetNumChunks())
+		assert.Equal(t, uint32(assignmentInfo.TotalChunks), quorumParams.GetTotalChunks())
+	}
+}
+
 func TestRetrieveBlob(t *testing.T) {
 	// Create random data
 	data := make([]byte, 1024)
@@ -248,6 +1142,85 @@ func TestRetrieveBlobFailsWhenBlobNotConfirmed(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestEstimateConfirmation(t *testing.T) {
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	tx := &mock.MockTransactor{}
+	server := apiserver.NewDispersalServer(disperser.ServerConfig{
+		GrpcPort:                   "51001",
+		BatchInterval:              time.Minute,
+		BlobsPerBatch:              2,
+		DefaultConfirmationLatency: 10 * time.Minute,
+		QueueRefreshInterval:       time.Nanosecond,
+	}, queue, tx, logger, disperser.NewMetrics("9001", logger), nil, apiserver.RateConfig{})
+
+	// seed a backlog of blobs requested after all the blobs of the other tests
+	ctx := context.Background()
+	requestedAt := uint64(time.Now().Add(time.Hour).UnixNano())
+	numBlobs := 6
+	requestIDs := make([][]byte, numBlobs)
+	for i := 0; i < numBlobs; i++ {
+		data := make([]byte, 1024)
+		_, err := rand.Read(data)
+		assert.NoError(t, err)
+		key, err := queue.StoreBlob(ctx, &core.Blob{
+			RequestHeader: core.BlobRequestHeader{
+				SecurityParams: []*core.SecurityParam{{
+					QuorumID:           0,
+					AdversaryThreshold: 80,
+					QuorumThreshold:    100,
+				}},
+			},
+			Data: data,
+		}, requestedAt+uint64(i))
+		assert.NoError(t, err)
+		requestIDs[i] = []byte(key.String())
+	}
+
+	estimates := make([]uint64, numBlobs)
+	positions := make([]uint32, numBlobs)
+	for i, requestID := range requestIDs {
+		reply, err := server.EstimateConfirmation(ctx, &pb.EstimateConfirmationRequest{RequestId: requestID})
+		assert.NoError(t, err)
+		estimates[i] = reply.GetEstimatedSeconds()
+		positions[i] = reply.GetQueuePosition()
+	}
+
+	for i := 1; i < numBlobs; i++ {
+		// each blob is one position further back in the queue
+		assert.Equal(t, positions[i-1]+1, positions[i])
+		assert.GreaterOrEqual(t, estimates[i], estimates[i-1])
+	}
+	// every 2 blobs queued ahead add one batch interval to the estimate
+	assert.InDelta(t, float64(estimates[0]+2*60), float64(estimates[4]), 1)
+	assert.InDelta(t, float64(estimates[1]+2*60), float64(estimates[5]), 1)
+	// the blob at the back of the queue waits for at least the default confirmation latency
+	assert.GreaterOrEqual(t, estimates[numBlobs-1], uint64((10 * time.Minute).Seconds()))
+
+	// the confirmation latency is sampled from the blobs confirmed since the queue was last read, up to the time the
+	// batcher processed their confirmation
+	key, err := disperser.ParseBlobKey(string(requestIDs[0]))
+	assert.NoError(t, err)
+	metadata, err := queue.GetBlobMetadata(ctx, key)
+	assert.NoError(t, err)
+	metadata, err = queue.MarkBlobConfirmed(ctx, metadata, &disperser.ConfirmationInfo{
+		BatchID:     99,
+		ConfirmedAt: requestedAt + uint64(2*time.Minute),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Confirmed, metadata.BlobStatus)
+	reply, err := server.EstimateConfirmation(ctx, &pb.EstimateConfirmationRequest{RequestId: requestIDs[1]})
+	assert.NoError(t, err)
+	assert.Equal(t, positions[1]-1, reply.GetQueuePosition())
+	assert.InDelta(t, float64(2*60+(positions[1]-2)/2*60), float64(reply.GetEstimatedSeconds()), 1)
+
+	// a confirmed blob is not waiting for confirmation
+	reply, err = server.EstimateConfirmation(ctx, &pb.EstimateConfirmationRequest{RequestId: requestIDs[0]})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), reply.GetEstimatedSeconds())
+	assert.Equal(t, uint32(0), reply.GetQueuePosition())
+}
+
 func TestDisperseBlobWithExceedSizeLimit(t *testing.T) {
 	data := make([]byte, 2*1024*1024+10)
 	_, err := rand.Read(data)
@@ -384,6 +1357,7 @@ func newTestServer(m *testing.M) *apiserver.DispersalServer {
 				},
 			},
 		},
+		PerUserEstimateRate: 1e6,
 	}
 
 	queue = blobstore.NewSharedStorage(bucketName, s3Client, blobMetadataStore, logger)
@@ -478,6 +1452,7 @@ func simulateBlobConfirmation(t *testing.T, requestID []byte, blobSize uint, sec
 	inclusionProof := []byte{1, 2, 3, 4, 5}
 	quorumResults := make(map[core.QuorumID]*core.QuorumResult, len(securityParams))
 	quorumInfos := make([]*core.BlobQuorumInfo, len(securityParams))
+	assignmentInfos := make(map[core.QuorumID]core.AssignmentInfo, len(securityParams))
 	for i, sp := range securityParams {
 		quorumResults[sp.QuorumID] = &core.QuorumResult{
 			QuorumID:      sp.QuorumID,
@@ -487,6 +1462,9 @@ func simulateBlobConfirmation(t *testing.T, requestID []byte, blobSize uint, sec
 			SecurityParam: *sp,
 			ChunkLength:   10,
 		}
+		assignmentInfos[sp.QuorumID] = core.AssignmentInfo{
+			TotalChunks: 12,
+		}
 	}
 
 	confirmationInfo := &disperser.ConfirmationInfo{
@@ -506,6 +1484,7 @@ func simulateBlobConfirmation(t *testing.T, requestID []byte, blobSize uint, sec
 		Fee:                     fee,
 		QuorumResults:           quorumResults,
 		BlobQuorumInfos:         quorumInfos,
+		AssignmentInfos:         assignmentInfos,
 	}
 	metadata := &disperser.BlobMetadata{
 		BlobHash:     metadataKey.BlobHash,
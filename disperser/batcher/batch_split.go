@@ -0,0 +1,217 @@
+package batcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/Layr-Labs/eigenda/disperser"
+)
+
+// estimateConfirmBatchCalldataSize returns the size in bytes of the ABI encoded confirmBatch call for the given quorums
+// and non-signers. The call encodes a fixed number of words, plus words for each quorum, each non-signer and each quorum
+// membership of the non-signers.
+func estimateConfirmBatchCalldataSize(state *core.IndexedOperatorState, quorumIDs []core.QuorumID, nonSigners []core.OperatorID) uint {
+	const (
+		selectorSize = 4
+		wordSize     = 32
+		// offsets of the two arguments, the static fields of the batch header and of the non-signer stakes and signature,
+		// and the length words of the dynamic fields
+		fixedWords = 26
+	)
+
+	numMemberships := 0
+	for _, id := range nonSigners {
+		numMemberships += numQuorumMemberships(state, quorumIDs, id)
+	}
+
+	numQuorums := len(quorumIDs)
+	// quorum numbers and threshold percentages are one byte per quorum, padded to words
+	quorumBytesWords := (numQuorums + wordSize - 1) / wordSize
+	// each non-signer has a bitmap index and a G1 public key, and each quorum has a G1 aggregate public key,
+	// an apk index, a total stake index and the offset and length of its non-signer stake indices
+	numWords := fixedWords + 2*quorumBytesWords + 3*len(nonSigners) + 6*numQuorums + numMemberships
+	return uint(selectorSize + wordSize*numWords)
+}
+
+// maxConfirmBatchCalldataSize returns the largest confirmBatch calldata size of a batch over the given quorums, which
+// is known before the batch is dispersed. It assumes the maxNonSigners operators with the most quorum memberships don't
+// sign, or all the operators of the quorums if maxNonSigners is 0.
+func maxConfirmBatchCalldataSize(state *core.IndexedOperatorState, quorumIDs []core.QuorumID, maxNonSigners uint) uint {
+	operators := make(map[core.OperatorID]struct{})
+	for _, quorumID := range quorumIDs {
+		for id := range state.Operators[quorumID] {
+			operators[id] = struct{}{}
+		}
+	}
+	nonSigners := make([]core.OperatorID, 0, len(operators))
+	for id := range operators {
+		nonSigners = append(nonSigners, id)
+	}
+	if maxNonSigners > 0 && uint(len(nonSigners)) > maxNonSigners {
+		sort.Slice(nonSigners, func(i, j int) bool {
+			return numQuorumMemberships(state, quorumIDs, nonSigners[i]) > numQuorumMemberships(state, quorumIDs, nonSigners[j])
+		})
+		nonSigners = nonSigners[:maxNonSigners]
+	}
+	return estimateConfirmBatchCalldataSize(state, quorumIDs, nonSigners)
+}
+
+// numQuorumMemberships returns the number of the given quorums the operator is a member of
+func numQuorumMemberships(state *core.IndexedOperatorState, quorumIDs []core.QuorumID, id core.OperatorID) int {
+	numMemberships := 0
+	for _, quorumID := range quorumIDs {
+		if _, ok := state.Operators[quorumID][id]; ok {
+			numMemberships++
+		}
+	}
+	return numMemberships
+}
+
+// splitBatch splits the blobs of a batch before it is dispersed, so that the confirmBatch calldata of each sub-batch
+// stays within MaxConfirmBatchCalldata. The calldata grows with the quorums of a batch and their non-signers, not with
+// its blobs, so blobs with the same quorums are never split apart. Groups of blobs with the same quorums are packed into
+// sub-batches, each with its own batch header, merkle tree and the operator state of its quorums. A group exceeding the
+// limit on its own is dispatched as a sub-batch of its own.
+// The batch is returned as is if it doesn't exceed the limit or if all its blobs have the same quorums.
+func (b *Batcher) splitBatch(parent *batch) ([]*batch, error) {
+	if b.MaxConfirmBatchCalldata == 0 || b.confirmBatchCalldataSize(parent.State, parent.BlobHeaders) <= b.MaxConfirmBatchCalldata {
+		return []*batch{parent}, nil
+	}
+
+	groups := make(map[string][]int)
+	keys := make([]string, 0)
+	for i, header := range parent.BlobHeaders {
+		key := blobQuorumsKey(header)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+	if len(keys) < 2 {
+		return []*batch{parent}, nil
+	}
+	sort.Strings(keys)
+
+	// pack the groups in order of their quorums, so that groups sharing quorums tend to end up in the same sub-batch
+	packed := make([][]int, 0)
+	var current []int
+	for _, key := range keys {
+		candidate := append(append([]int{}, current...), groups[key]...)
+		headers := make([]*core.BlobHeader, len(candidate))
+		for i, ind := range candidate {
+			headers[i] = parent.BlobHeaders[ind]
+		}
+		if len(current) > 0 && b.confirmBatchCalldataSize(parent.State, headers) > b.MaxConfirmBatchCalldata {
+			packed = append(packed, current)
+			candidate = groups[key]
+		}
+		current = candidate
+	}
+	packed = append(packed, current)
+
+	subBatches := make([]*batch, 0, len(packed))
+	for _, indices := range packed {
+		subBatch, err := b.newSubBatch(parent, indices)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sub-batch: %w", err)
+		}
+		subBatches = append(subBatches, subBatch)
+	}
+	return subBatches, nil
+}
+
+// confirmBatchCalldataSize returns the largest confirmBatch calldata size of a batch of blobs with the given headers
+func (b *Batcher) confirmBatchCalldataSize(state *core.IndexedOperatorState, headers []*core.BlobHeader) uint {
+	quorums := make(map[core.QuorumID]struct{})
+	for _, header := range headers {
+		for _, quorum := range header.QuorumInfos {
+			quorums[quorum.QuorumID] = struct{}{}
+		}
+	}
+	quorumIDs := make([]core.QuorumID, 0, len(quorums))
+	for quorumID := range quorums {
+		quorumIDs = append(quorumIDs, quorumID)
+	}
+	return maxConfirmBatchCalldataSize(state, quorumIDs, b.MaxNonSigners)
+}
+
+// newSubBatch creates a batch of the blobs of the parent batch at the given indices, pinned to the same reference block
+func (b *Batcher) newSubBatch(parent *batch, indices []int) (*batch, error) {
+	encodedBlobs := make([]core.EncodedBlob, len(indices))
+	blobHeaders := make([]*core.BlobHeader, len(indices))
+	metadatas := make([]*disperser.BlobMetadata, len(indices))
+	quorums := make(map[core.QuorumID]struct{})
+	for i, ind := range indices {
+		encodedBlobs[i] = parent.EncodedBlobs[ind]
+		blobHeaders[i] = parent.BlobHeaders[ind]
+		metadatas[i] = parent.BlobMetadata[ind]
+		for _, quorum := range parent.BlobHeaders[ind].QuorumInfos {
+			quorums[quorum.QuorumID] = struct{}{}
+		}
+	}
+
+	batchHeader := &core.BatchHeader{
+		ReferenceBlockNumber: parent.BatchHeader.ReferenceBlockNumber,
+		BatchRoot:            [32]byte{},
+		SigningScheme:        parent.BatchHeader.SigningScheme,
+	}
+	tree, err := batchHeader.SetBatchRoot(blobHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	return &batch{
+		EncodedBlobs: encodedBlobs,
+		BlobMetadata: metadatas,
+		BlobHeaders:  blobHeaders,
+		BatchHeader:  batchHeader,
+		State:        stateForQuorums(parent.State, quorums),
+		MerkleTree:   tree,
+	}, nil
+}
+
+// stateForQuorums returns the operator state restricted to the given quorums and their operators
+func stateForQuorums(state *core.IndexedOperatorState, quorums map[core.QuorumID]struct{}) *core.IndexedOperatorState {
+	operatorState := &core.OperatorState{
+		Operators:   make(map[core.QuorumID]map[core.OperatorID]*core.OperatorInfo, len(quorums)),
+		Totals:      make(map[core.QuorumID]*core.OperatorInfo, len(quorums)),
+		BlockNumber: state.BlockNumber,
+	}
+	indexedOperators := make(map[core.OperatorID]*core.IndexedOperatorInfo)
+	aggKeys := make(map[core.QuorumID]*core.G1Point, len(quorums))
+	for quorumID := range quorums {
+		operators, ok := state.Operators[quorumID]
+		if !ok {
+			continue
+		}
+		operatorState.Operators[quorumID] = operators
+		operatorState.Totals[quorumID] = state.Totals[quorumID]
+		aggKeys[quorumID] = state.AggKeys[quorumID]
+		for opID := range operators {
+			if op, ok := state.IndexedOperators[opID]; ok {
+				indexedOperators[opID] = op
+			}
+		}
+	}
+	return &core.IndexedOperatorState{
+		OperatorState:    operatorState,
+		IndexedOperators: indexedOperators,
+		AggKeys:          aggKeys,
+	}
+}
+
+// blobQuorumsKey returns a key identifying the set of quorums of a blob
+func blobQuorumsKey(header *core.BlobHeader) string {
+	quorumIDs := make([]int, len(header.QuorumInfos))
+	for i, quorum := range header.QuorumInfos {
+		quorumIDs[i] = int(quorum.QuorumID)
+	}
+	sort.Ints(quorumIDs)
+	var key strings.Builder
+	for _, id := range quorumIDs {
+		key.WriteByte(byte(id))
+	}
+	return key.String()
+}
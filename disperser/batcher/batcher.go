@@ -7,24 +7,44 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/Layr-Labs/eigenda/common"
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/disperser"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	gcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/gammazero/workerpool"
 	"github.com/hashicorp/go-multierror"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/wealdtech/go-merkletree"
 )
 
 const (
 	QuantizationFactor = uint(1)
 	indexerWarmupDelay = 2 * time.Second
+
+	defaultMaxReceiptParseRetries = 3
+	// maxReceiptRetryTotalDelay caps the total time the receipt of a confirmBatch transaction is waited for when its
+	// batch ID can't be parsed, so that batches are handed to the BatchIDPending reconciliation instead of blocking
+	// the batcher
+	maxReceiptRetryTotalDelay = 10 * time.Second
 )
 
+// ErrTooManyNonSigners is returned when the non-signers of a batch exceed MaxNonSigners
+var ErrTooManyNonSigners = errors.New("too many non-signers")
+
+// ErrCriticalQuorumNotAttested is returned when a critical quorum of a batch falls short of CriticalQuorumThreshold
+var ErrCriticalQuorumNotAttested = errors.New("critical quorum not attested")
+
+// ErrBatchIDPending is returned when a batch is confirmed onchain but its batch ID can't be parsed from the receipt of
+// the confirmation transaction. The attested blobs of the batch are marked as BatchIDPending until the batch ID is
+// reconciled by ReconcilePendingBatchIDs.
+var ErrBatchIDPending = errors.New("batch ID pending")
+
 type BatchPlan struct {
 	IncludedBlobs []*disperser.BlobMetadata
 	Quorums       map[core.QuorumID]QuorumInfo
@@ -42,12 +62,16 @@ type TimeoutConfig struct {
 	AttestationTimeout time.Duration
 	ChainReadTimeout   time.Duration
 	ChainWriteTimeout  time.Duration
+	// StoreChunksTimeout is how long each operator is given to store its chunks before it's counted as a non-signer,
+	// independently of AttestationTimeout. 0 gives each operator the whole AttestationTimeout.
+	StoreChunksTimeout time.Duration
 }
 
 type Config struct {
 	PullInterval             time.Duration
 	FinalizerInterval        time.Duration
 	FinalizerPoolSize        int
+	FinalizerRetryConfig     FinalizerRetryConfig
 	EncoderSocket            string
 	SRSOrder                 int
 	NumConnections           int
@@ -58,6 +82,105 @@ type Config struct {
 
 	TargetNumChunks          uint
 	MaxBlobsToFetchFromStore int
+	// MaxPagesToFetchFromStore is the maximum number of pages of MaxBlobsToFetchFromStore blobs read from the queue in
+	// each round of encoding requests. If 0, a single page is read.
+	MaxPagesToFetchFromStore int
+	// MinBatchInterval is the minimum time between dispatched batches, regardless of what triggered them. 0 disables the limit.
+	MinBatchInterval time.Duration
+	// PendingConfirmationTimeout is how long a dispersed blob can wait for its batch to be confirmed before it's batched again.
+	// Batches confirmed onchain past the timeout are processed from their BatchConfirmed event instead. 0 disables the timeout.
+	PendingConfirmationTimeout time.Duration
+	// ServiceManagerAddress is the address of the EigenDA service manager, whose BatchConfirmed events are checked before
+	// the blobs of a batch pending confirmation past PendingConfirmationTimeout are retried
+	ServiceManagerAddress gcommon.Address
+	// HighPriorityLaneWeight is the number of blobs encoded from a priority lane for each blob from the lane below it.
+	// If 0, higher priority blobs are always encoded first.
+	HighPriorityLaneWeight uint
+	// StreamerStallTimeout is how long encoding can make no progress before the encoding streamer is restarted.
+	// 0 disables the restarts.
+	StreamerStallTimeout time.Duration
+	// VerifyAggregateSignature enables verifying the aggregate signature against the operator state before confirming a batch
+	VerifyAggregateSignature bool
+	// PullIntervalJitterPercent randomizes each pull interval within this percentage of PullInterval. 0 disables the jitter.
+	PullIntervalJitterPercent uint
+	// MaxConfirmBatchCalldata is the maximum size in bytes of the confirmBatch calldata. Before dispersal, batches that could
+	// exceed it when MaxNonSigners operators (or all operators if unset) don't sign are split by quorums into sub-batches
+	// that are dispatched and confirmed independently. 0 disables the limit.
+	MaxConfirmBatchCalldata uint
+	// SigningScheme is the scheme operators sign the headers of new batches with. Defaults to BN254 BLS.
+	SigningScheme core.SigningSchemeID
+	// ConfirmationInfoRetention is how long after a blob is requested the confirmation info of the finalized blob is kept
+	// in full, before its inclusion proof and length proofs are pruned. 0 disables pruning.
+	ConfirmationInfoRetention time.Duration
+	// ConfirmedBatchDir is the directory the confirmation info of confirmed batches is kept in until all of their blobs
+	// are updated, so that the blobs are reconciled after a restart. If empty, it is kept in memory only.
+	ConfirmedBatchDir string
+	// MaxBlobQueueAge is how long the oldest encoded blob can wait to be batched before a batch is triggered, even if the
+	// batch size limit and pull interval haven't been reached. 0 disables the trigger.
+	MaxBlobQueueAge time.Duration
+	// MinGasTipCap is the minimum gas tip cap in wei of the confirmBatch transactions, including the replacement
+	// transactions sent to speed them up. 0 disables the minimum.
+	MinGasTipCap uint64
+	// TxnSignerURL is the URL of the remote signer the transactions of the batcher are signed with through
+	// eth_signTransaction, e.g. a Web3Signer holding the key in AWS KMS. If empty, the local key of the eth client is used.
+	TxnSignerURL string
+	// TxnSignerAddress is the account of the key held by the remote signer at TxnSignerURL
+	TxnSignerAddress string
+	// MaxNonSigners is the maximum number of non-signers of a batch. A larger non-signer set indicates a quorum failure, and
+	// the batch is failed instead of computing and confirming a signatory record over all the non-signers. 0 disables the
+	// limit.
+	MaxNonSigners uint
+	// ConfirmationWriteBatchSize is the number of blob confirmations written to the blob store in a single batched write.
+	// 0 writes the confirmations of a batch in a single batched write.
+	ConfirmationWriteBatchSize uint
+	// ConfirmationWriteConcurrency is the maximum number of batched confirmation writes in flight at a time. Defaults to 1.
+	ConfirmationWriteConcurrency uint
+	// RedisperseUnattestedQuorums retries blobs that got insufficient signatures on some of their quorums instead of
+	// marking them as having insufficient signatures. The blobs are dispersed again to all of their quorums, so that the
+	// confirmation info of a blob always refers to a single batch whose blob header covers all of the blob's quorums.
+	RedisperseUnattestedQuorums bool
+	// BatchFailureLogInterval is the interval over which repeated identical batch failures are collapsed into a single
+	// summary log. 0 logs every failure.
+	BatchFailureLogInterval time.Duration
+	// EncodingRequestTimeout is the timeout of each encoding request sent to the encoder. Defaults to PullInterval if 0.
+	EncodingRequestTimeout time.Duration
+	// DispersalFailurePolicy determines how the operators that fail to receive the chunks of a batch are handled.
+	// Defaults to DispersalFailureProceed.
+	DispersalFailurePolicy DispersalFailurePolicy
+	// CriticalQuorums are the must-have quorums of a batch. The batch is failed if the percentage of stake that signed
+	// any of its critical quorums is below CriticalQuorumThreshold. Otherwise, the blobs falling short of their own quorum
+	// threshold on any quorum are marked as having insufficient signatures while the rest of the batch is confirmed.
+	CriticalQuorums []core.QuorumID
+	// CriticalQuorumThreshold is the minimum percentage of stake that must sign each critical quorum of a batch
+	CriticalQuorumThreshold uint8
+	// FinalizerWriteBatchSize is the number of blob finalizations written to the blob store in a single batched write,
+	// if the blob store supports batched writes. 0 writes the finalizations of each page of blobs in a single batched write.
+	FinalizerWriteBatchSize int
+	// MaxEncodedResultAge is how long an encoded blob that isn't pending confirmation is kept in memory before it's
+	// dropped, so that the encodings of blobs that are never batched are freed. 0 keeps them until they go stale.
+	MaxEncodedResultAge time.Duration
+	// MaxReceiptParseRetries is the number of times the receipt of a confirmBatch transaction is fetched again when the
+	// batch ID can't be parsed from it, independently of MaxNumRetriesPerBlob. The attested blobs of batches whose batch
+	// ID still can't be parsed are marked as BatchIDPending and reconciled every FinalizerInterval, instead of being
+	// dispersed again. The retries stop early once they have waited for 10s in total.
+	// Defaults to 3.
+	MaxReceiptParseRetries uint
+	// MaxPendingConfirmationDuration is how long a dispersed blob can wait for its batch to be confirmed before it's
+	// counted as stuck in the metrics and logged. 0 disables the count.
+	MaxPendingConfirmationDuration time.Duration
+	// VerifyCommitmentsBeforeDispatch verifies that the commitment of each blob of a batch is equivalent to its length
+	// commitment before the batch is dispatched, with CommitmentVerifier. The blobs failing the verification are failed,
+	// and the other blobs are left to the next batch.
+	VerifyCommitmentsBeforeDispatch bool
+	// EnableLocalEncoderFallback encodes the blobs with a local encoder while the remote encoder is unhealthy, so that
+	// dispersal continues degraded instead of stopping
+	EnableLocalEncoderFallback bool
+	// EncoderFallbackRetryInterval is how long the local encoder is used after the remote encoder fails before the remote
+	// encoder is tried again. Defaults to 30s.
+	EncoderFallbackRetryInterval time.Duration
+	// IdleLogPolicy determines how the pulls that find no encoded results to make a batch with are logged. Defaults to
+	// IdleLogWarn.
+	IdleLogPolicy IdleLogPolicy
 }
 
 type Batcher struct {
@@ -75,11 +198,42 @@ type Batcher struct {
 	Transactor            core.Transactor
 	TransactionManager    TxnManager
 	Metrics               *Metrics
+	// Observer is notified of batch lifecycle events. Defaults to a no-op observer.
+	Observer EventObserver
+	// CommitmentVerifier verifies the commitments of the blobs of a batch before dispatch. It must be set if
+	// VerifyCommitmentsBeforeDispatch is enabled.
+	CommitmentVerifier core.Encoder
 
 	ethClient     common.EthClient
 	finalizer     Finalizer
 	logger        common.Logger
 	HeartbeatChan chan time.Time
+
+	// batchFailureLogs collapses repeated identical batch failures so that persistent failures don't flood the logs
+	batchFailureLogs *ErrorLogLimiter
+	// idleLogs logs the pulls that find no encoded results according to IdleLogPolicy
+	idleLogs *IdleLogger
+
+	// lastBatchDispatchedAt is the time the last batch was dispatched, used to enforce MinBatchInterval
+	lastBatchDispatchedAt time.Time
+
+	// confirmedBatches holds the confirmation info of the blobs of batches confirmed onchain whose blobs have not all
+	// been updated yet, so that they can be reconciled with ReconcileConfirmedBatch
+	confirmedBatches ConfirmedBatchStore
+
+	// pendingBatches are the batches sent for confirmation whose receipt hasn't been returned by the transaction manager
+	// yet, by the ID assigned with addPendingBatch
+	pendingBatches     map[uint64]*pendingBatch
+	lastPendingBatchID uint64
+	pendingBatchesMu   sync.Mutex
+
+	// inFlightBatches is the number of batches sent for confirmation and not processed yet at each reference block
+	inFlightBatches   map[uint]int
+	inFlightBatchesMu sync.Mutex
+
+	// batchConfirmedCallbacks are the callbacks registered with OnBatchConfirmed
+	batchConfirmedCallbacks   []func(*BatchResult)
+	batchConfirmedCallbacksMu sync.RWMutex
 }
 
 func NewBatcher(
@@ -103,12 +257,33 @@ func NewBatcher(
 		make(chan struct{}, 1),
 		uint64(config.BatchSizeMBLimit)*1024*1024, // convert to bytes
 	)
+	encodingRequestTimeout := config.EncodingRequestTimeout
+	if encodingRequestTimeout == 0 {
+		encodingRequestTimeout = config.PullInterval
+	}
 	streamerConfig := StreamerConfig{
 		SRSOrder:                 config.SRSOrder,
-		EncodingRequestTimeout:   config.PullInterval,
+		EncodingRequestTimeout:   encodingRequestTimeout,
 		EncodingQueueLimit:       config.EncodingRequestQueueSize,
 		TargetNumChunks:          config.TargetNumChunks,
 		MaxBlobsToFetchFromStore: config.MaxBlobsToFetchFromStore,
+		MaxPagesToFetchFromStore: config.MaxPagesToFetchFromStore,
+
+		HighPriorityLaneWeight: config.HighPriorityLaneWeight,
+		StallTimeout:           config.StreamerStallTimeout,
+		SigningScheme:          config.SigningScheme,
+		MaxBlobQueueAge:        config.MaxBlobQueueAge,
+		MaxEncodedResultAge:    config.MaxEncodedResultAge,
+
+		MaxPendingConfirmationDuration: config.MaxPendingConfirmationDuration,
+	}
+	confirmedBatches := NewInMemoryConfirmedBatchStore()
+	if config.ConfirmedBatchDir != "" {
+		var err error
+		confirmedBatches, err = NewFileConfirmedBatchStore(config.ConfirmedBatchDir)
+		if err != nil {
+			return nil, err
+		}
 	}
 	encodingWorkerPool := workerpool.New(config.NumConnections)
 	encodingStreamer, err := NewEncodingStreamer(streamerConfig, queue, chainState, encoderClient, assignmentCoordinator, batchTrigger, encodingWorkerPool, metrics.EncodingStreamerMetrics, logger)
@@ -131,15 +306,26 @@ func NewBatcher(
 		Transactor:            transactor,
 		TransactionManager:    txnManager,
 		Metrics:               metrics,
+		Observer:              noopEventObserver{},
 
 		ethClient:     ethClient,
 		finalizer:     finalizer,
 		logger:        logger,
 		HeartbeatChan: heartbeatChan,
+
+		batchFailureLogs: NewErrorLogLimiter(logger, "failed to process a batch", config.BatchFailureLogInterval, nil),
+		idleLogs:         NewIdleLogger(logger, config.IdleLogPolicy),
+
+		confirmedBatches: confirmedBatches,
+		pendingBatches:   make(map[uint64]*pendingBatch),
+		inFlightBatches:  make(map[uint]int),
 	}, nil
 }
 
 func (b *Batcher) Start(ctx context.Context) error {
+	if b.VerifyCommitmentsBeforeDispatch && b.CommitmentVerifier == nil {
+		return errors.New("a commitment verifier is required to verify commitments before dispatch")
+	}
 	err := b.ChainState.Start(ctx)
 	if err != nil {
 		return err
@@ -147,6 +333,10 @@ func (b *Batcher) Start(ctx context.Context) error {
 	// Wait for few seconds for indexer to index blockchain
 	// This won't be needed when we switch to using Graph node
 	time.Sleep(indexerWarmupDelay)
+	// Blobs of batches confirmed before a restart are updated before the encoding streamer picks them up for dispersal
+	if err := b.ReconcileConfirmedBatches(ctx); err != nil {
+		b.logger.Error("failed to reconcile confirmed batches", "err", err)
+	}
 	err = b.EncodingStreamer.Start(ctx)
 	if err != nil {
 		return err
@@ -171,34 +361,27 @@ func (b *Batcher) Start(ctx context.Context) error {
 	b.TransactionManager.Start(ctx)
 
 	b.finalizer.Start(ctx)
+	if b.FinalizerInterval > 0 {
+		go b.reconcilePendingBatchIDsLoop(ctx)
+	}
+	if b.ConfirmationInfoRetention > 0 {
+		NewConfirmationInfoPruner(b.Queue, b.ConfirmationInfoRetention, b.FinalizerInterval, 1000, b.logger).Start(ctx)
+	}
 
 	go func() {
-		ticker := time.NewTicker(b.PullInterval)
-		defer ticker.Stop()
+		ticker := NewPullTicker(b.PullInterval, b.PullIntervalJitterPercent, time.After)
+		tick := ticker.Next()
 
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-ticker.C:
-				if err := b.HandleSingleBatch(ctx); err != nil {
-					if errors.Is(err, errNoEncodedResults) {
-						b.logger.Warn("no encoded results to make a batch with")
-					} else {
-						b.logger.Error("failed to process a batch", "err", err)
-					}
-				}
+			case <-tick:
+				tick = ticker.Next()
+				b.handleSingleBatchAndLog(ctx)
 			case <-batchTrigger.Notify:
-				ticker.Stop()
-
-				if err := b.HandleSingleBatch(ctx); err != nil {
-					if errors.Is(err, errNoEncodedResults) {
-						b.logger.Warn("no encoded results to make a batch with")
-					} else {
-						b.logger.Error("failed to process a batch", "err", err)
-					}
-				}
-				ticker.Reset(b.PullInterval)
+				b.handleSingleBatchAndLog(ctx)
+				tick = ticker.Next()
 			}
 		}
 	}()
@@ -206,6 +389,21 @@ func (b *Batcher) Start(ctx context.Context) error {
 	return nil
 }
 
+// handleSingleBatchAndLog handles a single batch and logs its failure, collapsing repeated identical failures
+func (b *Batcher) handleSingleBatchAndLog(ctx context.Context) {
+	err := b.HandleSingleBatch(ctx)
+	if errors.Is(err, errNoEncodedResults) {
+		b.idleLogs.Idle()
+		return
+	}
+	b.idleLogs.Active()
+	if err == nil {
+		b.batchFailureLogs.Reset()
+	} else {
+		b.batchFailureLogs.Log(err)
+	}
+}
+
 // updateConfirmationInfo updates the confirmation info for each blob in the batch and returns failed blobs to retry.
 func (b *Batcher) updateConfirmationInfo(
 	ctx context.Context,
@@ -234,84 +432,265 @@ func (b *Batcher) updateConfirmationInfo(
 	if err != nil {
 		return nil, fmt.Errorf("HandleSingleBatch: error getting batch header hash: %w", err)
 	}
-	batchID, err := b.getBatchID(ctx, txnReceipt)
-	if err != nil {
-		return nil, fmt.Errorf("HandleSingleBatch: error fetching batch ID: %w", err)
-	}
+	// The batch is onchain even if its batch ID can't be parsed from the receipt, so its attested blobs are recorded
+	// without the batch ID instead of being dispersed again
+	batchID, batchIDErr := b.getBatchID(ctx, txnReceipt)
+	batchIDPending := batchIDErr != nil
 
 	blobsToRetry := make([]*disperser.BlobMetadata, 0)
-	var updateConfirmationInfoErr error
-
+	confirmedBlobs := make([]ConfirmedBlob, 0, len(batchData.blobs))
+	confirmedMetadatas := make([]*disperser.BlobMetadata, 0, len(batchData.blobs))
 	for blobIndex, metadata := range batchData.blobs {
-		// Mark the blob failed if it didn't get enough signatures.
-		status := disperser.InsufficientSignatures
-
-		var proof []byte
-		if isBlobAttested(batchData.aggSig.QuorumResults, batchData.blobHeaders[blobIndex]) {
-			status = disperser.Confirmed
-			// generate inclusion proof
-			if blobIndex >= len(batchData.blobHeaders) {
-				b.logger.Error("HandleSingleBatch: error confirming blobs: blob header not found in batch", "index", blobIndex)
-				blobsToRetry = append(blobsToRetry, batchData.blobs[blobIndex])
-				continue
-			}
-			blobHeader := batchData.blobHeaders[blobIndex]
+		if _, ok := batchData.skipBlobs[metadata.GetBlobKey()]; ok {
+			continue
+		}
+		confirmationInfo, attested, err := b.getBlobConfirmationInfo(batchData, blobIndex, metadata, headerHash, batchID, txnReceipt)
+		if err != nil {
+			b.logger.Error("HandleSingleBatch: error updating blob confirmed metadata", "err", err)
+			blobsToRetry = append(blobsToRetry, metadata)
+			continue
+		}
+		confirmedBlobs = append(confirmedBlobs, ConfirmedBlob{BlobKey: metadata.GetBlobKey(), ConfirmationInfo: confirmationInfo, Attested: attested})
+		confirmedMetadatas = append(confirmedMetadatas, metadata)
+	}
 
-			blobHeaderHash, err := blobHeader.GetBlobHeaderHash()
-			if err != nil {
-				b.logger.Error("HandleSingleBatch: failed to get blob header hash", "err", err)
-				blobsToRetry = append(blobsToRetry, batchData.blobs[blobIndex])
-				continue
+	// The confirmation info is stored before any blob is updated, so that the blobs left processing by a crash are
+	// reconciled after a restart
+	if !batchIDPending {
+		err := b.confirmedBatches.PutConfirmedBatch(ctx, &ConfirmedBatch{BatchID: batchID, TxHash: txnReceipt.TxHash, Blobs: confirmedBlobs})
+		if err != nil {
+			b.logger.Error("HandleSingleBatch: error storing confirmed batch", "batchID", batchID, "err", err)
+		}
+		if err := b.Queue.StoreBatchAggregateSignature(ctx, batchID, batchData.aggSig); err != nil {
+			b.logger.Error("HandleSingleBatch: error storing batch aggregate signature", "batchID", batchID, "err", err)
+		}
+	}
+
+	confirmations := make([]disperser.ConfirmationWrite, 0, len(confirmedBlobs))
+	for i, confirmedBlob := range confirmedBlobs {
+		metadata, confirmationInfo := confirmedMetadatas[i], confirmedBlob.ConfirmationInfo
+		if confirmedBlob.Attested {
+			confirmations = append(confirmations, disperser.ConfirmationWrite{Metadata: metadata, ConfirmationInfo: confirmationInfo})
+			continue
+		}
+
+		if b.shouldRedisperseUnattestedQuorums(metadata, confirmationInfo) {
+			if err := b.markBlobPartiallyAttested(ctx, metadata); err != nil {
+				b.logger.Error("HandleSingleBatch: error updating blob partially attested metadata", "err", err)
+				blobsToRetry = append(blobsToRetry, metadata)
 			}
-			merkleProof, err := batchData.merkleTree.GenerateProof(blobHeaderHash[:], 0)
-			if err != nil {
-				b.logger.Error("HandleSingleBatch: failed to generate blob header inclusion proof", "err", err)
-				blobsToRetry = append(blobsToRetry, batchData.blobs[blobIndex])
-				continue
+			continue
+		}
+		if err := b.markBlobInsufficientSignatures(ctx, metadata, confirmationInfo); err != nil {
+			b.logger.Error("HandleSingleBatch: error updating blob confirmed metadata", "err", err)
+			blobsToRetry = append(blobsToRetry, metadata)
+		}
+	}
+	if batchIDPending {
+		blobsToRetry = append(blobsToRetry, b.markBlobsBatchIDPending(ctx, confirmations)...)
+		return blobsToRetry, fmt.Errorf("HandleSingleBatch: error fetching batch ID: %w: %w", ErrBatchIDPending, batchIDErr)
+	}
+	blobsToRetry = append(blobsToRetry, b.markBlobsConfirmed(ctx, confirmations)...)
+	if len(blobsToRetry) == 0 {
+		b.removeConfirmedBatch(ctx, batchID)
+	}
+
+	return blobsToRetry, nil
+}
+
+// markBlobsBatchIDPending records the confirmations, which lack the batch ID, and returns the blobs that failed to be
+// recorded
+func (b *Batcher) markBlobsBatchIDPending(ctx context.Context, confirmations []disperser.ConfirmationWrite) []*disperser.BlobMetadata {
+	blobsToRetry := make([]*disperser.BlobMetadata, 0)
+	for _, confirmation := range confirmations {
+		metadata := confirmation.Metadata
+		if _, err := b.Queue.MarkBlobBatchIDPending(ctx, metadata, confirmation.ConfirmationInfo); err != nil {
+			b.logger.Error("HandleSingleBatch: error updating blob batch ID pending metadata", "blobKey", metadata.GetBlobKey().String(), "err", err)
+			blobsToRetry = append(blobsToRetry, metadata)
+			continue
+		}
+		// remove encoded blob from storage so we don't disperse it again
+		b.EncodingStreamer.RemoveEncodedBlob(metadata)
+	}
+	return blobsToRetry
+}
+
+// markBlobsConfirmed writes the confirmations in groups of ConfirmationWriteBatchSize, with up to
+// ConfirmationWriteConcurrency groups written at a time, and returns the blobs that failed to be marked as confirmed.
+func (b *Batcher) markBlobsConfirmed(ctx context.Context, confirmations []disperser.ConfirmationWrite) []*disperser.BlobMetadata {
+	if len(confirmations) == 0 {
+		return nil
+	}
+	batchSize := int(b.ConfirmationWriteBatchSize)
+	if batchSize == 0 {
+		batchSize = len(confirmations)
+	}
+	concurrency := int(b.ConfirmationWriteConcurrency)
+	if concurrency == 0 {
+		concurrency = 1
+	}
+
+	var mu sync.Mutex
+	failed := make(map[disperser.BlobKey]struct{})
+	pool := workerpool.New(concurrency)
+	for start := 0; start < len(confirmations); start += batchSize {
+		end := start + batchSize
+		if end > len(confirmations) {
+			end = len(confirmations)
+		}
+		group := confirmations[start:end]
+		pool.Submit(func() {
+			err := b.Queue.BatchMarkBlobsConfirmed(ctx, group)
+			if err == nil {
+				return
 			}
-			proof = serializeProof(merkleProof)
-		}
-
-		confirmationInfo := &disperser.ConfirmationInfo{
-			BatchHeaderHash:         headerHash,
-			BlobIndex:               uint32(blobIndex),
-			SignatoryRecordHash:     core.ComputeSignatoryRecordHash(uint32(batchData.batchHeader.ReferenceBlockNumber), batchData.aggSig.NonSigners),
-			ReferenceBlockNumber:    uint32(batchData.batchHeader.ReferenceBlockNumber),
-			BatchRoot:               batchData.batchHeader.BatchRoot[:],
-			BlobInclusionProof:      proof,
-			BlobCommitment:          &batchData.blobHeaders[blobIndex].BlobCommitments,
-			BatchID:                 uint32(batchID),
-			ConfirmationTxnHash:     txnReceipt.TxHash,
-			ConfirmationBlockNumber: uint32(txnReceipt.BlockNumber.Uint64()),
-			Fee:                     []byte{0}, // No fee
-			QuorumResults:           batchData.aggSig.QuorumResults,
-			BlobQuorumInfos:         batchData.blobHeaders[blobIndex].QuorumInfos,
-		}
-
-		if status == disperser.Confirmed {
-			if _, updateConfirmationInfoErr = b.Queue.MarkBlobConfirmed(ctx, metadata, confirmationInfo); updateConfirmationInfoErr == nil {
-				b.Metrics.UpdateCompletedBlob(int(metadata.RequestMetadata.BlobSize), disperser.Confirmed)
-				// remove encoded blob from storage so we don't disperse it again
-				b.EncodingStreamer.RemoveEncodedBlob(metadata)
+			b.logger.Error("HandleSingleBatch: error updating blob confirmed metadata", "numBlobs", len(group), "err", err)
+			mu.Lock()
+			defer mu.Unlock()
+			var batchErr *disperser.BatchConfirmationError
+			if errors.As(err, &batchErr) {
+				for _, key := range batchErr.FailedKeys {
+					failed[key] = struct{}{}
+				}
+				return
 			}
-		} else if status == disperser.InsufficientSignatures {
-			if _, updateConfirmationInfoErr = b.Queue.MarkBlobInsufficientSignatures(ctx, metadata, confirmationInfo); updateConfirmationInfoErr == nil {
-				b.Metrics.UpdateCompletedBlob(int(metadata.RequestMetadata.BlobSize), disperser.InsufficientSignatures)
-				// remove encoded blob from storage so we don't disperse it again
-				b.EncodingStreamer.RemoveEncodedBlob(metadata)
+			for _, confirmation := range group {
+				failed[confirmation.Metadata.GetBlobKey()] = struct{}{}
 			}
+		})
+	}
+	pool.StopWait()
+
+	blobsToRetry := make([]*disperser.BlobMetadata, 0, len(failed))
+	for _, confirmation := range confirmations {
+		metadata := confirmation.Metadata
+		if _, ok := failed[metadata.GetBlobKey()]; ok {
+			blobsToRetry = append(blobsToRetry, metadata)
 		} else {
-			updateConfirmationInfoErr = fmt.Errorf("HandleSingleBatch: trying to update confirmation info for blob in status other than confirmed or insufficient signatures: %s", status.String())
+			b.Metrics.UpdateCompletedBlob(int(metadata.RequestMetadata.BlobSize), disperser.Confirmed)
+			// remove encoded blob from storage so we don't disperse it again
+			b.EncodingStreamer.RemoveEncodedBlob(metadata)
+		}
+		b.observeE2ELatency(metadata)
+	}
+	return blobsToRetry
+}
+
+// updateBlobConfirmationInfo marks the blob of a confirmed batch as confirmed, or as having insufficient signatures if it
+// isn't attested
+func (b *Batcher) updateBlobConfirmationInfo(ctx context.Context, metadata *disperser.BlobMetadata, confirmationInfo *disperser.ConfirmationInfo, attested bool) error {
+	if !attested {
+		if b.shouldRedisperseUnattestedQuorums(metadata, confirmationInfo) {
+			return b.markBlobPartiallyAttested(ctx, metadata)
 		}
-		if updateConfirmationInfoErr != nil {
-			b.logger.Error("HandleSingleBatch: error updating blob confirmed metadata", "err", updateConfirmationInfoErr)
-			blobsToRetry = append(blobsToRetry, batchData.blobs[blobIndex])
+		return b.markBlobInsufficientSignatures(ctx, metadata, confirmationInfo)
+	}
+	if failed := b.markBlobsConfirmed(ctx, []disperser.ConfirmationWrite{{Metadata: metadata, ConfirmationInfo: confirmationInfo}}); len(failed) > 0 {
+		return fmt.Errorf("failed to mark blob %s as confirmed", metadata.GetBlobKey().String())
+	}
+	return nil
+}
+
+// markBlobInsufficientSignatures marks the blob failed as it didn't get enough signatures
+func (b *Batcher) markBlobInsufficientSignatures(ctx context.Context, metadata *disperser.BlobMetadata, confirmationInfo *disperser.ConfirmationInfo) error {
+	_, err := b.Queue.MarkBlobInsufficientSignatures(ctx, metadata, confirmationInfo)
+	if err == nil {
+		b.Metrics.UpdateCompletedBlob(int(metadata.RequestMetadata.BlobSize), disperser.InsufficientSignatures)
+		// remove encoded blob from storage so we don't disperse it again
+		b.EncodingStreamer.RemoveEncodedBlob(metadata)
+	}
+	b.observeE2ELatency(metadata)
+	return err
+}
+
+// shouldRedisperseUnattestedQuorums returns whether the blob, which didn't get enough signatures on all of its quorums,
+// is to be retried
+func (b *Batcher) shouldRedisperseUnattestedQuorums(metadata *disperser.BlobMetadata, confirmationInfo *disperser.ConfirmationInfo) bool {
+	if !b.RedisperseUnattestedQuorums || metadata.NumRetries >= b.MaxNumRetriesPerBlob {
+		return false
+	}
+	for _, attested := range confirmationInfo.AttestedQuorums {
+		if attested {
+			return true
 		}
-		requestTime := time.Unix(0, int64(metadata.RequestMetadata.RequestedAt))
-		b.Metrics.ObserveLatency("E2E", float64(time.Since(requestTime).Milliseconds()))
 	}
+	return false
+}
 
-	return blobsToRetry, nil
+// markBlobPartiallyAttested keeps the blob in processing with an incremented retry count, so that it is dispersed again
+// to all of its quorums in a later batch
+func (b *Batcher) markBlobPartiallyAttested(ctx context.Context, metadata *disperser.BlobMetadata) error {
+	// remove encoded blob from storage so that it is encoded again at the reference block of the next batch
+	b.EncodingStreamer.RemoveEncodedBlob(metadata)
+	err := b.Queue.IncrementBlobRetryCount(ctx, metadata)
+	if err == nil {
+		b.Metrics.UpdateBlobFailure(FailInsufficientSignatures, true)
+	}
+	return err
+}
+
+func (b *Batcher) observeE2ELatency(metadata *disperser.BlobMetadata) {
+	requestTime := time.Unix(0, int64(metadata.RequestMetadata.RequestedAt))
+	b.Metrics.ObserveLatency("E2E", float64(time.Since(requestTime).Milliseconds()))
+}
+
+// getBlobConfirmationInfo returns the confirmation info of the blob at the given index of the confirmed batch, and whether
+// the blob is attested, i.e. whether it is to be marked as confirmed or as having insufficient signatures
+func (b *Batcher) getBlobConfirmationInfo(
+	batchData confirmationMetadata,
+	blobIndex int,
+	metadata *disperser.BlobMetadata,
+	headerHash [32]byte,
+	batchID uint32,
+	txnReceipt *types.Receipt,
+) (*disperser.ConfirmationInfo, bool, error) {
+	if blobIndex >= len(batchData.blobHeaders) {
+		return nil, false, fmt.Errorf("blob header not found in batch at index %d", blobIndex)
+	}
+	blobHeader := batchData.blobHeaders[blobIndex]
+
+	var proof []byte
+	attested := isBlobAttested(batchData.aggSig.QuorumResults, blobHeader)
+	if attested {
+		// generate inclusion proof
+		blobHeaderHash, err := blobHeader.GetBlobHeaderHash()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get blob header hash: %w", err)
+		}
+		merkleProof, err := batchData.merkleTree.GenerateProof(blobHeaderHash[:])
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to generate blob header inclusion proof: %w", err)
+		}
+		proof = merkleProof.Serialize()
+	}
+
+	var assignmentInfos map[core.QuorumID]core.AssignmentInfo
+	if batchData.state != nil {
+		var err error
+		assignmentInfos, err = b.getAssignmentInfos(batchData.state, metadata, blobHeader)
+		if err != nil {
+			b.logger.Error("HandleSingleBatch: failed to get blob assignment infos", "err", err)
+		}
+	}
+
+	return &disperser.ConfirmationInfo{
+		BatchHeaderHash:         headerHash,
+		BlobIndex:               uint32(blobIndex),
+		SignatoryRecordHash:     core.ComputeSignatoryRecordHash(uint32(batchData.batchHeader.ReferenceBlockNumber), batchData.aggSig.NonSigners),
+		ReferenceBlockNumber:    uint32(batchData.batchHeader.ReferenceBlockNumber),
+		BatchRoot:               batchData.batchHeader.BatchRoot[:],
+		BlobInclusionProof:      proof,
+		BlobCommitment:          &blobHeader.BlobCommitments,
+		BatchID:                 batchID,
+		ConfirmationTxnHash:     txnReceipt.TxHash,
+		ConfirmationBlockNumber: uint32(txnReceipt.BlockNumber.Uint64()),
+		Fee:                     []byte{0}, // No fee
+		QuorumResults:           batchData.aggSig.QuorumResults,
+		BlobQuorumInfos:         blobHeader.QuorumInfos,
+		AttestedQuorums:         getAttestedQuorums(batchData.aggSig.QuorumResults, blobHeader),
+		AssignmentInfos:         assignmentInfos,
+		ConfirmedAt:             uint64(b.EncodingStreamer.Now().UnixNano()),
+	}, attested, nil
 }
 
 func (b *Batcher) ProcessConfirmedBatch(ctx context.Context, receiptOrErr *ReceiptOrErr) error {
@@ -319,31 +698,88 @@ func (b *Batcher) ProcessConfirmedBatch(ctx context.Context, receiptOrErr *Recei
 		return fmt.Errorf("failed to process confirmed batch: no metadata from transaction manager response")
 	}
 	confirmationMetadata := receiptOrErr.Metadata.(confirmationMetadata)
-	blobs := confirmationMetadata.blobs
+	switch b.takePendingBatch(confirmationMetadata) {
+	case batchReconciled:
+		b.logger.Info("ignoring the receipt of a batch already processed from its confirmation onchain", "err", receiptOrErr.Err)
+		return nil
+	case batchTimedOut:
+		if receiptOrErr.Err != nil || receiptOrErr.Receipt.Status == types.ReceiptStatusFailed {
+			b.logger.Info("ignoring the failed confirmation of a batch that timed out, its blobs were retried already", "err", receiptOrErr.Err)
+			return nil
+		}
+		// The batch was confirmed onchain after its blobs were retried. The blobs batched again since are left to their
+		// new batch, so that they aren't confirmed twice.
+		confirmationMetadata.skipBlobs = b.rebatchedBlobs(confirmationMetadata.blobs)
+		if len(confirmationMetadata.skipBlobs) == len(confirmationMetadata.blobs) {
+			b.logger.Info("ignoring the confirmation of a batch that timed out, its blobs were all batched again", "txnHash", receiptOrErr.Receipt.TxHash.Hex())
+			return nil
+		}
+		b.logger.Warn("batch that timed out was confirmed onchain, confirming its blobs that weren't batched again", "txnHash", receiptOrErr.Receipt.TxHash.Hex(), "numBlobs", len(confirmationMetadata.blobs), "numRebatched", len(confirmationMetadata.skipBlobs))
+		return b.notifyConfirmedBatch(ctx, confirmationMetadata, receiptOrErr)
+	}
+	return b.handleConfirmedBatch(ctx, confirmationMetadata, receiptOrErr)
+}
+
+// handleConfirmedBatch processes the receipt of the in-flight batch
+func (b *Batcher) handleConfirmedBatch(ctx context.Context, confirmationMetadata confirmationMetadata, receiptOrErr *ReceiptOrErr) error {
+	if confirmationMetadata.batchHeader != nil {
+		defer b.removeInFlightBatch(confirmationMetadata.batchHeader.ReferenceBlockNumber)
+	}
+	return b.notifyConfirmedBatch(ctx, confirmationMetadata, receiptOrErr)
+}
+
+// notifyConfirmedBatch processes the receipt of the batch and notifies the OnBatchConfirmed callbacks
+func (b *Batcher) notifyConfirmedBatch(ctx context.Context, confirmationMetadata confirmationMetadata, receiptOrErr *ReceiptOrErr) error {
+	blobsToRetry, err := b.processConfirmedBatch(ctx, confirmationMetadata, receiptOrErr)
+	b.notifyBatchConfirmed(&BatchResult{
+		BatchHeader: confirmationMetadata.batchHeader,
+		Receipt:     receiptOrErr.Receipt,
+		Blobs:       confirmationMetadata.blobs,
+		FailedBlobs: blobsToRetry,
+		Err:         err,
+	})
+	return err
+}
+
+// processConfirmedBatch updates the confirmation info of the blobs of the batch, and returns the blobs that failed to be
+// updated and are retried
+func (b *Batcher) processConfirmedBatch(ctx context.Context, confirmationMetadata confirmationMetadata, receiptOrErr *ReceiptOrErr) ([]*disperser.BlobMetadata, error) {
+	blobs := confirmationMetadata.blobsToUpdate()
 	if len(blobs) == 0 {
-		return fmt.Errorf("failed to process confirmed batch: no blobs from transaction manager metadata")
+		return nil, fmt.Errorf("failed to process confirmed batch: no blobs from transaction manager metadata")
 	}
 	if receiptOrErr.Err != nil {
 		_ = b.handleFailure(ctx, blobs, FailConfirmBatch)
-		return fmt.Errorf("failed to confirm batch onchain: %w", receiptOrErr.Err)
+		return blobs, fmt.Errorf("failed to confirm batch onchain: %w", receiptOrErr.Err)
+	}
+	if receiptOrErr.Receipt.Status == types.ReceiptStatusFailed {
+		_ = b.handleFailure(ctx, blobs, FailConfirmBatchReverted)
+		return blobs, fmt.Errorf("failed to confirm batch onchain: confirmBatch transaction %s reverted", receiptOrErr.Receipt.TxHash.Hex())
 	}
 	if confirmationMetadata.aggSig == nil {
 		_ = b.handleFailure(ctx, blobs, FailNoAggregatedSignature)
-		return fmt.Errorf("failed to process confirmed batch: aggSig from transaction manager metadata is nil")
+		return blobs, fmt.Errorf("failed to process confirmed batch: aggSig from transaction manager metadata is nil")
 	}
 	b.logger.Info("received ConfirmBatch transaction receipt", "blockNumber", receiptOrErr.Receipt.BlockNumber, "txnHash", receiptOrErr.Receipt.TxHash.Hex())
 
 	// Mark the blobs as complete
 	stageTimer := time.Now()
 	blobsToRetry, err := b.updateConfirmationInfo(ctx, confirmationMetadata, receiptOrErr.Receipt)
-	if err != nil {
+	if err != nil && !errors.Is(err, ErrBatchIDPending) {
 		_ = b.handleFailure(ctx, blobs, FailUpdateConfirmationInfo)
-		return fmt.Errorf("failed to update confirmation info: %w", err)
+		return blobs, fmt.Errorf("failed to update confirmation info: %w", err)
 	}
 	if len(blobsToRetry) > 0 {
 		b.logger.Error("failed to update confirmation info", "failed", len(blobsToRetry), "total", len(blobs))
 		_ = b.handleFailure(ctx, blobsToRetry, FailUpdateConfirmationInfo)
 	}
+	if err != nil {
+		b.logger.Warn("batch confirmed onchain, but its batch ID couldn't be parsed from the receipt. its blobs are kept pending until the batch ID is reconciled", "txnHash", receiptOrErr.Receipt.TxHash.Hex(), "numBlobs", len(blobs))
+		return blobsToRetry, fmt.Errorf("failed to update confirmation info: %w", err)
+	}
+	notifyObserver(b.logger, b.Observer, "BatchConfirmed", func(o EventObserver) error {
+		return o.OnBatchConfirmed(ctx, confirmationMetadata.batchHeader, receiptOrErr.Receipt, blobs)
+	})
 	b.logger.Trace("[batcher] Update confirmation info took", "duration", time.Since(stageTimer))
 	b.Metrics.ObserveLatency("UpdateConfirmationInfo", float64(time.Since(stageTimer).Milliseconds()))
 	batchSize := int64(0)
@@ -352,6 +788,107 @@ func (b *Batcher) ProcessConfirmedBatch(ctx context.Context, receiptOrErr *Recei
 	}
 	b.Metrics.IncrementBatchCount(batchSize)
 
+	return blobsToRetry, nil
+}
+
+// ReconcileConfirmedBatch updates the confirmation info of the blobs of a batch confirmed onchain that are still being processed.
+// This recovers from failures in ProcessConfirmedBatch after the batch was confirmed but before all of its blobs were updated,
+// including crashes of the batcher if ConfirmedBatchDir is set. The receipt of the confirmation transaction is fetched
+// again, and blobs that are no longer processing are left untouched, so it is safe to call repeatedly.
+// The batch is forgotten once all of its blobs are updated, or if its confirmation transaction is no longer onchain, in
+// which case its blobs still processing are retried.
+func (b *Batcher) ReconcileConfirmedBatch(ctx context.Context, batchID uint32) error {
+	batch, err := b.confirmedBatches.GetConfirmedBatch(ctx, batchID)
+	if errors.Is(err, ErrConfirmedBatchNotFound) {
+		return fmt.Errorf("ReconcileConfirmedBatch: no pending metadata for batch %d", batchID)
+	}
+	if err != nil {
+		return fmt.Errorf("ReconcileConfirmedBatch: error getting confirmed batch: %w", err)
+	}
+
+	receipt, err := b.ethClient.TransactionReceipt(ctx, batch.TxHash)
+	if errors.Is(err, ethereum.NotFound) {
+		b.logger.Warn("ReconcileConfirmedBatch: confirmation transaction not found, retrying the blobs of the batch", "batchID", batchID, "txnHash", batch.TxHash.Hex())
+		return b.abandonConfirmedBatch(ctx, batch)
+	}
+	if err != nil {
+		return fmt.Errorf("ReconcileConfirmedBatch: error fetching transaction receipt: %w", err)
+	}
+	if receipt.BlockNumber == nil {
+		return fmt.Errorf("ReconcileConfirmedBatch: error getting transaction receipt block number")
+	}
+	receiptBatchID, err := b.parseBatchIDFromReceipt(ctx, receipt)
+	if err != nil {
+		return fmt.Errorf("ReconcileConfirmedBatch: error parsing batch ID: %w", err)
+	}
+	if receiptBatchID != batchID {
+		b.logger.Warn("ReconcileConfirmedBatch: confirmation transaction confirms another batch, retrying the blobs of the batch", "batchID", batchID, "receiptBatchID", receiptBatchID)
+		return b.abandonConfirmedBatch(ctx, batch)
+	}
+
+	var result *multierror.Error
+	for _, blob := range batch.Blobs {
+		current, err := b.Queue.GetBlobMetadata(ctx, blob.BlobKey)
+		if err != nil {
+			result = multierror.Append(result, err)
+			continue
+		}
+		if current.BlobStatus != disperser.Processing {
+			continue
+		}
+		// the transaction may have been included in another block after a reorg
+		confirmationInfo := *blob.ConfirmationInfo
+		confirmationInfo.ConfirmationBlockNumber = uint32(receipt.BlockNumber.Uint64())
+		if err := b.updateBlobConfirmationInfo(ctx, current, &confirmationInfo, blob.Attested); err != nil {
+			b.logger.Error("ReconcileConfirmedBatch: error updating blob confirmed metadata", "err", err)
+			result = multierror.Append(result, err)
+			continue
+		}
+		b.logger.Info("ReconcileConfirmedBatch: reconciled blob", "batchID", batchID, "blobIndex", confirmationInfo.BlobIndex, "blobKey", blob.BlobKey.String())
+	}
+	if result.ErrorOrNil() == nil {
+		b.removeConfirmedBatch(ctx, batchID)
+	}
+
+	return result.ErrorOrNil()
+}
+
+// ReconcileConfirmedBatches reconciles all the stored confirmed batches with ReconcileConfirmedBatch
+func (b *Batcher) ReconcileConfirmedBatches(ctx context.Context) error {
+	batches, err := b.confirmedBatches.ListConfirmedBatches(ctx)
+	if err != nil {
+		return fmt.Errorf("ReconcileConfirmedBatches: error listing confirmed batches: %w", err)
+	}
+	var result *multierror.Error
+	for _, batch := range batches {
+		if err := b.ReconcileConfirmedBatch(ctx, batch.BatchID); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	return result.ErrorOrNil()
+}
+
+// abandonConfirmedBatch forgets a batch that is no longer confirmed onchain, and retries its blobs still processing
+func (b *Batcher) abandonConfirmedBatch(ctx context.Context, batch *ConfirmedBatch) error {
+	blobs := make([]*disperser.BlobMetadata, 0, len(batch.Blobs))
+	var result *multierror.Error
+	for _, blob := range batch.Blobs {
+		current, err := b.Queue.GetBlobMetadata(ctx, blob.BlobKey)
+		if err != nil {
+			result = multierror.Append(result, err)
+			continue
+		}
+		if current.BlobStatus == disperser.Processing {
+			blobs = append(blobs, current)
+		}
+	}
+	if result.ErrorOrNil() != nil {
+		return fmt.Errorf("ReconcileConfirmedBatch: error getting blob metadata: %w", result.ErrorOrNil())
+	}
+	if err := b.handleFailure(ctx, blobs, FailConfirmBatch); err != nil {
+		return fmt.Errorf("ReconcileConfirmedBatch: error retrying blobs: %w", err)
+	}
+	b.removeConfirmedBatch(ctx, batch.BatchID)
 	return nil
 }
 
@@ -359,13 +896,14 @@ func (b *Batcher) handleFailure(ctx context.Context, blobMetadatas []*disperser.
 	var result *multierror.Error
 	for _, metadata := range blobMetadatas {
 		b.EncodingStreamer.RemoveEncodedBlob(metadata)
-		err := b.Queue.HandleBlobFailure(ctx, metadata, b.MaxNumRetriesPerBlob)
+		retried, err := b.Queue.HandleBlobFailure(ctx, metadata, b.MaxNumRetriesPerBlob)
 		if err != nil {
 			b.logger.Error("HandleSingleBatch: error handling blob failure", "err", err)
 			// Append the error
 			result = multierror.Append(result, err)
 		}
 		b.Metrics.UpdateCompletedBlob(int(metadata.RequestMetadata.BlobSize), disperser.Failed)
+		b.Metrics.UpdateBlobFailure(reason, retried)
 	}
 	b.Metrics.UpdateBatchError(reason, len(blobMetadatas))
 
@@ -373,12 +911,223 @@ func (b *Batcher) handleFailure(ctx context.Context, blobMetadatas []*disperser.
 	return result.ErrorOrNil()
 }
 
+// getBlobsWithInconsistentCommitments returns the blobs of the batch whose commitment isn't equivalent to their length
+// commitment. The commitments of the batch are verified together, and one by one only if the batch fails verification.
+func (b *Batcher) getBlobsWithInconsistentCommitments(batch *batch) ([]*disperser.BlobMetadata, error) {
+	if b.CommitmentVerifier == nil {
+		return nil, errors.New("no commitment verifier")
+	}
+	commitments := make([]core.BlobCommitments, len(batch.BlobHeaders))
+	for i, header := range batch.BlobHeaders {
+		if header.Commitment == nil || header.LengthCommitment == nil {
+			return nil, fmt.Errorf("blob %d of the batch has no commitment", i)
+		}
+		commitments[i] = header.BlobCommitments
+	}
+	if err := b.CommitmentVerifier.VerifyCommitEquivalenceBatch(commitments); err == nil {
+		return nil, nil
+	}
+
+	inconsistent := make([]*disperser.BlobMetadata, 0)
+	for i, commitment := range commitments {
+		if err := b.CommitmentVerifier.VerifyCommitEquivalenceBatch([]core.BlobCommitments{commitment}); err != nil {
+			b.logger.Warn("blob commitment is inconsistent with its length commitment", "blobKey", batch.BlobMetadata[i].GetBlobKey().String(), "err", err)
+			inconsistent = append(inconsistent, batch.BlobMetadata[i])
+		}
+	}
+	return inconsistent, nil
+}
+
+// getBlobsWithEmptyQuorums returns the blobs that require a quorum without any operators or stake in the operator state,
+// along with the empty quorums
+func getBlobsWithEmptyQuorums(state *core.IndexedOperatorState, blobs []*disperser.BlobMetadata) ([]*disperser.BlobMetadata, []core.QuorumID) {
+	empty := make(map[core.QuorumID]bool)
+	affected := make([]*disperser.BlobMetadata, 0)
+	for _, metadata := range blobs {
+		isAffected := false
+		for _, param := range metadata.RequestMetadata.SecurityParams {
+			isEmpty, ok := empty[param.QuorumID]
+			if !ok {
+				total := state.Totals[param.QuorumID]
+				isEmpty = len(state.Operators[param.QuorumID]) == 0 || total == nil || total.Stake == nil || total.Stake.Sign() <= 0
+				empty[param.QuorumID] = isEmpty
+			}
+			isAffected = isAffected || isEmpty
+		}
+		if isAffected {
+			affected = append(affected, metadata)
+		}
+	}
+
+	emptyQuorums := make([]core.QuorumID, 0)
+	for quorumID, isEmpty := range empty {
+		if isEmpty {
+			emptyQuorums = append(emptyQuorums, quorumID)
+		}
+	}
+	sort.Slice(emptyQuorums, func(i, j int) bool {
+		return emptyQuorums[i] < emptyQuorums[j]
+	})
+	return affected, emptyQuorums
+}
+
 type confirmationMetadata struct {
 	batchHeader *core.BatchHeader
 	blobs       []*disperser.BlobMetadata
 	blobHeaders []*core.BlobHeader
-	merkleTree  *merkletree.MerkleTree
+	merkleTree  *core.BatchMerkleTree
 	aggSig      *core.SignatureAggregation
+	state       *core.IndexedOperatorState
+	// skipBlobs are the blobs of the batch that aren't updated when it's confirmed, see HandleTimedOutBatches
+	skipBlobs map[disperser.BlobKey]struct{}
+	// pendingBatchID identifies the batch while it's pending confirmation, see addPendingBatch. 0 if it isn't tracked.
+	pendingBatchID uint64
+}
+
+// blobsToUpdate returns the blobs of the batch updated when it's confirmed
+func (m confirmationMetadata) blobsToUpdate() []*disperser.BlobMetadata {
+	if len(m.skipBlobs) == 0 {
+		return m.blobs
+	}
+	blobs := make([]*disperser.BlobMetadata, 0, len(m.blobs))
+	for _, metadata := range m.blobs {
+		if _, ok := m.skipBlobs[metadata.GetBlobKey()]; !ok {
+			blobs = append(blobs, metadata)
+		}
+	}
+	return blobs
+}
+
+// reconcilePendingBatchIDsLoop reconciles the blobs pending their batch ID and the stored confirmed batches every
+// FinalizerInterval until ctx is done
+func (b *Batcher) reconcilePendingBatchIDsLoop(ctx context.Context) {
+	ticker := time.NewTicker(b.FinalizerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.ReconcilePendingBatchIDs(ctx); err != nil {
+				b.logger.Error("failed to reconcile pending batch IDs", "err", err)
+			}
+			if err := b.ReconcileConfirmedBatches(ctx); err != nil {
+				b.logger.Error("failed to reconcile confirmed batches", "err", err)
+			}
+		}
+	}
+}
+
+// ReconcilePendingBatchIDs confirms the blobs whose batch ID couldn't be parsed from the receipt of their confirmation
+// transaction, once the batch ID can be parsed from the receipt fetched again. Blobs whose confirmation transaction is
+// no longer onchain are retried, and the others are left pending until the next call.
+func (b *Batcher) ReconcilePendingBatchIDs(ctx context.Context) error {
+	metadatas, err := b.Queue.GetBlobMetadataByStatus(ctx, disperser.BatchIDPending)
+	if err != nil {
+		return fmt.Errorf("ReconcilePendingBatchIDs: error getting blob metadata: %w", err)
+	}
+	batches := make(map[gcommon.Hash][]*disperser.BlobMetadata)
+	for _, metadata := range metadatas {
+		if metadata.ConfirmationInfo == nil {
+			b.logger.Error("ReconcilePendingBatchIDs: blob pending its batch ID has no confirmation info", "blobKey", metadata.GetBlobKey().String())
+			continue
+		}
+		txHash := metadata.ConfirmationInfo.ConfirmationTxnHash
+		batches[txHash] = append(batches[txHash], metadata)
+	}
+
+	var result *multierror.Error
+	for txHash, blobs := range batches {
+		receipt, err := b.ethClient.TransactionReceipt(ctx, txHash)
+		if errors.Is(err, ethereum.NotFound) {
+			// the confirmation transaction was reorged out, so the blobs are dispersed again
+			b.logger.Warn("ReconcilePendingBatchIDs: confirmation transaction not found, retrying its blobs", "txnHash", txHash.Hex(), "numBlobs", len(blobs))
+			for _, metadata := range blobs {
+				if err := b.Queue.MarkBlobProcessing(ctx, metadata.GetBlobKey()); err != nil {
+					result = multierror.Append(result, err)
+				}
+			}
+			if err := b.handleFailure(ctx, blobs, FailConfirmBatch); err != nil {
+				result = multierror.Append(result, err)
+			}
+			continue
+		}
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("error fetching transaction receipt of %s: %w", txHash.Hex(), err))
+			continue
+		}
+		if receipt.BlockNumber == nil {
+			result = multierror.Append(result, fmt.Errorf("transaction receipt of %s has no block number", txHash.Hex()))
+			continue
+		}
+		batchID, err := b.parseBatchIDFromReceipt(ctx, receipt)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("error parsing batch ID of %s: %w", txHash.Hex(), err))
+			continue
+		}
+
+		confirmations := make([]disperser.ConfirmationWrite, len(blobs))
+		for i, metadata := range blobs {
+			confirmationInfo := *metadata.ConfirmationInfo
+			confirmationInfo.BatchID = batchID
+			confirmationInfo.ConfirmationBlockNumber = uint32(receipt.BlockNumber.Uint64())
+			confirmations[i] = disperser.ConfirmationWrite{Metadata: metadata, ConfirmationInfo: &confirmationInfo}
+		}
+		if failed := b.markBlobsConfirmed(ctx, confirmations); len(failed) > 0 {
+			result = multierror.Append(result, fmt.Errorf("failed to mark %d blobs of batch %d as confirmed", len(failed), batchID))
+			continue
+		}
+		b.logger.Info("ReconcilePendingBatchIDs: reconciled batch", "batchID", batchID, "txnHash", txHash.Hex(), "numBlobs", len(blobs))
+	}
+
+	return result.ErrorOrNil()
+}
+
+// addInFlightBatch records a batch sent for confirmation at the reference block
+func (b *Batcher) addInFlightBatch(referenceBlockNumber uint) {
+	b.inFlightBatchesMu.Lock()
+	defer b.inFlightBatchesMu.Unlock()
+	b.inFlightBatches[referenceBlockNumber]++
+	b.Metrics.UpdateInFlightReferenceBlocks(len(b.inFlightBatches))
+}
+
+// removeInFlightBatch records that a batch sent for confirmation at the reference block has been processed
+func (b *Batcher) removeInFlightBatch(referenceBlockNumber uint) {
+	b.inFlightBatchesMu.Lock()
+	defer b.inFlightBatchesMu.Unlock()
+	if b.inFlightBatches[referenceBlockNumber] <= 1 {
+		delete(b.inFlightBatches, referenceBlockNumber)
+	} else {
+		b.inFlightBatches[referenceBlockNumber]--
+	}
+	b.Metrics.UpdateInFlightReferenceBlocks(len(b.inFlightBatches))
+}
+
+func (b *Batcher) removeConfirmedBatch(ctx context.Context, batchID uint32) {
+	if err := b.confirmedBatches.DeleteConfirmedBatch(ctx, batchID); err != nil {
+		b.logger.Error("failed to delete confirmed batch", "batchID", batchID, "err", err)
+	}
+}
+
+// waitForMinBatchInterval blocks until at least MinBatchInterval has passed since the last batch was dispatched
+func (b *Batcher) waitForMinBatchInterval(ctx context.Context) error {
+	if b.MinBatchInterval <= 0 || b.lastBatchDispatchedAt.IsZero() {
+		return nil
+	}
+	wait := time.Until(b.lastBatchDispatchedAt.Add(b.MinBatchInterval))
+	if wait <= 0 {
+		return nil
+	}
+	b.logger.Debug("deferring batch to respect the minimum batch interval", "wait", wait)
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 func (b *Batcher) HandleSingleBatch(ctx context.Context) error {
@@ -393,22 +1142,97 @@ func (b *Batcher) HandleSingleBatch(ctx context.Context) error {
 	}))
 	defer timer.ObserveDuration()
 
+	if err := b.waitForMinBatchInterval(ctx); err != nil {
+		return err
+	}
+
+	// The blobs of batches pending confirmation for too long are retried, so that they're encoded again for a later batch
+	b.HandleTimedOutBatches(ctx)
+
 	stageTimer := time.Now()
 	batch, err := b.EncodingStreamer.CreateBatch()
 	if err != nil {
 		return err
 	}
 	log.Trace("[batcher] CreateBatch took", "duration", time.Since(stageTimer))
+	notifyObserver(log, b.Observer, "BatchCreated", func(o EventObserver) error {
+		return o.OnBatchCreated(ctx, batch.BatchHeader, batch.BlobMetadata)
+	})
+
+	// The dispatcher and the aggregator must use the operator state at the reference block the batch is pinned to
+	if err := batch.State.ValidateReferenceBlock(batch.BatchHeader.ReferenceBlockNumber); err != nil {
+		_ = b.handleFailure(ctx, batch.BlobMetadata, FailReferenceBlockMismatch)
+		return err
+	}
+	// Operators registered after the reference block have no operator info at the reference block, so the batch can't
+	// be dispersed to them
+	if err := batch.State.ValidateOperatorRegistrations(); err != nil {
+		_ = b.handleFailure(ctx, batch.BlobMetadata, FailOperatorNotRegistered)
+		return fmt.Errorf("HandleSingleBatch: invalid reference block %d: %w", batch.BatchHeader.ReferenceBlockNumber, err)
+	}
+
+	// Blobs requiring a quorum without operators at the reference block can't be attested, and aggregating the signatures
+	// of an empty quorum fails for the whole batch. Such blobs are failed and the other blobs are left to the next batch.
+	if blobs, quorums := getBlobsWithEmptyQuorums(batch.State, batch.BlobMetadata); len(blobs) > 0 {
+		_ = b.handleFailure(ctx, blobs, FailEmptyQuorum)
+		return fmt.Errorf("HandleSingleBatch: %d blobs require quorums %v without operators at block %d", len(blobs), quorums, batch.BatchHeader.ReferenceBlockNumber)
+	}
+
+	// Operators reject the whole batch if a commitment of a blob doesn't match its length commitment. Such blobs are
+	// failed and the other blobs are left to the next batch.
+	if b.VerifyCommitmentsBeforeDispatch {
+		blobs, err := b.getBlobsWithInconsistentCommitments(batch)
+		if err != nil {
+			return fmt.Errorf("HandleSingleBatch: failed to verify commitments: %w", err)
+		}
+		if len(blobs) > 0 {
+			_ = b.handleFailure(ctx, blobs, FailInconsistentCommitment)
+			return fmt.Errorf("HandleSingleBatch: %d blobs have a commitment inconsistent with their length commitment", len(blobs))
+		}
+	}
+
+	// The confirmBatch calldata grows with the quorums of the batch, so a batch that could exceed MaxConfirmBatchCalldata
+	// is split by quorums before it is dispersed, and the sub-batches are dispatched and confirmed independently
+	subBatches, err := b.splitBatch(batch)
+	if err != nil {
+		_ = b.handleFailure(ctx, batch.BlobMetadata, FailConfirmBatch)
+		return fmt.Errorf("HandleSingleBatch: error splitting batch: %w", err)
+	}
+	if len(subBatches) == 1 {
+		return b.dispatchBatch(ctx, subBatches[0])
+	}
+	log.Info("[batcher] confirmBatch calldata could exceed the limit, splitting the batch", "limit", b.MaxConfirmBatchCalldata, "numBlobs", len(batch.BlobMetadata), "numSubBatches", len(subBatches))
+	var errs []error
+	for _, subBatch := range subBatches {
+		if err := b.dispatchBatch(ctx, subBatch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// dispatchBatch disperses the batch to the operators, aggregates their signatures and sends the confirmBatch transaction.
+func (b *Batcher) dispatchBatch(ctx context.Context, batch *batch) error {
+	log := b.logger
 
 	// Dispatch encoded batch
 	log.Trace("[batcher] Dispatching encoded batch...")
-	stageTimer = time.Now()
+	stageTimer := time.Now()
+	b.lastBatchDispatchedAt = stageTimer
 	update := b.Dispatcher.DisperseBatch(ctx, batch.State, batch.EncodedBlobs, batch.BatchHeader)
+	update, err := b.applyDispersalFailurePolicy(ctx, batch, update)
+	if err != nil {
+		_ = b.handleFailure(ctx, batch.BlobMetadata, FailDispersal)
+		return fmt.Errorf("HandleSingleBatch: batch aborted: %w", err)
+	}
 	log.Trace("[batcher] DisperseBatch took", "duration", time.Since(stageTimer))
+	notifyObserver(log, b.Observer, "BatchDispatched", func(o EventObserver) error {
+		return o.OnBatchDispatched(ctx, batch.BatchHeader, batch.BlobMetadata)
+	})
 
-	// Get the batch header hash
-	log.Trace("[batcher] Getting batch header hash...")
-	headerHash, err := batch.BatchHeader.GetBatchHeaderHash()
+	// Get the message operators sign, which is the batch header hash under the default signing scheme
+	log.Trace("[batcher] Getting batch signing message...")
+	signingMessage, err := batch.BatchHeader.GetSigningMessage()
 	if err != nil {
 		_ = b.handleFailure(ctx, batch.BlobMetadata, FailBatchHeaderHash)
 		return fmt.Errorf("HandleSingleBatch: error getting batch header hash: %w", err)
@@ -417,25 +1241,48 @@ func (b *Batcher) HandleSingleBatch(ctx context.Context) error {
 	// Aggregate the signatures
 	log.Trace("[batcher] Aggregating signatures...")
 
-	// construct quorumParams
+	// construct quorumParams. The quorums are sorted in ascending order, the order of the quorum numbers of the batch
+	// header onchain, so that the aggregate keys of the quorums align with them
 	quorumIDs := make([]core.QuorumID, 0, len(batch.State.AggKeys))
 	for quorumID := range batch.State.Operators {
 		quorumIDs = append(quorumIDs, quorumID)
 	}
+	sort.Slice(quorumIDs, func(i, j int) bool {
+		return quorumIDs[i] < quorumIDs[j]
+	})
 
 	stageTimer = time.Now()
-	aggSig, err := b.Aggregator.AggregateSignatures(ctx, batch.State, quorumIDs, headerHash, update)
+	aggSig, err := b.Aggregator.AggregateSignatures(ctx, batch.State, quorumIDs, batch.BatchHeader.SigningScheme, signingMessage, update)
 	if err != nil {
 		_ = b.handleFailure(ctx, batch.BlobMetadata, FailAggregateSignatures)
 		return fmt.Errorf("HandleSingleBatch: error aggregating signatures: %w", err)
 	}
+	if b.VerifyAggregateSignature {
+		if err := b.Aggregator.VerifyAggregation(batch.State, quorumIDs, signingMessage, aggSig); err != nil {
+			_ = b.handleFailure(ctx, batch.BlobMetadata, FailInvalidAggregateSignature)
+			return fmt.Errorf("HandleSingleBatch: aggregate signature failed verification: %w", err)
+		}
+	}
 	log.Trace("[batcher] AggregateSignatures took", "duration", time.Since(stageTimer))
 	b.Metrics.ObserveLatency("AggregateSignatures", float64(time.Since(stageTimer).Milliseconds()))
 	b.Metrics.UpdateAttestation(len(batch.State.IndexedOperators), len(aggSig.NonSigners), aggSig.QuorumResults)
+	notifyObserver(log, b.Observer, "BatchAggregated", func(o EventObserver) error {
+		return o.OnBatchAggregated(ctx, batch.BatchHeader, aggSig)
+	})
 	for _, quorumResult := range aggSig.QuorumResults {
 		log.Info("[batcher] Aggregated quorum result", "quorumID", quorumResult.QuorumID, "percentSigned", quorumResult.PercentSigned)
 	}
 
+	if b.MaxNonSigners > 0 && uint(len(aggSig.NonSigners)) > b.MaxNonSigners {
+		_ = b.handleFailure(ctx, batch.BlobMetadata, FailTooManyNonSigners)
+		return fmt.Errorf("HandleSingleBatch: %w: %d non-signers exceed the limit of %d", ErrTooManyNonSigners, len(aggSig.NonSigners), b.MaxNonSigners)
+	}
+
+	if unattested := unattestedCriticalQuorums(b.CriticalQuorums, b.CriticalQuorumThreshold, aggSig.QuorumResults, batch.BlobHeaders); len(unattested) > 0 {
+		_ = b.handleFailure(ctx, batch.BlobMetadata, FailCriticalQuorum)
+		return fmt.Errorf("HandleSingleBatch: %w: quorums %v", ErrCriticalQuorumNotAttested, unattested)
+	}
+
 	numPassed := numBlobsAttested(aggSig.QuorumResults, batch.BlobHeaders)
 	// TODO(mooselumph): Determine whether to confirm the batch based on the number of successes
 	if numPassed == 0 {
@@ -451,17 +1298,23 @@ func (b *Batcher) HandleSingleBatch(ctx context.Context) error {
 		_ = b.handleFailure(ctx, batch.BlobMetadata, FailConfirmBatch)
 		return fmt.Errorf("HandleSingleBatch: error building confirmBatch transaction: %w", err)
 	}
-	err = b.TransactionManager.ProcessTransaction(ctx, NewTxnRequest(txn, "confirmBatch", big.NewInt(0), confirmationMetadata{
+	metadata := confirmationMetadata{
 		batchHeader: batch.BatchHeader,
 		blobs:       batch.BlobMetadata,
 		blobHeaders: batch.BlobHeaders,
 		merkleTree:  batch.MerkleTree,
 		aggSig:      aggSig,
-	}))
+		state:       batch.State,
+	}
+	// the batch is tracked before the transaction is sent, since its receipt can be returned right after
+	b.addPendingBatch(&metadata, b.EncodingStreamer.Now())
+	err = b.TransactionManager.ProcessTransaction(ctx, NewTxnRequest(txn, "confirmBatch", big.NewInt(0), metadata))
 	if err != nil {
+		b.takePendingBatch(metadata)
 		_ = b.handleFailure(ctx, batch.BlobMetadata, FailConfirmBatch)
 		return fmt.Errorf("HandleSingleBatch: error sending confirmBatch transaction: %w", err)
 	} else {
+		b.addInFlightBatch(batch.BatchHeader.ReferenceBlockNumber)
 		for _, metadata := range batch.BlobMetadata {
 			err = b.EncodingStreamer.MarkBlobPendingConfirmation(metadata)
 			if err != nil {
@@ -473,14 +1326,6 @@ func (b *Batcher) HandleSingleBatch(ctx context.Context) error {
 	return nil
 }
 
-func serializeProof(proof *merkletree.Proof) []byte {
-	proofBytes := make([]byte, 0)
-	for _, hash := range proof.Hashes {
-		proofBytes = append(proofBytes, hash[:]...)
-	}
-	return proofBytes
-}
-
 func (b *Batcher) parseBatchIDFromReceipt(ctx context.Context, txReceipt *types.Receipt) (uint32, error) {
 	if len(txReceipt.Logs) == 0 {
 		return 0, fmt.Errorf("failed to get transaction receipt with logs")
@@ -493,35 +1338,41 @@ func (b *Batcher) parseBatchIDFromReceipt(ctx context.Context, txReceipt *types.
 		b.logger.Debug("[getBatchIDFromReceipt] ", "sigHash", log.Topics[0].Hex())
 
 		if log.Topics[0] == common.BatchConfirmedEventSigHash {
-			smAbi, err := abi.JSON(bytes.NewReader(common.ServiceManagerAbi))
-			if err != nil {
-				return 0, fmt.Errorf("failed to parse ServiceManager ABI: %w", err)
-			}
-			eventAbi, err := smAbi.EventByID(common.BatchConfirmedEventSigHash)
-			if err != nil {
-				return 0, fmt.Errorf("failed to parse BatchConfirmed event ABI: %w", err)
-			}
-			unpackedData, err := eventAbi.Inputs.Unpack(log.Data)
-			if err != nil {
-				return 0, fmt.Errorf("failed to unpack BatchConfirmed log data: %w", err)
-			}
-
-			// There should be exactly one input in the data field, batchId.
-			// Labs/eigenda/blob/master/contracts/src/interfaces/IEigenDAServiceManager.sol#L17
-			if len(unpackedData) != 1 {
-				return 0, fmt.Errorf("BatchConfirmed log should contain exactly 1 inputs. Found %d", len(unpackedData))
-			}
-			return unpackedData[0].(uint32), nil
+			return parseBatchConfirmedLogData(log.Data)
 		}
 	}
 	return 0, fmt.Errorf("failed to find BatchConfirmed log from the transaction")
 }
 
+// parseBatchConfirmedLogData returns the batch ID from the data of a BatchConfirmed log
+func parseBatchConfirmedLogData(data []byte) (uint32, error) {
+	smAbi, err := abi.JSON(bytes.NewReader(common.ServiceManagerAbi))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse ServiceManager ABI: %w", err)
+	}
+	eventAbi, err := smAbi.EventByID(common.BatchConfirmedEventSigHash)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse BatchConfirmed event ABI: %w", err)
+	}
+	unpackedData, err := eventAbi.Inputs.Unpack(data)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unpack BatchConfirmed log data: %w", err)
+	}
+
+	// There should be exactly one input in the data field, batchId.
+	// Labs/eigenda/blob/master/contracts/src/interfaces/IEigenDAServiceManager.sol#L17
+	if len(unpackedData) != 1 {
+		return 0, fmt.Errorf("BatchConfirmed log should contain exactly 1 inputs. Found %d", len(unpackedData))
+	}
+	return unpackedData[0].(uint32), nil
+}
+
 func (b *Batcher) getBatchID(ctx context.Context, txReceipt *types.Receipt) (uint32, error) {
-	const (
-		maxRetries = 4
-		baseDelay  = 1 * time.Second
-	)
+	const baseDelay = 1 * time.Second
+	maxRetries := int(b.MaxReceiptParseRetries)
+	if maxRetries == 0 {
+		maxRetries = defaultMaxReceiptParseRetries
+	}
 	var (
 		batchID uint32
 		err     error
@@ -533,10 +1384,21 @@ func (b *Batcher) getBatchID(ctx context.Context, txReceipt *types.Receipt) (uin
 	}
 
 	txHash := txReceipt.TxHash
+	var waited time.Duration
 	for i := 0; i < maxRetries; i++ {
-		retrySec := math.Pow(2, float64(i))
-		b.logger.Warn("failed to get transaction receipt, retrying...", "retryIn", retrySec, "err", err)
-		time.Sleep(time.Duration(retrySec) * baseDelay)
+		retryIn := time.Duration(math.Pow(2, float64(i))) * baseDelay
+		if waited+retryIn > maxReceiptRetryTotalDelay {
+			break
+		}
+		waited += retryIn
+		b.logger.Warn("failed to get transaction receipt, retrying...", "retryIn", retryIn, "err", err)
+		timer := time.NewTimer(retryIn)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, ctx.Err()
+		case <-timer.C:
+		}
 
 		txReceipt, err = b.ethClient.TransactionReceipt(ctx, txHash)
 		if err != nil {
@@ -550,7 +1412,7 @@ func (b *Batcher) getBatchID(ctx context.Context, txReceipt *types.Receipt) (uin
 	}
 
 	if err != nil {
-		b.logger.Warn("failed to get transaction receipt after retries", "numRetries", maxRetries, "err", err)
+		b.logger.Warn("failed to get transaction receipt after retries", "numRetries", maxRetries, "waited", waited, "err", err)
 		return 0, err
 	}
 
@@ -576,15 +1438,71 @@ func numBlobsAttested(signedQuorums map[core.QuorumID]*core.QuorumResult, header
 	return numPassed
 }
 
+// unattestedCriticalQuorums returns the critical quorums required by some blob of the batch whose percentage of signed
+// stake is below threshold. A quorum without a result is treated as not attested.
+func unattestedCriticalQuorums(criticalQuorums []core.QuorumID, threshold uint8, signedQuorums map[core.QuorumID]*core.QuorumResult, headers []*core.BlobHeader) []core.QuorumID {
+	if len(criticalQuorums) == 0 {
+		return nil
+	}
+	critical := make(map[core.QuorumID]bool, len(criticalQuorums))
+	for _, quorumID := range criticalQuorums {
+		critical[quorumID] = true
+	}
+
+	unattested := make([]core.QuorumID, 0)
+	seen := make(map[core.QuorumID]bool)
+	for _, header := range headers {
+		for _, quorum := range header.QuorumInfos {
+			if !critical[quorum.QuorumID] || seen[quorum.QuorumID] {
+				continue
+			}
+			seen[quorum.QuorumID] = true
+			result, ok := signedQuorums[quorum.QuorumID]
+			if !ok || result == nil || result.PercentSigned < threshold {
+				unattested = append(unattested, quorum.QuorumID)
+			}
+		}
+	}
+	sort.Slice(unattested, func(i, j int) bool { return unattested[i] < unattested[j] })
+	return unattested
+}
+
+// isBlobAttested returns whether every quorum required by the blob has met the blob's quorum threshold. A quorum
+// without a result is treated as not attested.
 func isBlobAttested(signedQuorums map[core.QuorumID]*core.QuorumResult, header *core.BlobHeader) bool {
 	for _, quorum := range header.QuorumInfos {
-		if signedQuorums[quorum.QuorumID].PercentSigned < quorum.QuorumThreshold {
+		result, ok := signedQuorums[quorum.QuorumID]
+		if !ok || result == nil || result.PercentSigned < quorum.QuorumThreshold {
 			return false
 		}
 	}
 	return true
 }
 
+// getAttestedQuorums returns whether each quorum required by the blob has met the blob's quorum threshold
+func getAttestedQuorums(signedQuorums map[core.QuorumID]*core.QuorumResult, header *core.BlobHeader) map[core.QuorumID]bool {
+	attested := make(map[core.QuorumID]bool, len(header.QuorumInfos))
+	for _, quorum := range header.QuorumInfos {
+		result, ok := signedQuorums[quorum.QuorumID]
+		attested[quorum.QuorumID] = ok && result != nil && result.PercentSigned >= quorum.QuorumThreshold
+	}
+	return attested
+}
+
+// getAssignmentInfos returns the chunk assignment info of each quorum of the blob in the given operator state
+func (b *Batcher) getAssignmentInfos(state *core.IndexedOperatorState, metadata *disperser.BlobMetadata, header *core.BlobHeader) (map[core.QuorumID]core.AssignmentInfo, error) {
+	blobLength := core.GetBlobLength(metadata.RequestMetadata.BlobSize)
+	infos := make(map[core.QuorumID]core.AssignmentInfo, len(header.QuorumInfos))
+	for _, quorum := range header.QuorumInfos {
+		_, info, err := b.AssignmentCoordinator.GetAssignments(state.OperatorState, blobLength, quorum)
+		if err != nil {
+			return nil, err
+		}
+		infos[quorum.QuorumID] = info
+	}
+	return infos, nil
+}
+
 func (b *Batcher) signalLiveness() {
 	select {
 	case b.HeartbeatChan <- time.Now():
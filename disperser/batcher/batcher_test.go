@@ -2,10 +2,15 @@ package batcher_test
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -22,9 +27,16 @@ import (
 	"github.com/Layr-Labs/eigenda/disperser/common/inmem"
 	dmock "github.com/Layr-Labs/eigenda/disperser/mock"
 	"github.com/Layr-Labs/eigenda/encoding/kzgrs"
+	"github.com/ethereum/go-ethereum"
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gammazero/workerpool"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -39,6 +51,7 @@ type batcherComponents struct {
 	encoderClient    *disperser.LocalEncoderClient
 	encodingStreamer *bat.EncodingStreamer
 	ethClient        *cmock.MockEthClient
+	chainData        *coremock.ChainDataMock
 }
 
 // makeTestEncoder makes an encoder currently using the only supported backend.
@@ -66,6 +79,12 @@ func makeTestBlob(securityParams []*core.SecurityParam) core.Blob {
 }
 
 func makeBatcher(t *testing.T) (*batcherComponents, *bat.Batcher, func() []time.Time) {
+	return makeBatcherWithConfig(t, nil, nil)
+}
+
+// makeBatcherWithConfig makes a batcher whose config is adjusted by configure and whose encoder client is wrapped by
+// wrapEncoder, if they are not nil
+func makeBatcherWithConfig(t *testing.T, configure func(*bat.Config), wrapEncoder func(disperser.EncoderClient) disperser.EncoderClient) (*batcherComponents, *bat.Batcher, func() []time.Time) {
 	// Common Components
 	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
 	assert.NoError(t, err)
@@ -97,6 +116,9 @@ func makeBatcher(t *testing.T) (*batcherComponents, *bat.Batcher, func() []time.
 		SRSOrder:                 3000,
 		MaxNumRetriesPerBlob:     2,
 	}
+	if configure != nil {
+		configure(&config)
+	}
 	timeoutConfig := bat.TimeoutConfig{
 		EncodingTimeout:    10 * time.Second,
 		AttestationTimeout: 10 * time.Second,
@@ -111,7 +133,11 @@ func makeBatcher(t *testing.T) (*batcherComponents, *bat.Batcher, func() []time.
 	ethClient := &cmock.MockEthClient{}
 	txnManager := mock.NewTxnManager()
 
-	b, err := bat.NewBatcher(config, timeoutConfig, blobStore, dispatcher, cst, asgn, encoderClient, agg, ethClient, finalizer, transactor, txnManager, logger, metrics, handleBatchLivenessChan)
+	var batcherEncoderClient disperser.EncoderClient = encoderClient
+	if wrapEncoder != nil {
+		batcherEncoderClient = wrapEncoder(encoderClient)
+	}
+	b, err := bat.NewBatcher(config, timeoutConfig, blobStore, dispatcher, cst, asgn, batcherEncoderClient, agg, ethClient, finalizer, transactor, txnManager, logger, metrics, handleBatchLivenessChan)
 	assert.NoError(t, err)
 
 	var heartbeatsReceived []time.Time
@@ -136,6 +162,7 @@ func makeBatcher(t *testing.T) (*batcherComponents, *bat.Batcher, func() []time.
 			encoderClient:    encoderClient,
 			encodingStreamer: b.EncodingStreamer,
 			ethClient:        ethClient,
+			chainData:        cst,
 		}, b, func() []time.Time {
 			close(doneListening) // Stop the goroutine listening to heartbeats
 			return heartbeatsReceived
@@ -177,6 +204,7 @@ func TestBatcherIterations(t *testing.T) {
 	txHash := gethcommon.HexToHash("0x1234")
 	blockNumber := big.NewInt(123)
 	receipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
 		Logs: []*types.Log{
 			{
 				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
@@ -252,265 +280,207 @@ func TestBatcherIterations(t *testing.T) {
 	assert.Equal(t, disperser.Confirmed, meta1.BlobStatus)
 }
 
-func TestBlobFailures(t *testing.T) {
+func TestBatcherStoresAssignmentInfo(t *testing.T) {
 	blob := makeTestBlob([]*core.SecurityParam{{
 		QuorumID:           0,
 		AdversaryThreshold: 80,
 		QuorumThreshold:    100,
 	}})
+	components, batcher, _ := makeBatcher(t)
 
-	components, batcher, getHeartbeats := makeBatcher(t)
-
-	defer func() {
-		heartbeats := getHeartbeats()
-		assert.Equal(t, 3, len(heartbeats), "Expected heartbeats, but none were received")
-	}()
-
-	confirmationErr := fmt.Errorf("error")
+	logData, err := hex.DecodeString("00000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000")
+	assert.NoError(t, err)
+	receipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{
+			{
+				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+				Data:   logData,
+			},
+		},
+		BlockNumber: big.NewInt(123),
+		TxHash:      gethcommon.HexToHash("0x1234"),
+	}
 	blobStore := components.blobStore
 	ctx := context.Background()
-	requestedAt, blobKey := queueBlob(t, ctx, &blob, blobStore)
+	_, blobKey := queueBlob(t, ctx, &blob, blobStore)
 
-	// Start the batcher
 	out := make(chan bat.EncodingResultOrStatus)
-	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
 	assert.NoError(t, err)
 	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
 	assert.NoError(t, err)
+	encodedResult, err := components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey, 0)
+	assert.NoError(t, err)
 
 	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
 	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
 	components.txnManager.On("ProcessTransaction").Return(nil)
 
-	// Test with receipt response with error
 	err = batcher.HandleSingleBatch(ctx)
 	assert.NoError(t, err)
 	assert.Greater(t, len(components.txnManager.Requests), 0)
 	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
-		Receipt:  nil,
-		Err:      confirmationErr,
-		Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
-	})
-	assert.ErrorIs(t, err, confirmationErr)
-
-	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
-	assert.NoError(t, err)
-	assert.Equal(t, blobKey, meta.GetBlobKey())
-	assert.Equal(t, requestedAt, meta.RequestMetadata.RequestedAt)
-	// should be retried
-	assert.Equal(t, disperser.Processing, meta.BlobStatus)
-	assert.Equal(t, uint(1), meta.NumRetries)
-	metadatas, err := blobStore.GetBlobMetadataByStatus(ctx, disperser.Processing)
-	assert.NoError(t, err)
-	assert.Len(t, metadatas, 1)
-	encodedResult, err := components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey, 0)
-	assert.Error(t, err)
-	assert.Nil(t, encodedResult)
-
-	// Test with receipt response with no block number
-	err = components.encodingStreamer.RequestEncoding(ctx, out)
-	assert.NoError(t, err)
-	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
-	assert.NoError(t, err)
-	components.encodingStreamer.ReferenceBlockNumber = 10
-	err = batcher.HandleSingleBatch(ctx)
-	assert.NoError(t, err)
-	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
-		Receipt: &types.Receipt{
-			TxHash: gethcommon.HexToHash("0x1234"),
-		},
+		Receipt:  receipt,
 		Err:      nil,
 		Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
 	})
-	assert.ErrorContains(t, err, "error getting transaction receipt block number")
-
-	meta, err = blobStore.GetBlobMetadata(ctx, blobKey)
-	assert.NoError(t, err)
-
-	// should be retried again
-	assert.Equal(t, disperser.Processing, meta.BlobStatus)
-	assert.Equal(t, uint(2), meta.NumRetries)
-
-	// Try again
-	err = components.encodingStreamer.RequestEncoding(ctx, out)
 	assert.NoError(t, err)
-	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
-	assert.NoError(t, err)
-	components.encodingStreamer.ReferenceBlockNumber = 10
-	err = batcher.HandleSingleBatch(ctx)
+	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
 	assert.NoError(t, err)
+	assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
 
-	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
-		Receipt: &types.Receipt{
-			TxHash: gethcommon.HexToHash("0x1234"),
-		},
-		Err:      nil,
-		Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
-	})
-	assert.ErrorContains(t, err, "error getting transaction receipt block number")
-
-	meta, err = blobStore.GetBlobMetadata(ctx, blobKey)
+	// the stored assignment info yields the encoding params used at dispersal
+	assignmentInfo, ok := meta.ConfirmationInfo.AssignmentInfos[0]
+	assert.True(t, ok)
+	params, err := core.GetEncodingParams(encodedResult.BlobQuorumInfo.ChunkLength, uint(assignmentInfo.TotalChunks))
 	assert.NoError(t, err)
-
-	// should not be retried again
-	assert.Equal(t, disperser.Failed, meta.BlobStatus)
-	assert.Equal(t, uint(2), meta.NumRetries)
+	assert.Equal(t, uint(len(encodedResult.Chunks)), params.NumChunks)
+	assert.Equal(t, uint(len(encodedResult.Chunks[0].Coeffs)), params.ChunkLength)
 }
 
-// TestBlobRetry tests that the blob that has been dispersed to DA nodes but is pending onchain confirmation isn't re-dispersed.
-func TestBlobRetry(t *testing.T) {
-	blob := makeTestBlob([]*core.SecurityParam{{
-		QuorumID:           0,
-		AdversaryThreshold: 80,
-		QuorumThreshold:    100,
-	}})
+// partialQuorumAggregator wraps a signature aggregator and lowers the signed percentage of the given quorum
+type partialQuorumAggregator struct {
+	core.SignatureAggregator
+	quorumID      core.QuorumID
+	percentSigned uint8
+}
 
-	components, batcher, getHeartbeats := makeBatcher(t)
+func (a *partialQuorumAggregator) AggregateSignatures(ctx context.Context, state *core.IndexedOperatorState, quorumIDs []core.QuorumID, scheme core.SigningSchemeID, message [32]byte, messageChan chan core.SignerMessage) (*core.SignatureAggregation, error) {
+	aggSig, err := a.SignatureAggregator.AggregateSignatures(ctx, state, quorumIDs, scheme, message, messageChan)
+	if err != nil {
+		return nil, err
+	}
+	if result, ok := aggSig.QuorumResults[a.quorumID]; ok {
+		result.PercentSigned = a.percentSigned
+	}
+	return aggSig, nil
+}
 
-	defer func() {
-		heartbeats := getHeartbeats()
-		assert.Equal(t, 1, len(heartbeats), "Expected heartbeats, but none were received")
-	}()
+// recordingAggregator records the quorums, operator states and results of the signature aggregations
+type recordingAggregator struct {
+	core.SignatureAggregator
 
-	blobStore := components.blobStore
-	ctx := context.Background()
-	_, blobKey := queueBlob(t, ctx, &blob, blobStore)
+	quorumIDs [][]core.QuorumID
+	states    []*core.IndexedOperatorState
+	aggSigs   []*core.SignatureAggregation
+}
 
-	// Start the batcher
-	out := make(chan bat.EncodingResultOrStatus)
-	err := components.encodingStreamer.RequestEncoding(ctx, out)
-	assert.NoError(t, err)
-	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
-	assert.NoError(t, err)
+func (a *recordingAggregator) AggregateSignatures(ctx context.Context, state *core.IndexedOperatorState, quorumIDs []core.QuorumID, scheme core.SigningSchemeID, message [32]byte, messageChan chan core.SignerMessage) (*core.SignatureAggregation, error) {
+	aggSig, err := a.SignatureAggregator.AggregateSignatures(ctx, state, quorumIDs, scheme, message, messageChan)
+	if err != nil {
+		return nil, err
+	}
+	a.quorumIDs = append(a.quorumIDs, append([]core.QuorumID{}, quorumIDs...))
+	a.states = append(a.states, state)
+	a.aggSigs = append(a.aggSigs, aggSig)
+	return aggSig, nil
+}
 
-	encodedResult, err := components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey, 0)
-	assert.NoError(t, err)
-	assert.Equal(t, encodedResult.Status, bat.PendingDispersal)
+func TestBatcherSortsQuorums(t *testing.T) {
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+	aggregator := &recordingAggregator{SignatureAggregator: batcher.Aggregator}
+	batcher.Aggregator = aggregator
 
 	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
 	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
 	components.txnManager.On("ProcessTransaction").Return(nil)
+	blobStore := components.blobStore
+	ctx := context.Background()
 
-	err = batcher.HandleSingleBatch(ctx)
-	assert.NoError(t, err)
-
-	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
-	assert.NoError(t, err)
-	assert.Equal(t, disperser.Processing, meta.BlobStatus)
-	encodedResult, err = components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey, 0)
-	assert.NoError(t, err)
-	assert.Equal(t, encodedResult.Status, bat.PendingConfirmation)
-
-	err = components.encodingStreamer.RequestEncoding(ctx, out)
-	assert.NoError(t, err)
-	timer := time.NewTimer(1 * time.Second)
-	select {
-	case <-out:
-		t.Fatal("shouldn't have picked up any blobs to encode")
-	case <-timer.C:
-	}
-	batch, err := components.encodingStreamer.CreateBatch()
-	assert.ErrorContains(t, err, "no encoded results")
-	assert.Nil(t, batch)
+	// the quorums of a batch are iterated from a map, so the order would vary across batches if they weren't sorted
+	numBatches := 10
+	for i := 0; i < numBatches; i++ {
+		blob := makeTestBlob([]*core.SecurityParam{
+			{
+				QuorumID:           1,
+				AdversaryThreshold: 70,
+				QuorumThreshold:    100,
+			},
+			{
+				QuorumID:           0,
+				AdversaryThreshold: 80,
+				QuorumThreshold:    100,
+			},
+		})
+		queueBlob(t, ctx, &blob, blobStore)
+
+		out := make(chan bat.EncodingResultOrStatus)
+		err := components.encodingStreamer.RequestEncoding(ctx, out)
+		assert.NoError(t, err)
+		for j := 0; j < 2; j++ {
+			err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+			assert.NoError(t, err)
+		}
 
-	// Shouldn't pick up any blobs to encode
-	components.encodingStreamer.ReferenceBlockNumber = 12
-	err = components.encodingStreamer.RequestEncoding(ctx, out)
-	assert.NoError(t, err)
-	timer = time.NewTimer(1 * time.Second)
-	select {
-	case <-out:
-		t.Fatal("shouldn't have picked up any blobs to encode")
-	case <-timer.C:
+		err = batcher.HandleSingleBatch(ctx)
+		assert.NoError(t, err)
 	}
 
-	batch, err = components.encodingStreamer.CreateBatch()
-	assert.ErrorContains(t, err, "no encoded results")
-	assert.Nil(t, batch)
-	_, err = components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey, 0)
-	assert.NoError(t, err)
-
-	meta, err = blobStore.GetBlobMetadata(ctx, blobKey)
-	assert.NoError(t, err)
-	assert.Equal(t, disperser.Processing, meta.BlobStatus)
-
-	// Trigger a retry
-	confirmationErr := fmt.Errorf("error")
-	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
-		Receipt:  nil,
-		Err:      confirmationErr,
-		Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
-	})
-	assert.ErrorIs(t, err, confirmationErr)
-
-	components.encodingStreamer.ReferenceBlockNumber = 14
-	// Should pick up the blob to encode
-	err = components.encodingStreamer.RequestEncoding(ctx, out)
-	assert.NoError(t, err)
-	timer = time.NewTimer(1 * time.Second)
-	var res bat.EncodingResultOrStatus
-	select {
-	case res = <-out:
-	case <-timer.C:
-		t.Fatal("should have picked up the blob to encode")
+	assert.Len(t, aggregator.quorumIDs, numBatches)
+	for i, quorumIDs := range aggregator.quorumIDs {
+		assert.Equal(t, []core.QuorumID{0, 1}, quorumIDs)
+		// the aggregate keys of the quorums are in the same order
+		for j, quorumID := range quorumIDs {
+			assert.Equal(t, aggregator.states[i].AggKeys[quorumID], aggregator.aggSigs[i].QuorumAggPubKeys[j])
+		}
 	}
-	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, res)
-	assert.NoError(t, err)
-	encodedResult, err = components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey, 0)
-	assert.NoError(t, err)
-	assert.Equal(t, encodedResult.Status, bat.PendingDispersal)
 }
 
-func TestRetryTxnReceipt(t *testing.T) {
-	var err error
-	blob := makeTestBlob([]*core.SecurityParam{{
+func TestBatcherPartialQuorumAttestation(t *testing.T) {
+	blob1 := makeTestBlob([]*core.SecurityParam{
+		{
+			QuorumID:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+		{
+			QuorumID:           1,
+			AdversaryThreshold: 70,
+			QuorumThreshold:    100,
+		},
+	})
+	blob2 := makeTestBlob([]*core.SecurityParam{{
 		QuorumID:           0,
 		AdversaryThreshold: 80,
 		QuorumThreshold:    100,
 	}})
 	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
 
-	defer func() {
-		heartbeats := getHeartbeats()
-		assert.NotEmpty(t, heartbeats, "Expected heartbeats, but none were received")
-
-		// Further assertions can be made here, such as checking the number of heartbeats
-		// or validating the time intervals between them if needed.
-	}()
-	invalidReceipt := &types.Receipt{
-		Logs: []*types.Log{
-			{
-				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
-				Data:   []byte{}, // empty data
-			},
-		},
-		BlockNumber: big.NewInt(123),
+	// quorum 1 falls short of the threshold while quorum 0 is fully signed
+	batcher.Aggregator = &partialQuorumAggregator{
+		SignatureAggregator: batcher.Aggregator,
+		quorumID:            1,
+		percentSigned:       50,
 	}
-	// should be encoding 3 and 0
-	validLogData, err := hex.DecodeString("00000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000")
+
+	logData, err := hex.DecodeString("00000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000")
 	assert.NoError(t, err)
-	validReceipt := &types.Receipt{
+	receipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
 		Logs: []*types.Log{
 			{
 				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
-				Data:   validLogData,
+				Data:   logData,
 			},
 		},
 		BlockNumber: big.NewInt(123),
+		TxHash:      gethcommon.HexToHash("0x1234"),
 	}
-
-	components.ethClient.On("TransactionReceipt").Return(invalidReceipt, nil).Twice()
-	components.ethClient.On("TransactionReceipt").Return(validReceipt, nil).Once()
 	blobStore := components.blobStore
 	ctx := context.Background()
-	requestedAt, blobKey := queueBlob(t, ctx, &blob, blobStore)
+	_, blobKey1 := queueBlob(t, ctx, &blob1, blobStore)
+	_, blobKey2 := queueBlob(t, ctx, &blob2, blobStore)
 
-	// Start the batcher
 	out := make(chan bat.EncodingResultOrStatus)
 	err = components.encodingStreamer.RequestEncoding(ctx, out)
 	assert.NoError(t, err)
-	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
-	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+		assert.NoError(t, err)
+	}
 
 	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
 	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
@@ -518,18 +488,2284 @@ func TestRetryTxnReceipt(t *testing.T) {
 
 	err = batcher.HandleSingleBatch(ctx)
 	assert.NoError(t, err)
+	assert.Greater(t, len(components.txnManager.Requests), 0)
 	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
-		Receipt:  invalidReceipt,
+		Receipt:  receipt,
 		Err:      nil,
 		Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
 	})
 	assert.NoError(t, err)
-	// Check that the blob was processed
-	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+
+	meta1, err := blobStore.GetBlobMetadata(ctx, blobKey1)
 	assert.NoError(t, err)
-	assert.Equal(t, blobKey, meta.GetBlobKey())
-	assert.Equal(t, requestedAt, meta.RequestMetadata.RequestedAt)
-	assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
-	assert.Equal(t, meta.ConfirmationInfo.BatchID, uint32(3))
-	components.ethClient.AssertNumberOfCalls(t, "TransactionReceipt", 3)
+	assert.Equal(t, disperser.InsufficientSignatures, meta1.BlobStatus)
+	assert.NotNil(t, meta1.ConfirmationInfo)
+	assert.Equal(t, map[core.QuorumID]bool{0: true, 1: false}, meta1.ConfirmationInfo.AttestedQuorums)
+	assert.Equal(t, uint8(100), meta1.ConfirmationInfo.QuorumResults[0].PercentSigned)
+
+	meta2, err := blobStore.GetBlobMetadata(ctx, blobKey2)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Confirmed, meta2.BlobStatus)
+	assert.Equal(t, map[core.QuorumID]bool{0: true}, meta2.ConfirmationInfo.AttestedQuorums)
+}
+
+func TestBatcherCriticalQuorumNotAttested(t *testing.T) {
+	blob1 := makeTestBlob([]*core.SecurityParam{
+		{
+			QuorumID:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+		{
+			QuorumID:           1,
+			AdversaryThreshold: 70,
+			QuorumThreshold:    100,
+		},
+	})
+	blob2 := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+
+	setup := func(t *testing.T, criticalQuorumThreshold uint8) (*batcherComponents, *bat.Batcher, disperser.BlobKey, disperser.BlobKey, func() []time.Time) {
+		components, batcher, getHeartbeats := makeBatcherWithConfig(t, func(config *bat.Config) {
+			config.CriticalQuorums = []core.QuorumID{1}
+			config.CriticalQuorumThreshold = criticalQuorumThreshold
+		}, nil)
+
+		// the critical quorum 1 is half signed while quorum 0 is fully signed
+		batcher.Aggregator = &partialQuorumAggregator{
+			SignatureAggregator: batcher.Aggregator,
+			quorumID:            1,
+			percentSigned:       50,
+		}
+
+		ctx := context.Background()
+		_, blobKey1 := queueBlob(t, ctx, &blob1, components.blobStore)
+		_, blobKey2 := queueBlob(t, ctx, &blob2, components.blobStore)
+
+		out := make(chan bat.EncodingResultOrStatus)
+		err := components.encodingStreamer.RequestEncoding(ctx, out)
+		assert.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+			assert.NoError(t, err)
+		}
+
+		txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+		components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+		components.txnManager.On("ProcessTransaction").Return(nil)
+		return components, batcher, blobKey1, blobKey2, getHeartbeats
+	}
+
+	t.Run("below the critical quorum threshold", func(t *testing.T) {
+		components, batcher, blobKey1, blobKey2, getHeartbeats := setup(t, 67)
+		defer getHeartbeats()
+		ctx := context.Background()
+
+		// the whole batch is failed, including the blob that isn't dispersed to the critical quorum
+		err := batcher.HandleSingleBatch(ctx)
+		assert.ErrorIs(t, err, bat.ErrCriticalQuorumNotAttested)
+		components.transactor.AssertNotCalled(t, "BuildConfirmBatchTxn")
+		assert.Len(t, components.txnManager.Requests, 0)
+		for _, blobKey := range []disperser.BlobKey{blobKey1, blobKey2} {
+			meta, err := components.blobStore.GetBlobMetadata(ctx, blobKey)
+			assert.NoError(t, err)
+			assert.Equal(t, disperser.Processing, meta.BlobStatus)
+			assert.Equal(t, uint(1), meta.NumRetries)
+		}
+	})
+
+	t.Run("at the critical quorum threshold", func(t *testing.T) {
+		components, batcher, blobKey1, blobKey2, getHeartbeats := setup(t, 50)
+		defer getHeartbeats()
+		ctx := context.Background()
+
+		// the batch is confirmed, and only the blob short of its own quorum threshold has insufficient signatures
+		err := batcher.HandleSingleBatch(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, components.txnManager.Requests, 1)
+		logData, err := hex.DecodeString("00000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000")
+		assert.NoError(t, err)
+		receipt := &types.Receipt{
+			Status: types.ReceiptStatusSuccessful,
+			Logs: []*types.Log{
+				{
+					Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+					Data:   logData,
+				},
+			},
+			BlockNumber: big.NewInt(123),
+			TxHash:      gethcommon.HexToHash("0x1234"),
+		}
+		err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+			Receipt:  receipt,
+			Err:      nil,
+			Metadata: components.txnManager.Requests[0].Metadata,
+		})
+		assert.NoError(t, err)
+
+		meta1, err := components.blobStore.GetBlobMetadata(ctx, blobKey1)
+		assert.NoError(t, err)
+		assert.Equal(t, disperser.InsufficientSignatures, meta1.BlobStatus)
+		meta2, err := components.blobStore.GetBlobMetadata(ctx, blobKey2)
+		assert.NoError(t, err)
+		assert.Equal(t, disperser.Confirmed, meta2.BlobStatus)
+	})
+}
+
+func TestBatcherRedisperseUnattestedQuorums(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{
+		{
+			QuorumID:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+		{
+			QuorumID:           1,
+			AdversaryThreshold: 70,
+			QuorumThreshold:    100,
+		},
+	})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+	batcher.RedisperseUnattestedQuorums = true
+	aggregator := batcher.Aggregator
+	dispatcher := &recordingDispatcher{Dispatcher: batcher.Dispatcher}
+	batcher.Dispatcher = dispatcher
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	// a blob fully attested on quorum 0 gets the first batch confirmed
+	otherBlob := makeTestBlob([]*core.SecurityParam{blob.RequestHeader.SecurityParams[0]})
+
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, blobStore)
+	_, otherBlobKey := queueBlob(t, ctx, &otherBlob, blobStore)
+
+	disperseAndConfirm := func(numQuorums int, batchID byte) {
+		out := make(chan bat.EncodingResultOrStatus)
+		err := components.encodingStreamer.RequestEncoding(ctx, out)
+		assert.NoError(t, err)
+		for i := 0; i < numQuorums; i++ {
+			err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+			assert.NoError(t, err)
+		}
+
+		err = batcher.HandleSingleBatch(ctx)
+		assert.NoError(t, err)
+		logData := make([]byte, 64)
+		logData[31] = batchID
+		err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+			Receipt: &types.Receipt{
+				Status: types.ReceiptStatusSuccessful,
+				Logs: []*types.Log{
+					{
+						Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+						Data:   logData,
+					},
+				},
+				BlockNumber: big.NewInt(123),
+				TxHash:      gethcommon.HexToHash("0x1234"),
+			},
+			Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
+		})
+		assert.NoError(t, err)
+	}
+
+	// quorum 1 falls short of the threshold while quorum 0 is fully signed
+	batcher.Aggregator = &partialQuorumAggregator{
+		SignatureAggregator: aggregator,
+		quorumID:            1,
+		percentSigned:       50,
+	}
+	disperseAndConfirm(3, 3)
+
+	// the partially attested blob is retried instead of being marked as having insufficient signatures
+	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, meta.BlobStatus)
+	assert.Equal(t, uint(1), meta.NumRetries)
+	meta, err = blobStore.GetBlobMetadata(ctx, otherBlobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
+
+	// the retry is dispersed to all the quorums of the blob, which are now fully signed
+	batcher.Aggregator = aggregator
+	disperseAndConfirm(2, 4)
+	assert.Len(t, dispatcher.blobs, 2)
+	assert.Len(t, dispatcher.blobs[1], 1)
+	for _, blobMessage := range dispatcher.blobs[1][0] {
+		assert.Len(t, blobMessage.BlobHeader.QuorumInfos, 2)
+	}
+
+	meta, err = blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
+	assert.Equal(t, uint32(4), meta.ConfirmationInfo.BatchID)
+	assert.Equal(t, map[core.QuorumID]bool{0: true, 1: true}, meta.ConfirmationInfo.AttestedQuorums)
+	assert.Equal(t, uint8(100), meta.ConfirmationInfo.QuorumResults[0].PercentSigned)
+	assert.Equal(t, uint8(100), meta.ConfirmationInfo.QuorumResults[1].PercentSigned)
+	assert.Len(t, meta.ConfirmationInfo.BlobQuorumInfos, 2)
+	assert.Equal(t, core.QuorumID(0), meta.ConfirmationInfo.BlobQuorumInfos[0].QuorumID)
+	assert.Equal(t, core.QuorumID(1), meta.ConfirmationInfo.BlobQuorumInfos[1].QuorumID)
+}
+
+// invalidSignatureAggregator wraps a signature aggregator and tampers with the aggregate signature
+type invalidSignatureAggregator struct {
+	core.SignatureAggregator
+}
+
+func (a *invalidSignatureAggregator) AggregateSignatures(ctx context.Context, state *core.IndexedOperatorState, quorumIDs []core.QuorumID, scheme core.SigningSchemeID, message [32]byte, messageChan chan core.SignerMessage) (*core.SignatureAggregation, error) {
+	aggSig, err := a.SignatureAggregator.AggregateSignatures(ctx, state, quorumIDs, scheme, message, messageChan)
+	if err != nil {
+		return nil, err
+	}
+	aggSig.AggSignature.Add(aggSig.AggSignature.G1Point)
+	return aggSig, nil
+}
+
+func TestBatcherVerifyAggregateSignature(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+	batcher.VerifyAggregateSignature = true
+	aggregator := batcher.Aggregator
+	batcher.Aggregator = &invalidSignatureAggregator{SignatureAggregator: aggregator}
+
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	// the batch isn't confirmed with an invalid aggregate signature
+	err = batcher.HandleSingleBatch(ctx)
+	assert.ErrorIs(t, err, core.ErrAggSigNotValid)
+	components.transactor.AssertNotCalled(t, "BuildConfirmBatchTxn")
+	assert.Len(t, components.txnManager.Requests, 0)
+	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, meta.BlobStatus)
+	assert.Equal(t, uint(1), meta.NumRetries)
+
+	// a valid aggregate signature passes the verification
+	batcher.Aggregator = aggregator
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	components.transactor.AssertNumberOfCalls(t, "BuildConfirmBatchTxn", 1)
+	assert.Len(t, components.txnManager.Requests, 1)
+}
+
+func TestBatcherVerifyCommitmentsBeforeDispatch(t *testing.T) {
+	securityParams := []*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}}
+	blob1 := makeTestBlob(securityParams)
+	blob2 := makeTestBlob(securityParams)
+	blob2.Data = gettysburgAddressBytes[:500]
+	components, batcher, getHeartbeats := makeBatcherWithConfig(t, func(config *bat.Config) {
+		config.VerifyCommitmentsBeforeDispatch = true
+	}, nil)
+	defer getHeartbeats()
+	enc, err := makeTestEncoder()
+	assert.NoError(t, err)
+	batcher.CommitmentVerifier = enc
+
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey1 := queueBlob(t, ctx, &blob1, blobStore)
+	_, blobKey2 := queueBlob(t, ctx, &blob2, blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+		assert.NoError(t, err)
+	}
+
+	// the length commitment of the first blob is replaced by the length commitment of the second blob, which has a
+	// different length
+	result1, err := components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey1, 0)
+	assert.NoError(t, err)
+	result2, err := components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey2, 0)
+	assert.NoError(t, err)
+	result1.Commitment.LengthCommitment = result2.Commitment.LengthCommitment
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	// the blob with the inconsistent commitment is failed before the batch is dispatched
+	err = batcher.HandleSingleBatch(ctx)
+	assert.ErrorContains(t, err, "inconsistent")
+	components.transactor.AssertNotCalled(t, "BuildConfirmBatchTxn")
+	meta1, err := blobStore.GetBlobMetadata(ctx, blobKey1)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, meta1.BlobStatus)
+	assert.Equal(t, uint(1), meta1.NumRetries)
+	meta2, err := blobStore.GetBlobMetadata(ctx, blobKey2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), meta2.NumRetries)
+
+	// the other blob is confirmed in the next batch, without the excluded blob
+	components.encodingStreamer.ReferenceBlockNumber = 10
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	components.transactor.AssertNumberOfCalls(t, "BuildConfirmBatchTxn", 1)
+	assert.Len(t, components.txnManager.Requests, 1)
+	_, err = components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey1, 0)
+	assert.ErrorContains(t, err, "no such key")
+	result2, err = components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, bat.PendingConfirmation, result2.Status)
+}
+
+// nonSignersAggregator wraps a signature aggregator and reports the given number of non-signers
+type nonSignersAggregator struct {
+	core.SignatureAggregator
+	numNonSigners int
+}
+
+func (a *nonSignersAggregator) AggregateSignatures(ctx context.Context, state *core.IndexedOperatorState, quorumIDs []core.QuorumID, scheme core.SigningSchemeID, message [32]byte, messageChan chan core.SignerMessage) (*core.SignatureAggregation, error) {
+	aggSig, err := a.SignatureAggregator.AggregateSignatures(ctx, state, quorumIDs, scheme, message, messageChan)
+	if err != nil {
+		return nil, err
+	}
+	nonSigner := core.NewG1Point(big.NewInt(1), big.NewInt(2))
+	aggSig.NonSigners = make([]*core.G1Point, a.numNonSigners)
+	for i := range aggSig.NonSigners {
+		aggSig.NonSigners[i] = nonSigner
+	}
+	return aggSig, nil
+}
+
+func TestBatcherMaxNonSigners(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+	batcher.MaxNonSigners = 100
+	batcher.Aggregator = &nonSignersAggregator{SignatureAggregator: batcher.Aggregator, numNonSigners: 1_000_000}
+
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	// the batch is failed before a confirmBatch transaction is built over the huge non-signer set
+	err = batcher.HandleSingleBatch(ctx)
+	assert.ErrorIs(t, err, bat.ErrTooManyNonSigners)
+	components.transactor.AssertNotCalled(t, "BuildConfirmBatchTxn")
+	assert.Len(t, components.txnManager.Requests, 0)
+	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, meta.BlobStatus)
+	assert.Equal(t, uint(1), meta.NumRetries)
+	m := &dto.Metric{}
+	assert.NoError(t, batcher.Metrics.BlobRetried.WithLabelValues(string(bat.FailTooManyNonSigners)).Write(m))
+	assert.Equal(t, float64(1), m.GetCounter().GetValue())
+}
+
+func TestBatcherInFlightReferenceBlocks(t *testing.T) {
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+	inFlightReferenceBlocks := func() float64 {
+		m := &dto.Metric{}
+		assert.NoError(t, batcher.Metrics.InFlightReferenceBlocks.Write(m))
+		return m.GetGauge().GetValue()
+	}
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+	blobStore := components.blobStore
+	ctx := context.Background()
+
+	// dispatch a batch at each of two reference blocks
+	for _, referenceBlockNumber := range []uint{10, 12} {
+		blob := makeTestBlob([]*core.SecurityParam{{
+			QuorumID:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		}})
+		queueBlob(t, ctx, &blob, blobStore)
+
+		components.encodingStreamer.ReferenceBlockNumber = referenceBlockNumber
+		out := make(chan bat.EncodingResultOrStatus)
+		err := components.encodingStreamer.RequestEncoding(ctx, out)
+		assert.NoError(t, err)
+		err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+		assert.NoError(t, err)
+
+		err = batcher.HandleSingleBatch(ctx)
+		assert.NoError(t, err)
+	}
+	assert.Len(t, components.txnManager.Requests, 2)
+	assert.Equal(t, float64(2), inFlightReferenceBlocks())
+
+	// the gauge goes down as the batches resolve
+	confirmationErr := errors.New("error")
+	err := batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Err:      confirmationErr,
+		Metadata: components.txnManager.Requests[0].Metadata,
+	})
+	assert.ErrorIs(t, err, confirmationErr)
+	assert.Equal(t, float64(1), inFlightReferenceBlocks())
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Err:      confirmationErr,
+		Metadata: components.txnManager.Requests[1].Metadata,
+	})
+	assert.ErrorIs(t, err, confirmationErr)
+	assert.Equal(t, float64(0), inFlightReferenceBlocks())
+}
+
+func TestBatcherBlobFailureMetrics(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+	batcher.VerifyAggregateSignature = true
+	batcher.Aggregator = &invalidSignatureAggregator{SignatureAggregator: batcher.Aggregator}
+
+	counterValue := func(counter *prometheus.CounterVec) float64 {
+		m := &dto.Metric{}
+		assert.NoError(t, counter.WithLabelValues(string(bat.FailInvalidAggregateSignature)).Write(m))
+		return m.GetCounter().GetValue()
+	}
+
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, blobStore)
+	out := make(chan bat.EncodingResultOrStatus)
+
+	// the blob is retried while it's under the retry cap, and then fails permanently
+	for i, expected := range []struct {
+		retried float64
+		failed  float64
+		status  disperser.BlobStatus
+	}{
+		{retried: 1, failed: 0, status: disperser.Processing},
+		{retried: 2, failed: 0, status: disperser.Processing},
+		{retried: 2, failed: 1, status: disperser.Failed},
+	} {
+		err := components.encodingStreamer.RequestEncoding(ctx, out)
+		assert.NoError(t, err)
+		err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+		assert.NoError(t, err)
+
+		err = batcher.HandleSingleBatch(ctx)
+		assert.ErrorIs(t, err, core.ErrAggSigNotValid, "attempt %d", i)
+		assert.Equal(t, expected.retried, counterValue(batcher.Metrics.BlobRetried), "attempt %d", i)
+		assert.Equal(t, expected.failed, counterValue(batcher.Metrics.BlobFailed), "attempt %d", i)
+		meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.status, meta.BlobStatus, "attempt %d", i)
+	}
+}
+
+func TestBatcherRevertedConfirmation(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+
+	// the receipt carries the BatchConfirmed event, but the transaction reverted
+	logData, err := hex.DecodeString("00000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000")
+	assert.NoError(t, err)
+	receipt := &types.Receipt{
+		Status: types.ReceiptStatusFailed,
+		Logs: []*types.Log{
+			{
+				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+				Data:   logData,
+			},
+		},
+		BlockNumber: big.NewInt(123),
+		TxHash:      gethcommon.HexToHash("0x1234"),
+	}
+
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, blobStore)
+	out := make(chan bat.EncodingResultOrStatus)
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt:  receipt,
+		Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
+	})
+	assert.ErrorContains(t, err, "reverted")
+
+	// the blob is retried instead of confirmed
+	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, meta.BlobStatus)
+	assert.Equal(t, uint(1), meta.NumRetries)
+	assert.Nil(t, meta.ConfirmationInfo)
+	m := &dto.Metric{}
+	assert.NoError(t, batcher.Metrics.BlobRetried.WithLabelValues(string(bat.FailConfirmBatchReverted)).Write(m))
+	assert.Equal(t, float64(1), m.GetCounter().GetValue())
+}
+
+// emptyQuorumChainState wraps a chain state and removes the operators of the given quorum once empty is set
+type emptyQuorumChainState struct {
+	core.IndexedChainState
+	quorumID core.QuorumID
+	empty    atomic.Bool
+}
+
+func (s *emptyQuorumChainState) GetIndexedOperatorState(ctx context.Context, blockNumber uint, quorums []core.QuorumID) (*core.IndexedOperatorState, error) {
+	state, err := s.IndexedChainState.GetIndexedOperatorState(ctx, blockNumber, quorums)
+	if err != nil || !s.empty.Load() {
+		return state, err
+	}
+	if _, ok := state.Operators[s.quorumID]; ok {
+		state.Operators[s.quorumID] = map[core.OperatorID]*core.OperatorInfo{}
+		state.Totals[s.quorumID] = &core.OperatorInfo{Stake: big.NewInt(0)}
+	}
+	return state, nil
+}
+
+func TestBatcherEmptyQuorum(t *testing.T) {
+	blob1 := makeTestBlob([]*core.SecurityParam{
+		{
+			QuorumID:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+		{
+			QuorumID:           1,
+			AdversaryThreshold: 70,
+			QuorumThreshold:    100,
+		},
+	})
+	blob2 := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+
+	// quorum 1 has no operators when the batch is created
+	chainState := &emptyQuorumChainState{IndexedChainState: components.chainData, quorumID: 1}
+	streamer, err := bat.NewEncodingStreamer(bat.StreamerConfig{
+		SRSOrder:                 3000,
+		EncodingRequestTimeout:   batcher.PullInterval,
+		EncodingQueueLimit:       100,
+		MaxBlobsToFetchFromStore: 10,
+	}, components.blobStore, chainState, components.encoderClient, &core.StdAssignmentCoordinator{}, bat.NewEncodedSizeNotifier(make(chan struct{}, 1), 100*1024*1024), workerpool.New(1), batcher.Metrics.EncodingStreamerMetrics, &cmock.Logger{})
+	assert.NoError(t, err)
+	batcher.EncodingStreamer = streamer
+
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey1 := queueBlob(t, ctx, &blob1, blobStore)
+	_, blobKey2 := queueBlob(t, ctx, &blob2, blobStore)
+
+	streamer.ReferenceBlockNumber = 10
+	out := make(chan bat.EncodingResultOrStatus)
+	err = streamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		err = streamer.ProcessEncodedBlobs(ctx, <-out)
+		assert.NoError(t, err)
+	}
+	chainState.empty.Store(true)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	// the blob requiring the empty quorum is failed without aggregating signatures
+	err = batcher.HandleSingleBatch(ctx)
+	assert.ErrorContains(t, err, "without operators")
+	components.transactor.AssertNotCalled(t, "BuildConfirmBatchTxn")
+	meta1, err := blobStore.GetBlobMetadata(ctx, blobKey1)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, meta1.BlobStatus)
+	assert.Equal(t, uint(1), meta1.NumRetries)
+	meta2, err := blobStore.GetBlobMetadata(ctx, blobKey2)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, meta2.BlobStatus)
+	assert.Equal(t, uint(0), meta2.NumRetries)
+
+	// the other blob is confirmed in the next batch without being encoded again
+	streamer.ReferenceBlockNumber = 10
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	components.transactor.AssertNumberOfCalls(t, "BuildConfirmBatchTxn", 1)
+	assert.Len(t, components.txnManager.Requests, 1)
+}
+
+// unregisteredOperatorChainState simulates a reference block preceding the registration of an operator of the quorum,
+// which then registers after the reference block
+type unregisteredOperatorChainState struct {
+	core.IndexedChainState
+	quorumID     core.QuorumID
+	unregistered atomic.Bool
+}
+
+func (s *unregisteredOperatorChainState) GetIndexedOperatorState(ctx context.Context, blockNumber uint, quorums []core.QuorumID) (*core.IndexedOperatorState, error) {
+	state, err := s.IndexedChainState.GetIndexedOperatorState(ctx, blockNumber, quorums)
+	if err != nil || !s.unregistered.Load() {
+		return state, err
+	}
+	indexedOperators := make(map[core.OperatorID]*core.IndexedOperatorInfo, len(state.IndexedOperators))
+	for id, info := range state.IndexedOperators {
+		indexedOperators[id] = info
+	}
+	for id := range state.Operators[s.quorumID] {
+		info := *indexedOperators[id]
+		info.RegistrationBlockNumber = blockNumber + 1
+		indexedOperators[id] = &info
+		break
+	}
+	state.IndexedOperators = indexedOperators
+	return state, nil
+}
+
+func TestBatcherReferenceBlockBeforeOperatorRegistration(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+
+	chainState := &unregisteredOperatorChainState{IndexedChainState: components.chainData, quorumID: 0}
+	streamer, err := bat.NewEncodingStreamer(bat.StreamerConfig{
+		SRSOrder:                 3000,
+		EncodingRequestTimeout:   batcher.PullInterval,
+		EncodingQueueLimit:       100,
+		MaxBlobsToFetchFromStore: 10,
+	}, components.blobStore, chainState, components.encoderClient, &core.StdAssignmentCoordinator{}, bat.NewEncodedSizeNotifier(make(chan struct{}, 1), 100*1024*1024), workerpool.New(1), batcher.Metrics.EncodingStreamerMetrics, &cmock.Logger{})
+	assert.NoError(t, err)
+	batcher.EncodingStreamer = streamer
+
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, components.blobStore)
+
+	streamer.ReferenceBlockNumber = 10
+	out := make(chan bat.EncodingResultOrStatus)
+	err = streamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = streamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+	chainState.unregistered.Store(true)
+
+	// the batch isn't dispersed and the blob is retried
+	err = batcher.HandleSingleBatch(ctx)
+	assert.ErrorIs(t, err, core.ErrOperatorNotRegistered)
+	assert.ErrorContains(t, err, "invalid reference block 10")
+	components.transactor.AssertNotCalled(t, "BuildConfirmBatchTxn")
+	meta, err := components.blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, meta.BlobStatus)
+	assert.Equal(t, uint(1), meta.NumRetries)
+}
+
+func TestBlobFailures(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+
+	components, batcher, getHeartbeats := makeBatcher(t)
+
+	defer func() {
+		heartbeats := getHeartbeats()
+		assert.Equal(t, 3, len(heartbeats), "Expected heartbeats, but none were received")
+	}()
+
+	confirmationErr := fmt.Errorf("error")
+	blobStore := components.blobStore
+	ctx := context.Background()
+	requestedAt, blobKey := queueBlob(t, ctx, &blob, blobStore)
+
+	// Start the batcher
+	out := make(chan bat.EncodingResultOrStatus)
+	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	// Test with receipt response with error
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	assert.Greater(t, len(components.txnManager.Requests), 0)
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt:  nil,
+		Err:      confirmationErr,
+		Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
+	})
+	assert.ErrorIs(t, err, confirmationErr)
+
+	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, blobKey, meta.GetBlobKey())
+	assert.Equal(t, requestedAt, meta.RequestMetadata.RequestedAt)
+	// should be retried
+	assert.Equal(t, disperser.Processing, meta.BlobStatus)
+	assert.Equal(t, uint(1), meta.NumRetries)
+	metadatas, err := blobStore.GetBlobMetadataByStatus(ctx, disperser.Processing)
+	assert.NoError(t, err)
+	assert.Len(t, metadatas, 1)
+	encodedResult, err := components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey, 0)
+	assert.Error(t, err)
+	assert.Nil(t, encodedResult)
+
+	// Test with receipt response with no block number
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+	components.encodingStreamer.ReferenceBlockNumber = 10
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt: &types.Receipt{
+			Status: types.ReceiptStatusSuccessful,
+			TxHash: gethcommon.HexToHash("0x1234"),
+		},
+		Err:      nil,
+		Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
+	})
+	assert.ErrorContains(t, err, "error getting transaction receipt block number")
+
+	meta, err = blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+
+	// should be retried again
+	assert.Equal(t, disperser.Processing, meta.BlobStatus)
+	assert.Equal(t, uint(2), meta.NumRetries)
+
+	// Try again
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+	components.encodingStreamer.ReferenceBlockNumber = 10
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt: &types.Receipt{
+			Status: types.ReceiptStatusSuccessful,
+			TxHash: gethcommon.HexToHash("0x1234"),
+		},
+		Err:      nil,
+		Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
+	})
+	assert.ErrorContains(t, err, "error getting transaction receipt block number")
+
+	meta, err = blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+
+	// should not be retried again
+	assert.Equal(t, disperser.Failed, meta.BlobStatus)
+	assert.Equal(t, uint(2), meta.NumRetries)
+}
+
+// TestBlobRetry tests that the blob that has been dispersed to DA nodes but is pending onchain confirmation isn't re-dispersed.
+func TestBlobRetry(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+
+	components, batcher, getHeartbeats := makeBatcher(t)
+
+	defer func() {
+		heartbeats := getHeartbeats()
+		assert.Equal(t, 1, len(heartbeats), "Expected heartbeats, but none were received")
+	}()
+
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, blobStore)
+
+	// Start the batcher
+	out := make(chan bat.EncodingResultOrStatus)
+	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	encodedResult, err := components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, encodedResult.Status, bat.PendingDispersal)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+
+	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, meta.BlobStatus)
+	encodedResult, err = components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, encodedResult.Status, bat.PendingConfirmation)
+
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	timer := time.NewTimer(1 * time.Second)
+	select {
+	case <-out:
+		t.Fatal("shouldn't have picked up any blobs to encode")
+	case <-timer.C:
+	}
+	batch, err := components.encodingStreamer.CreateBatch()
+	assert.ErrorContains(t, err, "no encoded results")
+	assert.Nil(t, batch)
+
+	// Shouldn't pick up any blobs to encode
+	components.encodingStreamer.ReferenceBlockNumber = 12
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	timer = time.NewTimer(1 * time.Second)
+	select {
+	case <-out:
+		t.Fatal("shouldn't have picked up any blobs to encode")
+	case <-timer.C:
+	}
+
+	batch, err = components.encodingStreamer.CreateBatch()
+	assert.ErrorContains(t, err, "no encoded results")
+	assert.Nil(t, batch)
+	_, err = components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey, 0)
+	assert.NoError(t, err)
+
+	meta, err = blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, meta.BlobStatus)
+
+	// Trigger a retry
+	confirmationErr := fmt.Errorf("error")
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt:  nil,
+		Err:      confirmationErr,
+		Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
+	})
+	assert.ErrorIs(t, err, confirmationErr)
+
+	components.encodingStreamer.ReferenceBlockNumber = 14
+	// Should pick up the blob to encode
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	timer = time.NewTimer(1 * time.Second)
+	var res bat.EncodingResultOrStatus
+	select {
+	case res = <-out:
+	case <-timer.C:
+		t.Fatal("should have picked up the blob to encode")
+	}
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, res)
+	assert.NoError(t, err)
+	encodedResult, err = components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, encodedResult.Status, bat.PendingDispersal)
+}
+
+func TestRetryTxnReceipt(t *testing.T) {
+	var err error
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+
+	defer func() {
+		heartbeats := getHeartbeats()
+		assert.NotEmpty(t, heartbeats, "Expected heartbeats, but none were received")
+
+		// Further assertions can be made here, such as checking the number of heartbeats
+		// or validating the time intervals between them if needed.
+	}()
+	invalidReceipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{
+			{
+				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+				Data:   []byte{}, // empty data
+			},
+		},
+		BlockNumber: big.NewInt(123),
+	}
+	// should be encoding 3 and 0
+	validLogData, err := hex.DecodeString("00000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000")
+	assert.NoError(t, err)
+	validReceipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{
+			{
+				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+				Data:   validLogData,
+			},
+		},
+		BlockNumber: big.NewInt(123),
+	}
+
+	components.ethClient.On("TransactionReceipt").Return(invalidReceipt, nil).Twice()
+	components.ethClient.On("TransactionReceipt").Return(validReceipt, nil).Once()
+	blobStore := components.blobStore
+	ctx := context.Background()
+	requestedAt, blobKey := queueBlob(t, ctx, &blob, blobStore)
+
+	// Start the batcher
+	out := make(chan bat.EncodingResultOrStatus)
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt:  invalidReceipt,
+		Err:      nil,
+		Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
+	})
+	assert.NoError(t, err)
+	// Check that the blob was processed
+	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, blobKey, meta.GetBlobKey())
+	assert.Equal(t, requestedAt, meta.RequestMetadata.RequestedAt)
+	assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
+	assert.Equal(t, meta.ConfirmationInfo.BatchID, uint32(3))
+	components.ethClient.AssertNumberOfCalls(t, "TransactionReceipt", 3)
+}
+
+func TestBatchIDPending(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	batcher.MaxReceiptParseRetries = 1
+
+	defer getHeartbeats()
+	invalidReceipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{
+			{
+				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+				Data:   []byte{}, // empty data
+			},
+		},
+		BlockNumber: big.NewInt(123),
+		TxHash:      gethcommon.HexToHash("0x1234"),
+	}
+	// batch ID 3
+	validLogData, err := hex.DecodeString("00000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000")
+	assert.NoError(t, err)
+	validReceipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{
+			{
+				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+				Data:   validLogData,
+			},
+		},
+		BlockNumber: big.NewInt(123),
+		TxHash:      gethcommon.HexToHash("0x1234"),
+	}
+	components.ethClient.On("TransactionReceipt").Return(invalidReceipt, nil).Twice()
+	components.ethClient.On("TransactionReceipt").Return(validReceipt, nil).Once()
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, components.txnManager.Requests, 1)
+
+	// the batch ID is never parsed from the receipt
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt:  invalidReceipt,
+		Err:      nil,
+		Metadata: components.txnManager.Requests[0].Metadata,
+	})
+	assert.ErrorIs(t, err, bat.ErrBatchIDPending)
+	components.ethClient.AssertNumberOfCalls(t, "TransactionReceipt", 1)
+
+	// the blob is recorded as pending its batch ID, and isn't retried nor dispersed again
+	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.BatchIDPending, meta.BlobStatus)
+	assert.Equal(t, uint(0), meta.NumRetries)
+	assert.Equal(t, invalidReceipt.TxHash, meta.ConfirmationInfo.ConfirmationTxnHash)
+	_, err = components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey, 0)
+	assert.Error(t, err)
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = batcher.HandleSingleBatch(ctx)
+	assert.ErrorContains(t, err, "no encoded results")
+	assert.Len(t, components.txnManager.Requests, 1)
+
+	// the blob is kept pending until its batch ID is parsed
+	err = batcher.ReconcilePendingBatchIDs(ctx)
+	assert.ErrorContains(t, err, "error parsing batch ID")
+	meta, err = blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.BatchIDPending, meta.BlobStatus)
+
+	err = batcher.ReconcilePendingBatchIDs(ctx)
+	assert.NoError(t, err)
+	meta, err = blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
+	assert.Equal(t, uint32(3), meta.ConfirmationInfo.BatchID)
+	assert.Equal(t, uint(0), meta.NumRetries)
+
+	err = batcher.ReconcilePendingBatchIDs(ctx)
+	assert.NoError(t, err)
+	components.ethClient.AssertNumberOfCalls(t, "TransactionReceipt", 3)
+}
+
+func TestBatchIDPendingRetryDelayCapped(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	batcher.MaxReceiptParseRetries = 100
+
+	defer getHeartbeats()
+	invalidReceipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{
+			{
+				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+				Data:   []byte{}, // empty data
+			},
+		},
+		BlockNumber: big.NewInt(123),
+		TxHash:      gethcommon.HexToHash("0x1234"),
+	}
+	components.ethClient.On("TransactionReceipt").Return(invalidReceipt, nil)
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, components.txnManager.Requests, 1)
+
+	// the receipt is fetched again until the retries have waited for 1s, 2s and 4s, since waiting another 8s would
+	// exceed the cap, and the blob is handed to the reconciliation
+	start := time.Now()
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt:  invalidReceipt,
+		Err:      nil,
+		Metadata: components.txnManager.Requests[0].Metadata,
+	})
+	assert.ErrorIs(t, err, bat.ErrBatchIDPending)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	components.ethClient.AssertNumberOfCalls(t, "TransactionReceipt", 3)
+
+	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.BatchIDPending, meta.BlobStatus)
+}
+
+func TestBatchIDPendingTransactionNotFound(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	batcher.MaxReceiptParseRetries = 1
+
+	defer getHeartbeats()
+	invalidReceipt := &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		Logs:        []*types.Log{},
+		BlockNumber: big.NewInt(123),
+		TxHash:      gethcommon.HexToHash("0x1234"),
+	}
+	components.ethClient.On("TransactionReceipt").Return(invalidReceipt, nil).Once()
+	components.ethClient.On("TransactionReceipt").Return(nil, ethereum.NotFound).Once()
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt:  invalidReceipt,
+		Err:      nil,
+		Metadata: components.txnManager.Requests[0].Metadata,
+	})
+	assert.ErrorIs(t, err, bat.ErrBatchIDPending)
+
+	// the confirmation transaction is no longer onchain, so the blob is retried
+	err = batcher.ReconcilePendingBatchIDs(ctx)
+	assert.NoError(t, err)
+	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, meta.BlobStatus)
+	assert.Equal(t, uint(1), meta.NumRetries)
+}
+
+// dispatchTimeObserver records the time each batch is dispatched
+type dispatchTimeObserver struct {
+	bat.EventObserver
+
+	mu           sync.Mutex
+	dispatchedAt []time.Time
+}
+
+func (o *dispatchTimeObserver) OnBatchDispatched(ctx context.Context, header *core.BatchHeader, blobs []*disperser.BlobMetadata) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.dispatchedAt = append(o.dispatchedAt, time.Now())
+	return nil
+}
+
+func (o *dispatchTimeObserver) getDispatchedAt() []time.Time {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]time.Time{}, o.dispatchedAt...)
+}
+
+func TestMinBatchInterval(t *testing.T) {
+	blob1 := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	blob2 := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           1,
+		AdversaryThreshold: 70,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, _ := makeBatcher(t)
+	// only the size notifier triggers batches in this test
+	batcher.PullInterval = time.Hour
+	minBatchInterval := 2 * time.Second
+	batcher.MinBatchInterval = minBatchInterval
+	observer := &dispatchTimeObserver{EventObserver: bat.NewNoopEventObserver()}
+	batcher.Observer = observer
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+	components.txnManager.On("ReceiptChan").Return(make(chan *bat.ReceiptOrErr))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := batcher.Start(ctx)
+	assert.NoError(t, err)
+	notifier := components.encodingStreamer.EncodedSizeNotifier
+
+	queueBlob(t, ctx, &blob1, components.blobStore)
+	assert.Eventually(t, func() bool {
+		count, _ := components.encodingStreamer.EncodedBlobstore.GetEncodedResultSize()
+		return count == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	notifier.Notify <- struct{}{}
+	assert.Eventually(t, func() bool {
+		return len(observer.getDispatchedAt()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// fire the notifier again right after the first batch
+	_, blobKey2 := queueBlob(t, ctx, &blob2, components.blobStore)
+	assert.Eventually(t, func() bool {
+		_, err := components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey2, 1)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	notifier.Notify <- struct{}{}
+	assert.Eventually(t, func() bool {
+		return len(observer.getDispatchedAt()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	dispatchedAt := observer.getDispatchedAt()
+	assert.Len(t, dispatchedAt, 2)
+	assert.GreaterOrEqual(t, dispatchedAt[1].Sub(dispatchedAt[0]), minBatchInterval)
+}
+
+func TestMaxBlobQueueAge(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, _ := makeBatcher(t)
+	// neither the pull interval nor the batch size limit is reached in this test
+	batcher.PullInterval = time.Hour
+	observer := &dispatchTimeObserver{EventObserver: bat.NewNoopEventObserver()}
+	batcher.Observer = observer
+	streamer := components.encodingStreamer
+	streamer.MaxBlobQueueAge = time.Minute
+	var clockOffset atomic.Int64
+	streamer.Now = func() time.Time {
+		return time.Now().Add(time.Duration(clockOffset.Load()))
+	}
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+	components.txnManager.On("ReceiptChan").Return(make(chan *bat.ReceiptOrErr))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := batcher.Start(ctx)
+	assert.NoError(t, err)
+
+	queueBlob(t, ctx, &blob, components.blobStore)
+	assert.Eventually(t, func() bool {
+		count, _ := streamer.EncodedBlobstore.GetEncodedResultSize()
+		return count == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	// the blob hasn't waited long enough yet
+	assert.False(t, streamer.CheckBlobQueueAge())
+	assert.Len(t, observer.getDispatchedAt(), 0)
+
+	clockOffset.Store(int64(2 * time.Minute))
+	assert.Eventually(t, func() bool {
+		return len(observer.getDispatchedAt()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// failingConfirmationStore wraps a blob store and fails to mark the given blob as confirmed
+type failingConfirmationStore struct {
+	disperser.BlobStore
+	blobKey disperser.BlobKey
+}
+
+func (s *failingConfirmationStore) MarkBlobConfirmed(ctx context.Context, existingMetadata *disperser.BlobMetadata, confirmationInfo *disperser.ConfirmationInfo) (*disperser.BlobMetadata, error) {
+	if existingMetadata.GetBlobKey() == s.blobKey {
+		return nil, fmt.Errorf("failed to mark blob %s confirmed", s.blobKey.String())
+	}
+	return s.BlobStore.MarkBlobConfirmed(ctx, existingMetadata, confirmationInfo)
+}
+
+func (s *failingConfirmationStore) BatchMarkBlobsConfirmed(ctx context.Context, writes []disperser.ConfirmationWrite) error {
+	var failedKeys []disperser.BlobKey
+	for _, write := range writes {
+		if _, err := s.MarkBlobConfirmed(ctx, write.Metadata, write.ConfirmationInfo); err != nil {
+			failedKeys = append(failedKeys, write.Metadata.GetBlobKey())
+		}
+	}
+	if len(failedKeys) > 0 {
+		return &disperser.BatchConfirmationError{FailedKeys: failedKeys, Err: fmt.Errorf("failed to mark blob %s confirmed", s.blobKey.String())}
+	}
+	return nil
+}
+
+// confirmationWriteRecordingStore wraps a blob store and records the number of confirmations of each batched write
+type confirmationWriteRecordingStore struct {
+	disperser.BlobStore
+	mu         sync.Mutex
+	writeSizes []int
+}
+
+func (s *confirmationWriteRecordingStore) BatchMarkBlobsConfirmed(ctx context.Context, writes []disperser.ConfirmationWrite) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writeSizes = append(s.writeSizes, len(writes))
+	return s.BlobStore.BatchMarkBlobsConfirmed(ctx, writes)
+}
+
+func TestBatcherBatchedConfirmationWrites(t *testing.T) {
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+	batcher.ConfirmationWriteBatchSize = 2
+	batcher.ConfirmationWriteConcurrency = 2
+
+	blobStore := components.blobStore
+	ctx := context.Background()
+	numBlobs := 5
+	blobKeys := make([]disperser.BlobKey, numBlobs)
+	for i := range blobKeys {
+		blob := makeTestBlob([]*core.SecurityParam{{
+			QuorumID:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		}})
+		_, blobKeys[i] = queueBlob(t, ctx, &blob, blobStore)
+	}
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	for i := 0; i < numBlobs; i++ {
+		err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+		assert.NoError(t, err)
+	}
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+
+	recordingStore := &confirmationWriteRecordingStore{BlobStore: blobStore}
+	batcher.Queue = recordingStore
+	logData := make([]byte, 64)
+	logData[31] = 1
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt: &types.Receipt{
+			Status: types.ReceiptStatusSuccessful,
+			Logs: []*types.Log{
+				{
+					Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+					Data:   logData,
+				},
+			},
+			BlockNumber: big.NewInt(123),
+			TxHash:      gethcommon.HexToHash("0x1234"),
+		},
+		Metadata: components.txnManager.Requests[0].Metadata,
+	})
+	assert.NoError(t, err)
+
+	// the confirmations are written in groups of at most 2
+	sort.Ints(recordingStore.writeSizes)
+	assert.Equal(t, []int{1, 2, 2}, recordingStore.writeSizes)
+	for _, blobKey := range blobKeys {
+		meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+		assert.NoError(t, err)
+		assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
+	}
+}
+
+func TestReconcileConfirmedBatch(t *testing.T) {
+	blob1 := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	blob2 := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           1,
+		AdversaryThreshold: 70,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+
+	defer getHeartbeats()
+	// batch ID 3
+	logData, err := hex.DecodeString("00000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000")
+	assert.NoError(t, err)
+	receipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{
+			{
+				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+				Data:   logData,
+			},
+		},
+		BlockNumber: big.NewInt(123),
+		TxHash:      gethcommon.HexToHash("0x1234"),
+	}
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey1 := queueBlob(t, ctx, &blob1, blobStore)
+	_, blobKey2 := queueBlob(t, ctx, &blob2, blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+	components.ethClient.On("TransactionReceipt").Return(receipt, nil)
+
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+
+	// blob2 fails to be updated while processing the confirmed batch
+	batcher.Queue = &failingConfirmationStore{BlobStore: blobStore, blobKey: blobKey2}
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt:  receipt,
+		Err:      nil,
+		Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
+	})
+	assert.NoError(t, err)
+	meta1, err := blobStore.GetBlobMetadata(ctx, blobKey1)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Confirmed, meta1.BlobStatus)
+	meta2, err := blobStore.GetBlobMetadata(ctx, blobKey2)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, meta2.BlobStatus)
+
+	// reconciliation fails while the store keeps failing
+	err = batcher.ReconcileConfirmedBatch(ctx, 3)
+	assert.ErrorContains(t, err, "failed to mark blob")
+
+	batcher.Queue = blobStore
+	err = batcher.ReconcileConfirmedBatch(ctx, 4)
+	assert.ErrorContains(t, err, "no pending metadata for batch 4")
+	err = batcher.ReconcileConfirmedBatch(ctx, 3)
+	assert.NoError(t, err)
+
+	confirmedMeta1, err := blobStore.GetBlobMetadata(ctx, blobKey1)
+	assert.NoError(t, err)
+	assert.Equal(t, meta1, confirmedMeta1)
+	meta2, err = blobStore.GetBlobMetadata(ctx, blobKey2)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Confirmed, meta2.BlobStatus)
+	assert.Equal(t, uint32(3), meta2.ConfirmationInfo.BatchID)
+	assert.Equal(t, 1-meta1.ConfirmationInfo.BlobIndex, meta2.ConfirmationInfo.BlobIndex)
+	assert.Equal(t, meta1.ConfirmationInfo.BatchHeaderHash, meta2.ConfirmationInfo.BatchHeaderHash)
+	assert.Equal(t, receipt.TxHash, meta2.ConfirmationInfo.ConfirmationTxnHash)
+	assert.NotEmpty(t, meta2.ConfirmationInfo.BlobInclusionProof)
+
+	// the batch is fully reconciled
+	err = batcher.ReconcileConfirmedBatch(ctx, 3)
+	assert.ErrorContains(t, err, "no pending metadata for batch 3")
+}
+
+func TestReconcileConfirmedBatchTransactionNotFound(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+
+	defer getHeartbeats()
+	// batch ID 3
+	logData, err := hex.DecodeString("00000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000")
+	assert.NoError(t, err)
+	receipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{
+			{
+				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+				Data:   logData,
+			},
+		},
+		BlockNumber: big.NewInt(123),
+		TxHash:      gethcommon.HexToHash("0x1234"),
+	}
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+	components.ethClient.On("TransactionReceipt").Return(nil, ethereum.NotFound)
+
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+
+	batcher.Queue = &failingConfirmationStore{BlobStore: blobStore, blobKey: blobKey}
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt:  receipt,
+		Err:      nil,
+		Metadata: components.txnManager.Requests[0].Metadata,
+	})
+	assert.NoError(t, err)
+	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, meta.BlobStatus)
+	assert.Equal(t, uint(1), meta.NumRetries)
+
+	// the confirmation transaction is no longer onchain, so the blob is retried and the batch is forgotten
+	batcher.Queue = blobStore
+	err = batcher.ReconcileConfirmedBatch(ctx, 3)
+	assert.NoError(t, err)
+	meta, err = blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, meta.BlobStatus)
+	assert.Equal(t, uint(2), meta.NumRetries)
+	err = batcher.ReconcileConfirmedBatch(ctx, 3)
+	assert.ErrorContains(t, err, "no pending metadata for batch 3")
+}
+
+// recordingDispatcher records the batch headers and operator states of the dispersed batches
+type recordingDispatcher struct {
+	disperser.Dispatcher
+
+	headers []*core.BatchHeader
+	states  []*core.IndexedOperatorState
+	blobs   [][]core.EncodedBlob
+}
+
+func (d *recordingDispatcher) DisperseBatch(ctx context.Context, state *core.IndexedOperatorState, blobs []core.EncodedBlob, header *core.BatchHeader) chan core.SignerMessage {
+	d.headers = append(d.headers, header)
+	d.states = append(d.states, state)
+	d.blobs = append(d.blobs, blobs)
+	return d.Dispatcher.DisperseBatch(ctx, state, blobs, header)
+}
+
+// referenceBlockObserver advances the reference block of the encoding streamer once a batch is created
+type referenceBlockObserver struct {
+	bat.EventObserver
+
+	streamer             *bat.EncodingStreamer
+	referenceBlockNumber uint
+	aggregatedHeaders    []*core.BatchHeader
+}
+
+func (o *referenceBlockObserver) OnBatchCreated(ctx context.Context, header *core.BatchHeader, blobs []*disperser.BlobMetadata) error {
+	o.streamer.ReferenceBlockNumber = o.referenceBlockNumber
+	return nil
+}
+
+func (o *referenceBlockObserver) OnBatchAggregated(ctx context.Context, header *core.BatchHeader, aggSig *core.SignatureAggregation) error {
+	o.aggregatedHeaders = append(o.aggregatedHeaders, header)
+	return nil
+}
+
+func TestReferenceBlockPinning(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+
+	defer getHeartbeats()
+	dispatcher := &recordingDispatcher{Dispatcher: batcher.Dispatcher}
+	batcher.Dispatcher = dispatcher
+	observer := &referenceBlockObserver{
+		EventObserver:        bat.NewNoopEventObserver(),
+		streamer:             components.encodingStreamer,
+		referenceBlockNumber: 20,
+	}
+	batcher.Observer = observer
+
+	ctx := context.Background()
+	queueBlob(t, ctx, &blob, components.blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+	pinnedBlockNumber := components.encodingStreamer.ReferenceBlockNumber
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+
+	// the reference block of the streamer advanced mid-batch, but the batch still uses the pinned reference block
+	assert.Equal(t, uint(20), components.encodingStreamer.ReferenceBlockNumber)
+	assert.Len(t, dispatcher.headers, 1)
+	assert.Equal(t, pinnedBlockNumber, dispatcher.headers[0].ReferenceBlockNumber)
+	assert.Equal(t, pinnedBlockNumber, dispatcher.states[0].BlockNumber)
+	assert.Len(t, observer.aggregatedHeaders, 1)
+	assert.Equal(t, pinnedBlockNumber, observer.aggregatedHeaders[0].ReferenceBlockNumber)
+}
+
+func TestBatcherSplitsOversizedConfirmBatch(t *testing.T) {
+	blob1 := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	blob2 := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           1,
+		AdversaryThreshold: 70,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+
+	// if none of the operators sign, the calldata of a batch over both quorums is 2884 bytes and of a batch over a
+	// single quorum 2372 bytes
+	batcher.MaxConfirmBatchCalldata = 2600
+	dispatcher := &recordingDispatcher{Dispatcher: batcher.Dispatcher}
+	batcher.Dispatcher = dispatcher
+
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey1 := queueBlob(t, ctx, &blob1, blobStore)
+	_, blobKey2 := queueBlob(t, ctx, &blob2, blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+		assert.NoError(t, err)
+	}
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	components.transactor.AssertNumberOfCalls(t, "BuildConfirmBatchTxn", 2)
+	assert.Len(t, components.txnManager.Requests, 2)
+	// the batch is split before dispersal, so only the sub-batches are dispersed, each to the operators of its quorum
+	assert.Len(t, dispatcher.headers, 2)
+	for _, state := range dispatcher.states {
+		assert.Len(t, state.Operators, 1)
+	}
+
+	for i, req := range components.txnManager.Requests {
+		logData := make([]byte, 64)
+		logData[31] = byte(i + 1)
+		err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+			Receipt: &types.Receipt{
+				Status: types.ReceiptStatusSuccessful,
+				Logs: []*types.Log{
+					{
+						Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+						Data:   logData,
+					},
+				},
+				BlockNumber: big.NewInt(123),
+				TxHash:      gethcommon.HexToHash("0x1234"),
+			},
+			Metadata: req.Metadata,
+		})
+		assert.NoError(t, err)
+	}
+
+	// each blob is confirmed in its own sub-batch, with a merkle proof against the sub-batch root
+	batchRoots := make(map[string]struct{})
+	for _, blobKey := range []disperser.BlobKey{blobKey1, blobKey2} {
+		meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+		assert.NoError(t, err)
+		assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
+		info := meta.ConfirmationInfo
+		assert.Equal(t, uint32(0), info.BlobIndex)
+		batchRoots[string(info.BatchRoot)] = struct{}{}
+
+		blobHeader := &core.BlobHeader{
+			BlobCommitments: *info.BlobCommitment,
+			QuorumInfos:     info.BlobQuorumInfos,
+		}
+		blobHeaderHash, err := blobHeader.GetBlobHeaderHash()
+		assert.NoError(t, err)
+		proof, err := core.DeserializeBatchMerkleProof(uint64(info.BlobIndex), info.BlobInclusionProof)
+		assert.NoError(t, err)
+		ok, err := proof.Verify(blobHeaderHash[:], [32]byte(info.BatchRoot))
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+	assert.Len(t, batchRoots, 2)
+}
+
+// aggregationObserver records the headers and signature aggregations of aggregated batches
+type aggregationObserver struct {
+	bat.EventObserver
+
+	headers      []*core.BatchHeader
+	aggregations []*core.SignatureAggregation
+}
+
+func (o *aggregationObserver) OnBatchAggregated(ctx context.Context, header *core.BatchHeader, aggSig *core.SignatureAggregation) error {
+	o.headers = append(o.headers, header)
+	o.aggregations = append(o.aggregations, aggSig)
+	return nil
+}
+
+func TestBatcherRecordsSigningScheme(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+	observer := &aggregationObserver{EventObserver: bat.NewNoopEventObserver()}
+	batcher.Observer = observer
+
+	ctx := context.Background()
+	queueBlob(t, ctx, &blob, components.blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+
+	// the batch is signed and aggregated under the default scheme, which is recorded in its header
+	assert.Len(t, observer.headers, 1)
+	assert.Equal(t, core.SigningSchemeBN254BLS, observer.headers[0].SigningScheme)
+	assert.Equal(t, core.SigningSchemeBN254BLS, observer.aggregations[0].Scheme)
+}
+
+func TestBatcherBatchAggregateSignature(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+	observer := &aggregationObserver{EventObserver: bat.NewNoopEventObserver()}
+	batcher.Observer = observer
+
+	// batch ID 3
+	logData, err := hex.DecodeString("00000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000")
+	assert.NoError(t, err)
+	receipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{
+			{
+				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+				Data:   logData,
+			},
+		},
+		BlockNumber: big.NewInt(123),
+		TxHash:      gethcommon.HexToHash("0x1234"),
+	}
+
+	ctx := context.Background()
+	queueBlob(t, ctx, &blob, components.blobStore)
+	out := make(chan bat.EncodingResultOrStatus)
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	_, err = components.blobStore.GetBatchAggregateSignature(ctx, 3)
+	assert.ErrorIs(t, err, disperser.ErrBatchNotFound)
+
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt:  receipt,
+		Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
+	})
+	assert.NoError(t, err)
+
+	// the retrieved aggregate signature matches the one produced for the batch
+	assert.Len(t, observer.aggregations, 1)
+	produced := observer.aggregations[0]
+	aggSig, err := components.blobStore.GetBatchAggregateSignature(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, produced.AggSignature.Serialize(), aggSig.AggSignature.Serialize())
+	assert.Equal(t, produced.AggPubKey.Serialize(), aggSig.AggPubKey.Serialize())
+	assert.Len(t, aggSig.NonSigners, len(produced.NonSigners))
+	assert.Len(t, aggSig.QuorumAggPubKeys, len(produced.QuorumAggPubKeys))
+	for i, apk := range produced.QuorumAggPubKeys {
+		assert.Equal(t, apk.Serialize(), aggSig.QuorumAggPubKeys[i].Serialize())
+	}
+	assert.Equal(t, produced.QuorumResults, aggSig.QuorumResults)
+	assert.Equal(t, produced.Scheme, aggSig.Scheme)
+
+	// the aggregate signature verifies against the batch header independently
+	headerHash, err := observer.headers[0].GetBatchHeaderHash()
+	assert.NoError(t, err)
+	assert.True(t, aggSig.AggSignature.Verify(aggSig.AggPubKey, headerHash))
+
+	_, err = components.blobStore.GetBatchAggregateSignature(ctx, 4)
+	assert.ErrorIs(t, err, disperser.ErrBatchNotFound)
+}
+
+// makeBatchConfirmedReceipt makes the receipt of a successful confirmBatch transaction confirming the batch ID
+func makeBatchConfirmedReceipt(t *testing.T, batchID uint32, txHash gethcommon.Hash) *types.Receipt {
+	logData := make([]byte, 64)
+	binary.BigEndian.PutUint32(logData[28:32], batchID)
+	return &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{
+			{
+				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+				Data:   logData,
+			},
+		},
+		BlockNumber: big.NewInt(123),
+		TxHash:      txHash,
+	}
+}
+
+func TestBatcherPendingConfirmationTimeout(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcherWithConfig(t, func(config *bat.Config) {
+		config.PendingConfirmationTimeout = time.Minute
+	}, nil)
+	defer getHeartbeats()
+	now := time.Unix(1000, 0)
+	batcher.EncodingStreamer.Now = func() time.Time {
+		return now
+	}
+
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, components.blobStore)
+	encode := func() {
+		out := make(chan bat.EncodingResultOrStatus)
+		err := components.encodingStreamer.RequestEncoding(ctx, out)
+		assert.NoError(t, err)
+		err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+		assert.NoError(t, err)
+	}
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+	components.ethClient.On("FilterLogs", tmock.Anything).Return([]types.Log{}, nil)
+
+	encode()
+	err := batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	firstBatch := components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata
+	metadata, err := components.blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+
+	// the batch is left pending before the timeout
+	now = now.Add(30 * time.Second)
+	batcher.HandleTimedOutBatches(ctx)
+	components.ethClient.AssertNotCalled(t, "FilterLogs", tmock.Anything)
+	assert.True(t, components.encodingStreamer.IsBlobPendingConfirmation(metadata))
+
+	// past the timeout the batch isn't confirmed onchain, so its blob is retried
+	now = now.Add(time.Minute)
+	batcher.HandleTimedOutBatches(ctx)
+	components.ethClient.AssertNumberOfCalls(t, "FilterLogs", 1)
+	metadata, err = components.blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, metadata.BlobStatus)
+	assert.Equal(t, uint(1), metadata.NumRetries)
+	assert.False(t, components.encodingStreamer.IsBlobPendingConfirmation(metadata))
+
+	// the retried blob is batched again
+	encode()
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	secondBatch := components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata
+	assert.True(t, components.encodingStreamer.IsBlobPendingConfirmation(metadata))
+
+	// the late confirmation of the first batch is ignored for the blob batched again
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt:  makeBatchConfirmedReceipt(t, 3, gethcommon.HexToHash("0x1")),
+		Metadata: firstBatch,
+	})
+	assert.NoError(t, err)
+	metadata, err = components.blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, metadata.BlobStatus)
+	assert.True(t, components.encodingStreamer.IsBlobPendingConfirmation(metadata))
+
+	// the blob is confirmed by its new batch
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt:  makeBatchConfirmedReceipt(t, 4, gethcommon.HexToHash("0x2")),
+		Metadata: secondBatch,
+	})
+	assert.NoError(t, err)
+	metadata, err = components.blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Confirmed, metadata.BlobStatus)
+	assert.Equal(t, uint32(4), metadata.ConfirmationInfo.BatchID)
+}
+
+func TestBatcherPendingConfirmationTimeoutConfirmedOnchain(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	serviceManagerAddress := gethcommon.HexToAddress("0x5678")
+	components, batcher, getHeartbeats := makeBatcherWithConfig(t, func(config *bat.Config) {
+		config.PendingConfirmationTimeout = time.Minute
+		config.ServiceManagerAddress = serviceManagerAddress
+	}, nil)
+	defer getHeartbeats()
+	now := time.Unix(1000, 0)
+	batcher.EncodingStreamer.Now = func() time.Time {
+		return now
+	}
+	var confirmedBatches []*bat.BatchResult
+	batcher.OnBatchConfirmed(func(result *bat.BatchResult) {
+		confirmedBatches = append(confirmedBatches, result)
+	})
+
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, components.blobStore)
+	out := make(chan bat.EncodingResultOrStatus)
+	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	batch := components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata
+
+	// the batch was confirmed onchain, but its receipt wasn't returned by the transaction manager within the timeout
+	txHash := gethcommon.HexToHash("0x1")
+	receipt := makeBatchConfirmedReceipt(t, 3, txHash)
+	var query ethereum.FilterQuery
+	components.ethClient.On("FilterLogs", tmock.Anything).Run(func(args tmock.Arguments) {
+		query = args.Get(0).(ethereum.FilterQuery)
+	}).Return([]types.Log{{TxHash: txHash}}, nil)
+	components.ethClient.On("TransactionReceipt").Return(receipt, nil)
+	now = now.Add(2 * time.Minute)
+	batcher.HandleTimedOutBatches(ctx)
+
+	// the search for the BatchConfirmed event of the batch is bounded by its reference block
+	assert.Equal(t, []gethcommon.Address{serviceManagerAddress}, query.Addresses)
+	assert.Equal(t, common.BatchConfirmedEventSigHash, query.Topics[0][0])
+	assert.Equal(t, big.NewInt(10), query.FromBlock)
+	metadata, err := components.blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Confirmed, metadata.BlobStatus)
+	assert.Equal(t, uint32(3), metadata.ConfirmationInfo.BatchID)
+	assert.Equal(t, uint(0), metadata.NumRetries)
+	assert.Len(t, confirmedBatches, 1)
+
+	// the receipt returned later by the transaction manager is ignored
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{Receipt: receipt, Metadata: batch})
+	assert.NoError(t, err)
+	assert.Len(t, confirmedBatches, 1)
+}
+
+// slowEncoderClient delays each encoding request, failing it if the request times out first
+type slowEncoderClient struct {
+	disperser.EncoderClient
+	delay time.Duration
+}
+
+func (c *slowEncoderClient) EncodeBlob(ctx context.Context, data []byte, encodingParams core.EncodingParams) (*core.BlobCommitments, []*core.Chunk, error) {
+	select {
+	case <-time.After(c.delay):
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+	return c.EncoderClient.EncodeBlob(ctx, data, encodingParams)
+}
+
+func TestBatcherEncodingRequestTimeout(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	// encoding takes several pull intervals, but stays within the encoding request timeout
+	components, _, getHeartbeats := makeBatcherWithConfig(t, func(config *bat.Config) {
+		config.PullInterval = 10 * time.Millisecond
+		config.EncodingRequestTimeout = 5 * time.Second
+	}, func(encoderClient disperser.EncoderClient) disperser.EncoderClient {
+		return &slowEncoderClient{EncoderClient: encoderClient, delay: 200 * time.Millisecond}
+	})
+	defer getHeartbeats()
+
+	ctx := context.Background()
+	queueBlob(t, ctx, &blob, components.blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	result := <-out
+	assert.NoError(t, result.Err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, result)
+	assert.NoError(t, err)
+	count, _ := components.encodingStreamer.EncodedBlobstore.GetEncodedResultSize()
+	assert.Equal(t, 1, count)
+}
+
+// failingEncoderClient fails every encoding request as if the encoder couldn't be reached
+type failingEncoderClient struct {
+	numRequests atomic.Int32
+}
+
+func (c *failingEncoderClient) EncodeBlob(ctx context.Context, data []byte, encodingParams core.EncodingParams) (*core.BlobCommitments, []*core.Chunk, error) {
+	c.numRequests.Add(1)
+	return nil, nil, status.Error(codes.Unavailable, "encoder unavailable")
+}
+
+func TestBatcherLocalEncoderFallback(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	// the remote encoder fails, and the blob is encoded with the local encoder instead
+	remote := &failingEncoderClient{}
+	var fallback *disperser.FallbackEncoderClient
+	components, batcher, getHeartbeats := makeBatcherWithConfig(t, func(config *bat.Config) {
+		config.EnableLocalEncoderFallback = true
+	}, func(encoderClient disperser.EncoderClient) disperser.EncoderClient {
+		fallback = disperser.NewFallbackEncoderClient(remote, encoderClient, time.Minute, &cmock.Logger{})
+		return fallback
+	})
+	defer getHeartbeats()
+
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, components.blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	result := <-out
+	assert.NoError(t, result.Err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, result)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), remote.numRequests.Load())
+	assert.False(t, fallback.IsPrimaryHealthy())
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	// the blob encoded with the local encoder is dispersed and signed
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, components.txnManager.Requests, 1)
+	encodingResult, err := components.encodingStreamer.EncodedBlobstore.GetEncodingResult(blobKey, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, bat.PendingConfirmation, encodingResult.Status)
+}
+
+// flakyDispatcher fails to disperse to the failing operators in the first dispersal, and has the other operators of the
+// state it is given sign the batch
+type flakyDispatcher struct {
+	operators map[core.OperatorID]*coremock.PrivateOperatorInfo
+	failing   map[core.OperatorID]bool
+
+	states []*core.IndexedOperatorState
+}
+
+func (d *flakyDispatcher) DisperseBatch(ctx context.Context, state *core.IndexedOperatorState, blobs []core.EncodedBlob, header *core.BatchHeader) chan core.SignerMessage {
+	d.states = append(d.states, state)
+	message, err := header.GetBatchHeaderHash()
+	if err != nil {
+		panic(err)
+	}
+	update := make(chan core.SignerMessage, len(state.IndexedOperators))
+	for id := range state.IndexedOperators {
+		if d.failing[id] && len(d.states) == 1 {
+			update <- core.SignerMessage{Operator: id, Err: errors.New("failed to store chunks")}
+			continue
+		}
+		update <- core.SignerMessage{Operator: id, Signature: d.operators[id].KeyPair.SignMessage(message)}
+	}
+	return update
+}
+
+func (d *flakyDispatcher) EstimateCoverage(state *core.IndexedOperatorState, blobs []core.EncodedBlob, header *core.BatchHeader) map[core.QuorumID]float64 {
+	return disperser.EstimateSigningCoverage(state, blobs)
+}
+
+// dispersalFailureBatcher makes a batcher with the given dispersal failure policy whose first dispersal fails for the
+// operators with the largest stakes, holding at least failureFraction of the stake of quorum 0
+func dispersalFailureBatcher(t *testing.T, policy bat.DispersalFailurePolicy, failureFraction float64) (*batcherComponents, *bat.Batcher, *flakyDispatcher, disperser.BlobKey) {
+	components, batcher, getHeartbeats := makeBatcherWithConfig(t, func(config *bat.Config) {
+		config.DispersalFailurePolicy = policy
+	}, nil)
+	t.Cleanup(func() { getHeartbeats() })
+
+	state := components.chainData.GetTotalOperatorState(context.Background(), 0)
+	operators := make([]core.OperatorID, 0, len(state.Operators[0]))
+	for id := range state.Operators[0] {
+		operators = append(operators, id)
+	}
+	sort.Slice(operators, func(i, j int) bool {
+		return state.Operators[0][operators[i]].Stake.Cmp(state.Operators[0][operators[j]].Stake) > 0
+	})
+	totalStake := float64(state.Totals[0].Stake.Int64())
+	failing := make(map[core.OperatorID]bool)
+	failedStake := 0.0
+	for _, id := range operators {
+		if failedStake >= failureFraction*totalStake {
+			break
+		}
+		failing[id] = true
+		failedStake += float64(state.Operators[0][id].Stake.Int64())
+	}
+	dispatcher := &flakyDispatcher{operators: state.PrivateOperators, failing: failing}
+	batcher.Dispatcher = dispatcher
+
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 50,
+		QuorumThreshold:    80,
+	}})
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, components.blobStore)
+	out := make(chan bat.EncodingResultOrStatus)
+	err := components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+	return components, batcher, dispatcher, blobKey
+}
+
+func TestBatcherDispersalFailureProceed(t *testing.T) {
+	components, batcher, dispatcher, blobKey := dispersalFailureBatcher(t, bat.DispersalFailureProceed, 0.3)
+	ctx := context.Background()
+
+	// the signatures are aggregated regardless of the failures, and fall short of the quorum threshold
+	err := batcher.HandleSingleBatch(ctx)
+	assert.ErrorContains(t, err, "no blobs received sufficient signatures")
+	assert.Len(t, dispatcher.states, 1)
+	components.transactor.AssertNotCalled(t, "BuildConfirmBatchTxn")
+	meta, err := components.blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), meta.NumRetries)
+}
+
+func TestBatcherDispersalFailureAbort(t *testing.T) {
+	components, batcher, dispatcher, blobKey := dispersalFailureBatcher(t, bat.DispersalFailureAbort, 0.3)
+	ctx := context.Background()
+
+	// the batch is aborted before the signatures are aggregated
+	err := batcher.HandleSingleBatch(ctx)
+	assert.ErrorContains(t, err, "batch aborted")
+	assert.ErrorContains(t, err, "2 of 10 operators failed to receive their chunks")
+	assert.Len(t, dispatcher.states, 1)
+	components.transactor.AssertNotCalled(t, "BuildConfirmBatchTxn")
+	meta, err := components.blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), meta.NumRetries)
+}
+
+func TestBatcherDispersalFailureAbortWithSufficientCoverage(t *testing.T) {
+	components, batcher, dispatcher, _ := dispersalFailureBatcher(t, bat.DispersalFailureAbort, 0.1)
+
+	// the operators that received their chunks hold enough stake, so the batch is confirmed with their signatures
+	err := batcher.HandleSingleBatch(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, dispatcher.states, 1)
+	components.transactor.AssertNumberOfCalls(t, "BuildConfirmBatchTxn", 1)
+}
+
+func TestBatcherDispersalFailureRetry(t *testing.T) {
+	components, batcher, dispatcher, _ := dispersalFailureBatcher(t, bat.DispersalFailureRetry, 0.3)
+
+	// the batch is dispersed once more to the failed operators only, and then confirmed
+	err := batcher.HandleSingleBatch(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, dispatcher.states, 2)
+	assert.Len(t, dispatcher.states[0].IndexedOperators, 10)
+	assert.Len(t, dispatcher.states[1].IndexedOperators, 2)
+	for id := range dispatcher.states[1].IndexedOperators {
+		assert.True(t, dispatcher.failing[id])
+	}
+	components.transactor.AssertNumberOfCalls(t, "BuildConfirmBatchTxn", 1)
 }
@@ -0,0 +1,194 @@
+package batcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Layr-Labs/eigenda/disperser"
+	gcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// ErrConfirmedBatchNotFound is returned by ConfirmedBatchStore when no confirmed batch is stored with the batch ID
+var ErrConfirmedBatchNotFound = errors.New("confirmed batch not found")
+
+// ConfirmedBatch is a batch confirmed onchain whose blobs may not all have been updated yet. It records the
+// confirmation info of each blob, so that the blobs can be updated after a restart without the in-memory batch.
+type ConfirmedBatch struct {
+	BatchID uint32          `json:"batch_id"`
+	TxHash  gcommon.Hash    `json:"tx_hash"`
+	Blobs   []ConfirmedBlob `json:"blobs"`
+}
+
+// ConfirmedBlob is the confirmation info of a blob of a confirmed batch, and whether the blob is attested, i.e. whether
+// it is to be marked as confirmed or as having insufficient signatures
+type ConfirmedBlob struct {
+	BlobKey          disperser.BlobKey           `json:"blob_key"`
+	ConfirmationInfo *disperser.ConfirmationInfo `json:"confirmation_info"`
+	Attested         bool                        `json:"attested"`
+}
+
+// ConfirmedBatchStore stores the confirmed batches whose blobs have not all been updated yet, keyed by batch ID
+type ConfirmedBatchStore interface {
+	PutConfirmedBatch(ctx context.Context, batch *ConfirmedBatch) error
+	// GetConfirmedBatch returns ErrConfirmedBatchNotFound if no batch is stored with the batch ID
+	GetConfirmedBatch(ctx context.Context, batchID uint32) (*ConfirmedBatch, error)
+	DeleteConfirmedBatch(ctx context.Context, batchID uint32) error
+	// ListConfirmedBatches returns the stored batches in ascending order of batch ID
+	ListConfirmedBatches(ctx context.Context) ([]*ConfirmedBatch, error)
+}
+
+type inMemoryConfirmedBatchStore struct {
+	mu      sync.Mutex
+	batches map[uint32]*ConfirmedBatch
+}
+
+// NewInMemoryConfirmedBatchStore returns a ConfirmedBatchStore that doesn't outlive the process
+func NewInMemoryConfirmedBatchStore() ConfirmedBatchStore {
+	return &inMemoryConfirmedBatchStore{
+		batches: make(map[uint32]*ConfirmedBatch),
+	}
+}
+
+func (s *inMemoryConfirmedBatchStore) PutConfirmedBatch(ctx context.Context, batch *ConfirmedBatch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches[batch.BatchID] = batch
+	return nil
+}
+
+func (s *inMemoryConfirmedBatchStore) GetConfirmedBatch(ctx context.Context, batchID uint32) (*ConfirmedBatch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batch, ok := s.batches[batchID]
+	if !ok {
+		return nil, ErrConfirmedBatchNotFound
+	}
+	return batch, nil
+}
+
+func (s *inMemoryConfirmedBatchStore) DeleteConfirmedBatch(ctx context.Context, batchID uint32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.batches, batchID)
+	return nil
+}
+
+func (s *inMemoryConfirmedBatchStore) ListConfirmedBatches(ctx context.Context) ([]*ConfirmedBatch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batches := make([]*ConfirmedBatch, 0, len(s.batches))
+	for _, batch := range s.batches {
+		batches = append(batches, batch)
+	}
+	sort.Slice(batches, func(i, j int) bool {
+		return batches[i].BatchID < batches[j].BatchID
+	})
+	return batches, nil
+}
+
+const confirmedBatchFileExt = ".json"
+
+type fileConfirmedBatchStore struct {
+	dir string
+}
+
+// NewFileConfirmedBatchStore returns a ConfirmedBatchStore that stores each batch as a JSON file in the directory, so
+// that the batches survive restarts of the batcher
+func NewFileConfirmedBatchStore(dir string) (ConfirmedBatchStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create confirmed batch directory %s: %w", dir, err)
+	}
+	return &fileConfirmedBatchStore{dir: dir}, nil
+}
+
+func (s *fileConfirmedBatchStore) path(batchID uint32) string {
+	return filepath.Join(s.dir, strconv.FormatUint(uint64(batchID), 10)+confirmedBatchFileExt)
+}
+
+func (s *fileConfirmedBatchStore) PutConfirmedBatch(ctx context.Context, batch *ConfirmedBatch) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode confirmed batch %d: %w", batch.BatchID, err)
+	}
+	// write to a temporary file first, so that a crash never leaves a partially written batch behind
+	tmp, err := os.CreateTemp(s.dir, "tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create confirmed batch file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write confirmed batch %d: %w", batch.BatchID, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write confirmed batch %d: %w", batch.BatchID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write confirmed batch %d: %w", batch.BatchID, err)
+	}
+	return os.Rename(tmp.Name(), s.path(batch.BatchID))
+}
+
+func (s *fileConfirmedBatchStore) GetConfirmedBatch(ctx context.Context, batchID uint32) (*ConfirmedBatch, error) {
+	data, err := os.ReadFile(s.path(batchID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrConfirmedBatchNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read confirmed batch %d: %w", batchID, err)
+	}
+	batch := new(ConfirmedBatch)
+	if err := json.Unmarshal(data, batch); err != nil {
+		return nil, fmt.Errorf("failed to decode confirmed batch %d: %w", batchID, err)
+	}
+	return batch, nil
+}
+
+func (s *fileConfirmedBatchStore) DeleteConfirmedBatch(ctx context.Context, batchID uint32) error {
+	err := os.Remove(s.path(batchID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete confirmed batch %d: %w", batchID, err)
+	}
+	return nil
+}
+
+func (s *fileConfirmedBatchStore) ListConfirmedBatches(ctx context.Context) ([]*ConfirmedBatch, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list confirmed batches: %w", err)
+	}
+	batchIDs := make([]uint32, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, confirmedBatchFileExt) {
+			continue
+		}
+		batchID, err := strconv.ParseUint(strings.TrimSuffix(name, confirmedBatchFileExt), 10, 32)
+		if err != nil {
+			continue
+		}
+		batchIDs = append(batchIDs, uint32(batchID))
+	}
+	sort.Slice(batchIDs, func(i, j int) bool {
+		return batchIDs[i] < batchIDs[j]
+	})
+
+	batches := make([]*ConfirmedBatch, 0, len(batchIDs))
+	for _, batchID := range batchIDs {
+		batch, err := s.GetConfirmedBatch(ctx, batchID)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
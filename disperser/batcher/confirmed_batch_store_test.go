@@ -0,0 +1,79 @@
+package batcher_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/Layr-Labs/eigenda/disperser"
+	bat "github.com/Layr-Labs/eigenda/disperser/batcher"
+	bn "github.com/consensys/gnark-crypto/ecc/bn254"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeConfirmedBatch(batchID uint32) *bat.ConfirmedBatch {
+	_, _, g1, g2 := bn.Generators()
+	commitment := core.G1Commitment(g1)
+	lengthCommitment := core.G2Commitment(g2)
+	lengthProof := core.LengthProof(g2)
+
+	return &bat.ConfirmedBatch{
+		BatchID: batchID,
+		TxHash:  gethcommon.HexToHash("0x1234"),
+		Blobs: []bat.ConfirmedBlob{
+			{
+				BlobKey: disperser.BlobKey{BlobHash: "blob", MetadataHash: "metadata"},
+				ConfirmationInfo: &disperser.ConfirmationInfo{
+					BatchHeaderHash: [32]byte{1, 2, 3},
+					BlobIndex:       1,
+					BatchRoot:       []byte("batch root"),
+					BlobCommitment: &core.BlobCommitments{
+						Commitment:       &commitment,
+						LengthCommitment: &lengthCommitment,
+						LengthProof:      &lengthProof,
+						Length:           32,
+					},
+					BatchID:             batchID,
+					ConfirmationTxnHash: gethcommon.HexToHash("0x1234"),
+					QuorumResults: map[core.QuorumID]*core.QuorumResult{
+						0: {QuorumID: 0, PercentSigned: 100},
+					},
+					AttestedQuorums: map[core.QuorumID]bool{0: true},
+				},
+				Attested: true,
+			},
+		},
+	}
+}
+
+func TestFileConfirmedBatchStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := bat.NewFileConfirmedBatchStore(dir)
+	assert.NoError(t, err)
+
+	_, err = store.GetConfirmedBatch(ctx, 3)
+	assert.ErrorIs(t, err, bat.ErrConfirmedBatchNotFound)
+
+	assert.NoError(t, store.PutConfirmedBatch(ctx, makeConfirmedBatch(12)))
+	assert.NoError(t, store.PutConfirmedBatch(ctx, makeConfirmedBatch(3)))
+
+	// the batches outlive the store
+	store, err = bat.NewFileConfirmedBatchStore(dir)
+	assert.NoError(t, err)
+	batch, err := store.GetConfirmedBatch(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, makeConfirmedBatch(3), batch)
+	batches, err := store.ListConfirmedBatches(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []*bat.ConfirmedBatch{makeConfirmedBatch(3), makeConfirmedBatch(12)}, batches)
+
+	assert.NoError(t, store.DeleteConfirmedBatch(ctx, 3))
+	assert.NoError(t, store.DeleteConfirmedBatch(ctx, 3))
+	_, err = store.GetConfirmedBatch(ctx, 3)
+	assert.ErrorIs(t, err, bat.ErrConfirmedBatchNotFound)
+	batches, err = store.ListConfirmedBatches(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []*bat.ConfirmedBatch{makeConfirmedBatch(12)}, batches)
+}
@@ -0,0 +1,123 @@
+package batcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/Layr-Labs/eigenda/core"
+)
+
+// DispersalFailurePolicy determines how the batcher handles the operators that fail to receive the chunks of a batch
+type DispersalFailurePolicy string
+
+const (
+	// DispersalFailureProceed aggregates the signatures of the batch regardless of the dispersal failures
+	DispersalFailureProceed DispersalFailurePolicy = "proceed"
+	// DispersalFailureAbort fails the batch without aggregating the signatures if the operators that received their
+	// chunks don't hold enough stake for any blob of the batch to reach its quorum thresholds
+	DispersalFailureAbort DispersalFailurePolicy = "abort"
+	// DispersalFailureRetry disperses the batch once more to the operators that failed to receive their chunks before
+	// aggregating the signatures
+	DispersalFailureRetry DispersalFailurePolicy = "retry"
+)
+
+// ParseDispersalFailurePolicy returns the policy with the given name. An empty name is the proceed policy.
+func ParseDispersalFailurePolicy(name string) (DispersalFailurePolicy, error) {
+	switch policy := DispersalFailurePolicy(name); policy {
+	case "":
+		return DispersalFailureProceed, nil
+	case DispersalFailureProceed, DispersalFailureAbort, DispersalFailureRetry:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown dispersal failure policy: %s", name)
+	}
+}
+
+// applyDispersalFailurePolicy applies the dispersal failure policy to the replies of the operators to the dispersal of
+// the batch, and returns the channel the signatures are aggregated from. An error is returned if the batch is aborted.
+func (b *Batcher) applyDispersalFailurePolicy(ctx context.Context, batch *batch, update chan core.SignerMessage) (chan core.SignerMessage, error) {
+	if b.DispersalFailurePolicy == "" || b.DispersalFailurePolicy == DispersalFailureProceed {
+		return update, nil
+	}
+
+	replies := collectSignerMessages(update, len(batch.State.IndexedOperators))
+	failed := make(map[core.OperatorID]*core.IndexedOperatorInfo)
+	for _, reply := range replies {
+		if reply.Err != nil {
+			failed[reply.Operator] = batch.State.IndexedOperators[reply.Operator]
+		}
+	}
+	if len(failed) == 0 {
+		return replayed(replies), nil
+	}
+
+	switch b.DispersalFailurePolicy {
+	case DispersalFailureAbort:
+		coverage := dispersalCoverage(batch.State, replies)
+		if numBlobsAttested(coverage, batch.BlobHeaders) == 0 {
+			return nil, fmt.Errorf("%d of %d operators failed to receive their chunks, leaving no blob able to reach its quorum thresholds", len(failed), len(replies))
+		}
+	case DispersalFailureRetry:
+		b.logger.Info("[batcher] retrying dispersal to the operators that failed to receive their chunks", "numFailed", len(failed), "numOperators", len(replies))
+		retryState := *batch.State
+		retryState.IndexedOperators = failed
+		retries := collectSignerMessages(b.Dispatcher.DisperseBatch(ctx, &retryState, batch.EncodedBlobs, batch.BatchHeader), len(failed))
+		retried := make(map[core.OperatorID]core.SignerMessage, len(retries))
+		for _, retry := range retries {
+			retried[retry.Operator] = retry
+		}
+		for i, reply := range replies {
+			if retry, ok := retried[reply.Operator]; ok && reply.Err != nil {
+				replies[i] = retry
+			}
+		}
+	}
+	return replayed(replies), nil
+}
+
+// collectSignerMessages reads the given number of replies from the channel
+func collectSignerMessages(update chan core.SignerMessage, numReplies int) []core.SignerMessage {
+	replies := make([]core.SignerMessage, numReplies)
+	for i := range replies {
+		replies[i] = <-update
+	}
+	return replies
+}
+
+// replayed returns a channel that holds the given replies
+func replayed(replies []core.SignerMessage) chan core.SignerMessage {
+	update := make(chan core.SignerMessage, len(replies))
+	for _, reply := range replies {
+		update <- reply
+	}
+	return update
+}
+
+// dispersalCoverage returns the percentage of the stake of each quorum held by the operators that received their chunks
+func dispersalCoverage(state *core.IndexedOperatorState, replies []core.SignerMessage) map[core.QuorumID]*core.QuorumResult {
+	received := make(map[core.OperatorID]bool, len(replies))
+	for _, reply := range replies {
+		if reply.Err == nil {
+			received[reply.Operator] = true
+		}
+	}
+
+	coverage := make(map[core.QuorumID]*core.QuorumResult, len(state.Operators))
+	for quorumID, operators := range state.Operators {
+		coverage[quorumID] = &core.QuorumResult{QuorumID: quorumID}
+		total, ok := state.Totals[quorumID]
+		if !ok || total.Stake == nil || total.Stake.Sign() == 0 {
+			continue
+		}
+		stake := big.NewInt(0)
+		for id, op := range operators {
+			if received[id] {
+				stake.Add(stake, op.Stake)
+			}
+		}
+		percent := new(big.Int).Div(new(big.Int).Mul(stake, big.NewInt(100)), total.Stake)
+		coverage[quorumID].PercentSigned = uint8(percent.Uint64())
+	}
+	return coverage
+}
@@ -1,8 +1,10 @@
 package batcher
 
 import (
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/Layr-Labs/eigenda/common"
 	"github.com/Layr-Labs/eigenda/core"
@@ -17,11 +19,18 @@ const (
 	PendingConfirmation
 )
 
+// errEncodingRequestCancelled is returned when the result of an encoding request is stored after the request was
+// cancelled, e.g. because its blob was removed
+var errEncodingRequestCancelled = errors.New("encoding request cancelled")
+
 type encodedBlobStore struct {
 	mu sync.RWMutex
 
-	requested map[requestID]struct{}
-	encoded   map[requestID]*EncodingResult
+	// requested holds the sequence number of the live encoding request of each blob and quorum
+	requested map[requestID]uint64
+	// lastRequestSeq is the sequence number of the last encoding request
+	lastRequestSeq uint64
+	encoded        map[requestID]*EncodingResult
 	// encodedResultSize is the total size of all the chunks in the encoded results in bytes
 	encodedResultSize uint64
 
@@ -37,6 +46,16 @@ type EncodingResult struct {
 	Chunks               []*core.Chunk
 	Assignments          map[core.OperatorID]core.Assignment
 	Status               status
+
+	// pendingConfirmationSince is the time the result was marked pending confirmation
+	pendingConfirmationSince time.Time
+	// exceededMaxPendingConfirmation is set once the result has been pending confirmation for longer than the maximum
+	// duration, so that it's only counted once
+	exceededMaxPendingConfirmation bool
+	// encodedAt is the time the result was put in the store
+	encodedAt time.Time
+	// requestSeq is the sequence number of the encoding request the result was produced by
+	requestSeq uint64
 }
 
 // EncodingResultOrStatus is a wrapper for EncodingResult that also contains an error
@@ -48,19 +67,23 @@ type EncodingResultOrStatus struct {
 
 func newEncodedBlobStore(logger common.Logger) *encodedBlobStore {
 	return &encodedBlobStore{
-		requested:         make(map[requestID]struct{}),
+		requested:         make(map[requestID]uint64),
 		encoded:           make(map[requestID]*EncodingResult),
 		encodedResultSize: 0,
 		logger:            logger,
 	}
 }
 
-func (e *encodedBlobStore) PutEncodingRequest(blobKey disperser.BlobKey, quorumID core.QuorumID) {
+// PutEncodingRequest records a new encoding request of the blob for the quorum, replacing any previous one, and returns
+// its sequence number
+func (e *encodedBlobStore) PutEncodingRequest(blobKey disperser.BlobKey, quorumID core.QuorumID) uint64 {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	e.lastRequestSeq++
 	requestID := getRequestID(blobKey, quorumID)
-	e.requested[requestID] = struct{}{}
+	e.requested[requestID] = e.lastRequestSeq
+	return e.lastRequestSeq
 }
 
 func (e *encodedBlobStore) HasEncodingRequested(blobKey disperser.BlobKey, quorumID core.QuorumID, referenceBlockNumber uint) bool {
@@ -79,19 +102,39 @@ func (e *encodedBlobStore) HasEncodingRequested(blobKey disperser.BlobKey, quoru
 	return false
 }
 
-func (e *encodedBlobStore) DeleteEncodingRequest(blobKey disperser.BlobKey, quorumID core.QuorumID) {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
+// DeleteEncodingRequest deletes the encoding request of the blob for the quorum with the given sequence number. A newer
+// request of the blob for the quorum is kept.
+func (e *encodedBlobStore) DeleteEncodingRequest(blobKey disperser.BlobKey, quorumID core.QuorumID, requestSeq uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
 	requestID := getRequestID(blobKey, quorumID)
-	if _, ok := e.requested[requestID]; !ok {
+	if seq, ok := e.requested[requestID]; !ok || seq != requestSeq {
 		return
 	}
 
 	delete(e.requested, requestID)
 }
 
-func (e *encodedBlobStore) PutEncodingResult(result *EncodingResult) error {
+// CancelEncodingRequest deletes the encoding request of the blob for the quorum, so that its result is discarded
+func (e *encodedBlobStore) CancelEncodingRequest(blobKey disperser.BlobKey, quorumID core.QuorumID) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.requested, getRequestID(blobKey, quorumID))
+}
+
+// CancelAllEncodingRequests deletes all the encoding requests, so that their results are discarded
+func (e *encodedBlobStore) CancelAllEncodingRequests() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.requested = make(map[requestID]uint64)
+}
+
+// PutEncodingResult stores the encoding result of a requested blob. The result is considered encoded at the given time.
+// It returns errEncodingRequestCancelled if the request the result was produced by was cancelled or replaced.
+func (e *encodedBlobStore) PutEncodingResult(result *EncodingResult, now time.Time) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -100,13 +143,18 @@ func (e *encodedBlobStore) PutEncodingResult(result *EncodingResult) error {
 		MetadataHash: result.BlobMetadata.MetadataHash,
 	}
 	requestID := getRequestID(blobKey, result.BlobQuorumInfo.QuorumID)
-	if _, ok := e.requested[requestID]; !ok {
-		return fmt.Errorf("PutEncodedBlob: no such key (%s) in requested set", requestID)
+	seq, ok := e.requested[requestID]
+	if !ok {
+		return fmt.Errorf("PutEncodedBlob: %w: no such key (%s) in requested set", errEncodingRequestCancelled, requestID)
+	}
+	if seq != result.requestSeq {
+		return fmt.Errorf("PutEncodedBlob: %w: request %d of %s was replaced by request %d", errEncodingRequestCancelled, result.requestSeq, requestID, seq)
 	}
 
 	if _, ok := e.encoded[requestID]; !ok {
 		e.encodedResultSize += getChunksSize(result)
 	}
+	result.encodedAt = now
 	e.encoded[requestID] = result
 	delete(e.requested, requestID)
 
@@ -125,6 +173,15 @@ func (e *encodedBlobStore) GetEncodingResult(blobKey disperser.BlobKey, quorumID
 	return e.encoded[requestID], nil
 }
 
+// IsPendingConfirmation returns whether the result of the blob for the quorum is pending confirmation
+func (e *encodedBlobStore) IsPendingConfirmation(blobKey disperser.BlobKey, quorumID core.QuorumID) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	encodedResult, ok := e.encoded[getRequestID(blobKey, quorumID)]
+	return ok && encodedResult.Status == PendingConfirmation
+}
+
 func (e *encodedBlobStore) DeleteEncodingResult(blobKey disperser.BlobKey, quorumID core.QuorumID) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -165,6 +222,20 @@ func (e *encodedBlobStore) GetNewAndDeleteStaleEncodingResults(blockNumber uint)
 	return fetched
 }
 
+// GetNewEncodingResults returns all the fresh encoded results that are pending dispersal at the given block number,
+// without deleting the stale results
+func (e *encodedBlobStore) GetNewEncodingResults(blockNumber uint) []*EncodingResult {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	fetched := make([]*EncodingResult, 0)
+	for _, encodedResult := range e.encoded {
+		if encodedResult.Status == PendingDispersal && encodedResult.ReferenceBlockNumber == blockNumber {
+			fetched = append(fetched, encodedResult)
+		}
+	}
+	return fetched
+}
+
 // GetEncodedResultSize returns the total size of all the chunks in the encoded results in bytes
 func (e *encodedBlobStore) GetEncodedResultSize() (int, uint64) {
 	e.mu.RLock()
@@ -173,7 +244,28 @@ func (e *encodedBlobStore) GetEncodedResultSize() (int, uint64) {
 	return len(e.encoded), e.encodedResultSize
 }
 
-func (e *encodedBlobStore) MarkEncodedResultPendingConfirmation(blobKey disperser.BlobKey, quorumID core.QuorumID) error {
+// GetOldestPendingDispersalRequestedAt returns the earliest request time (in nanoseconds) among the blobs of the encoded
+// results pending dispersal, and false if there are no such results
+func (e *encodedBlobStore) GetOldestPendingDispersalRequestedAt() (uint64, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var oldest uint64
+	found := false
+	for _, encodedResult := range e.encoded {
+		if encodedResult.Status != PendingDispersal || encodedResult.BlobMetadata == nil || encodedResult.BlobMetadata.RequestMetadata == nil {
+			continue
+		}
+		requestedAt := encodedResult.BlobMetadata.RequestMetadata.RequestedAt
+		if !found || requestedAt < oldest {
+			oldest = requestedAt
+			found = true
+		}
+	}
+	return oldest, found
+}
+
+func (e *encodedBlobStore) MarkEncodedResultPendingConfirmation(blobKey disperser.BlobKey, quorumID core.QuorumID, now time.Time) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -183,9 +275,73 @@ func (e *encodedBlobStore) MarkEncodedResultPendingConfirmation(blobKey disperse
 	}
 
 	e.encoded[requestID].Status = PendingConfirmation
+	e.encoded[requestID].pendingConfirmationSince = now
+	e.encoded[requestID].exceededMaxPendingConfirmation = false
 	return nil
 }
 
+// GetOldestPendingConfirmationSince returns the earliest time a result still pending confirmation was marked pending
+// confirmation, and false if there are no such results
+func (e *encodedBlobStore) GetOldestPendingConfirmationSince() (time.Time, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var oldest time.Time
+	found := false
+	for _, encodedResult := range e.encoded {
+		if encodedResult.Status != PendingConfirmation {
+			continue
+		}
+		if !found || encodedResult.pendingConfirmationSince.Before(oldest) {
+			oldest = encodedResult.pendingConfirmationSince
+			found = true
+		}
+	}
+	return oldest, found
+}
+
+// MarkExceededPendingConfirmations marks the results that have been pending confirmation since before the given deadline
+// and haven't been marked yet. It returns the keys of the blobs of the newly marked results.
+func (e *encodedBlobStore) MarkExceededPendingConfirmations(deadline time.Time) []disperser.BlobKey {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	exceeded := make(map[disperser.BlobKey]struct{})
+	for _, encodedResult := range e.encoded {
+		if encodedResult.Status != PendingConfirmation || encodedResult.exceededMaxPendingConfirmation || !encodedResult.pendingConfirmationSince.Before(deadline) {
+			continue
+		}
+		encodedResult.exceededMaxPendingConfirmation = true
+		if encodedResult.BlobMetadata != nil {
+			exceeded[encodedResult.BlobMetadata.GetBlobKey()] = struct{}{}
+		}
+	}
+	blobKeys := make([]disperser.BlobKey, 0, len(exceeded))
+	for blobKey := range exceeded {
+		blobKeys = append(blobKeys, blobKey)
+	}
+	return blobKeys
+}
+
+// DeleteExpiredEncodingResults deletes the results that have been encoded before the given deadline and that aren't
+// pending confirmation, so that the results of blobs that are never batched don't hold on to memory. It returns the
+// number of deleted results.
+func (e *encodedBlobStore) DeleteExpiredEncodingResults(deadline time.Time) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	deleted := 0
+	for k, encodedResult := range e.encoded {
+		if encodedResult.Status == PendingConfirmation || !encodedResult.encodedAt.Before(deadline) {
+			continue
+		}
+		delete(e.encoded, k)
+		e.encodedResultSize -= getChunksSize(encodedResult)
+		deleted++
+	}
+	return deleted
+}
+
 func getRequestID(key disperser.BlobKey, quorumID core.QuorumID) requestID {
 	return requestID(fmt.Sprintf("%s-%d", key.String(), quorumID))
 }
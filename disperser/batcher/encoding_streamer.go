@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -11,7 +12,6 @@ import (
 	"github.com/Layr-Labs/eigenda/common"
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/disperser"
-	"github.com/wealdtech/go-merkletree"
 )
 
 const encodingInterval = 2 * time.Second
@@ -42,8 +42,36 @@ type StreamerConfig struct {
 	// TargetNumChunks is the target number of chunks per encoded blob
 	TargetNumChunks uint
 
-	// Maximum number of Blobs to fetch from store
+	// Maximum number of Blobs to fetch from store in each page of the queue
 	MaxBlobsToFetchFromStore int
+
+	// MaxPagesToFetchFromStore is the maximum number of pages of the queue read in each round of encoding requests. The
+	// next round resumes where the last one stopped. If 0, a single page is read.
+	MaxPagesToFetchFromStore int
+
+	// HighPriorityLaneWeight is the number of blobs selected for encoding from a priority lane for each blob selected from
+	// the lane below it, so that lower priority blobs aren't starved. If 0, blobs are strictly selected in priority order.
+	// The lanes are weighted within the pages read in each round and across rounds of encoding requests.
+	HighPriorityLaneWeight uint
+
+	// StallTimeout is how long the streamer can go without a successful encode while encoding requests are pending
+	// before the watchdog restarts it. 0 disables the watchdog.
+	StallTimeout time.Duration
+
+	// SigningScheme is the scheme recorded in the header of each batch, which operators sign the header with
+	SigningScheme core.SigningSchemeID
+
+	// MaxBlobQueueAge is how long an encoded result can wait to be batched before the encoded size notifier is triggered,
+	// regardless of the encoded size. 0 disables the trigger.
+	MaxBlobQueueAge time.Duration
+
+	// MaxEncodedResultAge is how long an encoded result that isn't pending confirmation is kept in the encoded blob store
+	// before it's deleted, so that the results of blobs that are never batched don't leak memory. 0 disables the sweep.
+	MaxEncodedResultAge time.Duration
+
+	// MaxPendingConfirmationDuration is how long an encoded result can be pending confirmation before its blob is
+	// counted as stuck in the metrics. 0 disables the count.
+	MaxPendingConfirmationDuration time.Duration
 }
 
 type EncodingStreamer struct {
@@ -55,19 +83,33 @@ type EncodingStreamer struct {
 	ReferenceBlockNumber uint
 	Pool                 common.WorkerPool
 	EncodedSizeNotifier  *EncodedSizeNotifier
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
 
 	blobStore             disperser.BlobStore
 	chainState            core.IndexedChainState
 	encoderClient         disperser.EncoderClient
 	assignmentCoordinator core.AssignmentCoordinator
 
-	encodingCtxCancelFuncs []context.CancelFunc
+	// encodingCtxCancelFuncs holds the cancel functions of the in-flight encoding requests of each blob
+	encodingCtxCancelFuncs map[disperser.BlobKey][]context.CancelFunc
 
 	metrics *EncodingStreamerMetrics
 	logger  common.Logger
 
+	// lanes selects the blobs to encode from the priority lanes
+	lanes *laneScheduler
+
 	// Used to keep track of the last evaluated key for fetching metadatas
 	exclusiveStartKey *disperser.BlobStoreExclusiveStartKey
+
+	// stopStreaming stops the goroutines started by the last call to startStreaming
+	stopStreaming context.CancelFunc
+	// lastProgressAt is the time of the last successful encode, or of the first encoding request after it
+	lastProgressAt time.Time
+	// awaitingEncode is set when an encoding request is made and reset when an encode succeeds
+	awaitingEncode bool
+	progressMu     sync.Mutex
 }
 
 type batch struct {
@@ -76,7 +118,7 @@ type batch struct {
 	BlobHeaders  []*core.BlobHeader
 	BatchHeader  *core.BatchHeader
 	State        *core.IndexedOperatorState
-	MerkleTree   *merkletree.MerkleTree
+	MerkleTree   *core.BatchMerkleTree
 }
 
 func NewEncodedSizeNotifier(notify chan struct{}, threshold uint64) *EncodedSizeNotifier {
@@ -106,18 +148,82 @@ func NewEncodingStreamer(
 		ReferenceBlockNumber:   uint(0),
 		Pool:                   workerPool,
 		EncodedSizeNotifier:    encodedSizeNotifier,
+		Now:                    time.Now,
 		blobStore:              blobStore,
 		chainState:             chainState,
 		encoderClient:          encoderClient,
 		assignmentCoordinator:  assignmentCoordinator,
-		encodingCtxCancelFuncs: make([]context.CancelFunc, 0),
+		encodingCtxCancelFuncs: make(map[disperser.BlobKey][]context.CancelFunc),
 		metrics:                metrics,
 		logger:                 logger,
-		exclusiveStartKey:      nil,
+		lanes:                  &laneScheduler{weight: config.HighPriorityLaneWeight, lane: core.MaxBlobPriority},
 	}, nil
 }
 
 func (e *EncodingStreamer) Start(ctx context.Context) error {
+	e.startStreaming(ctx)
+
+	if e.StallTimeout > 0 {
+		go e.watchdog(ctx)
+	}
+
+	return nil
+}
+
+// Stop stops requesting and processing encodings and cancels the in-flight encoding requests.
+// The streamer can be started again with Start.
+func (e *EncodingStreamer) Stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopStreaming != nil {
+		e.stopStreaming()
+		e.stopStreaming = nil
+	}
+	for _, cancelFuncs := range e.encodingCtxCancelFuncs {
+		for _, cancel := range cancelFuncs {
+			cancel()
+		}
+	}
+	e.encodingCtxCancelFuncs = make(map[disperser.BlobKey][]context.CancelFunc)
+	e.EncodedBlobstore.CancelAllEncodingRequests()
+}
+
+// watchdog restarts the streamer if there has been no successful encode for StallTimeout while encoding requests are pending
+func (e *EncodingStreamer) watchdog(ctx context.Context) {
+	ticker := time.NewTicker(e.StallTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.progressMu.Lock()
+			stalledFor := e.Now().Sub(e.lastProgressAt)
+			stalled := e.awaitingEncode && stalledFor > e.StallTimeout
+			if stalled {
+				// The in-flight requests are cancelled by the restart, so the stall clock starts again with the next request
+				e.awaitingEncode = false
+			}
+			e.progressMu.Unlock()
+			if !stalled {
+				continue
+			}
+
+			e.logger.Warn("[watchdog] no successful encode within the stall timeout, restarting the encoding streamer", "stalledFor", stalledFor, "stallTimeout", e.StallTimeout)
+			e.metrics.IncrementRestarts()
+			e.Stop()
+			e.startStreaming(ctx)
+		}
+	}
+}
+
+func (e *EncodingStreamer) startStreaming(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	e.mu.Lock()
+	e.stopStreaming = cancel
+	e.mu.Unlock()
+
 	encoderChan := make(chan EncodingResultOrStatus)
 
 	// goroutine for handling blob encoding responses
@@ -153,11 +259,11 @@ func (e *EncodingStreamer) Start(ctx context.Context) error {
 				if err != nil {
 					e.logger.Warn("error requesting encoding", "err", err)
 				}
+				e.CheckBlobQueueAge()
+				e.CheckPendingConfirmations()
 			}
 		}
 	}()
-
-	return nil
 }
 
 func (e *EncodingStreamer) dedupRequests(metadatas []*disperser.BlobMetadata, referenceBlockNumber uint) []*disperser.BlobMetadata {
@@ -181,12 +287,9 @@ func (e *EncodingStreamer) dedupRequests(metadatas []*disperser.BlobMetadata, re
 
 func (e *EncodingStreamer) RequestEncoding(ctx context.Context, encoderChan chan EncodingResultOrStatus) error {
 	stageTimer := time.Now()
-	// pull new blobs and send to encoder
-	e.mu.Lock()
-	metadatas, newExclusiveStartKey, err := e.blobStore.GetBlobMetadataByStatusWithPagination(ctx, disperser.Processing, int32(e.StreamerConfig.MaxBlobsToFetchFromStore), e.exclusiveStartKey)
-	e.exclusiveStartKey = newExclusiveStartKey
-	e.mu.Unlock()
-
+	// pull new blobs and send to encoder. High priority blobs are selected ahead of the normal ones within the pages
+	// read in this round.
+	metadatas, err := e.getQueuedMetadatas(ctx)
 	if err != nil {
 		return fmt.Errorf("error getting blob metadatas: %w", err)
 	}
@@ -222,17 +325,15 @@ func (e *EncodingStreamer) RequestEncoding(ctx context.Context, encoderChan chan
 
 	waitingQueueSize := e.Pool.WaitingQueueSize()
 	numMetadatastoProcess := e.EncodingQueueLimit - waitingQueueSize
-	if numMetadatastoProcess > len(metadatas) {
-		numMetadatastoProcess = len(metadatas)
-	}
 	if numMetadatastoProcess <= 0 {
 		// encoding queue is full
 		e.logger.Warn("[RequestEncoding] worker pool queue is full. skipping this round of encoding requests", "waitingQueueSize", waitingQueueSize, "encodingQueueLimit", e.EncodingQueueLimit)
 		return nil
 	}
 	// only process subset of blobs so it doesn't exceed the EncodingQueueLimit
-	// TODO: this should be done at the request time and keep the cursor so that we don't fetch the same metadata every time
-	metadatas = metadatas[:numMetadatastoProcess]
+	e.mu.Lock()
+	metadatas = e.lanes.selectBlobs(metadatas, numMetadatastoProcess)
+	e.mu.Unlock()
 
 	e.logger.Trace("[encodingstreamer] new metadatas to encode", "numMetadata", len(metadatas), "duration", time.Since(stageTimer))
 
@@ -266,6 +367,93 @@ func (e *EncodingStreamer) RequestEncoding(ctx context.Context, encoderChan chan
 	return nil
 }
 
+// getQueuedMetadatas returns the metadata of the blobs waiting to be encoded, by request time. At most
+// MaxPagesToFetchFromStore pages of MaxBlobsToFetchFromStore blobs are read, starting where the last call stopped. Once
+// the end of the queue is reached, the next call starts over from the beginning.
+func (e *EncodingStreamer) getQueuedMetadatas(ctx context.Context) ([]*disperser.BlobMetadata, error) {
+	maxPages := max(e.MaxPagesToFetchFromStore, 1)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var metadatas []*disperser.BlobMetadata
+	for i := 0; i < maxPages; i++ {
+		page, newExclusiveStartKey, err := e.blobStore.GetBlobMetadataByStatusWithPagination(ctx, disperser.Processing, int32(e.MaxBlobsToFetchFromStore), e.exclusiveStartKey)
+		if err != nil {
+			return nil, err
+		}
+		e.exclusiveStartKey = newExclusiveStartKey
+		metadatas = append(metadatas, page...)
+		if newExclusiveStartKey == nil {
+			break
+		}
+	}
+	return metadatas, nil
+}
+
+// laneScheduler selects the blobs to encode from the priority lanes, keeping the order of the blobs within each lane.
+// If weight is 0, blobs are selected strictly from the highest priority to the lowest. Otherwise, the lanes
+// take turns from the highest priority to the lowest, each lane selecting weight times as many blobs in its turn as the
+// lane below it. The turns carry over from one selection to the next, so that the lanes are weighted across rounds of
+// encoding requests rather than the highest lane going first in every round.
+type laneScheduler struct {
+	weight uint
+
+	// lane is the lane whose turn it is, starting from the highest priority lane, and taken is the number of blobs it
+	// selected in this turn
+	lane  core.BlobPriority
+	taken uint
+}
+
+// selectBlobs selects up to n of the metadatas
+func (s *laneScheduler) selectBlobs(metadatas []*disperser.BlobMetadata, n int) []*disperser.BlobMetadata {
+	lanes := make([][]*disperser.BlobMetadata, core.MaxBlobPriority+1)
+	for _, metadata := range metadatas {
+		priority := metadata.RequestMetadata.Priority
+		if priority > core.MaxBlobPriority {
+			priority = core.MaxBlobPriority
+		}
+		lanes[priority] = append(lanes[priority], metadata)
+	}
+	if n > len(metadatas) {
+		n = len(metadatas)
+	}
+
+	selected := make([]*disperser.BlobMetadata, 0, n)
+	if s.weight == 0 {
+		for priority := len(lanes) - 1; priority >= 0 && len(selected) < n; priority-- {
+			selected = append(selected, lanes[priority][:min(len(lanes[priority]), n-len(selected))]...)
+		}
+		return selected
+	}
+
+	for len(selected) < n {
+		if s.taken < s.laneWeight(s.lane) && len(lanes[s.lane]) > 0 {
+			selected = append(selected, lanes[s.lane][0])
+			lanes[s.lane] = lanes[s.lane][1:]
+			s.taken++
+			continue
+		}
+		// the lane used up its turn or has no blob left, so the turn passes to the lane below it, or back to the highest
+		// priority lane from the lowest
+		if s.lane == core.PriorityNormal {
+			s.lane = core.MaxBlobPriority
+		} else {
+			s.lane--
+		}
+		s.taken = 0
+	}
+	return selected
+}
+
+// laneWeight returns the number of blobs a lane selects in its turn
+func (s *laneScheduler) laneWeight(lane core.BlobPriority) uint {
+	weight := uint(1)
+	for priority := core.PriorityNormal; priority < lane; priority++ {
+		weight *= s.weight
+	}
+	return weight
+}
+
 type pendingRequestInfo struct {
 	BlobQuorumInfo *core.BlobQuorumInfo
 	EncodingParams core.EncodingParams
@@ -291,10 +479,30 @@ func (e *EncodingStreamer) RequestEncodingForBlob(ctx context.Context, metadata
 
 		blobLength := core.GetBlobLength(metadata.RequestMetadata.BlobSize)
 
-		chunkLength, err := e.assignmentCoordinator.CalculateChunkLength(state.OperatorState, blobLength, e.StreamerConfig.TargetNumChunks, quorum)
-		if err != nil {
-			e.logger.Error("[RequestEncodingForBlob] error calculating chunk length", "err", err)
-			continue
+		var chunkLength uint
+		if metadata.RequestMetadata.ChunkLength > 0 {
+			// The client pinned the chunk length, which is used as long as the assignment is feasible with it
+			chunkLength = metadata.RequestMetadata.ChunkLength
+			ok, err := e.assignmentCoordinator.ValidateChunkLength(state.OperatorState, blobLength, &core.BlobQuorumInfo{
+				SecurityParam: *quorum,
+				ChunkLength:   chunkLength,
+			})
+			if err != nil || !ok {
+				e.logger.Error("[RequestEncodingForBlob] invalid requested chunk length", "chunkLength", chunkLength, "quorum", quorum.QuorumID, "err", err)
+				// Cancel the blob
+				err := e.blobStore.MarkBlobFailed(ctx, blobKey)
+				if err != nil {
+					e.logger.Error("[RequestEncodingForBlob] error marking blob failed", "err", err)
+				}
+				return
+			}
+		} else {
+			var err error
+			chunkLength, err = e.assignmentCoordinator.CalculateChunkLength(state.OperatorState, blobLength, e.StreamerConfig.TargetNumChunks, quorum)
+			if err != nil {
+				e.logger.Error("[RequestEncodingForBlob] error calculating chunk length", "err", err)
+				continue
+			}
 		}
 
 		blobQuorumInfo := &core.BlobQuorumInfo{
@@ -337,6 +545,17 @@ func (e *EncodingStreamer) RequestEncodingForBlob(ctx context.Context, metadata
 		})
 	}
 
+	// The queue wait is only observed the first time a blob is encoded, not when it is re-encoded
+	// at a newer reference block
+	firstEncode := len(pending) > 0
+	for _, quorum := range metadata.RequestMetadata.SecurityParams {
+		if _, err := e.EncodedBlobstore.GetEncodingResult(blobKey, quorum.QuorumID); err == nil {
+			firstEncode = false
+			break
+		}
+	}
+	var observeQueueWait sync.Once
+
 	// Execute the encoding requests
 	for ind := range pending {
 
@@ -347,50 +566,76 @@ func (e *EncodingStreamer) RequestEncodingForBlob(ctx context.Context, metadata
 		// This is necessary because an encoding request is dependent on the reference block number
 		// If the reference block number changes, we need to cancel all outstanding encoding requests
 		// and re-request them with the new reference block number
+		// The request is also cancelled if the blob is removed while it is being encoded
 		encodingCtx, cancel := context.WithTimeout(ctx, e.EncodingRequestTimeout)
 		e.mu.Lock()
-		e.encodingCtxCancelFuncs = append(e.encodingCtxCancelFuncs, cancel)
+		e.encodingCtxCancelFuncs[blobKey] = append(e.encodingCtxCancelFuncs[blobKey], cancel)
 		e.mu.Unlock()
+		e.markEncodingRequested()
+		// The request is recorded before it's submitted, so that it can be cancelled before it completes
+		requestSeq := e.EncodedBlobstore.PutEncodingRequest(blobKey, res.BlobQuorumInfo.QuorumID)
 		e.Pool.Submit(func() {
 			defer cancel()
+			if firstEncode {
+				observeQueueWait.Do(func() {
+					requestTime := time.Unix(0, int64(metadata.RequestMetadata.RequestedAt))
+					e.metrics.ObserveQueueWaitLatency(float64(time.Since(requestTime).Milliseconds()))
+				})
+			}
 			commits, chunks, err := e.encoderClient.EncodeBlob(encodingCtx, blob.Data, res.EncodingParams)
-			if err != nil {
-				encoderChan <- EncodingResultOrStatus{Err: err, EncodingResult: EncodingResult{
-					BlobMetadata:   metadata,
-					BlobQuorumInfo: res.BlobQuorumInfo,
-				}}
-				return
+			if err == nil && errors.Is(encodingCtx.Err(), context.Canceled) {
+				// Discard the result if the request was cancelled before the encoder finished
+				err = fmt.Errorf("encoding request cancelled: %w", encodingCtx.Err())
+			}
+			result := EncodingResultOrStatus{Err: err, EncodingResult: EncodingResult{
+				BlobMetadata:   metadata,
+				BlobQuorumInfo: res.BlobQuorumInfo,
+				requestSeq:     requestSeq,
+			}}
+			if err == nil {
+				result = EncodingResultOrStatus{
+					EncodingResult: EncodingResult{
+						BlobMetadata:         metadata,
+						ReferenceBlockNumber: referenceBlockNumber,
+						BlobQuorumInfo:       res.BlobQuorumInfo,
+						Commitment:           commits,
+						Chunks:               chunks,
+						Assignments:          res.Assignments,
+						Status:               PendingDispersal,
+						requestSeq:           requestSeq,
+					},
+					Err: nil,
+				}
 			}
 
-			encoderChan <- EncodingResultOrStatus{
-				EncodingResult: EncodingResult{
-					BlobMetadata:         metadata,
-					ReferenceBlockNumber: referenceBlockNumber,
-					BlobQuorumInfo:       res.BlobQuorumInfo,
-					Commitment:           commits,
-					Chunks:               chunks,
-					Assignments:          res.Assignments,
-					Status:               PendingDispersal,
-				},
-				Err: nil,
+			select {
+			case encoderChan <- result:
+			case <-ctx.Done():
+				// The streamer was stopped and nothing processes the result anymore. Forget the request so that
+				// the blob is requested again once the streamer is restarted.
+				e.EncodedBlobstore.DeleteEncodingRequest(blobKey, res.BlobQuorumInfo.QuorumID, requestSeq)
 			}
 		})
-		e.EncodedBlobstore.PutEncodingRequest(blobKey, res.BlobQuorumInfo.QuorumID)
-
 	}
 
 }
 
 func (e *EncodingStreamer) ProcessEncodedBlobs(ctx context.Context, result EncodingResultOrStatus) error {
 	if result.Err != nil {
-		e.EncodedBlobstore.DeleteEncodingRequest(result.BlobMetadata.GetBlobKey(), result.BlobQuorumInfo.QuorumID)
+		e.EncodedBlobstore.DeleteEncodingRequest(result.BlobMetadata.GetBlobKey(), result.BlobQuorumInfo.QuorumID, result.requestSeq)
 		return fmt.Errorf("error encoding blob: %w", result.Err)
 	}
 
-	err := e.EncodedBlobstore.PutEncodingResult(&result.EncodingResult)
+	err := e.EncodedBlobstore.PutEncodingResult(&result.EncodingResult, e.Now())
+	if errors.Is(err, errEncodingRequestCancelled) {
+		// The blob was removed or the request cancelled while the blob was being encoded
+		e.logger.Debug("discarding the result of a cancelled encoding request", "blobKey", result.BlobMetadata.GetBlobKey().String(), "quorumID", result.BlobQuorumInfo.QuorumID)
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to putEncodedBlob: %w", err)
 	}
+	e.markEncodeSucceeded()
 
 	count, encodedSize := e.EncodedBlobstore.GetEncodedResultSize()
 	e.metrics.UpdateEncodedBlobs(count, encodedSize)
@@ -409,6 +654,92 @@ func (e *EncodingStreamer) ProcessEncodedBlobs(ctx context.Context, result Encod
 	return nil
 }
 
+// SweepExpiredEncodingResults deletes the encoded results that are older than MaxEncodedResultAge and aren't pending
+// confirmation. It returns the number of deleted results.
+func (e *EncodingStreamer) SweepExpiredEncodingResults() int {
+	if e.MaxEncodedResultAge == 0 {
+		return 0
+	}
+	swept := e.EncodedBlobstore.DeleteExpiredEncodingResults(e.Now().Add(-e.MaxEncodedResultAge))
+	if swept == 0 {
+		return 0
+	}
+	count, encodedSize := e.EncodedBlobstore.GetEncodedResultSize()
+	e.logger.Warn("swept expired encoded results", "count", swept, "maxAge", e.MaxEncodedResultAge, "encodedSize", encodedSize)
+	e.metrics.AddSweptEncodedResults(swept)
+	e.metrics.UpdateEncodedBlobs(count, encodedSize)
+	return swept
+}
+
+// CheckBlobQueueAge triggers the encoded size notifier if the oldest encoded result pending dispersal has waited longer
+// than MaxBlobQueueAge, so that blobs aren't held back when the size threshold is slow to fill up.
+// It returns whether the notifier was triggered.
+func (e *EncodingStreamer) CheckBlobQueueAge() bool {
+	if e.MaxBlobQueueAge == 0 {
+		return false
+	}
+	oldest, ok := e.EncodedBlobstore.GetOldestPendingDispersalRequestedAt()
+	if !ok {
+		return false
+	}
+	age := e.Now().Sub(time.Unix(0, int64(oldest)))
+	if age < e.MaxBlobQueueAge {
+		return false
+	}
+
+	e.EncodedSizeNotifier.mu.Lock()
+	defer e.EncodedSizeNotifier.mu.Unlock()
+	if !e.EncodedSizeNotifier.active {
+		return false
+	}
+	e.logger.Info("max blob queue age reached", "age", age, "maxBlobQueueAge", e.MaxBlobQueueAge)
+	e.EncodedSizeNotifier.Notify <- struct{}{}
+	// make sure this doesn't keep triggering before encoded blob store is reset
+	e.EncodedSizeNotifier.active = false
+	return true
+}
+
+// CheckPendingConfirmations updates the age of the oldest encoded result pending confirmation, and counts the blobs that
+// have been pending confirmation for longer than MaxPendingConfirmationDuration, each only once. Blobs that never leave
+// pending confirmation indicate a stuck batch. It returns the number of newly counted blobs.
+func (e *EncodingStreamer) CheckPendingConfirmations() int {
+	now := e.Now()
+	oldest, ok := e.EncodedBlobstore.GetOldestPendingConfirmationSince()
+	if !ok {
+		e.metrics.UpdateOldestPendingConfirmationAge(0)
+		return 0
+	}
+	e.metrics.UpdateOldestPendingConfirmationAge(now.Sub(oldest))
+
+	if e.MaxPendingConfirmationDuration == 0 {
+		return 0
+	}
+	exceeded := e.EncodedBlobstore.MarkExceededPendingConfirmations(now.Add(-e.MaxPendingConfirmationDuration))
+	if len(exceeded) == 0 {
+		return 0
+	}
+	e.logger.Warn("blobs pending confirmation for longer than the maximum duration", "count", len(exceeded), "maxPendingConfirmationDuration", e.MaxPendingConfirmationDuration, "oldestPendingSince", oldest)
+	e.metrics.AddExceededPendingConfirmations(len(exceeded))
+	return len(exceeded)
+}
+
+// markEncodingRequested starts the stall clock of the watchdog if no encoding request has been waiting for a result
+func (e *EncodingStreamer) markEncodingRequested() {
+	e.progressMu.Lock()
+	defer e.progressMu.Unlock()
+	if !e.awaitingEncode {
+		e.awaitingEncode = true
+		e.lastProgressAt = e.Now()
+	}
+}
+
+func (e *EncodingStreamer) markEncodeSucceeded() {
+	e.progressMu.Lock()
+	defer e.progressMu.Unlock()
+	e.awaitingEncode = false
+	e.lastProgressAt = e.Now()
+}
+
 // CreateBatch makes a batch from all blobs in the encoded blob store.
 // If successful, it returns a batch, and updates the reference block number for next batch to use.
 // Otherwise, it returns an error and keeps the blobs in the encoded blob store.
@@ -421,12 +752,18 @@ func (e *EncodingStreamer) CreateBatch() (*batch, error) {
 	// Assumption: `CreateBatch` will be called at an interval longer than time it takes to encode a single blob
 	if len(e.encodingCtxCancelFuncs) > 0 {
 		e.logger.Info("[CreateBatch] canceling outstanding encoding requests", "count", len(e.encodingCtxCancelFuncs))
-		for _, cancel := range e.encodingCtxCancelFuncs {
-			cancel()
+		for _, cancelFuncs := range e.encodingCtxCancelFuncs {
+			for _, cancel := range cancelFuncs {
+				cancel()
+			}
 		}
-		e.encodingCtxCancelFuncs = make([]context.CancelFunc, 0)
+		e.encodingCtxCancelFuncs = make(map[disperser.BlobKey][]context.CancelFunc)
+		e.EncodedBlobstore.CancelAllEncodingRequests()
 	}
 
+	// Delete the encoded results that have been kept for too long without being confirmed
+	e.SweepExpiredEncodingResults()
+
 	// If there were no requested blobs between the last batch and now, there is no need to create a new batch
 	if e.ReferenceBlockNumber == 0 {
 		blockNumber, err := e.chainState.GetCurrentBlockNumber()
@@ -438,16 +775,20 @@ func (e *EncodingStreamer) CreateBatch() (*batch, error) {
 		return nil, errNoEncodedResults
 	}
 
+	// Pin the reference block of the batch. The batch header, the operator state and the assignments of the encoded results
+	// all use this block number, even if the reference block of the streamer advances while the batch is in flight.
+	referenceBlockNumber := e.ReferenceBlockNumber
+
 	// Delete any encoded results that are not from the current batching iteration (i.e. that has different reference block number)
 	// If any pending encoded results are discarded here, it will be re-requested in the next iteration
-	encodedResults := e.EncodedBlobstore.GetNewAndDeleteStaleEncodingResults(e.ReferenceBlockNumber)
+	encodedResults := e.EncodedBlobstore.GetNewAndDeleteStaleEncodingResults(referenceBlockNumber)
 
 	// Reset the notifier
 	e.EncodedSizeNotifier.mu.Lock()
 	e.EncodedSizeNotifier.active = true
 	e.EncodedSizeNotifier.mu.Unlock()
 
-	e.logger.Info("[CreateBatch] creating a batch...", "numBlobs", len(encodedResults), "refblockNumber", e.ReferenceBlockNumber)
+	e.logger.Info("[CreateBatch] creating a batch...", "numBlobs", len(encodedResults), "refblockNumber", referenceBlockNumber)
 	if len(encodedResults) == 0 {
 		return nil, errNoEncodedResults
 	}
@@ -455,7 +796,6 @@ func (e *EncodingStreamer) CreateBatch() (*batch, error) {
 	encodedBlobByKey := make(map[disperser.BlobKey]core.EncodedBlob)
 	blobQuorums := make(map[disperser.BlobKey][]*core.BlobQuorumInfo)
 	blobHeaderByKey := make(map[disperser.BlobKey]*core.BlobHeader)
-	metadataByKey := make(map[disperser.BlobKey]*disperser.BlobMetadata)
 	for i := range encodedResults {
 		// each result represent an encoded result per (blob, quorum param)
 		// if the same blob has been dispersed multiple time with different security params,
@@ -463,7 +803,6 @@ func (e *EncodingStreamer) CreateBatch() (*batch, error) {
 		result := encodedResults[i]
 		blobKey := result.BlobMetadata.GetBlobKey()
 		if _, ok := encodedBlobByKey[blobKey]; !ok {
-			metadataByKey[blobKey] = result.BlobMetadata
 			blobQuorums[blobKey] = make([]*core.BlobQuorumInfo, 0)
 			encodedBlobByKey[blobKey] = make(core.EncodedBlob)
 		}
@@ -495,21 +834,9 @@ func (e *EncodingStreamer) CreateBatch() (*batch, error) {
 		}
 	}
 
-	for blobKey, metadata := range metadataByKey {
-		quorumPresent := make(map[core.QuorumID]bool)
-		for _, quorum := range blobQuorums[blobKey] {
-			quorumPresent[quorum.QuorumID] = true
-		}
-		for _, quorum := range metadata.RequestMetadata.SecurityParams {
-			_, ok := quorumPresent[quorum.QuorumID]
-			if !ok {
-				// Delete the blobKey. These encoded blobs will be automatically removed by the next run of
-				// RequestEncoding
-				delete(metadataByKey, blobKey)
-				break
-			}
-		}
-	}
+	// Blobs without the encoded results of all their quorums are left out. These encoded blobs will be automatically
+	// removed by the next run of RequestEncoding
+	metadataByKey := getCompleteBlobs(encodedResults)
 
 	// Transform maps to slices so orders in different slices match
 	encodedBlobs := make([]core.EncodedBlob, len(metadataByKey))
@@ -523,15 +850,19 @@ func (e *EncodingStreamer) CreateBatch() (*batch, error) {
 		i++
 	}
 
-	state, err := e.getOperatorState(context.Background(), metadatas, e.ReferenceBlockNumber)
+	state, err := e.getOperatorState(context.Background(), metadatas, referenceBlockNumber)
 	if err != nil {
 		return nil, err
 	}
+	if err := state.ValidateReferenceBlock(referenceBlockNumber); err != nil {
+		return nil, err
+	}
 
 	// Populate the batch header
 	batchHeader := &core.BatchHeader{
-		ReferenceBlockNumber: e.ReferenceBlockNumber,
+		ReferenceBlockNumber: referenceBlockNumber,
 		BatchRoot:            [32]byte{},
+		SigningScheme:        e.SigningScheme,
 	}
 
 	tree, err := batchHeader.SetBatchRoot(blobHeaders)
@@ -551,15 +882,114 @@ func (e *EncodingStreamer) CreateBatch() (*batch, error) {
 	}, nil
 }
 
+// PreviewBatchPlan returns the plan of the batch that CreateBatch would create from the current encoded results, without
+// creating the batch or changing the state of the streamer, so that it can be logged or served for diagnostics. The
+// plan is empty if there are no blobs to batch. The quorums of the plan aggregate the assignments of the included
+// blobs: the assignment of each operator counts the chunks of all the blobs assigned to it in the quorum, and the total
+// chunks count the chunks assigned to all the operators.
+func (e *EncodingStreamer) PreviewBatchPlan() (*BatchPlan, error) {
+	e.mu.RLock()
+	referenceBlockNumber := e.ReferenceBlockNumber
+	e.mu.RUnlock()
+
+	plan := &BatchPlan{
+		IncludedBlobs: make([]*disperser.BlobMetadata, 0),
+		Quorums:       make(map[core.QuorumID]QuorumInfo),
+	}
+	if referenceBlockNumber == 0 {
+		return plan, nil
+	}
+
+	encodedResults := e.EncodedBlobstore.GetNewEncodingResults(referenceBlockNumber)
+	metadataByKey := getCompleteBlobs(encodedResults)
+	if len(metadataByKey) == 0 {
+		return plan, nil
+	}
+	for _, metadata := range metadataByKey {
+		plan.IncludedBlobs = append(plan.IncludedBlobs, metadata)
+	}
+	sort.Slice(plan.IncludedBlobs, func(i, j int) bool {
+		return plan.IncludedBlobs[i].GetBlobKey().String() < plan.IncludedBlobs[j].GetBlobKey().String()
+	})
+
+	for _, result := range encodedResults {
+		if _, ok := metadataByKey[result.BlobMetadata.GetBlobKey()]; !ok {
+			continue
+		}
+		quorumID := result.BlobQuorumInfo.QuorumID
+		quorum, ok := plan.Quorums[quorumID]
+		if !ok {
+			quorum = QuorumInfo{
+				Assignments:        make(map[core.OperatorID]core.Assignment),
+				QuantizationFactor: QuantizationFactor,
+			}
+		}
+		for opID, assignment := range result.Assignments {
+			total := quorum.Assignments[opID]
+			total.NumChunks += assignment.NumChunks
+			quorum.Assignments[opID] = total
+			quorum.Info.TotalChunks += assignment.NumChunks
+		}
+		plan.Quorums[quorumID] = quorum
+	}
+
+	state, err := e.getOperatorState(context.Background(), plan.IncludedBlobs, referenceBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	plan.State = state
+	return plan, nil
+}
+
+// getCompleteBlobs returns the metadata of the blobs of the encoded results that have been encoded for all the quorums
+// they are to be dispersed to
+func getCompleteBlobs(encodedResults []*EncodingResult) map[disperser.BlobKey]*disperser.BlobMetadata {
+	metadataByKey := make(map[disperser.BlobKey]*disperser.BlobMetadata)
+	quorumPresent := make(map[disperser.BlobKey]map[core.QuorumID]bool)
+	for _, result := range encodedResults {
+		blobKey := result.BlobMetadata.GetBlobKey()
+		if _, ok := metadataByKey[blobKey]; !ok {
+			metadataByKey[blobKey] = result.BlobMetadata
+			quorumPresent[blobKey] = make(map[core.QuorumID]bool)
+		}
+		quorumPresent[blobKey][result.BlobQuorumInfo.QuorumID] = true
+	}
+
+	for blobKey, metadata := range metadataByKey {
+		for _, quorum := range metadata.RequestMetadata.SecurityParams {
+			if !quorumPresent[blobKey][quorum.QuorumID] {
+				delete(metadataByKey, blobKey)
+				break
+			}
+		}
+	}
+	return metadataByKey
+}
+
+// RemoveEncodedBlob removes the encoding results of the blob and cancels any of its in-flight encoding requests, whose
+// results are then discarded even if they complete
 func (e *EncodingStreamer) RemoveEncodedBlob(metadata *disperser.BlobMetadata) {
+	blobKey := metadata.GetBlobKey()
+	e.mu.Lock()
+	cancelFuncs, ok := e.encodingCtxCancelFuncs[blobKey]
+	delete(e.encodingCtxCancelFuncs, blobKey)
+	e.mu.Unlock()
+	if ok {
+		e.logger.Debug("[RemoveEncodedBlob] canceling in-flight encoding requests", "blobKey", blobKey.String(), "count", len(cancelFuncs))
+		for _, cancel := range cancelFuncs {
+			cancel()
+		}
+	}
+
 	for _, sp := range metadata.RequestMetadata.SecurityParams {
+		e.EncodedBlobstore.CancelEncodingRequest(metadata.GetBlobKey(), sp.QuorumID)
 		e.EncodedBlobstore.DeleteEncodingResult(metadata.GetBlobKey(), sp.QuorumID)
 	}
 }
 
 func (e *EncodingStreamer) MarkBlobPendingConfirmation(metadata *disperser.BlobMetadata) error {
 	for _, sp := range metadata.RequestMetadata.SecurityParams {
-		err := e.EncodedBlobstore.MarkEncodedResultPendingConfirmation(metadata.GetBlobKey(), sp.QuorumID)
+		err := e.EncodedBlobstore.MarkEncodedResultPendingConfirmation(metadata.GetBlobKey(), sp.QuorumID, e.Now())
 		if err != nil {
 			return fmt.Errorf("error marking blob pending confirmation: %w", err)
 		}
@@ -567,6 +997,16 @@ func (e *EncodingStreamer) MarkBlobPendingConfirmation(metadata *disperser.BlobM
 	return nil
 }
 
+// IsBlobPendingConfirmation returns whether the blob is part of a batch pending confirmation
+func (e *EncodingStreamer) IsBlobPendingConfirmation(metadata *disperser.BlobMetadata) bool {
+	for _, sp := range metadata.RequestMetadata.SecurityParams {
+		if e.EncodedBlobstore.IsPendingConfirmation(metadata.GetBlobKey(), sp.QuorumID) {
+			return true
+		}
+	}
+	return false
+}
+
 // getOperatorState returns the operator state for the blobs that have valid quorums
 func (e *EncodingStreamer) getOperatorState(ctx context.Context, metadatas []*disperser.BlobMetadata, blockNumber uint) (*core.IndexedOperatorState, error) {
 
@@ -606,7 +1046,7 @@ func (e *EncodingStreamer) validateMetadataQuorums(metadatas []*disperser.BlobMe
 		if valid {
 			validMetadata = append(validMetadata, metadata)
 		} else {
-			err := e.blobStore.HandleBlobFailure(context.Background(), metadata, 0)
+			_, err := e.blobStore.HandleBlobFailure(context.Background(), metadata, 0)
 			if err != nil {
 				e.logger.Error("error handling blob failure", "err", err)
 			}
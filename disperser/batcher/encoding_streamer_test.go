@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -15,6 +16,8 @@ import (
 	"github.com/Layr-Labs/eigenda/disperser/common/inmem"
 	"github.com/Layr-Labs/eigenda/disperser/mock"
 	"github.com/gammazero/workerpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	tmock "github.com/stretchr/testify/mock"
 )
@@ -346,6 +349,124 @@ func TestEncodingFailure(t *testing.T) {
 	assert.False(t, isRequested)
 }
 
+func TestRemoveBlobCancelsEncoding(t *testing.T) {
+	logger := &cmock.Logger{}
+	blobStore := inmem.NewBlobStore()
+	cst, err := coremock.MakeChainDataMock(numOperators)
+	assert.Nil(t, err)
+	encoderClient := mock.NewMockEncoderClient()
+	asgn := &core.StdAssignmentCoordinator{}
+	sizeNotifier := batcher.NewEncodedSizeNotifier(make(chan struct{}, 1), 1e12)
+	workerpool := workerpool.New(5)
+	metrics := batcher.NewMetrics("9100", logger)
+	encodingStreamer, err := batcher.NewEncodingStreamer(streamerConfig, blobStore, cst, encoderClient, asgn, sizeNotifier, workerpool, metrics.EncodingStreamerMetrics, logger)
+	assert.Nil(t, err)
+	encodingStreamer.ReferenceBlockNumber = 10
+
+	ctx := context.Background()
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	metadataKey, err := blobStore.StoreBlob(ctx, &blob, uint64(time.Now().UnixNano()))
+	assert.Nil(t, err)
+	metadata, err := blobStore.GetBlobMetadata(ctx, metadataKey)
+	assert.Nil(t, err)
+
+	// the encoder blocks until released and ignores cancellation of its context
+	started := make(chan struct{})
+	release := make(chan struct{})
+	cst.On("GetCurrentBlockNumber").Return(uint(10), nil)
+	encoderClient.On("EncodeBlob", tmock.Anything, tmock.Anything, tmock.Anything).Run(func(args tmock.Arguments) {
+		close(started)
+		<-release
+	}).Return(&core.BlobCommitments{}, []*core.Chunk{}, nil)
+
+	out := make(chan batcher.EncodingResultOrStatus)
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	<-started
+
+	encodingStreamer.RemoveEncodedBlob(metadata)
+	close(release)
+
+	err = encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.ErrorIs(t, err, context.Canceled)
+	res, err := encodingStreamer.EncodedBlobstore.GetEncodingResult(metadataKey, core.QuorumID(0))
+	assert.ErrorContains(t, err, "no such key")
+	assert.Nil(t, res)
+	isRequested := encodingStreamer.EncodedBlobstore.HasEncodingRequested(metadataKey, core.QuorumID(0), 10)
+	assert.False(t, isRequested)
+}
+
+func TestEncodingResultDiscardedAfterRemoval(t *testing.T) {
+	logger := &cmock.Logger{}
+	blobStore := inmem.NewBlobStore()
+	cst, err := coremock.MakeChainDataMock(numOperators)
+	assert.Nil(t, err)
+	encoderClient := mock.NewMockEncoderClient()
+	asgn := &core.StdAssignmentCoordinator{}
+	sizeNotifier := batcher.NewEncodedSizeNotifier(make(chan struct{}, 1), 1e12)
+	workerpool := workerpool.New(5)
+	metrics := batcher.NewMetrics("9100", logger)
+	encodingStreamer, err := batcher.NewEncodingStreamer(streamerConfig, blobStore, cst, encoderClient, asgn, sizeNotifier, workerpool, metrics.EncodingStreamerMetrics, logger)
+	assert.Nil(t, err)
+	encodingStreamer.ReferenceBlockNumber = 10
+
+	ctx := context.Background()
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	metadataKey, err := blobStore.StoreBlob(ctx, &blob, uint64(time.Now().UnixNano()))
+	assert.Nil(t, err)
+	metadata, err := blobStore.GetBlobMetadata(ctx, metadataKey)
+	assert.Nil(t, err)
+
+	cst.On("GetCurrentBlockNumber").Return(uint(10), nil)
+	encoderClient.On("EncodeBlob", tmock.Anything, tmock.Anything, tmock.Anything).Return(&core.BlobCommitments{}, []*core.Chunk{}, nil)
+
+	// the encoding finishes and is handed off before the blob is removed,
+	// but is only processed afterwards
+	out := make(chan batcher.EncodingResultOrStatus, 1)
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	result := <-out
+	assert.Nil(t, result.Err)
+
+	encodingStreamer.RemoveEncodedBlob(metadata)
+
+	err = encodingStreamer.ProcessEncodedBlobs(ctx, result)
+	assert.Nil(t, err)
+	res, err := encodingStreamer.EncodedBlobstore.GetEncodingResult(metadataKey, core.QuorumID(0))
+	assert.ErrorContains(t, err, "no such key")
+	assert.Nil(t, res)
+	count, size := encodingStreamer.EncodedBlobstore.GetEncodedResultSize()
+	assert.Equal(t, 0, count)
+	assert.Equal(t, uint64(0), size)
+
+	// a result for a request that was superseded by a newer one is discarded
+	// without clearing the newer request
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	stale := <-out
+	encodingStreamer.RemoveEncodedBlob(metadata)
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	fresh := <-out
+
+	err = encodingStreamer.ProcessEncodedBlobs(ctx, stale)
+	assert.Nil(t, err)
+	assert.True(t, encodingStreamer.EncodedBlobstore.HasEncodingRequested(metadataKey, core.QuorumID(0), 10))
+	err = encodingStreamer.ProcessEncodedBlobs(ctx, fresh)
+	assert.Nil(t, err)
+	res, err = encodingStreamer.EncodedBlobstore.GetEncodingResult(metadataKey, core.QuorumID(0))
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+}
+
 func TestPartialBlob(t *testing.T) {
 	encodingStreamer, c := createEncodingStreamer(t, 10, 1e12, streamerConfig)
 
@@ -723,3 +844,664 @@ func TestGetBatch(t *testing.T) {
 	assert.Contains(t, batch.BlobMetadata, metadata1)
 	assert.Contains(t, batch.BlobMetadata, metadata2)
 }
+
+func TestPreviewBatchPlan(t *testing.T) {
+	encodingStreamer, c := createEncodingStreamer(t, 10, 1e12, streamerConfig)
+	ctx := context.Background()
+	c.chainDataMock.On("GetCurrentBlockNumber").Return(uint(10), nil)
+
+	// nothing is encoded yet
+	plan, err := encodingStreamer.PreviewBatchPlan()
+	assert.Nil(t, err)
+	assert.Empty(t, plan.IncludedBlobs)
+
+	blob1 := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}, {
+		QuorumID:           1,
+		AdversaryThreshold: 70,
+		QuorumThreshold:    95,
+	}})
+	blob2 := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}, {
+		QuorumID:           2,
+		AdversaryThreshold: 75,
+		QuorumThreshold:    100,
+	}})
+	metadataKey1, err := c.blobStore.StoreBlob(ctx, &blob1, uint64(time.Now().UnixNano()))
+	assert.Nil(t, err)
+	metadataKey2, err := c.blobStore.StoreBlob(ctx, &blob2, uint64(time.Now().UnixNano()))
+	assert.Nil(t, err)
+
+	out := make(chan batcher.EncodingResultOrStatus)
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	for i := 0; i < 4; i++ {
+		err = encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+		assert.Nil(t, err)
+	}
+	encodingStreamer.Pool.StopWait()
+	// blob2 misses the encoded result of one of its quorums, so it isn't batched
+	encodingStreamer.EncodedBlobstore.DeleteEncodingResult(metadataKey2, 2)
+
+	plan, err = encodingStreamer.PreviewBatchPlan()
+	assert.Nil(t, err)
+	assert.Len(t, plan.IncludedBlobs, 1)
+	assert.Equal(t, metadataKey1, plan.IncludedBlobs[0].GetBlobKey())
+	assert.NotNil(t, plan.State)
+	assert.Len(t, plan.Quorums, 2)
+	for _, quorumID := range []core.QuorumID{0, 1} {
+		result, err := encodingStreamer.EncodedBlobstore.GetEncodingResult(metadataKey1, quorumID)
+		assert.Nil(t, err)
+		quorum := plan.Quorums[quorumID]
+		var totalChunks core.ChunkNumber
+		for opID, assignment := range result.Assignments {
+			assert.Equal(t, assignment.NumChunks, quorum.Assignments[opID].NumChunks)
+			totalChunks += assignment.NumChunks
+		}
+		assert.Equal(t, totalChunks, quorum.Info.TotalChunks)
+	}
+
+	// the preview doesn't change the state of the streamer, and matches the batch that is created
+	assert.Equal(t, uint(10), encodingStreamer.ReferenceBlockNumber)
+	batch, err := encodingStreamer.CreateBatch()
+	assert.Nil(t, err)
+	batchKeys := make([]disperser.BlobKey, len(batch.BlobMetadata))
+	for i, metadata := range batch.BlobMetadata {
+		batchKeys[i] = metadata.GetBlobKey()
+	}
+	previewKeys := make([]disperser.BlobKey, len(plan.IncludedBlobs))
+	for i, metadata := range plan.IncludedBlobs {
+		previewKeys[i] = metadata.GetBlobKey()
+	}
+	assert.ElementsMatch(t, previewKeys, batchKeys)
+
+	// the encoded results are consumed by the batch
+	plan, err = encodingStreamer.PreviewBatchPlan()
+	assert.Nil(t, err)
+	assert.Empty(t, plan.IncludedBlobs)
+}
+
+func TestPriorityLanes(t *testing.T) {
+	config := streamerConfig
+	config.EncodingQueueLimit = 1
+	encodingStreamer, c := createEncodingStreamer(t, 10, 1e12, config)
+	c.chainDataMock.On("GetCurrentBlockNumber").Return(uint(10), nil)
+
+	ctx := context.Background()
+	securityParams := []*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}}
+	lowPriorityBlob := makeTestBlob(securityParams)
+	highPriorityBlob := makeTestBlob(securityParams)
+	highPriorityBlob.RequestHeader.Priority = core.PriorityHigh
+	requestedAt := uint64(time.Now().UnixNano())
+	lowPriorityKey, err := c.blobStore.StoreBlob(ctx, &lowPriorityBlob, requestedAt)
+	assert.Nil(t, err)
+	highPriorityKey, err := c.blobStore.StoreBlob(ctx, &highPriorityBlob, requestedAt+1)
+	assert.Nil(t, err)
+
+	// only one blob can be encoded at a time, and the high priority blob goes first even though it was queued last
+	out := make(chan batcher.EncodingResultOrStatus)
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	err = encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.Nil(t, err)
+	batch, err := encodingStreamer.CreateBatch()
+	assert.Nil(t, err)
+	assert.Len(t, batch.BlobMetadata, 1)
+	assert.Equal(t, highPriorityKey, batch.BlobMetadata[0].GetBlobKey())
+	err = c.blobStore.MarkBlobFinalized(ctx, highPriorityKey)
+	assert.Nil(t, err)
+	encodingStreamer.RemoveEncodedBlob(batch.BlobMetadata[0])
+
+	encodingStreamer.ReferenceBlockNumber = 10
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	err = encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.Nil(t, err)
+	batch, err = encodingStreamer.CreateBatch()
+	assert.Nil(t, err)
+	assert.Len(t, batch.BlobMetadata, 1)
+	assert.Equal(t, lowPriorityKey, batch.BlobMetadata[0].GetBlobKey())
+}
+
+func TestPriorityLaneFairness(t *testing.T) {
+	config := streamerConfig
+	config.EncodingQueueLimit = 3
+	config.HighPriorityLaneWeight = 2
+	encodingStreamer, c := createEncodingStreamer(t, 10, 1e12, config)
+	c.chainDataMock.On("GetCurrentBlockNumber").Return(uint(10), nil)
+
+	ctx := context.Background()
+	securityParams := []*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}}
+	requestedAt := uint64(time.Now().UnixNano())
+	lowPriorityBlob := makeTestBlob(securityParams)
+	lowPriorityKey, err := c.blobStore.StoreBlob(ctx, &lowPriorityBlob, requestedAt)
+	assert.Nil(t, err)
+	highPriorityKeys := make(map[disperser.BlobKey]struct{})
+	for i := 0; i < 3; i++ {
+		highPriorityBlob := makeTestBlob(securityParams)
+		highPriorityBlob.RequestHeader.Priority = core.PriorityHigh
+		key, err := c.blobStore.StoreBlob(ctx, &highPriorityBlob, requestedAt+uint64(i)+1)
+		assert.Nil(t, err)
+		highPriorityKeys[key] = struct{}{}
+	}
+
+	// two high priority blobs are encoded for each low priority blob, so the low priority blob isn't starved
+	out := make(chan batcher.EncodingResultOrStatus)
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		err = encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+		assert.Nil(t, err)
+	}
+	batch, err := encodingStreamer.CreateBatch()
+	assert.Nil(t, err)
+	assert.Len(t, batch.BlobMetadata, 3)
+	numHighPriority := 0
+	hasLowPriority := false
+	for _, metadata := range batch.BlobMetadata {
+		if _, ok := highPriorityKeys[metadata.GetBlobKey()]; ok {
+			numHighPriority++
+		}
+		if metadata.GetBlobKey() == lowPriorityKey {
+			hasLowPriority = true
+		}
+	}
+	assert.Equal(t, 2, numHighPriority)
+	assert.True(t, hasLowPriority)
+}
+
+func TestPriorityLanesAcrossPages(t *testing.T) {
+	config := streamerConfig
+	config.EncodingQueueLimit = 1
+	config.MaxBlobsToFetchFromStore = 1
+	config.MaxPagesToFetchFromStore = 4
+	config.HighPriorityLaneWeight = 0
+	encodingStreamer, c := createEncodingStreamer(t, 10, 1e12, config)
+	c.chainDataMock.On("GetCurrentBlockNumber").Return(uint(10), nil)
+
+	ctx := context.Background()
+	securityParams := []*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}}
+	requestedAt := uint64(time.Now().UnixNano())
+	for i := 0; i < 3; i++ {
+		blob := makeTestBlob(securityParams)
+		_, err := c.blobStore.StoreBlob(ctx, &blob, requestedAt+uint64(i))
+		assert.Nil(t, err)
+	}
+	highPriorityBlob := makeTestBlob(securityParams)
+	highPriorityBlob.RequestHeader.Priority = core.PriorityHigh
+	highPriorityKey, err := c.blobStore.StoreBlob(ctx, &highPriorityBlob, requestedAt+3)
+	assert.Nil(t, err)
+
+	// the high priority blob is selected first even though it's queued pages behind the normal ones
+	out := make(chan batcher.EncodingResultOrStatus)
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	result := <-out
+	assert.Nil(t, result.Err)
+	assert.Equal(t, highPriorityKey, result.BlobMetadata.GetBlobKey())
+}
+
+func TestPriorityLanesBoundedWindow(t *testing.T) {
+	config := streamerConfig
+	config.EncodingQueueLimit = 1
+	config.MaxBlobsToFetchFromStore = 1
+	config.MaxPagesToFetchFromStore = 2
+	config.HighPriorityLaneWeight = 0
+	encodingStreamer, c := createEncodingStreamer(t, 10, 1e12, config)
+	c.chainDataMock.On("GetCurrentBlockNumber").Return(uint(10), nil)
+
+	ctx := context.Background()
+	securityParams := []*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}}
+	requestedAt := uint64(time.Now().UnixNano())
+	blob := makeTestBlob(securityParams)
+	firstKey, err := c.blobStore.StoreBlob(ctx, &blob, requestedAt)
+	assert.Nil(t, err)
+	for i := 1; i < 3; i++ {
+		blob := makeTestBlob(securityParams)
+		_, err := c.blobStore.StoreBlob(ctx, &blob, requestedAt+uint64(i))
+		assert.Nil(t, err)
+	}
+	highPriorityBlob := makeTestBlob(securityParams)
+	highPriorityBlob.RequestHeader.Priority = core.PriorityHigh
+	highPriorityKey, err := c.blobStore.StoreBlob(ctx, &highPriorityBlob, requestedAt+3)
+	assert.Nil(t, err)
+
+	// the high priority blob is past the pages read in the first round, so the first normal blob is selected
+	out := make(chan batcher.EncodingResultOrStatus)
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	result := <-out
+	assert.Nil(t, result.Err)
+	assert.Equal(t, firstKey, result.BlobMetadata.GetBlobKey())
+
+	// the next round resumes where the first one stopped and selects the high priority blob first
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	result = <-out
+	assert.Nil(t, result.Err)
+	assert.Equal(t, highPriorityKey, result.BlobMetadata.GetBlobKey())
+}
+
+func TestPriorityLaneFairnessAcrossRounds(t *testing.T) {
+	config := streamerConfig
+	config.EncodingQueueLimit = 1
+	config.HighPriorityLaneWeight = 2
+	encodingStreamer, c := createEncodingStreamer(t, 10, 1e12, config)
+	c.chainDataMock.On("GetCurrentBlockNumber").Return(uint(10), nil)
+
+	ctx := context.Background()
+	securityParams := []*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}}
+	requestedAt := uint64(time.Now().UnixNano())
+	lowPriorityBlob := makeTestBlob(securityParams)
+	_, err := c.blobStore.StoreBlob(ctx, &lowPriorityBlob, requestedAt)
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		highPriorityBlob := makeTestBlob(securityParams)
+		highPriorityBlob.RequestHeader.Priority = core.PriorityHigh
+		_, err := c.blobStore.StoreBlob(ctx, &highPriorityBlob, requestedAt+uint64(i)+1)
+		assert.Nil(t, err)
+	}
+
+	// one blob is encoded per round, and the turns of the lanes carry over between rounds, so the low priority blob is
+	// encoded after two high priority blobs rather than after all of them
+	out := make(chan batcher.EncodingResultOrStatus)
+	priorities := make([]core.BlobPriority, 0, 4)
+	for i := 0; i < 4; i++ {
+		err = encodingStreamer.RequestEncoding(ctx, out)
+		assert.Nil(t, err)
+		result := <-out
+		assert.Nil(t, result.Err)
+		priorities = append(priorities, result.BlobMetadata.RequestMetadata.Priority)
+		err = encodingStreamer.ProcessEncodedBlobs(ctx, result)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, []core.BlobPriority{core.PriorityHigh, core.PriorityHigh, core.PriorityNormal, core.PriorityHigh}, priorities)
+}
+
+func TestComputeBatchRoot(t *testing.T) {
+	encodingStreamer, c := createEncodingStreamer(t, 10, 1e12, streamerConfig)
+
+	ctx := context.Background()
+	securityParams := []*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}}
+	numBlobs := 3
+	for i := 0; i < numBlobs; i++ {
+		blob := makeTestBlob(securityParams)
+		blob.Data = append([]byte{byte(i)}, blob.Data...)
+		_, err := c.blobStore.StoreBlob(ctx, &blob, uint64(time.Now().UnixNano()))
+		assert.Nil(t, err)
+	}
+
+	out := make(chan batcher.EncodingResultOrStatus)
+	err := encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	for i := 0; i < numBlobs; i++ {
+		err = encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+		assert.Nil(t, err)
+	}
+	batch, err := encodingStreamer.CreateBatch()
+	assert.Nil(t, err)
+	assert.Len(t, batch.BlobHeaders, numBlobs)
+
+	root, err := core.ComputeBatchRoot(batch.BlobHeaders)
+	assert.Nil(t, err)
+	assert.Equal(t, batch.BatchHeader.BatchRoot, root)
+
+	// the root depends on the order of the blob headers
+	reordered := []*core.BlobHeader{batch.BlobHeaders[1], batch.BlobHeaders[0], batch.BlobHeaders[2]}
+	root, err = core.ComputeBatchRoot(reordered)
+	assert.Nil(t, err)
+	assert.NotEqual(t, batch.BatchHeader.BatchRoot, root)
+}
+
+func TestStalledEncodingRestartsStreamer(t *testing.T) {
+	logger := &cmock.Logger{}
+	blobStore := inmem.NewBlobStore()
+	cst, err := coremock.MakeChainDataMock(numOperators)
+	assert.Nil(t, err)
+	// The encoder never replies and only returns once the request is cancelled
+	var numEncodeCalls atomic.Int32
+	encoderClient := mock.NewMockEncoderClient()
+	encoderClient.On("EncodeBlob", tmock.Anything, tmock.Anything, tmock.Anything).Run(func(args tmock.Arguments) {
+		numEncodeCalls.Add(1)
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil, nil, context.Canceled)
+	asgn := &core.StdAssignmentCoordinator{}
+	sizeNotifier := batcher.NewEncodedSizeNotifier(make(chan struct{}, 1), 100000)
+	metrics := batcher.NewMetrics("9100", logger)
+	config := streamerConfig
+	config.EncodingRequestTimeout = time.Minute
+	config.StallTimeout = 500 * time.Millisecond
+	encodingStreamer, err := batcher.NewEncodingStreamer(config, blobStore, cst, encoderClient, asgn, sizeNotifier, workerpool.New(5), metrics.EncodingStreamerMetrics, logger)
+	assert.Nil(t, err)
+	encodingStreamer.ReferenceBlockNumber = 10
+
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err = blobStore.StoreBlob(ctx, &blob, uint64(time.Now().UnixNano()))
+	assert.Nil(t, err)
+
+	err = encodingStreamer.Start(ctx)
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.EncodingStreamerMetrics.Restarts) >= 1
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// The stalled request is cancelled by the restart and the blob is requested again by the restarted streamer
+	assert.Eventually(t, func() bool {
+		return numEncodeCalls.Load() >= 2
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func TestQueueWaitLatencyObservedOnFirstEncode(t *testing.T) {
+	logger := &cmock.Logger{}
+	blobStore := inmem.NewBlobStore()
+	cst, err := coremock.MakeChainDataMock(numOperators)
+	assert.Nil(t, err)
+	enc, err := makeTestEncoder()
+	assert.Nil(t, err)
+	encoderClient := disperser.NewLocalEncoderClient(enc)
+	asgn := &core.StdAssignmentCoordinator{}
+	sizeNotifier := batcher.NewEncodedSizeNotifier(make(chan struct{}, 1), 100000)
+	metrics := batcher.NewMetrics("9100", logger)
+	encodingStreamer, err := batcher.NewEncodingStreamer(streamerConfig, blobStore, cst, encoderClient, asgn, sizeNotifier, workerpool.New(5), metrics.EncodingStreamerMetrics, logger)
+	assert.Nil(t, err)
+	encodingStreamer.ReferenceBlockNumber = 10
+
+	queueWaitSamples := func() (uint64, float64) {
+		m := &dto.Metric{}
+		assert.Nil(t, metrics.EncodingStreamerMetrics.QueueWaitLatency.Write(m))
+		return m.GetSummary().GetSampleCount(), m.GetSummary().GetSampleSum()
+	}
+
+	blob := makeTestBlob([]*core.SecurityParam{
+		{
+			QuorumID:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+		{
+			QuorumID:           1,
+			AdversaryThreshold: 70,
+			QuorumThreshold:    95,
+		},
+	})
+	ctx := context.Background()
+	// the blob has been waiting in the queue for 2 seconds
+	requestedAt := time.Now().Add(-2 * time.Second)
+	_, err = blobStore.StoreBlob(ctx, &blob, uint64(requestedAt.UnixNano()))
+	assert.Nil(t, err)
+
+	count, _ := queueWaitSamples()
+	assert.Equal(t, uint64(0), count)
+
+	out := make(chan batcher.EncodingResultOrStatus)
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	err = encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.Nil(t, err)
+	err = encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.Nil(t, err)
+
+	// the latency is observed once per blob, not once per quorum
+	count, sum := queueWaitSamples()
+	assert.Equal(t, uint64(1), count)
+	assert.GreaterOrEqual(t, sum, float64(2000))
+
+	// re-encoding the blob at a newer reference block does not count as queue wait
+	encodingStreamer.ReferenceBlockNumber = 11
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	err = encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.Nil(t, err)
+	err = encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.Nil(t, err)
+
+	count, _ = queueWaitSamples()
+	assert.Equal(t, uint64(1), count)
+}
+
+func TestPreviewAssignmentsMatchDispersal(t *testing.T) {
+	config := streamerConfig
+	config.TargetNumChunks = 16
+	encodingStreamer, c := createEncodingStreamer(t, 0, 1e12, config)
+	c.chainDataMock.On("GetCurrentBlockNumber").Return(uint(10), nil)
+
+	securityParams := []*core.SecurityParam{
+		{
+			QuorumID:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+		{
+			QuorumID:           1,
+			AdversaryThreshold: 50,
+			QuorumThreshold:    90,
+		},
+	}
+	blob := makeTestBlob(securityParams)
+	ctx := context.Background()
+	metadataKey, err := c.blobStore.StoreBlob(ctx, &blob, uint64(time.Now().UnixNano()))
+	assert.Nil(t, err)
+
+	coordinator := &core.StdAssignmentCoordinator{
+		ChainState:      c.chainDataMock,
+		TargetNumChunks: config.TargetNumChunks,
+	}
+	previews, err := coordinator.PreviewAssignments(ctx, uint(len(blob.Data)), securityParams)
+	assert.Nil(t, err)
+	assert.Len(t, previews, len(securityParams))
+
+	out := make(chan batcher.EncodingResultOrStatus)
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	for range securityParams {
+		err = encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+		assert.Nil(t, err)
+	}
+
+	// the preview matches the assignments the blob was actually encoded and dispersed with
+	for _, param := range securityParams {
+		encodedResult, err := encodingStreamer.EncodedBlobstore.GetEncodingResult(metadataKey, param.QuorumID)
+		assert.Nil(t, err)
+		assert.Equal(t, encodedResult.Assignments, previews[param.QuorumID])
+	}
+
+	// a coordinator without chain state can't preview assignments
+	_, err = (&core.StdAssignmentCoordinator{}).PreviewAssignments(ctx, uint(len(blob.Data)), securityParams)
+	assert.ErrorIs(t, err, core.ErrNoChainState)
+}
+
+func TestSweepExpiredEncodingResults(t *testing.T) {
+	logger := &cmock.Logger{}
+	blobStore := inmem.NewBlobStore()
+	cst, err := coremock.MakeChainDataMock(numOperators)
+	assert.Nil(t, err)
+	enc, err := makeTestEncoder()
+	assert.Nil(t, err)
+	encoderClient := disperser.NewLocalEncoderClient(enc)
+	asgn := &core.StdAssignmentCoordinator{}
+	sizeNotifier := batcher.NewEncodedSizeNotifier(make(chan struct{}, 1), 1e12)
+	metrics := batcher.NewMetrics("9100", logger)
+	config := streamerConfig
+	config.MaxEncodedResultAge = time.Minute
+	encodingStreamer, err := batcher.NewEncodingStreamer(config, blobStore, cst, encoderClient, asgn, sizeNotifier, workerpool.New(5), metrics.EncodingStreamerMetrics, logger)
+	assert.Nil(t, err)
+	encodingStreamer.ReferenceBlockNumber = 10
+	now := time.Unix(1000, 0)
+	encodingStreamer.Now = func() time.Time {
+		return now
+	}
+
+	sweptCount := func() float64 {
+		m := &dto.Metric{}
+		assert.Nil(t, metrics.EncodingStreamerMetrics.SweptEncodedResults.Write(m))
+		return m.GetCounter().GetValue()
+	}
+
+	ctx := context.Background()
+	securityParams := []*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}}
+	staleBlob := makeTestBlob(securityParams)
+	staleKey, err := blobStore.StoreBlob(ctx, &staleBlob, uint64(time.Now().UnixNano()))
+	assert.Nil(t, err)
+	pendingBlob := makeTestBlob(securityParams)
+	pendingKey, err := blobStore.StoreBlob(ctx, &pendingBlob, uint64(time.Now().UnixNano()))
+	assert.Nil(t, err)
+	pendingMetadata, err := blobStore.GetBlobMetadata(ctx, pendingKey)
+	assert.Nil(t, err)
+
+	out := make(chan batcher.EncodingResultOrStatus)
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	for i := 0; i < 2; i++ {
+		err = encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+		assert.Nil(t, err)
+	}
+	err = encodingStreamer.MarkBlobPendingConfirmation(pendingMetadata)
+	assert.Nil(t, err)
+	count, size := encodingStreamer.EncodedBlobstore.GetEncodedResultSize()
+	assert.Equal(t, 2, count)
+
+	// nothing is swept before the results expire
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, 0, encodingStreamer.SweepExpiredEncodingResults())
+	assert.Equal(t, float64(0), sweptCount())
+
+	// the expired result is swept while the one pending confirmation is retained
+	now = now.Add(time.Minute)
+	assert.Equal(t, 1, encodingStreamer.SweepExpiredEncodingResults())
+	assert.Equal(t, float64(1), sweptCount())
+	_, err = encodingStreamer.EncodedBlobstore.GetEncodingResult(staleKey, core.QuorumID(0))
+	assert.ErrorContains(t, err, "no such key")
+	encodedResult, err := encodingStreamer.EncodedBlobstore.GetEncodingResult(pendingKey, core.QuorumID(0))
+	assert.Nil(t, err)
+	assert.Equal(t, batcher.PendingConfirmation, encodedResult.Status)
+	remainingCount, remainingSize := encodingStreamer.EncodedBlobstore.GetEncodedResultSize()
+	assert.Equal(t, 1, remainingCount)
+	assert.Less(t, remainingSize, size)
+}
+
+func TestPendingConfirmationMetrics(t *testing.T) {
+	logger := &cmock.Logger{}
+	blobStore := inmem.NewBlobStore()
+	cst, err := coremock.MakeChainDataMock(numOperators)
+	assert.Nil(t, err)
+	enc, err := makeTestEncoder()
+	assert.Nil(t, err)
+	encoderClient := disperser.NewLocalEncoderClient(enc)
+	asgn := &core.StdAssignmentCoordinator{}
+	sizeNotifier := batcher.NewEncodedSizeNotifier(make(chan struct{}, 1), 1e12)
+	metrics := batcher.NewMetrics("9100", logger)
+	config := streamerConfig
+	config.MaxPendingConfirmationDuration = time.Minute
+	encodingStreamer, err := batcher.NewEncodingStreamer(config, blobStore, cst, encoderClient, asgn, sizeNotifier, workerpool.New(5), metrics.EncodingStreamerMetrics, logger)
+	assert.Nil(t, err)
+	encodingStreamer.ReferenceBlockNumber = 10
+	now := time.Unix(1000, 0)
+	encodingStreamer.Now = func() time.Time {
+		return now
+	}
+
+	oldestAge := func() float64 {
+		m := &dto.Metric{}
+		assert.Nil(t, metrics.EncodingStreamerMetrics.OldestPendingConfirmationAge.Write(m))
+		return m.GetGauge().GetValue()
+	}
+	exceededCount := func() float64 {
+		m := &dto.Metric{}
+		assert.Nil(t, metrics.EncodingStreamerMetrics.ExceededPendingConfirmations.Write(m))
+		return m.GetCounter().GetValue()
+	}
+
+	ctx := context.Background()
+	securityParams := []*core.SecurityParam{
+		{
+			QuorumID:           0,
+			AdversaryThreshold: 80,
+			QuorumThreshold:    100,
+		},
+		{
+			QuorumID:           1,
+			AdversaryThreshold: 70,
+			QuorumThreshold:    95,
+		},
+	}
+	blob := makeTestBlob(securityParams)
+	key, err := blobStore.StoreBlob(ctx, &blob, uint64(time.Now().UnixNano()))
+	assert.Nil(t, err)
+	metadata, err := blobStore.GetBlobMetadata(ctx, key)
+	assert.Nil(t, err)
+
+	out := make(chan batcher.EncodingResultOrStatus)
+	err = encodingStreamer.RequestEncoding(ctx, out)
+	assert.Nil(t, err)
+	for range securityParams {
+		err = encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+		assert.Nil(t, err)
+	}
+
+	// nothing is pending confirmation yet
+	assert.Equal(t, 0, encodingStreamer.CheckPendingConfirmations())
+	assert.Equal(t, float64(0), oldestAge())
+
+	err = encodingStreamer.MarkBlobPendingConfirmation(metadata)
+	assert.Nil(t, err)
+
+	// the age of the oldest blob pending confirmation grows with the clock
+	now = now.Add(20 * time.Second)
+	assert.Equal(t, 0, encodingStreamer.CheckPendingConfirmations())
+	assert.Equal(t, float64(20), oldestAge())
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, 0, encodingStreamer.CheckPendingConfirmations())
+	assert.Equal(t, float64(50), oldestAge())
+	assert.Equal(t, float64(0), exceededCount())
+
+	// the blob is counted once when it exceeds the maximum duration, regardless of its number of quorums
+	now = now.Add(20 * time.Second)
+	assert.Equal(t, 1, encodingStreamer.CheckPendingConfirmations())
+	assert.Equal(t, float64(70), oldestAge())
+	assert.Equal(t, float64(1), exceededCount())
+	now = now.Add(time.Minute)
+	assert.Equal(t, 0, encodingStreamer.CheckPendingConfirmations())
+	assert.Equal(t, float64(130), oldestAge())
+	assert.Equal(t, float64(1), exceededCount())
+}
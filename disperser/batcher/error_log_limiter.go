@@ -0,0 +1,74 @@
+package batcher
+
+import (
+	"time"
+
+	"github.com/Layr-Labs/eigenda/common"
+)
+
+// ErrorLogLimiter collapses repeated identical errors into periodic summaries so that persistent failures don't flood
+// the logs. The first occurrence of an error is logged right away. Identical errors that follow are only counted, and
+// logged as a single summary once the interval elapses, a different error occurs or the failures stop.
+type ErrorLogLimiter struct {
+	msg      string
+	interval time.Duration
+	logger   common.Logger
+	now      func() time.Time
+
+	lastErr     string
+	windowStart time.Time
+	numRepeated int
+}
+
+// NewErrorLogLimiter creates an ErrorLogLimiter that logs errors with the given message. An interval of 0 logs every
+// error. now returns the current time; it defaults to time.Now if nil.
+func NewErrorLogLimiter(logger common.Logger, msg string, interval time.Duration, now func() time.Time) *ErrorLogLimiter {
+	if now == nil {
+		now = time.Now
+	}
+	return &ErrorLogLimiter{
+		msg:      msg,
+		interval: interval,
+		logger:   logger,
+		now:      now,
+	}
+}
+
+// Log logs the error, unless it is identical to the previous error and the summary of the current interval is not due yet
+func (l *ErrorLogLimiter) Log(err error) {
+	if l.interval == 0 {
+		l.logger.Error(l.msg, "err", err)
+		return
+	}
+
+	now := l.now()
+	if err.Error() == l.lastErr {
+		l.numRepeated++
+		if now.Sub(l.windowStart) >= l.interval {
+			l.flush(now)
+		}
+		return
+	}
+
+	l.flush(now)
+	l.logger.Error(l.msg, "err", err)
+	l.lastErr = err.Error()
+}
+
+// Reset logs the summary of the suppressed errors, if any, and forgets the last error. It should be called once the
+// operation succeeds again.
+func (l *ErrorLogLimiter) Reset() {
+	if l.lastErr == "" {
+		return
+	}
+	l.flush(l.now())
+	l.lastErr = ""
+}
+
+func (l *ErrorLogLimiter) flush(now time.Time) {
+	if l.numRepeated > 0 {
+		l.logger.Error(l.msg, "err", l.lastErr, "numRepeated", l.numRepeated, "window", now.Sub(l.windowStart))
+		l.numRepeated = 0
+	}
+	l.windowStart = now
+}
@@ -0,0 +1,69 @@
+package batcher_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigenda/common/logging"
+	"github.com/Layr-Labs/eigenda/disperser/batcher"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRecordingLogger() (*logging.Logger, *[]*log.Record) {
+	records := make([]*log.Record, 0)
+	logger := &logging.Logger{Logger: log.New()}
+	logger.SetHandler(log.FuncHandler(func(r *log.Record) error {
+		records = append(records, r)
+		return nil
+	}))
+	return logger, &records
+}
+
+func TestErrorLogLimiterCollapsesRepeatedErrors(t *testing.T) {
+	logger, records := newRecordingLogger()
+	now := time.Unix(0, 0)
+	limiter := batcher.NewErrorLogLimiter(logger, "failed to process a batch", time.Minute, func() time.Time { return now })
+
+	// an identical error every 5 seconds for 10 minutes
+	err := errors.New("encoder unavailable")
+	numErrors := 0
+	for i := 0; i < 120; i++ {
+		limiter.Log(err)
+		numErrors++
+		now = now.Add(5 * time.Second)
+	}
+	// the first error, then one summary per minute
+	assert.Equal(t, 10, len(*records))
+	assert.Less(t, len(*records)*10, numErrors)
+	assert.Equal(t, 12, (*records)[1].Ctx[3])
+
+	// a different error is logged right away, after the summary of the previous one
+	limiter.Log(errors.New("operator state unavailable"))
+	assert.Equal(t, 12, len(*records))
+	assert.Equal(t, "encoder unavailable", (*records)[10].Ctx[1])
+	assert.Equal(t, "operator state unavailable", (*records)[11].Ctx[1].(error).Error())
+
+	// the summary of the suppressed errors is logged once the failures stop
+	limiter.Log(errors.New("operator state unavailable"))
+	limiter.Log(errors.New("operator state unavailable"))
+	assert.Equal(t, 12, len(*records))
+	limiter.Reset()
+	assert.Equal(t, 13, len(*records))
+	assert.Equal(t, 2, (*records)[12].Ctx[3])
+
+	limiter.Reset()
+	assert.Equal(t, 13, len(*records))
+}
+
+func TestErrorLogLimiterDisabled(t *testing.T) {
+	logger, records := newRecordingLogger()
+	limiter := batcher.NewErrorLogLimiter(logger, "failed to process a batch", 0, nil)
+
+	err := errors.New("encoder unavailable")
+	for i := 0; i < 10; i++ {
+		limiter.Log(err)
+	}
+	assert.Equal(t, 10, len(*records))
+}
@@ -0,0 +1,76 @@
+package batcher
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// failReasonBucketDuration is the resolution of the FailReasonWindow. Windows are rounded up to whole buckets.
+	failReasonBucketDuration = time.Minute
+	// MaxFailReasonWindow is the longest window the failures can be counted over
+	MaxFailReasonWindow = time.Hour
+)
+
+type failReasonBucket struct {
+	start  time.Time
+	counts map[FailReason]int
+}
+
+// FailReasonWindow counts the failures by reason over a rolling window of up to MaxFailReasonWindow, so that the
+// distribution of the recent failures can be queried for incident dashboards. The failures are counted in one-minute
+// buckets.
+type FailReasonWindow struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	buckets []failReasonBucket
+}
+
+// NewFailReasonWindow creates a FailReasonWindow. now returns the current time; it defaults to time.Now if nil.
+func NewFailReasonWindow(now func() time.Time) *FailReasonWindow {
+	if now == nil {
+		now = time.Now
+	}
+	return &FailReasonWindow{
+		now:     now,
+		buckets: make([]failReasonBucket, MaxFailReasonWindow/failReasonBucketDuration),
+	}
+}
+
+// Add counts the given number of failures for the reason at the current time
+func (w *FailReasonWindow) Add(reason FailReason, count int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	start := w.now().Truncate(failReasonBucketDuration)
+	bucket := &w.buckets[(start.UnixNano()/int64(failReasonBucketDuration))%int64(len(w.buckets))]
+	if !bucket.start.Equal(start) || bucket.counts == nil {
+		bucket.start = start
+		bucket.counts = make(map[FailReason]int)
+	}
+	bucket.counts[reason] += count
+}
+
+// Counts returns the number of failures of each reason over the given window, rounded up to whole minutes and capped at
+// MaxFailReasonWindow. The reasons without failures in the window are omitted.
+func (w *FailReasonWindow) Counts(window time.Duration) map[FailReason]int {
+	if window > MaxFailReasonWindow {
+		window = MaxFailReasonWindow
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// the buckets starting after the cutoff are in the window, including the current, partial bucket
+	cutoff := w.now().Truncate(failReasonBucketDuration).Add(-window)
+	counts := make(map[FailReason]int)
+	for _, bucket := range w.buckets {
+		if !bucket.start.After(cutoff) {
+			continue
+		}
+		for reason, count := range bucket.counts {
+			counts[reason] += count
+		}
+	}
+	return counts
+}
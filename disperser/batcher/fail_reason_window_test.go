@@ -0,0 +1,61 @@
+package batcher_test
+
+import (
+	"testing"
+	"time"
+
+	cmock "github.com/Layr-Labs/eigenda/common/mock"
+	"github.com/Layr-Labs/eigenda/disperser/batcher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailReasonWindow(t *testing.T) {
+	now := time.Unix(0, 0).Add(30 * time.Second)
+	window := batcher.NewFailReasonWindow(func() time.Time { return now })
+
+	// failures spread over 20 minutes
+	window.Add(batcher.FailConfirmBatch, 3)
+	window.Add(batcher.FailDispersal, 1)
+	now = now.Add(10 * time.Minute)
+	window.Add(batcher.FailConfirmBatch, 2)
+	window.Add(batcher.FailAggregateSignatures, 4)
+	now = now.Add(9 * time.Minute)
+	window.Add(batcher.FailDispersal, 5)
+	window.Add(batcher.FailDispersal, 1)
+
+	assert.Equal(t, map[batcher.FailReason]int{
+		batcher.FailDispersal: 6,
+	}, window.Counts(5*time.Minute))
+	assert.Equal(t, map[batcher.FailReason]int{
+		batcher.FailConfirmBatch:        2,
+		batcher.FailAggregateSignatures: 4,
+		batcher.FailDispersal:           6,
+	}, window.Counts(15*time.Minute))
+	assert.Equal(t, map[batcher.FailReason]int{
+		batcher.FailConfirmBatch:        5,
+		batcher.FailAggregateSignatures: 4,
+		batcher.FailDispersal:           7,
+	}, window.Counts(batcher.MaxFailReasonWindow))
+
+	// the failures leave the window as time passes, and their buckets are reused
+	now = now.Add(51 * time.Minute)
+	window.Add(batcher.FailNoSignatures, 1)
+	assert.Equal(t, map[batcher.FailReason]int{
+		batcher.FailDispersal:    6,
+		batcher.FailNoSignatures: 1,
+	}, window.Counts(2*time.Hour))
+	now = now.Add(time.Hour)
+	assert.Empty(t, window.Counts(batcher.MaxFailReasonWindow))
+}
+
+func TestBatchErrorsInWindow(t *testing.T) {
+	metrics := batcher.NewMetrics("9100", &cmock.Logger{})
+	metrics.UpdateBatchError(batcher.FailConfirmBatch, 2)
+	metrics.UpdateBatchError(batcher.FailDispersal, 3)
+	metrics.UpdateBatchError(batcher.FailConfirmBatch, 1)
+
+	assert.Equal(t, map[batcher.FailReason]int{
+		batcher.FailConfirmBatch: 3,
+		batcher.FailDispersal:    3,
+	}, metrics.BatchErrorsInWindow(metrics.BatchErrorWindow))
+}
@@ -16,13 +16,36 @@ import (
 	gcommon "github.com/ethereum/go-ethereum/common"
 )
 
-const maxRetries = 3
-const baseDelay = 1 * time.Second
+const (
+	defaultFinalizerMaxRetries = 3
+	defaultFinalizerBaseDelay  = 1 * time.Second
+)
+
+// FinalizerRetryConfig controls how the finalizer retries failed chain reads.
+// The delay before retry i is BaseDelay * 2^i, capped at MaxDelay.
+type FinalizerRetryConfig struct {
+	// MaxRetries is the number of attempts made before giving up. Defaults to 3 if not set.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry. Defaults to 1 second if not set.
+	BaseDelay time.Duration
+	// MaxDelay is the upper bound on the delay between retries. No cap is applied if not set.
+	MaxDelay time.Duration
+	// Sleep waits for the given duration between retries. Defaults to time.Sleep if not set.
+	Sleep func(time.Duration)
+}
+
+// ErrBlobNotFinalized is returned for a blob whose confirmation block is not yet finalized
+var ErrBlobNotFinalized = errors.New("blob confirmation block is not yet finalized")
+
+// ErrNoFinalizedBlock is returned when the chain has no finalized block yet, e.g. right after it started
+var ErrNoFinalizedBlock = errors.New("no finalized block available yet")
 
 // Finalizer runs periodically to finalize blobs that have been confirmed
 type Finalizer interface {
 	Start(ctx context.Context)
 	FinalizeBlobs(ctx context.Context) error
+	// FinalizeNow finalizes the given blobs immediately and returns the result for each blob
+	FinalizeNow(ctx context.Context, keys []disperser.BlobKey) (map[disperser.BlobKey]error, error)
 }
 
 type finalizer struct {
@@ -34,6 +57,9 @@ type finalizer struct {
 	maxNumRetriesPerBlob uint
 	numBlobsPerFetch     int32
 	numWorkers           int
+	writeBatchSize       int
+	retryConfig          FinalizerRetryConfig
+	observer             EventObserver
 	logger               common.Logger
 	metrics              *FinalizerMetrics
 }
@@ -47,9 +73,24 @@ func NewFinalizer(
 	maxNumRetriesPerBlob uint,
 	numBlobsPerFetch int32,
 	numWorkers int,
+	writeBatchSize int,
+	retryConfig FinalizerRetryConfig,
+	observer EventObserver,
 	logger common.Logger,
 	metrics *FinalizerMetrics,
 ) Finalizer {
+	if retryConfig.MaxRetries <= 0 {
+		retryConfig.MaxRetries = defaultFinalizerMaxRetries
+	}
+	if retryConfig.BaseDelay <= 0 {
+		retryConfig.BaseDelay = defaultFinalizerBaseDelay
+	}
+	if retryConfig.Sleep == nil {
+		retryConfig.Sleep = time.Sleep
+	}
+	if observer == nil {
+		observer = noopEventObserver{}
+	}
 	return &finalizer{
 		timeout:              timeout,
 		loopInterval:         loopInterval,
@@ -59,6 +100,9 @@ func NewFinalizer(
 		maxNumRetriesPerBlob: maxNumRetriesPerBlob,
 		numBlobsPerFetch:     numBlobsPerFetch,
 		numWorkers:           numWorkers,
+		writeBatchSize:       writeBatchSize,
+		retryConfig:          retryConfig,
+		observer:             observer,
 		logger:               logger,
 		metrics:              metrics,
 	}
@@ -90,6 +134,11 @@ func (f *finalizer) FinalizeBlobs(ctx context.Context) error {
 	pool := workerpool.New(f.numWorkers)
 	finalizedHeader, err := f.getLatestFinalizedBlock(ctx)
 	if err != nil {
+		if errors.Is(err, ErrNoFinalizedBlock) {
+			// Nothing can be finalized before the chain finalizes its first block
+			f.logger.Warn("FinalizeBlobs: skipping run, no finalized block is available yet")
+			return nil
+		}
 		return fmt.Errorf("FinalizeBlobs: error getting latest finalized block: %w", err)
 	}
 	lastFinalBlock := finalizedHeader.Number.Uint64()
@@ -120,57 +169,136 @@ func (f *finalizer) FinalizeBlobs(ctx context.Context) error {
 	return nil
 }
 
-func (f *finalizer) updateBlobs(ctx context.Context, metadatas []*disperser.BlobMetadata, lastFinalBlock uint64) {
+// FinalizeNow runs the finalization checks for the given blobs immediately instead of waiting for the finalizer loop.
+// It returns the result for each blob: nil if the blob was finalized, ErrBlobNotFinalized if its confirmation block
+// is not yet finalized, or the error encountered while processing it.
+func (f *finalizer) FinalizeNow(ctx context.Context, keys []disperser.BlobKey) (map[disperser.BlobKey]error, error) {
+	finalizedHeader, err := f.getLatestFinalizedBlock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("FinalizeNow: error getting latest finalized block: %w", err)
+	}
+	lastFinalBlock := finalizedHeader.Number.Uint64()
+
+	results := make(map[disperser.BlobKey]error, len(keys))
+	metadatas := make([]*disperser.BlobMetadata, 0, len(keys))
+	for _, key := range keys {
+		metadata, err := f.blobStore.GetBlobMetadata(ctx, key)
+		if err != nil {
+			results[key] = fmt.Errorf("error getting blob metadata: %w", err)
+			continue
+		}
+		metadatas = append(metadatas, metadata)
+	}
+	f.logger.Info("FinalizeNow: finalizing blobs", "numBlobs", len(metadatas), "finalizedBlockNumber", lastFinalBlock)
+	for key, err := range f.updateBlobs(ctx, metadatas, lastFinalBlock) {
+		results[key] = err
+	}
+	return results, nil
+}
+
+// updateBlobs finalizes the given confirmed blobs whose confirmation block is at or before lastFinalBlock.
+// It returns the result for each blob, see FinalizeNow.
+func (f *finalizer) updateBlobs(ctx context.Context, metadatas []*disperser.BlobMetadata, lastFinalBlock uint64) map[disperser.BlobKey]error {
+	results := make(map[disperser.BlobKey]error, len(metadatas))
+	finalized := make([]*disperser.BlobMetadata, 0, len(metadatas))
 	for _, m := range metadatas {
 		stageTimer := time.Now()
 		blobKey := m.GetBlobKey()
 		if m.BlobStatus != disperser.Confirmed {
 			f.logger.Error("FinalizeBlobs: the blob retrieved by status Confirmed is actually", m.BlobStatus.String(), "blobKey", blobKey.String())
-			continue
-		}
-		confirmationMetadata, err := f.blobStore.GetBlobMetadata(ctx, blobKey)
-		if err != nil {
-			f.logger.Error("FinalizeBlobs: error getting confirmed metadata", "blobKey", blobKey.String(), "err", err)
+			results[blobKey] = fmt.Errorf("blob is in status %s, not confirmed", m.BlobStatus.String())
 			continue
 		}
 
 		// Leave as confirmed if the confirmation block is after the latest finalized block (not yet finalized)
-		if uint64(confirmationMetadata.ConfirmationInfo.ConfirmationBlockNumber) > lastFinalBlock {
+		if uint64(m.ConfirmationInfo.ConfirmationBlockNumber) > lastFinalBlock {
+			results[blobKey] = ErrBlobNotFinalized
 			continue
 		}
 
 		// confirmation block number may have changed due to reorg
-		confirmationBlockNumber, err := f.getTransactionBlockNumber(ctx, confirmationMetadata.ConfirmationInfo.ConfirmationTxnHash)
+		confirmationBlockNumber, err := f.getTransactionBlockNumber(ctx, m.ConfirmationInfo.ConfirmationTxnHash)
 		if errors.Is(err, ethereum.NotFound) {
 			// The confirmed block is finalized, but the transaction is not found. It means the transaction should be considered forked/invalid and the blob should be considered as failed.
-			err := f.blobStore.HandleBlobFailure(ctx, m, f.maxNumRetriesPerBlob)
+			_, err := f.blobStore.HandleBlobFailure(ctx, m, f.maxNumRetriesPerBlob)
 			if err != nil {
 				f.logger.Error("FinalizeBlobs: error marking blob as failed", "blobKey", blobKey.String(), "err", err)
 			}
 			f.metrics.IncrementNumBlobs("failed")
+			results[blobKey] = fmt.Errorf("confirmation transaction not found: %w", ethereum.NotFound)
 			continue
 		}
 		if err != nil {
 			f.logger.Error("FinalizeBlobs: error getting transaction block number", "err", err)
 			f.metrics.IncrementNumBlobs("failed")
+			results[blobKey] = err
 			continue
 		}
 
 		// Leave as confirmed if the reorged confirmation block is after the latest finalized block (not yet finalized)
 		if uint64(confirmationBlockNumber) > lastFinalBlock {
+			results[blobKey] = ErrBlobNotFinalized
 			continue
 		}
 
-		confirmationMetadata.ConfirmationInfo.ConfirmationBlockNumber = uint32(confirmationBlockNumber)
-		err = f.blobStore.MarkBlobFinalized(ctx, blobKey)
-		if err != nil {
+		finalizedMetadata := *m
+		confirmationInfo := *m.ConfirmationInfo
+		confirmationInfo.ConfirmationBlockNumber = uint32(confirmationBlockNumber)
+		finalizedMetadata.ConfirmationInfo = &confirmationInfo
+		finalized = append(finalized, &finalizedMetadata)
+		f.metrics.ObserveLatency("round", float64(time.Since(stageTimer).Milliseconds()))
+	}
+
+	for key, err := range f.markBlobsFinalized(ctx, finalized) {
+		results[key] = err
+	}
+	for _, metadata := range finalized {
+		blobKey := metadata.GetBlobKey()
+		if err := results[blobKey]; err != nil {
 			f.logger.Error("FinalizeBlobs: error marking blob as finalized", "blobKey", blobKey.String(), "err", err)
 			f.metrics.IncrementNumBlobs("failed")
 			continue
 		}
+		metadata.BlobStatus = disperser.Finalized
 		f.metrics.IncrementNumBlobs("finalized")
-		f.metrics.ObserveLatency("round", float64(time.Since(stageTimer).Milliseconds()))
+		notifyObserver(f.logger, f.observer, "BlobFinalized", func(o EventObserver) error {
+			return o.OnBlobFinalized(ctx, metadata)
+		})
 	}
+	return results
+}
+
+// markBlobsFinalized marks the given blobs as finalized, in batched writes of up to writeBatchSize blobs if the blob
+// store supports them. It returns the result of the write of each blob.
+func (f *finalizer) markBlobsFinalized(ctx context.Context, metadatas []*disperser.BlobMetadata) map[disperser.BlobKey]error {
+	results := make(map[disperser.BlobKey]error, len(metadatas))
+	batchFinalizer, ok := f.blobStore.(disperser.BatchBlobFinalizer)
+	if !ok {
+		for _, metadata := range metadatas {
+			results[metadata.GetBlobKey()] = f.blobStore.MarkBlobFinalized(ctx, metadata.GetBlobKey())
+		}
+		return results
+	}
+
+	batchSize := f.writeBatchSize
+	if batchSize <= 0 {
+		batchSize = len(metadatas)
+	}
+	for start := 0; start < len(metadatas); start += batchSize {
+		end := start + batchSize
+		if end > len(metadatas) {
+			end = len(metadatas)
+		}
+		batch := metadatas[start:end]
+		notConfirmedKeys, err := batchFinalizer.BatchMarkBlobsFinalized(ctx, batch)
+		for _, metadata := range batch {
+			results[metadata.GetBlobKey()] = err
+		}
+		for _, key := range notConfirmedKeys {
+			results[key] = fmt.Errorf("blob is no longer confirmed: %w", disperser.ErrBlobNotConfirmed)
+		}
+	}
+	return results
 }
 
 func (f *finalizer) getTransactionBlockNumber(ctx context.Context, hash gcommon.Hash) (uint64, error) {
@@ -178,7 +306,7 @@ func (f *finalizer) getTransactionBlockNumber(ctx context.Context, hash gcommon.
 	var cancel context.CancelFunc
 	var txReceipt *types.Receipt
 	var err error
-	for i := 0; i < maxRetries; i++ {
+	for i := 0; i < f.retryConfig.MaxRetries; i++ {
 		ctxWithTimeout, cancel = context.WithTimeout(ctx, f.timeout)
 		defer cancel()
 		txReceipt, err = f.ethClient.TransactionReceipt(ctxWithTimeout, hash)
@@ -190,9 +318,9 @@ func (f *finalizer) getTransactionBlockNumber(ctx context.Context, hash gcommon.
 			return 0, err
 		}
 
-		retrySec := math.Pow(2, float64(i))
-		f.logger.Error("Finalizer: error getting transaction", "err", err, "retrySec", retrySec, "hash", hash.Hex())
-		time.Sleep(time.Duration(retrySec) * baseDelay)
+		delay := f.retryDelay(i)
+		f.logger.Error("Finalizer: error getting transaction", "err", err, "retryIn", delay, "hash", hash.Hex())
+		f.retryConfig.Sleep(delay)
 	}
 
 	if err != nil {
@@ -207,22 +335,36 @@ func (f *finalizer) getLatestFinalizedBlock(ctx context.Context) (*types.Header,
 	var cancel context.CancelFunc
 	var header = types.Header{}
 	var err error
-	for i := 0; i < maxRetries; i++ {
+	for i := 0; i < f.retryConfig.MaxRetries; i++ {
 		ctxWithTimeout, cancel = context.WithTimeout(ctx, f.timeout)
 		defer cancel()
-		err := f.rpcClient.CallContext(ctxWithTimeout, &header, "eth_getBlockByNumber", "finalized", false)
+		err = f.rpcClient.CallContext(ctxWithTimeout, &header, "eth_getBlockByNumber", "finalized", false)
 		if err == nil {
 			break
 		}
 
-		retrySec := math.Pow(2, float64(i))
-		f.logger.Error("Finalizer: error getting latest finalized block", "err", err, "retrySec", retrySec)
-		time.Sleep(time.Duration(retrySec) * baseDelay)
+		delay := f.retryDelay(i)
+		f.logger.Error("Finalizer: error getting latest finalized block", "err", err, "retryIn", delay)
+		f.retryConfig.Sleep(delay)
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("Finalizer: error getting latest finalized block after retries: %w", err)
 	}
+	// A chain that hasn't finalized a block yet returns an empty header
+	if header.Number == nil || header.Number.Sign() == 0 {
+		return nil, ErrNoFinalizedBlock
+	}
 
 	return &header, nil
 }
+
+// retryDelay returns the exponential backoff delay for the given (zero-indexed) retry attempt, capped at the configured max delay
+func (f *finalizer) retryDelay(attempt int) time.Duration {
+	delay := time.Duration(math.Pow(2, float64(attempt)) * float64(f.retryConfig.BaseDelay))
+	// guard against overflow for large attempt counts as well as the configured cap
+	if f.retryConfig.MaxDelay > 0 && (delay > f.retryConfig.MaxDelay || delay <= 0) {
+		return f.retryConfig.MaxDelay
+	}
+	return delay
+}
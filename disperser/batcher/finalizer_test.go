@@ -2,6 +2,7 @@ package batcher_test
 
 import (
 	"context"
+	"errors"
 	"math/big"
 	"testing"
 	"time"
@@ -40,7 +41,7 @@ func TestFinalizedBlob(t *testing.T) {
 	}, nil)
 
 	metrics := batcher.NewMetrics("9100", logger)
-	finalizer := batcher.NewFinalizer(timeout, loopInterval, queue, ethClient, rpcClient, 1, 1, 1, logger, metrics.FinalizerMetrics)
+	finalizer := batcher.NewFinalizer(timeout, loopInterval, queue, ethClient, rpcClient, 1, 1, 1, 0, batcher.FinalizerRetryConfig{}, nil, logger, metrics.FinalizerMetrics)
 
 	requestedAt := uint64(time.Now().UnixNano())
 	blob := makeTestBlob([]*core.SecurityParam{{
@@ -139,7 +140,7 @@ func TestUnfinalizedBlob(t *testing.T) {
 	}, nil)
 
 	metrics := batcher.NewMetrics("9100", logger)
-	finalizer := batcher.NewFinalizer(timeout, loopInterval, queue, ethClient, rpcClient, 1, 1, 1, logger, metrics.FinalizerMetrics)
+	finalizer := batcher.NewFinalizer(timeout, loopInterval, queue, ethClient, rpcClient, 1, 1, 1, 0, batcher.FinalizerRetryConfig{}, nil, logger, metrics.FinalizerMetrics)
 
 	requestedAt := uint64(time.Now().UnixNano())
 	blob := makeTestBlob([]*core.SecurityParam{{
@@ -194,6 +195,53 @@ func TestUnfinalizedBlob(t *testing.T) {
 	assert.Len(t, metadatas, 0)
 }
 
+func TestNoFinalizedBlock(t *testing.T) {
+	ctx := context.Background()
+	queue := inmem.NewBlobStore()
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	ethClient := &mock.MockEthClient{}
+	rpcClient := &mock.MockRPCEthClient{}
+
+	// the chain has not finalized any block yet: the first run gets an empty header, the second one the genesis block
+	rpcClient.On("CallContext", m.Anything, m.Anything, "eth_getBlockByNumber", "finalized", false).Return(nil).Once()
+	rpcClient.On("CallContext", m.Anything, m.Anything, "eth_getBlockByNumber", "finalized", false).
+		Run(func(args m.Arguments) {
+			args[1].(*types.Header).Number = big.NewInt(0)
+		}).Return(nil).Once()
+
+	metrics := batcher.NewMetrics("9100", logger)
+	finalizer := batcher.NewFinalizer(timeout, loopInterval, queue, ethClient, rpcClient, 1, 1, 1, 0, batcher.FinalizerRetryConfig{}, nil, logger, metrics.FinalizerMetrics)
+
+	requestedAt := uint64(time.Now().UnixNano())
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+	}})
+	metadataKey, err := queue.StoreBlob(ctx, &blob, requestedAt)
+	assert.NoError(t, err)
+	metadata, err := queue.GetBlobMetadata(ctx, metadataKey)
+	assert.NoError(t, err)
+	confirmed, err := queue.MarkBlobConfirmed(ctx, metadata, &disperser.ConfirmationInfo{
+		BatchHeaderHash:         [32]byte{1, 2, 3},
+		ConfirmationTxnHash:     common.HexToHash("0x123"),
+		ConfirmationBlockNumber: uint32(0),
+	})
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		err = finalizer.FinalizeBlobs(ctx)
+		assert.NoError(t, err)
+	}
+
+	// the runs are skipped without touching the blob
+	ethClient.AssertNotCalled(t, "TransactionReceipt", m.Anything, m.Anything)
+	metadata, err = queue.GetBlobMetadata(ctx, metadataKey)
+	assert.NoError(t, err)
+	assert.Equal(t, confirmed, metadata)
+	rpcClient.AssertExpectations(t)
+}
+
 func TestNoReceipt(t *testing.T) {
 	ctx := context.Background()
 	queue := inmem.NewBlobStore()
@@ -210,7 +258,7 @@ func TestNoReceipt(t *testing.T) {
 	ethClient.On("TransactionReceipt", m.Anything, m.Anything).Return(nil, ethereum.NotFound)
 
 	metrics := batcher.NewMetrics("9100", logger)
-	finalizer := batcher.NewFinalizer(timeout, loopInterval, queue, ethClient, rpcClient, 1, 1, 1, logger, metrics.FinalizerMetrics)
+	finalizer := batcher.NewFinalizer(timeout, loopInterval, queue, ethClient, rpcClient, 1, 1, 1, 0, batcher.FinalizerRetryConfig{}, nil, logger, metrics.FinalizerMetrics)
 
 	requestedAt := uint64(time.Now().UnixNano())
 	blob := makeTestBlob([]*core.SecurityParam{{
@@ -286,3 +334,287 @@ func TestNoReceipt(t *testing.T) {
 	// num retries should be the same
 	assert.Equal(t, metadatas[0].NumRetries, uint(1))
 }
+
+func TestFinalizerRetryDelayCapped(t *testing.T) {
+	ctx := context.Background()
+	queue := inmem.NewBlobStore()
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	ethClient := &mock.MockEthClient{}
+	rpcClient := &mock.MockRPCEthClient{}
+
+	latestFinalBlock := int64(1_000_010)
+	rpcClient.On("CallContext", m.Anything, m.Anything, "eth_getBlockByNumber", "finalized", false).
+		Run(func(args m.Arguments) {
+			args[1].(*types.Header).Number = big.NewInt(latestFinalBlock)
+		}).Return(nil).Once()
+	ethClient.On("TransactionReceipt", m.Anything, m.Anything).Return(nil, errors.New("rpc unavailable"))
+
+	// fake clock that records the requested delays instead of sleeping
+	delays := make([]time.Duration, 0)
+	retryConfig := batcher.FinalizerRetryConfig{
+		MaxRetries: 6,
+		BaseDelay:  time.Second,
+		MaxDelay:   5 * time.Second,
+		Sleep: func(d time.Duration) {
+			delays = append(delays, d)
+		},
+	}
+	metrics := batcher.NewMetrics("9100", logger)
+	finalizer := batcher.NewFinalizer(timeout, loopInterval, queue, ethClient, rpcClient, 1, 1, 1, 0, retryConfig, nil, logger, metrics.FinalizerMetrics)
+
+	requestedAt := uint64(time.Now().UnixNano())
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+	}})
+	metadataKey, err := queue.StoreBlob(ctx, &blob, requestedAt)
+	assert.NoError(t, err)
+	metadata, err := queue.GetBlobMetadata(ctx, metadataKey)
+	assert.NoError(t, err)
+	_, err = queue.MarkBlobConfirmed(ctx, metadata, &disperser.ConfirmationInfo{
+		BatchHeaderHash:         [32]byte{1, 2, 3},
+		ConfirmationTxnHash:     common.HexToHash("0x123"),
+		ConfirmationBlockNumber: uint32(150),
+	})
+	assert.NoError(t, err)
+
+	err = finalizer.FinalizeBlobs(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+	}, delays)
+	for _, d := range delays {
+		assert.LessOrEqual(t, d, retryConfig.MaxDelay)
+	}
+	ethClient.AssertNumberOfCalls(t, "TransactionReceipt", retryConfig.MaxRetries)
+
+	// blob should be left as confirmed so that it is retried on the next run
+	metadatas, err := queue.GetBlobMetadataByStatus(ctx, disperser.Confirmed)
+	assert.NoError(t, err)
+	assert.Len(t, metadatas, 1)
+}
+
+func TestFinalizeNow(t *testing.T) {
+	queue := inmem.NewBlobStore()
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	ethClient := &mock.MockEthClient{}
+	rpcClient := &mock.MockRPCEthClient{}
+
+	latestFinalBlock := int64(1_000_010)
+	rpcClient.On("CallContext", m.Anything, m.Anything, "eth_getBlockByNumber", "finalized", false).
+		Run(func(args m.Arguments) {
+			args[1].(*types.Header).Number = big.NewInt(latestFinalBlock)
+		}).Return(nil).Once()
+	ethClient.On("TransactionReceipt", m.Anything, m.Anything).Return(&types.Receipt{
+		BlockNumber: new(big.Int).SetUint64(1_000_000),
+	}, nil)
+
+	metrics := batcher.NewMetrics("9100", logger)
+	finalizer := batcher.NewFinalizer(timeout, loopInterval, queue, ethClient, rpcClient, 1, 1, 1, 0, batcher.FinalizerRetryConfig{}, nil, logger, metrics.FinalizerMetrics)
+
+	requestedAt := uint64(time.Now().UnixNano())
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+	}})
+	ctx := context.Background()
+	metadataKey1, err := queue.StoreBlob(ctx, &blob, requestedAt)
+	assert.NoError(t, err)
+	metadataKey2, err := queue.StoreBlob(ctx, &blob, requestedAt+1)
+	assert.NoError(t, err)
+	confirmationInfo := &disperser.ConfirmationInfo{
+		BatchHeaderHash:         [32]byte{1, 2, 3},
+		BlobIndex:               0,
+		SignatoryRecordHash:     [32]byte{0},
+		ReferenceBlockNumber:    132,
+		BatchRoot:               []byte("hello"),
+		BlobInclusionProof:      []byte{1, 2, 3, 4, 5},
+		BlobCommitment:          &core.BlobCommitments{},
+		BatchID:                 99,
+		ConfirmationTxnHash:     common.HexToHash("0x123"),
+		ConfirmationBlockNumber: uint32(150),
+		Fee:                     []byte{0},
+	}
+	for _, key := range []disperser.BlobKey{metadataKey1, metadataKey2} {
+		metadata, err := queue.GetBlobMetadata(ctx, key)
+		assert.NoError(t, err)
+		_, err = queue.MarkBlobConfirmed(ctx, metadata, confirmationInfo)
+		assert.NoError(t, err)
+	}
+
+	results, err := finalizer.FinalizeNow(ctx, []disperser.BlobKey{metadataKey1})
+	assert.NoError(t, err)
+	assert.Equal(t, map[disperser.BlobKey]error{metadataKey1: nil}, results)
+
+	metadata1, err := queue.GetBlobMetadata(ctx, metadataKey1)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Finalized, metadata1.BlobStatus)
+	metadata2, err := queue.GetBlobMetadata(ctx, metadataKey2)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Confirmed, metadata2.BlobStatus)
+}
+
+// countingBlobStore counts the metadata reads and the batched finalization writes of the finalizer
+type countingBlobStore struct {
+	disperser.BlobStore
+	numGetBlobMetadata      int
+	numBatchFinalizeWrites  int
+	numBlobFinalizeWrites   int
+	numSingleFinalizeWrites int
+}
+
+func (s *countingBlobStore) GetBlobMetadata(ctx context.Context, blobKey disperser.BlobKey) (*disperser.BlobMetadata, error) {
+	s.numGetBlobMetadata++
+	return s.BlobStore.GetBlobMetadata(ctx, blobKey)
+}
+
+func (s *countingBlobStore) MarkBlobFinalized(ctx context.Context, blobKey disperser.BlobKey) error {
+	s.numSingleFinalizeWrites++
+	return s.BlobStore.MarkBlobFinalized(ctx, blobKey)
+}
+
+func (s *countingBlobStore) BatchMarkBlobsFinalized(ctx context.Context, metadatas []*disperser.BlobMetadata) ([]disperser.BlobKey, error) {
+	s.numBatchFinalizeWrites++
+	s.numBlobFinalizeWrites += len(metadatas)
+	return s.BlobStore.(disperser.BatchBlobFinalizer).BatchMarkBlobsFinalized(ctx, metadatas)
+}
+
+func TestFinalizerBatchesStoreAccess(t *testing.T) {
+	queue := &countingBlobStore{BlobStore: inmem.NewBlobStore()}
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	ethClient := &mock.MockEthClient{}
+	rpcClient := &mock.MockRPCEthClient{}
+
+	rpcClient.On("CallContext", m.Anything, m.Anything, "eth_getBlockByNumber", "finalized", false).
+		Run(func(args m.Arguments) {
+			args[1].(*types.Header).Number = big.NewInt(1_000_010)
+		}).Return(nil).Once()
+	ethClient.On("TransactionReceipt", m.Anything, m.Anything).Return(&types.Receipt{
+		BlockNumber: new(big.Int).SetUint64(1_000_000),
+	}, nil)
+
+	metrics := batcher.NewMetrics("9100", logger)
+	finalizer := batcher.NewFinalizer(timeout, loopInterval, queue, ethClient, rpcClient, 1, 10, 1, 2, batcher.FinalizerRetryConfig{}, nil, logger, metrics.FinalizerMetrics)
+
+	const numBlobs = 5
+	ctx := context.Background()
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+	}})
+	requestedAt := uint64(time.Now().UnixNano())
+	blobKeys := make([]disperser.BlobKey, numBlobs)
+	for i := range blobKeys {
+		blobKeys[i], err = queue.StoreBlob(ctx, &blob, requestedAt+uint64(i))
+		assert.NoError(t, err)
+		_, err = queue.MarkBlobConfirmed(ctx, &disperser.BlobMetadata{
+			BlobHash:     blobKeys[i].BlobHash,
+			MetadataHash: blobKeys[i].MetadataHash,
+			BlobStatus:   disperser.Processing,
+			RequestMetadata: &disperser.RequestMetadata{
+				BlobRequestHeader: blob.RequestHeader,
+				RequestedAt:       requestedAt + uint64(i),
+			},
+		}, &disperser.ConfirmationInfo{
+			BlobIndex:               uint32(i),
+			BlobCommitment:          &core.BlobCommitments{},
+			ConfirmationTxnHash:     common.HexToHash("0x123"),
+			ConfirmationBlockNumber: 150,
+		})
+		assert.NoError(t, err)
+	}
+	// only the reads made while confirming the blobs
+	numGetBlobMetadata := queue.numGetBlobMetadata
+
+	err = finalizer.FinalizeBlobs(ctx)
+	assert.NoError(t, err)
+
+	// the metadata of the page is used as is, and the finalizations are written in batches of 2
+	assert.Equal(t, numGetBlobMetadata, queue.numGetBlobMetadata)
+	assert.Equal(t, 3, queue.numBatchFinalizeWrites)
+	assert.Equal(t, numBlobs, queue.numBlobFinalizeWrites)
+	assert.Equal(t, 0, queue.numSingleFinalizeWrites)
+	for _, blobKey := range blobKeys {
+		metadata, err := queue.BlobStore.GetBlobMetadata(ctx, blobKey)
+		assert.NoError(t, err)
+		assert.Equal(t, disperser.Finalized, metadata.BlobStatus)
+		assert.Equal(t, uint32(1_000_000), metadata.ConfirmationInfo.ConfirmationBlockNumber)
+	}
+}
+
+// revertingBlobStore reverts a blob to processing right before the finalization writes, as if the blob was reverted
+// after the finalizer read it as confirmed
+type revertingBlobStore struct {
+	disperser.BlobStore
+	reverted disperser.BlobKey
+}
+
+func (s *revertingBlobStore) BatchMarkBlobsFinalized(ctx context.Context, metadatas []*disperser.BlobMetadata) ([]disperser.BlobKey, error) {
+	if err := s.BlobStore.MarkBlobProcessing(ctx, s.reverted); err != nil {
+		return nil, err
+	}
+	return s.BlobStore.(disperser.BatchBlobFinalizer).BatchMarkBlobsFinalized(ctx, metadatas)
+}
+
+func TestFinalizerSkipsRevertedBlobs(t *testing.T) {
+	queue := &revertingBlobStore{BlobStore: inmem.NewBlobStore()}
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	ethClient := &mock.MockEthClient{}
+	rpcClient := &mock.MockRPCEthClient{}
+
+	rpcClient.On("CallContext", m.Anything, m.Anything, "eth_getBlockByNumber", "finalized", false).
+		Run(func(args m.Arguments) {
+			args[1].(*types.Header).Number = big.NewInt(1_000_010)
+		}).Return(nil).Once()
+	ethClient.On("TransactionReceipt", m.Anything, m.Anything).Return(&types.Receipt{
+		BlockNumber: new(big.Int).SetUint64(1_000_000),
+	}, nil)
+
+	metrics := batcher.NewMetrics("9100", logger)
+	finalizer := batcher.NewFinalizer(timeout, loopInterval, queue, ethClient, rpcClient, 1, 10, 1, 0, batcher.FinalizerRetryConfig{}, nil, logger, metrics.FinalizerMetrics)
+
+	ctx := context.Background()
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+	}})
+	requestedAt := uint64(time.Now().UnixNano())
+	blobKeys := make([]disperser.BlobKey, 2)
+	for i := range blobKeys {
+		blobKeys[i], err = queue.StoreBlob(ctx, &blob, requestedAt+uint64(i))
+		assert.NoError(t, err)
+		metadata, err := queue.GetBlobMetadata(ctx, blobKeys[i])
+		assert.NoError(t, err)
+		_, err = queue.MarkBlobConfirmed(ctx, metadata, &disperser.ConfirmationInfo{
+			BlobIndex:               uint32(i),
+			BlobCommitment:          &core.BlobCommitments{},
+			ConfirmationTxnHash:     common.HexToHash("0x123"),
+			ConfirmationBlockNumber: 150,
+		})
+		assert.NoError(t, err)
+	}
+	queue.reverted = blobKeys[0]
+
+	results, err := finalizer.FinalizeNow(ctx, blobKeys)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, results[blobKeys[0]], disperser.ErrBlobNotConfirmed)
+	assert.NoError(t, results[blobKeys[1]])
+
+	// the reverted blob is not overwritten by the finalization
+	metadata, err := queue.GetBlobMetadata(ctx, blobKeys[0])
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, metadata.BlobStatus)
+	metadata, err = queue.GetBlobMetadata(ctx, blobKeys[1])
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Finalized, metadata.BlobStatus)
+}
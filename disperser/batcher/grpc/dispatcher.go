@@ -11,23 +11,30 @@ import (
 	"github.com/Layr-Labs/eigenda/disperser"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type Config struct {
+	// Timeout is the deadline of the dispersal of a batch to the operators
 	Timeout time.Duration
+	// StoreChunksTimeout is how long each operator is given to store its chunks, so that a slow operator is cut off and
+	// counted as a non-signer before the batch deadline. 0 gives each operator until the batch deadline.
+	StoreChunksTimeout time.Duration
+	// ConnectionPool configures the connections to the operators, which are reused across batches
+	ConnectionPool common.ConnectionPoolConfig
 }
 
 type dispatcher struct {
 	*Config
 
 	logger common.Logger
+	pool   *common.ConnectionPool
 }
 
 func NewDispatcher(cfg *Config, logger common.Logger) *dispatcher {
 	return &dispatcher{
 		Config: cfg,
 		logger: logger,
+		pool:   common.NewConnectionPool(cfg.ConnectionPool),
 	}
 }
 
@@ -36,12 +43,28 @@ var _ disperser.Dispatcher = (*dispatcher)(nil)
 func (c *dispatcher) DisperseBatch(ctx context.Context, state *core.IndexedOperatorState, blobs []core.EncodedBlob, header *core.BatchHeader) chan core.SignerMessage {
 	update := make(chan core.SignerMessage, len(state.IndexedOperators))
 
+	// Operators must only be asked to sign a batch whose assignments are derived from the state at its reference block
+	if err := state.ValidateReferenceBlock(header.ReferenceBlockNumber); err != nil {
+		for id := range state.IndexedOperators {
+			update <- core.SignerMessage{
+				Err:       err,
+				Signature: nil,
+				Operator:  id,
+			}
+		}
+		return update
+	}
+
 	// Disperse
 	c.sendAllChunks(ctx, state, blobs, header, update)
 
 	return update
 }
 
+func (c *dispatcher) EstimateCoverage(state *core.IndexedOperatorState, blobs []core.EncodedBlob, header *core.BatchHeader) map[core.QuorumID]float64 {
+	return disperser.EstimateSigningCoverage(state, blobs)
+}
+
 func (c *dispatcher) sendAllChunks(ctx context.Context, state *core.IndexedOperatorState, blobs []core.EncodedBlob, header *core.BatchHeader, update chan core.SignerMessage) {
 	for id, op := range state.IndexedOperators {
 		go func(op core.IndexedOperatorInfo, id core.OperatorID) {
@@ -49,18 +72,21 @@ func (c *dispatcher) sendAllChunks(ctx context.Context, state *core.IndexedOpera
 			for i, blob := range blobs {
 				blobMessages[i] = blob[id]
 			}
-			sig, err := c.sendChunks(ctx, blobMessages, header, &op)
+			start := time.Now()
+			sig, err := c.sendChunks(ctx, blobMessages, header, id, &op)
 			if err != nil {
 				update <- core.SignerMessage{
 					Err:       err,
 					Signature: nil,
 					Operator:  id,
+					Latency:   time.Since(start),
 				}
 			} else {
 				update <- core.SignerMessage{
 					Signature: sig,
 					Operator:  id,
 					Err:       nil,
+					Latency:   time.Since(start),
 				}
 			}
 
@@ -72,22 +98,25 @@ func (c *dispatcher) sendAllChunks(ctx context.Context, state *core.IndexedOpera
 	}
 }
 
-func (c *dispatcher) sendChunks(ctx context.Context, blobs []*core.BlobMessage, header *core.BatchHeader, op *core.IndexedOperatorInfo) (*core.Signature, error) {
+func (c *dispatcher) sendChunks(ctx context.Context, blobs []*core.BlobMessage, header *core.BatchHeader, id core.OperatorID, op *core.IndexedOperatorInfo) (*core.Signature, error) {
 	// TODO Add secure Grpc
 
-	conn, err := grpc.Dial(
-		core.OperatorSocket(op.Socket).GetDispersalSocket(),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	// The socket of the operator comes from the indexed operator sockets, so the pooled connection to a previous socket of
+	// the operator is closed once the operator updates its socket
+	conn, err := c.pool.GetConnection(id.Hex(), core.OperatorSocket(op.Socket).GetDispersalSocket())
 	if err != nil {
 		c.logger.Warn("Disperser cannot connect to operator dispersal socket", "dispersal_socket", core.OperatorSocket(op.Socket).GetDispersalSocket(), "err", err)
 		return nil, err
 	}
-	defer conn.Close()
 
 	gc := node.NewDispersalClient(conn)
 	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
 	defer cancel()
+	if c.StoreChunksTimeout > 0 {
+		var cancelStore context.CancelFunc
+		ctx, cancelStore = context.WithTimeout(ctx, c.StoreChunksTimeout)
+		defer cancelStore()
+	}
 
 	request, totalSize, err := GetStoreChunksRequest(blobs, header)
 	if err != nil {
@@ -178,6 +207,7 @@ func getBlobMessage(blob *core.BlobMessage) (*node.Blob, error) {
 			LengthProof:      &lengthProofData,
 			Length:           uint32(blob.BlobHeader.Length),
 			QuorumHeaders:    quorumHeaders,
+			Version:          uint32(blob.BlobHeader.Version),
 		},
 		Bundles: bundles,
 	}, nil
@@ -188,5 +218,6 @@ func getBatchHeaderMessage(header *core.BatchHeader) *node.BatchHeader {
 	return &node.BatchHeader{
 		BatchRoot:            header.BatchRoot[:],
 		ReferenceBlockNumber: uint32(header.ReferenceBlockNumber),
+		SigningScheme:        uint32(header.SigningScheme),
 	}
 }
@@ -0,0 +1,207 @@
+package dispatcher_test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigenda/api/grpc/node"
+	cmock "github.com/Layr-Labs/eigenda/common/mock"
+	"github.com/Layr-Labs/eigenda/core"
+	coremock "github.com/Layr-Labs/eigenda/core/mock"
+	dispatcher "github.com/Layr-Labs/eigenda/disperser/batcher/grpc"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEstimateCoverage(t *testing.T) {
+	dat, err := coremock.NewChainDataMock([]int{10, 20, 30, 40})
+	assert.NoError(t, err)
+	state := dat.GetTotalOperatorState(context.Background(), 0).IndexedOperatorState
+
+	operatorIDs := make([]core.OperatorID, 4)
+	for id, op := range state.Operators[0] {
+		operatorIDs[op.Index] = id
+	}
+	// operator 0 is not in quorum 1
+	quorum1Operators := make(map[core.OperatorID]*core.OperatorInfo)
+	for id, op := range state.Operators[1] {
+		if id != operatorIDs[0] {
+			quorum1Operators[id] = op
+		}
+	}
+	state.Operators[1] = quorum1Operators
+	state.Totals[1] = &core.OperatorInfo{Stake: big.NewInt(90), Index: 3}
+
+	chunk := &core.Chunk{}
+	blobs := []core.EncodedBlob{
+		{
+			operatorIDs[0]: {Bundles: core.Bundles{0: {chunk}}},
+			operatorIDs[1]: {Bundles: core.Bundles{0: {chunk}}},
+		},
+		{
+			operatorIDs[1]: {Bundles: core.Bundles{1: {chunk}}},
+			operatorIDs[2]: {Bundles: core.Bundles{1: {chunk}}},
+			// operator 3 receives no chunks
+			operatorIDs[3]: {Bundles: core.Bundles{1: {}}},
+		},
+	}
+
+	d := dispatcher.NewDispatcher(&dispatcher.Config{Timeout: time.Second}, &cmock.Logger{})
+	coverage := d.EstimateCoverage(state, blobs, &core.BatchHeader{})
+
+	// operators 0, 1 and 2 are assigned chunks and may sign
+	assert.Len(t, coverage, len(state.Operators))
+	assert.InDelta(t, 60.0, coverage[0], 1e-9)        // (10+20+30)/100
+	assert.InDelta(t, 100.0*50/90, coverage[1], 1e-9) // (20+30)/90
+	assert.InDelta(t, 60.0, coverage[2], 1e-9)        // (10+20+30)/100
+}
+
+func TestDisperseBatchReferenceBlockMismatch(t *testing.T) {
+	dat, err := coremock.NewChainDataMock([]int{10, 20, 30})
+	assert.NoError(t, err)
+	state := dat.GetTotalOperatorState(context.Background(), 10).IndexedOperatorState
+
+	d := dispatcher.NewDispatcher(&dispatcher.Config{Timeout: time.Second}, &cmock.Logger{})
+	update := d.DisperseBatch(context.Background(), state, []core.EncodedBlob{}, &core.BatchHeader{ReferenceBlockNumber: 11})
+
+	for range state.IndexedOperators {
+		msg := <-update
+		assert.ErrorIs(t, msg.Err, core.ErrReferenceBlockMismatch)
+		assert.Nil(t, msg.Signature)
+	}
+}
+
+// countingListener counts the connections accepted by the listener
+type countingListener struct {
+	net.Listener
+	accepted atomic.Int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+	}
+	return conn, err
+}
+
+type dispersalServer struct {
+	node.UnimplementedDispersalServer
+
+	keyPair *core.KeyPair
+	// delay is how long the operator takes to store the chunks
+	delay time.Duration
+}
+
+func (s *dispersalServer) StoreChunks(ctx context.Context, in *node.StoreChunksRequest) (*node.StoreChunksReply, error) {
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	sig := s.keyPair.SignMessage([32]byte{})
+	return &node.StoreChunksReply{Signature: sig.Serialize()}, nil
+}
+
+// startDispersalServer starts an operator dispersal server and returns the socket of the operator
+func startDispersalServer(t *testing.T, keyPair *core.KeyPair) (string, *countingListener) {
+	return startSlowDispersalServer(t, keyPair, 0)
+}
+
+// startSlowDispersalServer starts an operator dispersal server taking the given delay to store chunks, and returns the
+// socket of the operator
+func startSlowDispersalServer(t *testing.T, keyPair *core.KeyPair, delay time.Duration) (string, *countingListener) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	listener := &countingListener{Listener: lis}
+	server := grpc.NewServer()
+	node.RegisterDispersalServer(server, &dispersalServer{keyPair: keyPair, delay: delay})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	port := lis.Addr().(*net.TCPAddr).Port
+	return fmt.Sprintf("127.0.0.1:%d;%d", port, port), listener
+}
+
+func TestDisperseBatchReusesConnections(t *testing.T) {
+	keyPair, err := core.GenRandomBlsKeys()
+	assert.NoError(t, err)
+	socket, listener := startDispersalServer(t, keyPair)
+
+	operatorID := core.OperatorID{1}
+	state := &core.IndexedOperatorState{
+		OperatorState: &core.OperatorState{
+			Operators:   map[core.QuorumID]map[core.OperatorID]*core.OperatorInfo{},
+			Totals:      map[core.QuorumID]*core.OperatorInfo{},
+			BlockNumber: 10,
+		},
+		IndexedOperators: map[core.OperatorID]*core.IndexedOperatorInfo{
+			operatorID: {Socket: socket},
+		},
+	}
+	header := &core.BatchHeader{ReferenceBlockNumber: 10}
+
+	d := dispatcher.NewDispatcher(&dispatcher.Config{Timeout: 5 * time.Second}, &cmock.Logger{})
+	for i := 0; i < 2; i++ {
+		msg := <-d.DisperseBatch(context.Background(), state, []core.EncodedBlob{}, header)
+		assert.NoError(t, msg.Err)
+		assert.Equal(t, operatorID, msg.Operator)
+	}
+	// both batches are dispersed over the same connection
+	assert.Equal(t, int32(1), listener.accepted.Load())
+
+	// the operator updates its socket
+	newSocket, newListener := startDispersalServer(t, keyPair)
+	state.IndexedOperators[operatorID] = &core.IndexedOperatorInfo{Socket: newSocket}
+	msg := <-d.DisperseBatch(context.Background(), state, []core.EncodedBlob{}, header)
+	assert.NoError(t, msg.Err)
+	assert.Equal(t, int32(1), listener.accepted.Load())
+	assert.Equal(t, int32(1), newListener.accepted.Load())
+}
+
+func TestDisperseBatchStoreChunksTimeout(t *testing.T) {
+	keyPair, err := core.GenRandomBlsKeys()
+	assert.NoError(t, err)
+	fastSocket, _ := startDispersalServer(t, keyPair)
+	slowSocket, _ := startSlowDispersalServer(t, keyPair, 5*time.Second)
+
+	fastOperator := core.OperatorID{1}
+	slowOperator := core.OperatorID{2}
+	state := &core.IndexedOperatorState{
+		OperatorState: &core.OperatorState{
+			Operators:   map[core.QuorumID]map[core.OperatorID]*core.OperatorInfo{},
+			Totals:      map[core.QuorumID]*core.OperatorInfo{},
+			BlockNumber: 10,
+		},
+		IndexedOperators: map[core.OperatorID]*core.IndexedOperatorInfo{
+			fastOperator: {Socket: fastSocket},
+			slowOperator: {Socket: slowSocket},
+		},
+	}
+	header := &core.BatchHeader{ReferenceBlockNumber: 10}
+
+	// the slow operator is cut off well before the batch deadline
+	d := dispatcher.NewDispatcher(&dispatcher.Config{Timeout: 10 * time.Second, StoreChunksTimeout: 200 * time.Millisecond}, &cmock.Logger{})
+	start := time.Now()
+	update := d.DisperseBatch(context.Background(), state, []core.EncodedBlob{}, header)
+	replies := make(map[core.OperatorID]core.SignerMessage)
+	for range state.IndexedOperators {
+		msg := <-update
+		replies[msg.Operator] = msg
+	}
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	assert.NoError(t, replies[fastOperator].Err)
+	assert.NotNil(t, replies[fastOperator].Signature)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(replies[slowOperator].Err))
+	assert.Nil(t, replies[slowOperator].Signature)
+}
@@ -0,0 +1,74 @@
+package batcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Layr-Labs/eigenda/core"
+)
+
+const (
+	DependencyEncoder    = "encoder"
+	DependencyEthClient  = "ethClient"
+	DependencyChainState = "chainState"
+)
+
+// healthCheckBlob is the blob encoded to check that the encoder is reachable
+var healthCheckBlob = make([]byte, 32)
+
+// healthCheckEncodingParams are the smallest encoding params sufficient for healthCheckBlob
+var healthCheckEncodingParams = core.EncodingParams{
+	ChunkLength: 1,
+	NumChunks:   4,
+}
+
+// DependencyCheckError is returned by CheckDependencies when one or more dependencies are unhealthy
+type DependencyCheckError struct {
+	// Unhealthy maps the name of each unhealthy dependency to the error returned when checking it
+	Unhealthy map[string]error
+}
+
+func (e *DependencyCheckError) Error() string {
+	names := make([]string, 0, len(e.Unhealthy))
+	for name := range e.Unhealthy {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	failures := make([]string, len(names))
+	for i, name := range names {
+		failures[i] = fmt.Sprintf("%s: %v", name, e.Unhealthy[name])
+	}
+	return fmt.Sprintf("unhealthy dependencies: %s", strings.Join(failures, "; "))
+}
+
+// CheckDependencies checks that the encoder, the eth client and the chain state are reachable.
+// It returns a *DependencyCheckError listing the unhealthy dependencies, or nil if all of them are healthy.
+func (b *Batcher) CheckDependencies(ctx context.Context) error {
+	unhealthy := make(map[string]error)
+
+	encodingCtx, cancel := context.WithTimeout(ctx, b.EncodingTimeout)
+	_, _, err := b.EncoderClient.EncodeBlob(encodingCtx, healthCheckBlob, healthCheckEncodingParams)
+	cancel()
+	if err != nil {
+		unhealthy[DependencyEncoder] = err
+	}
+
+	chainCtx, cancel := context.WithTimeout(ctx, b.ChainReadTimeout)
+	_, _, err = b.ethClient.GetLatestGasCaps(chainCtx)
+	cancel()
+	if err != nil {
+		unhealthy[DependencyEthClient] = err
+	}
+
+	if _, err = b.ChainState.GetCurrentBlockNumber(); err != nil {
+		unhealthy[DependencyChainState] = err
+	}
+
+	if len(unhealthy) > 0 {
+		b.logger.Warn("batcher dependency check failed", "unhealthy", len(unhealthy))
+		return &DependencyCheckError{Unhealthy: unhealthy}
+	}
+	return nil
+}
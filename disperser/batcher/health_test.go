@@ -0,0 +1,31 @@
+package batcher_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	bat "github.com/Layr-Labs/eigenda/disperser/batcher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDependencies(t *testing.T) {
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+	ctx := context.Background()
+
+	components.ethClient.On("GetLatestGasCaps").Return(big.NewInt(1), big.NewInt(1), nil).Once()
+	err := batcher.CheckDependencies(ctx)
+	assert.NoError(t, err)
+
+	ethErr := errors.New("connection refused")
+	components.ethClient.On("GetLatestGasCaps").Return(big.NewInt(0), big.NewInt(0), ethErr).Once()
+	err = batcher.CheckDependencies(ctx)
+	assert.Error(t, err)
+
+	var dependencyErr *bat.DependencyCheckError
+	assert.True(t, errors.As(err, &dependencyErr))
+	assert.Len(t, dependencyErr.Unhealthy, 1)
+	assert.ErrorIs(t, dependencyErr.Unhealthy[bat.DependencyEthClient], ethErr)
+}
@@ -0,0 +1,74 @@
+package batcher
+
+import (
+	"fmt"
+
+	"github.com/Layr-Labs/eigenda/common"
+)
+
+// IdleLogPolicy determines how the batcher logs the pulls that find no encoded results to make a batch with
+type IdleLogPolicy string
+
+const (
+	// IdleLogWarn logs a warning on every idle pull
+	IdleLogWarn IdleLogPolicy = "warn"
+	// IdleLogWarnOnce logs a warning on the first idle pull after activity, and suppresses the warnings of the idle pulls
+	// that follow until a batch is made again
+	IdleLogWarnOnce IdleLogPolicy = "warn-once"
+	// IdleLogDebug logs every idle pull at debug level
+	IdleLogDebug IdleLogPolicy = "debug"
+)
+
+// ParseIdleLogPolicy returns the policy with the given name. An empty name is the warn policy.
+func ParseIdleLogPolicy(name string) (IdleLogPolicy, error) {
+	switch policy := IdleLogPolicy(name); policy {
+	case "":
+		return IdleLogWarn, nil
+	case IdleLogWarn, IdleLogWarnOnce, IdleLogDebug:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown idle log policy: %s", name)
+	}
+}
+
+// IdleLogger logs the pulls that find no encoded results according to an IdleLogPolicy, so that idle deployments
+// don't flood the logs with warnings
+type IdleLogger struct {
+	policy IdleLogPolicy
+	logger common.Logger
+
+	// numIdle is the number of consecutive idle pulls
+	numIdle int
+}
+
+// NewIdleLogger creates an IdleLogger logging with the given policy. An empty policy is the warn policy.
+func NewIdleLogger(logger common.Logger, policy IdleLogPolicy) *IdleLogger {
+	if policy == "" {
+		policy = IdleLogWarn
+	}
+	return &IdleLogger{
+		policy: policy,
+		logger: logger,
+	}
+}
+
+// Idle logs an idle pull
+func (l *IdleLogger) Idle() {
+	l.numIdle++
+	switch {
+	case l.policy == IdleLogDebug:
+		l.logger.Debug("no encoded results to make a batch with")
+	case l.policy == IdleLogWarnOnce && l.numIdle > 1:
+		l.logger.Debug("no encoded results to make a batch with", "numIdle", l.numIdle)
+	default:
+		l.logger.Warn("no encoded results to make a batch with")
+	}
+}
+
+// Active records that a pull found encoded results, so that the next idle pull is logged as the first
+func (l *IdleLogger) Active() {
+	if l.numIdle > 1 && l.policy == IdleLogWarnOnce {
+		l.logger.Info("encoded results found after idle pulls", "numIdle", l.numIdle)
+	}
+	l.numIdle = 0
+}
@@ -0,0 +1,66 @@
+package batcher_test
+
+import (
+	"testing"
+
+	"github.com/Layr-Labs/eigenda/disperser/batcher"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func countWarnings(records []*log.Record) int {
+	numWarnings := 0
+	for _, r := range records {
+		if r.Lvl <= log.LvlWarn {
+			numWarnings++
+		}
+	}
+	return numWarnings
+}
+
+func TestIdleLoggerWarnOnce(t *testing.T) {
+	logger, records := newRecordingLogger()
+	idleLogs := batcher.NewIdleLogger(logger, batcher.IdleLogWarnOnce)
+
+	// only the first of the repeated idle pulls is warned about
+	for i := 0; i < 10; i++ {
+		idleLogs.Idle()
+	}
+	assert.Equal(t, 1, countWarnings(*records))
+
+	// the next idle pull after a batch is warned about again
+	idleLogs.Active()
+	idleLogs.Idle()
+	idleLogs.Idle()
+	assert.Equal(t, 2, countWarnings(*records))
+}
+
+func TestIdleLoggerPolicies(t *testing.T) {
+	for _, tc := range []struct {
+		policy              batcher.IdleLogPolicy
+		expectedNumWarnings int
+	}{
+		{policy: "", expectedNumWarnings: 5},
+		{policy: batcher.IdleLogWarn, expectedNumWarnings: 5},
+		{policy: batcher.IdleLogDebug, expectedNumWarnings: 0},
+	} {
+		logger, records := newRecordingLogger()
+		idleLogs := batcher.NewIdleLogger(logger, tc.policy)
+		for i := 0; i < 5; i++ {
+			idleLogs.Idle()
+		}
+		assert.Equal(t, tc.expectedNumWarnings, countWarnings(*records), tc.policy)
+		assert.Len(t, *records, 5)
+	}
+}
+
+func TestParseIdleLogPolicy(t *testing.T) {
+	policy, err := batcher.ParseIdleLogPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, batcher.IdleLogWarn, policy)
+	policy, err = batcher.ParseIdleLogPolicy("warn-once")
+	assert.NoError(t, err)
+	assert.Equal(t, batcher.IdleLogWarnOnce, policy)
+	_, err = batcher.ParseIdleLogPolicy("silent")
+	assert.Error(t, err)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/Layr-Labs/eigenda/common"
 	"github.com/Layr-Labs/eigenda/core"
@@ -16,23 +18,55 @@ import (
 
 type FailReason string
 
+// maxSigningLatencyOperators bounds the number of operator labels of the signing latency histogram.
+// Operators beyond the bound are recorded under the "other" label.
+const maxSigningLatencyOperators = 300
+
 const (
-	FailBatchHeaderHash        FailReason = "batch_header_hash"
-	FailAggregateSignatures    FailReason = "aggregate_signatures"
-	FailNoSignatures           FailReason = "no_signatures"
-	FailConfirmBatch           FailReason = "confirm_batch"
-	FailGetBatchID             FailReason = "get_batch_id"
-	FailUpdateConfirmationInfo FailReason = "update_confirmation_info"
-	FailNoAggregatedSignature  FailReason = "no_aggregated_signature"
+	FailBatchHeaderHash           FailReason = "batch_header_hash"
+	FailAggregateSignatures       FailReason = "aggregate_signatures"
+	FailNoSignatures              FailReason = "no_signatures"
+	FailConfirmBatch              FailReason = "confirm_batch"
+	FailConfirmBatchReverted      FailReason = "confirm_batch_reverted"
+	FailGetBatchID                FailReason = "get_batch_id"
+	FailUpdateConfirmationInfo    FailReason = "update_confirmation_info"
+	FailNoAggregatedSignature     FailReason = "no_aggregated_signature"
+	FailReferenceBlockMismatch    FailReason = "reference_block_mismatch"
+	FailInvalidAggregateSignature FailReason = "invalid_aggregate_signature"
+	FailEmptyQuorum               FailReason = "empty_quorum"
+	FailTooManyNonSigners         FailReason = "too_many_non_signers"
+	FailInsufficientSignatures    FailReason = "insufficient_signatures"
+	FailOperatorNotRegistered     FailReason = "operator_not_registered"
+	FailDispersal                 FailReason = "dispersal"
+	FailCriticalQuorum            FailReason = "critical_quorum"
+	FailInconsistentCommitment    FailReason = "inconsistent_commitment"
+	FailConfirmationTimeout       FailReason = "confirmation_timeout"
 )
 
+// DefaultBatchErrorWindow is the default window the batch errors are counted over in the batch_error_window metric
+const DefaultBatchErrorWindow = 10 * time.Minute
+
 type MetricsConfig struct {
 	HTTPPort      string
 	EnableMetrics bool
+	// BatchErrorWindow is the window the batch errors are counted over in the batch_error_window metric, up to
+	// MaxFailReasonWindow. Defaults to DefaultBatchErrorWindow.
+	BatchErrorWindow time.Duration
 }
 
 type EncodingStreamerMetrics struct {
 	EncodedBlobs *prometheus.GaugeVec
+	Restarts     prometheus.Counter
+	// QueueWaitLatency is the time from the dispersal request until the blob first starts encoding
+	QueueWaitLatency prometheus.Summary
+	// SweptEncodedResults is the number of encoded results deleted because they expired before being confirmed
+	SweptEncodedResults prometheus.Counter
+	// OldestPendingConfirmationAge is the time the oldest encoded blob pending confirmation has been waiting for its
+	// batch to be confirmed
+	OldestPendingConfirmationAge prometheus.Gauge
+	// ExceededPendingConfirmations is the number of blobs that have been pending confirmation for longer than the
+	// maximum duration
+	ExceededPendingConfirmations prometheus.Counter
 }
 
 type TxnManagerMetrics struct {
@@ -61,6 +95,22 @@ type Metrics struct {
 	BatchProcLatency *prometheus.SummaryVec
 	Attestation      *prometheus.GaugeVec
 	BatchError       *prometheus.CounterVec
+	SigningLatency   *prometheus.HistogramVec
+	// BlobRetried and BlobFailed count the failed blobs that are retried and that have failed permanently, by reason
+	BlobRetried *prometheus.CounterVec
+	BlobFailed  *prometheus.CounterVec
+	// InFlightReferenceBlocks is the number of distinct reference blocks of the batches sent for confirmation that haven't
+	// been processed yet. More than one indicates batches fragmented across reference blocks, which multiplies the
+	// operator state fetches.
+	InFlightReferenceBlocks prometheus.Gauge
+	// BatchErrorWindow is the window the batch errors are counted over in the batch_error_window metric
+	BatchErrorWindow time.Duration
+
+	// batchErrors counts the recent batch errors by reason
+	batchErrors *FailReasonWindow
+
+	signingLatencyOperators   map[core.OperatorID]struct{}
+	signingLatencyOperatorsMu sync.Mutex
 
 	httpPort string
 	logger   common.Logger
@@ -80,6 +130,42 @@ func NewMetrics(httpPort string, logger common.Logger) *Metrics {
 			},
 			[]string{"type"},
 		),
+		Restarts: promauto.With(reg).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "encoding_streamer_restarts_total",
+				Help:      "number of times the encoding streamer was restarted because encoding stalled",
+			},
+		),
+		QueueWaitLatency: promauto.With(reg).NewSummary(
+			prometheus.SummaryOpts{
+				Namespace:  namespace,
+				Name:       "encoding_queue_wait_latency_ms",
+				Help:       "latency summary in milliseconds from the dispersal request until the blob starts encoding",
+				Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.95: 0.01, 0.99: 0.001},
+			},
+		),
+		SweptEncodedResults: promauto.With(reg).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "encoded_results_swept_total",
+				Help:      "number of encoded results deleted because they expired before being confirmed",
+			},
+		),
+		OldestPendingConfirmationAge: promauto.With(reg).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "oldest_pending_confirmation_age_seconds",
+				Help:      "time in seconds the oldest encoded blob pending confirmation has been waiting for its batch to be confirmed",
+			},
+		),
+		ExceededPendingConfirmations: promauto.With(reg).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "pending_confirmation_exceeded_total",
+				Help:      "number of blobs pending confirmation for longer than the maximum pending confirmation duration",
+			},
+		),
 	}
 
 	txnManagerMetrics := TxnManagerMetrics{
@@ -194,10 +280,54 @@ func NewMetrics(httpPort string, logger common.Logger) *Metrics {
 			},
 			[]string{"type"},
 		),
-		registry: reg,
-		httpPort: httpPort,
-		logger:   logger,
+		SigningLatency: promauto.With(reg).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "operator_signing_latency_ms",
+				Help:      "time from dispatching a batch to receiving each operator's valid signature in milliseconds",
+				Buckets:   prometheus.ExponentialBuckets(10, 2, 12),
+			},
+			[]string{"operator"},
+		),
+		BlobRetried: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "blob_retried_total",
+				Help:      "number of failed blobs that are retried",
+			},
+			[]string{"reason"},
+		),
+		BlobFailed: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "blob_failed_total",
+				Help:      "number of blobs that have failed permanently",
+			},
+			[]string{"reason"},
+		),
+		InFlightReferenceBlocks: promauto.With(reg).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "in_flight_reference_blocks",
+				Help:      "number of distinct reference blocks of the batches pending confirmation",
+			},
+		),
+		BatchErrorWindow:        DefaultBatchErrorWindow,
+		batchErrors:             NewFailReasonWindow(nil),
+		signingLatencyOperators: make(map[core.OperatorID]struct{}),
+		registry:                reg,
+		httpPort:                httpPort,
+		logger:                  logger,
 	}
+	reg.MustRegister(&batchErrorWindowCollector{
+		metrics: metrics,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "batch_error_window"),
+			"number of batch errors over the last batch error window",
+			[]string{"type"},
+			nil,
+		),
+	})
 	return metrics
 }
 
@@ -238,12 +368,67 @@ func (g *Metrics) IncrementBatchCount(size int64) {
 
 func (g *Metrics) UpdateBatchError(errType FailReason, numBlobs int) {
 	g.BatchError.WithLabelValues(string(errType)).Add(float64(numBlobs))
+	g.batchErrors.Add(errType, numBlobs)
+}
+
+// BatchErrorsInWindow returns the number of batch errors of each reason over the given window, up to
+// MaxFailReasonWindow
+func (g *Metrics) BatchErrorsInWindow(window time.Duration) map[FailReason]int {
+	return g.batchErrors.Counts(window)
+}
+
+// batchErrorWindowCollector reports the number of batch errors of each reason over the batch error window when the
+// metrics are scraped
+type batchErrorWindowCollector struct {
+	metrics *Metrics
+	desc    *prometheus.Desc
+}
+
+func (c *batchErrorWindowCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *batchErrorWindowCollector) Collect(ch chan<- prometheus.Metric) {
+	for reason, count := range c.metrics.BatchErrorsInWindow(c.metrics.BatchErrorWindow) {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), string(reason))
+	}
+}
+
+// UpdateBlobFailure records whether a blob that failed for the given reason is retried or has failed permanently
+func (g *Metrics) UpdateBlobFailure(reason FailReason, retried bool) {
+	if retried {
+		g.BlobRetried.WithLabelValues(string(reason)).Inc()
+	} else {
+		g.BlobFailed.WithLabelValues(string(reason)).Inc()
+	}
+}
+
+func (g *Metrics) UpdateInFlightReferenceBlocks(count int) {
+	g.InFlightReferenceBlocks.Set(float64(count))
 }
 
 func (g *Metrics) ObserveLatency(stage string, latencyMs float64) {
 	g.BatchProcLatency.WithLabelValues(stage).Observe(latencyMs)
 }
 
+// ObserveSigningLatency records the signing latency of the operator
+func (g *Metrics) ObserveSigningLatency(operatorID core.OperatorID, latency time.Duration) {
+	g.SigningLatency.WithLabelValues(g.signingLatencyLabel(operatorID)).Observe(float64(latency.Milliseconds()))
+}
+
+// signingLatencyLabel returns the label of the operator in the signing latency histogram
+func (g *Metrics) signingLatencyLabel(operatorID core.OperatorID) string {
+	g.signingLatencyOperatorsMu.Lock()
+	defer g.signingLatencyOperatorsMu.Unlock()
+	if _, ok := g.signingLatencyOperators[operatorID]; !ok {
+		if len(g.signingLatencyOperators) >= maxSigningLatencyOperators {
+			return "other"
+		}
+		g.signingLatencyOperators[operatorID] = struct{}{}
+	}
+	return operatorID.Hex()
+}
+
 func (g *Metrics) Start(ctx context.Context) {
 	g.logger.Info("starting metrics server at ", "port", g.httpPort)
 	addr := fmt.Sprintf(":%s", g.httpPort)
@@ -264,6 +449,26 @@ func (e *EncodingStreamerMetrics) UpdateEncodedBlobs(count int, size uint64) {
 	e.EncodedBlobs.WithLabelValues("number").Set(float64(count))
 }
 
+func (e *EncodingStreamerMetrics) IncrementRestarts() {
+	e.Restarts.Inc()
+}
+
+func (e *EncodingStreamerMetrics) ObserveQueueWaitLatency(latencyMs float64) {
+	e.QueueWaitLatency.Observe(latencyMs)
+}
+
+func (e *EncodingStreamerMetrics) AddSweptEncodedResults(count int) {
+	e.SweptEncodedResults.Add(float64(count))
+}
+
+func (e *EncodingStreamerMetrics) UpdateOldestPendingConfirmationAge(age time.Duration) {
+	e.OldestPendingConfirmationAge.Set(age.Seconds())
+}
+
+func (e *EncodingStreamerMetrics) AddExceededPendingConfirmations(count int) {
+	e.ExceededPendingConfirmations.Add(float64(count))
+}
+
 func (t *TxnManagerMetrics) ObserveLatency(latencyMs float64) {
 	t.Latency.Observe(latencyMs)
 }
@@ -3,6 +3,7 @@ package mock
 import (
 	"context"
 
+	"github.com/Layr-Labs/eigenda/disperser"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -20,3 +21,12 @@ func (b *MockFinalizer) FinalizeBlobs(ctx context.Context) error {
 	args := b.Called()
 	return args.Error(0)
 }
+
+func (b *MockFinalizer) FinalizeNow(ctx context.Context, keys []disperser.BlobKey) (map[disperser.BlobKey]error, error) {
+	args := b.Called()
+	var results map[disperser.BlobKey]error
+	if args.Get(0) != nil {
+		results = args.Get(0).(map[disperser.BlobKey]error)
+	}
+	return results, args.Error(1)
+}
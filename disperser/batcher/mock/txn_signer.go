@@ -0,0 +1,30 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/Layr-Labs/eigenda/disperser/batcher"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTxnSigner struct {
+	mock.Mock
+}
+
+var _ batcher.TxnSigner = (*MockTxnSigner)(nil)
+
+func (s *MockTxnSigner) Address() gcommon.Address {
+	args := s.Called()
+	return args.Get(0).(gcommon.Address)
+}
+
+func (s *MockTxnSigner) SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	args := s.Called(tx)
+	var signed *types.Transaction
+	if args.Get(0) != nil {
+		signed = args.Get(0).(*types.Transaction)
+	}
+	return signed, args.Error(1)
+}
@@ -0,0 +1,96 @@
+package batcher
+
+import (
+	"context"
+
+	"github.com/Layr-Labs/eigenda/common"
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/Layr-Labs/eigenda/disperser"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// EventObserver receives callbacks as a batch moves through its lifecycle.
+// Callbacks are invoked synchronously from the batcher and finalizer, so implementations should return quickly.
+// Errors returned by an observer are logged and never affect batch processing.
+type EventObserver interface {
+	// OnBatchCreated is called after a batch is created from the encoded blobs.
+	OnBatchCreated(ctx context.Context, batchHeader *core.BatchHeader, blobs []*disperser.BlobMetadata) error
+	// OnBatchDispatched is called after the batch is sent to the operators.
+	OnBatchDispatched(ctx context.Context, batchHeader *core.BatchHeader, blobs []*disperser.BlobMetadata) error
+	// OnBatchAggregated is called after the operator signatures for the batch are aggregated.
+	OnBatchAggregated(ctx context.Context, batchHeader *core.BatchHeader, aggSig *core.SignatureAggregation) error
+	// OnBatchConfirmed is called after the confirmation info of the blobs in the batch is updated.
+	OnBatchConfirmed(ctx context.Context, batchHeader *core.BatchHeader, receipt *types.Receipt, blobs []*disperser.BlobMetadata) error
+	// OnBlobFinalized is called after a confirmed blob is marked as finalized.
+	OnBlobFinalized(ctx context.Context, metadata *disperser.BlobMetadata) error
+}
+
+// BatchResult is the outcome of the confirmation of a batch, passed to the callbacks registered with
+// Batcher.OnBatchConfirmed
+type BatchResult struct {
+	BatchHeader *core.BatchHeader
+	// Receipt is the receipt of the confirmation transaction, nil if the transaction failed
+	Receipt *types.Receipt
+	// Blobs are all the blobs of the batch
+	Blobs []*disperser.BlobMetadata
+	// FailedBlobs are the blobs that were not confirmed and are retried or marked as failed
+	FailedBlobs []*disperser.BlobMetadata
+	// Err is set if the batch failed to be confirmed, in which case all its blobs are in FailedBlobs
+	Err error
+}
+
+// OnBatchConfirmed registers a callback invoked once the confirmation of each batch completes, whether or not it
+// succeeds, so that callers don't have to follow the receipts of the confirmation transactions. Callbacks are invoked
+// synchronously in the order they are registered, so they should return quickly.
+func (b *Batcher) OnBatchConfirmed(callback func(*BatchResult)) {
+	b.batchConfirmedCallbacksMu.Lock()
+	defer b.batchConfirmedCallbacksMu.Unlock()
+	b.batchConfirmedCallbacks = append(b.batchConfirmedCallbacks, callback)
+}
+
+func (b *Batcher) notifyBatchConfirmed(result *BatchResult) {
+	b.batchConfirmedCallbacksMu.RLock()
+	defer b.batchConfirmedCallbacksMu.RUnlock()
+	for _, callback := range b.batchConfirmedCallbacks {
+		callback(result)
+	}
+}
+
+type noopEventObserver struct{}
+
+var _ EventObserver = noopEventObserver{}
+
+func (noopEventObserver) OnBatchCreated(context.Context, *core.BatchHeader, []*disperser.BlobMetadata) error {
+	return nil
+}
+
+func (noopEventObserver) OnBatchDispatched(context.Context, *core.BatchHeader, []*disperser.BlobMetadata) error {
+	return nil
+}
+
+func (noopEventObserver) OnBatchAggregated(context.Context, *core.BatchHeader, *core.SignatureAggregation) error {
+	return nil
+}
+
+func (noopEventObserver) OnBatchConfirmed(context.Context, *core.BatchHeader, *types.Receipt, []*disperser.BlobMetadata) error {
+	return nil
+}
+
+func (noopEventObserver) OnBlobFinalized(context.Context, *disperser.BlobMetadata) error {
+	return nil
+}
+
+// NewNoopEventObserver returns an EventObserver that ignores all events.
+func NewNoopEventObserver() EventObserver {
+	return noopEventObserver{}
+}
+
+// notifyObserver invokes the callback on the observer and logs any error it returns.
+func notifyObserver(logger common.Logger, observer EventObserver, event string, callback func(EventObserver) error) {
+	if observer == nil {
+		return
+	}
+	if err := callback(observer); err != nil {
+		logger.Error("event observer failed", "event", event, "err", err)
+	}
+}
@@ -0,0 +1,211 @@
+package batcher_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/Layr-Labs/eigenda/common"
+	"github.com/Layr-Labs/eigenda/common/logging"
+	cmock "github.com/Layr-Labs/eigenda/common/mock"
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/Layr-Labs/eigenda/disperser"
+	bat "github.com/Layr-Labs/eigenda/disperser/batcher"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+)
+
+// recordingObserver records the lifecycle events it receives and fails every callback
+type recordingObserver struct {
+	mu     sync.Mutex
+	events []string
+}
+
+var _ bat.EventObserver = (*recordingObserver)(nil)
+
+func (o *recordingObserver) record(event string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, event)
+	return errors.New("observer failure")
+}
+
+func (o *recordingObserver) OnBatchCreated(ctx context.Context, batchHeader *core.BatchHeader, blobs []*disperser.BlobMetadata) error {
+	return o.record("created")
+}
+
+func (o *recordingObserver) OnBatchDispatched(ctx context.Context, batchHeader *core.BatchHeader, blobs []*disperser.BlobMetadata) error {
+	return o.record("dispatched")
+}
+
+func (o *recordingObserver) OnBatchAggregated(ctx context.Context, batchHeader *core.BatchHeader, aggSig *core.SignatureAggregation) error {
+	return o.record("aggregated")
+}
+
+func (o *recordingObserver) OnBatchConfirmed(ctx context.Context, batchHeader *core.BatchHeader, receipt *types.Receipt, blobs []*disperser.BlobMetadata) error {
+	return o.record("confirmed")
+}
+
+func (o *recordingObserver) OnBlobFinalized(ctx context.Context, metadata *disperser.BlobMetadata) error {
+	return o.record("finalized")
+}
+
+func TestEventObserverBlobLifecycle(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+
+	observer := &recordingObserver{}
+	batcher.Observer = observer
+
+	logData, err := hex.DecodeString("00000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000")
+	assert.NoError(t, err)
+	txHash := gethcommon.HexToHash("0x1234")
+	receipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{
+			{
+				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+				Data:   logData,
+			},
+		},
+		BlockNumber: big.NewInt(123),
+		TxHash:      txHash,
+	}
+	blobStore := components.blobStore
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	assert.Greater(t, len(components.txnManager.Requests), 0)
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt:  receipt,
+		Err:      nil,
+		Metadata: components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata,
+	})
+	assert.NoError(t, err)
+
+	meta, err := blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
+
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	ethClient := &cmock.MockEthClient{}
+	rpcClient := &cmock.MockRPCEthClient{}
+	rpcClient.On("CallContext", m.Anything, m.Anything, "eth_getBlockByNumber", "finalized", false).
+		Run(func(args m.Arguments) {
+			args[1].(*types.Header).Number = big.NewInt(200)
+		}).Return(nil).Once()
+	ethClient.On("TransactionReceipt").Return(receipt, nil)
+	metrics := bat.NewMetrics("9100", logger)
+	finalizer := bat.NewFinalizer(timeout, loopInterval, blobStore, ethClient, rpcClient, 1, 1, 1, 0, bat.FinalizerRetryConfig{}, observer, logger, metrics.FinalizerMetrics)
+	err = finalizer.FinalizeBlobs(ctx)
+	assert.NoError(t, err)
+
+	meta, err = blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Finalized, meta.BlobStatus)
+
+	assert.Equal(t, []string{"created", "dispatched", "aggregated", "confirmed", "finalized"}, observer.events)
+}
+
+func TestBatcherOnBatchConfirmed(t *testing.T) {
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+		QuorumThreshold:    100,
+	}})
+	components, batcher, getHeartbeats := makeBatcher(t)
+	defer getHeartbeats()
+
+	results := make([]*bat.BatchResult, 0)
+	batcher.OnBatchConfirmed(func(result *bat.BatchResult) {
+		results = append(results, result)
+	})
+
+	logData, err := hex.DecodeString("00000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000")
+	assert.NoError(t, err)
+	receipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{
+			{
+				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+				Data:   logData,
+			},
+		},
+		BlockNumber: big.NewInt(123),
+		TxHash:      gethcommon.HexToHash("0x1234"),
+	}
+	ctx := context.Background()
+	_, blobKey := queueBlob(t, ctx, &blob, components.blobStore)
+
+	out := make(chan bat.EncodingResultOrStatus)
+	err = components.encodingStreamer.RequestEncoding(ctx, out)
+	assert.NoError(t, err)
+	err = components.encodingStreamer.ProcessEncodedBlobs(ctx, <-out)
+	assert.NoError(t, err)
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	components.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	components.txnManager.On("ProcessTransaction").Return(nil)
+
+	// the callback doesn't fire when the batch is dispatched
+	err = batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	metadata := components.txnManager.Requests[len(components.txnManager.Requests)-1].Metadata
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Receipt:  receipt,
+		Metadata: metadata,
+	})
+	assert.NoError(t, err)
+
+	// it fires once the confirmation completes
+	assert.Len(t, results, 1)
+	result := results[0]
+	assert.NoError(t, result.Err)
+	assert.Equal(t, receipt, result.Receipt)
+	assert.NotNil(t, result.BatchHeader)
+	assert.Len(t, result.Blobs, 1)
+	assert.Equal(t, blobKey, result.Blobs[0].GetBlobKey())
+	assert.Empty(t, result.FailedBlobs)
+	meta, err := components.blobStore.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
+	headerHash, err := result.BatchHeader.GetBatchHeaderHash()
+	assert.NoError(t, err)
+	assert.Equal(t, headerHash, meta.ConfirmationInfo.BatchHeaderHash)
+
+	// a failed confirmation is reported as well
+	err = batcher.ProcessConfirmedBatch(ctx, &bat.ReceiptOrErr{
+		Err:      errors.New("transaction reverted"),
+		Metadata: metadata,
+	})
+	assert.Error(t, err)
+	assert.Len(t, results, 2)
+	assert.ErrorContains(t, results[1].Err, "transaction reverted")
+	assert.Nil(t, results[1].Receipt)
+	assert.Len(t, results[1].FailedBlobs, 1)
+}
@@ -0,0 +1,173 @@
+package batcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Layr-Labs/eigenda/common"
+	"github.com/Layr-Labs/eigenda/disperser"
+	"github.com/ethereum/go-ethereum"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// pendingBatchState is the state of a batch sent for confirmation
+type pendingBatchState int
+
+const (
+	// batchPendingConfirmation is a batch waiting for the transaction manager to return the receipt of its confirmBatch
+	// transaction
+	batchPendingConfirmation pendingBatchState = iota
+	// batchReconciled is a batch found confirmed onchain after PendingConfirmationTimeout, which was processed before
+	// the transaction manager returned its receipt
+	batchReconciled
+	// batchTimedOut is a batch not confirmed onchain within PendingConfirmationTimeout, whose blobs were retried
+	batchTimedOut
+)
+
+// pendingBatch is a batch sent for confirmation, tracked until the transaction manager returns its receipt
+type pendingBatch struct {
+	metadata confirmationMetadata
+	sentAt   time.Time
+	state    pendingBatchState
+}
+
+// addPendingBatch tracks the batch sent for confirmation at the given time. It assigns the batch the ID it's tracked
+// by, since batches of the same blobs at the same reference block have the same header hash.
+func (b *Batcher) addPendingBatch(metadata *confirmationMetadata, sentAt time.Time) {
+	b.pendingBatchesMu.Lock()
+	defer b.pendingBatchesMu.Unlock()
+	b.lastPendingBatchID++
+	metadata.pendingBatchID = b.lastPendingBatchID
+	b.pendingBatches[metadata.pendingBatchID] = &pendingBatch{
+		metadata: *metadata,
+		sentAt:   sentAt,
+		state:    batchPendingConfirmation,
+	}
+}
+
+// takePendingBatch stops tracking the batch once the transaction manager returned its receipt, and returns the state
+// it was in. Batches that aren't tracked are pending confirmation.
+func (b *Batcher) takePendingBatch(metadata confirmationMetadata) pendingBatchState {
+	b.pendingBatchesMu.Lock()
+	defer b.pendingBatchesMu.Unlock()
+	pending, ok := b.pendingBatches[metadata.pendingBatchID]
+	if !ok {
+		return batchPendingConfirmation
+	}
+	delete(b.pendingBatches, metadata.pendingBatchID)
+	return pending.state
+}
+
+// resolvePendingBatch moves the batch from pending confirmation to the given state, and returns false if the batch
+// isn't pending confirmation anymore, e.g. because its receipt was returned in the meantime
+func (b *Batcher) resolvePendingBatch(pendingBatchID uint64, state pendingBatchState) bool {
+	b.pendingBatchesMu.Lock()
+	defer b.pendingBatchesMu.Unlock()
+	pending, ok := b.pendingBatches[pendingBatchID]
+	if !ok || pending.state != batchPendingConfirmation {
+		return false
+	}
+	pending.state = state
+	return true
+}
+
+// getTimedOutBatches returns the batches pending confirmation since before the deadline
+func (b *Batcher) getTimedOutBatches(deadline time.Time) []*pendingBatch {
+	b.pendingBatchesMu.Lock()
+	defer b.pendingBatchesMu.Unlock()
+	timedOut := make([]*pendingBatch, 0)
+	for _, pending := range b.pendingBatches {
+		if pending.state == batchPendingConfirmation && pending.sentAt.Before(deadline) {
+			timedOut = append(timedOut, pending)
+		}
+	}
+	return timedOut
+}
+
+// HandleTimedOutBatches resolves the batches pending confirmation for longer than PendingConfirmationTimeout. The chain
+// is checked first: a batch confirmed onchain is processed with the receipt of its confirmation transaction, and the
+// blobs of the other batches are retried so that they are batched again. The receipt of a timed-out batch returned
+// later by the transaction manager is only applied to its blobs that haven't been batched again since.
+func (b *Batcher) HandleTimedOutBatches(ctx context.Context) {
+	if b.PendingConfirmationTimeout <= 0 {
+		return
+	}
+	for _, pending := range b.getTimedOutBatches(b.EncodingStreamer.Now().Add(-b.PendingConfirmationTimeout)) {
+		pendingBatchID := pending.metadata.pendingBatchID
+		referenceBlockNumber := pending.metadata.batchHeader.ReferenceBlockNumber
+		headerHash, err := pending.metadata.batchHeader.GetBatchHeaderHash()
+		if err != nil {
+			b.logger.Error("failed to get the header hash of the batch pending confirmation past the timeout", "err", err)
+			continue
+		}
+		receipt, err := b.findBatchConfirmation(ctx, headerHash, referenceBlockNumber)
+		if err != nil {
+			// the batch is checked again before the next batch is created
+			b.logger.Error("failed to check whether the batch pending confirmation past the timeout is confirmed onchain", "batchHeaderHash", gcommon.Hash(headerHash).Hex(), "err", err)
+			continue
+		}
+
+		if receipt != nil {
+			if !b.resolvePendingBatch(pendingBatchID, batchReconciled) {
+				continue
+			}
+			b.logger.Warn("batch pending confirmation past the timeout is confirmed onchain, processing its confirmation", "batchHeaderHash", gcommon.Hash(headerHash).Hex(), "txnHash", receipt.TxHash.Hex())
+			if err := b.handleConfirmedBatch(ctx, pending.metadata, &ReceiptOrErr{Receipt: receipt, Metadata: pending.metadata}); err != nil {
+				b.logger.Error("failed to process the confirmation of the batch pending confirmation past the timeout", "batchHeaderHash", gcommon.Hash(headerHash).Hex(), "err", err)
+			}
+			continue
+		}
+
+		if !b.resolvePendingBatch(pendingBatchID, batchTimedOut) {
+			continue
+		}
+		b.logger.Warn("batch not confirmed onchain within the timeout, retrying its blobs", "batchHeaderHash", gcommon.Hash(headerHash).Hex(), "numBlobs", len(pending.metadata.blobs), "timeout", b.PendingConfirmationTimeout)
+		b.removeInFlightBatch(referenceBlockNumber)
+		_ = b.handleFailure(ctx, pending.metadata.blobs, FailConfirmationTimeout)
+	}
+}
+
+// findBatchConfirmation returns the receipt of the successful transaction that confirmed the batch with the given header
+// hash onchain, or nil if the batch isn't confirmed. The batch can only be confirmed after its reference block, which
+// bounds the blocks searched.
+func (b *Batcher) findBatchConfirmation(ctx context.Context, headerHash [32]byte, referenceBlockNumber uint) (*types.Receipt, error) {
+	logs, err := b.ethClient.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(uint64(referenceBlockNumber)),
+		Addresses: []gcommon.Address{b.ServiceManagerAddress},
+		Topics: [][]gcommon.Hash{
+			{common.BatchConfirmedEventSigHash},
+			{gcommon.Hash(headerHash)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error filtering BatchConfirmed logs: %w", err)
+	}
+	for _, log := range logs {
+		if log.Removed {
+			continue
+		}
+		receipt, err := b.ethClient.TransactionReceipt(ctx, log.TxHash)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching the receipt of transaction %s: %w", log.TxHash.Hex(), err)
+		}
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			return receipt, nil
+		}
+	}
+	return nil, nil
+}
+
+// rebatchedBlobs returns the keys of the blobs that are pending confirmation in a batch, i.e. the blobs of a timed-out
+// batch that have been batched again since its blobs were retried
+func (b *Batcher) rebatchedBlobs(blobs []*disperser.BlobMetadata) map[disperser.BlobKey]struct{} {
+	rebatched := make(map[disperser.BlobKey]struct{})
+	for _, metadata := range blobs {
+		if b.EncodingStreamer.IsBlobPendingConfirmation(metadata) {
+			rebatched[metadata.GetBlobKey()] = struct{}{}
+		}
+	}
+	return rebatched
+}
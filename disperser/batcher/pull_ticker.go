@@ -0,0 +1,46 @@
+package batcher
+
+import (
+	"math/rand"
+	"time"
+)
+
+// PullTicker schedules the batcher's pulls from the queue. Each interval is drawn uniformly from the band of
+// JitterPercent percent around the pull interval, so that replicas polling on the same interval spread their load.
+type PullTicker struct {
+	interval      time.Duration
+	jitterPercent uint
+	after         func(time.Duration) <-chan time.Time
+	rand          *rand.Rand
+}
+
+// NewPullTicker creates a PullTicker for the given interval and jitter percentage, which is capped at 100. after waits
+// for the given duration and then sends the current time, like time.After, which it defaults to if nil. It lets tests
+// drive the ticker with a fake clock.
+func NewPullTicker(interval time.Duration, jitterPercent uint, after func(time.Duration) <-chan time.Time) *PullTicker {
+	if jitterPercent > 100 {
+		jitterPercent = 100
+	}
+	if after == nil {
+		after = time.After
+	}
+	return &PullTicker{
+		interval:      interval,
+		jitterPercent: jitterPercent,
+		after:         after,
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Next returns a channel that receives the next tick once the jittered interval elapses
+func (t *PullTicker) Next() <-chan time.Time {
+	return t.after(t.nextInterval())
+}
+
+func (t *PullTicker) nextInterval() time.Duration {
+	if t.jitterPercent == 0 {
+		return t.interval
+	}
+	band := float64(t.interval) * float64(t.jitterPercent) / 100
+	return t.interval + time.Duration((2*t.rand.Float64()-1)*band)
+}
@@ -0,0 +1,69 @@
+package batcher_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigenda/disperser/batcher"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock fires each wait immediately, advancing its time by the requested duration
+type fakeClock struct {
+	now   time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestPullTickerJitter(t *testing.T) {
+	interval := 10 * time.Second
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	ticker := batcher.NewPullTicker(interval, 20, clock.After)
+
+	const numTicks = 1000
+	last := clock.now
+	intervals := make(map[time.Duration]struct{})
+	for i := 0; i < numTicks; i++ {
+		tick := <-ticker.Next()
+		elapsed := tick.Sub(last)
+		last = tick
+		assert.GreaterOrEqual(t, elapsed, 8*time.Second)
+		assert.LessOrEqual(t, elapsed, 12*time.Second)
+		intervals[elapsed] = struct{}{}
+	}
+	assert.Len(t, clock.waits, numTicks)
+	// the intervals vary within the band
+	assert.Greater(t, len(intervals), numTicks/2)
+
+	// the intervals are spread on both sides of the pull interval
+	var shorter, longer int
+	for _, wait := range clock.waits {
+		if wait < interval {
+			shorter++
+		} else if wait > interval {
+			longer++
+		}
+	}
+	assert.Greater(t, shorter, numTicks/4)
+	assert.Greater(t, longer, numTicks/4)
+}
+
+func TestPullTickerWithoutJitter(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	ticker := batcher.NewPullTicker(10*time.Second, 0, clock.After)
+
+	for i := 0; i < 10; i++ {
+		<-ticker.Next()
+	}
+	for _, wait := range clock.waits {
+		assert.Equal(t, 10*time.Second, wait)
+	}
+	assert.Equal(t, time.Unix(100, 0), clock.now)
+}
@@ -0,0 +1,168 @@
+package batcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Layr-Labs/eigenda/common"
+	"github.com/Layr-Labs/eigenda/disperser"
+	"github.com/ethereum/go-ethereum"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// BatchDiscrepancy is a batch that blobs in the store are confirmed in, but that doesn't match the batches confirmed
+// onchain
+type BatchDiscrepancy struct {
+	BatchID         uint32
+	BatchHeaderHash [32]byte
+	// BlobKeys are the keys of the confirmed or finalized blobs of the batch
+	BlobKeys []disperser.BlobKey
+	Reason   string
+}
+
+// ReconciliationReport is the result of cross-checking the confirmed and finalized blobs in the store against the
+// BatchConfirmed events onchain
+type ReconciliationReport struct {
+	NumBlobs      int
+	NumBatches    int
+	Discrepancies []*BatchDiscrepancy
+}
+
+// Reconciler detects drift between the blob store and the chain, i.e. blobs marked as confirmed or finalized whose
+// batch was never confirmed onchain.
+type Reconciler struct {
+	blobStore             disperser.BlobStore
+	ethClient             common.EthClient
+	serviceManagerAddress gethcommon.Address
+	numBlobsPerFetch      int32
+	logger                common.Logger
+}
+
+func NewReconciler(
+	blobStore disperser.BlobStore,
+	ethClient common.EthClient,
+	serviceManagerAddress gethcommon.Address,
+	numBlobsPerFetch int32,
+	logger common.Logger,
+) *Reconciler {
+	return &Reconciler{
+		blobStore:             blobStore,
+		ethClient:             ethClient,
+		serviceManagerAddress: serviceManagerAddress,
+		numBlobsPerFetch:      numBlobsPerFetch,
+		logger:                logger,
+	}
+}
+
+type reconciledBatch struct {
+	batchID         uint32
+	batchHeaderHash [32]byte
+	blobKeys        []disperser.BlobKey
+}
+
+// Reconcile scans the confirmed and finalized blobs, groups them by batch and verifies that a BatchConfirmed event with
+// the batch ID and batch header hash of each batch exists onchain. Batches without a matching event are reported as
+// discrepancies.
+func (r *Reconciler) Reconcile(ctx context.Context) (*ReconciliationReport, error) {
+	report := &ReconciliationReport{}
+
+	// Blobs of the same batch ID are split further by batch header hash, since a batch ID confirmed with different
+	// headers is itself a discrepancy
+	batches := make(map[uint32]map[[32]byte]*reconciledBatch)
+	for _, status := range []disperser.BlobStatus{disperser.Confirmed, disperser.Finalized, disperser.Pruned} {
+		metadatas, exclusiveStartKey, err := r.blobStore.GetBlobMetadataByStatusWithPagination(ctx, status, r.numBlobsPerFetch, nil)
+		for len(metadatas) > 0 {
+			if err != nil {
+				return nil, fmt.Errorf("Reconcile: error getting blob metadata: %w", err)
+			}
+			for _, m := range metadatas {
+				if m.ConfirmationInfo == nil {
+					continue
+				}
+				report.NumBlobs++
+				info := m.ConfirmationInfo
+				if _, ok := batches[info.BatchID]; !ok {
+					batches[info.BatchID] = make(map[[32]byte]*reconciledBatch)
+				}
+				batch, ok := batches[info.BatchID][info.BatchHeaderHash]
+				if !ok {
+					batch = &reconciledBatch{
+						batchID:         info.BatchID,
+						batchHeaderHash: info.BatchHeaderHash,
+					}
+					batches[info.BatchID][info.BatchHeaderHash] = batch
+				}
+				batch.blobKeys = append(batch.blobKeys, m.GetBlobKey())
+			}
+
+			if exclusiveStartKey == nil {
+				break
+			}
+			metadatas, exclusiveStartKey, err = r.blobStore.GetBlobMetadataByStatusWithPagination(ctx, status, r.numBlobsPerFetch, exclusiveStartKey)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Reconcile: error getting blob metadata: %w", err)
+		}
+	}
+
+	batchIDs := make([]uint32, 0, len(batches))
+	for batchID := range batches {
+		batchIDs = append(batchIDs, batchID)
+	}
+	sort.Slice(batchIDs, func(i, j int) bool { return batchIDs[i] < batchIDs[j] })
+
+	for _, batchID := range batchIDs {
+		for _, batch := range batches[batchID] {
+			report.NumBatches++
+			reason, err := r.checkBatch(ctx, batch)
+			if err != nil {
+				return nil, err
+			}
+			if reason == "" {
+				continue
+			}
+			r.logger.Warn("Reconcile: confirmed blobs don't match the onchain batches", "batchID", batch.batchID, "batchHeaderHash", gethcommon.Hash(batch.batchHeaderHash).Hex(), "numBlobs", len(batch.blobKeys), "reason", reason)
+			report.Discrepancies = append(report.Discrepancies, &BatchDiscrepancy{
+				BatchID:         batch.batchID,
+				BatchHeaderHash: batch.batchHeaderHash,
+				BlobKeys:        batch.blobKeys,
+				Reason:          reason,
+			})
+		}
+	}
+
+	r.logger.Info("Reconcile: reconciled confirmed blobs with onchain batches", "numBlobs", report.NumBlobs, "numBatches", report.NumBatches, "numDiscrepancies", len(report.Discrepancies))
+	return report, nil
+}
+
+// checkBatch returns the reason the batch doesn't match the onchain batches, or an empty string if a matching
+// BatchConfirmed event exists
+func (r *Reconciler) checkBatch(ctx context.Context, batch *reconciledBatch) (string, error) {
+	logs, err := r.ethClient.FilterLogs(ctx, ethereum.FilterQuery{
+		Addresses: []gethcommon.Address{r.serviceManagerAddress},
+		Topics: [][]gethcommon.Hash{
+			{common.BatchConfirmedEventSigHash},
+			{gethcommon.Hash(batch.batchHeaderHash)},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("Reconcile: error filtering BatchConfirmed logs of batch %d: %w", batch.batchID, err)
+	}
+	if len(logs) == 0 {
+		return "no BatchConfirmed event onchain for the batch header hash", nil
+	}
+
+	for _, log := range logs {
+		batchID, err := parseBatchConfirmedLogData(log.Data)
+		if err != nil {
+			r.logger.Warn("Reconcile: failed to parse BatchConfirmed log", "txHash", log.TxHash.Hex(), "err", err)
+			continue
+		}
+		if batchID == batch.batchID {
+			return "", nil
+		}
+		r.logger.Debug("Reconcile: BatchConfirmed event has a different batch ID", "batchID", batch.batchID, "onchainBatchID", batchID)
+	}
+	return "BatchConfirmed event onchain has a different batch ID", nil
+}
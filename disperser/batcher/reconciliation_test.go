@@ -0,0 +1,91 @@
+package batcher_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Layr-Labs/eigenda/common"
+	"github.com/Layr-Labs/eigenda/common/logging"
+	cmock "github.com/Layr-Labs/eigenda/common/mock"
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/Layr-Labs/eigenda/disperser"
+	"github.com/Layr-Labs/eigenda/disperser/batcher"
+	"github.com/Layr-Labs/eigenda/disperser/common/inmem"
+	"github.com/ethereum/go-ethereum"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileConfirmedBlobs(t *testing.T) {
+	queue := inmem.NewBlobStore()
+	ethClient := &cmock.MockEthClient{}
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	serviceManagerAddress := gethcommon.HexToAddress("0x1234")
+	reconciler := batcher.NewReconciler(queue, ethClient, serviceManagerAddress, 1, logger)
+
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+	}})
+	ctx := context.Background()
+	confirm := func(requestedAt uint64, batchID uint32, batchHeaderHash [32]byte, finalize bool) disperser.BlobKey {
+		key, err := queue.StoreBlob(ctx, &blob, requestedAt)
+		assert.NoError(t, err)
+		metadata, err := queue.GetBlobMetadata(ctx, key)
+		assert.NoError(t, err)
+		_, err = queue.MarkBlobConfirmed(ctx, metadata, &disperser.ConfirmationInfo{
+			BatchHeaderHash: batchHeaderHash,
+			BatchID:         batchID,
+		})
+		assert.NoError(t, err)
+		if finalize {
+			assert.NoError(t, queue.MarkBlobFinalized(ctx, key))
+		}
+		return key
+	}
+	onchainBatches := func(batchHeaderHash [32]byte, batchIDs ...uint32) {
+		logs := make([]types.Log, len(batchIDs))
+		for i, batchID := range batchIDs {
+			logs[i] = types.Log{
+				Address: serviceManagerAddress,
+				Topics:  []gethcommon.Hash{common.BatchConfirmedEventSigHash, batchHeaderHash},
+				Data:    gethcommon.LeftPadBytes(big.NewInt(int64(batchID)).Bytes(), 32),
+			}
+		}
+		ethClient.On("FilterLogs", ethereum.FilterQuery{
+			Addresses: []gethcommon.Address{serviceManagerAddress},
+			Topics:    [][]gethcommon.Hash{{common.BatchConfirmedEventSigHash}, {batchHeaderHash}},
+		}).Return(logs, nil)
+	}
+
+	// batch 1 is confirmed onchain, with one blob confirmed and one finalized
+	confirm(1, 1, [32]byte{1}, false)
+	confirm(2, 1, [32]byte{1}, true)
+	onchainBatches([32]byte{1}, 1)
+	// batch 2 was never confirmed onchain
+	missing := confirm(3, 2, [32]byte{2}, false)
+	onchainBatches([32]byte{2})
+	// the batch header hash of batch 3 was confirmed onchain under another batch ID
+	mismatched := confirm(4, 3, [32]byte{3}, true)
+	onchainBatches([32]byte{3}, 4)
+	// blobs that aren't confirmed are not reconciled
+	_, err = queue.StoreBlob(ctx, &blob, 5)
+	assert.NoError(t, err)
+
+	report, err := reconciler.Reconcile(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.NumBlobs)
+	assert.Equal(t, 3, report.NumBatches)
+	assert.Len(t, report.Discrepancies, 2)
+
+	assert.Equal(t, uint32(2), report.Discrepancies[0].BatchID)
+	assert.Equal(t, [32]byte{2}, report.Discrepancies[0].BatchHeaderHash)
+	assert.Equal(t, []disperser.BlobKey{missing}, report.Discrepancies[0].BlobKeys)
+
+	assert.Equal(t, uint32(3), report.Discrepancies[1].BatchID)
+	assert.Equal(t, [32]byte{3}, report.Discrepancies[1].BatchHeaderHash)
+	assert.Equal(t, []disperser.BlobKey{mismatched}, report.Discrepancies[1].BlobKeys)
+}
@@ -0,0 +1,94 @@
+package batcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Layr-Labs/eigenda/common"
+	"github.com/Layr-Labs/eigenda/disperser"
+)
+
+// ConfirmationInfoPruner runs periodically to drop the blob inclusion proofs and length proofs from the confirmation info
+// of finalized blobs requested longer than the retention period ago. The fields referencing the batch of the blob onchain
+// (batch ID, batch header hash, confirmation block number, etc.) and the commitment are kept. Pruned blobs are moved to
+// Pruned status, so that they aren't fetched again by the following invocations.
+type ConfirmationInfoPruner struct {
+	blobStore        disperser.BlobStore
+	retention        time.Duration
+	loopInterval     time.Duration
+	numBlobsPerFetch int32
+	logger           common.Logger
+}
+
+func NewConfirmationInfoPruner(
+	blobStore disperser.BlobStore,
+	retention time.Duration,
+	loopInterval time.Duration,
+	numBlobsPerFetch int32,
+	logger common.Logger,
+) *ConfirmationInfoPruner {
+	return &ConfirmationInfoPruner{
+		blobStore:        blobStore,
+		retention:        retention,
+		loopInterval:     loopInterval,
+		numBlobsPerFetch: numBlobsPerFetch,
+		logger:           logger,
+	}
+}
+
+func (p *ConfirmationInfoPruner) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(p.loopInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := p.PruneBlobs(ctx); err != nil {
+					p.logger.Error("failed to prune confirmation info", "err", err)
+				}
+			}
+		}
+	}()
+}
+
+// PruneBlobs prunes the confirmation info of the finalized blobs past the retention period and returns the number of
+// blobs pruned. Blobs that fail to be pruned are logged and skipped, so that they're retried on the next invocation.
+// The blobs are fetched in the order they were requested in, so the fetching stops at the first blob within the
+// retention period.
+func (p *ConfirmationInfoPruner) PruneBlobs(ctx context.Context) (int, error) {
+	cutoff := uint64(time.Now().Add(-p.retention).UnixNano())
+
+	numPruned := 0
+	metadatas, exclusiveStartKey, err := p.blobStore.GetBlobMetadataByStatusWithPagination(ctx, disperser.Finalized, p.numBlobsPerFetch, nil)
+	for len(metadatas) > 0 {
+		if err != nil {
+			return numPruned, fmt.Errorf("PruneBlobs: error getting blob metadata: %w", err)
+		}
+		pastCutoff := false
+		for _, m := range metadatas {
+			if m.RequestMetadata != nil && m.RequestMetadata.RequestedAt > cutoff {
+				pastCutoff = true
+				break
+			}
+			if m.ConfirmationInfo == nil {
+				continue
+			}
+			if err := p.blobStore.PruneConfirmationInfo(ctx, m); err != nil {
+				p.logger.Error("PruneBlobs: error pruning confirmation info", "blobKey", m.GetBlobKey().String(), "err", err)
+				continue
+			}
+			numPruned++
+		}
+
+		if pastCutoff || exclusiveStartKey == nil {
+			break
+		}
+		metadatas, exclusiveStartKey, err = p.blobStore.GetBlobMetadataByStatusWithPagination(ctx, disperser.Finalized, p.numBlobsPerFetch, exclusiveStartKey)
+	}
+	p.logger.Info("PruneBlobs: pruned confirmation info of finalized blobs", "numPruned", numPruned, "retention", p.retention)
+	return numPruned, nil
+}
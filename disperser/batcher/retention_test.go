@@ -0,0 +1,90 @@
+package batcher_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigenda/common/logging"
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/Layr-Labs/eigenda/disperser"
+	"github.com/Layr-Labs/eigenda/disperser/batcher"
+	"github.com/Layr-Labs/eigenda/disperser/common/inmem"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirmationInfoPruner(t *testing.T) {
+	queue := inmem.NewBlobStore()
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	pruner := batcher.NewConfirmationInfoPruner(queue, time.Hour, loopInterval, 1, logger)
+
+	blob := makeTestBlob([]*core.SecurityParam{{
+		QuorumID:           0,
+		AdversaryThreshold: 80,
+	}})
+	ctx := context.Background()
+	confirm := func(requestedAt time.Time, finalize bool) disperser.BlobKey {
+		key, err := queue.StoreBlob(ctx, &blob, uint64(requestedAt.UnixNano()))
+		assert.NoError(t, err)
+		metadata, err := queue.GetBlobMetadata(ctx, key)
+		assert.NoError(t, err)
+		_, err = queue.MarkBlobConfirmed(ctx, metadata, &disperser.ConfirmationInfo{
+			BatchHeaderHash:         [32]byte{1, 2, 3},
+			BlobIndex:               1,
+			ReferenceBlockNumber:    132,
+			BatchRoot:               []byte("hello"),
+			BlobInclusionProof:      []byte{1, 2, 3, 4, 5},
+			BlobCommitment:          &core.BlobCommitments{Length: 10},
+			BatchID:                 99,
+			ConfirmationTxnHash:     common.HexToHash("0x123"),
+			ConfirmationBlockNumber: 150,
+		})
+		assert.NoError(t, err)
+		if finalize {
+			assert.NoError(t, queue.MarkBlobFinalized(ctx, key))
+		}
+		return key
+	}
+	oldFinalized := confirm(time.Now().Add(-2*time.Hour), true)
+	recentFinalized := confirm(time.Now(), true)
+	oldConfirmed := confirm(time.Now().Add(-2*time.Hour), false)
+
+	numPruned, err := pruner.PruneBlobs(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, numPruned)
+
+	// the heavy fields of the old finalized blob are cleared, while its references to the batch onchain and its
+	// commitment remain
+	metadata, err := queue.GetBlobMetadata(ctx, oldFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Pruned, metadata.BlobStatus)
+	assert.Nil(t, metadata.ConfirmationInfo.BlobInclusionProof)
+	assert.Equal(t, uint(10), metadata.ConfirmationInfo.BlobCommitment.Length)
+	assert.Nil(t, metadata.ConfirmationInfo.BlobCommitment.LengthProof)
+	assert.Equal(t, uint32(99), metadata.ConfirmationInfo.BatchID)
+	assert.Equal(t, [32]byte{1, 2, 3}, metadata.ConfirmationInfo.BatchHeaderHash)
+	assert.Equal(t, uint32(1), metadata.ConfirmationInfo.BlobIndex)
+	assert.Equal(t, uint32(150), metadata.ConfirmationInfo.ConfirmationBlockNumber)
+	assert.Equal(t, common.HexToHash("0x123"), metadata.ConfirmationInfo.ConfirmationTxnHash)
+
+	// blobs within the retention period or not yet finalized are kept in full
+	for _, key := range []disperser.BlobKey{recentFinalized, oldConfirmed} {
+		metadata, err := queue.GetBlobMetadata(ctx, key)
+		assert.NoError(t, err)
+		assert.NotEqual(t, disperser.Pruned, metadata.BlobStatus)
+		assert.Equal(t, []byte{1, 2, 3, 4, 5}, metadata.ConfirmationInfo.BlobInclusionProof)
+		assert.Equal(t, uint(10), metadata.ConfirmationInfo.BlobCommitment.Length)
+	}
+
+	// pruned blobs are not pruned again
+	numPruned, err = pruner.PruneBlobs(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, numPruned)
+
+	// only finalized blobs can be pruned
+	metadata, err = queue.GetBlobMetadata(ctx, oldConfirmed)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, queue.PruneConfirmationInfo(ctx, metadata), disperser.ErrBlobNotFinalized)
+}
@@ -62,12 +62,17 @@ type txnManager struct {
 	receiptChan        chan *ReceiptOrErr
 	queueSize          int
 	txnRefreshInterval time.Duration
-	metrics            *TxnManagerMetrics
+	// minGasTipCap is the floor of the gas tip cap of the sent transactions. nil if there is no floor.
+	minGasTipCap *big.Int
+	metrics      *TxnManagerMetrics
 }
 
 var _ TxnManager = (*txnManager)(nil)
 
-func NewTxnManager(ethClient common.EthClient, queueSize int, txnRefreshInterval time.Duration, logger common.Logger, metrics *TxnManagerMetrics) TxnManager {
+// NewTxnManager returns a transaction manager. If minGasTipCap is set, the gas tip cap of the sent transactions is
+// raised to at least minGasTipCap. The transactions are sent from the account of the eth client and signed by it, so
+// that a client made with NewTxnSignerEthClient signs them with its TxnSigner.
+func NewTxnManager(ethClient common.EthClient, queueSize int, txnRefreshInterval time.Duration, minGasTipCap *big.Int, logger common.Logger, metrics *TxnManagerMetrics) TxnManager {
 	return &txnManager{
 		ethClient:          ethClient,
 		requestChan:        make(chan *TxnRequest, queueSize),
@@ -75,6 +80,7 @@ func NewTxnManager(ethClient common.EthClient, queueSize int, txnRefreshInterval
 		receiptChan:        make(chan *ReceiptOrErr, queueSize),
 		queueSize:          queueSize,
 		txnRefreshInterval: txnRefreshInterval,
+		minGasTipCap:       minGasTipCap,
 		metrics:            metrics,
 	}
 }
@@ -133,10 +139,11 @@ func (t *txnManager) ProcessTransaction(ctx context.Context, req *TxnRequest) er
 	if err != nil {
 		return fmt.Errorf("failed to get latest gas caps: %w", err)
 	}
+	gasTipCap, gasFeeCap = t.applyMinGasTipCap(gasTipCap, gasFeeCap)
 
 	txn, err := t.ethClient.UpdateGas(ctx, req.Tx, req.Value, gasTipCap, gasFeeCap)
 	if err != nil {
-		return fmt.Errorf("failed to update gas price: %w", err)
+		return fmt.Errorf("failed to update gas price and sign txn (%s): %w", req.Tag, err)
 	}
 	err = t.ethClient.SendTransaction(ctx, txn)
 	if err != nil {
@@ -223,6 +230,7 @@ func (t *txnManager) speedUpTxn(ctx context.Context, tx *types.Transaction, tag
 	if err != nil {
 		return nil, err
 	}
+	currentGasTipCap, currentGasFeeCap = t.applyMinGasTipCap(currentGasTipCap, currentGasFeeCap)
 	increasedGasTipCap := increaseGasPrice(prevGasTipCap)
 	increasedGasFeeCap := increaseGasPrice(prevGasFeeCap)
 	// make sure increased gas prices are not lower than current gas prices
@@ -242,6 +250,17 @@ func (t *txnManager) speedUpTxn(ctx context.Context, tx *types.Transaction, tag
 	return t.ethClient.UpdateGas(ctx, tx, tx.Value(), newGasTipCap, newGasFeeCap)
 }
 
+// applyMinGasTipCap raises the gas tip cap to minGasTipCap if it's below it. The gas fee cap is raised by the same
+// amount so that the room left for the base fee is unchanged.
+func (t *txnManager) applyMinGasTipCap(gasTipCap, gasFeeCap *big.Int) (*big.Int, *big.Int) {
+	if t.minGasTipCap == nil || gasTipCap.Cmp(t.minGasTipCap) >= 0 {
+		return gasTipCap, gasFeeCap
+	}
+	t.logger.Debug("[TxnManager] raising gas tip cap to the minimum", "gasTipCap", gasTipCap, "minGasTipCap", t.minGasTipCap)
+	increase := new(big.Int).Sub(t.minGasTipCap, gasTipCap)
+	return new(big.Int).Set(t.minGasTipCap), new(big.Int).Add(gasFeeCap, increase)
+}
+
 // increaseGasPrice increases the gas price by specified percentage.
 // i.e. gasPrice + ((gasPrice * gasPricePercentageMultiplier + 99) / 100)
 func increaseGasPrice(gasPrice *big.Int) *big.Int {
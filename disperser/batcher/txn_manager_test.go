@@ -4,15 +4,20 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/Layr-Labs/eigenda/common/logging"
 	"github.com/Layr-Labs/eigenda/common/mock"
 	"github.com/Layr-Labs/eigenda/disperser/batcher"
+	batchermock "github.com/Layr-Labs/eigenda/disperser/batcher/mock"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
 )
 
 func TestProcessTransaction(t *testing.T) {
@@ -20,7 +25,7 @@ func TestProcessTransaction(t *testing.T) {
 	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
 	assert.NoError(t, err)
 	metrics := batcher.NewMetrics("9100", logger)
-	txnManager := batcher.NewTxnManager(ethClient, 5, 48*time.Second, logger, metrics.TxnManagerMetrics)
+	txnManager := batcher.NewTxnManager(ethClient, 5, 48*time.Second, nil, logger, metrics.TxnManagerMetrics)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
 	defer cancel()
 	txnManager.Start(ctx)
@@ -70,7 +75,7 @@ func TestReplaceGasFee(t *testing.T) {
 	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
 	assert.NoError(t, err)
 	metrics := batcher.NewMetrics("9100", logger)
-	txnManager := batcher.NewTxnManager(ethClient, 5, 48*time.Second, logger, metrics.TxnManagerMetrics)
+	txnManager := batcher.NewTxnManager(ethClient, 5, 48*time.Second, nil, logger, metrics.TxnManagerMetrics)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
 	defer cancel()
 	txnManager.Start(ctx)
@@ -97,12 +102,140 @@ func TestReplaceGasFee(t *testing.T) {
 	ethClient.AssertNumberOfCalls(t, "EnsureAnyTransactionEvaled", 2)
 }
 
+// estimateGasRecordingEthClient records the accounts gas is estimated for
+type estimateGasRecordingEthClient struct {
+	*mock.MockEthClient
+
+	mu   sync.Mutex
+	from []common.Address
+}
+
+func (c *estimateGasRecordingEthClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	c.mu.Lock()
+	c.from = append(c.from, msg.From)
+	c.mu.Unlock()
+	return c.MockEthClient.EstimateGas(ctx, msg)
+}
+
+func TestTxnSigner(t *testing.T) {
+	ethClient := &estimateGasRecordingEthClient{MockEthClient: &mock.MockEthClient{}}
+	signer := &batchermock.MockTxnSigner{}
+	signerAddress := common.HexToAddress("0x2")
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	metrics := batcher.NewMetrics("9100", logger)
+	txnManager := batcher.NewTxnManager(batcher.NewTxnSignerEthClient(ethClient, signer), 5, 48*time.Second, nil, logger, metrics.TxnManagerMetrics)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+	txnManager.Start(ctx)
+	txn := types.NewTransaction(7, common.HexToAddress("0x1"), big.NewInt(0), 100000, big.NewInt(1e9), []byte{1, 2, 3})
+	signedTxn := types.NewTransaction(7, common.HexToAddress("0x1"), big.NewInt(0), 100000, big.NewInt(2e9), []byte{1, 2, 3})
+	ethClient.On("GetLatestGasCaps").Return(big.NewInt(1e9), big.NewInt(1e9), nil)
+	ethClient.On("EstimateGas").Return(uint64(100000), nil)
+	ethClient.On("ChainID").Return(big.NewInt(1), nil)
+	ethClient.On("SendTransaction").Return(nil)
+	signer.On("Address").Return(signerAddress)
+	signer.On("SignTx", testifymock.Anything).Return(signedTxn, nil)
+	// the first transaction is not mined within the timeout, and is replaced
+	ethClient.On("EnsureAnyTransactionEvaled").Return(nil, context.DeadlineExceeded).Once()
+	ethClient.On("EnsureAnyTransactionEvaled").Return(&types.Receipt{
+		BlockNumber: new(big.Int).SetUint64(1),
+	}, nil)
+
+	requests := []*batcher.TxnRequest{
+		batcher.NewTxnRequest(txn, "confirmBatch", big.NewInt(0), nil),
+		batcher.NewTxnRequest(txn, "confirmBatch", big.NewInt(0), nil),
+	}
+	for _, req := range requests {
+		err = txnManager.ProcessTransaction(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, signedTxn, req.Tx)
+		receiptOrErr := <-txnManager.ReceiptChan()
+		assert.NoError(t, receiptOrErr.Err)
+	}
+	// each transaction is signed when it's sent, and the replacement transaction as well
+	signer.AssertNumberOfCalls(t, "SignTx", 3)
+	ethClient.AssertNumberOfCalls(t, "SendTransaction", 3)
+	// the signed transactions keep the nonce, destination and data of the request, with the gas limit estimated for the
+	// account of the signer
+	for _, call := range signer.Calls {
+		if call.Method != "SignTx" {
+			continue
+		}
+		unsigned := call.Arguments.Get(0).(*types.Transaction)
+		assert.Equal(t, uint64(7), unsigned.Nonce())
+		assert.Equal(t, txn.To(), unsigned.To())
+		assert.Equal(t, txn.Data(), unsigned.Data())
+		assert.Equal(t, uint64(120000), unsigned.Gas())
+		assert.Equal(t, big.NewInt(1), unsigned.ChainId())
+	}
+	ethClient.mu.Lock()
+	assert.Equal(t, []common.Address{signerAddress, signerAddress, signerAddress}, ethClient.from)
+	ethClient.mu.Unlock()
+
+	// the transaction isn't sent if it fails to be signed
+	signer.ExpectedCalls = nil
+	signer.On("Address").Return(signerAddress)
+	signer.On("SignTx", testifymock.Anything).Return(nil, fmt.Errorf("signer unavailable"))
+	err = txnManager.ProcessTransaction(ctx, batcher.NewTxnRequest(txn, "confirmBatch", big.NewInt(0), nil))
+	assert.ErrorContains(t, err, "signer unavailable")
+	ethClient.AssertNumberOfCalls(t, "SendTransaction", 3)
+}
+
+func TestTxnSignerEthClientTransactOpts(t *testing.T) {
+	signer := &batchermock.MockTxnSigner{}
+	signerAddress := common.HexToAddress("0x2")
+	txn := types.NewTransaction(0, common.HexToAddress("0x1"), big.NewInt(0), 100000, big.NewInt(1e9), []byte{})
+	signedTxn := types.NewTransaction(0, common.HexToAddress("0x1"), big.NewInt(0), 100000, big.NewInt(2e9), []byte{})
+	signer.On("Address").Return(signerAddress)
+	signer.On("SignTx", txn).Return(signedTxn, nil)
+	ethClient := batcher.NewTxnSignerEthClient(&mock.MockEthClient{}, signer)
+
+	// transactors built on the client send from the account of the signer and sign with it
+	assert.Equal(t, signerAddress, ethClient.GetAccountAddress())
+	opts, err := ethClient.GetNoSendTransactOpts()
+	assert.NoError(t, err)
+	assert.True(t, opts.NoSend)
+	assert.Equal(t, signerAddress, opts.From)
+	signed, err := opts.Signer(signerAddress, txn)
+	assert.NoError(t, err)
+	assert.Equal(t, signedTxn, signed)
+	_, err = opts.Signer(common.HexToAddress("0x3"), txn)
+	assert.Error(t, err)
+}
+
+func TestLocalTxnSigner(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	chainID := big.NewInt(17000)
+	signer := batcher.NewLocalTxnSigner(privateKey, chainID)
+	assert.Equal(t, address, signer.Address())
+
+	txn := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     3,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(2e9),
+		Gas:       100000,
+		To:        &common.Address{1},
+		Data:      []byte{1, 2, 3},
+	})
+	signed, err := signer.SignTx(context.Background(), txn)
+	assert.NoError(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	assert.NoError(t, err)
+	assert.Equal(t, address, sender)
+	assert.Equal(t, txn.Nonce(), signed.Nonce())
+	assert.Equal(t, txn.Data(), signed.Data())
+}
+
 func TestTransactionFailure(t *testing.T) {
 	ethClient := &mock.MockEthClient{}
 	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
 	assert.NoError(t, err)
 	metrics := batcher.NewMetrics("9100", logger)
-	txnManager := batcher.NewTxnManager(ethClient, 5, 48*time.Second, logger, metrics.TxnManagerMetrics)
+	txnManager := batcher.NewTxnManager(ethClient, 5, 48*time.Second, nil, logger, metrics.TxnManagerMetrics)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
 	defer cancel()
 	txnManager.Start(ctx)
@@ -135,7 +268,7 @@ func TestSendTransactionRetry(t *testing.T) {
 	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
 	assert.NoError(t, err)
 	metrics := batcher.NewMetrics("9100", logger)
-	txnManager := batcher.NewTxnManager(ethClient, 5, 48*time.Second, logger, metrics.TxnManagerMetrics)
+	txnManager := batcher.NewTxnManager(ethClient, 5, 48*time.Second, nil, logger, metrics.TxnManagerMetrics)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
 	defer cancel()
 	txnManager.Start(ctx)
@@ -172,7 +305,7 @@ func TestSendTransactionRetryFailure(t *testing.T) {
 	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
 	assert.NoError(t, err)
 	metrics := batcher.NewMetrics("9100", logger)
-	txnManager := batcher.NewTxnManager(ethClient, 5, 48*time.Second, logger, metrics.TxnManagerMetrics)
+	txnManager := batcher.NewTxnManager(ethClient, 5, 48*time.Second, nil, logger, metrics.TxnManagerMetrics)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
 	defer cancel()
 	txnManager.Start(ctx)
@@ -201,3 +334,60 @@ func TestSendTransactionRetryFailure(t *testing.T) {
 	ethClient.AssertNumberOfCalls(t, "SendTransaction", 5)
 	ethClient.AssertNumberOfCalls(t, "EnsureAnyTransactionEvaled", 4)
 }
+
+// gasCapsRecordingEthClient records the gas caps transactions are updated with
+type gasCapsRecordingEthClient struct {
+	*mock.MockEthClient
+
+	mu         sync.Mutex
+	gasTipCaps []*big.Int
+	gasFeeCaps []*big.Int
+}
+
+func (c *gasCapsRecordingEthClient) UpdateGas(ctx context.Context, tx *types.Transaction, value, gasTipCap, gasFeeCap *big.Int) (*types.Transaction, error) {
+	c.mu.Lock()
+	c.gasTipCaps = append(c.gasTipCaps, gasTipCap)
+	c.gasFeeCaps = append(c.gasFeeCaps, gasFeeCap)
+	c.mu.Unlock()
+	return c.MockEthClient.UpdateGas(ctx, tx, value, gasTipCap, gasFeeCap)
+}
+
+func TestMinGasTipCap(t *testing.T) {
+	ethClient := &gasCapsRecordingEthClient{MockEthClient: &mock.MockEthClient{}}
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	metrics := batcher.NewMetrics("9100", logger)
+	txnManager := batcher.NewTxnManager(ethClient, 5, 48*time.Second, big.NewInt(2e9), logger, metrics.TxnManagerMetrics)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+	txnManager.Start(ctx)
+	txn := types.NewTransaction(0, common.HexToAddress("0x1"), big.NewInt(1e18), 100000, big.NewInt(1e9), []byte{})
+	// the suggested tip is zero
+	ethClient.On("GetLatestGasCaps").Return(big.NewInt(0), big.NewInt(1e9), nil)
+	ethClient.On("UpdateGas").Return(txn, nil)
+	ethClient.On("SendTransaction").Return(nil)
+	// assume that the transaction is not mined within the timeout so that it's sped up
+	ethClient.On("EnsureAnyTransactionEvaled").Return(nil, context.DeadlineExceeded).Once()
+	ethClient.On("EnsureAnyTransactionEvaled").Return(&types.Receipt{
+		BlockNumber: new(big.Int).SetUint64(1),
+	}, nil)
+
+	err = txnManager.ProcessTransaction(ctx, &batcher.TxnRequest{
+		Tx:    txn,
+		Tag:   "test transaction",
+		Value: nil,
+	})
+	assert.NoError(t, err)
+	res := <-txnManager.ReceiptChan()
+	assert.NoError(t, res.Err)
+
+	ethClient.mu.Lock()
+	defer ethClient.mu.Unlock()
+	// the tip of both the original and the replacement transaction is raised to the minimum, and the fee cap is raised
+	// by the same amount
+	assert.Len(t, ethClient.gasTipCaps, 2)
+	for i := range ethClient.gasTipCaps {
+		assert.Equal(t, big.NewInt(2e9), ethClient.gasTipCaps[i])
+		assert.Equal(t, big.NewInt(3e9), ethClient.gasFeeCaps[i])
+	}
+}
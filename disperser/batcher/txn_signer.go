@@ -0,0 +1,215 @@
+package batcher
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/Layr-Labs/eigenda/common"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// TxnSigner signs the transactions of the batcher, such as the confirmBatch transactions and their replacements. It
+// allows the signing key to be held outside of the batcher, e.g. in AWS KMS behind a remote signer.
+type TxnSigner interface {
+	// Address returns the account the transactions are sent from
+	Address() gcommon.Address
+	SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error)
+}
+
+type localTxnSigner struct {
+	privateKey *ecdsa.PrivateKey
+	address    gcommon.Address
+	chainID    *big.Int
+}
+
+var _ TxnSigner = (*localTxnSigner)(nil)
+
+// NewLocalTxnSigner returns a TxnSigner that signs the transactions for chainID with a private key held by the batcher
+func NewLocalTxnSigner(privateKey *ecdsa.PrivateKey, chainID *big.Int) TxnSigner {
+	return &localTxnSigner{
+		privateKey: privateKey,
+		address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		chainID:    chainID,
+	}
+}
+
+func (s *localTxnSigner) Address() gcommon.Address {
+	return s.address
+}
+
+func (s *localTxnSigner) SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(s.chainID), s.privateKey)
+}
+
+type remoteTxnSigner struct {
+	client  *rpc.Client
+	address gcommon.Address
+	chainID *big.Int
+}
+
+var _ TxnSigner = (*remoteTxnSigner)(nil)
+
+// NewRemoteTxnSigner returns a TxnSigner that signs the transactions of the address with the eth_signTransaction
+// method of the remote signer at url, e.g. a Web3Signer instance holding the key in AWS KMS. The signed transactions
+// are checked to be signed by the address for chainID.
+func NewRemoteTxnSigner(ctx context.Context, url string, address gcommon.Address, chainID *big.Int) (TxnSigner, error) {
+	if address == (gcommon.Address{}) {
+		return nil, errors.New("the address of the remote signer must be set")
+	}
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial the remote signer %s: %w", url, err)
+	}
+	return &remoteTxnSigner{
+		client:  client,
+		address: address,
+		chainID: chainID,
+	}, nil
+}
+
+type signTxArgs struct {
+	From                 gcommon.Address  `json:"from"`
+	To                   *gcommon.Address `json:"to,omitempty"`
+	Gas                  hexutil.Uint64   `json:"gas"`
+	GasPrice             *hexutil.Big     `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big     `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big     `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *hexutil.Big     `json:"value"`
+	Nonce                hexutil.Uint64   `json:"nonce"`
+	Data                 hexutil.Bytes    `json:"data"`
+}
+
+func (s *remoteTxnSigner) Address() gcommon.Address {
+	return s.address
+}
+
+func (s *remoteTxnSigner) SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	args := signTxArgs{
+		From:  s.address,
+		To:    tx.To(),
+		Gas:   hexutil.Uint64(tx.Gas()),
+		Value: (*hexutil.Big)(new(big.Int)),
+		Nonce: hexutil.Uint64(tx.Nonce()),
+		Data:  tx.Data(),
+	}
+	if tx.Value() != nil {
+		args.Value = (*hexutil.Big)(tx.Value())
+	}
+	if tx.Type() == types.LegacyTxType {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	} else {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	}
+
+	var raw hexutil.Bytes
+	if err := s.client.CallContext(ctx, &raw, "eth_signTransaction", args); err != nil {
+		return nil, fmt.Errorf("failed to sign the transaction with the remote signer: %w", err)
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("failed to decode the transaction signed by the remote signer: %w", err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(s.chainID), signed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature from the remote signer: %w", err)
+	}
+	if sender != s.address {
+		return nil, fmt.Errorf("the remote signer signed the transaction for %s instead of %s", sender.Hex(), s.address.Hex())
+	}
+	return signed, nil
+}
+
+// txnSignerEthClient is an eth client whose transactions are sent from the account of a TxnSigner and signed by it,
+// instead of with the local key of the eth client
+type txnSignerEthClient struct {
+	common.EthClient
+	signer TxnSigner
+}
+
+var _ common.EthClient = (*txnSignerEthClient)(nil)
+
+// NewTxnSignerEthClient returns an eth client that sends its transactions from the account of the signer. The
+// transaction opts, gas estimation and signing of the returned client all use the signer, so that transactors built on
+// it don't need the private key of the account.
+func NewTxnSignerEthClient(ethClient common.EthClient, signer TxnSigner) common.EthClient {
+	return &txnSignerEthClient{
+		EthClient: ethClient,
+		signer:    signer,
+	}
+}
+
+func (c *txnSignerEthClient) GetAccountAddress() gcommon.Address {
+	return c.signer.Address()
+}
+
+func (c *txnSignerEthClient) GetNoSendTransactOpts() (*bind.TransactOpts, error) {
+	address := c.signer.Address()
+	return &bind.TransactOpts{
+		From: address,
+		Signer: func(from gcommon.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if from != address {
+				return nil, bind.ErrNotAuthorized
+			}
+			return c.signer.SignTx(context.Background(), tx)
+		},
+		Context: context.Background(),
+		NoSend:  true,
+	}, nil
+}
+
+func (c *txnSignerEthClient) UpdateGas(ctx context.Context, tx *types.Transaction, value, gasTipCap, gasFeeCap *big.Int) (*types.Transaction, error) {
+	gasLimit, err := c.EstimateGas(ctx, ethereum.CallMsg{
+		From:      c.signer.Address(),
+		To:        tx.To(),
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Value:     value,
+		Data:      tx.Data(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := c.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	return c.signer.SignTx(ctx, types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     tx.Nonce(),
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       6 * gasLimit / 5, // add 20% buffer to gas limit, as the eth client does
+		To:        tx.To(),
+		Value:     value,
+		Data:      tx.Data(),
+	}))
+}
+
+func (c *txnSignerEthClient) EstimateGasPriceAndLimitAndSendTx(ctx context.Context, tx *types.Transaction, tag string, value *big.Int) (*types.Receipt, error) {
+	gasTipCap, gasFeeCap, err := c.GetLatestGasCaps(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("EstimateGasPriceAndLimitAndSendTx: failed to get gas price for txn (%s): %w", tag, err)
+	}
+	tx, err = c.UpdateGas(ctx, tx, value, gasTipCap, gasFeeCap)
+	if err != nil {
+		return nil, fmt.Errorf("EstimateGasPriceAndLimitAndSendTx: failed to update gas for txn (%s): %w", tag, err)
+	}
+	if err = c.SendTransaction(ctx, tx); err != nil {
+		return nil, fmt.Errorf("EstimateGasPriceAndLimitAndSendTx: failed to send txn (%s): %w", tag, err)
+	}
+	return c.EnsureTransactionEvaled(ctx, tx, tag)
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/Layr-Labs/eigenda/common/aws"
 	"github.com/Layr-Labs/eigenda/common/geth"
 	"github.com/Layr-Labs/eigenda/common/logging"
 	"github.com/Layr-Labs/eigenda/common/ratelimit"
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/Layr-Labs/eigenda/core/auth"
 	"github.com/Layr-Labs/eigenda/disperser"
 	"github.com/Layr-Labs/eigenda/disperser/apiserver"
 	"github.com/Layr-Labs/eigenda/disperser/cmd/apiserver/flags"
@@ -41,10 +45,57 @@ func NewConfig(ctx *cli.Context) (Config, error) {
 		return Config{}, err
 	}
 
+	allowedQuorumIDs := make([]core.QuorumID, 0, len(ctx.GlobalIntSlice(flags.AllowedQuorumIDsFlag.Name)))
+	for _, quorumID := range ctx.GlobalIntSlice(flags.AllowedQuorumIDsFlag.Name) {
+		if quorumID < 0 || quorumID > core.MaxQuorumID {
+			return Config{}, fmt.Errorf("invalid allowed quorum ID %d: must be in range [0, %d]", quorumID, core.MaxQuorumID)
+		}
+		allowedQuorumIDs = append(allowedQuorumIDs, core.QuorumID(quorumID))
+	}
+
+	duplicateBlobPolicy, err := disperser.ParseDuplicateBlobPolicy(ctx.GlobalString(flags.DuplicateBlobPolicyFlag.Name))
+	if err != nil {
+		return Config{}, err
+	}
+
+	var allowedAccounts *auth.AccountAllowlist
+	allowedAccountsFile := ctx.GlobalString(flags.AllowedAccountsFileFlag.Name)
+	if accountIDs := ctx.GlobalStringSlice(flags.AllowedAccountsFlag.Name); len(accountIDs) > 0 || allowedAccountsFile != "" {
+		allowedAccounts = auth.NewAccountAllowlist(accountIDs...)
+	}
+	if allowedAccountsFile != "" {
+		if err := allowedAccounts.LoadFile(allowedAccountsFile); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var receiptSigner *disperser.ReceiptSigner
+	if key := ctx.GlobalString(flags.ReceiptSigningKeyFlag.Name); key != "" {
+		receiptSigner, err = disperser.NewReceiptSigner(key)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+
 	config := Config{
 		AwsClientConfig: aws.ReadClientConfig(ctx, flags.FlagPrefix),
 		ServerConfig: disperser.ServerConfig{
-			GrpcPort: ctx.GlobalString(flags.GrpcPortFlag.Name),
+			GrpcPort:          ctx.GlobalString(flags.GrpcPortFlag.Name),
+			AllowedQuorumIDs:  allowedQuorumIDs,
+			MaxQuorumsPerBlob: ctx.GlobalUint(flags.MaxQuorumsPerBlobFlag.Name),
+
+			BatchInterval:                 ctx.GlobalDuration(flags.BatchIntervalFlag.Name),
+			BlobsPerBatch:                 ctx.GlobalUint(flags.BlobsPerBatchFlag.Name),
+			DefaultConfirmationLatency:    ctx.GlobalDuration(flags.DefaultConfirmationLatencyFlag.Name),
+			QueueRefreshInterval:          ctx.GlobalDuration(flags.QueueRefreshIntervalFlag.Name),
+			DuplicateBlobPolicy:           duplicateBlobPolicy,
+			RequireAuthentication:         ctx.GlobalBool(flags.RequireAuthenticationFlag.Name),
+			AllowedAccounts:               allowedAccounts,
+			AllowedAccountsFile:           allowedAccountsFile,
+			AllowedAccountsReloadInterval: ctx.GlobalDuration(flags.AllowedAccountsReloadIntervalFlag.Name),
+			ReceiptSigner:                 receiptSigner,
+			MaxWatchStreamsPerClient:      ctx.GlobalUint(flags.MaxWatchStreamsPerClientFlag.Name),
+			MaxWatchDuration:              ctx.GlobalDuration(flags.MaxWatchDurationFlag.Name),
 		},
 		BlobstoreConfig: blobstore.Config{
 			BucketName: ctx.GlobalString(flags.S3BucketNameFlag.Name),
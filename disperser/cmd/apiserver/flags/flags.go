@@ -1,6 +1,8 @@
 package flags
 
 import (
+	"time"
+
 	"github.com/Layr-Labs/eigenda/common"
 	"github.com/Layr-Labs/eigenda/common/aws"
 	"github.com/Layr-Labs/eigenda/common/geth"
@@ -72,6 +74,98 @@ var (
 		Value:  "",
 		EnvVar: common.PrefixEnvVar(envVarPrefix, "RATE_BUCKET_TABLE_NAME"),
 	}
+	AllowedQuorumIDsFlag = cli.IntSliceFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "allowed-quorum-ids"),
+		Usage:    "quorum IDs that clients may request dispersal to. If not provided, all onchain quorums are allowed",
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "ALLOWED_QUORUM_IDS"),
+		Required: false,
+	}
+	MaxQuorumsPerBlobFlag = cli.UintFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "max-quorums-per-blob"),
+		Usage:    "maximum number of quorums a blob may be dispersed to. 0 means no limit",
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "MAX_QUORUMS_PER_BLOB"),
+		Required: false,
+	}
+	BatchIntervalFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "batch-interval"),
+		Usage:    "expected time between batches, used to estimate the time to confirmation of queued blobs",
+		Value:    5 * time.Minute,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "BATCH_INTERVAL"),
+		Required: false,
+	}
+	BlobsPerBatchFlag = cli.UintFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "blobs-per-batch"),
+		Usage:    "expected number of blobs in each batch, used to estimate the time to confirmation of queued blobs",
+		Value:    100,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "BLOBS_PER_BATCH"),
+		Required: false,
+	}
+	DefaultConfirmationLatencyFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "default-confirmation-latency"),
+		Usage:    "confirmation latency assumed by the time to confirmation estimate until a confirmed blob has been observed",
+		Value:    10 * time.Minute,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "DEFAULT_CONFIRMATION_LATENCY"),
+		Required: false,
+	}
+	QueueRefreshIntervalFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "queue-refresh-interval"),
+		Usage:    "how long the queue of blobs waiting for confirmation is cached by the time to confirmation estimate",
+		Value:    10 * time.Second,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "QUEUE_REFRESH_INTERVAL"),
+		Required: false,
+	}
+	DuplicateBlobPolicyFlag = cli.StringFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "duplicate-blob-policy"),
+		Usage:    "how requests duplicating the content and quorums of an earlier blob of the same account or origin are handled: allow, dedup or reject",
+		Value:    "allow",
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "DUPLICATE_BLOB_POLICY"),
+		Required: false,
+	}
+	RequireAuthenticationFlag = cli.BoolFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "require-authentication"),
+		Usage:    "reject unauthenticated DisperseBlob requests, so that blobs can only be dispersed with DisperseBlobAuthenticated",
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "REQUIRE_AUTHENTICATION"),
+		Required: false,
+	}
+	AllowedAccountsFlag = cli.StringSliceFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "allowed-accounts"),
+		Usage:    "account IDs (hex-encoded public keys) allowed to disperse authenticated blobs. If not provided, all accounts are allowed",
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "ALLOWED_ACCOUNTS"),
+		Required: false,
+	}
+	AllowedAccountsFileFlag = cli.StringFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "allowed-accounts-file"),
+		Usage:    "file listing the account IDs allowed to disperse authenticated blobs, one per line, in addition to the allowed-accounts. The file is reloaded when it changes",
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "ALLOWED_ACCOUNTS_FILE"),
+		Required: false,
+	}
+	AllowedAccountsReloadIntervalFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "allowed-accounts-reload-interval"),
+		Usage:    "how often the allowed-accounts-file is checked for changes",
+		Value:    time.Minute,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "ALLOWED_ACCOUNTS_RELOAD_INTERVAL"),
+		Required: false,
+	}
+	ReceiptSigningKeyFlag = cli.StringFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "receipt-signing-key"),
+		Usage:    "hex-encoded ECDSA private key the disperser signs the receipts of the accepted blobs with. If not provided, no receipts are returned",
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "RECEIPT_SIGNING_KEY"),
+		Required: false,
+	}
+	MaxWatchStreamsPerClientFlag = cli.UintFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "max-watch-streams-per-client"),
+		Usage:    "maximum number of WatchBlobStatus streams a client may have open at once. 0 means no limit",
+		Value:    16,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "MAX_WATCH_STREAMS_PER_CLIENT"),
+		Required: false,
+	}
+	MaxWatchDurationFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "max-watch-duration"),
+		Usage:    "maximum time a WatchBlobStatus stream is kept open, after which the client has to watch the blob again. 0 means no limit",
+		Value:    30 * time.Minute,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "MAX_WATCH_DURATION"),
+		Required: false,
+	}
 	BucketStoreSize = cli.UintFlag{
 		Name:     common.PrefixFlag(FlagPrefix, "rate-bucket-store-size"),
 		Usage:    "size (max number of entries) of the local store to use for rate limiting buckets",
@@ -95,6 +189,20 @@ var optionalFlags = []cli.Flag{
 	EnableMetrics,
 	EnableRatelimiter,
 	BucketStoreSize,
+	AllowedQuorumIDsFlag,
+	MaxQuorumsPerBlobFlag,
+	BatchIntervalFlag,
+	BlobsPerBatchFlag,
+	DefaultConfirmationLatencyFlag,
+	QueueRefreshIntervalFlag,
+	DuplicateBlobPolicyFlag,
+	RequireAuthenticationFlag,
+	AllowedAccountsFlag,
+	AllowedAccountsFileFlag,
+	AllowedAccountsReloadIntervalFlag,
+	ReceiptSigningKeyFlag,
+	MaxWatchStreamsPerClientFlag,
+	MaxWatchDurationFlag,
 }
 
 // Flags contains the list of configuration options available to the binary.
@@ -1,14 +1,19 @@
 package main
 
 import (
+	"fmt"
+
+	"github.com/Layr-Labs/eigenda/common"
 	"github.com/Layr-Labs/eigenda/common/aws"
 	"github.com/Layr-Labs/eigenda/common/geth"
 	"github.com/Layr-Labs/eigenda/common/logging"
+	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/core/encoding"
 	"github.com/Layr-Labs/eigenda/disperser/batcher"
 	"github.com/Layr-Labs/eigenda/disperser/cmd/batcher/flags"
 	"github.com/Layr-Labs/eigenda/disperser/common/blobstore"
 	"github.com/Layr-Labs/eigenda/indexer"
+	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/urfave/cli"
 )
 
@@ -27,11 +32,24 @@ type Config struct {
 
 	IndexerDataDir string
 
+	OperatorConnectionPoolConfig common.ConnectionPoolConfig
+
 	BLSOperatorStateRetrieverAddr string
 	EigenDAServiceManagerAddr     string
 }
 
-func NewConfig(ctx *cli.Context) Config {
+func NewConfig(ctx *cli.Context) (Config, error) {
+	criticalQuorums, err := parseQuorumIDs(ctx.GlobalIntSlice(flags.CriticalQuorumIDsFlag.Name))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid critical quorum IDs: %w", err)
+	}
+	criticalQuorumThreshold := ctx.GlobalUint(flags.CriticalQuorumThresholdFlag.Name)
+	if criticalQuorumThreshold > 100 {
+		return Config{}, fmt.Errorf("critical quorum threshold %d must be at most 100", criticalQuorumThreshold)
+	}
+	if len(criticalQuorums) > 0 && criticalQuorumThreshold == 0 {
+		return Config{}, fmt.Errorf("critical quorum threshold must be set along with critical quorum IDs")
+	}
 
 	config := Config{
 		BlobstoreConfig: blobstore.Config{
@@ -43,27 +61,71 @@ func NewConfig(ctx *cli.Context) Config {
 		EncoderConfig:   encoding.ReadCLIConfig(ctx),
 		LoggerConfig:    logging.ReadCLIConfig(ctx, flags.FlagPrefix),
 		BatcherConfig: batcher.Config{
-			PullInterval:             ctx.GlobalDuration(flags.PullIntervalFlag.Name),
-			FinalizerInterval:        ctx.GlobalDuration(flags.FinalizerIntervalFlag.Name),
-			FinalizerPoolSize:        ctx.GlobalInt(flags.FinalizerPoolSizeFlag.Name),
-			EncoderSocket:            ctx.GlobalString(flags.EncoderSocket.Name),
-			NumConnections:           ctx.GlobalInt(flags.NumConnectionsFlag.Name),
-			EncodingRequestQueueSize: ctx.GlobalInt(flags.EncodingRequestQueueSizeFlag.Name),
-			BatchSizeMBLimit:         ctx.GlobalUint(flags.BatchSizeLimitFlag.Name),
-			SRSOrder:                 ctx.GlobalInt(flags.SRSOrderFlag.Name),
-			MaxNumRetriesPerBlob:     ctx.GlobalUint(flags.MaxNumRetriesPerBlobFlag.Name),
-			TargetNumChunks:          ctx.GlobalUint(flags.TargetNumChunksFlag.Name),
-			MaxBlobsToFetchFromStore: ctx.GlobalInt(flags.MaxBlobsToFetchFromStoreFlag.Name),
+			PullInterval:            ctx.GlobalDuration(flags.PullIntervalFlag.Name),
+			FinalizerInterval:       ctx.GlobalDuration(flags.FinalizerIntervalFlag.Name),
+			FinalizerPoolSize:       ctx.GlobalInt(flags.FinalizerPoolSizeFlag.Name),
+			FinalizerWriteBatchSize: ctx.GlobalInt(flags.FinalizerWriteBatchSizeFlag.Name),
+			FinalizerRetryConfig: batcher.FinalizerRetryConfig{
+				MaxRetries: ctx.GlobalInt(flags.FinalizerMaxRetriesFlag.Name),
+				BaseDelay:  ctx.GlobalDuration(flags.FinalizerRetryBaseDelayFlag.Name),
+				MaxDelay:   ctx.GlobalDuration(flags.FinalizerRetryMaxDelayFlag.Name),
+			},
+			EncoderSocket:                   ctx.GlobalString(flags.EncoderSocket.Name),
+			NumConnections:                  ctx.GlobalInt(flags.NumConnectionsFlag.Name),
+			EncodingRequestQueueSize:        ctx.GlobalInt(flags.EncodingRequestQueueSizeFlag.Name),
+			BatchSizeMBLimit:                ctx.GlobalUint(flags.BatchSizeLimitFlag.Name),
+			SRSOrder:                        ctx.GlobalInt(flags.SRSOrderFlag.Name),
+			MaxNumRetriesPerBlob:            ctx.GlobalUint(flags.MaxNumRetriesPerBlobFlag.Name),
+			TargetNumChunks:                 ctx.GlobalUint(flags.TargetNumChunksFlag.Name),
+			MaxBlobsToFetchFromStore:        ctx.GlobalInt(flags.MaxBlobsToFetchFromStoreFlag.Name),
+			MaxPagesToFetchFromStore:        ctx.GlobalInt(flags.MaxPagesToFetchFromStoreFlag.Name),
+			MinBatchInterval:                ctx.GlobalDuration(flags.MinBatchIntervalFlag.Name),
+			PendingConfirmationTimeout:      ctx.GlobalDuration(flags.PendingConfirmationTimeoutFlag.Name),
+			ServiceManagerAddress:           gethcommon.HexToAddress(ctx.GlobalString(flags.EigenDAServiceManagerFlag.Name)),
+			HighPriorityLaneWeight:          ctx.GlobalUint(flags.HighPriorityLaneWeightFlag.Name),
+			StreamerStallTimeout:            ctx.GlobalDuration(flags.StreamerStallTimeoutFlag.Name),
+			VerifyAggregateSignature:        ctx.GlobalBool(flags.VerifyAggregateSignatureFlag.Name),
+			PullIntervalJitterPercent:       ctx.GlobalUint(flags.PullIntervalJitterPercentFlag.Name),
+			MaxConfirmBatchCalldata:         ctx.GlobalUint(flags.MaxConfirmBatchCalldataFlag.Name),
+			ConfirmationInfoRetention:       ctx.GlobalDuration(flags.ConfirmationInfoRetentionFlag.Name),
+			ConfirmedBatchDir:               ctx.GlobalString(flags.ConfirmedBatchDirFlag.Name),
+			MaxBlobQueueAge:                 ctx.GlobalDuration(flags.MaxBlobQueueAgeFlag.Name),
+			MaxEncodedResultAge:             ctx.GlobalDuration(flags.MaxEncodedResultAgeFlag.Name),
+			MaxReceiptParseRetries:          ctx.GlobalUint(flags.MaxReceiptParseRetriesFlag.Name),
+			MaxPendingConfirmationDuration:  ctx.GlobalDuration(flags.MaxPendingConfirmationDurationFlag.Name),
+			VerifyCommitmentsBeforeDispatch: ctx.GlobalBool(flags.VerifyCommitmentsBeforeDispatchFlag.Name),
+			EnableLocalEncoderFallback:      ctx.GlobalBool(flags.EnableLocalEncoderFallbackFlag.Name),
+			EncoderFallbackRetryInterval:    ctx.GlobalDuration(flags.EncoderFallbackRetryIntervalFlag.Name),
+			IdleLogPolicy:                   batcher.IdleLogPolicy(ctx.GlobalString(flags.IdleLogPolicyFlag.Name)),
+			MinGasTipCap:                    ctx.GlobalUint64(flags.MinGasTipCapFlag.Name),
+			TxnSignerURL:                    ctx.GlobalString(flags.TxnSignerURLFlag.Name),
+			TxnSignerAddress:                ctx.GlobalString(flags.TxnSignerAddressFlag.Name),
+			MaxNonSigners:                   ctx.GlobalUint(flags.MaxNonSignersFlag.Name),
+			ConfirmationWriteBatchSize:      ctx.GlobalUint(flags.ConfirmationWriteBatchSizeFlag.Name),
+			ConfirmationWriteConcurrency:    ctx.GlobalUint(flags.ConfirmationWriteConcurrencyFlag.Name),
+			RedisperseUnattestedQuorums:     ctx.GlobalBool(flags.RedisperseUnattestedQuorumsFlag.Name),
+			BatchFailureLogInterval:         ctx.GlobalDuration(flags.BatchFailureLogIntervalFlag.Name),
+			EncodingRequestTimeout:          ctx.GlobalDuration(flags.EncodingRequestTimeoutFlag.Name),
+			DispersalFailurePolicy:          batcher.DispersalFailurePolicy(ctx.GlobalString(flags.DispersalFailurePolicyFlag.Name)),
+			CriticalQuorums:                 criticalQuorums,
+			CriticalQuorumThreshold:         uint8(criticalQuorumThreshold),
 		},
 		TimeoutConfig: batcher.TimeoutConfig{
 			EncodingTimeout:    ctx.GlobalDuration(flags.EncodingTimeoutFlag.Name),
 			AttestationTimeout: ctx.GlobalDuration(flags.AttestationTimeoutFlag.Name),
 			ChainReadTimeout:   ctx.GlobalDuration(flags.ChainReadTimeoutFlag.Name),
 			ChainWriteTimeout:  ctx.GlobalDuration(flags.ChainWriteTimeoutFlag.Name),
+			StoreChunksTimeout: ctx.GlobalDuration(flags.StoreChunksTimeoutFlag.Name),
+		},
+		OperatorConnectionPoolConfig: common.ConnectionPoolConfig{
+			IdleTimeout:      ctx.GlobalDuration(flags.OperatorConnectionIdleTimeoutFlag.Name),
+			KeepaliveTime:    ctx.GlobalDuration(flags.OperatorKeepaliveTimeFlag.Name),
+			KeepaliveTimeout: ctx.GlobalDuration(flags.OperatorKeepaliveTimeoutFlag.Name),
 		},
 		MetricsConfig: batcher.MetricsConfig{
-			HTTPPort:      ctx.GlobalString(flags.MetricsHTTPPort.Name),
-			EnableMetrics: ctx.GlobalBool(flags.EnableMetrics.Name),
+			HTTPPort:         ctx.GlobalString(flags.MetricsHTTPPort.Name),
+			EnableMetrics:    ctx.GlobalBool(flags.EnableMetrics.Name),
+			BatchErrorWindow: ctx.GlobalDuration(flags.BatchErrorWindowFlag.Name),
 		},
 		UseGraph:                      ctx.Bool(flags.UseGraphFlag.Name),
 		GraphUrl:                      ctx.GlobalString(flags.GraphUrlFlag.Name),
@@ -72,5 +134,17 @@ func NewConfig(ctx *cli.Context) Config {
 		IndexerDataDir:                ctx.GlobalString(flags.IndexerDataDirFlag.Name),
 		IndexerConfig:                 indexer.ReadIndexerConfig(ctx),
 	}
-	return config
+	return config, nil
+}
+
+// parseQuorumIDs converts the quorum IDs given as flag values, which must be in range [0, core.MaxQuorumID]
+func parseQuorumIDs(values []int) ([]core.QuorumID, error) {
+	quorumIDs := make([]core.QuorumID, 0, len(values))
+	for _, value := range values {
+		if value < 0 || value > core.MaxQuorumID {
+			return nil, fmt.Errorf("quorum ID %d must be in range [0, %d]", value, core.MaxQuorumID)
+		}
+		quorumIDs = append(quorumIDs, core.QuorumID(value))
+	}
+	return quorumIDs, nil
 }
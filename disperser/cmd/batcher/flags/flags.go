@@ -7,6 +7,7 @@ import (
 	"github.com/Layr-Labs/eigenda/common/aws"
 	"github.com/Layr-Labs/eigenda/common/geth"
 	"github.com/Layr-Labs/eigenda/common/logging"
+	"github.com/Layr-Labs/eigenda/core/encoding"
 	"github.com/Layr-Labs/eigenda/indexer"
 	"github.com/urfave/cli"
 )
@@ -36,6 +37,13 @@ var (
 		Required: true,
 		EnvVar:   common.PrefixEnvVar(envVarPrefix, "PULL_INTERVAL"),
 	}
+	PullIntervalJitterPercentFlag = cli.UintFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "pull-interval-jitter-percent"),
+		Usage:    "Percentage of the pull interval within which each interval is randomized, to spread the load of replicas polling on the same interval. Capped at 100. If set to zero, the pull interval is fixed",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "PULL_INTERVAL_JITTER_PERCENT"),
+		Value:    0,
+	}
 	BlsOperatorStateRetrieverFlag = cli.StringFlag{
 		Name:     common.PrefixFlag(FlagPrefix, "bls-operator-state-retriever"),
 		Usage:    "Address of the BLS Operator State Retriever",
@@ -106,6 +114,13 @@ var (
 		Value:    20 * time.Second,
 		EnvVar:   common.PrefixEnvVar(envVarPrefix, "ATTESTATION_TIMEOUT"),
 	}
+	StoreChunksTimeoutFlag = cli.DurationFlag{
+		Name:     "store-chunks-timeout",
+		Usage:    "timeout of the grpc call storing the chunks of a batch on each DA node, after which the node is counted as a non-signer. If set to zero, each node is given the whole attestation timeout",
+		Required: false,
+		Value:    0,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "STORE_CHUNKS_TIMEOUT"),
+	}
 	ChainReadTimeoutFlag = cli.DurationFlag{
 		Name:     "chain-read-timeout",
 		Usage:    "connection timeout to read from chain",
@@ -141,6 +156,47 @@ var (
 		EnvVar:   common.PrefixEnvVar(envVarPrefix, "FINALIZER_POOL_SIZE"),
 		Value:    4,
 	}
+	FinalizerWriteBatchSizeFlag = cli.IntFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "finalizer-write-batch-size"),
+		Usage:    "Number of blob finalizations written to the blob store in a single batched write. If set to zero, the finalizations of each page of blobs are written in a single batched write",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "FINALIZER_WRITE_BATCH_SIZE"),
+		Value:    25,
+	}
+	StreamerStallTimeoutFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "encoding-streamer-stall-timeout"),
+		Usage:    "Time without a successful encode while encoding requests are pending after which the encoding streamer is restarted. If set to zero, the streamer is never restarted",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "ENCODING_STREAMER_STALL_TIMEOUT"),
+		Value:    5 * time.Minute,
+	}
+	VerifyAggregateSignatureFlag = cli.BoolFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "verify-aggregate-signature"),
+		Usage:    "Verify the aggregate signature of a batch against the operator state before confirming it onchain",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "VERIFY_AGGREGATE_SIGNATURE"),
+	}
+	FinalizerMaxRetriesFlag = cli.IntFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "finalizer-max-retries"),
+		Usage:    "Maximum number of attempts the finalizer makes when reading from the chain",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "FINALIZER_MAX_RETRIES"),
+		Value:    3,
+	}
+	FinalizerRetryBaseDelayFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "finalizer-retry-base-delay"),
+		Usage:    "Delay before the finalizer's first chain read retry. The delay doubles on each subsequent retry",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "FINALIZER_RETRY_BASE_DELAY"),
+		Value:    1 * time.Second,
+	}
+	FinalizerRetryMaxDelayFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "finalizer-retry-max-delay"),
+		Usage:    "Maximum delay between the finalizer's chain read retries. If set to zero, the delay is not capped",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "FINALIZER_RETRY_MAX_DELAY"),
+		Value:    0,
+	}
 	EncodingRequestQueueSizeFlag = cli.IntFlag{
 		Name:     common.PrefixFlag(FlagPrefix, "encoding-request-queue-size"),
 		Usage:    "Size of the encoding request queue",
@@ -178,6 +234,219 @@ var (
 		EnvVar:   common.PrefixEnvVar(envVarPrefix, "MAX_BLOBS_TO_FETCH_FROM_STORE"),
 		Value:    100,
 	}
+	MaxPagesToFetchFromStoreFlag = cli.IntFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "max-pages-to-fetch-from-store"),
+		Usage:    "Maximum number of pages of blobs read from the queue in each round of encoding requests. High priority blobs are selected ahead of the normal ones within the pages read. If set to zero, a single page is read",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "MAX_PAGES_TO_FETCH_FROM_STORE"),
+		Value:    10,
+	}
+	MinBatchIntervalFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "min-batch-interval"),
+		Usage:    "Minimum time between dispatched batches. Batches triggered earlier are deferred. If set to zero, batches are not rate limited",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "MIN_BATCH_INTERVAL"),
+		Value:    0,
+	}
+	PendingConfirmationTimeoutFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "pending-confirmation-timeout"),
+		Usage:    "Time a batch can wait for its confirmation. Past it, the batch is checked onchain, and its blobs are batched again if it isn't confirmed. If set to zero, batches wait until their confirmation is resolved",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "PENDING_CONFIRMATION_TIMEOUT"),
+		Value:    0,
+	}
+	HighPriorityLaneWeightFlag = cli.UintFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "high-priority-lane-weight"),
+		Usage:    "Number of high priority blobs encoded for each normal priority blob when both are waiting. If set to zero, high priority blobs are always encoded first",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "HIGH_PRIORITY_LANE_WEIGHT"),
+		Value:    4,
+	}
+	MaxConfirmBatchCalldataFlag = cli.UintFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "max-confirm-batch-calldata"),
+		Usage:    "Maximum size in bytes of the confirmBatch calldata. Before dispersal, batches whose calldata could exceed it are split by quorums into sub-batches that are dispatched and confirmed independently. If set to zero, batches are never split",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "MAX_CONFIRM_BATCH_CALLDATA"),
+		Value:    0,
+	}
+	ConfirmationInfoRetentionFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "confirmation-info-retention"),
+		Usage:    "Time after a blob is requested after which the inclusion proof and length proofs of the finalized blob are pruned from its confirmation info. If set to zero, confirmation info is never pruned",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "CONFIRMATION_INFO_RETENTION"),
+		Value:    0,
+	}
+	ConfirmedBatchDirFlag = cli.StringFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "confirmed-batch-dir"),
+		Usage:    "Directory the confirmation info of confirmed batches is kept in until all of their blobs are updated, so that the blobs are reconciled after a restart. If empty, it is kept in memory only",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "CONFIRMED_BATCH_DIR"),
+		Value:    "",
+	}
+	MaxBlobQueueAgeFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "max-blob-queue-age"),
+		Usage:    "Maximum time an encoded blob can wait to be batched before a batch is created regardless of the batch size limit and pull interval. If set to zero, the queue age doesn't trigger batches",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "MAX_BLOB_QUEUE_AGE"),
+		Value:    0,
+	}
+	MaxEncodedResultAgeFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "max-encoded-result-age"),
+		Usage:    "Maximum time an encoded blob that isn't pending confirmation is kept in memory before it's dropped. If set to zero, encoded blobs are only dropped once their reference block goes stale",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "MAX_ENCODED_RESULT_AGE"),
+		Value:    0,
+	}
+	MaxReceiptParseRetriesFlag = cli.UintFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "max-receipt-parse-retries"),
+		Usage:    "Number of times the receipt of a confirmBatch transaction is fetched again when the batch ID can't be parsed from it. The retries stop once they have waited for 10s in total. Batches whose batch ID still can't be parsed are kept pending and reconciled in the background instead of being dispersed again. If set to zero, the receipt is fetched up to 3 times",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "MAX_RECEIPT_PARSE_RETRIES"),
+		Value:    0,
+	}
+	MaxPendingConfirmationDurationFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "max-pending-confirmation-duration"),
+		Usage:    "Time a dispersed blob can wait for its batch to be confirmed before it's counted as stuck in the metrics and logged. If set to zero, stuck blobs aren't counted",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "MAX_PENDING_CONFIRMATION_DURATION"),
+		Value:    0,
+	}
+	VerifyCommitmentsBeforeDispatchFlag = cli.BoolFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "verify-commitments-before-dispatch"),
+		Usage:    "Verify that the commitment of each blob of a batch is equivalent to its length commitment before dispatching the batch, failing the blobs that aren't",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "VERIFY_COMMITMENTS_BEFORE_DISPATCH"),
+	}
+	EnableLocalEncoderFallbackFlag = cli.BoolFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "enable-local-encoder-fallback"),
+		Usage:    "Encode blobs with a local encoder, loading the SRS from the kzg flags, while the remote encoder is unhealthy",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "ENABLE_LOCAL_ENCODER_FALLBACK"),
+	}
+	EncoderFallbackRetryIntervalFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "encoder-fallback-retry-interval"),
+		Usage:    "Time the local encoder is used after the remote encoder fails before the remote encoder is tried again. If set to zero, the remote encoder is tried again after 30s",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "ENCODER_FALLBACK_RETRY_INTERVAL"),
+		Value:    0,
+	}
+	IdleLogPolicyFlag = cli.StringFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "idle-log-policy"),
+		Usage:    "How to log the pulls that find no encoded results to make a batch with: warn (warn on every idle pull), warn-once (warn on the first idle pull after a batch, and log the others at debug level) or debug (log every idle pull at debug level)",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "IDLE_LOG_POLICY"),
+		Value:    "warn",
+	}
+	BatchErrorWindowFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "batch-error-window"),
+		Usage:    "Window the batch errors are counted over by reason in the batch_error_window metric, up to 1h",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "BATCH_ERROR_WINDOW"),
+		Value:    10 * time.Minute,
+	}
+	MinGasTipCapFlag = cli.Uint64Flag{
+		Name:     common.PrefixFlag(FlagPrefix, "min-gas-tip-cap"),
+		Usage:    "Minimum gas tip cap in wei of the confirmBatch transactions. The suggested tip cap is raised to it when it's lower. If set to zero, the suggested tip cap is used as is",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "MIN_GAS_TIP_CAP"),
+		Value:    0,
+	}
+	TxnSignerURLFlag = cli.StringFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "txn-signer-url"),
+		Usage:    "URL of the remote signer (eth_signTransaction) to sign the transactions of the batcher with, e.g. a Web3Signer holding the key in AWS KMS. If empty, the private key of the eth client is used",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "TXN_SIGNER_URL"),
+		Value:    "",
+	}
+	TxnSignerAddressFlag = cli.StringFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "txn-signer-address"),
+		Usage:    "Address of the account whose key is held by the remote signer. Required if the txn signer URL is set",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "TXN_SIGNER_ADDRESS"),
+		Value:    "",
+	}
+	MaxNonSignersFlag = cli.UintFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "max-non-signers"),
+		Usage:    "Maximum number of non-signers of a batch. Batches with more non-signers are failed instead of confirmed. If set to zero, the number of non-signers isn't limited",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "MAX_NON_SIGNERS"),
+		Value:    0,
+	}
+	ConfirmationWriteBatchSizeFlag = cli.UintFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "confirmation-write-batch-size"),
+		Usage:    "Number of blob confirmations written to the blob store in a single batched write. If set to zero, the confirmations of a batch are written in a single batched write",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "CONFIRMATION_WRITE_BATCH_SIZE"),
+		Value:    0,
+	}
+	ConfirmationWriteConcurrencyFlag = cli.UintFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "confirmation-write-concurrency"),
+		Usage:    "Maximum number of batched blob confirmation writes in flight at a time",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "CONFIRMATION_WRITE_CONCURRENCY"),
+		Value:    1,
+	}
+	RedisperseUnattestedQuorumsFlag = cli.BoolFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "redisperse-unattested-quorums"),
+		Usage:    "Retry blobs that got insufficient signatures on some of their quorums by re-dispersing them to all of their quorums",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "REDISPERSE_UNATTESTED_QUORUMS"),
+	}
+	EncodingRequestTimeoutFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "encoding-request-timeout"),
+		Usage:    "Timeout of each encoding request sent to the encoder. If set to zero, the pull interval is used",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "ENCODING_REQUEST_TIMEOUT"),
+		Value:    0,
+	}
+	DispersalFailurePolicyFlag = cli.StringFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "dispersal-failure-policy"),
+		Usage:    "How to handle operators that fail to receive the chunks of a batch: proceed (aggregate the signatures regardless), abort (fail the batch if no blob can reach its quorum thresholds) or retry (disperse once more to the failed operators)",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "DISPERSAL_FAILURE_POLICY"),
+		Value:    "proceed",
+	}
+	CriticalQuorumIDsFlag = cli.IntSliceFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "critical-quorum-ids"),
+		Usage:    "IDs of the must-have quorums. A batch is failed if the percentage of stake that signed one of them is below the critical quorum threshold, while blobs falling short of their own quorum threshold are marked as having insufficient signatures",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "CRITICAL_QUORUM_IDS"),
+	}
+	CriticalQuorumThresholdFlag = cli.UintFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "critical-quorum-threshold"),
+		Usage:    "Minimum percentage of stake that must sign each critical quorum of a batch. Required if critical quorum IDs are set",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "CRITICAL_QUORUM_THRESHOLD"),
+		Value:    0,
+	}
+	BatchFailureLogIntervalFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "batch-failure-log-interval"),
+		Usage:    "Interval over which repeated identical batch failures are collapsed into a single summary log. If set to zero, every failure is logged",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "BATCH_FAILURE_LOG_INTERVAL"),
+		Value:    1 * time.Minute,
+	}
+	OperatorConnectionIdleTimeoutFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "operator-connection-idle-timeout"),
+		Usage:    "Duration without any dispersal after which the connection to an operator releases its transport. If set to zero, connections are kept open",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "OPERATOR_CONNECTION_IDLE_TIMEOUT"),
+		Value:    10 * time.Minute,
+	}
+	OperatorKeepaliveTimeFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "operator-keepalive-time"),
+		Usage:    "Interval of the keepalive pings sent to the operators. Operators close the connections that ping more often than every 5 minutes. If set to zero, no keepalive pings are sent",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "OPERATOR_KEEPALIVE_TIME"),
+		Value:    5 * time.Minute,
+	}
+	OperatorKeepaliveTimeoutFlag = cli.DurationFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "operator-keepalive-timeout"),
+		Usage:    "Duration to wait for an operator to acknowledge a keepalive ping before the connection is closed",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envVarPrefix, "OPERATOR_KEEPALIVE_TIMEOUT"),
+		Value:    20 * time.Second,
+	}
 )
 
 var requiredFlags = []cli.Flag{
@@ -199,15 +468,54 @@ var optionalFlags = []cli.Flag{
 	IndexerDataDirFlag,
 	EncodingTimeoutFlag,
 	AttestationTimeoutFlag,
+	StoreChunksTimeoutFlag,
 	ChainReadTimeoutFlag,
 	ChainWriteTimeoutFlag,
 	NumConnectionsFlag,
 	FinalizerIntervalFlag,
 	FinalizerPoolSizeFlag,
+	FinalizerWriteBatchSizeFlag,
+	FinalizerMaxRetriesFlag,
+	FinalizerRetryBaseDelayFlag,
+	FinalizerRetryMaxDelayFlag,
 	EncodingRequestQueueSizeFlag,
 	MaxNumRetriesPerBlobFlag,
 	TargetNumChunksFlag,
 	MaxBlobsToFetchFromStoreFlag,
+	MaxPagesToFetchFromStoreFlag,
+	MinBatchIntervalFlag,
+	PendingConfirmationTimeoutFlag,
+	HighPriorityLaneWeightFlag,
+	PullIntervalJitterPercentFlag,
+	MaxConfirmBatchCalldataFlag,
+	ConfirmationInfoRetentionFlag,
+	ConfirmedBatchDirFlag,
+	MaxBlobQueueAgeFlag,
+	MaxEncodedResultAgeFlag,
+	MaxReceiptParseRetriesFlag,
+	MaxPendingConfirmationDurationFlag,
+	VerifyCommitmentsBeforeDispatchFlag,
+	EnableLocalEncoderFallbackFlag,
+	EncoderFallbackRetryIntervalFlag,
+	IdleLogPolicyFlag,
+	BatchErrorWindowFlag,
+	MinGasTipCapFlag,
+	TxnSignerURLFlag,
+	TxnSignerAddressFlag,
+	MaxNonSignersFlag,
+	ConfirmationWriteBatchSizeFlag,
+	ConfirmationWriteConcurrencyFlag,
+	RedisperseUnattestedQuorumsFlag,
+	BatchFailureLogIntervalFlag,
+	EncodingRequestTimeoutFlag,
+	DispersalFailurePolicyFlag,
+	CriticalQuorumIDsFlag,
+	CriticalQuorumThresholdFlag,
+	StreamerStallTimeoutFlag,
+	VerifyAggregateSignatureFlag,
+	OperatorConnectionIdleTimeoutFlag,
+	OperatorKeepaliveTimeFlag,
+	OperatorKeepaliveTimeoutFlag,
 }
 
 // Flags contains the list of configuration options available to the binary.
@@ -219,4 +527,22 @@ func init() {
 	Flags = append(Flags, logging.CLIFlags(envVarPrefix, FlagPrefix)...)
 	Flags = append(Flags, indexer.CLIFlags(envVarPrefix)...)
 	Flags = append(Flags, aws.ClientFlags(envVarPrefix, FlagPrefix)...)
+	Flags = append(Flags, optionalKzgFlags()...)
+}
+
+// optionalKzgFlags returns the kzg flags with none of them required, since the batcher only loads the SRS to verify
+// commitments before dispatch or to encode blobs with the local encoder fallback
+func optionalKzgFlags() []cli.Flag {
+	kzgFlags := encoding.CLIFlags(envVarPrefix)
+	for i, flag := range kzgFlags {
+		switch f := flag.(type) {
+		case cli.StringFlag:
+			f.Required = false
+			kzgFlags[i] = f
+		case cli.Uint64Flag:
+			f.Required = false
+			kzgFlags[i] = f
+		}
+	}
+	return kzgFlags
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math/big"
 	"os"
 	"time"
 
@@ -17,12 +18,16 @@ import (
 	"github.com/Layr-Labs/eigenda/common/geth"
 	"github.com/Layr-Labs/eigenda/common/logging"
 	"github.com/Layr-Labs/eigenda/core"
+	"github.com/Layr-Labs/eigenda/core/encoding"
 	coreeth "github.com/Layr-Labs/eigenda/core/eth"
+	"github.com/Layr-Labs/eigenda/disperser"
 	"github.com/Layr-Labs/eigenda/disperser/batcher"
 	dispatcher "github.com/Layr-Labs/eigenda/disperser/batcher/grpc"
 	"github.com/Layr-Labs/eigenda/disperser/cmd/batcher/flags"
 	"github.com/Layr-Labs/eigenda/disperser/common/blobstore"
 	"github.com/Layr-Labs/eigenda/disperser/encoder"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/urfave/cli"
 )
@@ -70,7 +75,10 @@ func RunBatcher(ctx *cli.Context) error {
 		log.Printf("Failed to clean up readiness file: %v at path %v \n", err, readinessProbePath)
 	}
 
-	config := NewConfig(ctx)
+	config, err := NewConfig(ctx)
+	if err != nil {
+		return err
+	}
 
 	logger, err := logging.GetLogger(config.LoggerConfig)
 	if err != nil {
@@ -90,15 +98,40 @@ func RunBatcher(ctx *cli.Context) error {
 	}
 
 	dispatcher := dispatcher.NewDispatcher(&dispatcher.Config{
-		Timeout: config.TimeoutConfig.AttestationTimeout,
+		Timeout:            config.TimeoutConfig.AttestationTimeout,
+		StoreChunksTimeout: config.TimeoutConfig.StoreChunksTimeout,
+		ConnectionPool:     config.OperatorConnectionPoolConfig,
 	}, logger)
 	asgn := &core.StdAssignmentCoordinator{}
 
-	client, err := geth.NewClient(config.EthClientConfig, logger)
+	gethClient, err := geth.NewClient(config.EthClientConfig, logger)
 	if err != nil {
 		logger.Error("Cannot create chain.Client", "err", err)
 		return err
 	}
+	chainID, err := gethClient.ChainID(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	var signer batcher.TxnSigner
+	if config.BatcherConfig.TxnSignerURL != "" {
+		if !gcommon.IsHexAddress(config.BatcherConfig.TxnSignerAddress) {
+			return fmt.Errorf("invalid txn signer address %q", config.BatcherConfig.TxnSignerAddress)
+		}
+		signer, err = batcher.NewRemoteTxnSigner(context.Background(), config.BatcherConfig.TxnSignerURL, gcommon.HexToAddress(config.BatcherConfig.TxnSignerAddress), chainID)
+		if err != nil {
+			return err
+		}
+		logger.Info("Signing transactions with the remote signer", "url", config.BatcherConfig.TxnSignerURL, "address", signer.Address().Hex())
+	} else {
+		privateKey, err := crypto.HexToECDSA(config.EthClientConfig.PrivateKeyString)
+		if err != nil {
+			return fmt.Errorf("failed to parse the private key of the batcher: %w", err)
+		}
+		signer = batcher.NewLocalTxnSigner(privateKey, chainID)
+	}
+	// the transactor and the transaction manager both send from the account of the signer
+	client := batcher.NewTxnSignerEthClient(gethClient, signer)
 	rpcClient, err := rpc.Dial(config.EthClientConfig.RPCURL)
 	if err != nil {
 		return err
@@ -150,20 +183,50 @@ func RunBatcher(ctx *cli.Context) error {
 	}
 
 	metrics := batcher.NewMetrics(config.MetricsConfig.HTTPPort, logger)
+	if config.MetricsConfig.BatchErrorWindow > 0 {
+		metrics.BatchErrorWindow = config.MetricsConfig.BatchErrorWindow
+	}
+	agg.LatencyRecorder = metrics
 
 	if len(config.BatcherConfig.EncoderSocket) == 0 {
 		return fmt.Errorf("encoder socket must be specified")
 	}
-	encoderClient, err := encoder.NewEncoderClient(config.BatcherConfig.EncoderSocket, config.TimeoutConfig.EncodingTimeout)
+	config.BatcherConfig.DispersalFailurePolicy, err = batcher.ParseDispersalFailurePolicy(string(config.BatcherConfig.DispersalFailurePolicy))
 	if err != nil {
 		return err
 	}
-	finalizer := batcher.NewFinalizer(config.TimeoutConfig.ChainReadTimeout, config.BatcherConfig.FinalizerInterval, queue, client, rpcClient, config.BatcherConfig.MaxNumRetriesPerBlob, 1000, config.BatcherConfig.FinalizerPoolSize, logger, metrics.FinalizerMetrics)
-	txnManager := batcher.NewTxnManager(client, 20, config.TimeoutConfig.ChainWriteTimeout, logger, metrics.TxnManagerMetrics)
+	config.BatcherConfig.IdleLogPolicy, err = batcher.ParseIdleLogPolicy(string(config.BatcherConfig.IdleLogPolicy))
+	if err != nil {
+		return err
+	}
+	var encoderClient disperser.EncoderClient
+	encoderClient, err = encoder.NewEncoderClient(config.BatcherConfig.EncoderSocket, config.TimeoutConfig.EncodingTimeout)
+	if err != nil {
+		return err
+	}
+	if config.BatcherConfig.EnableLocalEncoderFallback {
+		localEncoder, err := encoding.NewEncoder(config.EncoderConfig, true)
+		if err != nil {
+			return fmt.Errorf("failed to create the local encoder fallback: %w", err)
+		}
+		encoderClient = disperser.NewFallbackEncoderClient(encoderClient, disperser.NewLocalEncoderClient(localEncoder), config.BatcherConfig.EncoderFallbackRetryInterval, logger)
+	}
+	finalizer := batcher.NewFinalizer(config.TimeoutConfig.ChainReadTimeout, config.BatcherConfig.FinalizerInterval, queue, client, rpcClient, config.BatcherConfig.MaxNumRetriesPerBlob, 1000, config.BatcherConfig.FinalizerPoolSize, config.BatcherConfig.FinalizerWriteBatchSize, config.BatcherConfig.FinalizerRetryConfig, nil, logger, metrics.FinalizerMetrics)
+	var minGasTipCap *big.Int
+	if config.BatcherConfig.MinGasTipCap > 0 {
+		minGasTipCap = new(big.Int).SetUint64(config.BatcherConfig.MinGasTipCap)
+	}
+	txnManager := batcher.NewTxnManager(client, 20, config.TimeoutConfig.ChainWriteTimeout, minGasTipCap, logger, metrics.TxnManagerMetrics)
 	batcher, err := batcher.NewBatcher(config.BatcherConfig, config.TimeoutConfig, queue, dispatcher, ics, asgn, encoderClient, agg, client, finalizer, tx, txnManager, logger, metrics, handleBatchLivenessChan)
 	if err != nil {
 		return err
 	}
+	if config.BatcherConfig.VerifyCommitmentsBeforeDispatch {
+		batcher.CommitmentVerifier, err = encoding.NewEncoder(config.EncoderConfig, false)
+		if err != nil {
+			return fmt.Errorf("failed to create the commitment verifier: %w", err)
+		}
+	}
 
 	// Enable Metrics Block
 	if config.MetricsConfig.EnableMetrics {
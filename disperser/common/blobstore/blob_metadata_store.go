@@ -2,30 +2,59 @@ package blobstore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/Layr-Labs/eigenda/common"
 	commondynamodb "github.com/Layr-Labs/eigenda/common/aws/dynamodb"
+	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/disperser"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 const (
-	statusIndexName = "StatusIndex"
-	batchIndexName  = "BatchIndex"
+	idempotencyKeyClaimPrefix  = "IdempotencyKey#"
+	idempotencyKeyClaimSortKey = "IdempotencyKey"
+
+	aggregateSignaturePrefix  = "BatchAggregateSignature#"
+	aggregateSignatureSortKey = "BatchAggregateSignature"
+
+	statusIndexName     = "StatusIndex"
+	batchIndexName      = "BatchIndex"
+	batchIDIndexName    = "BatchIDIndex"
+	commitmentIndexName = "CommitmentIndex"
+
+	// compactConfirmationInfoField is the attribute holding the confirmation info of a blob in its compact encoding,
+	// whose leading byte is the version of the encoding
+	compactConfirmationInfoField = "CompactConfirmationInfo"
 )
 
+// confirmationInfoIndexedFields are the fields of the confirmation info that are stored as attributes of their own,
+// so that the blobs can be indexed by them
+var confirmationInfoIndexedFields = []string{"BatchHeaderHash", "BlobIndex", "BatchID"}
+
 // BlobMetadataStore is a blob metadata storage backed by DynamoDB
 // The blob metadata is stored in a single table and replicated in several indexes.
 // - Metadata: (Partition Key: BlobKey, Sort Key: MetadataHash) -> Metadata
 // - Indexes
 //   - StatusIndex: (Partition Key: Status, Sort Key: RequestedAt) -> Metadata
 //   - BatchIndex: (Partition Key: BatchHeaderHash, Sort Key: BlobIndex) -> Metadata
+//   - BatchIDIndex: (Partition Key: BatchID, Sort Key: BlobIndex) -> Metadata
+//   - CommitmentIndex: (Partition Key: CommitmentKey, Sort Key: RequestedAt) -> Metadata
+//
+// The confirmation info of a blob is stored in its compact encoding, along with the fields of it the blob is indexed by.
+//
+// Idempotency keys are claimed with items of the same table, which have none of the attributes of the indexes.
+// - IdempotencyKeyClaim: (Partition Key: "IdempotencyKey#" + IdempotencyKey, Sort Key: "IdempotencyKey") -> Claim
+//
+// The aggregate signature of each confirmed batch is stored once for the batch in an item of the same table as well.
+// - BatchAggregateSignature: (Partition Key: "BatchAggregateSignature#" + BatchID, Sort Key: "BatchAggregateSignature") -> Signature
 type BlobMetadataStore struct {
 	dynamoDBClient *commondynamodb.Client
 	logger         common.Logger
@@ -73,6 +102,27 @@ func (s *BlobMetadataStore) GetBlobMetadata(ctx context.Context, metadataKey dis
 	return metadata, nil
 }
 
+// GetBulkBlobMetadata returns the metadata of the blobs with the given keys in batched reads. Blobs that don't exist
+// are left out, and the metadata is returned in no particular order.
+func (s *BlobMetadataStore) GetBulkBlobMetadata(ctx context.Context, metadataKeys []disperser.BlobKey) ([]*disperser.BlobMetadata, error) {
+	keys := make([]commondynamodb.Key, len(metadataKeys))
+	for i, metadataKey := range metadataKeys {
+		keys[i] = blobMetadataKey(metadataKey)
+	}
+	items, err := s.dynamoDBClient.GetItems(ctx, s.tableName, keys)
+	if err != nil {
+		return nil, err
+	}
+	metadatas := make([]*disperser.BlobMetadata, len(items))
+	for i, item := range items {
+		metadatas[i], err = UnmarshalBlobMetadata(item)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return metadatas, nil
+}
+
 // GetBlobMetadataByStatus returns all the metadata with the given status
 // Because this function scans the entire index, it should only be used for status with a limited number of items.
 // It should only be used to filter "Processing" status. To support other status, a streaming version should be implemented.
@@ -186,6 +236,197 @@ func (s *BlobMetadataStore) GetAllBlobMetadataByBatch(ctx context.Context, batch
 	return metadatas, nil
 }
 
+// GetBlobMetadataByBatchID returns the metadata of all the blobs in the batch with the given onchain batch ID.
+// Only blobs that have gone through batch confirmation carry a BatchID, so blobs still being processed are never returned.
+func (s *BlobMetadataStore) GetBlobMetadataByBatchID(ctx context.Context, batchID uint32) ([]*disperser.BlobMetadata, error) {
+	items, err := s.dynamoDBClient.QueryIndex(ctx, s.tableName, batchIDIndexName, "BatchID = :batch_id", commondynamodb.ExpresseionValues{
+		":batch_id": &types.AttributeValueMemberN{
+			Value: strconv.FormatUint(uint64(batchID), 10),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metadatas := make([]*disperser.BlobMetadata, len(items))
+	for i, item := range items {
+		metadatas[i], err = UnmarshalBlobMetadata(item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return metadatas, nil
+}
+
+// idempotencyKeyClaim is the item claiming an idempotency key for a blob
+type idempotencyKeyClaim struct {
+	BlobHash            string
+	MetadataHash        string
+	ClaimedBlobHash     disperser.BlobHash
+	ClaimedMetadataHash disperser.MetadataHash
+	PayloadHash         string
+	Expiry              uint64
+}
+
+func idempotencyKeyClaimKey(idempotencyKey string) commondynamodb.Key {
+	return commondynamodb.Key{
+		"BlobHash": &types.AttributeValueMemberS{
+			Value: idempotencyKeyClaimPrefix + idempotencyKey,
+		},
+		"MetadataHash": &types.AttributeValueMemberS{
+			Value: idempotencyKeyClaimSortKey,
+		},
+	}
+}
+
+// ClaimIdempotencyKey claims the idempotency key for the blob with the given key and payload hash, unless it was already
+// claimed. If it was, the key of the blob that claimed it is returned along with disperser.ErrIdempotencyKeyExists, or
+// disperser.ErrIdempotencyKeyMismatch if that blob has a different payload hash.
+func (s *BlobMetadataStore) ClaimIdempotencyKey(ctx context.Context, idempotencyKey string, blobKey disperser.BlobKey, payloadHash string) (disperser.BlobKey, error) {
+	// don't expire if ttl is 0
+	expiry := uint64(0)
+	if s.ttl > 0 {
+		expiry = uint64(time.Now().Add(s.ttl).Unix())
+	}
+	item, err := attributevalue.MarshalMap(&idempotencyKeyClaim{
+		BlobHash:            idempotencyKeyClaimPrefix + idempotencyKey,
+		MetadataHash:        idempotencyKeyClaimSortKey,
+		ClaimedBlobHash:     blobKey.BlobHash,
+		ClaimedMetadataHash: blobKey.MetadataHash,
+		PayloadHash:         payloadHash,
+		Expiry:              expiry,
+	})
+	if err != nil {
+		return disperser.BlobKey{}, err
+	}
+
+	err = s.dynamoDBClient.PutItemWithCondition(ctx, s.tableName, item, "attribute_not_exists(BlobHash)", nil)
+	if errors.Is(err, commondynamodb.ErrConditionFailed) {
+		claim, err := s.getIdempotencyKeyClaim(ctx, idempotencyKey)
+		if err != nil {
+			return disperser.BlobKey{}, err
+		}
+		claimedKey := disperser.BlobKey{BlobHash: claim.ClaimedBlobHash, MetadataHash: claim.ClaimedMetadataHash}
+		if claim.PayloadHash != payloadHash {
+			return claimedKey, disperser.ErrIdempotencyKeyMismatch
+		}
+		return claimedKey, disperser.ErrIdempotencyKeyExists
+	}
+	if err != nil {
+		return disperser.BlobKey{}, err
+	}
+	return blobKey, nil
+}
+
+// ReleaseIdempotencyKey removes the claim of the idempotency key, so that it can be claimed again
+func (s *BlobMetadataStore) ReleaseIdempotencyKey(ctx context.Context, idempotencyKey string) error {
+	return s.dynamoDBClient.DeleteItem(ctx, s.tableName, idempotencyKeyClaimKey(idempotencyKey))
+}
+
+// getIdempotencyKeyClaim returns the claim of the idempotency key, read consistently with ClaimIdempotencyKey
+func (s *BlobMetadataStore) getIdempotencyKeyClaim(ctx context.Context, idempotencyKey string) (*idempotencyKeyClaim, error) {
+	item, err := s.dynamoDBClient.GetItemWithConsistentRead(ctx, s.tableName, idempotencyKeyClaimKey(idempotencyKey))
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, disperser.ErrBlobNotFound
+	}
+	claim := &idempotencyKeyClaim{}
+	if err := attributevalue.UnmarshalMap(item, claim); err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+// batchAggregateSignature is the item storing the aggregate signature of a batch
+type batchAggregateSignature struct {
+	BlobHash             string
+	MetadataHash         string
+	SignatureAggregation []byte
+	Expiry               uint64
+}
+
+func batchAggregateSignatureKey(batchID uint32) commondynamodb.Key {
+	return commondynamodb.Key{
+		"BlobHash": &types.AttributeValueMemberS{
+			Value: aggregateSignaturePrefix + strconv.FormatUint(uint64(batchID), 10),
+		},
+		"MetadataHash": &types.AttributeValueMemberS{
+			Value: aggregateSignatureSortKey,
+		},
+	}
+}
+
+// PutBatchAggregateSignature stores the serialized aggregate signature of the batch with the given batch ID
+func (s *BlobMetadataStore) PutBatchAggregateSignature(ctx context.Context, batchID uint32, signatureAggregation []byte) error {
+	// don't expire if ttl is 0
+	expiry := uint64(0)
+	if s.ttl > 0 {
+		expiry = uint64(time.Now().Add(s.ttl).Unix())
+	}
+	item, err := attributevalue.MarshalMap(&batchAggregateSignature{
+		BlobHash:             aggregateSignaturePrefix + strconv.FormatUint(uint64(batchID), 10),
+		MetadataHash:         aggregateSignatureSortKey,
+		SignatureAggregation: signatureAggregation,
+		Expiry:               expiry,
+	})
+	if err != nil {
+		return err
+	}
+	return s.dynamoDBClient.PutItem(ctx, s.tableName, item)
+}
+
+// GetBatchAggregateSignature returns the serialized aggregate signature of the batch with the given batch ID, or
+// disperser.ErrBatchNotFound if it isn't stored
+func (s *BlobMetadataStore) GetBatchAggregateSignature(ctx context.Context, batchID uint32) ([]byte, error) {
+	item, err := s.dynamoDBClient.GetItem(ctx, s.tableName, batchAggregateSignatureKey(batchID))
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, disperser.ErrBatchNotFound
+	}
+	aggSig := &batchAggregateSignature{}
+	if err := attributevalue.UnmarshalMap(item, aggSig); err != nil {
+		return nil, err
+	}
+	return aggSig.SignatureAggregation, nil
+}
+
+// GetBlobMetadataByIdempotencyKey returns the metadata of the blob that claimed the given idempotency key with
+// ClaimIdempotencyKey. Returns disperser.ErrIdempotencyKeyMismatch if that blob has a different payload hash.
+func (s *BlobMetadataStore) GetBlobMetadataByIdempotencyKey(ctx context.Context, idempotencyKey string, payloadHash string) (*disperser.BlobMetadata, error) {
+	if idempotencyKey == "" {
+		return nil, disperser.ErrBlobNotFound
+	}
+	claim, err := s.getIdempotencyKeyClaim(ctx, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if claim.PayloadHash != payloadHash {
+		return nil, disperser.ErrIdempotencyKeyMismatch
+	}
+
+	// the blob metadata is written after the key is claimed
+	item, err := s.dynamoDBClient.GetItemWithConsistentRead(ctx, s.tableName, commondynamodb.Key{
+		"BlobHash": &types.AttributeValueMemberS{
+			Value: claim.ClaimedBlobHash,
+		},
+		"MetadataHash": &types.AttributeValueMemberS{
+			Value: claim.ClaimedMetadataHash,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, disperser.ErrBlobNotFound
+	}
+	return UnmarshalBlobMetadata(item)
+}
+
 func (s *BlobMetadataStore) GetBlobMetadataInBatch(ctx context.Context, batchHeaderHash [32]byte, blobIndex uint32) (*disperser.BlobMetadata, error) {
 	items, err := s.dynamoDBClient.QueryIndex(ctx, s.tableName, batchIndexName, "BatchHeaderHash = :batch_header_hash AND BlobIndex = :blob_index", commondynamodb.ExpresseionValues{
 		":batch_header_hash": &types.AttributeValueMemberB{
@@ -248,6 +489,118 @@ func (s *BlobMetadataStore) UpdateBlobMetadata(ctx context.Context, metadataKey
 	return err
 }
 
+// UpdateBlobMetadatasIfStatus updates the metadata of several blobs in transactional writes, only for the blobs whose
+// stored status is one of the given statuses. It returns the keys of the blobs that were in another status, whose
+// metadata is left as is.
+func (s *BlobMetadataStore) UpdateBlobMetadatasIfStatus(ctx context.Context, updated []*disperser.BlobMetadata, statuses ...disperser.BlobStatus) ([]disperser.BlobKey, error) {
+	if len(statuses) == 0 {
+		return nil, errors.New("at least one status must be given")
+	}
+	statusValues := make([]expression.OperandBuilder, len(statuses))
+	for i, status := range statuses {
+		statusValues[i] = expression.Value(&types.AttributeValueMemberN{Value: strconv.Itoa(int(status))})
+	}
+	condition := expression.Name("BlobStatus").In(statusValues[0], statusValues[1:]...)
+
+	updates := make([]commondynamodb.ConditionalUpdate, len(updated))
+	for i, metadata := range updated {
+		item, err := MarshalBlobMetadata(metadata)
+		if err != nil {
+			return nil, err
+		}
+		updates[i] = commondynamodb.ConditionalUpdate{
+			Key:       blobMetadataKey(metadata.GetBlobKey()),
+			Item:      item,
+			Condition: condition,
+		}
+	}
+
+	failedKeys, err := s.dynamoDBClient.UpdateItemsWithCondition(ctx, s.tableName, updates)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]disperser.BlobKey, 0, len(failedKeys))
+	for _, key := range failedKeys {
+		blobKey := disperser.BlobKey{}
+		if err := attributevalue.UnmarshalMap(key, &blobKey); err != nil {
+			return nil, err
+		}
+		keys = append(keys, blobKey)
+	}
+	return keys, nil
+}
+
+// blobMetadataKey returns the primary key of the metadata of the blob
+func blobMetadataKey(blobKey disperser.BlobKey) commondynamodb.Key {
+	return commondynamodb.Key{
+		"BlobHash": &types.AttributeValueMemberS{
+			Value: blobKey.BlobHash,
+		},
+		"MetadataHash": &types.AttributeValueMemberS{
+			Value: blobKey.MetadataHash,
+		},
+	}
+}
+
+// GetBlobMetadataByCommitment returns the metadata of the blobs whose confirmation info carries the given commitment,
+// ordered by the time they were requested, upto the specified limit. It also returns a pagination token that can be
+// used to fetch the next set of items, which is nil once all the items have been fetched.
+// Only blobs that have gone through batch confirmation carry a commitment, so blobs still being processed are never returned.
+func (s *BlobMetadataStore) GetBlobMetadataByCommitment(ctx context.Context, commitment *core.G1Commitment, limit int32, exclusiveStartKey *disperser.BlobStoreExclusiveStartKey) ([]*disperser.BlobMetadata, *disperser.BlobStoreExclusiveStartKey, error) {
+	var attributeMap map[string]types.AttributeValue
+	if exclusiveStartKey != nil {
+		attributeMap = commitmentIndexStartKey(commitment, exclusiveStartKey)
+	}
+
+	queryResult, err := s.dynamoDBClient.QueryIndexWithPagination(ctx, s.tableName, commitmentIndexName, "CommitmentKey = :commitment", commondynamodb.ExpresseionValues{
+		":commitment": &types.AttributeValueMemberB{
+			Value: commitmentKey(commitment),
+		},
+	}, limit, attributeMap)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadatas := make([]*disperser.BlobMetadata, len(queryResult.Items))
+	for i, item := range queryResult.Items {
+		metadatas[i], err = UnmarshalBlobMetadata(item)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if queryResult.LastEvaluatedKey == nil {
+		return metadatas, nil, nil
+	}
+	exclusiveStartKey, err = convertToExclusiveStartKey(queryResult.LastEvaluatedKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return metadatas, exclusiveStartKey, nil
+}
+
+// GetBlobMetadataByBlobHash returns the metadata of all the blobs with the given blob hash
+func (s *BlobMetadataStore) GetBlobMetadataByBlobHash(ctx context.Context, blobHash disperser.BlobHash) ([]*disperser.BlobMetadata, error) {
+	items, err := s.dynamoDBClient.Query(ctx, s.tableName, "BlobHash = :blobHash", commondynamodb.ExpresseionValues{
+		":blobHash": &types.AttributeValueMemberS{
+			Value: blobHash,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metadatas := make([]*disperser.BlobMetadata, len(items))
+	for i, item := range items {
+		metadatas[i], err = UnmarshalBlobMetadata(item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return metadatas, nil
+}
+
 func (s *BlobMetadataStore) SetBlobStatus(ctx context.Context, metadataKey disperser.BlobKey, status disperser.BlobStatus) error {
 	_, err := s.dynamoDBClient.UpdateItem(ctx, s.tableName, map[string]types.AttributeValue{
 		"BlobHash": &types.AttributeValueMemberS{
@@ -292,6 +645,14 @@ func GenerateTableSchema(metadataTableName string, readCapacityUnits int64, writ
 				AttributeName: aws.String("BlobIndex"),
 				AttributeType: types.ScalarAttributeTypeN,
 			},
+			{
+				AttributeName: aws.String("BatchID"),
+				AttributeType: types.ScalarAttributeTypeN,
+			},
+			{
+				AttributeName: aws.String("CommitmentKey"),
+				AttributeType: types.ScalarAttributeTypeB,
+			},
 		},
 		KeySchema: []types.KeySchemaElement{
 			{
@@ -345,6 +706,46 @@ func GenerateTableSchema(metadataTableName string, readCapacityUnits int64, writ
 					WriteCapacityUnits: aws.Int64(writeCapacityUnits),
 				},
 			},
+			{
+				IndexName: aws.String(batchIDIndexName),
+				KeySchema: []types.KeySchemaElement{
+					{
+						AttributeName: aws.String("BatchID"),
+						KeyType:       types.KeyTypeHash,
+					},
+					{
+						AttributeName: aws.String("BlobIndex"),
+						KeyType:       types.KeyTypeRange,
+					},
+				},
+				Projection: &types.Projection{
+					ProjectionType: types.ProjectionTypeAll,
+				},
+				ProvisionedThroughput: &types.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(readCapacityUnits),
+					WriteCapacityUnits: aws.Int64(writeCapacityUnits),
+				},
+			},
+			{
+				IndexName: aws.String(commitmentIndexName),
+				KeySchema: []types.KeySchemaElement{
+					{
+						AttributeName: aws.String("CommitmentKey"),
+						KeyType:       types.KeyTypeHash,
+					},
+					{
+						AttributeName: aws.String("RequestedAt"),
+						KeyType:       types.KeyTypeRange,
+					},
+				},
+				Projection: &types.Projection{
+					ProjectionType: types.ProjectionTypeAll,
+				},
+				ProvisionedThroughput: &types.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(readCapacityUnits),
+					WriteCapacityUnits: aws.Int64(writeCapacityUnits),
+				},
+			},
 		},
 		ProvisionedThroughput: &types.ProvisionedThroughput{
 			ReadCapacityUnits:  aws.Int64(readCapacityUnits),
@@ -382,14 +783,53 @@ func MarshalBlobMetadata(metadata *disperser.BlobMetadata) (commondynamodb.Item,
 		return nil, err
 	}
 
-	// Flatten the confirmation info
-	for k, v := range confirmationInfo {
-		basicFields[k] = v
+	// Flatten the fields of the confirmation info the blobs are indexed by. The whole confirmation info is stored in its
+	// compact encoding.
+	for _, k := range confirmationInfoIndexedFields {
+		basicFields[k] = confirmationInfo[k]
+	}
+	compactConfirmationInfo, err := metadata.ConfirmationInfo.EncodeCompact()
+	if err != nil {
+		return nil, err
+	}
+	basicFields[compactConfirmationInfoField] = &types.AttributeValueMemberB{
+		Value: compactConfirmationInfo,
+	}
+
+	// Index the blob by its commitment
+	if metadata.ConfirmationInfo.BlobCommitment != nil && metadata.ConfirmationInfo.BlobCommitment.Commitment != nil {
+		basicFields["CommitmentKey"] = &types.AttributeValueMemberB{
+			Value: commitmentKey(metadata.ConfirmationInfo.BlobCommitment.Commitment),
+		}
 	}
 
 	return basicFields, nil
 }
 
+// commitmentKey returns the key a commitment is indexed by, which is the concatenation of its coordinates
+func commitmentKey(commitment *core.G1Commitment) []byte {
+	return append(commitment.X.Marshal(), commitment.Y.Marshal()...)
+}
+
+// commitmentIndexStartKey returns the exclusive start key of a query of the commitment index. The key of an item in the
+// index is made of the keys of the table and of the index.
+func commitmentIndexStartKey(commitment *core.G1Commitment, exclusiveStartKey *disperser.BlobStoreExclusiveStartKey) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"BlobHash": &types.AttributeValueMemberS{
+			Value: exclusiveStartKey.BlobHash,
+		},
+		"MetadataHash": &types.AttributeValueMemberS{
+			Value: exclusiveStartKey.MetadataHash,
+		},
+		"CommitmentKey": &types.AttributeValueMemberB{
+			Value: commitmentKey(commitment),
+		},
+		"RequestedAt": &types.AttributeValueMemberN{
+			Value: strconv.FormatInt(exclusiveStartKey.RequestedAt, 10),
+		},
+	}
+}
+
 func UnmarshalBlobMetadata(item commondynamodb.Item) (*disperser.BlobMetadata, error) {
 	metadata := disperser.BlobMetadata{}
 	err := attributevalue.UnmarshalMap(item, &metadata)
@@ -403,7 +843,16 @@ func UnmarshalBlobMetadata(item commondynamodb.Item) (*disperser.BlobMetadata, e
 		return nil, err
 	}
 	metadata.RequestMetadata = &requestMetadata
-	if metadata.BlobStatus != disperser.Confirmed && metadata.BlobStatus != disperser.Finalized {
+	if metadata.BlobStatus != disperser.Confirmed && metadata.BlobStatus != disperser.Finalized && metadata.BlobStatus != disperser.Pruned && metadata.BlobStatus != disperser.InsufficientSignatures && metadata.BlobStatus != disperser.BatchIDPending {
+		return &metadata, nil
+	}
+
+	// Items written by earlier releases have the confirmation info flattened instead of compactly encoded
+	if compactConfirmationInfo, ok := item[compactConfirmationInfoField].(*types.AttributeValueMemberB); ok {
+		metadata.ConfirmationInfo, err = disperser.DecodeCompactConfirmationInfo(compactConfirmationInfo.Value)
+		if err != nil {
+			return nil, err
+		}
 		return &metadata, nil
 	}
 
@@ -8,6 +8,8 @@ import (
 	commondynamodb "github.com/Layr-Labs/eigenda/common/aws/dynamodb"
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/disperser"
+	"github.com/Layr-Labs/eigenda/disperser/common/blobstore"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
 	"github.com/ethereum/go-ethereum/common"
@@ -98,6 +100,15 @@ func TestBlobMetadataStoreOperations(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, int32(1), confirmedCount)
 
+	inBatch, err := blobMetadataStore.GetBlobMetadataByBatchID(ctx, confirmedMetadata.ConfirmationInfo.BatchID)
+	assert.NoError(t, err)
+	assert.Len(t, inBatch, 1)
+	assert.Equal(t, confirmedMetadata, inBatch[0])
+
+	inBatch, err = blobMetadataStore.GetBlobMetadataByBatchID(ctx, confirmedMetadata.ConfirmationInfo.BatchID+1)
+	assert.NoError(t, err)
+	assert.Len(t, inBatch, 0)
+
 	deleteItems(t, []commondynamodb.Key{
 		{
 			"MetadataHash": &types.AttributeValueMemberS{Value: blobKey1.MetadataHash},
@@ -110,6 +121,133 @@ func TestBlobMetadataStoreOperations(t *testing.T) {
 	})
 }
 
+func TestBlobMetadataStoreIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	blobKey1 := disperser.BlobKey{
+		BlobHash:     blobHash,
+		MetadataHash: "idempotent-hash",
+	}
+	blobKey2 := disperser.BlobKey{
+		BlobHash:     "blob2",
+		MetadataHash: "idempotent-hash2",
+	}
+	metadata1 := &disperser.BlobMetadata{
+		MetadataHash: blobKey1.MetadataHash,
+		BlobHash:     blobKey1.BlobHash,
+		BlobStatus:   disperser.Processing,
+		RequestMetadata: &disperser.RequestMetadata{
+			BlobRequestHeader: blob.RequestHeader,
+			BlobSize:          blobSize,
+			RequestedAt:       123,
+		},
+	}
+	idempotencyKey := disperser.ScopeIdempotencyKey("account", "key")
+
+	_, err := blobMetadataStore.GetBlobMetadataByIdempotencyKey(ctx, idempotencyKey, "payload")
+	assert.ErrorIs(t, err, disperser.ErrBlobNotFound)
+
+	claimedKey, err := blobMetadataStore.ClaimIdempotencyKey(ctx, idempotencyKey, blobKey1, "payload")
+	assert.NoError(t, err)
+	assert.Equal(t, blobKey1, claimedKey)
+	err = blobMetadataStore.QueueNewBlobMetadata(ctx, metadata1)
+	assert.NoError(t, err)
+
+	// the key can't be claimed again
+	claimedKey, err = blobMetadataStore.ClaimIdempotencyKey(ctx, idempotencyKey, blobKey2, "payload")
+	assert.ErrorIs(t, err, disperser.ErrIdempotencyKeyExists)
+	assert.Equal(t, blobKey1, claimedKey)
+	claimedKey, err = blobMetadataStore.ClaimIdempotencyKey(ctx, idempotencyKey, blobKey2, "other payload")
+	assert.ErrorIs(t, err, disperser.ErrIdempotencyKeyMismatch)
+	assert.Equal(t, blobKey1, claimedKey)
+
+	fetchedMetadata, err := blobMetadataStore.GetBlobMetadataByIdempotencyKey(ctx, idempotencyKey, "payload")
+	assert.NoError(t, err)
+	assert.Equal(t, metadata1, fetchedMetadata)
+	_, err = blobMetadataStore.GetBlobMetadataByIdempotencyKey(ctx, idempotencyKey, "other payload")
+	assert.ErrorIs(t, err, disperser.ErrIdempotencyKeyMismatch)
+
+	err = blobMetadataStore.ReleaseIdempotencyKey(ctx, idempotencyKey)
+	assert.NoError(t, err)
+	_, err = blobMetadataStore.GetBlobMetadataByIdempotencyKey(ctx, idempotencyKey, "payload")
+	assert.ErrorIs(t, err, disperser.ErrBlobNotFound)
+
+	deleteItems(t, []commondynamodb.Key{
+		{
+			"MetadataHash": &types.AttributeValueMemberS{Value: blobKey1.MetadataHash},
+			"BlobHash":     &types.AttributeValueMemberS{Value: blobKey1.BlobHash},
+		},
+	})
+}
+
+func TestBlobMetadataStoreUpdateBlobMetadatasIfStatus(t *testing.T) {
+	ctx := context.Background()
+	confirmedKey := disperser.BlobKey{
+		BlobHash:     blobHash,
+		MetadataHash: "confirmed",
+	}
+	revertedKey := disperser.BlobKey{
+		BlobHash:     blobHash,
+		MetadataHash: "reverted",
+	}
+	confirmed := getConfirmedMetadata(t, confirmedKey)
+	reverted := getConfirmedMetadata(t, revertedKey)
+	reverted.BlobStatus = disperser.Processing
+	err := blobMetadataStore.QueueNewBlobMetadata(ctx, confirmed)
+	assert.NoError(t, err)
+	err = blobMetadataStore.QueueNewBlobMetadata(ctx, reverted)
+	assert.NoError(t, err)
+
+	finalized := make([]*disperser.BlobMetadata, 0, 2)
+	for _, metadata := range []*disperser.BlobMetadata{confirmed, reverted} {
+		updated := *metadata
+		updated.BlobStatus = disperser.Finalized
+		finalized = append(finalized, &updated)
+	}
+	notConfirmed, err := blobMetadataStore.UpdateBlobMetadatasIfStatus(ctx, finalized, disperser.Confirmed)
+	assert.NoError(t, err)
+	assert.Equal(t, []disperser.BlobKey{revertedKey}, notConfirmed)
+
+	// only the blob that was still confirmed is updated
+	fetchedMetadata, err := blobMetadataStore.GetBlobMetadata(ctx, confirmedKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Finalized, fetchedMetadata.BlobStatus)
+	fetchedMetadata, err = blobMetadataStore.GetBlobMetadata(ctx, revertedKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Processing, fetchedMetadata.BlobStatus)
+
+	deleteItems(t, []commondynamodb.Key{
+		{
+			"MetadataHash": &types.AttributeValueMemberS{Value: confirmedKey.MetadataHash},
+			"BlobHash":     &types.AttributeValueMemberS{Value: confirmedKey.BlobHash},
+		},
+		{
+			"MetadataHash": &types.AttributeValueMemberS{Value: revertedKey.MetadataHash},
+			"BlobHash":     &types.AttributeValueMemberS{Value: revertedKey.BlobHash},
+		},
+	})
+}
+
+func TestBlobMetadataStoreBatchAggregateSignature(t *testing.T) {
+	ctx := context.Background()
+	_, err := blobMetadataStore.GetBatchAggregateSignature(ctx, 7)
+	assert.ErrorIs(t, err, disperser.ErrBatchNotFound)
+
+	err = blobMetadataStore.PutBatchAggregateSignature(ctx, 7, []byte{1, 2, 3})
+	assert.NoError(t, err)
+	signatureAggregation, err := blobMetadataStore.GetBatchAggregateSignature(ctx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, signatureAggregation)
+	_, err = blobMetadataStore.GetBatchAggregateSignature(ctx, 8)
+	assert.ErrorIs(t, err, disperser.ErrBatchNotFound)
+
+	deleteItems(t, []commondynamodb.Key{
+		{
+			"MetadataHash": &types.AttributeValueMemberS{Value: "BatchAggregateSignature"},
+			"BlobHash":     &types.AttributeValueMemberS{Value: "BatchAggregateSignature#7"},
+		},
+	})
+}
+
 func TestBlobMetadataStoreOperationsWithPagination(t *testing.T) {
 	ctx := context.Background()
 	blobKey1 := disperser.BlobKey{
@@ -196,6 +334,57 @@ func TestBlobMetadataStoreOperationsWithPaginationNoStoredBlob(t *testing.T) {
 	assert.Nil(t, lastEvaluatedKey)
 }
 
+func TestBlobMetadataStoreGetBlobMetadataByCommitment(t *testing.T) {
+	ctx := context.Background()
+	blobKey1 := disperser.BlobKey{
+		BlobHash:     "commitmentblob1",
+		MetadataHash: "hash1",
+	}
+	blobKey2 := disperser.BlobKey{
+		BlobHash:     "commitmentblob2",
+		MetadataHash: "hash2",
+	}
+	metadata1 := getConfirmedMetadata(t, blobKey1)
+	metadata1.RequestMetadata.RequestedAt = 1
+	metadata2 := getConfirmedMetadata(t, blobKey2)
+	metadata2.RequestMetadata.RequestedAt = 2
+	err := blobMetadataStore.QueueNewBlobMetadata(ctx, metadata1)
+	assert.NoError(t, err)
+	err = blobMetadataStore.QueueNewBlobMetadata(ctx, metadata2)
+	assert.NoError(t, err)
+
+	// the blobs with the same commitment are returned by request time, one page at a time
+	commitment := metadata1.ConfirmationInfo.BlobCommitment.Commitment
+	fetched, exclusiveStartKey, err := blobMetadataStore.GetBlobMetadataByCommitment(ctx, commitment, 1, nil)
+	assert.NoError(t, err)
+	assert.Len(t, fetched, 1)
+	assert.Equal(t, blobKey1, fetched[0].GetBlobKey())
+	assert.NotNil(t, exclusiveStartKey)
+
+	fetched, exclusiveStartKey, err = blobMetadataStore.GetBlobMetadataByCommitment(ctx, commitment, 1, exclusiveStartKey)
+	assert.NoError(t, err)
+	assert.Len(t, fetched, 1)
+	assert.Equal(t, blobKey2, fetched[0].GetBlobKey())
+
+	if exclusiveStartKey != nil {
+		fetched, exclusiveStartKey, err = blobMetadataStore.GetBlobMetadataByCommitment(ctx, commitment, 1, exclusiveStartKey)
+		assert.NoError(t, err)
+		assert.Len(t, fetched, 0)
+		assert.Nil(t, exclusiveStartKey)
+	}
+
+	deleteItems(t, []commondynamodb.Key{
+		{
+			"MetadataHash": &types.AttributeValueMemberS{Value: blobKey1.MetadataHash},
+			"BlobHash":     &types.AttributeValueMemberS{Value: blobKey1.BlobHash},
+		},
+		{
+			"MetadataHash": &types.AttributeValueMemberS{Value: blobKey2.MetadataHash},
+			"BlobHash":     &types.AttributeValueMemberS{Value: blobKey2.BlobHash},
+		},
+	})
+}
+
 func deleteItems(t *testing.T, keys []commondynamodb.Key) {
 	_, err := dynamoClient.DeleteItems(context.Background(), metadataTableName, keys)
 	assert.NoError(t, err)
@@ -253,3 +442,51 @@ func getConfirmedMetadata(t *testing.T, metadataKey disperser.BlobKey) *disperse
 		},
 	}
 }
+
+func TestMarshalBlobMetadataCompactConfirmationInfo(t *testing.T) {
+	metadata := &disperser.BlobMetadata{
+		MetadataHash: "hash",
+		BlobHash:     blobHash,
+		BlobStatus:   disperser.Confirmed,
+		RequestMetadata: &disperser.RequestMetadata{
+			BlobRequestHeader: blob.RequestHeader,
+			BlobSize:          blobSize,
+			RequestedAt:       123,
+		},
+		ConfirmationInfo: &disperser.ConfirmationInfo{
+			BatchHeaderHash:    [32]byte{1, 2, 3},
+			BlobIndex:          2,
+			BatchID:            7,
+			BlobInclusionProof: []byte{1, 2, 3, 4},
+			QuorumResults: map[core.QuorumID]*core.QuorumResult{
+				0: {QuorumID: 0, PercentSigned: 100},
+			},
+			ConfirmedAt: 456,
+		},
+	}
+
+	item, err := blobstore.MarshalBlobMetadata(metadata)
+	assert.NoError(t, err)
+	assert.IsType(t, &types.AttributeValueMemberB{}, item["CompactConfirmationInfo"])
+	assert.Equal(t, &types.AttributeValueMemberB{Value: metadata.ConfirmationInfo.BatchHeaderHash[:]}, item["BatchHeaderHash"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "2"}, item["BlobIndex"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "7"}, item["BatchID"])
+	assert.NotContains(t, item, "BlobInclusionProof")
+	unmarshalled, err := blobstore.UnmarshalBlobMetadata(item)
+	assert.NoError(t, err)
+	assert.Equal(t, metadata, unmarshalled)
+
+	// items written by earlier releases have the confirmation info flattened
+	legacyItem, err := attributevalue.MarshalMap(metadata)
+	assert.NoError(t, err)
+	for _, fields := range []interface{}{metadata.RequestMetadata, metadata.ConfirmationInfo} {
+		flattened, err := attributevalue.MarshalMap(fields)
+		assert.NoError(t, err)
+		for k, v := range flattened {
+			legacyItem[k] = v
+		}
+	}
+	unmarshalled, err = blobstore.UnmarshalBlobMetadata(legacyItem)
+	assert.NoError(t, err)
+	assert.Equal(t, metadata, unmarshalled)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Layr-Labs/eigenda/common"
@@ -17,8 +18,20 @@ import (
 
 const (
 	maxS3BlobFetchWorkers = 64
+	// The interval at which the metadata of watched blobs is polled for updates
+	watchPollInterval = time.Second
+	// The number of updates buffered for each watcher of a blob
+	watchBufferSize = 16
 )
 
+// unconfirmedStatuses are the statuses of the blobs that BatchMarkBlobsConfirmed may confirm
+var unconfirmedStatuses = []disperser.BlobStatus{
+	disperser.Processing,
+	disperser.Failed,
+	disperser.InsufficientSignatures,
+	disperser.BatchIDPending,
+}
+
 // The shared blob store that the disperser is operating on.
 // The metadata store is backed by DynamoDB and the blob store is backed by S3.
 //
@@ -43,6 +56,16 @@ type SharedBlobStore struct {
 	s3Client          s3.Client
 	blobMetadataStore *BlobMetadataStore
 	logger            common.Logger
+
+	watchesMu sync.Mutex
+	watches   map[disperser.BlobKey]*blobWatch
+}
+
+// blobWatch polls the metadata of a watched blob on behalf of all of its watchers, so that a blob is polled once
+// however many streams watch it
+type blobWatch struct {
+	watchers []chan *disperser.BlobMetadata
+	cancel   context.CancelFunc
 }
 
 type Config struct {
@@ -69,6 +92,7 @@ func NewSharedStorage(bucketName string, s3Client s3.Client, blobMetadataStore *
 		s3Client:          s3Client,
 		blobMetadataStore: blobMetadataStore,
 		logger:            logger,
+		watches:           make(map[disperser.BlobKey]*blobWatch),
 	}
 }
 
@@ -87,9 +111,28 @@ func (s *SharedBlobStore) StoreBlob(ctx context.Context, blob *core.Blob, reques
 	metadataKey.BlobHash = blobHash
 	metadataKey.MetadataHash = metadataHash
 
+	// the idempotency key is claimed first, so that concurrent requests with the same key store a single blob
+	idempotencyKey := blob.RequestHeader.IdempotencyKey
+	if idempotencyKey != "" {
+		claimedKey, err := s.blobMetadataStore.ClaimIdempotencyKey(ctx, idempotencyKey, metadataKey, disperser.GetIdempotencyPayloadHash(blob))
+		if err != nil {
+			return claimedKey, err
+		}
+	}
+	// releaseIdempotencyKey lets the request be retried with the same idempotency key if the blob fails to be stored
+	releaseIdempotencyKey := func() {
+		if idempotencyKey == "" {
+			return
+		}
+		if err := s.blobMetadataStore.ReleaseIdempotencyKey(ctx, idempotencyKey); err != nil {
+			s.logger.Error("error releasing idempotency key", "err", err)
+		}
+	}
+
 	err = s.s3Client.UploadObject(ctx, s.bucketName, blobObjectKey(blobHash), blob.Data)
 	if err != nil {
 		s.logger.Error("error uploading blob", "err", err)
+		releaseIdempotencyKey()
 		return metadataKey, err
 	}
 
@@ -113,6 +156,7 @@ func (s *SharedBlobStore) StoreBlob(ctx context.Context, blob *core.Blob, reques
 	err = s.blobMetadataStore.QueueNewBlobMetadata(ctx, &metadata)
 	if err != nil {
 		s.logger.Error("error uploading blob metadata", "err", err)
+		releaseIdempotencyKey()
 		return metadataKey, err
 	}
 
@@ -156,6 +200,82 @@ func (s *SharedBlobStore) MarkBlobConfirmed(ctx context.Context, existingMetadat
 	return &newMetadata, s.blobMetadataStore.UpdateBlobMetadata(ctx, existingMetadata.GetBlobKey(), &newMetadata)
 }
 
+// BatchMarkBlobsConfirmed reads the metadata of the blobs in batched reads, and confirms the blobs that aren't confirmed
+// yet with conditional writes, so that a blob confirmed concurrently is never overwritten
+func (s *SharedBlobStore) BatchMarkBlobsConfirmed(ctx context.Context, writes []disperser.ConfirmationWrite) error {
+	var failedKeys []disperser.BlobKey
+	var firstErr error
+	fail := func(key disperser.BlobKey, err error) {
+		failedKeys = append(failedKeys, key)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	blobKeys := make([]disperser.BlobKey, len(writes))
+	for i, write := range writes {
+		blobKeys[i] = write.Metadata.GetBlobKey()
+	}
+	refreshedMetadatas, err := s.blobMetadataStore.GetBulkBlobMetadata(ctx, blobKeys)
+	if err != nil {
+		s.logger.Error("[BatchMarkBlobsConfirmed] error getting blob metadata", "err", err)
+		return &disperser.BatchConfirmationError{FailedKeys: blobKeys, Err: err}
+	}
+	refreshed := make(map[disperser.BlobKey]*disperser.BlobMetadata, len(refreshedMetadatas))
+	for _, metadata := range refreshedMetadatas {
+		refreshed[metadata.GetBlobKey()] = metadata
+	}
+
+	ttlFromNow := uint64(time.Now().Add(s.blobMetadataStore.ttl).Unix())
+	updated := make([]*disperser.BlobMetadata, 0, len(writes))
+	for _, write := range writes {
+		blobKey := write.Metadata.GetBlobKey()
+		refreshedMetadata, ok := refreshed[blobKey]
+		if !ok {
+			s.logger.Error("[BatchMarkBlobsConfirmed] blob metadata not found", "blobKey", blobKey.String())
+			fail(blobKey, disperser.ErrBlobNotFound)
+			continue
+		}
+		// Confirmed blobs are immutable, so only blobs that aren't confirmed yet are written
+		alreadyConfirmed, _ := refreshedMetadata.IsConfirmed()
+		if alreadyConfirmed {
+			s.logger.Warn("[BatchMarkBlobsConfirmed] trying to confirm blob already marked as confirmed", "blobKey", blobKey.String())
+			continue
+		}
+		newMetadata := *write.Metadata
+		if newMetadata.Expiry < ttlFromNow {
+			newMetadata.Expiry = ttlFromNow
+		}
+		newMetadata.BlobStatus = disperser.Confirmed
+		newMetadata.ConfirmationInfo = write.ConfirmationInfo
+		updated = append(updated, &newMetadata)
+	}
+
+	if len(updated) > 0 {
+		confirmedKeys, err := s.blobMetadataStore.UpdateBlobMetadatasIfStatus(ctx, updated, unconfirmedStatuses...)
+		if err != nil {
+			for _, metadata := range updated {
+				fail(metadata.GetBlobKey(), err)
+			}
+		}
+		for _, key := range confirmedKeys {
+			s.logger.Warn("[BatchMarkBlobsConfirmed] blob was confirmed concurrently", "blobKey", key.String())
+		}
+	}
+
+	if len(failedKeys) > 0 {
+		return &disperser.BatchConfirmationError{FailedKeys: failedKeys, Err: firstErr}
+	}
+	return nil
+}
+
+func (s *SharedBlobStore) MarkBlobBatchIDPending(ctx context.Context, existingMetadata *disperser.BlobMetadata, confirmationInfo *disperser.ConfirmationInfo) (*disperser.BlobMetadata, error) {
+	newMetadata := *existingMetadata
+	newMetadata.BlobStatus = disperser.BatchIDPending
+	newMetadata.ConfirmationInfo = confirmationInfo
+	return &newMetadata, s.blobMetadataStore.UpdateBlobMetadata(ctx, existingMetadata.GetBlobKey(), &newMetadata)
+}
+
 func (s *SharedBlobStore) MarkBlobInsufficientSignatures(ctx context.Context, existingMetadata *disperser.BlobMetadata, confirmationInfo *disperser.ConfirmationInfo) (*disperser.BlobMetadata, error) {
 	newMetadata := *existingMetadata
 	newMetadata.BlobStatus = disperser.InsufficientSignatures
@@ -167,6 +287,20 @@ func (s *SharedBlobStore) MarkBlobFinalized(ctx context.Context, metadataKey dis
 	return s.blobMetadataStore.SetBlobStatus(ctx, metadataKey, disperser.Finalized)
 }
 
+var _ disperser.BatchBlobFinalizer = (*SharedBlobStore)(nil)
+
+// BatchMarkBlobsFinalized finalizes the blobs with conditional writes, so that a blob that was reverted or failed since it
+// was read as confirmed is not overwritten
+func (s *SharedBlobStore) BatchMarkBlobsFinalized(ctx context.Context, metadatas []*disperser.BlobMetadata) ([]disperser.BlobKey, error) {
+	updated := make([]*disperser.BlobMetadata, len(metadatas))
+	for i, metadata := range metadatas {
+		newMetadata := *metadata
+		newMetadata.BlobStatus = disperser.Finalized
+		updated[i] = &newMetadata
+	}
+	return s.blobMetadataStore.UpdateBlobMetadatasIfStatus(ctx, updated, disperser.Confirmed)
+}
+
 func (s *SharedBlobStore) MarkBlobProcessing(ctx context.Context, metadataKey disperser.BlobKey) error {
 	return s.blobMetadataStore.SetBlobStatus(ctx, metadataKey, disperser.Processing)
 }
@@ -226,19 +360,163 @@ func (s *SharedBlobStore) GetAllBlobMetadataByBatch(ctx context.Context, batchHe
 	return s.blobMetadataStore.GetAllBlobMetadataByBatch(ctx, batchHeaderHash)
 }
 
+func (s *SharedBlobStore) GetBlobMetadataByBatchID(ctx context.Context, batchID uint32) ([]*disperser.BlobMetadata, error) {
+	return s.blobMetadataStore.GetBlobMetadataByBatchID(ctx, batchID)
+}
+
+func (s *SharedBlobStore) StoreBatchAggregateSignature(ctx context.Context, batchID uint32, aggSig *core.SignatureAggregation) error {
+	data, err := aggSig.Serialize()
+	if err != nil {
+		return fmt.Errorf("failed to serialize the aggregate signature: %w", err)
+	}
+	return s.blobMetadataStore.PutBatchAggregateSignature(ctx, batchID, data)
+}
+
+func (s *SharedBlobStore) GetBatchAggregateSignature(ctx context.Context, batchID uint32) (*core.SignatureAggregation, error) {
+	data, err := s.blobMetadataStore.GetBatchAggregateSignature(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	aggSig, err := new(core.SignatureAggregation).Deserialize(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize the aggregate signature: %w", err)
+	}
+	return aggSig, nil
+}
+
+func (s *SharedBlobStore) GetBlobMetadataByCommitment(ctx context.Context, commitment *core.G1Commitment, limit int32, exclusiveStartKey *disperser.BlobStoreExclusiveStartKey) ([]*disperser.BlobMetadata, *disperser.BlobStoreExclusiveStartKey, error) {
+	return s.blobMetadataStore.GetBlobMetadataByCommitment(ctx, commitment, limit, exclusiveStartKey)
+}
+
+// GetBlobMetadataByContentHash returns the metadata of all the blobs dispersed by the requester whose content has the
+// given hash. Blobs are keyed by the hash of their content, so this looks up the blobs sharing the partition key.
+func (s *SharedBlobStore) GetBlobMetadataByContentHash(ctx context.Context, contentHash disperser.BlobHash, requester string) ([]*disperser.BlobMetadata, error) {
+	metadatas, err := s.blobMetadataStore.GetBlobMetadataByBlobHash(ctx, contentHash)
+	if err != nil {
+		return nil, err
+	}
+	requested := make([]*disperser.BlobMetadata, 0, len(metadatas))
+	for _, metadata := range metadatas {
+		if metadata.RequestMetadata != nil && metadata.RequestMetadata.Requester == requester {
+			requested = append(requested, metadata)
+		}
+	}
+	return requested, nil
+}
+
+func (s *SharedBlobStore) GetBlobMetadataByIdempotencyKey(ctx context.Context, idempotencyKey string, payloadHash string) (*disperser.BlobMetadata, error) {
+	return s.blobMetadataStore.GetBlobMetadataByIdempotencyKey(ctx, idempotencyKey, payloadHash)
+}
+
 // GetMetadata returns a blob metadata given a metadata key
 func (s *SharedBlobStore) GetBlobMetadata(ctx context.Context, metadataKey disperser.BlobKey) (*disperser.BlobMetadata, error) {
 	return s.blobMetadataStore.GetBlobMetadata(ctx, metadataKey)
 }
 
-func (s *SharedBlobStore) HandleBlobFailure(ctx context.Context, metadata *disperser.BlobMetadata, maxRetry uint) error {
+// WatchBlobStatus returns a channel that receives the metadata of the blob whenever its status or retry count changes.
+// The blobs are updated by the batcher, which runs as a separate process, so the metadata store is polled for changes.
+// The watchers of a blob share a single poller, which stops once the last of them is done.
+func (s *SharedBlobStore) WatchBlobStatus(ctx context.Context, metadataKey disperser.BlobKey) (<-chan *disperser.BlobMetadata, error) {
+	last, err := s.blobMetadataStore.GetBlobMetadata(ctx, metadataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(chan *disperser.BlobMetadata, watchBufferSize)
+	s.watchesMu.Lock()
+	watch, ok := s.watches[metadataKey]
+	if !ok {
+		pollCtx, cancel := context.WithCancel(context.Background())
+		watch = &blobWatch{cancel: cancel}
+		s.watches[metadataKey] = watch
+		go s.pollBlobStatus(pollCtx, watch, metadataKey, last)
+	}
+	watch.watchers = append(watch.watchers, updates)
+	s.watchesMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.watchesMu.Lock()
+		defer s.watchesMu.Unlock()
+		for i, watcher := range watch.watchers {
+			if watcher == updates {
+				watch.watchers = append(watch.watchers[:i], watch.watchers[i+1:]...)
+				break
+			}
+		}
+		if len(watch.watchers) == 0 {
+			watch.cancel()
+			delete(s.watches, metadataKey)
+		}
+		close(updates)
+	}()
+	return updates, nil
+}
+
+// pollBlobStatus polls the metadata of the blob until ctx is done, and sends it to the watchers of the blob whenever
+// its status or retry count changes
+func (s *SharedBlobStore) pollBlobStatus(ctx context.Context, watch *blobWatch, metadataKey disperser.BlobKey, last *disperser.BlobMetadata) {
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		metadata, err := s.blobMetadataStore.GetBlobMetadata(ctx, metadataKey)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Warn("[WatchBlobStatus] error getting blob metadata", "blobKey", metadataKey.String(), "err", err)
+			}
+			continue
+		}
+		if metadata.BlobStatus == last.BlobStatus && metadata.NumRetries == last.NumRetries {
+			continue
+		}
+		last = metadata
+		s.notifyWatchers(watch, metadata)
+	}
+}
+
+// notifyWatchers sends a snapshot of the metadata to the watchers of the blob. A watcher that falls behind loses its
+// oldest pending update, so that the latest update is always delivered.
+func (s *SharedBlobStore) notifyWatchers(watch *blobWatch, metadata *disperser.BlobMetadata) {
+	s.watchesMu.Lock()
+	defer s.watchesMu.Unlock()
+	for _, watcher := range watch.watchers {
+		snapshot := *metadata
+		select {
+		case watcher <- &snapshot:
+			continue
+		default:
+		}
+		select {
+		case <-watcher:
+		default:
+		}
+		watcher <- &snapshot
+	}
+}
+
+func (s *SharedBlobStore) HandleBlobFailure(ctx context.Context, metadata *disperser.BlobMetadata, maxRetry uint) (bool, error) {
 	if metadata.NumRetries < maxRetry {
-		return s.IncrementBlobRetryCount(ctx, metadata)
+		return true, s.IncrementBlobRetryCount(ctx, metadata)
 	} else {
-		return s.MarkBlobFailed(ctx, metadata.GetBlobKey())
+		return false, s.MarkBlobFailed(ctx, metadata.GetBlobKey())
 	}
 }
 
+func (s *SharedBlobStore) PruneConfirmationInfo(ctx context.Context, existingMetadata *disperser.BlobMetadata) error {
+	if existingMetadata.BlobStatus != disperser.Finalized || existingMetadata.ConfirmationInfo == nil {
+		return fmt.Errorf("%w: blob %s is in status %s", disperser.ErrBlobNotFinalized, existingMetadata.GetBlobKey().String(), existingMetadata.BlobStatus.String())
+	}
+	newMetadata := *existingMetadata
+	newMetadata.BlobStatus = disperser.Pruned
+	newMetadata.ConfirmationInfo = existingMetadata.ConfirmationInfo.Pruned()
+	return s.blobMetadataStore.UpdateBlobMetadata(ctx, existingMetadata.GetBlobKey(), &newMetadata)
+}
+
 func getMetadataHash(requestedAt uint64, securityParams []*core.SecurityParam) (string, error) {
 	var str string
 	str = fmt.Sprintf("%d/", requestedAt)
@@ -256,8 +534,5 @@ func blobObjectKey(blobHash disperser.BlobHash) string {
 }
 
 func getBlobHash(blob *core.Blob) disperser.BlobHash {
-	hasher := sha256.New()
-	hasher.Write(blob.Data)
-	hash := hasher.Sum(nil)
-	return hex.EncodeToString(hash)
+	return disperser.GetBlobContentHash(blob.Data)
 }
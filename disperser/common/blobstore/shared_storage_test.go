@@ -8,8 +8,10 @@ import (
 	"testing"
 	"time"
 
+	commondynamodb "github.com/Layr-Labs/eigenda/common/aws/dynamodb"
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/disperser"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -196,3 +198,72 @@ func metadataSuffix(requestedAt uint64, securityParams []*core.SecurityParam) (s
 	bytes := []byte(str)
 	return hex.EncodeToString(sha256.New().Sum(bytes)), nil
 }
+
+func TestSharedBlobStoreBatchMarkBlobsConfirmed(t *testing.T) {
+	ctx := context.Background()
+	requestedAt := uint64(time.Now().UnixNano())
+
+	numBlobs := 30
+	size := uint(len(blob.Data))
+	metas := make([]*disperser.BlobMetadata, numBlobs)
+	for i := 0; i < numBlobs; i++ {
+		blobKey, err := sharedStorage.StoreBlob(ctx, blob, requestedAt+uint64(i))
+		assert.Nil(t, err)
+		metas[i], err = sharedStorage.GetBlobMetadata(ctx, blobKey)
+		assert.Nil(t, err)
+	}
+
+	// The first blob is already confirmed in batch 7
+	_, err := sharedStorage.MarkBlobConfirmed(ctx, metas[0], &disperser.ConfirmationInfo{
+		BatchHeaderHash: [32]byte{7},
+		BatchID:         7,
+		BlobCommitment:  &core.BlobCommitments{},
+	})
+	assert.Nil(t, err)
+
+	// The confirmations span more than one BatchWriteItem request
+	writes := make([]disperser.ConfirmationWrite, numBlobs)
+	for i, meta := range metas {
+		writes[i] = disperser.ConfirmationWrite{
+			Metadata: meta,
+			ConfirmationInfo: &disperser.ConfirmationInfo{
+				BatchHeaderHash: [32]byte{8},
+				BlobIndex:       uint32(i),
+				BatchID:         8,
+				BlobCommitment:  &core.BlobCommitments{},
+			},
+		}
+	}
+	err = sharedStorage.BatchMarkBlobsConfirmed(ctx, writes)
+	assert.Nil(t, err)
+
+	// Confirmed blobs are immutable, so the first blob stays in batch 7
+	meta, err := sharedStorage.GetBlobMetadata(ctx, metas[0].GetBlobKey())
+	assert.Nil(t, err)
+	assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
+	assert.Equal(t, uint32(7), meta.ConfirmationInfo.BatchID)
+	for i := 1; i < numBlobs; i++ {
+		meta, err := sharedStorage.GetBlobMetadata(ctx, metas[i].GetBlobKey())
+		assert.Nil(t, err)
+		assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
+		assert.Equal(t, uint32(8), meta.ConfirmationInfo.BatchID)
+		assert.Equal(t, uint32(i), meta.ConfirmationInfo.BlobIndex)
+		assert.Equal(t, size, meta.RequestMetadata.BlobSize)
+	}
+
+	// A blob that isn't in the store fails
+	unknown := &disperser.BlobMetadata{BlobHash: "unknown", MetadataHash: "unknown"}
+	err = sharedStorage.BatchMarkBlobsConfirmed(ctx, []disperser.ConfirmationWrite{{Metadata: unknown, ConfirmationInfo: &disperser.ConfirmationInfo{BatchID: 8}}})
+	var batchErr *disperser.BatchConfirmationError
+	assert.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, []disperser.BlobKey{unknown.GetBlobKey()}, batchErr.FailedKeys)
+
+	keys := make([]commondynamodb.Key, numBlobs)
+	for i, meta := range metas {
+		keys[i] = commondynamodb.Key{
+			"MetadataHash": &types.AttributeValueMemberS{Value: meta.MetadataHash},
+			"BlobHash":     &types.AttributeValueMemberS{Value: meta.BlobHash},
+		}
+	}
+	deleteItems(t, keys)
+}
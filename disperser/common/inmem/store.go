@@ -4,8 +4,10 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"sort"
 	"strconv"
+	"sync"
 
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/disperser"
@@ -15,6 +17,22 @@ import (
 type BlobStore struct {
 	Blobs    map[disperser.BlobHash]*BlobHolder
 	Metadata map[disperser.BlobKey]*disperser.BlobMetadata
+
+	watchersMu sync.Mutex
+	watchers   map[disperser.BlobKey][]chan *disperser.BlobMetadata
+
+	idempotencyKeysMu sync.Mutex
+	idempotencyKeys   map[string]idempotencyKeyClaim
+
+	// aggregateSignatures holds the serialized aggregate signature of each confirmed batch by batch ID
+	aggregateSignaturesMu sync.Mutex
+	aggregateSignatures   map[uint32][]byte
+}
+
+// idempotencyKeyClaim is the blob that claimed an idempotency key along with the hash of its payload
+type idempotencyKeyClaim struct {
+	blobKey     disperser.BlobKey
+	payloadHash string
 }
 
 // BlobHolder stores the blob along with its status and any other metadata
@@ -22,6 +40,9 @@ type BlobHolder struct {
 	Data []byte
 }
 
+// watchBufferSize is the number of updates buffered for each watcher of a blob
+const watchBufferSize = 16
+
 var _ disperser.BlobStore = (*BlobStore)(nil)
 
 // NewBlobStore creates an empty BlobStore
@@ -29,6 +50,10 @@ func NewBlobStore() disperser.BlobStore {
 	return &BlobStore{
 		Blobs:    make(map[disperser.BlobHash]*BlobHolder),
 		Metadata: make(map[disperser.BlobKey]*disperser.BlobMetadata),
+		watchers: make(map[disperser.BlobKey][]chan *disperser.BlobMetadata),
+
+		idempotencyKeys:     make(map[string]idempotencyKeyClaim),
+		aggregateSignatures: make(map[uint32][]byte),
 	}
 }
 
@@ -42,6 +67,13 @@ func (q *BlobStore) StoreBlob(ctx context.Context, blob *core.Blob, requestedAt
 	blobKey.BlobHash = blobHash
 	blobKey.MetadataHash = getMetadataHash(requestedAt)
 
+	if idempotencyKey := blob.RequestHeader.IdempotencyKey; idempotencyKey != "" {
+		claimedKey, err := q.claimIdempotencyKey(idempotencyKey, blobKey, disperser.GetIdempotencyPayloadHash(blob))
+		if err != nil {
+			return claimedKey, err
+		}
+	}
+
 	// Add the blob to the queue
 	q.Blobs[blobHash] = &BlobHolder{
 		Data: blob.Data,
@@ -88,6 +120,37 @@ func (q *BlobStore) MarkBlobConfirmed(ctx context.Context, existingMetadata *dis
 	newMetadata.BlobStatus = disperser.Confirmed
 	newMetadata.ConfirmationInfo = confirmationInfo
 	q.Metadata[blobKey] = &newMetadata
+	q.notify(blobKey)
+	return &newMetadata, nil
+}
+
+func (q *BlobStore) BatchMarkBlobsConfirmed(ctx context.Context, writes []disperser.ConfirmationWrite) error {
+	var failedKeys []disperser.BlobKey
+	var firstErr error
+	for _, write := range writes {
+		if _, err := q.MarkBlobConfirmed(ctx, write.Metadata, write.ConfirmationInfo); err != nil {
+			failedKeys = append(failedKeys, write.Metadata.GetBlobKey())
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if len(failedKeys) > 0 {
+		return &disperser.BatchConfirmationError{FailedKeys: failedKeys, Err: firstErr}
+	}
+	return nil
+}
+
+func (q *BlobStore) MarkBlobBatchIDPending(ctx context.Context, existingMetadata *disperser.BlobMetadata, confirmationInfo *disperser.ConfirmationInfo) (*disperser.BlobMetadata, error) {
+	blobKey := existingMetadata.GetBlobKey()
+	if _, ok := q.Metadata[blobKey]; !ok {
+		return nil, disperser.ErrBlobNotFound
+	}
+	newMetadata := *existingMetadata
+	newMetadata.BlobStatus = disperser.BatchIDPending
+	newMetadata.ConfirmationInfo = confirmationInfo
+	q.Metadata[blobKey] = &newMetadata
+	q.notify(blobKey)
 	return &newMetadata, nil
 }
 
@@ -100,6 +163,7 @@ func (q *BlobStore) MarkBlobInsufficientSignatures(ctx context.Context, existing
 	newMetadata.BlobStatus = disperser.InsufficientSignatures
 	newMetadata.ConfirmationInfo = confirmationInfo
 	q.Metadata[blobKey] = &newMetadata
+	q.notify(blobKey)
 	return &newMetadata, nil
 }
 
@@ -109,15 +173,40 @@ func (q *BlobStore) MarkBlobFinalized(ctx context.Context, blobKey disperser.Blo
 	}
 
 	q.Metadata[blobKey].BlobStatus = disperser.Finalized
+	q.notify(blobKey)
 	return nil
 }
 
+var _ disperser.BatchBlobFinalizer = (*BlobStore)(nil)
+
+func (q *BlobStore) BatchMarkBlobsFinalized(ctx context.Context, metadatas []*disperser.BlobMetadata) ([]disperser.BlobKey, error) {
+	for _, metadata := range metadatas {
+		if _, ok := q.Metadata[metadata.GetBlobKey()]; !ok {
+			return nil, disperser.ErrBlobNotFound
+		}
+	}
+	notConfirmed := make([]disperser.BlobKey, 0)
+	for _, metadata := range metadatas {
+		blobKey := metadata.GetBlobKey()
+		if q.Metadata[blobKey].BlobStatus != disperser.Confirmed {
+			notConfirmed = append(notConfirmed, blobKey)
+			continue
+		}
+		newMetadata := *metadata
+		newMetadata.BlobStatus = disperser.Finalized
+		q.Metadata[blobKey] = &newMetadata
+		q.notify(blobKey)
+	}
+	return notConfirmed, nil
+}
+
 func (q *BlobStore) MarkBlobProcessing(ctx context.Context, blobKey disperser.BlobKey) error {
 	if _, ok := q.Metadata[blobKey]; !ok {
 		return disperser.ErrBlobNotFound
 	}
 
 	q.Metadata[blobKey].BlobStatus = disperser.Processing
+	q.notify(blobKey)
 	return nil
 }
 
@@ -127,6 +216,7 @@ func (q *BlobStore) MarkBlobFailed(ctx context.Context, blobKey disperser.BlobKe
 	}
 
 	q.Metadata[blobKey].BlobStatus = disperser.Failed
+	q.notify(blobKey)
 	return nil
 }
 
@@ -136,6 +226,7 @@ func (q *BlobStore) IncrementBlobRetryCount(ctx context.Context, existingMetadat
 	}
 
 	q.Metadata[existingMetadata.GetBlobKey()].NumRetries++
+	q.notify(existingMetadata.GetBlobKey())
 	return nil
 }
 
@@ -225,6 +316,121 @@ func (q *BlobStore) GetAllBlobMetadataByBatch(ctx context.Context, batchHeaderHa
 	return metas, nil
 }
 
+func (q *BlobStore) GetBlobMetadataByBatchID(ctx context.Context, batchID uint32) ([]*disperser.BlobMetadata, error) {
+	metas := make([]*disperser.BlobMetadata, 0)
+	for _, meta := range q.Metadata {
+		if meta.ConfirmationInfo != nil && meta.ConfirmationInfo.BatchID == batchID {
+			metas = append(metas, meta)
+		}
+	}
+	return metas, nil
+}
+
+func (q *BlobStore) StoreBatchAggregateSignature(ctx context.Context, batchID uint32, aggSig *core.SignatureAggregation) error {
+	data, err := aggSig.Serialize()
+	if err != nil {
+		return err
+	}
+	q.aggregateSignaturesMu.Lock()
+	defer q.aggregateSignaturesMu.Unlock()
+	q.aggregateSignatures[batchID] = data
+	return nil
+}
+
+func (q *BlobStore) GetBatchAggregateSignature(ctx context.Context, batchID uint32) (*core.SignatureAggregation, error) {
+	q.aggregateSignaturesMu.Lock()
+	data, ok := q.aggregateSignatures[batchID]
+	q.aggregateSignaturesMu.Unlock()
+	if !ok {
+		return nil, disperser.ErrBatchNotFound
+	}
+	return new(core.SignatureAggregation).Deserialize(data)
+}
+
+// claimIdempotencyKey claims the idempotency key for the blob unless it was already claimed
+func (q *BlobStore) claimIdempotencyKey(idempotencyKey string, blobKey disperser.BlobKey, payloadHash string) (disperser.BlobKey, error) {
+	q.idempotencyKeysMu.Lock()
+	defer q.idempotencyKeysMu.Unlock()
+	if claim, ok := q.idempotencyKeys[idempotencyKey]; ok {
+		if claim.payloadHash != payloadHash {
+			return claim.blobKey, disperser.ErrIdempotencyKeyMismatch
+		}
+		return claim.blobKey, disperser.ErrIdempotencyKeyExists
+	}
+	q.idempotencyKeys[idempotencyKey] = idempotencyKeyClaim{blobKey: blobKey, payloadHash: payloadHash}
+	return blobKey, nil
+}
+
+func (q *BlobStore) GetBlobMetadataByIdempotencyKey(ctx context.Context, idempotencyKey string, payloadHash string) (*disperser.BlobMetadata, error) {
+	q.idempotencyKeysMu.Lock()
+	claim, ok := q.idempotencyKeys[idempotencyKey]
+	q.idempotencyKeysMu.Unlock()
+	if !ok {
+		return nil, disperser.ErrBlobNotFound
+	}
+	if claim.payloadHash != payloadHash {
+		return nil, disperser.ErrIdempotencyKeyMismatch
+	}
+	if meta, ok := q.Metadata[claim.blobKey]; ok {
+		return meta, nil
+	}
+	return nil, disperser.ErrBlobNotFound
+}
+
+func (q *BlobStore) GetBlobMetadataByContentHash(ctx context.Context, contentHash disperser.BlobHash, requester string) ([]*disperser.BlobMetadata, error) {
+	metas := make([]*disperser.BlobMetadata, 0)
+	for _, meta := range q.Metadata {
+		if meta.RequestMetadata == nil || meta.RequestMetadata.Requester != requester {
+			continue
+		}
+		blob, ok := q.Blobs[meta.BlobHash]
+		if !ok {
+			continue
+		}
+		if disperser.GetBlobContentHash(blob.Data) == contentHash {
+			metas = append(metas, meta)
+		}
+	}
+	return metas, nil
+}
+
+func (q *BlobStore) GetBlobMetadataByCommitment(ctx context.Context, commitment *core.G1Commitment, limit int32, exclusiveStartKey *disperser.BlobStoreExclusiveStartKey) ([]*disperser.BlobMetadata, *disperser.BlobStoreExclusiveStartKey, error) {
+	matches := make([]*disperser.BlobMetadata, 0)
+	for _, meta := range q.Metadata {
+		if meta.ConfirmationInfo == nil || meta.ConfirmationInfo.BlobCommitment == nil || meta.ConfirmationInfo.BlobCommitment.Commitment == nil {
+			continue
+		}
+		c := meta.ConfirmationInfo.BlobCommitment.Commitment
+		if c.X.Equal(&commitment.X) && c.Y.Equal(&commitment.Y) {
+			matches = append(matches, meta)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].RequestMetadata.RequestedAt != matches[j].RequestMetadata.RequestedAt {
+			return matches[i].RequestMetadata.RequestedAt < matches[j].RequestMetadata.RequestedAt
+		}
+		return matches[i].GetBlobKey().String() < matches[j].GetBlobKey().String()
+	})
+
+	if exclusiveStartKey != nil {
+		for i, meta := range matches {
+			if meta.BlobHash == exclusiveStartKey.BlobHash && meta.MetadataHash == exclusiveStartKey.MetadataHash {
+				matches = matches[i+1:]
+				break
+			}
+		}
+	}
+	if limit <= 0 || len(matches) <= int(limit) {
+		return matches, nil, nil
+	}
+	last := matches[limit-1]
+	return matches[:limit], &disperser.BlobStoreExclusiveStartKey{
+		BlobHash:     last.BlobHash,
+		MetadataHash: last.MetadataHash,
+		RequestedAt:  int64(last.RequestMetadata.RequestedAt),
+	}, nil
+}
+
 func (q *BlobStore) GetBlobMetadata(ctx context.Context, blobKey disperser.BlobKey) (*disperser.BlobMetadata, error) {
 	if meta, ok := q.Metadata[blobKey]; ok {
 		return meta, nil
@@ -232,11 +438,75 @@ func (q *BlobStore) GetBlobMetadata(ctx context.Context, blobKey disperser.BlobK
 	return nil, disperser.ErrBlobNotFound
 }
 
-func (q *BlobStore) HandleBlobFailure(ctx context.Context, metadata *disperser.BlobMetadata, maxRetry uint) error {
+func (q *BlobStore) HandleBlobFailure(ctx context.Context, metadata *disperser.BlobMetadata, maxRetry uint) (bool, error) {
 	if metadata.NumRetries < maxRetry {
-		return q.IncrementBlobRetryCount(ctx, metadata)
+		return true, q.IncrementBlobRetryCount(ctx, metadata)
 	} else {
-		return q.MarkBlobFailed(ctx, metadata.GetBlobKey())
+		return false, q.MarkBlobFailed(ctx, metadata.GetBlobKey())
+	}
+}
+
+func (q *BlobStore) PruneConfirmationInfo(ctx context.Context, existingMetadata *disperser.BlobMetadata) error {
+	blobKey := existingMetadata.GetBlobKey()
+	if _, ok := q.Metadata[blobKey]; !ok {
+		return disperser.ErrBlobNotFound
+	}
+	if existingMetadata.BlobStatus != disperser.Finalized || existingMetadata.ConfirmationInfo == nil {
+		return fmt.Errorf("%w: blob %s is in status %s", disperser.ErrBlobNotFinalized, blobKey.String(), existingMetadata.BlobStatus.String())
+	}
+	newMetadata := *existingMetadata
+	newMetadata.BlobStatus = disperser.Pruned
+	newMetadata.ConfirmationInfo = existingMetadata.ConfirmationInfo.Pruned()
+	q.Metadata[blobKey] = &newMetadata
+	return nil
+}
+
+func (q *BlobStore) WatchBlobStatus(ctx context.Context, blobKey disperser.BlobKey) (<-chan *disperser.BlobMetadata, error) {
+	if _, ok := q.Metadata[blobKey]; !ok {
+		return nil, disperser.ErrBlobNotFound
+	}
+
+	updates := make(chan *disperser.BlobMetadata, watchBufferSize)
+	q.watchersMu.Lock()
+	q.watchers[blobKey] = append(q.watchers[blobKey], updates)
+	q.watchersMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		q.watchersMu.Lock()
+		defer q.watchersMu.Unlock()
+		watchers := q.watchers[blobKey]
+		for i, watcher := range watchers {
+			if watcher == updates {
+				q.watchers[blobKey] = append(watchers[:i], watchers[i+1:]...)
+				break
+			}
+		}
+		if len(q.watchers[blobKey]) == 0 {
+			delete(q.watchers, blobKey)
+		}
+		close(updates)
+	}()
+	return updates, nil
+}
+
+// notify sends a snapshot of the metadata of the blob to its watchers. A watcher that falls behind loses its oldest
+// pending update, so that the latest update is always delivered.
+func (q *BlobStore) notify(blobKey disperser.BlobKey) {
+	q.watchersMu.Lock()
+	defer q.watchersMu.Unlock()
+	for _, watcher := range q.watchers[blobKey] {
+		snapshot := *q.Metadata[blobKey]
+		select {
+		case watcher <- &snapshot:
+			continue
+		default:
+		}
+		select {
+		case <-watcher:
+		default:
+		}
+		watcher <- &snapshot
 	}
 }
 
@@ -114,3 +114,122 @@ func TestBlobStore(t *testing.T) {
 	assert.Equal(t, 1, len(allMeta))
 	assert.Equal(t, allMeta[0].BlobStatus, disperser.Confirmed)
 }
+
+func TestGetBlobMetadataByBatchID(t *testing.T) {
+	bs := inmem.NewBlobStore()
+	ctx := context.Background()
+	requestedAt := uint64(time.Now().UnixNano())
+
+	numBlobs := 5
+	metas := make([]*disperser.BlobMetadata, numBlobs)
+	for i := 0; i < numBlobs; i++ {
+		blobKey, err := bs.StoreBlob(ctx, &core.Blob{
+			RequestHeader: core.BlobRequestHeader{
+				SecurityParams: []*core.SecurityParam{},
+			},
+			Data: []byte{byte(i)},
+		}, requestedAt+uint64(i))
+		assert.Nil(t, err)
+		metas[i], err = bs.GetBlobMetadata(ctx, blobKey)
+		assert.Nil(t, err)
+	}
+
+	// The first three blobs land in batch 7, the fourth in batch 8 and the last one is never confirmed
+	batchIDs := []uint32{7, 7, 7, 8}
+	for i, batchID := range batchIDs {
+		_, err := bs.MarkBlobConfirmed(ctx, metas[i], &disperser.ConfirmationInfo{
+			BatchHeaderHash: [32]byte{byte(batchID)},
+			BlobIndex:       uint32(i),
+			BatchID:         batchID,
+		})
+		assert.Nil(t, err)
+	}
+
+	inBatch, err := bs.GetBlobMetadataByBatchID(ctx, 7)
+	assert.Nil(t, err)
+	assert.Len(t, inBatch, 3)
+	keys := make(map[disperser.BlobKey]struct{})
+	for _, meta := range inBatch {
+		assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
+		assert.Equal(t, uint32(7), meta.ConfirmationInfo.BatchID)
+		keys[meta.GetBlobKey()] = struct{}{}
+	}
+	for i := 0; i < 3; i++ {
+		assert.Contains(t, keys, metas[i].GetBlobKey())
+	}
+
+	inBatch, err = bs.GetBlobMetadataByBatchID(ctx, 8)
+	assert.Nil(t, err)
+	assert.Len(t, inBatch, 1)
+	assert.Equal(t, metas[3].GetBlobKey(), inBatch[0].GetBlobKey())
+
+	inBatch, err = bs.GetBlobMetadataByBatchID(ctx, 9)
+	assert.Nil(t, err)
+	assert.Len(t, inBatch, 0)
+}
+
+func TestBatchMarkBlobsConfirmed(t *testing.T) {
+	bs := inmem.NewBlobStore()
+	ctx := context.Background()
+	requestedAt := uint64(time.Now().UnixNano())
+
+	numBlobs := 4
+	metas := make([]*disperser.BlobMetadata, numBlobs)
+	for i := 0; i < numBlobs; i++ {
+		blobKey, err := bs.StoreBlob(ctx, &core.Blob{
+			RequestHeader: core.BlobRequestHeader{
+				SecurityParams: []*core.SecurityParam{},
+			},
+			Data: []byte{byte(i)},
+		}, requestedAt+uint64(i))
+		assert.Nil(t, err)
+		metas[i], err = bs.GetBlobMetadata(ctx, blobKey)
+		assert.Nil(t, err)
+	}
+
+	// The first blob is already confirmed in batch 7
+	_, err := bs.MarkBlobConfirmed(ctx, metas[0], &disperser.ConfirmationInfo{BatchID: 7})
+	assert.Nil(t, err)
+
+	writes := make([]disperser.ConfirmationWrite, numBlobs)
+	for i, meta := range metas {
+		writes[i] = disperser.ConfirmationWrite{
+			Metadata: meta,
+			ConfirmationInfo: &disperser.ConfirmationInfo{
+				BatchHeaderHash: [32]byte{8},
+				BlobIndex:       uint32(i),
+				BatchID:         8,
+			},
+		}
+	}
+	// A blob that isn't in the store fails while the others are written
+	unknown := &disperser.BlobMetadata{BlobHash: "unknown", MetadataHash: "unknown"}
+	writes = append(writes, disperser.ConfirmationWrite{Metadata: unknown, ConfirmationInfo: &disperser.ConfirmationInfo{BatchID: 8}})
+
+	err = bs.BatchMarkBlobsConfirmed(ctx, writes)
+	var batchErr *disperser.BatchConfirmationError
+	assert.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, []disperser.BlobKey{unknown.GetBlobKey()}, batchErr.FailedKeys)
+	assert.ErrorIs(t, err, disperser.ErrBlobNotFound)
+
+	// Confirmed blobs are immutable, so the first blob stays in batch 7
+	meta, err := bs.GetBlobMetadata(ctx, metas[0].GetBlobKey())
+	assert.Nil(t, err)
+	assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
+	assert.Equal(t, uint32(7), meta.ConfirmationInfo.BatchID)
+	for i := 1; i < numBlobs; i++ {
+		meta, err := bs.GetBlobMetadata(ctx, metas[i].GetBlobKey())
+		assert.Nil(t, err)
+		assert.Equal(t, disperser.Confirmed, meta.BlobStatus)
+		assert.Equal(t, uint32(8), meta.ConfirmationInfo.BatchID)
+		assert.Equal(t, uint32(i), meta.ConfirmationInfo.BlobIndex)
+	}
+
+	// Writing the confirmations again leaves the confirmed blobs unchanged
+	writes[1].ConfirmationInfo = &disperser.ConfirmationInfo{BatchID: 9}
+	err = bs.BatchMarkBlobsConfirmed(ctx, writes[:numBlobs])
+	assert.Nil(t, err)
+	meta, err = bs.GetBlobMetadata(ctx, metas[1].GetBlobKey())
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(8), meta.ConfirmationInfo.BatchID)
+}
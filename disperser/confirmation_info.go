@@ -0,0 +1,431 @@
+package disperser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/Layr-Labs/eigenda/core"
+	bn "github.com/consensys/gnark-crypto/ecc/bn254"
+)
+
+// compactConfirmationInfoVersion is the version of the compact encoding of ConfirmationInfo
+const compactConfirmationInfoVersion byte = 0
+
+// flags of a quorum record in the compact encoding of ConfirmationInfo
+const (
+	quorumHasResult byte = 1 << iota
+	quorumHasNilResult
+	quorumHasInfo
+	quorumHasAttested
+	quorumAttested
+	quorumHasAssignment
+)
+
+// flags of the optional fields in the compact encoding of ConfirmationInfo
+const (
+	hasQuorumResults byte = 1 << iota
+	hasBlobQuorumInfos
+	hasAttestedQuorums
+	hasAssignmentInfos
+)
+
+var ErrInvalidCompactConfirmationInfo = errors.New("invalid compact confirmation info")
+
+// EncodeCompact encodes the confirmation info in a compact binary format for offchain storage.
+// Integers are varint encoded, curve points are compressed, and the per-quorum fields (quorum results,
+// quorum infos, attested quorums and assignment infos) are stored together in a single record per quorum
+// instead of repeating the quorum ID in each of them.
+// Empty byte slices are decoded as nil.
+func (c *ConfirmationInfo) EncodeCompact() ([]byte, error) {
+	buf := []byte{compactConfirmationInfoVersion}
+	buf = append(buf, c.BatchHeaderHash[:]...)
+	buf = binary.AppendUvarint(buf, uint64(c.BlobIndex))
+	buf = binary.AppendUvarint(buf, uint64(c.BlobCount))
+	buf = append(buf, c.SignatoryRecordHash[:]...)
+	buf = binary.AppendUvarint(buf, uint64(c.ReferenceBlockNumber))
+	buf = appendBytes(buf, c.BatchRoot)
+	buf = appendBytes(buf, c.BlobInclusionProof)
+	buf = appendBlobCommitments(buf, c.BlobCommitment)
+	buf = binary.AppendUvarint(buf, uint64(c.BatchID))
+	buf = append(buf, c.ConfirmationTxnHash[:]...)
+	buf = binary.AppendUvarint(buf, uint64(c.ConfirmationBlockNumber))
+	buf = appendBytes(buf, c.Fee)
+	buf = binary.AppendUvarint(buf, c.ConfirmedAt)
+
+	var fields byte
+	if c.QuorumResults != nil {
+		fields |= hasQuorumResults
+	}
+	if c.BlobQuorumInfos != nil {
+		fields |= hasBlobQuorumInfos
+	}
+	if c.AttestedQuorums != nil {
+		fields |= hasAttestedQuorums
+	}
+	if c.AssignmentInfos != nil {
+		fields |= hasAssignmentInfos
+	}
+	buf = append(buf, fields)
+
+	quorumIDs, err := c.getQuorumIDs()
+	if err != nil {
+		return nil, err
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(quorumIDs)))
+	infos := make(map[core.QuorumID]*core.BlobQuorumInfo, len(c.BlobQuorumInfos))
+	for _, info := range c.BlobQuorumInfos {
+		infos[info.QuorumID] = info
+	}
+	for _, quorumID := range quorumIDs {
+		var flags byte
+		result, hasResult := c.QuorumResults[quorumID]
+		if hasResult {
+			flags |= quorumHasResult
+			if result == nil {
+				flags |= quorumHasNilResult
+			}
+		}
+		info, hasInfo := infos[quorumID]
+		if hasInfo {
+			flags |= quorumHasInfo
+		}
+		if attested, ok := c.AttestedQuorums[quorumID]; ok {
+			flags |= quorumHasAttested
+			if attested {
+				flags |= quorumAttested
+			}
+		}
+		assignmentInfo, hasAssignment := c.AssignmentInfos[quorumID]
+		if hasAssignment {
+			flags |= quorumHasAssignment
+		}
+
+		buf = append(buf, quorumID, flags)
+		if hasResult && result != nil {
+			buf = append(buf, result.PercentSigned)
+		}
+		if hasInfo {
+			buf = append(buf, info.AdversaryThreshold, info.QuorumThreshold)
+			buf = binary.AppendUvarint(buf, uint64(info.QuorumRate))
+			buf = binary.AppendUvarint(buf, uint64(info.ChunkLength))
+		}
+		if hasAssignment {
+			buf = binary.AppendUvarint(buf, uint64(assignmentInfo.TotalChunks))
+		}
+	}
+	return buf, nil
+}
+
+// getQuorumIDs returns the IDs of the quorums in any of the per-quorum fields. The quorums of BlobQuorumInfos
+// come first in their original order, followed by the remaining quorums in ascending order.
+func (c *ConfirmationInfo) getQuorumIDs() ([]core.QuorumID, error) {
+	seen := make(map[core.QuorumID]bool)
+	quorumIDs := make([]core.QuorumID, 0)
+	for _, info := range c.BlobQuorumInfos {
+		if info == nil {
+			return nil, errors.New("nil blob quorum info")
+		}
+		if seen[info.QuorumID] {
+			return nil, fmt.Errorf("duplicate blob quorum info for quorum %d", info.QuorumID)
+		}
+		seen[info.QuorumID] = true
+		quorumIDs = append(quorumIDs, info.QuorumID)
+	}
+
+	remaining := make([]core.QuorumID, 0)
+	addRemaining := func(quorumID core.QuorumID) {
+		if !seen[quorumID] {
+			seen[quorumID] = true
+			remaining = append(remaining, quorumID)
+		}
+	}
+	for quorumID, result := range c.QuorumResults {
+		if result != nil && result.QuorumID != quorumID {
+			return nil, fmt.Errorf("quorum result for quorum %d has quorum ID %d", quorumID, result.QuorumID)
+		}
+		addRemaining(quorumID)
+	}
+	for quorumID := range c.AttestedQuorums {
+		addRemaining(quorumID)
+	}
+	for quorumID := range c.AssignmentInfos {
+		addRemaining(quorumID)
+	}
+	sort.Slice(remaining, func(i, j int) bool {
+		return remaining[i] < remaining[j]
+	})
+	return append(quorumIDs, remaining...), nil
+}
+
+// DecodeCompactConfirmationInfo decodes a confirmation info encoded with ConfirmationInfo.EncodeCompact
+func DecodeCompactConfirmationInfo(data []byte) (*ConfirmationInfo, error) {
+	r := bytes.NewReader(data)
+	info, err := decodeCompactConfirmationInfo(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCompactConfirmationInfo, err)
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidCompactConfirmationInfo, r.Len())
+	}
+	return info, nil
+}
+
+func decodeCompactConfirmationInfo(r *bytes.Reader) (*ConfirmationInfo, error) {
+	version, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if version != compactConfirmationInfoVersion {
+		return nil, fmt.Errorf("unsupported version %d", version)
+	}
+
+	c := &ConfirmationInfo{}
+	if _, err = io.ReadFull(r, c.BatchHeaderHash[:]); err != nil {
+		return nil, err
+	}
+	if c.BlobIndex, err = readUint32(r); err != nil {
+		return nil, err
+	}
+	if c.BlobCount, err = readUint32(r); err != nil {
+		return nil, err
+	}
+	if _, err = io.ReadFull(r, c.SignatoryRecordHash[:]); err != nil {
+		return nil, err
+	}
+	if c.ReferenceBlockNumber, err = readUint32(r); err != nil {
+		return nil, err
+	}
+	if c.BatchRoot, err = readBytes(r); err != nil {
+		return nil, err
+	}
+	if c.BlobInclusionProof, err = readBytes(r); err != nil {
+		return nil, err
+	}
+	if c.BlobCommitment, err = readBlobCommitments(r); err != nil {
+		return nil, err
+	}
+	if c.BatchID, err = readUint32(r); err != nil {
+		return nil, err
+	}
+	if _, err = io.ReadFull(r, c.ConfirmationTxnHash[:]); err != nil {
+		return nil, err
+	}
+	if c.ConfirmationBlockNumber, err = readUint32(r); err != nil {
+		return nil, err
+	}
+	if c.Fee, err = readBytes(r); err != nil {
+		return nil, err
+	}
+	if c.ConfirmedAt, err = binary.ReadUvarint(r); err != nil {
+		return nil, err
+	}
+
+	fields, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if fields&hasQuorumResults != 0 {
+		c.QuorumResults = make(map[core.QuorumID]*core.QuorumResult)
+	}
+	if fields&hasBlobQuorumInfos != 0 {
+		c.BlobQuorumInfos = make([]*core.BlobQuorumInfo, 0)
+	}
+	if fields&hasAttestedQuorums != 0 {
+		c.AttestedQuorums = make(map[core.QuorumID]bool)
+	}
+	if fields&hasAssignmentInfos != 0 {
+		c.AssignmentInfos = make(map[core.QuorumID]core.AssignmentInfo)
+	}
+
+	numQuorums, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if numQuorums > core.MaxQuorumID+1 {
+		return nil, fmt.Errorf("too many quorums: %d", numQuorums)
+	}
+	for i := uint64(0); i < numQuorums; i++ {
+		quorumID, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		flags, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if flags&quorumHasResult != 0 {
+			if c.QuorumResults == nil {
+				return nil, fmt.Errorf("unexpected quorum result for quorum %d", quorumID)
+			}
+			var result *core.QuorumResult
+			if flags&quorumHasNilResult == 0 {
+				percentSigned, err := r.ReadByte()
+				if err != nil {
+					return nil, err
+				}
+				result = &core.QuorumResult{QuorumID: quorumID, PercentSigned: percentSigned}
+			}
+			c.QuorumResults[quorumID] = result
+		}
+		if flags&quorumHasInfo != 0 {
+			if c.BlobQuorumInfos == nil {
+				return nil, fmt.Errorf("unexpected blob quorum info for quorum %d", quorumID)
+			}
+			info := &core.BlobQuorumInfo{}
+			info.QuorumID = quorumID
+			if info.AdversaryThreshold, err = r.ReadByte(); err != nil {
+				return nil, err
+			}
+			if info.QuorumThreshold, err = r.ReadByte(); err != nil {
+				return nil, err
+			}
+			if info.QuorumRate, err = readUint32(r); err != nil {
+				return nil, err
+			}
+			chunkLength, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			info.ChunkLength = uint(chunkLength)
+			c.BlobQuorumInfos = append(c.BlobQuorumInfos, info)
+		}
+		if flags&quorumHasAttested != 0 {
+			if c.AttestedQuorums == nil {
+				return nil, fmt.Errorf("unexpected attestation for quorum %d", quorumID)
+			}
+			c.AttestedQuorums[quorumID] = flags&quorumAttested != 0
+		}
+		if flags&quorumHasAssignment != 0 {
+			if c.AssignmentInfos == nil {
+				return nil, fmt.Errorf("unexpected assignment info for quorum %d", quorumID)
+			}
+			totalChunks, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			c.AssignmentInfos[quorumID] = core.AssignmentInfo{TotalChunks: core.ChunkNumber(totalChunks)}
+		}
+	}
+	return c, nil
+}
+
+func appendBytes(buf []byte, data []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if length == 0 {
+		return nil, nil
+	}
+	if length > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	data := make([]byte, length)
+	_, err = io.ReadFull(r, data)
+	return data, err
+}
+
+func readUint32(r *bytes.Reader) (uint32, error) {
+	value, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, err
+	}
+	if value > uint64(^uint32(0)) {
+		return 0, fmt.Errorf("value %d overflows uint32", value)
+	}
+	return uint32(value), nil
+}
+
+// appendBlobCommitments appends the commitments with compressed curve points. A leading flag byte records which
+// of the commitments and their points are present.
+func appendBlobCommitments(buf []byte, commitments *core.BlobCommitments) []byte {
+	if commitments == nil {
+		return append(buf, 0)
+	}
+	var flags byte = 1
+	if commitments.Commitment != nil {
+		flags |= 1 << 1
+	}
+	if commitments.LengthCommitment != nil {
+		flags |= 1 << 2
+	}
+	if commitments.LengthProof != nil {
+		flags |= 1 << 3
+	}
+	buf = append(buf, flags)
+	if commitments.Commitment != nil {
+		point := bn.G1Affine(*commitments.Commitment)
+		compressed := point.Bytes()
+		buf = append(buf, compressed[:]...)
+	}
+	if commitments.LengthCommitment != nil {
+		point := bn.G2Affine(*commitments.LengthCommitment)
+		compressed := point.Bytes()
+		buf = append(buf, compressed[:]...)
+	}
+	if commitments.LengthProof != nil {
+		point := bn.G2Affine(*commitments.LengthProof)
+		compressed := point.Bytes()
+		buf = append(buf, compressed[:]...)
+	}
+	return binary.AppendUvarint(buf, uint64(commitments.Length))
+}
+
+func readBlobCommitments(r *bytes.Reader) (*core.BlobCommitments, error) {
+	flags, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if flags == 0 {
+		return nil, nil
+	}
+	commitments := &core.BlobCommitments{}
+	if flags&(1<<1) != 0 {
+		var point bn.G1Affine
+		if err = readPoint(r, bn.SizeOfG1AffineCompressed, point.SetBytes); err != nil {
+			return nil, err
+		}
+		commitment := core.G1Commitment(point)
+		commitments.Commitment = &commitment
+	}
+	if flags&(1<<2) != 0 {
+		var point bn.G2Affine
+		if err = readPoint(r, bn.SizeOfG2AffineCompressed, point.SetBytes); err != nil {
+			return nil, err
+		}
+		lengthCommitment := core.G2Commitment(point)
+		commitments.LengthCommitment = &lengthCommitment
+	}
+	if flags&(1<<3) != 0 {
+		var point bn.G2Affine
+		if err = readPoint(r, bn.SizeOfG2AffineCompressed, point.SetBytes); err != nil {
+			return nil, err
+		}
+		lengthProof := core.LengthProof(point)
+		commitments.LengthProof = &lengthProof
+	}
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	commitments.Length = uint(length)
+	return commitments, nil
+}
+
+func readPoint(r *bytes.Reader, size int, setBytes func([]byte) (int, error)) error {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return err
+	}
+	_, err := setBytes(data)
+	return err
+}
@@ -0,0 +1,351 @@
+package disperser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Layr-Labs/eigenda/core"
+	bn "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ConfirmationInfoSchemaVersion is the version of the JSON schema of ConfirmationInfo produced by
+// ConfirmationInfo.Marshal. It must be incremented on any change of the schema.
+const ConfirmationInfoSchemaVersion = 1
+
+var ErrInvalidConfirmationInfo = errors.New("invalid confirmation info")
+
+// confirmationInfoJSON is the JSON schema of ConfirmationInfo shared with non-Go consumers:
+//   - byte strings (hashes, the batch root, the inclusion proof and the fee) are 0x-prefixed hex strings
+//   - field elements of curve points are decimal strings
+//   - the per-quorum maps are arrays sorted by quorum ID
+//
+// Fields must not be renamed or removed without bumping ConfirmationInfoSchemaVersion.
+type confirmationInfoJSON struct {
+	Version                 int                  `json:"version"`
+	BatchHeaderHash         hexutil.Bytes        `json:"batch_header_hash"`
+	BlobIndex               uint32               `json:"blob_index"`
+	BlobCount               uint32               `json:"blob_count"`
+	SignatoryRecordHash     hexutil.Bytes        `json:"signatory_record_hash"`
+	ReferenceBlockNumber    uint32               `json:"reference_block_number"`
+	BatchRoot               hexutil.Bytes        `json:"batch_root"`
+	BlobInclusionProof      hexutil.Bytes        `json:"blob_inclusion_proof"`
+	BlobCommitment          *blobCommitmentsJSON `json:"blob_commitment"`
+	BatchID                 uint32               `json:"batch_id"`
+	ConfirmationTxnHash     hexutil.Bytes        `json:"confirmation_txn_hash"`
+	ConfirmationBlockNumber uint32               `json:"confirmation_block_number"`
+	Fee                     hexutil.Bytes        `json:"fee"`
+	QuorumResults           []quorumResultJSON   `json:"quorum_results"`
+	BlobQuorumInfos         []blobQuorumInfoJSON `json:"blob_quorum_infos"`
+	AttestedQuorums         []attestedQuorumJSON `json:"attested_quorums"`
+	AssignmentInfos         []assignmentInfoJSON `json:"assignment_infos"`
+}
+
+type blobCommitmentsJSON struct {
+	Commitment       *g1PointJSON `json:"commitment"`
+	LengthCommitment *g2PointJSON `json:"length_commitment"`
+	LengthProof      *g2PointJSON `json:"length_proof"`
+	Length           uint         `json:"length"`
+}
+
+type g1PointJSON struct {
+	X string `json:"x"`
+	Y string `json:"y"`
+}
+
+// g2PointJSON holds the coordinates of a G2 point as [A0, A1] pairs
+type g2PointJSON struct {
+	X [2]string `json:"x"`
+	Y [2]string `json:"y"`
+}
+
+type quorumResultJSON struct {
+	QuorumID core.QuorumID `json:"quorum_id"`
+	// PercentSigned is null if the quorum has a nil result
+	PercentSigned *uint8 `json:"percent_signed"`
+}
+
+type blobQuorumInfoJSON struct {
+	QuorumID           core.QuorumID `json:"quorum_id"`
+	AdversaryThreshold uint8         `json:"adversary_threshold"`
+	QuorumThreshold    uint8         `json:"quorum_threshold"`
+	QuorumRate         uint32        `json:"quorum_rate"`
+	ChunkLength        uint          `json:"chunk_length"`
+}
+
+type attestedQuorumJSON struct {
+	QuorumID core.QuorumID `json:"quorum_id"`
+	Attested bool          `json:"attested"`
+}
+
+type assignmentInfoJSON struct {
+	QuorumID    core.QuorumID `json:"quorum_id"`
+	TotalChunks uint          `json:"total_chunks"`
+}
+
+// Marshal serializes the confirmation info to versioned JSON for consumers that don't share the Go types.
+// Nil maps and slices are serialized as null and empty ones as empty arrays. Empty byte slices are
+// unmarshalled as nil.
+func (c *ConfirmationInfo) Marshal() ([]byte, error) {
+	info := confirmationInfoJSON{
+		Version:                 ConfirmationInfoSchemaVersion,
+		BatchHeaderHash:         c.BatchHeaderHash[:],
+		BlobIndex:               c.BlobIndex,
+		BlobCount:               c.BlobCount,
+		SignatoryRecordHash:     c.SignatoryRecordHash[:],
+		ReferenceBlockNumber:    c.ReferenceBlockNumber,
+		BatchRoot:               c.BatchRoot,
+		BlobInclusionProof:      c.BlobInclusionProof,
+		BlobCommitment:          toBlobCommitmentsJSON(c.BlobCommitment),
+		BatchID:                 c.BatchID,
+		ConfirmationTxnHash:     c.ConfirmationTxnHash[:],
+		ConfirmationBlockNumber: c.ConfirmationBlockNumber,
+		Fee:                     c.Fee,
+	}
+
+	if c.QuorumResults != nil {
+		info.QuorumResults = make([]quorumResultJSON, 0, len(c.QuorumResults))
+		for quorumID, result := range c.QuorumResults {
+			res := quorumResultJSON{QuorumID: quorumID}
+			if result != nil {
+				if result.QuorumID != quorumID {
+					return nil, fmt.Errorf("quorum result for quorum %d has quorum ID %d", quorumID, result.QuorumID)
+				}
+				percentSigned := result.PercentSigned
+				res.PercentSigned = &percentSigned
+			}
+			info.QuorumResults = append(info.QuorumResults, res)
+		}
+		sort.Slice(info.QuorumResults, func(i, j int) bool {
+			return info.QuorumResults[i].QuorumID < info.QuorumResults[j].QuorumID
+		})
+	}
+	if c.BlobQuorumInfos != nil {
+		info.BlobQuorumInfos = make([]blobQuorumInfoJSON, 0, len(c.BlobQuorumInfos))
+		for _, quorumInfo := range c.BlobQuorumInfos {
+			if quorumInfo == nil {
+				return nil, errors.New("nil blob quorum info")
+			}
+			info.BlobQuorumInfos = append(info.BlobQuorumInfos, blobQuorumInfoJSON{
+				QuorumID:           quorumInfo.QuorumID,
+				AdversaryThreshold: quorumInfo.AdversaryThreshold,
+				QuorumThreshold:    quorumInfo.QuorumThreshold,
+				QuorumRate:         quorumInfo.QuorumRate,
+				ChunkLength:        quorumInfo.ChunkLength,
+			})
+		}
+	}
+	if c.AttestedQuorums != nil {
+		info.AttestedQuorums = make([]attestedQuorumJSON, 0, len(c.AttestedQuorums))
+		for quorumID, attested := range c.AttestedQuorums {
+			info.AttestedQuorums = append(info.AttestedQuorums, attestedQuorumJSON{QuorumID: quorumID, Attested: attested})
+		}
+		sort.Slice(info.AttestedQuorums, func(i, j int) bool {
+			return info.AttestedQuorums[i].QuorumID < info.AttestedQuorums[j].QuorumID
+		})
+	}
+	if c.AssignmentInfos != nil {
+		info.AssignmentInfos = make([]assignmentInfoJSON, 0, len(c.AssignmentInfos))
+		for quorumID, assignmentInfo := range c.AssignmentInfos {
+			info.AssignmentInfos = append(info.AssignmentInfos, assignmentInfoJSON{QuorumID: quorumID, TotalChunks: uint(assignmentInfo.TotalChunks)})
+		}
+		sort.Slice(info.AssignmentInfos, func(i, j int) bool {
+			return info.AssignmentInfos[i].QuorumID < info.AssignmentInfos[j].QuorumID
+		})
+	}
+
+	return json.Marshal(info)
+}
+
+// UnmarshalConfirmationInfo deserializes a confirmation info serialized with ConfirmationInfo.Marshal
+func UnmarshalConfirmationInfo(data []byte) (*ConfirmationInfo, error) {
+	c, err := unmarshalConfirmationInfo(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfirmationInfo, err)
+	}
+	return c, nil
+}
+
+func unmarshalConfirmationInfo(data []byte) (*ConfirmationInfo, error) {
+	var info confirmationInfoJSON
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	if info.Version != ConfirmationInfoSchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version %d", info.Version)
+	}
+
+	c := &ConfirmationInfo{
+		BlobIndex:               info.BlobIndex,
+		BlobCount:               info.BlobCount,
+		ReferenceBlockNumber:    info.ReferenceBlockNumber,
+		BatchRoot:               bytesOrNil(info.BatchRoot),
+		BlobInclusionProof:      bytesOrNil(info.BlobInclusionProof),
+		BatchID:                 info.BatchID,
+		ConfirmationBlockNumber: info.ConfirmationBlockNumber,
+		Fee:                     bytesOrNil(info.Fee),
+	}
+	if err := copyHash(c.BatchHeaderHash[:], info.BatchHeaderHash, "batch_header_hash"); err != nil {
+		return nil, err
+	}
+	if err := copyHash(c.SignatoryRecordHash[:], info.SignatoryRecordHash, "signatory_record_hash"); err != nil {
+		return nil, err
+	}
+	if err := copyHash(c.ConfirmationTxnHash[:], info.ConfirmationTxnHash, "confirmation_txn_hash"); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if c.BlobCommitment, err = fromBlobCommitmentsJSON(info.BlobCommitment); err != nil {
+		return nil, err
+	}
+
+	if info.QuorumResults != nil {
+		c.QuorumResults = make(map[core.QuorumID]*core.QuorumResult, len(info.QuorumResults))
+		for _, result := range info.QuorumResults {
+			if _, ok := c.QuorumResults[result.QuorumID]; ok {
+				return nil, fmt.Errorf("duplicate quorum result for quorum %d", result.QuorumID)
+			}
+			var res *core.QuorumResult
+			if result.PercentSigned != nil {
+				res = &core.QuorumResult{QuorumID: result.QuorumID, PercentSigned: *result.PercentSigned}
+			}
+			c.QuorumResults[result.QuorumID] = res
+		}
+	}
+	if info.BlobQuorumInfos != nil {
+		c.BlobQuorumInfos = make([]*core.BlobQuorumInfo, 0, len(info.BlobQuorumInfos))
+		for _, quorumInfo := range info.BlobQuorumInfos {
+			c.BlobQuorumInfos = append(c.BlobQuorumInfos, &core.BlobQuorumInfo{
+				SecurityParam: core.SecurityParam{
+					QuorumID:           quorumInfo.QuorumID,
+					AdversaryThreshold: quorumInfo.AdversaryThreshold,
+					QuorumThreshold:    quorumInfo.QuorumThreshold,
+					QuorumRate:         quorumInfo.QuorumRate,
+				},
+				ChunkLength: quorumInfo.ChunkLength,
+			})
+		}
+	}
+	if info.AttestedQuorums != nil {
+		c.AttestedQuorums = make(map[core.QuorumID]bool, len(info.AttestedQuorums))
+		for _, attested := range info.AttestedQuorums {
+			if _, ok := c.AttestedQuorums[attested.QuorumID]; ok {
+				return nil, fmt.Errorf("duplicate attestation for quorum %d", attested.QuorumID)
+			}
+			c.AttestedQuorums[attested.QuorumID] = attested.Attested
+		}
+	}
+	if info.AssignmentInfos != nil {
+		c.AssignmentInfos = make(map[core.QuorumID]core.AssignmentInfo, len(info.AssignmentInfos))
+		for _, assignmentInfo := range info.AssignmentInfos {
+			if _, ok := c.AssignmentInfos[assignmentInfo.QuorumID]; ok {
+				return nil, fmt.Errorf("duplicate assignment info for quorum %d", assignmentInfo.QuorumID)
+			}
+			c.AssignmentInfos[assignmentInfo.QuorumID] = core.AssignmentInfo{TotalChunks: core.ChunkNumber(assignmentInfo.TotalChunks)}
+		}
+	}
+	return c, nil
+}
+
+func toBlobCommitmentsJSON(commitments *core.BlobCommitments) *blobCommitmentsJSON {
+	if commitments == nil {
+		return nil
+	}
+	res := &blobCommitmentsJSON{Length: commitments.Length}
+	if commitments.Commitment != nil {
+		res.Commitment = &g1PointJSON{
+			X: commitments.Commitment.X.String(),
+			Y: commitments.Commitment.Y.String(),
+		}
+	}
+	if commitments.LengthCommitment != nil {
+		res.LengthCommitment = toG2PointJSON((*bn.G2Affine)(commitments.LengthCommitment))
+	}
+	if commitments.LengthProof != nil {
+		res.LengthProof = toG2PointJSON((*bn.G2Affine)(commitments.LengthProof))
+	}
+	return res
+}
+
+func toG2PointJSON(point *bn.G2Affine) *g2PointJSON {
+	return &g2PointJSON{
+		X: [2]string{point.X.A0.String(), point.X.A1.String()},
+		Y: [2]string{point.Y.A0.String(), point.Y.A1.String()},
+	}
+}
+
+func fromBlobCommitmentsJSON(commitments *blobCommitmentsJSON) (*core.BlobCommitments, error) {
+	if commitments == nil {
+		return nil, nil
+	}
+	res := &core.BlobCommitments{Length: commitments.Length}
+	if commitments.Commitment != nil {
+		var point bn.G1Affine
+		if _, err := point.X.SetString(commitments.Commitment.X); err != nil {
+			return nil, fmt.Errorf("invalid commitment: %w", err)
+		}
+		if _, err := point.Y.SetString(commitments.Commitment.Y); err != nil {
+			return nil, fmt.Errorf("invalid commitment: %w", err)
+		}
+		if !point.IsOnCurve() {
+			return nil, errors.New("invalid commitment: point is not on the curve")
+		}
+		commitment := core.G1Commitment(point)
+		res.Commitment = &commitment
+	}
+	if commitments.LengthCommitment != nil {
+		point, err := fromG2PointJSON(commitments.LengthCommitment)
+		if err != nil {
+			return nil, fmt.Errorf("invalid length commitment: %w", err)
+		}
+		lengthCommitment := core.G2Commitment(*point)
+		res.LengthCommitment = &lengthCommitment
+	}
+	if commitments.LengthProof != nil {
+		point, err := fromG2PointJSON(commitments.LengthProof)
+		if err != nil {
+			return nil, fmt.Errorf("invalid length proof: %w", err)
+		}
+		lengthProof := core.LengthProof(*point)
+		res.LengthProof = &lengthProof
+	}
+	return res, nil
+}
+
+func fromG2PointJSON(p *g2PointJSON) (*bn.G2Affine, error) {
+	var point bn.G2Affine
+	if _, err := point.X.A0.SetString(p.X[0]); err != nil {
+		return nil, err
+	}
+	if _, err := point.X.A1.SetString(p.X[1]); err != nil {
+		return nil, err
+	}
+	if _, err := point.Y.A0.SetString(p.Y[0]); err != nil {
+		return nil, err
+	}
+	if _, err := point.Y.A1.SetString(p.Y[1]); err != nil {
+		return nil, err
+	}
+	if !point.IsOnCurve() {
+		return nil, errors.New("point is not on the curve")
+	}
+	return &point, nil
+}
+
+func copyHash(dst []byte, src []byte, name string) error {
+	if len(src) != len(dst) {
+		return fmt.Errorf("%s must be %d bytes, got %d", name, len(dst), len(src))
+	}
+	copy(dst, src)
+	return nil
+}
+
+func bytesOrNil(data []byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,182 @@
+package disperser_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/Layr-Labs/eigenda/disperser"
+	bn "github.com/consensys/gnark-crypto/ecc/bn254"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeConfirmationInfo() *disperser.ConfirmationInfo {
+	_, _, g1, g2 := bn.Generators()
+	commitment := core.G1Commitment(g1)
+	lengthCommitment := core.G2Commitment(g2)
+	lengthProof := core.LengthProof(g2)
+
+	return &disperser.ConfirmationInfo{
+		BatchHeaderHash:      [32]byte{1, 2, 3},
+		BlobIndex:            7,
+		BlobCount:            12,
+		SignatoryRecordHash:  [32]byte{4, 5, 6},
+		ReferenceBlockNumber: 132,
+		BatchRoot:            []byte("batch root"),
+		BlobInclusionProof:   []byte{1, 2, 3, 4, 5},
+		BlobCommitment: &core.BlobCommitments{
+			Commitment:       &commitment,
+			LengthCommitment: &lengthCommitment,
+			LengthProof:      &lengthProof,
+			Length:           32,
+		},
+		BatchID:                 99,
+		ConfirmationTxnHash:     gcommon.HexToHash("0x123"),
+		ConfirmationBlockNumber: 150,
+		Fee:                     []byte{0},
+		QuorumResults: map[core.QuorumID]*core.QuorumResult{
+			0: {QuorumID: 0, PercentSigned: 100},
+			1: {QuorumID: 1, PercentSigned: 60},
+			2: {QuorumID: 2, PercentSigned: 20},
+		},
+		BlobQuorumInfos: []*core.BlobQuorumInfo{
+			{
+				SecurityParam: core.SecurityParam{QuorumID: 1, AdversaryThreshold: 50, QuorumThreshold: 80, QuorumRate: 32000},
+				ChunkLength:   10,
+			},
+			{
+				SecurityParam: core.SecurityParam{QuorumID: 0, AdversaryThreshold: 33, QuorumThreshold: 67, QuorumRate: 32000},
+				ChunkLength:   4,
+			},
+		},
+		AttestedQuorums: map[core.QuorumID]bool{
+			0: true,
+			1: false,
+		},
+		AssignmentInfos: map[core.QuorumID]core.AssignmentInfo{
+			0: {TotalChunks: 12},
+			1: {TotalChunks: 20},
+		},
+	}
+}
+
+func TestConfirmationInfoCompactRoundTrip(t *testing.T) {
+	info := makeConfirmationInfo()
+	info.ConfirmedAt = 1700000000000000000
+
+	data, err := info.EncodeCompact()
+	assert.NoError(t, err)
+	decoded, err := disperser.DecodeCompactConfirmationInfo(data)
+	assert.NoError(t, err)
+	assert.Equal(t, info, decoded)
+
+	// confirmation info of a blob without quorum results or commitments
+	info = &disperser.ConfirmationInfo{
+		BatchHeaderHash: [32]byte{1},
+		BatchID:         1,
+		QuorumResults:   map[core.QuorumID]*core.QuorumResult{},
+	}
+	data, err = info.EncodeCompact()
+	assert.NoError(t, err)
+	decoded, err = disperser.DecodeCompactConfirmationInfo(data)
+	assert.NoError(t, err)
+	assert.Equal(t, info, decoded)
+}
+
+func TestConfirmationInfoCompactSize(t *testing.T) {
+	info := makeConfirmationInfo()
+
+	data, err := info.EncodeCompact()
+	assert.NoError(t, err)
+	naive, err := json.Marshal(info)
+	assert.NoError(t, err)
+	assert.Less(t, len(data)*4, len(naive))
+}
+
+func TestDecodeCompactConfirmationInfoInvalid(t *testing.T) {
+	data, err := makeConfirmationInfo().EncodeCompact()
+	assert.NoError(t, err)
+
+	_, err = disperser.DecodeCompactConfirmationInfo(data[:len(data)-1])
+	assert.ErrorIs(t, err, disperser.ErrInvalidCompactConfirmationInfo)
+
+	_, err = disperser.DecodeCompactConfirmationInfo(append(data, 0))
+	assert.ErrorIs(t, err, disperser.ErrInvalidCompactConfirmationInfo)
+
+	data[0] = 1
+	_, err = disperser.DecodeCompactConfirmationInfo(data)
+	assert.ErrorIs(t, err, disperser.ErrInvalidCompactConfirmationInfo)
+
+	_, err = disperser.DecodeCompactConfirmationInfo(nil)
+	assert.ErrorIs(t, err, disperser.ErrInvalidCompactConfirmationInfo)
+}
+
+func TestConfirmationInfoMarshalRoundTrip(t *testing.T) {
+	info := makeConfirmationInfo()
+	info.QuorumResults[3] = nil
+
+	data, err := info.Marshal()
+	assert.NoError(t, err)
+	decoded, err := disperser.UnmarshalConfirmationInfo(data)
+	assert.NoError(t, err)
+	assert.Equal(t, info, decoded)
+
+	// confirmation info of a blob without quorum results or commitments
+	info = &disperser.ConfirmationInfo{
+		BatchHeaderHash: [32]byte{1},
+		BatchID:         1,
+		QuorumResults:   map[core.QuorumID]*core.QuorumResult{},
+	}
+	data, err = info.Marshal()
+	assert.NoError(t, err)
+	decoded, err = disperser.UnmarshalConfirmationInfo(data)
+	assert.NoError(t, err)
+	assert.Equal(t, info, decoded)
+}
+
+// TestConfirmationInfoMarshalGolden detects changes of the JSON schema of ConfirmationInfo. If the schema is changed on
+// purpose, bump disperser.ConfirmationInfoSchemaVersion and update the golden file.
+func TestConfirmationInfoMarshalGolden(t *testing.T) {
+	golden, err := os.ReadFile("testdata/confirmation_info_v1.json")
+	assert.NoError(t, err)
+
+	data, err := makeConfirmationInfo().Marshal()
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(golden), string(data))
+
+	decoded, err := disperser.UnmarshalConfirmationInfo(golden)
+	assert.NoError(t, err)
+	assert.Equal(t, makeConfirmationInfo(), decoded)
+}
+
+func TestUnmarshalConfirmationInfoInvalid(t *testing.T) {
+	data, err := makeConfirmationInfo().Marshal()
+	assert.NoError(t, err)
+
+	var fields map[string]any
+	assert.NoError(t, json.Unmarshal(data, &fields))
+	fields["version"] = disperser.ConfirmationInfoSchemaVersion + 1
+	data, err = json.Marshal(fields)
+	assert.NoError(t, err)
+	_, err = disperser.UnmarshalConfirmationInfo(data)
+	assert.ErrorIs(t, err, disperser.ErrInvalidConfirmationInfo)
+
+	assert.NoError(t, json.Unmarshal(data, &fields))
+	fields["version"] = disperser.ConfirmationInfoSchemaVersion
+	fields["batch_header_hash"] = "0x0102"
+	data, err = json.Marshal(fields)
+	assert.NoError(t, err)
+	_, err = disperser.UnmarshalConfirmationInfo(data)
+	assert.ErrorIs(t, err, disperser.ErrInvalidConfirmationInfo)
+
+	assert.NoError(t, json.Unmarshal(data, &fields))
+	fields["batch_header_hash"] = "0x" + strings.Repeat("00", 32)
+	fields["blob_commitment"].(map[string]any)["commitment"] = map[string]any{"x": "1", "y": "1"}
+	data, err = json.Marshal(fields)
+	assert.NoError(t, err)
+	_, err = disperser.UnmarshalConfirmationInfo(data)
+	assert.ErrorIs(t, err, disperser.ErrInvalidConfirmationInfo)
+}
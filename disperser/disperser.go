@@ -24,6 +24,12 @@ const (
 	Failed
 	Finalized
 	InsufficientSignatures
+	// Pruned blobs are finalized blobs whose inclusion proof and length proofs have been dropped after the retention
+	// period
+	Pruned
+	// BatchIDPending blobs are in a batch confirmed onchain whose batch ID couldn't be parsed from the receipt of the
+	// confirmation transaction yet. Their confirmation info is recorded without the batch ID until it's reconciled.
+	BatchIDPending
 )
 
 var enumStrings = map[BlobStatus]string{
@@ -32,6 +38,8 @@ var enumStrings = map[BlobStatus]string{
 	Failed:                 "Failed",
 	Finalized:              "Finalized",
 	InsufficientSignatures: "InsufficientSignatures",
+	Pruned:                 "Pruned",
+	BatchIDPending:         "BatchIDPending",
 }
 
 func (bs BlobStatus) String() string {
@@ -90,7 +98,7 @@ func (m *BlobMetadata) GetBlobKey() BlobKey {
 }
 
 func (m *BlobMetadata) IsConfirmed() (bool, error) {
-	if m.BlobStatus != Confirmed && m.BlobStatus != Finalized {
+	if m.BlobStatus != Confirmed && m.BlobStatus != Finalized && m.BlobStatus != Pruned {
 		return false, nil
 	}
 
@@ -100,6 +108,21 @@ func (m *BlobMetadata) IsConfirmed() (bool, error) {
 	return true, nil
 }
 
+// Pruned returns a copy of the confirmation info without the blob inclusion proof and the length commitment and proof,
+// keeping the fields that reference the batch of the blob onchain. The commitment and length of the blob are kept, so
+// that the blob can still be looked up by its commitment.
+func (c *ConfirmationInfo) Pruned() *ConfirmationInfo {
+	pruned := *c
+	pruned.BlobInclusionProof = nil
+	if c.BlobCommitment != nil {
+		pruned.BlobCommitment = &core.BlobCommitments{
+			Commitment: c.BlobCommitment.Commitment,
+			Length:     c.BlobCommitment.Length,
+		}
+	}
+	return &pruned
+}
+
 type RequestMetadata struct {
 	core.BlobRequestHeader
 	BlobSize    uint   `json:"blob_size"`
@@ -121,6 +144,46 @@ type ConfirmationInfo struct {
 	Fee                     []byte                               `json:"fee"`
 	QuorumResults           map[core.QuorumID]*core.QuorumResult `json:"quorum_results"`
 	BlobQuorumInfos         []*core.BlobQuorumInfo               `json:"blob_quorum_infos"`
+	// AttestedQuorums records whether each quorum required by the blob met the blob's quorum threshold.
+	// It is populated for blobs with insufficient signatures as well, so that clients can still use
+	// the blob on the quorums that passed.
+	AttestedQuorums map[core.QuorumID]bool `json:"attested_quorums"`
+	// AssignmentInfos records the chunk assignment info of each quorum of the blob at the reference block.
+	// Together with BlobQuorumInfos it determines the encoding params used to disperse the blob.
+	AssignmentInfos map[core.QuorumID]core.AssignmentInfo `json:"assignment_infos"`
+	// ConfirmedAt is the time at which the batcher processed the confirmation of the blob's batch, in unix nanoseconds.
+	// It's 0 for blobs confirmed by earlier releases.
+	ConfirmedAt uint64 `json:"confirmed_at"`
+}
+
+// ConfirmationWrite is the confirmation of a single blob written with BatchMarkBlobsConfirmed
+type ConfirmationWrite struct {
+	Metadata         *BlobMetadata
+	ConfirmationInfo *ConfirmationInfo
+}
+
+// BatchConfirmationError is returned by BatchMarkBlobsConfirmed when some of the blobs couldn't be marked as confirmed.
+// The confirmations of the other blobs are written.
+type BatchConfirmationError struct {
+	// FailedKeys are the keys of the blobs that couldn't be marked as confirmed
+	FailedKeys []BlobKey
+	Err        error
+}
+
+func (e *BatchConfirmationError) Error() string {
+	return fmt.Sprintf("failed to mark %d blobs as confirmed: %v", len(e.FailedKeys), e.Err)
+}
+
+func (e *BatchConfirmationError) Unwrap() error {
+	return e.Err
+}
+
+// BatchBlobFinalizer is implemented by the blob stores that can mark several blobs as finalized in batched writes
+type BatchBlobFinalizer interface {
+	// BatchMarkBlobsFinalized updates the metadata of the given confirmed blobs to Finalized status, along with their
+	// confirmation info, in batched writes. Only the blobs still in Confirmed status are updated. Returns the keys of the
+	// blobs that were not.
+	BatchMarkBlobsFinalized(ctx context.Context, metadatas []*BlobMetadata) ([]BlobKey, error)
 }
 
 type BlobStoreExclusiveStartKey struct {
@@ -131,13 +194,24 @@ type BlobStoreExclusiveStartKey struct {
 }
 
 type BlobStore interface {
-	// StoreBlob adds a blob to the queue and returns a key that can be used to retrieve the blob later
+	// StoreBlob adds a blob to the queue and returns a key that can be used to retrieve the blob later.
+	// If the blob has an idempotency key, the key is claimed atomically before the blob is stored. If the key was
+	// already claimed, the blob isn't stored and the key of the blob that claimed it is returned along with
+	// ErrIdempotencyKeyExists, or ErrIdempotencyKeyMismatch if that blob has a different payload, see
+	// GetIdempotencyPayloadHash.
 	StoreBlob(ctx context.Context, blob *core.Blob, requestedAt uint64) (BlobKey, error)
 	// GetBlobContent retrieves a blob's content
 	GetBlobContent(ctx context.Context, blobHash BlobHash) ([]byte, error)
 	// MarkBlobConfirmed updates blob metadata to Confirmed status with confirmation info
 	// Returns the updated metadata and error
 	MarkBlobConfirmed(ctx context.Context, existingMetadata *BlobMetadata, confirmationInfo *ConfirmationInfo) (*BlobMetadata, error)
+	// BatchMarkBlobsConfirmed updates the metadata of several blobs to Confirmed status with their confirmation info in
+	// batched writes. As with MarkBlobConfirmed, blobs that are already confirmed are left unchanged. Returns a
+	// *BatchConfirmationError if only some of the blobs are marked as confirmed.
+	BatchMarkBlobsConfirmed(ctx context.Context, writes []ConfirmationWrite) error
+	// MarkBlobBatchIDPending updates blob metadata to BatchIDPending status with confirmation info that lacks the batch ID
+	// Returns the updated metadata and error
+	MarkBlobBatchIDPending(ctx context.Context, existingMetadata *BlobMetadata, confirmationInfo *ConfirmationInfo) (*BlobMetadata, error)
 	// MarkBlobInsufficientSignatures updates blob metadata to InsufficientSignatures status with confirmation info
 	// Returns the updated metadata and error
 	MarkBlobInsufficientSignatures(ctx context.Context, existingMetadata *BlobMetadata, confirmationInfo *ConfirmationInfo) (*BlobMetadata, error)
@@ -160,14 +234,67 @@ type BlobStore interface {
 	GetBlobMetadataByStatusWithPagination(ctx context.Context, blobStatus BlobStatus, limit int32, exclusiveStartKey *BlobStoreExclusiveStartKey) ([]*BlobMetadata, *BlobStoreExclusiveStartKey, error)
 	// GetAllBlobMetadataByBatch returns the metadata of all the blobs in the batch.
 	GetAllBlobMetadataByBatch(ctx context.Context, batchHeaderHash [32]byte) ([]*BlobMetadata, error)
+	// GetBlobMetadataByBatchID returns the metadata of all the blobs in the batch with the given onchain batch ID.
+	GetBlobMetadataByBatchID(ctx context.Context, batchID uint32) ([]*BlobMetadata, error)
+	// GetBlobMetadataByIdempotencyKey returns the metadata of the blob that claimed the given idempotency key, read
+	// consistently with the claims of StoreBlob. Returns ErrBlobNotFound if no blob claimed the key, and
+	// ErrIdempotencyKeyMismatch if the payload hash of the blob that claimed it differs from the given one.
+	GetBlobMetadataByIdempotencyKey(ctx context.Context, idempotencyKey string, payloadHash string) (*BlobMetadata, error)
+	// GetBlobMetadataByCommitment returns the metadata of the blobs whose confirmation info carries the given
+	// commitment, by request time. Identical data dispersed in different requests has the same commitment, so there
+	// may be several. Results are limited to the given limit and the pagination token is returned
+	GetBlobMetadataByCommitment(ctx context.Context, commitment *core.G1Commitment, limit int32, exclusiveStartKey *BlobStoreExclusiveStartKey) ([]*BlobMetadata, *BlobStoreExclusiveStartKey, error)
+	// GetBlobMetadataByContentHash returns the metadata of all the blobs dispersed by the requester whose content has the
+	// given hash, as returned by GetBlobContentHash
+	GetBlobMetadataByContentHash(ctx context.Context, contentHash BlobHash, requester string) ([]*BlobMetadata, error)
+	// WatchBlobStatus returns a channel that receives a snapshot of the blob metadata whenever the blob is updated.
+	// The channel is closed once ctx is done.
+	WatchBlobStatus(ctx context.Context, blobKey BlobKey) (<-chan *BlobMetadata, error)
+	// StoreBatchAggregateSignature records the aggregate signature of the confirmed batch with the given onchain batch ID,
+	// once for the whole batch
+	StoreBatchAggregateSignature(ctx context.Context, batchID uint32, aggSig *core.SignatureAggregation) error
+	// GetBatchAggregateSignature returns the aggregate signature of the batch with the given onchain batch ID, as recorded
+	// with StoreBatchAggregateSignature. Returns ErrBatchNotFound if no aggregate signature is recorded for the batch.
+	GetBatchAggregateSignature(ctx context.Context, batchID uint32) (*core.SignatureAggregation, error)
 	// GetBlobMetadata returns a blob metadata given a metadata key
 	GetBlobMetadata(ctx context.Context, blobKey BlobKey) (*BlobMetadata, error)
-	// HandleBlobFailure handles a blob failure by either incrementing the retry count or marking the blob as failed
-	HandleBlobFailure(ctx context.Context, metadata *BlobMetadata, maxRetry uint) error
+	// HandleBlobFailure handles a blob failure by either incrementing the retry count or marking the blob as failed.
+	// It returns true if the blob is retried, and false if it is marked as failed.
+	HandleBlobFailure(ctx context.Context, metadata *BlobMetadata, maxRetry uint) (bool, error)
+	// PruneConfirmationInfo drops the blob inclusion proof and length proofs from the confirmation info of a finalized
+	// blob and moves it to Pruned status, see ConfirmationInfo.Pruned. Returns ErrBlobNotFinalized if the blob is not
+	// finalized.
+	PruneConfirmationInfo(ctx context.Context, existingMetadata *BlobMetadata) error
 }
 
 type Dispatcher interface {
 	DisperseBatch(context.Context, *core.IndexedOperatorState, []core.EncodedBlob, *core.BatchHeader) chan core.SignerMessage
+	// EstimateCoverage returns the maximum percentage of stake of each quorum that could sign the batch if every
+	// operator assigned chunks signed it, without dispersing the batch
+	EstimateCoverage(*core.IndexedOperatorState, []core.EncodedBlob, *core.BatchHeader) map[core.QuorumID]float64
+}
+
+// GetBlobContentHash returns the hash of the blob content, which identifies blobs dispersing identical data
+func GetBlobContentHash(data []byte) BlobHash {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// ScopeIdempotencyKey returns the idempotency key scoped to the account dispersing the blob, so that accounts can't
+// look up or claim each other's keys
+func ScopeIdempotencyKey(account string, idempotencyKey string) string {
+	return account + "/" + idempotencyKey
+}
+
+// GetIdempotencyPayloadHash returns the hash of the content and security params of the blob. A request retried with an
+// idempotency key must have the same payload as the request that claimed the key.
+func GetIdempotencyPayloadHash(blob *core.Blob) string {
+	hasher := sha256.New()
+	hasher.Write(blob.Data)
+	for _, param := range blob.RequestHeader.SecurityParams {
+		hasher.Write([]byte{param.QuorumID, param.AdversaryThreshold, param.QuorumThreshold})
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
 }
 
 // GenerateReverseIndexKey returns the key used to store the blob key in the reverse index
@@ -199,6 +326,9 @@ func FromBlobStatusProto(status disperser_rpc.BlobStatus) (*BlobStatus, error) {
 	case disperser_rpc.BlobStatus_FINALIZED:
 		res = Finalized
 		return &res, nil
+	case disperser_rpc.BlobStatus_PRUNED:
+		res = Pruned
+		return &res, nil
 	}
 
 	return nil, fmt.Errorf("unknown blob status: %v", status)
@@ -3,5 +3,14 @@ package disperser
 import "errors"
 
 var (
-	ErrBlobNotFound = errors.New("blob not found")
+	ErrBlobNotFound     = errors.New("blob not found")
+	ErrBlobNotFinalized = errors.New("blob not finalized")
+	// ErrBlobNotConfirmed is returned when a blob expected to be confirmed is in another status
+	ErrBlobNotConfirmed = errors.New("blob not confirmed")
+	ErrBatchNotFound    = errors.New("batch not found")
+	// ErrIdempotencyKeyExists is returned by BlobStore.StoreBlob when the idempotency key of the blob was already
+	// claimed by another blob with the same payload
+	ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
+	// ErrIdempotencyKeyMismatch is returned when an idempotency key is reused for a blob with a different payload
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key was already used with a different payload")
 )
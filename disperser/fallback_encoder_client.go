@@ -0,0 +1,129 @@
+package disperser
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigenda/common"
+	"github.com/Layr-Labs/eigenda/core"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultEncoderRetryInterval is how long the fallback encoder client keeps using the fallback encoder after the primary
+// encoder fails, before it tries the primary encoder again
+const DefaultEncoderRetryInterval = 30 * time.Second
+
+// FallbackEncoderClient encodes blobs with a primary encoder client, typically a remote encoder, and switches to a
+// fallback encoder client, typically a LocalEncoderClient, while the primary encoder is unhealthy, so that dispersal
+// continues degraded instead of stopping. The primary encoder is considered unhealthy once it fails a request because it's
+// unavailable, and is tried again once the retry interval has elapsed. Other errors, e.g. invalid encoding params, are
+// returned as is, since the fallback encoder would fail the same way.
+type FallbackEncoderClient struct {
+	primary       EncoderClient
+	fallback      EncoderClient
+	retryInterval time.Duration
+	logger        common.Logger
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+
+	mu sync.Mutex
+	// primaryFailedAt is the time the primary encoder last failed, or zero if it's healthy
+	primaryFailedAt time.Time
+}
+
+var _ EncoderClient = (*FallbackEncoderClient)(nil)
+
+// NewFallbackEncoderClient returns an encoder client falling back to the fallback encoder client while the primary
+// encoder client is unhealthy. A retry interval of 0 defaults to DefaultEncoderRetryInterval.
+func NewFallbackEncoderClient(primary EncoderClient, fallback EncoderClient, retryInterval time.Duration, logger common.Logger) *FallbackEncoderClient {
+	if retryInterval == 0 {
+		retryInterval = DefaultEncoderRetryInterval
+	}
+	return &FallbackEncoderClient{
+		primary:       primary,
+		fallback:      fallback,
+		retryInterval: retryInterval,
+		logger:        logger,
+		Now:           time.Now,
+	}
+}
+
+func (c *FallbackEncoderClient) EncodeBlob(ctx context.Context, data []byte, encodingParams core.EncodingParams) (*core.BlobCommitments, []*core.Chunk, error) {
+	if !c.usePrimary() {
+		return c.fallback.EncodeBlob(ctx, data, encodingParams)
+	}
+
+	commitments, chunks, err := c.primary.EncodeBlob(ctx, data, encodingParams)
+	if err == nil {
+		c.markPrimaryHealthy()
+		return commitments, chunks, nil
+	}
+	// Canceled requests are normal, and say nothing about the health of the primary encoder
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil, nil, err
+	}
+	if !isEncoderUnavailable(err) {
+		return nil, nil, err
+	}
+	c.markPrimaryFailed(err)
+	if ctx.Err() != nil {
+		return nil, nil, err
+	}
+	return c.fallback.EncodeBlob(ctx, data, encodingParams)
+}
+
+// IsPrimaryHealthy returns whether the blobs are encoded with the primary encoder
+func (c *FallbackEncoderClient) IsPrimaryHealthy() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.primaryFailedAt.IsZero()
+}
+
+// usePrimary returns whether the primary encoder should be tried, i.e. whether it's healthy or the retry interval has
+// elapsed since it failed
+func (c *FallbackEncoderClient) usePrimary() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.primaryFailedAt.IsZero() || c.Now().Sub(c.primaryFailedAt) >= c.retryInterval
+}
+
+func (c *FallbackEncoderClient) markPrimaryFailed(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.primaryFailedAt.IsZero() {
+		c.logger.Warn("primary encoder failed, falling back to the fallback encoder", "retryInterval", c.retryInterval, "err", err)
+	}
+	c.primaryFailedAt = c.Now()
+}
+
+func (c *FallbackEncoderClient) markPrimaryHealthy() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.primaryFailedAt.IsZero() {
+		c.logger.Info("primary encoder recovered, switching back from the fallback encoder")
+	}
+	c.primaryFailedAt = time.Time{}
+}
+
+// isEncoderUnavailable returns whether the encoder failed because it couldn't be reached or didn't respond in time,
+// rather than because it rejected the request
+func isEncoderUnavailable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package disperser_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	cmock "github.com/Layr-Labs/eigenda/common/mock"
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/Layr-Labs/eigenda/disperser"
+	dmock "github.com/Layr-Labs/eigenda/disperser/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFallbackEncoderClient(t *testing.T) {
+	ctx := context.Background()
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	local := disperser.NewLocalEncoderClient(encoder)
+
+	data := []byte("Fourscore and seven years ago our fathers brought forth, on this continent, a new nation, conceived in liberty, and dedicated to the proposition that all men are created equal.")
+	params := core.EncodingParams{ChunkLength: 4, NumChunks: 8}
+	expectedCommitments, expectedChunks, err := local.EncodeBlob(ctx, data, params)
+	assert.NoError(t, err)
+
+	primary := dmock.NewMockEncoderClient()
+	primary.On("EncodeBlob", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil, status.Error(codes.Unavailable, "encoder unavailable")).Once()
+	client := disperser.NewFallbackEncoderClient(primary, local, time.Minute, &cmock.Logger{})
+	now := time.Unix(0, 0)
+	client.Now = func() time.Time { return now }
+	assert.True(t, client.IsPrimaryHealthy())
+
+	// the blob is encoded with the fallback encoder once the primary encoder fails
+	commitments, chunks, err := client.EncodeBlob(ctx, data, params)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedCommitments, commitments)
+	assert.Equal(t, expectedChunks, chunks)
+	assert.False(t, client.IsPrimaryHealthy())
+
+	// the primary encoder isn't tried again before the retry interval elapses
+	now = now.Add(30 * time.Second)
+	_, _, err = client.EncodeBlob(ctx, data, params)
+	assert.NoError(t, err)
+	primary.AssertNumberOfCalls(t, "EncodeBlob", 1)
+
+	// the primary encoder is used again once it recovers
+	primary.On("EncodeBlob", mock.Anything, mock.Anything, mock.Anything).Return(expectedCommitments, expectedChunks, nil)
+	now = now.Add(30 * time.Second)
+	commitments, _, err = client.EncodeBlob(ctx, data, params)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedCommitments, commitments)
+	primary.AssertNumberOfCalls(t, "EncodeBlob", 2)
+	assert.True(t, client.IsPrimaryHealthy())
+}
+
+func TestFallbackEncoderClientCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	primary := dmock.NewMockEncoderClient()
+	primary.On("EncodeBlob", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil, context.Canceled)
+	fallback := dmock.NewMockEncoderClient()
+	client := disperser.NewFallbackEncoderClient(primary, fallback, 0, &cmock.Logger{})
+
+	// a canceled request neither falls back nor marks the primary encoder as unhealthy
+	_, _, err := client.EncodeBlob(ctx, []byte("data"), core.EncodingParams{ChunkLength: 4, NumChunks: 8})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, client.IsPrimaryHealthy())
+	fallback.AssertNotCalled(t, "EncodeBlob", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestFallbackEncoderClientRejected(t *testing.T) {
+	ctx := context.Background()
+	rejected := status.Error(codes.InvalidArgument, "invalid encoding params")
+
+	primary := dmock.NewMockEncoderClient()
+	primary.On("EncodeBlob", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil, rejected).Once()
+	primary.On("EncodeBlob", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil, errors.New("failed to deserialize commitment")).Once()
+	fallback := dmock.NewMockEncoderClient()
+	client := disperser.NewFallbackEncoderClient(primary, fallback, 0, &cmock.Logger{})
+
+	// errors other than the primary encoder being unavailable neither fall back nor mark the primary encoder as
+	// unhealthy
+	_, _, err := client.EncodeBlob(ctx, []byte("data"), core.EncodingParams{ChunkLength: 4, NumChunks: 8})
+	assert.ErrorIs(t, err, rejected)
+	_, _, err = client.EncodeBlob(ctx, []byte("data"), core.EncodingParams{ChunkLength: 4, NumChunks: 8})
+	assert.ErrorContains(t, err, "failed to deserialize commitment")
+	assert.True(t, client.IsPrimaryHealthy())
+	fallback.AssertNotCalled(t, "EncodeBlob", mock.Anything, mock.Anything, mock.Anything)
+
+	// a primary encoder that can't be reached is unavailable
+	primary.On("EncodeBlob", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}).Once()
+	fallback.On("EncodeBlob", mock.Anything, mock.Anything, mock.Anything).Return(&core.BlobCommitments{}, []*core.Chunk{}, nil)
+	_, _, err = client.EncodeBlob(ctx, []byte("data"), core.EncodingParams{ChunkLength: 4, NumChunks: 8})
+	assert.NoError(t, err)
+	assert.False(t, client.IsPrimaryHealthy())
+	fallback.AssertNumberOfCalls(t, "EncodeBlob", 1)
+}
@@ -23,6 +23,10 @@ type Metrics struct {
 	NumBlobRequests *prometheus.CounterVec
 	BlobSize        *prometheus.GaugeVec
 	Latency         *prometheus.SummaryVec
+	// RejectedRequests counts the dispersal requests rejected by the request validation, by rejection reason
+	RejectedRequests *prometheus.CounterVec
+	// DuplicateRequests counts the dispersal requests duplicating an earlier blob, by the duplicate blob policy applied
+	DuplicateRequests *prometheus.CounterVec
 
 	httpPort string
 	logger   common.Logger
@@ -35,6 +39,24 @@ const (
 	AccountRateLimitedFailure string = "ratelimited-account" // The request rate limited at account level
 )
 
+// The reasons dispersal requests are rejected by the request validation.
+const (
+	RejectEmptySecurityParams   string = "empty-security-params"    // The request has no security params
+	RejectTooManyQuorums        string = "too-many-quorums"         // The request has more quorums than allowed
+	RejectDuplicateQuorum       string = "duplicate-quorum"         // The request has a quorum more than once
+	RejectUnsupportedQuorum     string = "unsupported-quorum"       // The request has a quorum the disperser doesn't support
+	RejectInvalidQuorum         string = "invalid-quorum"           // The request has a quorum that doesn't exist onchain
+	RejectOversizedBlob         string = "oversized-blob"           // The blob exceeds the maximum blob size
+	RejectEmptyBlob             string = "empty-blob"               // The blob is empty
+	RejectIdempotencyKeyTooLong string = "idempotency-key-too-long" // The idempotency key exceeds the maximum length
+	RejectIdempotencyKeyReused  string = "idempotency-key-reused"   // The idempotency key was used with a different payload
+	RejectClientMetadataTooLong string = "client-metadata-too-long" // The client metadata exceeds the maximum length
+	RejectInvalidPriority       string = "invalid-priority"         // The priority is unknown
+	RejectUnauthorizedPriority  string = "unauthorized-priority"    // The high priority is requested by an account not entitled to it
+	RejectInvalidHeader         string = "invalid-header"           // The request header fails validation
+	RejectUnauthenticated       string = "unauthenticated"          // The request is unauthenticated, but authentication is required
+)
+
 func NewMetrics(httpPort string, logger common.Logger) *Metrics {
 	namespace := "eigenda_disperser"
 	reg := prometheus.NewRegistry()
@@ -67,6 +89,22 @@ func NewMetrics(httpPort string, logger common.Logger) *Metrics {
 			},
 			[]string{"method"},
 		),
+		RejectedRequests: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "disperse_requests_rejected_total",
+				Help:      "the number of dispersal requests rejected by the request validation",
+			},
+			[]string{"reason"},
+		),
+		DuplicateRequests: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "disperse_duplicates_total",
+				Help:      "the number of dispersal requests duplicating the content and quorums of an earlier blob",
+			},
+			[]string{"policy"},
+		),
 		registry: reg,
 		httpPort: httpPort,
 		logger:   logger,
@@ -159,6 +197,16 @@ func (g *Metrics) HandleAccountRateLimitedRequest(quorum string, blobBytes int,
 	}).Add(float64(blobBytes))
 }
 
+// HandleRejectedRequest increments the number of dispersal requests rejected for the given reason
+func (g *Metrics) HandleRejectedRequest(reason string) {
+	g.RejectedRequests.WithLabelValues(reason).Inc()
+}
+
+// HandleDuplicateRequest updates the number of requests duplicating an earlier blob
+func (g *Metrics) HandleDuplicateRequest(policy string) {
+	g.DuplicateRequests.WithLabelValues(policy).Inc()
+}
+
 // Start starts the metrics server
 func (g *Metrics) Start(ctx context.Context) {
 	g.logger.Info("Starting metrics server at ", "port", g.httpPort)
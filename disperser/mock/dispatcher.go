@@ -22,7 +22,7 @@ func NewDispatcher(state *mock.PrivateOperatorState) disperser.Dispatcher {
 
 func (d *Dispatcher) DisperseBatch(ctx context.Context, state *core.IndexedOperatorState, blobs []core.EncodedBlob, header *core.BatchHeader) chan core.SignerMessage {
 	update := make(chan core.SignerMessage)
-	message, err := header.GetBatchHeaderHash()
+	message, err := header.GetSigningMessage()
 	if err != nil {
 		for id := range d.state.PrivateOperators {
 			update <- core.SignerMessage{
@@ -47,3 +47,7 @@ func (d *Dispatcher) DisperseBatch(ctx context.Context, state *core.IndexedOpera
 
 	return update
 }
+
+func (d *Dispatcher) EstimateCoverage(state *core.IndexedOperatorState, blobs []core.EncodedBlob, header *core.BatchHeader) map[core.QuorumID]float64 {
+	return disperser.EstimateSigningCoverage(state, blobs)
+}
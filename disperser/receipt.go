@@ -0,0 +1,111 @@
+package disperser
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	pb "github.com/Layr-Labs/eigenda/api/grpc/disperser"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// BlobReceipt is the acknowledgement by the disperser that it accepted a blob, signed with the receipt signing key of
+// the disperser so that the client can prove that the blob was accepted before it's confirmed
+type BlobReceipt struct {
+	BlobKey BlobKey
+	// RequestedAt is the time the blob was requested at, in nanoseconds since the Unix epoch
+	RequestedAt uint64
+	// Signature is the ECDSA signature of the disperser over the hash of the receipt
+	Signature []byte
+}
+
+// Hash returns the keccak256 hash of the request ID of the blob followed by the big-endian time it was requested at,
+// which is the message the receipt is signed over
+func (r *BlobReceipt) Hash() []byte {
+	requestedAt := make([]byte, 8)
+	binary.BigEndian.PutUint64(requestedAt, r.RequestedAt)
+	return crypto.Keccak256([]byte(r.BlobKey.String()), requestedAt)
+}
+
+// ToProtobuf converts the receipt to its protobuf representation
+func (r *BlobReceipt) ToProtobuf() *pb.BlobReceipt {
+	return &pb.BlobReceipt{
+		RequestId:   []byte(r.BlobKey.String()),
+		RequestedAt: r.RequestedAt,
+		Signature:   r.Signature,
+	}
+}
+
+// BlobReceiptFromProtobuf converts the protobuf representation of a receipt to a receipt
+func BlobReceiptFromProtobuf(receipt *pb.BlobReceipt) (*BlobReceipt, error) {
+	if receipt == nil {
+		return nil, errors.New("missing blob receipt")
+	}
+	blobKey, err := ParseBlobKey(string(receipt.GetRequestId()))
+	if err != nil {
+		return nil, err
+	}
+	return &BlobReceipt{
+		BlobKey:     blobKey,
+		RequestedAt: receipt.GetRequestedAt(),
+		Signature:   receipt.GetSignature(),
+	}, nil
+}
+
+// ReceiptSigner signs the receipts of the blobs accepted by the disperser
+type ReceiptSigner struct {
+	privateKey *ecdsa.PrivateKey
+}
+
+// NewReceiptSigner creates a ReceiptSigner signing with the given hex encoded ECDSA private key
+func NewReceiptSigner(privateKeyHex string) (*ReceiptSigner, error) {
+	privateKey, err := crypto.ToECDSA(common.FromHex(privateKeyHex))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the receipt signing key: %w", err)
+	}
+	return &ReceiptSigner{
+		privateKey: privateKey,
+	}, nil
+}
+
+// SignReceipt returns the signed receipt of the blob requested at the given time
+func (s *ReceiptSigner) SignReceipt(blobKey BlobKey, requestedAt uint64) (*BlobReceipt, error) {
+	receipt := &BlobReceipt{
+		BlobKey:     blobKey,
+		RequestedAt: requestedAt,
+	}
+	sig, err := crypto.Sign(receipt.Hash(), s.privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign the blob receipt: %w", err)
+	}
+	receipt.Signature = sig
+	return receipt, nil
+}
+
+// PublicKey returns the hex encoded public key the receipts are verified with
+func (s *ReceiptSigner) PublicKey() string {
+	return hexutil.Encode(crypto.FromECDSAPub(&s.privateKey.PublicKey))
+}
+
+// VerifyBlobReceipt verifies that the receipt is signed by the disperser with the given hex encoded public key
+func VerifyBlobReceipt(receipt *BlobReceipt, publicKeyHex string) error {
+	publicKeyBytes, err := hexutil.Decode(publicKeyHex)
+	if err != nil {
+		return fmt.Errorf("failed to decode public key (%v): %v", publicKeyHex, err)
+	}
+	if len(receipt.Signature) != 65 {
+		return fmt.Errorf("signature length is unexpected: %d", len(receipt.Signature))
+	}
+	sigPublicKey, err := crypto.Ecrecover(receipt.Hash(), receipt.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to recover public key from signature: %v", err)
+	}
+	if !bytes.Equal(sigPublicKey, publicKeyBytes) {
+		return errors.New("signature doesn't match with provided public key")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package disperser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/Layr-Labs/eigenda/pkg/kzg/bn254"
+)
+
+// ErrCommitmentMismatch is returned when a re-encoded blob's commitment differs from the commitment it was confirmed with
+var ErrCommitmentMismatch = errors.New("re-encoded commitment does not match the confirmed commitment")
+
+// ReencodeBlob encodes the original data of a blob under new encoding params, e.g. to re-disperse confirmed blobs to a
+// new operator set after the encoding parameters change. The chunks are verified against the new commitments, and
+// since the commitment to the data doesn't depend on the encoding params, the commitment and length of a confirmed
+// blob must match the ones it was confirmed with.
+func ReencodeBlob(ctx context.Context, store BlobStore, encoder core.Encoder, metadata *BlobMetadata, params core.EncodingParams) (*core.BlobCommitments, []*core.Chunk, error) {
+	data, err := store.GetBlobContent(ctx, metadata.BlobHash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the content of blob %s: %w", metadata.BlobHash, err)
+	}
+
+	commitments, chunks, err := encoder.Encode(data, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to re-encode blob %s: %w", metadata.BlobHash, err)
+	}
+
+	if metadata.ConfirmationInfo != nil && metadata.ConfirmationInfo.BlobCommitment != nil {
+		confirmed := metadata.ConfirmationInfo.BlobCommitment
+		if confirmed.Commitment == nil || !bn254.EqualG1((*bn254.G1Point)(confirmed.Commitment), (*bn254.G1Point)(commitments.Commitment)) {
+			return nil, nil, fmt.Errorf("%w: blob %s", ErrCommitmentMismatch, metadata.BlobHash)
+		}
+		if confirmed.Length != commitments.Length {
+			return nil, nil, fmt.Errorf("%w: blob %s has length %d, confirmed with length %d", ErrCommitmentMismatch, metadata.BlobHash, commitments.Length, confirmed.Length)
+		}
+	}
+
+	if err := encoder.VerifyBlobLength(commitments); err != nil {
+		return nil, nil, fmt.Errorf("failed to verify the length of re-encoded blob %s: %w", metadata.BlobHash, err)
+	}
+	indices := make([]core.ChunkNumber, len(chunks))
+	for i := range indices {
+		indices[i] = core.ChunkNumber(i)
+	}
+	if err := encoder.VerifyChunks(chunks, indices, commitments, params); err != nil {
+		return nil, nil, fmt.Errorf("failed to verify the chunks of re-encoded blob %s: %w", metadata.BlobHash, err)
+	}
+
+	return &commitments, chunks, nil
+}
@@ -0,0 +1,108 @@
+package disperser_test
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/Layr-Labs/eigenda/core/encoding"
+	"github.com/Layr-Labs/eigenda/disperser"
+	"github.com/Layr-Labs/eigenda/disperser/common/inmem"
+	"github.com/Layr-Labs/eigenda/encoding/kzgrs"
+	"github.com/Layr-Labs/eigenda/pkg/kzg/bn254"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTestEncoder() (core.Encoder, error) {
+	config := kzgrs.KzgConfig{
+		G1Path:          "../inabox/resources/kzg/g1.point",
+		G2Path:          "../inabox/resources/kzg/g2.point",
+		CacheDir:        "../inabox/resources/kzg/SRSTables",
+		SRSOrder:        3000,
+		SRSNumberToLoad: 3000,
+		NumWorker:       uint64(runtime.GOMAXPROCS(0)),
+	}
+
+	return encoding.NewEncoder(encoding.EncoderConfig{KzgConfig: config}, true)
+}
+
+func TestReencodeBlob(t *testing.T) {
+	ctx := context.Background()
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	store := inmem.NewBlobStore()
+
+	data := []byte("Fourscore and seven years ago our fathers brought forth, on this continent, a new nation, conceived in liberty, and dedicated to the proposition that all men are created equal.")
+	blob := &core.Blob{
+		RequestHeader: core.BlobRequestHeader{
+			SecurityParams: []*core.SecurityParam{{QuorumID: 0, AdversaryThreshold: 80, QuorumThreshold: 100}},
+		},
+		Data: data,
+	}
+	blobKey, err := store.StoreBlob(ctx, blob, uint64(time.Now().UnixNano()))
+	assert.NoError(t, err)
+	metadata, err := store.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+
+	// the blob is confirmed with its original encoding
+	originalParams := core.EncodingParams{ChunkLength: 4, NumChunks: 8}
+	originalCommitments, _, err := encoder.Encode(data, originalParams)
+	assert.NoError(t, err)
+	metadata, err = store.MarkBlobConfirmed(ctx, metadata, &disperser.ConfirmationInfo{
+		BlobCommitment: &originalCommitments,
+	})
+	assert.NoError(t, err)
+
+	newParams := core.EncodingParams{ChunkLength: 2, NumChunks: 32}
+	commitments, chunks, err := disperser.ReencodeBlob(ctx, store, encoder, metadata, newParams)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, int(newParams.NumChunks))
+	assert.Equal(t, newParams.ChunkLength, uint(len(chunks[0].Coeffs)))
+	assert.Equal(t, originalCommitments.Length, commitments.Length)
+
+	// the blob is recovered from a subset of the re-encoded chunks
+	numChunksNeeded := (commitments.Length + newParams.ChunkLength - 1) / newParams.ChunkLength
+	indices := make([]core.ChunkNumber, 0, numChunksNeeded)
+	sampled := make([]*core.Chunk, 0, numChunksNeeded)
+	for i := uint(0); i < numChunksNeeded; i++ {
+		index := newParams.NumChunks - 1 - i
+		indices = append(indices, core.ChunkNumber(index))
+		sampled = append(sampled, chunks[index])
+	}
+	decoded, err := encoder.Decode(sampled, indices, newParams, uint64(commitments.Length)*bn254.BYTES_PER_COEFFICIENT)
+	assert.NoError(t, err)
+	assert.Equal(t, data, bytes.TrimRight(decoded, "\x00"))
+}
+
+func TestReencodeBlobCommitmentMismatch(t *testing.T) {
+	ctx := context.Background()
+	encoder, err := makeTestEncoder()
+	assert.NoError(t, err)
+	store := inmem.NewBlobStore()
+
+	blob := &core.Blob{
+		RequestHeader: core.BlobRequestHeader{
+			SecurityParams: []*core.SecurityParam{{QuorumID: 0, AdversaryThreshold: 80, QuorumThreshold: 100}},
+		},
+		Data: []byte("the data in the store"),
+	}
+	blobKey, err := store.StoreBlob(ctx, blob, uint64(time.Now().UnixNano()))
+	assert.NoError(t, err)
+	metadata, err := store.GetBlobMetadata(ctx, blobKey)
+	assert.NoError(t, err)
+
+	// the blob was confirmed with the commitment to other data
+	params := core.EncodingParams{ChunkLength: 4, NumChunks: 8}
+	otherCommitments, _, err := encoder.Encode([]byte("some other data than in the store"), params)
+	assert.NoError(t, err)
+	metadata, err = store.MarkBlobConfirmed(ctx, metadata, &disperser.ConfirmationInfo{
+		BlobCommitment: &otherCommitments,
+	})
+	assert.NoError(t, err)
+
+	_, _, err = disperser.ReencodeBlob(ctx, store, encoder, metadata, params)
+	assert.ErrorIs(t, err, disperser.ErrCommitmentMismatch)
+}
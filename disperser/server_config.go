@@ -1,9 +1,79 @@
 package disperser
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/Layr-Labs/eigenda/core"
+	"github.com/Layr-Labs/eigenda/core/auth"
+)
+
 const (
 	Localhost = "0.0.0.0"
 )
 
+// DuplicateBlobPolicy determines how the disperser handles a request to disperse the same content to the same quorums as
+// an earlier request of the same requester whose blob hasn't failed. Duplicates are rate limited like other requests.
+type DuplicateBlobPolicy string
+
+const (
+	// DuplicateBlobAllow disperses every request, including duplicates
+	DuplicateBlobAllow DuplicateBlobPolicy = "allow"
+	// DuplicateBlobDedup answers a duplicate request with the key of the existing blob, which is not dispersed again
+	DuplicateBlobDedup DuplicateBlobPolicy = "dedup"
+	// DuplicateBlobReject rejects a duplicate request with an error carrying the key of the existing blob
+	DuplicateBlobReject DuplicateBlobPolicy = "reject"
+)
+
+// ParseDuplicateBlobPolicy returns the policy with the given name. An empty name is the allow policy.
+func ParseDuplicateBlobPolicy(name string) (DuplicateBlobPolicy, error) {
+	switch policy := DuplicateBlobPolicy(name); policy {
+	case "":
+		return DuplicateBlobAllow, nil
+	case DuplicateBlobAllow, DuplicateBlobDedup, DuplicateBlobReject:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown duplicate blob policy: %s", name)
+	}
+}
+
 type ServerConfig struct {
 	GrpcPort string
+	// AllowedQuorumIDs is the set of quorums that clients may request dispersal to. Empty means all onchain quorums are allowed.
+	AllowedQuorumIDs []core.QuorumID
+	// MaxQuorumsPerBlob is the maximum number of quorums a blob may be dispersed to. 0 means no limit.
+	MaxQuorumsPerBlob uint
+	// DuplicateBlobPolicy determines how requests duplicating the content and quorums of an earlier request are handled.
+	// Defaults to DuplicateBlobAllow.
+	DuplicateBlobPolicy DuplicateBlobPolicy
+	// RequireAuthentication rejects the unauthenticated DisperseBlob requests, so that blobs can only be dispersed with
+	// DisperseBlobAuthenticated
+	RequireAuthentication bool
+	// AllowedAccounts restricts the authenticated dispersal to the accounts of the allowlist, which can be updated at
+	// runtime to rotate the keys of the clients. Nil allows any account.
+	AllowedAccounts *auth.AccountAllowlist
+	// AllowedAccountsFile is the file the AllowedAccounts are reloaded from when it changes, checked every
+	// AllowedAccountsReloadInterval. Empty disables the reloads.
+	AllowedAccountsFile           string
+	AllowedAccountsReloadInterval time.Duration
+	// ReceiptSigner signs the receipts returned to the clients for the blobs the disperser accepts. Nil disables the
+	// receipts.
+	ReceiptSigner *ReceiptSigner
+
+	// BatchInterval is the expected time between batches, used to estimate the time to confirmation of queued blobs
+	BatchInterval time.Duration
+	// BlobsPerBatch is the expected number of blobs in each batch, used to estimate the time to confirmation of queued blobs
+	BlobsPerBatch uint
+	// DefaultConfirmationLatency is the confirmation latency assumed until the latency of a confirmed blob has been observed
+	DefaultConfirmationLatency time.Duration
+	// QueueRefreshInterval is how long the queue of blobs waiting for confirmation is cached by the time to confirmation
+	// estimate. 0 defaults to 10s.
+	QueueRefreshInterval time.Duration
+
+	// MaxWatchStreamsPerClient is the maximum number of WatchBlobStatus streams a client, identified by its origin, may
+	// have open at once. 0 means no limit.
+	MaxWatchStreamsPerClient uint
+	// MaxWatchDuration is the maximum time a WatchBlobStatus stream is kept open, after which the client has to watch the
+	// blob again. 0 means no limit.
+	MaxWatchDuration time.Duration
 }
@@ -0,0 +1,54 @@
+package disperser
+
+import (
+	"math/big"
+
+	"github.com/Layr-Labs/eigenda/core"
+)
+
+// EstimateSigningCoverage returns the maximum percentage of stake of each quorum in the operator state that could sign
+// the batch, assuming that every operator assigned chunks of any blob in the batch signs it. As with the signature
+// aggregation, the stake of a signing operator counts towards every quorum the operator belongs to.
+// Unlike QuorumResult.PercentSigned, the percentages are not rounded down.
+func EstimateSigningCoverage(state *core.IndexedOperatorState, blobs []core.EncodedBlob) map[core.QuorumID]float64 {
+	assigned := make(map[core.OperatorID]bool)
+	for id := range state.IndexedOperators {
+		for _, blob := range blobs {
+			if isAssigned(blob[id]) {
+				assigned[id] = true
+				break
+			}
+		}
+	}
+
+	coverage := make(map[core.QuorumID]float64, len(state.Operators))
+	for quorumID, operators := range state.Operators {
+		total, ok := state.Totals[quorumID]
+		if !ok || total.Stake == nil || total.Stake.Sign() == 0 {
+			coverage[quorumID] = 0
+			continue
+		}
+		stakeSigned := big.NewInt(0)
+		for id, op := range operators {
+			if assigned[id] {
+				stakeSigned.Add(stakeSigned, op.Stake)
+			}
+		}
+		percent, _ := new(big.Rat).SetFrac(new(big.Int).Mul(stakeSigned, big.NewInt(100)), total.Stake).Float64()
+		coverage[quorumID] = percent
+	}
+	return coverage
+}
+
+// isAssigned returns whether the blob message contains any chunks
+func isAssigned(message *core.BlobMessage) bool {
+	if message == nil {
+		return false
+	}
+	for _, bundle := range message.Bundles {
+		if len(bundle) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package kzgrs
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -19,6 +20,9 @@ const (
 	G2PointBytes = 64
 )
 
+// ErrG2PowerOf2Mismatch is returned when the power-of-2 G2 points are not derived from the same SRS as the other points
+var ErrG2PowerOf2Mismatch = errors.New("G2 power of 2 points do not match the SRS")
+
 type EncodeParams struct {
 	NumNodeE  uint64
 	ChunkLenE uint64
@@ -96,6 +100,54 @@ func ReadG2PointOnPowerOf2(exponent uint64, g *KzgConfig) (bls.G2Point, error) {
 	return g2point[0], nil
 }
 
+// ValidateG2PowerOf2 checks that the points in G2PowerOf2Path are derived from the same SRS as the other SRS files.
+// Each point [tau^(2^i)] is compared with the point at index 2^i in G2Path if it's set, and otherwise checked
+// against the point at index 2^i in G1Path with a pairing. Only the points that are present in the file are checked.
+func ValidateG2PowerOf2(g *KzgConfig) error {
+	if len(g.G2PowerOf2Path) == 0 || g.SRSOrder < 2 {
+		return nil
+	}
+
+	info, err := os.Stat(g.G2PowerOf2Path)
+	if err != nil {
+		return err
+	}
+	numPoints := uint64(math.Log2(float64(g.SRSOrder-1))) + 1
+	if numPointsInFile := uint64(info.Size()) / G2PointBytes; numPointsInFile < numPoints {
+		numPoints = numPointsInFile
+	}
+	if numPoints == 0 {
+		return nil
+	}
+	powerOf2Points, err := ReadG2PointSection(g.G2PowerOf2Path, 0, numPoints, 1)
+	if err != nil {
+		return err
+	}
+
+	for exponent, point := range powerOf2Points {
+		power := uint64(1) << exponent
+		if len(g.G2Path) > 0 {
+			g2Points, err := ReadG2PointSection(g.G2Path, power, power+1, 1)
+			if err != nil {
+				return err
+			}
+			if !bls.EqualG2(&point, &g2Points[0]) {
+				return fmt.Errorf("%w: point %d of %s does not match point %d of %s", ErrG2PowerOf2Mismatch, exponent, g.G2PowerOf2Path, power, g.G2Path)
+			}
+			continue
+		}
+
+		g1Points, err := ReadG1PointSection(g.G1Path, power, power+1, 1)
+		if err != nil {
+			return err
+		}
+		if !bls.PairingsVerify(&g1Points[0], &bls.GenG2, &bls.GenG1, &point) {
+			return fmt.Errorf("%w: point %d of %s does not match point %d of %s", ErrG2PowerOf2Mismatch, exponent, g.G2PowerOf2Path, power, g.G1Path)
+		}
+	}
+	return nil
+}
+
 func ReadG1Points(filepath string, n uint64, numWorker uint64) ([]bls.G1Point, error) {
 	g1f, err := os.Open(filepath)
 	if err != nil {
@@ -6,10 +6,18 @@ import (
 )
 
 func (g *ParametrizedProver) Decode(frames []enc.Frame, indices []uint64, maxInputSize uint64) ([]byte, error) {
+	return g.Encoder.Decode(toRsFrames(frames), indices, maxInputSize)
+}
+
+// DecodeParallel decodes the frames like Decode, interpolating them across the configured number of workers
+func (g *ParametrizedProver) DecodeParallel(frames []enc.Frame, indices []uint64, maxInputSize uint64) ([]byte, error) {
+	return g.Encoder.DecodeParallel(toRsFrames(frames), indices, maxInputSize, g.NumWorker)
+}
+
+func toRsFrames(frames []enc.Frame) []rs.Frame {
 	rsFrames := make([]rs.Frame, len(frames))
 	for ind, frame := range frames {
 		rsFrames[ind] = rs.Frame{Coeffs: frame.Coeffs}
 	}
-
-	return g.Encoder.Decode(rsFrames, indices, maxInputSize)
+	return rsFrames
 }
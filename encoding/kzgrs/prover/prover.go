@@ -73,6 +73,10 @@ func NewProver(config *kzgrs.KzgConfig, loadG2Points bool) (*Prover, error) {
 		}
 	}
 
+	if err := kzgrs.ValidateG2PowerOf2(config); err != nil {
+		return nil, err
+	}
+
 	srs, err := kzg.NewSrs(s1, s2)
 	if err != nil {
 		log.Println("Could not create srs", err)
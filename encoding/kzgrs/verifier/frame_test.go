@@ -1,7 +1,10 @@
 package verifier_test
 
 import (
+	"fmt"
 	"math"
+	"runtime"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -42,3 +45,94 @@ func TestVerify(t *testing.T) {
 	require.Nil(t, err)
 	assert.True(t, verifier.VerifyFrame(&frames[0], enc.Ks, commit, &lc, &g2Atn))
 }
+
+func TestVerifyFramesParallel_MatchesSerial(t *testing.T) {
+	teardownSuite := setupSuite(t)
+	defer teardownSuite(t)
+
+	group, _ := prover.NewProver(kzgConfig, true)
+	verifierGroup, err := verifier.NewVerifier(kzgConfig, true)
+	require.Nil(t, err)
+
+	params := rs.GetEncodingParams(8, 8, uint64(len(GETTYSBURG_ADDRESS_BYTES)))
+	enc, err := group.NewKzgEncoder(params)
+	require.Nil(t, err)
+	ver, err := verifierGroup.GetKzgVerifier(params)
+	require.Nil(t, err)
+
+	commit, _, _, frames, _, err := enc.EncodeBytes(GETTYSBURG_ADDRESS_BYTES)
+	require.Nil(t, err)
+	indices := make([]uint64, len(frames))
+	for i := range indices {
+		indices[i] = uint64(i)
+	}
+
+	// each case verifies the frames at shifted indices at the given positions, which makes them fail
+	for _, failing := range [][]int{{}, {0}, {5}, {len(frames) - 1}, {3, 9}, {12, 2, 7}} {
+		shifted := make([]uint64, len(indices))
+		copy(shifted, indices)
+		for _, i := range failing {
+			shifted[i] = (indices[i] + 1) % uint64(len(frames))
+		}
+
+		serialErr := ver.VerifyFrames(commit, frames, shifted)
+		for _, numWorker := range []uint64{1, 3, uint64(runtime.GOMAXPROCS(0)), uint64(len(frames))} {
+			parallelErr := ver.VerifyFramesParallel(commit, frames, shifted, numWorker)
+			assert.Equal(t, serialErr, parallelErr, "failing %v, numWorker %d", failing, numWorker)
+		}
+		if len(failing) == 0 {
+			assert.Nil(t, serialErr)
+		} else {
+			assert.ErrorContains(t, serialErr, fmt.Sprintf("frame %d:", slices.Min(failing)))
+		}
+	}
+}
+
+func BenchmarkVerifyFrames(b *testing.B) {
+	config := &kzgrs.KzgConfig{
+		G1Path:          "../../../inabox/resources/kzg/g1.point",
+		G2Path:          "../../../inabox/resources/kzg/g2.point",
+		G2PowerOf2Path:  "../../../inabox/resources/kzg/g2.point.powerOf2",
+		CacheDir:        "../../../inabox/resources/kzg/SRSTables",
+		SRSOrder:        3000,
+		SRSNumberToLoad: 2900,
+		NumWorker:       uint64(runtime.GOMAXPROCS(0)),
+	}
+	group, err := prover.NewProver(config, true)
+	if err != nil {
+		b.Fatal(err)
+	}
+	verifierGroup, err := verifier.NewVerifier(config, true)
+	if err != nil {
+		b.Fatal(err)
+	}
+	params := rs.GetEncodingParams(64, 64, uint64(len(GETTYSBURG_ADDRESS_BYTES)))
+	enc, err := group.NewKzgEncoder(params)
+	if err != nil {
+		b.Fatal(err)
+	}
+	ver, err := verifierGroup.GetKzgVerifier(params)
+	if err != nil {
+		b.Fatal(err)
+	}
+	commit, _, _, frames, _, err := enc.EncodeBytes(GETTYSBURG_ADDRESS_BYTES)
+	if err != nil {
+		b.Fatal(err)
+	}
+	indices := make([]uint64, len(frames))
+	for i := range indices {
+		indices[i] = uint64(i)
+	}
+
+	b.Run("Serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = ver.VerifyFrames(commit, frames, indices)
+		}
+	})
+
+	b.Run("Parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = ver.VerifyFramesParallel(commit, frames, indices, config.NumWorker)
+		}
+	})
+}
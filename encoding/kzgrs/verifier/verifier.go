@@ -7,6 +7,7 @@ import (
 	"math"
 	"runtime"
 	"sync"
+	"sync/atomic"
 
 	enc "github.com/Layr-Labs/eigenda/encoding"
 	"github.com/Layr-Labs/eigenda/encoding/kzgrs"
@@ -70,6 +71,10 @@ func NewVerifier(config *kzgrs.KzgConfig, loadG2Points bool) (*Verifier, error)
 		}
 	}
 
+	if err := kzgrs.ValidateG2PowerOf2(config); err != nil {
+		return nil, err
+	}
+
 	srs, err := kzg.NewSrs(s1, s2)
 	if err != nil {
 		log.Println("Could not create srs", err)
@@ -199,6 +204,75 @@ func (v *ParametrizedVerifier) VerifyFrame(commit *wbls.G1Point, f *enc.Frame, i
 
 }
 
+// VerifyFrames verifies each frame at the index of the same position against the commitment, and returns the error of
+// the first frame that fails
+func (v *ParametrizedVerifier) VerifyFrames(commit *wbls.G1Point, frames []enc.Frame, indices []uint64) error {
+	if len(indices) != len(frames) {
+		return errors.New("number of indices must match number of frames")
+	}
+	for i := range frames {
+		if err := v.VerifyFrame(commit, &frames[i], indices[i]); err != nil {
+			return fmt.Errorf("frame %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// VerifyFramesParallel is identical to VerifyFrames except that the frames are verified across numWorker goroutines.
+// Once a frame fails, the frames after it are skipped, while the frames before it are still verified, so that the
+// returned error is the one of the first failing frame, as in VerifyFrames.
+func (v *ParametrizedVerifier) VerifyFramesParallel(commit *wbls.G1Point, frames []enc.Frame, indices []uint64, numWorker uint64) error {
+	if len(indices) != len(frames) {
+		return errors.New("number of indices must match number of frames")
+	}
+	if numWorker == 0 {
+		numWorker = 1
+	}
+
+	// firstFailed is the position of the first frame known to fail, or the number of frames if none has failed
+	var firstFailed atomic.Int64
+	firstFailed.Store(int64(len(frames)))
+	errs := make([]error, len(frames))
+
+	jobChan := make(chan int, numWorker)
+	var wg sync.WaitGroup
+	for w := uint64(0); w < numWorker; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobChan {
+				if int64(i) > firstFailed.Load() {
+					continue
+				}
+				errs[i] = v.VerifyFrame(commit, &frames[i], indices[i])
+				if errs[i] == nil {
+					continue
+				}
+				for {
+					failed := firstFailed.Load()
+					if int64(i) >= failed || firstFailed.CompareAndSwap(failed, int64(i)) {
+						break
+					}
+				}
+			}
+		}()
+	}
+
+	for i := range frames {
+		if int64(i) > firstFailed.Load() {
+			break
+		}
+		jobChan <- i
+	}
+	close(jobChan)
+	wg.Wait()
+
+	if i := firstFailed.Load(); i < int64(len(frames)) {
+		return fmt.Errorf("frame %d: %w", i, errs[i])
+	}
+	return nil
+}
+
 // Verify function assumes the Data stored is coefficients of coset's interpolating poly
 func VerifyFrame(f *enc.Frame, ks *kzg.KZGSettings, commitment *bls.G1Point, x *bls.Fr, g2Atn *bls.G2Point) bool {
 	var xPow bls.Fr
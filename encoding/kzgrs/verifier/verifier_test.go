@@ -3,10 +3,15 @@ package verifier_test
 import (
 	"log"
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 
 	"github.com/Layr-Labs/eigenda/encoding/kzgrs"
+	"github.com/Layr-Labs/eigenda/encoding/kzgrs/prover"
+	"github.com/Layr-Labs/eigenda/encoding/kzgrs/verifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -45,3 +50,37 @@ func setupSuite(t *testing.T) func(t *testing.T) {
 		os.RemoveAll("./data")
 	}
 }
+
+func TestNewVerifierMismatchedG2PowerOf2(t *testing.T) {
+	teardownSuite := setupSuite(t)
+	defer teardownSuite(t)
+
+	// swap the [tau^2] and [tau^4] points of the power of 2 file
+	data, err := os.ReadFile(kzgConfig.G2PowerOf2Path)
+	require.NoError(t, err)
+	mismatched := make([]byte, len(data))
+	copy(mismatched, data)
+	copy(mismatched[kzgrs.G2PointBytes:2*kzgrs.G2PointBytes], data[2*kzgrs.G2PointBytes:3*kzgrs.G2PointBytes])
+	copy(mismatched[2*kzgrs.G2PointBytes:3*kzgrs.G2PointBytes], data[kzgrs.G2PointBytes:2*kzgrs.G2PointBytes])
+	mismatchedPath := filepath.Join(t.TempDir(), "g2.point.powerOf2")
+	require.NoError(t, os.WriteFile(mismatchedPath, mismatched, 0644))
+
+	_, err = verifier.NewVerifier(kzgConfig, true)
+	assert.NoError(t, err)
+
+	config := *kzgConfig
+	config.G2PowerOf2Path = mismatchedPath
+	_, err = verifier.NewVerifier(&config, true)
+	assert.ErrorIs(t, err, kzgrs.ErrG2PowerOf2Mismatch)
+	_, err = prover.NewProver(&config, true)
+	assert.ErrorIs(t, err, kzgrs.ErrG2PowerOf2Mismatch)
+
+	// without G2Path, the points are checked against the G1 points
+	config.G2Path = ""
+	_, err = verifier.NewVerifier(&config, false)
+	assert.ErrorIs(t, err, kzgrs.ErrG2PowerOf2Mismatch)
+
+	config.G2PowerOf2Path = kzgConfig.G2PowerOf2Path
+	_, err = verifier.NewVerifier(&config, false)
+	assert.NoError(t, err)
+}
@@ -2,6 +2,7 @@ package rs
 
 import (
 	"errors"
+	"sync"
 
 	bls "github.com/Layr-Labs/eigenda/pkg/kzg/bn254"
 )
@@ -36,14 +37,81 @@ func (g *Encoder) Decode(frames []Frame, indices []uint64, maxInputSize uint64)
 			return nil, err
 		}
 
-		// Some pattern i butterfly swap. Find the leading coset, then increment by number of coset
-		for j := uint64(0); j < g.ChunkLen; j++ {
-			p := j*g.NumChunks + uint64(e)
-			samples[p] = new(bls.Fr)
-			bls.CopyFr(samples[p], &evals[j])
+		g.fillSamples(samples, evals, e)
+	}
+
+	return g.recoverData(samples, maxInputSize)
+}
+
+// DecodeParallel is identical to Decode except that the interpolation of the frames, which
+// requires one FFT per frame, is distributed across numWorker goroutines. The samples are
+// still assembled in the order of the input frames, so the output is bit-identical to Decode.
+func (g *Encoder) DecodeParallel(frames []Frame, indices []uint64, maxInputSize uint64, numWorker uint64) ([]byte, error) {
+	numSys := GetNumSys(maxInputSize, g.ChunkLen)
+
+	if uint64(len(frames)) < numSys {
+		return nil, errors.New("number of frame must be sufficient")
+	}
+	if len(indices) > len(frames) {
+		return nil, errors.New("number of indices must not exceed number of frames")
+	}
+	if numWorker == 0 {
+		numWorker = 1
+	}
+
+	cosets := make([]uint32, len(indices))
+	evals := make([][]bls.Fr, len(indices))
+	errs := make([]error, len(indices))
+
+	jobChan := make(chan int, numWorker)
+	var wg sync.WaitGroup
+	for w := uint64(0); w < numWorker; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobChan {
+				e, err := GetLeadingCosetIndex(indices[i], g.NumChunks)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				cosets[i] = e
+				evals[i], errs[i] = g.GetInterpolationPolyEval(frames[i].Coeffs, e)
+			}
+		}()
+	}
+
+	for i := range indices {
+		jobChan <- i
+	}
+	close(jobChan)
+	wg.Wait()
+
+	samples := make([]*bls.Fr, g.NumEvaluations())
+	// fill samples in frame order, so duplicated indices resolve the same way as in Decode
+	for i := range indices {
+		if errs[i] != nil {
+			return nil, errs[i]
 		}
+		g.fillSamples(samples, evals[i], cosets[i])
 	}
 
+	return g.recoverData(samples, maxInputSize)
+}
+
+// fillSamples copies the evaluations of the frame whose leading coset is e into samples.
+func (g *Encoder) fillSamples(samples []*bls.Fr, evals []bls.Fr, e uint32) {
+	// Some pattern i butterfly swap. Find the leading coset, then increment by number of coset
+	for j := uint64(0); j < g.ChunkLen; j++ {
+		p := j*g.NumChunks + uint64(e)
+		samples[p] = new(bls.Fr)
+		bls.CopyFr(samples[p], &evals[j])
+	}
+}
+
+// recoverData recovers the polynomial from the (possibly incomplete) samples and returns
+// the original data, trimmed to maxInputSize.
+func (g *Encoder) recoverData(samples []*bls.Fr, maxInputSize uint64) ([]byte, error) {
 	reconstructedData := make([]bls.Fr, g.NumEvaluations())
 	missingIndices := false
 	for i, s := range samples {
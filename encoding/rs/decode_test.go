@@ -0,0 +1,97 @@
+package rs_test
+
+import (
+	"crypto/rand"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Layr-Labs/eigenda/encoding/rs"
+)
+
+func TestDecodeParallel_MatchesSerialDecode(t *testing.T) {
+	teardownSuite := setupSuite(t)
+	defer teardownSuite(t)
+
+	data := make([]byte, 16*1024)
+	_, err := rand.Read(data)
+	require.Nil(t, err)
+
+	for _, numChunks := range []uint64{4, 16, 64, 256} {
+		t.Run(fmt.Sprintf("NumChunks=%d", numChunks), func(t *testing.T) {
+			numSys := numChunks / 2
+			params := rs.GetEncodingParams(numSys, numChunks-numSys, uint64(len(data)))
+			enc, err := rs.NewEncoder(params, false)
+			require.Nil(t, err)
+
+			_, frames, _, err := enc.Encode(rs.ToFrArray(data))
+			require.Nil(t, err)
+
+			// decode both from all frames and from the minimum number of frames, which requires recovery
+			for _, num := range []uint64{uint64(len(frames)), numSys} {
+				samples, indices := sampleFrames(frames, num)
+
+				serial, err := enc.Decode(samples, indices, uint64(len(data)))
+				require.Nil(t, err)
+
+				for _, numWorker := range []uint64{1, 3, uint64(runtime.GOMAXPROCS(0))} {
+					parallel, err := enc.DecodeParallel(samples, indices, uint64(len(data)), numWorker)
+					require.Nil(t, err)
+					assert.Equal(t, serial, parallel)
+				}
+				assert.Equal(t, data, serial)
+			}
+		})
+	}
+}
+
+func TestDecodeParallel_ErrorsWhenNotEnoughSampledFrames(t *testing.T) {
+	teardownSuite := setupSuite(t)
+	defer teardownSuite(t)
+
+	params := rs.GetEncodingParams(numSys, numPar, uint64(len(GETTYSBURG_ADDRESS_BYTES)))
+	enc, _ := rs.NewEncoder(params, false)
+	require.NotNil(t, enc)
+
+	_, frames, _, err := enc.Encode(rs.ToFrArray(GETTYSBURG_ADDRESS_BYTES))
+	assert.Nil(t, err)
+
+	samples, indices := sampleFrames(frames, uint64(len(frames)-2))
+	data, err := enc.DecodeParallel(samples, indices, uint64(len(GETTYSBURG_ADDRESS_BYTES)), 4)
+
+	require.Nil(t, data)
+	assert.EqualError(t, err, "number of frame must be sufficient")
+}
+
+func BenchmarkDecode(b *testing.B) {
+	numChunks := uint64(256)
+	data := make([]byte, 400*1024)
+	_, _ = rand.Read(data)
+
+	params := rs.GetEncodingParams(numChunks/2, numChunks/2, uint64(len(data)))
+	enc, err := rs.NewEncoder(params, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	_, frames, _, err := enc.Encode(rs.ToFrArray(data))
+	if err != nil {
+		b.Fatal(err)
+	}
+	samples, indices := sampleFrames(frames, numChunks/2)
+
+	b.Run("Serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = enc.Decode(samples, indices, uint64(len(data)))
+		}
+	})
+
+	b.Run("Parallel", func(b *testing.B) {
+		numWorker := uint64(runtime.GOMAXPROCS(0))
+		for i := 0; i < b.N; i++ {
+			_, _ = enc.DecodeParallel(samples, indices, uint64(len(data)), numWorker)
+		}
+	})
+}
@@ -191,7 +191,7 @@ func setupRetrievalClient(testConfig *deploy.Config) error {
 		return err
 	}
 
-	retrievalClient, err = clients.NewRetrievalClient(logger, ics, agn, nodeClient, encoder, 10)
+	retrievalClient, err = clients.NewRetrievalClient(logger, ics, agn, nodeClient, encoder, 10, 0, nil)
 	if err != nil {
 		return err
 	}
@@ -214,7 +214,13 @@ func (s *Server) RetrieveChunks(ctx context.Context, in *pb.RetrieveChunksReques
 		return nil, fmt.Errorf("could not find chunks for batchHeaderHash %v, blob index: %v, quorumID: %v", batchHeaderHash, in.GetBlobIndex(), in.GetQuorumId())
 	}
 	s.node.Metrics.RecordRPCRequest("RetrieveChunks", "success")
-	return &pb.RetrieveChunksReply{Chunks: chunks}, nil
+
+	reply := &pb.RetrieveChunksReply{Chunks: chunks}
+	if in.GetAttestStorage() {
+		message := core.ComputeStorageAttestationMessage(batchHeaderHash, in.GetBlobIndex(), uint8(in.GetQuorumId()), chunks)
+		reply.StorageSignature = s.node.KeyPair.SignMessage(message).Serialize()
+	}
+	return reply, nil
 }
 
 func (s *Server) GetBlobHeader(ctx context.Context, in *pb.GetBlobHeaderRequest) (*pb.GetBlobHeaderReply, error) {
@@ -236,7 +242,7 @@ func (s *Server) GetBlobHeader(ctx context.Context, in *pb.GetBlobHeaderRequest)
 		return nil, err
 	}
 
-	proof, err := tree.GenerateProof(blobHeaderHash[:], 0)
+	proof, err := tree.GenerateProof(blobHeaderHash[:])
 	if err != nil {
 		return nil, err
 	}
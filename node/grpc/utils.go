@@ -3,24 +3,29 @@ package grpc
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
 	"reflect"
 
 	pb "github.com/Layr-Labs/eigenda/api/grpc/node"
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/node"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
-	"github.com/wealdtech/go-merkletree"
-	"github.com/wealdtech/go-merkletree/keccak256"
 	"google.golang.org/protobuf/proto"
 )
 
 // Constructs a core.BatchHeader from a proto of pb.StoreChunksRequest.
 func GetBatchHeader(in *pb.StoreChunksRequest) (*core.BatchHeader, error) {
+	// The node signs with its BN254 BLS key only
+	if scheme := in.GetBatchHeader().GetSigningScheme(); scheme != uint32(core.SigningSchemeBN254BLS) {
+		return nil, fmt.Errorf("%w: %d", core.ErrUnsupportedSigningScheme, scheme)
+	}
 	var batchRoot [32]byte
 	copy(batchRoot[:], in.GetBatchHeader().GetBatchRoot())
 	batchHeader := core.BatchHeader{
 		ReferenceBlockNumber: uint(in.BatchHeader.ReferenceBlockNumber),
 		BatchRoot:            batchRoot,
+		SigningScheme:        core.SigningSchemeID(in.GetBatchHeader().GetSigningScheme()),
 	}
 	return &batchHeader, nil
 }
@@ -58,6 +63,10 @@ func GetBlobMessages(in *pb.StoreChunksRequest) ([]*core.BlobMessage, error) {
 
 // Constructs a core.BlobHeader from a proto of pb.BlobHeader.
 func GetBlobHeaderFromProto(h *pb.BlobHeader) (*core.BlobHeader, error) {
+	if h.GetVersion() > math.MaxUint8 {
+		return nil, fmt.Errorf("invalid blob header version: %d", h.GetVersion())
+	}
+
 	commitX := new(fp.Element).SetBytes(h.GetCommitment().GetX())
 	commitY := new(fp.Element).SetBytes(h.GetCommitment().GetY())
 	commitment := &core.G1Commitment{
@@ -100,11 +109,12 @@ func GetBlobHeaderFromProto(h *pb.BlobHeader) (*core.BlobHeader, error) {
 		},
 		QuorumInfos: quorumHeaders,
 		AccountID:   h.AccountId,
+		Version:     uint8(h.GetVersion()),
 	}, nil
 }
 
 // rebuildMerkleTree rebuilds the merkle tree from the blob headers and batch header.
-func (s *Server) rebuildMerkleTree(batchHeaderHash [32]byte, quorumID uint8) (*merkletree.MerkleTree, error) {
+func (s *Server) rebuildMerkleTree(batchHeaderHash [32]byte, quorumID uint8) (*core.BatchMerkleTree, error) {
 	batchHeaderBytes, err := s.node.Store.GetBatchHeader(context.Background(), batchHeaderHash)
 	if err != nil {
 		return nil, errors.New("failed to get the batch header from Store")
@@ -149,7 +159,7 @@ func (s *Server) rebuildMerkleTree(batchHeaderHash [32]byte, quorumID uint8) (*m
 		return nil, errors.New("no blob header found")
 	}
 
-	tree, err := merkletree.NewTree(merkletree.WithData(leafs), merkletree.WithHashType(keccak256.New()))
+	tree, err := core.NewBatchMerkleTree(leafs)
 	if err != nil {
 		return nil, err
 	}
@@ -344,7 +344,11 @@ func (n *Node) ProcessBatch(ctx context.Context, header *core.BatchHeader, blobs
 
 	// Sign batch header hash if all validation checks pass and data items are written to database.
 	stageTimer = time.Now()
-	sig := n.KeyPair.SignMessage(batchHeaderHash)
+	signingMessage, err := header.GetSigningMessage()
+	if err != nil {
+		return nil, err
+	}
+	sig := n.KeyPair.SignMessage(signingMessage)
 	log.Trace("Signed batch header hash", "pubkey", hexutil.Encode(n.KeyPair.GetPubKeyG2().Serialize()))
 	n.Metrics.AcceptBatches("signed", batchSize)
 	n.Metrics.ObserveLatency("StoreChunks", "signed", float64(time.Since(stageTimer).Milliseconds()))
@@ -126,12 +126,12 @@ func RetrieverMain(ctx *cli.Context) error {
 	}
 
 	agn := &core.StdAssignmentCoordinator{}
-	retrievalClient, err := clients.NewRetrievalClient(logger, ics, agn, nodeClient, encoder, config.NumConnections)
+	retrievalClient, err := clients.NewRetrievalClient(logger, ics, agn, nodeClient, encoder, config.NumConnections, config.OverRequestFactor, nil)
 	if err != nil {
 		log.Fatalln("could not start tcp listener", err)
 	}
 
-	chainClient := retrivereth.NewChainClient(gethClient, logger)
+	chainClient := retrivereth.NewChainClient(gethClient, logger, config.EigenDAStartBlock, config.EigenDAConfirmationDepth)
 	retrieverServiceServer := retriever.NewServer(config, logger, retrievalClient, encoder, ics, chainClient)
 	if err = retrieverServiceServer.Start(context.Background()); err != nil {
 		log.Fatalln("failed to start retriever service server", err)
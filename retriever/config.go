@@ -23,8 +23,12 @@ type Config struct {
 	NumConnections                int
 	BLSOperatorStateRetrieverAddr string
 	EigenDAServiceManagerAddr     string
+	EigenDAStartBlock             uint64
+	EigenDAConfirmationDepth      uint64
 	GraphUrl                      string
 	UseGraph                      bool
+	OverRequestFactor             float64
+	CacheOperatorState            bool
 }
 
 func NewConfig(ctx *cli.Context) *Config {
@@ -41,7 +45,11 @@ func NewConfig(ctx *cli.Context) *Config {
 		NumConnections:                ctx.Int(flags.NumConnectionsFlag.Name),
 		BLSOperatorStateRetrieverAddr: ctx.GlobalString(flags.BlsOperatorStateRetrieverFlag.Name),
 		EigenDAServiceManagerAddr:     ctx.GlobalString(flags.EigenDAServiceManagerFlag.Name),
+		EigenDAStartBlock:             ctx.GlobalUint64(flags.EigenDAStartBlockFlag.Name),
+		EigenDAConfirmationDepth:      ctx.GlobalUint64(flags.EigenDAConfirmationDepthFlag.Name),
 		GraphUrl:                      ctx.GlobalString(flags.GraphUrlFlag.Name),
 		UseGraph:                      ctx.GlobalBool(flags.UseGraphFlag.Name),
+		OverRequestFactor:             ctx.GlobalFloat64(flags.OverRequestFactorFlag.Name),
+		CacheOperatorState:            ctx.GlobalBool(flags.CacheOperatorStateFlag.Name),
 	}
 }
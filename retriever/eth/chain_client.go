@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math/big"
+	"sync"
 
 	"github.com/Layr-Labs/eigenda/common"
 	binding "github.com/Layr-Labs/eigenda/contracts/bindings/EigenDAServiceManager"
@@ -14,17 +16,47 @@ import (
 
 type ChainClient interface {
 	FetchBatchHeader(ctx context.Context, serviceManagerAddress gcommon.Address, batchHeaderHash []byte) (*binding.IEigenDAServiceManagerBatchHeader, error)
+	FetchBatchHeaderByID(ctx context.Context, serviceManagerAddress gcommon.Address, batchID uint32) ([32]byte, *binding.IEigenDAServiceManagerBatchHeader, error)
 }
 
+// batchLogsBlockRange is the number of blocks whose BatchConfirmed events are filtered at once when looking up a batch
+// by its ID
+const batchLogsBlockRange = 10_000
+
 type chainClient struct {
 	ethClient common.EthClient
 	logger    common.Logger
+	// startBlock is the first block searched for BatchConfirmed events when looking up a batch by its ID, i.e. the block
+	// the service manager contract was deployed at
+	startBlock uint64
+	// confirmationDepth is the number of blocks behind the current block whose BatchConfirmed events may still be
+	// reorged out. Only the events of the blocks before them are indexed
+	confirmationDepth uint64
+
+	// batchIndexes holds the batch header hashes by batch ID of each service manager contract, see FetchBatchHeaderByID
+	batchIndexes   map[gcommon.Address]*batchIndex
+	batchIndexesMu sync.Mutex
+	// scanMu is held while BatchConfirmed events are scanned, so that concurrent lookups don't scan the same blocks
+	scanMu sync.Mutex
+}
+
+// batchIndex holds the header hashes of the batches confirmed before nextBlock, by batch ID
+type batchIndex struct {
+	headerHashes map[uint32]gcommon.Hash
+	// nextBlock is the first block whose BatchConfirmed events aren't indexed yet
+	nextBlock uint64
 }
 
-func NewChainClient(ethClient common.EthClient, logger common.Logger) *chainClient {
+// NewChainClient returns a chain client that looks up batches by their ID from the BatchConfirmed events emitted since
+// the given start block. The events of the last confirmationDepth blocks are searched on each lookup but never indexed,
+// so that a reorg of those blocks can't leave a stale batch header hash in the index
+func NewChainClient(ethClient common.EthClient, logger common.Logger, startBlock uint64, confirmationDepth uint64) *chainClient {
 	return &chainClient{
-		ethClient: ethClient,
-		logger:    logger,
+		ethClient:         ethClient,
+		logger:            logger,
+		startBlock:        startBlock,
+		confirmationDepth: confirmationDepth,
+		batchIndexes:      make(map[gcommon.Address]*batchIndex),
 	}
 }
 
@@ -84,3 +116,159 @@ func (c *chainClient) FetchBatchHeader(ctx context.Context, serviceManagerAddres
 
 	return (*binding.IEigenDAServiceManagerBatchHeader)(&batchHeaderInput), nil
 }
+
+// FetchBatchHeaderByID fetches the batch header hash and batch header from chain given a service manager contract
+// address and batch ID. The batch ID is not indexed by the BatchConfirmed event, so the events are scanned in bounded
+// block ranges from the start block, and the header hash of each batch seen at least confirmationDepth blocks behind
+// the current block is kept by batch ID. Each of those blocks is only scanned once: later lookups scan the blocks
+// added since, and the blocks within the confirmation depth, which are scanned again by each lookup.
+func (c *chainClient) FetchBatchHeaderByID(ctx context.Context, serviceManagerAddress gcommon.Address, batchID uint32) ([32]byte, *binding.IEigenDAServiceManagerBatchHeader, error) {
+	batchHeaderHash, ok := c.lookupBatchHeaderHash(serviceManagerAddress, batchID)
+	if !ok {
+		var err error
+		batchHeaderHash, ok, err = c.scanBatchHeaderHash(ctx, serviceManagerAddress, batchID)
+		if err != nil {
+			return [32]byte{}, nil, err
+		}
+		if !ok {
+			return [32]byte{}, nil, fmt.Errorf("could not find confirmBatch event for batch ID %d", batchID)
+		}
+	}
+
+	batchHeader, err := c.FetchBatchHeader(ctx, serviceManagerAddress, batchHeaderHash.Bytes())
+	if err != nil {
+		return [32]byte{}, nil, err
+	}
+	return batchHeaderHash, batchHeader, nil
+}
+
+// lookupBatchHeaderHash returns the indexed header hash of the batch with the given ID
+func (c *chainClient) lookupBatchHeaderHash(serviceManagerAddress gcommon.Address, batchID uint32) (gcommon.Hash, bool) {
+	c.batchIndexesMu.Lock()
+	defer c.batchIndexesMu.Unlock()
+	index, ok := c.batchIndexes[serviceManagerAddress]
+	if !ok {
+		return gcommon.Hash{}, false
+	}
+	hash, ok := index.headerHashes[batchID]
+	return hash, ok
+}
+
+// scanBatchHeaderHash indexes the BatchConfirmed events of the blocks that aren't indexed yet, up to the confirmation
+// depth behind the current block, until the batch with the given ID is found. If it isn't, the blocks within the
+// confirmation depth are searched without indexing them
+func (c *chainClient) scanBatchHeaderHash(ctx context.Context, serviceManagerAddress gcommon.Address, batchID uint32) (gcommon.Hash, bool, error) {
+	c.scanMu.Lock()
+	defer c.scanMu.Unlock()
+
+	// the batch may have been indexed by the scan this one waited for
+	if hash, ok := c.lookupBatchHeaderHash(serviceManagerAddress, batchID); ok {
+		return hash, true, nil
+	}
+
+	currentBlock, err := c.ethClient.GetCurrentBlockNumber(ctx)
+	if err != nil {
+		return gcommon.Hash{}, false, err
+	}
+	smAbi, err := abi.JSON(bytes.NewReader(common.ServiceManagerAbi))
+	if err != nil {
+		return gcommon.Hash{}, false, err
+	}
+	endBlock := uint64(currentBlock) + 1
+	// confirmedEndBlock is the first block within the confirmation depth
+	confirmedEndBlock := endBlock - min(c.confirmationDepth, endBlock)
+
+	c.batchIndexesMu.Lock()
+	index, ok := c.batchIndexes[serviceManagerAddress]
+	if !ok {
+		index = &batchIndex{
+			headerHashes: make(map[uint32]gcommon.Hash),
+			nextBlock:    c.startBlock,
+		}
+		c.batchIndexes[serviceManagerAddress] = index
+	}
+	fromBlock := index.nextBlock
+	c.batchIndexesMu.Unlock()
+
+	for fromBlock < endBlock {
+		confirmed := fromBlock < confirmedEndBlock
+		toBlock := min(fromBlock+batchLogsBlockRange, endBlock) - 1
+		if confirmed {
+			toBlock = min(toBlock, confirmedEndBlock-1)
+		}
+		headerHashes, err := c.filterBatchHeaderHashes(ctx, smAbi, serviceManagerAddress, fromBlock, toBlock)
+		if err != nil {
+			return gcommon.Hash{}, false, err
+		}
+
+		if confirmed {
+			c.batchIndexesMu.Lock()
+			for id, hash := range headerHashes {
+				index.headerHashes[id] = hash
+			}
+			index.nextBlock = toBlock + 1
+			c.batchIndexesMu.Unlock()
+		}
+
+		if hash, ok := headerHashes[batchID]; ok {
+			return hash, true, nil
+		}
+		fromBlock = toBlock + 1
+	}
+	return gcommon.Hash{}, false, nil
+}
+
+// filterBatchHeaderHashes returns the header hashes of the batches confirmed in the given block range, by batch ID
+func (c *chainClient) filterBatchHeaderHashes(ctx context.Context, smAbi abi.ABI, serviceManagerAddress gcommon.Address, fromBlock, toBlock uint64) (map[uint32]gcommon.Hash, error) {
+	logs, err := c.ethClient.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: []gcommon.Address{serviceManagerAddress},
+		Topics: [][]gcommon.Hash{
+			{common.BatchConfirmedEventSigHash},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	headerHashes := make(map[uint32]gcommon.Hash, len(logs))
+	for _, txnLog := range logs {
+		if txnLog.Removed || len(txnLog.Topics) < 2 {
+			continue
+		}
+		values, err := smAbi.Unpack("BatchConfirmed", txnLog.Data)
+		if err != nil {
+			c.logger.Warn("failed to unpack BatchConfirmed event", "txHash", txnLog.TxHash.Hex(), "err", err)
+			continue
+		}
+		if len(values) != 1 {
+			continue
+		}
+		headerHashes[values[0].(uint32)] = txnLog.Topics[1]
+	}
+	return headerHashes, nil
+}
+
+// BatchRootFetcher fetches the batch roots confirmed onchain by the service manager contract at the given address,
+// so that the retrieval client can verify blob headers against them, see clients.WithOnchainBatchRoot
+type BatchRootFetcher struct {
+	chainClient           ChainClient
+	serviceManagerAddress gcommon.Address
+}
+
+func NewBatchRootFetcher(chainClient ChainClient, serviceManagerAddress gcommon.Address) *BatchRootFetcher {
+	return &BatchRootFetcher{
+		chainClient:           chainClient,
+		serviceManagerAddress: serviceManagerAddress,
+	}
+}
+
+// FetchBatchRoot fetches the root of the blob headers of the batch with the given batch header hash from chain
+func (f *BatchRootFetcher) FetchBatchRoot(ctx context.Context, batchHeaderHash [32]byte) ([32]byte, error) {
+	batchHeader, err := f.chainClient.FetchBatchHeader(ctx, f.serviceManagerAddress, batchHeaderHash[:])
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return batchHeader.BlobHeadersRoot, nil
+}
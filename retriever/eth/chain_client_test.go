@@ -3,6 +3,7 @@ package eth_test
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -10,18 +11,52 @@ import (
 	damock "github.com/Layr-Labs/eigenda/common/mock"
 	binding "github.com/Layr-Labs/eigenda/contracts/bindings/EigenDAServiceManager"
 	"github.com/Layr-Labs/eigenda/retriever/eth"
+	"github.com/Layr-Labs/eigenda/retriever/mock"
 	"github.com/ethereum/go-ethereum"
 	gcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
 )
 
+// expectedHeader is the batch header in the calldata of the transaction mocked by mockConfirmBatchTransaction
+var expectedHeader = binding.IEigenDAServiceManagerBatchHeader{
+	BlobHeadersRoot:            [32]byte{0},
+	QuorumNumbers:              []byte{0},
+	QuorumThresholdPercentages: []byte{100},
+	ReferenceBlockNumber:       86,
+}
+
+// mockConfirmBatchTransaction mocks a confirmBatch transaction with the given hash, whose calldata contains expectedHeader
+func mockConfirmBatchTransaction(t *testing.T, ethClient *damock.MockEthClient, serviceManagerAddress gcommon.Address, txHash gcommon.Hash) {
+	calldata, err := hex.DecodeString("7794965a000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000001400000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000008000000000000000000000000000000000000000000000000000000000000000c000000000000000000000000000000000000000000000000000000000000000560000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000016400000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000018000000000000000000000000000000000000000000000000000000000000001a000000000000000000000000000000000000000000000000000000000000001c01b4136a161225e9cebe4e2c561148043b2fde423fc5b64e01d897d0fb7970a142d5474fb609bda1b747bdb5c47375d5819000e3c5cbc75baf55b19849410a2610de9c40eb95b49aca940e0bec6ae8b2868855a6324d04d864cbfa61128cf06a51c069e5a0c490c5a359086b0a3660c2ea2e4fb50722bec1ef593c5245413e4cd0a3c7e490348fb279ccb58f91a3bd494511c2ab0321e3922a0cd26012ef3133c043acb758e735db805d360196f3fc89a6395a4b174c19b981afb7f64c2b1193e0000000000000000000000000000000000000000000000000000000000000220000000000000000000000000000000000000000000000000000000000000026000000000000000000000000000000000000000000000000000000000000002a0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001170c867415fef7db6d88e37598228f43de085616a25939dacbb6b5900f680c7f1d582c9ea38023afb08f368ea93692d17946619d9cf5f3c4d7b3c0cff1a92dff0000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000300000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000003000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000000")
+	assert.Nil(t, err)
+	r, ok := new(big.Int).SetString("8ad2b300a012fb0e90dceb8b66fa564717a2d218ca0fd25f11a1875e0153d1d8", 16)
+	assert.True(t, ok)
+	s, ok := new(big.Int).SetString("1accb1e1c69fa07bd4237d92143275960b24eec780862a673d54ffaaa5e77f9b", 16)
+	assert.True(t, ok)
+	ethClient.On("TransactionByHash", txHash).Return(
+		types.NewTx(&types.DynamicFeeTx{
+			ChainID:    big.NewInt(1),
+			Nonce:      1,
+			GasTipCap:  big.NewInt(1_000_000),
+			GasFeeCap:  big.NewInt(1_000_000),
+			Gas:        298617,
+			To:         &serviceManagerAddress,
+			Value:      big.NewInt(0),
+			Data:       calldata,
+			AccessList: types.AccessList{},
+			V:          big.NewInt(0x1),
+			R:          r,
+			S:          s,
+		}), false, nil)
+}
+
 func TestFetchBatchHeader(t *testing.T) {
 	ethClient := &damock.MockEthClient{}
 	logger := damock.Logger{}
 	serviceManagerAddress := gcommon.HexToAddress("0x0000000000000000000000000000000000000000")
 	batchHeaderHash := []byte("hashhash")
-	chainClient := eth.NewChainClient(ethClient, &logger)
+	chainClient := eth.NewChainClient(ethClient, &logger, 0, 0)
 	topics := [][]gcommon.Hash{
 		{common.BatchConfirmedEventSigHash},
 		{gcommon.BytesToHash(batchHeaderHash)},
@@ -44,33 +79,7 @@ func TestFetchBatchHeader(t *testing.T) {
 			Index:       0,
 		},
 	}, nil)
-	expectedHeader := binding.IEigenDAServiceManagerBatchHeader{
-		BlobHeadersRoot:            [32]byte{0},
-		QuorumNumbers:              []byte{0},
-		QuorumThresholdPercentages: []byte{100},
-		ReferenceBlockNumber:       86,
-	}
-	calldata, err := hex.DecodeString("7794965a000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000001400000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000008000000000000000000000000000000000000000000000000000000000000000c000000000000000000000000000000000000000000000000000000000000000560000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000016400000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000018000000000000000000000000000000000000000000000000000000000000001a000000000000000000000000000000000000000000000000000000000000001c01b4136a161225e9cebe4e2c561148043b2fde423fc5b64e01d897d0fb7970a142d5474fb609bda1b747bdb5c47375d5819000e3c5cbc75baf55b19849410a2610de9c40eb95b49aca940e0bec6ae8b2868855a6324d04d864cbfa61128cf06a51c069e5a0c490c5a359086b0a3660c2ea2e4fb50722bec1ef593c5245413e4cd0a3c7e490348fb279ccb58f91a3bd494511c2ab0321e3922a0cd26012ef3133c043acb758e735db805d360196f3fc89a6395a4b174c19b981afb7f64c2b1193e0000000000000000000000000000000000000000000000000000000000000220000000000000000000000000000000000000000000000000000000000000026000000000000000000000000000000000000000000000000000000000000002a0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001170c867415fef7db6d88e37598228f43de085616a25939dacbb6b5900f680c7f1d582c9ea38023afb08f368ea93692d17946619d9cf5f3c4d7b3c0cff1a92dff0000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000300000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000003000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000000")
-	assert.Nil(t, err)
-	r, ok := new(big.Int).SetString("8ad2b300a012fb0e90dceb8b66fa564717a2d218ca0fd25f11a1875e0153d1d8", 16)
-	assert.True(t, ok)
-	s, ok := new(big.Int).SetString("1accb1e1c69fa07bd4237d92143275960b24eec780862a673d54ffaaa5e77f9b", 16)
-	assert.True(t, ok)
-	ethClient.On("TransactionByHash", txHash).Return(
-		types.NewTx(&types.DynamicFeeTx{
-			ChainID:    big.NewInt(1),
-			Nonce:      1,
-			GasTipCap:  big.NewInt(1_000_000),
-			GasFeeCap:  big.NewInt(1_000_000),
-			Gas:        298617,
-			To:         &serviceManagerAddress,
-			Value:      big.NewInt(0),
-			Data:       calldata,
-			AccessList: types.AccessList{},
-			V:          big.NewInt(0x1),
-			R:          r,
-			S:          s,
-		}), false, nil)
+	mockConfirmBatchTransaction(t, ethClient, serviceManagerAddress, txHash)
 	batchHeader, err := chainClient.FetchBatchHeader(context.Background(), serviceManagerAddress, batchHeaderHash)
 	assert.Nil(t, err)
 	assert.Equal(t, batchHeader.BlobHeadersRoot, expectedHeader.BlobHeadersRoot)
@@ -78,3 +87,161 @@ func TestFetchBatchHeader(t *testing.T) {
 	assert.Equal(t, batchHeader.QuorumThresholdPercentages, expectedHeader.QuorumThresholdPercentages)
 	assert.Equal(t, batchHeader.ReferenceBlockNumber, expectedHeader.ReferenceBlockNumber)
 }
+
+func TestFetchBatchHeaderByID(t *testing.T) {
+	ethClient := &damock.MockEthClient{}
+	logger := damock.Logger{}
+	serviceManagerAddress := gcommon.HexToAddress("0x0000000000000000000000000000000000000000")
+	chainClient := eth.NewChainClient(ethClient, &logger, 5_000, 0)
+	batchConfirmedQuery := func(fromBlock, toBlock int64) ethereum.FilterQuery {
+		return ethereum.FilterQuery{
+			FromBlock: big.NewInt(fromBlock),
+			ToBlock:   big.NewInt(toBlock),
+			Addresses: []gcommon.Address{serviceManagerAddress},
+			Topics:    [][]gcommon.Hash{{common.BatchConfirmedEventSigHash}},
+		}
+	}
+
+	// BatchConfirmed events of batches 6 and 7, the batch ID being the only unindexed field, in the first two block
+	// ranges scanned from the start block
+	batchHeaderHash := gcommon.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000007")
+	txHash := gcommon.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000001")
+	ethClient.On("GetCurrentBlockNumber").Return(uint32(30_000))
+	ethClient.On("FilterLogs", batchConfirmedQuery(5_000, 14_999)).Return([]types.Log{
+		{
+			Address: serviceManagerAddress,
+			Topics:  []gcommon.Hash{common.BatchConfirmedEventSigHash, gcommon.HexToHash("0x06")},
+			Data:    gcommon.LeftPadBytes([]byte{6}, 32),
+			TxHash:  txHash,
+		},
+	}, nil).Once()
+	ethClient.On("FilterLogs", batchConfirmedQuery(15_000, 24_999)).Return([]types.Log{
+		{
+			Address: serviceManagerAddress,
+			Topics:  []gcommon.Hash{common.BatchConfirmedEventSigHash, batchHeaderHash},
+			Data:    gcommon.LeftPadBytes([]byte{7}, 32),
+			TxHash:  txHash,
+		},
+	}, nil).Once()
+	ethClient.On("FilterLogs", batchConfirmedQuery(25_000, 30_000)).Return([]types.Log{}, nil).Once()
+	for _, hash := range []gcommon.Hash{gcommon.HexToHash("0x06"), batchHeaderHash} {
+		ethClient.On("FilterLogs", ethereum.FilterQuery{
+			Addresses: []gcommon.Address{serviceManagerAddress},
+			Topics:    [][]gcommon.Hash{{common.BatchConfirmedEventSigHash}, {hash}},
+		}).Return([]types.Log{
+			{
+				Address: serviceManagerAddress,
+				Topics:  []gcommon.Hash{common.BatchConfirmedEventSigHash, hash},
+				TxHash:  txHash,
+			},
+		}, nil)
+	}
+	mockConfirmBatchTransaction(t, ethClient, serviceManagerAddress, txHash)
+
+	// the blocks are scanned until the batch is found
+	hash, batchHeader, err := chainClient.FetchBatchHeaderByID(context.Background(), serviceManagerAddress, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, [32]byte(batchHeaderHash), hash)
+	assert.Equal(t, expectedHeader.BlobHeadersRoot, batchHeader.BlobHeadersRoot)
+	assert.Equal(t, expectedHeader.ReferenceBlockNumber, batchHeader.ReferenceBlockNumber)
+	ethClient.AssertNumberOfCalls(t, "FilterLogs", 3)
+
+	// the batches seen by earlier scans are looked up without scanning again
+	hash, _, err = chainClient.FetchBatchHeaderByID(context.Background(), serviceManagerAddress, 6)
+	assert.Nil(t, err)
+	assert.Equal(t, [32]byte(gcommon.HexToHash("0x06")), hash)
+	ethClient.AssertNumberOfCalls(t, "FilterLogs", 4)
+
+	// only the blocks not scanned yet are scanned for unknown batches
+	_, _, err = chainClient.FetchBatchHeaderByID(context.Background(), serviceManagerAddress, 8)
+	assert.ErrorContains(t, err, "could not find confirmBatch event for batch ID 8")
+	ethClient.AssertNumberOfCalls(t, "FilterLogs", 5)
+	_, _, err = chainClient.FetchBatchHeaderByID(context.Background(), serviceManagerAddress, 8)
+	assert.ErrorContains(t, err, "could not find confirmBatch event for batch ID 8")
+	ethClient.AssertNumberOfCalls(t, "FilterLogs", 5)
+}
+
+func TestFetchBatchHeaderByIDConfirmationDepth(t *testing.T) {
+	ethClient := &damock.MockEthClient{}
+	logger := damock.Logger{}
+	serviceManagerAddress := gcommon.HexToAddress("0x0000000000000000000000000000000000000000")
+	chainClient := eth.NewChainClient(ethClient, &logger, 29_000, 100)
+	batchConfirmedQuery := func(fromBlock, toBlock int64) ethereum.FilterQuery {
+		return ethereum.FilterQuery{
+			FromBlock: big.NewInt(fromBlock),
+			ToBlock:   big.NewInt(toBlock),
+			Addresses: []gcommon.Address{serviceManagerAddress},
+			Topics:    [][]gcommon.Hash{{common.BatchConfirmedEventSigHash}},
+		}
+	}
+	batchConfirmedLog := func(batchID byte, hash gcommon.Hash) types.Log {
+		return types.Log{
+			Address: serviceManagerAddress,
+			Topics:  []gcommon.Hash{common.BatchConfirmedEventSigHash, hash},
+			Data:    gcommon.LeftPadBytes([]byte{batchID}, 32),
+		}
+	}
+
+	// batch 6 is confirmed more than 100 blocks behind the current block, and batch 7 within the last 100 blocks,
+	// where it is reorged into another batch header between the lookups
+	staleHash := gcommon.HexToHash("0x07")
+	reorgedHash := gcommon.HexToHash("0x77")
+	ethClient.On("GetCurrentBlockNumber").Return(uint32(30_000))
+	ethClient.On("FilterLogs", batchConfirmedQuery(29_000, 29_900)).Return([]types.Log{
+		batchConfirmedLog(6, gcommon.HexToHash("0x06")),
+	}, nil).Once()
+	ethClient.On("FilterLogs", batchConfirmedQuery(29_901, 30_000)).Return([]types.Log{
+		batchConfirmedLog(7, staleHash),
+	}, nil).Once()
+	ethClient.On("FilterLogs", batchConfirmedQuery(29_901, 30_000)).Return([]types.Log{
+		batchConfirmedLog(7, reorgedHash),
+	}, nil).Once()
+	txHash := gcommon.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000001")
+	for _, hash := range []gcommon.Hash{gcommon.HexToHash("0x06"), staleHash, reorgedHash} {
+		ethClient.On("FilterLogs", ethereum.FilterQuery{
+			Addresses: []gcommon.Address{serviceManagerAddress},
+			Topics:    [][]gcommon.Hash{{common.BatchConfirmedEventSigHash}, {hash}},
+		}).Return([]types.Log{
+			{
+				Address: serviceManagerAddress,
+				Topics:  []gcommon.Hash{common.BatchConfirmedEventSigHash, hash},
+				TxHash:  txHash,
+			},
+		}, nil)
+	}
+	mockConfirmBatchTransaction(t, ethClient, serviceManagerAddress, txHash)
+
+	hash, _, err := chainClient.FetchBatchHeaderByID(context.Background(), serviceManagerAddress, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, [32]byte(staleHash), hash)
+	ethClient.AssertNumberOfCalls(t, "FilterLogs", 3)
+
+	// the batch confirmed within the confirmation depth is searched again rather than served from the index
+	hash, _, err = chainClient.FetchBatchHeaderByID(context.Background(), serviceManagerAddress, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, [32]byte(reorgedHash), hash)
+	ethClient.AssertNumberOfCalls(t, "FilterLogs", 5)
+
+	// the batch confirmed before the confirmation depth is indexed
+	hash, _, err = chainClient.FetchBatchHeaderByID(context.Background(), serviceManagerAddress, 6)
+	assert.Nil(t, err)
+	assert.Equal(t, [32]byte(gcommon.HexToHash("0x06")), hash)
+	ethClient.AssertNumberOfCalls(t, "FilterLogs", 6)
+}
+
+func TestBatchRootFetcher(t *testing.T) {
+	chainClient := mock.NewMockChainClient()
+	fetcher := eth.NewBatchRootFetcher(chainClient, gcommon.HexToAddress("0x0000000000000000000000000000000000000000"))
+	onchainRoot := [32]byte{1, 2, 3}
+	chainClient.On("FetchBatchHeader").Return(&binding.IEigenDAServiceManagerBatchHeader{
+		BlobHeadersRoot:      onchainRoot,
+		ReferenceBlockNumber: 86,
+	}, nil).Once()
+	root, err := fetcher.FetchBatchRoot(context.Background(), [32]byte{4, 5, 6})
+	assert.NoError(t, err)
+	assert.Equal(t, onchainRoot, root)
+
+	chainClient.On("FetchBatchHeader").Return((*binding.IEigenDAServiceManagerBatchHeader)(nil), errors.New("no confirmBatch event")).Once()
+	_, err = fetcher.FetchBatchRoot(context.Background(), [32]byte{4, 5, 6})
+	assert.Error(t, err)
+}
@@ -80,6 +80,33 @@ var (
 		Required: false,
 		EnvVar:   common.PrefixEnvVar(envPrefix, "USE_GRAPH"),
 	}
+	OverRequestFactorFlag = cli.Float64Flag{
+		Name:     common.PrefixFlag(FlagPrefix, "over-request-factor"),
+		Usage:    "Chunks are requested from this many times the number of operators needed to reconstruct a blob, and the slowest requests are cancelled once enough chunks are verified. Must be at least 1. If set to zero, chunks are requested from all operators",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envPrefix, "OVER_REQUEST_FACTOR"),
+		Value:    0,
+	}
+	EigenDAStartBlockFlag = cli.Uint64Flag{
+		Name:     common.PrefixFlag(FlagPrefix, "eigenda-start-block"),
+		Usage:    "Block the EigenDA service manager contract was deployed at. Batches are looked up by their ID from the BatchConfirmed events emitted since this block",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envPrefix, "EIGENDA_START_BLOCK"),
+		Value:    0,
+	}
+	EigenDAConfirmationDepthFlag = cli.Uint64Flag{
+		Name:     common.PrefixFlag(FlagPrefix, "eigenda-confirmation-depth"),
+		Usage:    "Number of blocks behind the current block whose BatchConfirmed events may still be reorged out. Batches confirmed in those blocks are looked up by their ID again on each request instead of being indexed",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envPrefix, "EIGENDA_CONFIRMATION_DEPTH"),
+		Value:    64,
+	}
+	CacheOperatorStateFlag = cli.BoolFlag{
+		Name:     common.PrefixFlag(FlagPrefix, "cache-operator-state"),
+		Usage:    "Cache the operator pubkeys and sockets of the latest reference blocks retrieved from instead of fetching them from the indexer for each retrieval",
+		Required: false,
+		EnvVar:   common.PrefixEnvVar(envPrefix, "CACHE_OPERATOR_STATE"),
+	}
 )
 
 var requiredFlags = []cli.Flag{
@@ -96,6 +123,10 @@ var optionalFlags = []cli.Flag{
 	MetricsHTTPPortFlag,
 	GraphUrlFlag,
 	UseGraphFlag,
+	OverRequestFactorFlag,
+	EigenDAStartBlockFlag,
+	EigenDAConfirmationDepthFlag,
+	CacheOperatorStateFlag,
 }
 
 // Flags contains the list of configuration options available to the binary.
@@ -23,3 +23,8 @@ func (c *MockChainClient) FetchBatchHeader(ctx context.Context, serviceManagerAd
 	args := c.Called()
 	return args.Get(0).(*binding.IEigenDAServiceManagerBatchHeader), args.Error(1)
 }
+
+func (c *MockChainClient) FetchBatchHeaderByID(ctx context.Context, serviceManagerAddress gcommon.Address, batchID uint32) ([32]byte, *binding.IEigenDAServiceManagerBatchHeader, error) {
+	args := c.Called(batchID)
+	return args.Get(0).([32]byte), args.Get(1).(*binding.IEigenDAServiceManagerBatchHeader), args.Error(2)
+}
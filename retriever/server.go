@@ -2,14 +2,17 @@ package retriever
 
 import (
 	"context"
-	"fmt"
+	"errors"
 
 	pb "github.com/Layr-Labs/eigenda/api/grpc/retriever"
 	"github.com/Layr-Labs/eigenda/clients"
 	"github.com/Layr-Labs/eigenda/common"
+	binding "github.com/Layr-Labs/eigenda/contracts/bindings/EigenDAServiceManager"
 	"github.com/Layr-Labs/eigenda/core"
 	"github.com/Layr-Labs/eigenda/retriever/eth"
 	gcommon "github.com/ethereum/go-ethereum/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Server struct {
@@ -52,27 +55,72 @@ func (s *Server) RetrieveBlob(ctx context.Context, req *pb.BlobRequest) (*pb.Blo
 	s.logger.Info("Received request: ", "BatchHeaderHash", req.GetBatchHeaderHash(), "BlobIndex", req.GetBlobIndex())
 	s.metrics.IncrementRetrievalRequestCounter()
 	if len(req.GetBatchHeaderHash()) != 32 {
-		return nil, fmt.Errorf("got invalid batch header hash")
+		return nil, status.Error(codes.InvalidArgument, "got invalid batch header hash")
+	}
+	if req.GetQuorumId() > core.MaxQuorumID {
+		return nil, status.Errorf(codes.InvalidArgument, "got invalid quorum ID %d", req.GetQuorumId())
 	}
 	var batchHeaderHash [32]byte
 	copy(batchHeaderHash[:], req.GetBatchHeaderHash())
 
 	batchHeader, err := s.chainClient.FetchBatchHeader(ctx, gcommon.HexToAddress(s.config.EigenDAServiceManagerAddr), req.GetBatchHeaderHash())
 	if err != nil {
-		return nil, err
+		return nil, toGRPCError(err)
+	}
+
+	return s.retrieveBlob(ctx, batchHeaderHash, batchHeader, req.GetBlobIndex(), req.GetQuorumId())
+}
+
+// RetrieveBlobByBatchId retrieves a blob of the batch with the given onchain batch ID, fetching the batch header
+// from chain so that clients don't need to look it up first
+func (s *Server) RetrieveBlobByBatchId(ctx context.Context, req *pb.BlobByBatchIdRequest) (*pb.BlobReply, error) {
+	s.logger.Info("Received request: ", "BatchId", req.GetBatchId(), "BlobIndex", req.GetBlobIndex())
+	s.metrics.IncrementRetrievalRequestCounter()
+	if req.GetQuorumId() > core.MaxQuorumID {
+		return nil, status.Errorf(codes.InvalidArgument, "got invalid quorum ID %d", req.GetQuorumId())
 	}
 
+	batchHeaderHash, batchHeader, err := s.chainClient.FetchBatchHeaderByID(ctx, gcommon.HexToAddress(s.config.EigenDAServiceManagerAddr), req.GetBatchId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	return s.retrieveBlob(ctx, batchHeaderHash, batchHeader, req.GetBlobIndex(), req.GetQuorumId())
+}
+
+func (s *Server) retrieveBlob(ctx context.Context, batchHeaderHash [32]byte, batchHeader *binding.IEigenDAServiceManagerBatchHeader, blobIndex uint32, quorumID uint32) (*pb.BlobReply, error) {
+	var opts []clients.RetrievalOption
+	if s.config.CacheOperatorState {
+		opts = append(opts, clients.WithCachedOperatorState())
+	}
 	data, err := s.retrievalClient.RetrieveBlob(
 		ctx,
 		batchHeaderHash,
-		req.GetBlobIndex(),
+		blobIndex,
 		uint(batchHeader.ReferenceBlockNumber),
 		batchHeader.BlobHeadersRoot,
-		core.QuorumID(req.GetQuorumId()))
+		core.QuorumID(quorumID),
+		opts...)
 	if err != nil {
-		return nil, err
+		return nil, toGRPCError(err)
 	}
 	return &pb.BlobReply{
 		Data: data,
 	}, nil
 }
+
+// toGRPCError maps errors from retrieving a blob to gRPC status errors with the matching code
+func toGRPCError(err error) error {
+	switch {
+	case errors.Is(err, clients.ErrQuorumNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, clients.ErrBlobHeaderUnavailable), errors.Is(err, clients.ErrDecodeFailed):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -2,11 +2,14 @@ package retriever_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"runtime"
 	"testing"
 
 	pb "github.com/Layr-Labs/eigenda/api/grpc/retriever"
+	"github.com/Layr-Labs/eigenda/clients"
 	clientsmock "github.com/Layr-Labs/eigenda/clients/mock"
 	commock "github.com/Layr-Labs/eigenda/common/mock"
 	binding "github.com/Layr-Labs/eigenda/contracts/bindings/EigenDAServiceManager"
@@ -19,6 +22,8 @@ import (
 	"github.com/Layr-Labs/eigenda/retriever"
 	"github.com/Layr-Labs/eigenda/retriever/mock"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const numOperators = 10
@@ -99,3 +104,106 @@ func TestRetrieveBlob(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, gettysburgAddressBytes, retrievalReply.Data)
 }
+
+func TestRetrieveBlobInvalidRequest(t *testing.T) {
+	server := newTestServer(t)
+
+	_, err := server.RetrieveBlob(context.Background(), &pb.BlobRequest{
+		BatchHeaderHash: []byte{1, 2, 3},
+		BlobIndex:       0,
+		QuorumId:        0,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.RetrieveBlob(context.Background(), &pb.BlobRequest{
+		BatchHeaderHash: batchHeaderHash[:],
+		BlobIndex:       0,
+		QuorumId:        core.MaxQuorumID + 1,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestRetrieveBlobErrorCodes(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{
+			name: "quorum not found",
+			err:  fmt.Errorf("%w: no quorum with ID: %d", clients.ErrQuorumNotFound, 1),
+			code: codes.NotFound,
+		},
+		{
+			name: "no operators",
+			err:  fmt.Errorf("%w: failed to get blob header from all operators", clients.ErrBlobHeaderUnavailable),
+			code: codes.Unavailable,
+		},
+		{
+			name: "decode failure",
+			err:  fmt.Errorf("%w: not enough chunks", clients.ErrDecodeFailed),
+			code: codes.Unavailable,
+		},
+		{
+			name: "unknown failure",
+			err:  errors.New("unknown failure"),
+			code: codes.Internal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t)
+			chainClient.On("FetchBatchHeader").Return(&binding.IEigenDAServiceManagerBatchHeader{
+				BlobHeadersRoot:            batchRoot,
+				QuorumNumbers:              []byte{0},
+				QuorumThresholdPercentages: []byte{90},
+				ReferenceBlockNumber:       0,
+			}, nil)
+			retrievalClient.On("RetrieveBlob").Return([]byte(nil), tc.err)
+
+			_, err := server.RetrieveBlob(context.Background(), &pb.BlobRequest{
+				BatchHeaderHash: batchHeaderHash[:],
+				BlobIndex:       0,
+				QuorumId:        0,
+			})
+			assert.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
+
+func TestRetrieveBlobByBatchId(t *testing.T) {
+	server := newTestServer(t)
+	chainClient.On("FetchBatchHeaderByID", uint32(7)).Return(batchHeaderHash, &binding.IEigenDAServiceManagerBatchHeader{
+		BlobHeadersRoot:            batchRoot,
+		QuorumNumbers:              []byte{0},
+		QuorumThresholdPercentages: []byte{90},
+		ReferenceBlockNumber:       0,
+	}, nil)
+	chainClient.On("FetchBatchHeaderByID", uint32(8)).Return([32]byte{}, (*binding.IEigenDAServiceManagerBatchHeader)(nil), errors.New("could not find confirmBatch event for batch ID 8"))
+
+	retrievalClient.On("RetrieveBlob").Return(gettysburgAddressBytes, nil)
+
+	retrievalReply, err := server.RetrieveBlobByBatchId(context.Background(), &pb.BlobByBatchIdRequest{
+		BatchId:   7,
+		BlobIndex: 0,
+		QuorumId:  0,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, gettysburgAddressBytes, retrievalReply.Data)
+	chainClient.AssertNotCalled(t, "FetchBatchHeader")
+
+	_, err = server.RetrieveBlobByBatchId(context.Background(), &pb.BlobByBatchIdRequest{
+		BatchId:   8,
+		BlobIndex: 0,
+		QuorumId:  0,
+	})
+	assert.Error(t, err)
+
+	_, err = server.RetrieveBlobByBatchId(context.Background(), &pb.BlobByBatchIdRequest{
+		BatchId:   7,
+		BlobIndex: 0,
+		QuorumId:  core.MaxQuorumID + 1,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
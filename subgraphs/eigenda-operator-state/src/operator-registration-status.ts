@@ -27,6 +27,7 @@ export function handleOperatorRegistered(event: OperatorRegisteredEvent) : void
   }
 
   entity.deregistrationBlockNumber = BigInt.fromU32(4294967295)
+  entity.registrationBlockNumber = event.block.number
 
   entity.save()
 }
@@ -102,6 +102,12 @@ import {
         "deregistrationBlockNumber",
         "4294967295"
       )
+      assert.fieldEquals(
+        "Operator",
+        pubkeyHash.toHexString(),
+        "registrationBlockNumber",
+        operatorRegisteredEvent.block.number.toString()
+      )
   
       let operatorDeregisteredEvent = createNewOperatorDeregisteredEvent(
         operator,
@@ -27,6 +27,7 @@ import (
 	retrievermock "github.com/Layr-Labs/eigenda/retriever/mock"
 	"github.com/Layr-Labs/eigensdk-go/metrics"
 	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/peer"
 
 	"github.com/Layr-Labs/eigenda/common"
@@ -338,6 +339,162 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
+// serveOperator serves the dispersal and retrieval APIs of the operator until the test completes, so that the operator
+// ports are free again for the tests that follow
+func serveOperator(t *testing.T, op TestOperator) {
+	for _, port := range []string{op.Node.Config.InternalDispersalPort, op.Node.Config.InternalRetrievalPort} {
+		listener, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%s", port))
+		assert.NoError(t, err)
+		gs := grpc.NewServer(grpc.MaxRecvMsgSize(1024 * 1024 * 1024))
+		nodepb.RegisterDispersalServer(gs, op.Server)
+		nodepb.RegisterRetrievalServer(gs, op.Server)
+		go func() {
+			_ = gs.Serve(listener)
+		}()
+		t.Cleanup(gs.Stop)
+	}
+}
+
+func TestDispersalAndRetrievalPinnedChunkLength(t *testing.T) {
+
+	p := &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("0.0.0.0"),
+			Port: 3000,
+		},
+	}
+	ctx := peer.NewContext(context.Background(), p)
+
+	cst, err := coremock.MakeChainDataMock(numOperators)
+	assert.NoError(t, err)
+
+	cst.On("GetCurrentBlockNumber").Return(uint(10), nil)
+
+	logger, err := logging.GetLogger(logging.DefaultCLIConfig())
+	assert.NoError(t, err)
+	store := inmem.NewBlobStore()
+	dis := mustMakeDisperser(t, cst, store, logger)
+	go func() {
+		_ = dis.encoderServer.Start()
+	}()
+	t.Cleanup(func() {
+		dis.encoderServer.Close()
+	})
+	ops := mustMakeOperators(t, cst, logger)
+
+	for _, op := range ops {
+		err = op.Node.Start(ctx)
+		assert.NoError(t, err)
+		serveOperator(t, op)
+	}
+
+	// the client pins a smaller chunk length than the disperser derives for the blob
+	blob := mustMakeTestBlob()
+	blob.RequestHeader.ChunkLength = 4
+	requestedAt := uint64(time.Now().UnixNano())
+	metadataKey, err := store.StoreBlob(ctx, &blob, requestedAt)
+	assert.NoError(t, err)
+	out := make(chan batcher.EncodingResultOrStatus)
+	err = dis.batcher.EncodingStreamer.RequestEncoding(context.Background(), out)
+	assert.NoError(t, err)
+	err = dis.batcher.EncodingStreamer.ProcessEncodedBlobs(context.Background(), <-out)
+	assert.NoError(t, err)
+	dis.batcher.EncodingStreamer.Pool.StopWait()
+
+	txn := types.NewTransaction(0, gethcommon.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	dis.transactor.On("BuildConfirmBatchTxn").Return(txn, nil)
+	dis.txnManager.On("ProcessTransaction").Return(nil)
+
+	err = dis.batcher.HandleSingleBatch(ctx)
+	assert.NoError(t, err)
+	assert.Greater(t, len(dis.txnManager.Requests), 0)
+	logData, err := hex.DecodeString("00000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dis.batcher.ProcessConfirmedBatch(ctx, &batcher.ReceiptOrErr{
+		Receipt: &types.Receipt{
+			Status: types.ReceiptStatusSuccessful,
+			Logs: []*types.Log{
+				{
+					Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},
+					Data:   logData,
+				},
+			},
+			BlockNumber: big.NewInt(123),
+		},
+		Metadata: dis.txnManager.Requests[len(dis.txnManager.Requests)-1].Metadata,
+	})
+	assert.NoError(t, err)
+
+	metadata, err := store.GetBlobMetadata(ctx, metadataKey)
+	assert.NoError(t, err)
+	assert.Equal(t, disperser.Confirmed, metadata.BlobStatus)
+	batchHeaderHash := metadata.ConfirmationInfo.BatchHeaderHash[:]
+
+	operatorState, err := cst.GetOperatorState(ctx, 0, []core.QuorumID{0})
+	assert.NoError(t, err)
+	blobLength := core.GetBlobLength(uint(len(blob.Data)))
+	derivedChunkLength, err := asn.CalculateChunkLength(operatorState, blobLength, 0, blob.RequestHeader.SecurityParams[0])
+	assert.NoError(t, err)
+	assert.Less(t, blob.RequestHeader.ChunkLength, derivedChunkLength)
+
+	blobQuorumInfo := &core.BlobQuorumInfo{
+		SecurityParam: core.SecurityParam{
+			QuorumID:           0,
+			AdversaryThreshold: q0AdversaryThreshold,
+			QuorumThreshold:    q0QuorumThreshold,
+		},
+		ChunkLength: blob.RequestHeader.ChunkLength,
+	}
+	assignments, info, err := asn.GetAssignments(operatorState, blobLength, blobQuorumInfo)
+	assert.NoError(t, err)
+
+	var indices []core.ChunkNumber
+	var chunks []*core.Chunk
+	var blobHeader *core.BlobHeader
+	for _, op := range ops {
+		// the operators store the blob with the pinned chunk length
+		headerReply, err := op.Server.GetBlobHeader(ctx, &nodepb.GetBlobHeaderRequest{
+			BatchHeaderHash: batchHeaderHash,
+			BlobIndex:       metadata.ConfirmationInfo.BlobIndex,
+			QuorumId:        uint32(0),
+		})
+		assert.NoError(t, err)
+		assert.Len(t, headerReply.GetBlobHeader().GetQuorumHeaders(), 1)
+		assert.Equal(t, uint32(blob.RequestHeader.ChunkLength), headerReply.GetBlobHeader().GetQuorumHeaders()[0].GetChunkLength())
+
+		if blobHeader == nil {
+			blobHeader, err = nodegrpc.GetBlobHeaderFromProto(headerReply.GetBlobHeader())
+			assert.NoError(t, err)
+		}
+
+		chunksReply, err := op.Server.RetrieveChunks(ctx, &nodepb.RetrieveChunksRequest{
+			BatchHeaderHash: batchHeaderHash,
+			BlobIndex:       metadata.ConfirmationInfo.BlobIndex,
+			QuorumId:        uint32(0),
+		})
+		assert.NoError(t, err)
+		assignment, ok := assignments[op.Node.Config.ID]
+		assert.True(t, ok)
+		for _, data := range chunksReply.GetChunks() {
+			chunk, err := new(core.Chunk).Deserialize(data)
+			assert.NoError(t, err)
+			assert.Equal(t, int(blob.RequestHeader.ChunkLength), chunk.Length())
+			chunks = append(chunks, chunk)
+		}
+		assert.Len(t, chunksReply.GetChunks(), int(assignment.NumChunks))
+		indices = append(indices, assignment.GetIndices()...)
+	}
+
+	encodingParams, err := core.GetEncodingParams(blob.RequestHeader.ChunkLength, info.TotalChunks)
+	assert.NoError(t, err)
+	recovered, err := enc.Decode(chunks, indices, encodingParams, uint64(blobHeader.Length)*bn254.BYTES_PER_COEFFICIENT)
+	assert.NoError(t, err)
+	recovered = bytes.TrimRight(recovered, "\x00")
+	assert.Equal(t, gettysburgAddressBytes, recovered)
+}
+
 func TestDispersalAndRetrieval(t *testing.T) {
 
 	p := &peer.Peer{
@@ -403,6 +560,7 @@ func TestDispersalAndRetrieval(t *testing.T) {
 	}
 
 	receipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
 		Logs: []*types.Log{
 			{
 				Topics: []gethcommon.Hash{common.BatchConfirmedEventSigHash, gethcommon.HexToHash("1234")},